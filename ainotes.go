@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ── AI Notes ────────────────────────────────────────────────────────────────
+//
+// Grain's AI-generated meeting notes are scraped from the notes panel of the
+// meeting page. The raw payload (headed sections, plain text, and the panel
+// HTML) is saved as <id>.ai-notes.raw.json so nothing is lost to our
+// interpretation. Metadata gets the notes in the --notes-format shape:
+//
+//	json  structured sections: [{"title", "items", "text"}] (default)
+//	md    a markdown string (## heading, - bullet)
+//	text  the panel's plain text
+//
+// Sections with well-known headings are also mapped into first-class
+// metadata fields: summary, action_items, and questions.
+
+// Notes formats accepted by --notes-format.
+const (
+	notesFormatJSON = "json"
+	notesFormatMD   = "md"
+	notesFormatText = "text"
+)
+
+// AINoteSection is one headed block of AI notes.
+type AINoteSection struct {
+	Title string   `json:"title"`
+	Items []string `json:"items,omitempty"`
+	Text  string   `json:"text,omitempty"`
+}
+
+// AINotesPayload is the raw AI notes panel scraped from a meeting page.
+type AINotesPayload struct {
+	Sections []AINoteSection `json:"sections"`
+	Text     string          `json:"text"`
+	HTML     string          `json:"html,omitempty"`
+}
+
+// empty reports whether the page had no AI notes.
+func (p *AINotesPayload) empty() bool {
+	return p == nil || (len(p.Sections) == 0 && strings.TrimSpace(p.Text) == "")
+}
+
+// render returns the notes in the given --notes-format.
+func (p *AINotesPayload) render(format string) any {
+	switch format {
+	case notesFormatMD:
+		return aiNotesMarkdown(p.Sections)
+	case notesFormatText:
+		return strings.TrimSpace(p.Text)
+	default:
+		if len(p.Sections) == 0 {
+			return strings.TrimSpace(p.Text)
+		}
+		return p.Sections
+	}
+}
+
+// applyTo sets meta's AI notes and the mapped summary, action item, and
+// question fields. The first section of each kind wins.
+func (p *AINotesPayload) applyTo(meta *Metadata, format string) {
+	if p.empty() {
+		return
+	}
+	meta.AINotes = p.render(format)
+	for _, s := range p.Sections {
+		switch classifyNoteSection(s.Title) {
+		case "summary":
+			if meta.Summary == "" {
+				meta.Summary = strings.TrimSpace(strings.Join(append([]string{s.Text}, s.Items...), "\n"))
+			}
+		case "action_items":
+			if meta.ActionItems == nil {
+				meta.ActionItems = s.lines()
+			}
+		case "questions":
+			if meta.Questions == nil {
+				meta.Questions = s.lines()
+			}
+		}
+	}
+}
+
+// lines returns a section's bullet items, or its text split by line when it
+// has no bullets.
+func (s AINoteSection) lines() []string {
+	if len(s.Items) > 0 {
+		return s.Items
+	}
+	var out []string
+	for _, l := range strings.Split(s.Text, "\n") {
+		if l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "-•*")); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// classifyNoteSection maps a section heading to a metadata field name, or
+// "" for sections without a dedicated field.
+func classifyNoteSection(title string) string {
+	t := strings.ToLower(strings.TrimSpace(title))
+	switch {
+	case strings.Contains(t, "summary"), strings.Contains(t, "overview"), strings.Contains(t, "tl;dr"), t == "recap":
+		return "summary"
+	case strings.Contains(t, "action item"), strings.Contains(t, "next step"), strings.Contains(t, "to-do"),
+		strings.Contains(t, "todo"), strings.Contains(t, "follow-up"), strings.Contains(t, "follow up"):
+		return "action_items"
+	case strings.Contains(t, "question"):
+		return "questions"
+	}
+	return ""
+}
+
+// aiNotesMarkdown renders sections as markdown.
+func aiNotesMarkdown(sections []AINoteSection) string {
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if s.Title != "" {
+			b.WriteString("### " + s.Title + "\n\n")
+		}
+		if s.Text != "" {
+			b.WriteString(s.Text + "\n")
+		}
+		if s.Text != "" && len(s.Items) > 0 {
+			b.WriteString("\n")
+		}
+		for _, item := range s.Items {
+			b.WriteString("- " + item + "\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// aiNotesJS collects the AI notes panel as headed sections. Headings start
+// a section; list items become items and other blocks become text.
+const aiNotesJS = `() => {
+	const panel = document.querySelector(
+		'[data-testid="ai-notes"], [data-testid="meeting-notes"], ' +
+		'[class*="AINotes"], [class*="ai-notes"], [class*="SmartNotes"], [class*="smart-notes"]'
+	);
+	if (!panel) return '';
+	const sections = [];
+	let cur = null;
+	const start = (title) => { cur = { title: title, items: [], text: '' }; sections.push(cur); };
+	panel.querySelectorAll('h1, h2, h3, h4, h5, li, p').forEach(el => {
+		const t = (el.innerText || '').trim();
+		if (!t) return;
+		if (/^H\d$/.test(el.tagName)) { start(t); return; }
+		if (!cur) start('');
+		if (el.tagName === 'LI') cur.items.push(t);
+		else if (!el.closest('li')) cur.text = cur.text ? cur.text + '\n' + t : t;
+	});
+	return JSON.stringify({
+		sections: sections.filter(s => s.items.length || s.text),
+		text: (panel.innerText || '').trim(),
+		html: panel.innerHTML,
+	});
+}`
+
+// scrapeAINotes opens the notes panel and reads it. Returns nil when the
+// page has no AI notes.
+func (b *Browser) scrapeAINotes() *AINotesPayload {
+	b.clickElement(`[data-testid="ai-notes-tab"], button:has-text("AI Notes"), [role="tab"]:has-text("Notes"), [role="tab"]:has-text("Summary")`)
+	time.Sleep(1 * time.Second)
+
+	res, err := b.page.Eval(aiNotesJS)
+	if err != nil {
+		slog.Debug("AI notes scrape failed", "error", err)
+		return nil
+	}
+	raw := res.Value.Str()
+	if raw == "" {
+		return nil
+	}
+	var p AINotesPayload
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		slog.Debug("Failed to parse scraped AI notes", "error", err)
+		return nil
+	}
+	if p.empty() {
+		return nil
+	}
+	return &p
+}
+
+func (e *Exporter) writeAINotesRaw(scraped *MeetingPageData, id, relBase string, r *ExportResult) {
+	if scraped == nil || scraped.AINotes.empty() {
+		return
+	}
+
+	relPath := relBase + ".ai-notes.raw.json"
+	if err := e.storage.WriteJSON(relPath, scraped.AINotes); err != nil {
+		slog.Error("AI notes write failed", "error", err, "id", id)
+		return
+	}
+	r.AINotesPath = relPath
+	slog.Info("AI notes exported", "id", id, "sections", len(scraped.AINotes.Sections))
+}
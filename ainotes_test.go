@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func sampleAINotes() *AINotesPayload {
+	return &AINotesPayload{
+		Sections: []AINoteSection{
+			{Title: "Summary", Text: "Agreed to ship v2 in March."},
+			{Title: "Action Items", Items: []string{"Alice: draft launch plan", "Bob: fix billing bug"}},
+			{Title: "Open Questions", Text: "- Pricing for EU?\n- Who owns docs?"},
+			{Title: "Key Topics", Items: []string{"Roadmap"}},
+		},
+		Text: "Summary\nAgreed to ship v2 in March.\n...",
+		HTML: "<h3>Summary</h3><p>Agreed to ship v2 in March.</p>",
+	}
+}
+
+func TestClassifyNoteSection(t *testing.T) {
+	for title, want := range map[string]string{
+		"Summary":          "summary",
+		"Meeting overview": "summary",
+		"TL;DR":            "summary",
+		"Action items":     "action_items",
+		"Next Steps":       "action_items",
+		"Follow-ups":       "action_items",
+		"Questions asked":  "questions",
+		"Key Topics":       "",
+		"":                 "",
+	} {
+		if got := classifyNoteSection(title); got != want {
+			t.Errorf("classifyNoteSection(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestAINotesApplyTo(t *testing.T) {
+	meta := &Metadata{ID: "m1"}
+	sampleAINotes().applyTo(meta, notesFormatJSON)
+
+	if meta.Summary != "Agreed to ship v2 in March." {
+		t.Errorf("Summary = %q", meta.Summary)
+	}
+	if want := []string{"Alice: draft launch plan", "Bob: fix billing bug"}; !reflect.DeepEqual(meta.ActionItems, want) {
+		t.Errorf("ActionItems = %v", meta.ActionItems)
+	}
+	if want := []string{"Pricing for EU?", "Who owns docs?"}; !reflect.DeepEqual(meta.Questions, want) {
+		t.Errorf("Questions = %v", meta.Questions)
+	}
+	if sections, ok := meta.AINotes.([]AINoteSection); !ok || len(sections) != 4 {
+		t.Errorf("AINotes = %#v, want 4 sections", meta.AINotes)
+	}
+
+	var empty *AINotesPayload
+	untouched := &Metadata{ID: "m2"}
+	empty.applyTo(untouched, notesFormatJSON)
+	if untouched.AINotes != nil || untouched.Summary != "" {
+		t.Errorf("nil payload changed metadata: %+v", untouched)
+	}
+}
+
+func TestAINotesRenderFormats(t *testing.T) {
+	p := sampleAINotes()
+
+	md, ok := p.render(notesFormatMD).(string)
+	if !ok {
+		t.Fatalf("md render = %T", p.render(notesFormatMD))
+	}
+	for _, want := range []string{"### Summary\n\nAgreed to ship v2 in March.", "### Action Items\n\n- Alice: draft launch plan\n- Bob: fix billing bug"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("md missing %q:\n%s", want, md)
+		}
+	}
+	if got := p.render(notesFormatText); got != strings.TrimSpace(p.Text) {
+		t.Errorf("text render = %v", got)
+	}
+	// Without sections, json falls back to the panel text.
+	if got := (&AINotesPayload{Text: " plain notes "}).render(notesFormatJSON); got != "plain notes" {
+		t.Errorf("json render without sections = %v", got)
+	}
+}
+
+func TestFormatAnyAINoteSections(t *testing.T) {
+	got := formatAny(sampleAINotes().Sections)
+	if !strings.HasPrefix(got, "### Summary") || !strings.Contains(got, "- Roadmap") {
+		t.Errorf("formatAny(sections) = %q", got)
+	}
+}
+
+func TestWriteAINotesRaw(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, SkipVideo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+
+	r := &ExportResult{ID: "m1"}
+	e.writeAINotesRaw(&MeetingPageData{}, "m1", filepath.Join("2025-01-15", "m1"), r)
+	if r.AINotesPath != "" {
+		t.Error("raw notes written for a page without notes")
+	}
+
+	e.writeAINotesRaw(&MeetingPageData{AINotes: sampleAINotes()}, "m1", filepath.Join("2025-01-15", "m1"), r)
+	if r.AINotesPath != filepath.Join("2025-01-15", "m1.ai-notes.raw.json") {
+		t.Fatalf("AINotesPath = %q", r.AINotesPath)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, r.AINotesPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got AINotesPayload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&got, sampleAINotes()) {
+		t.Errorf("raw payload round trip = %+v", got)
+	}
+	if paths := collectResultPaths(r); !strings.Contains(strings.Join(paths, ","), "m1.ai-notes.raw.json") {
+		t.Errorf("collectResultPaths omits raw notes: %v", paths)
+	}
+}
+
+func TestBuildScrapedMetadataNotesFormat(t *testing.T) {
+	e := &Exporter{cfg: &Config{NotesFormat: notesFormatText}}
+	meta := e.buildScrapedMetadata(MeetingRef{ID: "m1"}, "https://grain.com/app/meetings/m1", &MeetingPageData{AINotes: sampleAINotes()})
+	if s, ok := meta.AINotes.(string); !ok || !strings.HasPrefix(s, "Summary\n") {
+		t.Errorf("AINotes = %#v, want panel text", meta.AINotes)
+	}
+	if meta.Summary == "" || len(meta.ActionItems) != 2 {
+		t.Errorf("mapped fields missing with text format: %+v", meta)
+	}
+}
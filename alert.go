@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ── Keyword Alerts ──────────────────────────────────────────────────────────
+//
+// When --alert-keywords is set, every newly exported transcript (and its
+// highlights) is scanned for the configured keywords. Matches are logged at
+// warn level and, if --alert-webhook is set, POSTed as JSON. The payload
+// carries a top-level "text" field so Slack incoming webhooks render it
+// directly; generic receivers can use the structured fields.
+
+// maxAlertMatches caps the number of snippets reported per meeting so a
+// transcript that mentions "cancel" 200 times doesn't produce a wall of text.
+const maxAlertMatches = 20
+
+// alertSnippetRadius is the number of runes kept on each side of a match.
+const alertSnippetRadius = 60
+
+// KeywordMatch is a single keyword hit inside a transcript segment or highlight.
+type KeywordMatch struct {
+	Keyword   string `json:"keyword"`
+	Source    string `json:"source"` // "transcript" or "highlight"
+	Snippet   string `json:"snippet"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// KeywordAlert is the webhook payload for a meeting with keyword matches.
+type KeywordAlert struct {
+	Text      string         `json:"text"`
+	Priority  string         `json:"priority"`
+	MeetingID string         `json:"meeting_id"`
+	Title     string         `json:"title"`
+	URL       string         `json:"url"`
+	Keywords  []string       `json:"keywords"`
+	Matches   []KeywordMatch `json:"matches"`
+}
+
+// parseKeywords splits a comma-separated keyword list, trimming whitespace,
+// lowercasing, and dropping empty or duplicate entries.
+func parseKeywords(s string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, k := range strings.Split(s, ",") {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" || seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, k)
+	}
+	return out
+}
+
+// findKeywordMatches scans transcript segments (blank-line separated, as
+// produced by scrapeTranscript) and highlights for case-insensitive keyword
+// occurrences. At most one match per keyword per segment is reported.
+func findKeywordMatches(keywords []string, transcript string, highlights []HighlightClip) []KeywordMatch {
+	if len(keywords) == 0 {
+		return nil
+	}
+	var matches []KeywordMatch
+
+	for _, h := range highlights {
+		for _, kw := range keywords {
+			if snip, ok := keywordSnippet(h.Text, kw); ok {
+				ts := ""
+				if h.StartSec > 0 {
+					ts = formatTimestamp(h.StartSec)
+				}
+				matches = append(matches, KeywordMatch{Keyword: kw, Source: "highlight", Snippet: snip, Timestamp: ts})
+				if len(matches) >= maxAlertMatches {
+					return matches
+				}
+			}
+		}
+	}
+
+	for _, seg := range strings.Split(transcript, "\n\n") {
+		seg = strings.TrimSpace(seg)
+		if seg == "" {
+			continue
+		}
+		for _, kw := range keywords {
+			if snip, ok := keywordSnippet(seg, kw); ok {
+				matches = append(matches, KeywordMatch{
+					Keyword:   kw,
+					Source:    "transcript",
+					Snippet:   snip,
+					Timestamp: leadingTimestamp(seg),
+				})
+				if len(matches) >= maxAlertMatches {
+					return matches
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// keywordSnippet returns a rune-safe excerpt of text around the first
+// case-insensitive occurrence of kw.
+func keywordSnippet(text, kw string) (string, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	kwRunes := []rune(kw)
+	// ToLower can change rune counts for a handful of scripts; fall back to
+	// a plain substring check if the lengths diverge.
+	if len(lower) != len(runes) {
+		if !strings.Contains(strings.ToLower(text), kw) {
+			return "", false
+		}
+		return truncateRunes(text, 2*alertSnippetRadius), true
+	}
+	idx := runeIndex(lower, kwRunes)
+	if idx < 0 {
+		return "", false
+	}
+	start := max(idx-alertSnippetRadius, 0)
+	end := min(idx+len(kwRunes)+alertSnippetRadius, len(runes))
+	snip := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		snip = "…" + snip
+	}
+	if end < len(runes) {
+		snip += "…"
+	}
+	return snip, true
+}
+
+func runeIndex(haystack, needle []rune) int {
+	if len(needle) == 0 {
+		return -1
+	}
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
+
+// leadingTimestamp returns an "HH:MM:SS" or "MM:SS" prefix of a transcript
+// segment if Grain rendered one (e.g. "00:12:34 Alice: ..."), else "".
+func leadingTimestamp(seg string) string {
+	field, _, _ := strings.Cut(seg, " ")
+	field = strings.TrimSuffix(strings.Trim(field, "[]()"), ":")
+	parts := strings.Split(field, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ""
+	}
+	for _, p := range parts {
+		if p == "" || len(p) > 2 {
+			return ""
+		}
+		for _, c := range p {
+			if c < '0' || c > '9' {
+				return ""
+			}
+		}
+	}
+	return field
+}
+
+// formatTimestamp renders seconds as HH:MM:SS.
+func formatTimestamp(sec float64) string {
+	s := int(sec)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, (s%3600)/60, s%60)
+}
+
+// ── Alerter ─────────────────────────────────────────────────────────────────
+
+// Alerter delivers keyword alerts to a webhook.
+type Alerter struct {
+	client     *http.Client
+	webhookURL string
+	keywords   []string
+}
+
+// NewAlerter returns an Alerter for the configured keywords, or nil when
+// keyword alerting is disabled.
+func NewAlerter(cfg *Config) *Alerter {
+	if len(cfg.AlertKeywords) == 0 {
+		return nil
+	}
+	return &Alerter{
+		client:     &http.Client{Timeout: 30 * time.Second},
+		webhookURL: cfg.AlertWebhook,
+		keywords:   cfg.AlertKeywords,
+	}
+}
+
+// Check scans a newly exported meeting and delivers an alert if any keyword
+// matched. Returns the number of matches and the webhook error, if any, for
+// the caller's receipt. Delivery failures are logged here; alerts must never
+// fail an export.
+func (a *Alerter) Check(ctx context.Context, meta *Metadata, transcript string, highlights []HighlightClip) (int, error) {
+	matches := findKeywordMatches(a.keywords, transcript, highlights)
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	alert := buildKeywordAlert(meta, matches)
+	slog.Warn("Keyword alert", "id", meta.ID, "keywords", strings.Join(alert.Keywords, ","), "matches", len(matches))
+
+	if a.webhookURL != "" {
+		if err := a.send(ctx, alert); err != nil {
+			slog.Error("Alert webhook failed", "id", meta.ID, "error", err)
+			return len(matches), err
+		}
+	}
+	return len(matches), nil
+}
+
+func buildKeywordAlert(meta *Metadata, matches []KeywordMatch) *KeywordAlert {
+	var kws []string
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if !seen[m.Keyword] {
+			seen[m.Keyword] = true
+			kws = append(kws, m.Keyword)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ":rotating_light: Keyword alert in *%s* (%s)\n", coalesce(meta.Title, meta.ID), strings.Join(kws, ", "))
+	for _, m := range matches {
+		b.WriteString("• ")
+		if m.Timestamp != "" {
+			b.WriteString("[" + m.Timestamp + "] ")
+		}
+		b.WriteString(m.Snippet)
+		b.WriteByte('\n')
+	}
+	if meta.Links.Grain != "" {
+		b.WriteString(meta.Links.Grain)
+	}
+
+	return &KeywordAlert{
+		Text:      strings.TrimSpace(b.String()),
+		Priority:  "high",
+		MeetingID: meta.ID,
+		Title:     meta.Title,
+		URL:       meta.Links.Grain,
+		Keywords:  kws,
+		Matches:   matches,
+	}
+}
+
+func (a *Alerter) send(ctx context.Context, alert *KeywordAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("marshal alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", a.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+	return nil
+}
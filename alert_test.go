@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ── parseKeywords ───────────────────────────────────────────────────────────
+
+func TestParseKeywords(t *testing.T) {
+	got := parseKeywords(" Churn, cancel,,REFUND ,churn ")
+	want := []string{"churn", "cancel", "refund"}
+	if len(got) != len(want) {
+		t.Fatalf("parseKeywords = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseKeywords[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if parseKeywords("") != nil {
+		t.Error("parseKeywords(\"\") should be nil")
+	}
+}
+
+// ── findKeywordMatches ──────────────────────────────────────────────────────
+
+func TestFindKeywordMatches(t *testing.T) {
+	transcript := "00:01:05 Alice: We are thinking about whether to Cancel the plan.\n\n" +
+		"Bob: Sounds good.\n\n" +
+		"Alice: Also we'd need a refund for last month."
+	highlights := []HighlightClip{
+		{Text: "Customer mentioned churn risk", StartSec: 3725},
+		{Text: "Nothing here"},
+	}
+
+	matches := findKeywordMatches([]string{"cancel", "refund", "churn"}, transcript, highlights)
+	if len(matches) != 3 {
+		t.Fatalf("got %d matches, want 3: %+v", len(matches), matches)
+	}
+
+	if matches[0].Source != "highlight" || matches[0].Keyword != "churn" || matches[0].Timestamp != "01:02:05" {
+		t.Errorf("highlight match = %+v", matches[0])
+	}
+	if matches[1].Keyword != "cancel" || matches[1].Timestamp != "00:01:05" {
+		t.Errorf("transcript match = %+v", matches[1])
+	}
+	if !strings.Contains(matches[1].Snippet, "Cancel the plan") {
+		t.Errorf("snippet = %q, want original casing preserved", matches[1].Snippet)
+	}
+	if matches[2].Keyword != "refund" || matches[2].Timestamp != "" {
+		t.Errorf("transcript match without timestamp = %+v", matches[2])
+	}
+}
+
+func TestFindKeywordMatchesCap(t *testing.T) {
+	transcript := strings.Repeat("cancel\n\n", maxAlertMatches*2)
+	matches := findKeywordMatches([]string{"cancel"}, transcript, nil)
+	if len(matches) != maxAlertMatches {
+		t.Errorf("got %d matches, want cap %d", len(matches), maxAlertMatches)
+	}
+}
+
+func TestKeywordSnippetTruncates(t *testing.T) {
+	text := strings.Repeat("a", 200) + " refund " + strings.Repeat("b", 200)
+	snip, ok := keywordSnippet(text, "refund")
+	if !ok {
+		t.Fatal("expected match")
+	}
+	if !strings.HasPrefix(snip, "…") || !strings.HasSuffix(snip, "…") {
+		t.Errorf("snippet should be elided on both sides: %q", snip)
+	}
+	if n := len([]rune(snip)); n > 2*alertSnippetRadius+len("refund")+2 {
+		t.Errorf("snippet too long: %d runes", n)
+	}
+}
+
+func TestLeadingTimestamp(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"00:12:34 Alice: hi", "00:12:34"},
+		{"[12:34] Alice: hi", "12:34"},
+		{"Alice: hi", ""},
+		{"2024:01 something", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := leadingTimestamp(tt.in); got != tt.want {
+			t.Errorf("leadingTimestamp(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// ── Alerter ─────────────────────────────────────────────────────────────────
+
+func TestNewAlerterDisabled(t *testing.T) {
+	if a := NewAlerter(&Config{}); a != nil {
+		t.Error("NewAlerter should return nil without keywords")
+	}
+}
+
+func TestAlerterCheckPostsWebhook(t *testing.T) {
+	var got KeywordAlert
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	a := NewAlerter(&Config{AlertKeywords: []string{"refund"}, AlertWebhook: srv.URL})
+	meta := &Metadata{ID: "m1", Title: "Q3 Review", Links: Links{Grain: "https://grain.com/app/meetings/m1"}}
+
+	if n, _ := a.Check(context.Background(), meta, "Bob: nothing to see", nil); n != 0 || calls != 0 {
+		t.Fatalf("no match should not alert: n=%d calls=%d", n, calls)
+	}
+
+	n, err := a.Check(context.Background(), meta, "Alice: they asked for a refund", nil)
+	if n != 1 || calls != 1 || err != nil {
+		t.Fatalf("n=%d calls=%d err=%v, want 1/1/nil", n, calls, err)
+	}
+	if got.MeetingID != "m1" || got.Priority != "high" || len(got.Matches) != 1 {
+		t.Errorf("payload = %+v", got)
+	}
+	if !strings.Contains(got.Text, "Q3 Review") || !strings.Contains(got.Text, "refund") {
+		t.Errorf("text = %q", got.Text)
+	}
+}
+
+func TestAlerterWebhookErrorIsNonFatal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	a := NewAlerter(&Config{AlertKeywords: []string{"churn"}, AlertWebhook: srv.URL})
+	n, err := a.Check(context.Background(), &Metadata{ID: "m2"}, "churn", nil)
+	if n != 1 {
+		t.Errorf("matches = %d, want 1 even when webhook fails", n)
+	}
+	if err == nil {
+		t.Error("webhook failure not reported for the receipt")
+	}
+}
+
+// exportOne needs a browser to scrape the transcript, so this drives what
+// it does afterwards: write the transcript, finishResult, alertMeeting.
+func TestAlertMeetingOncePerTranscript(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, SkipVideo: true, Overwrite: true, AlertKeywords: []string{"refund"}, AlertWebhook: srv.URL}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	meta := &Metadata{ID: "m1", Title: "Sync"}
+	export := func(transcript string) *ExportResult {
+		writeArchiveFile(t, dir, "2025-01-15/m1.transcript.txt", transcript, 0)
+		r := &ExportResult{ID: "m1", DateDir: "2025-01-15", TranscriptPaths: map[string]string{"text": "2025-01-15/m1.transcript.txt"}}
+		rc := e.finishResult(context.Background(), meta, r)
+		e.alertMeeting(context.Background(), meta, r, rc, transcript, nil)
+		return r
+	}
+
+	if r := export("Alice: a refund"); calls != 1 || r.AlertMatches != 1 {
+		t.Fatalf("first export: %d POST(s), %d match(es)", calls, r.AlertMatches)
+	}
+	// --overwrite re-exports the same transcript: no second alert.
+	export("Alice: a refund")
+	if calls != 1 {
+		t.Errorf("re-export of an unchanged transcript: %d POSTs, want 1", calls)
+	}
+	// A changed transcript is new content and alerts again.
+	export("Alice: a refund, and another refund")
+	if calls != 2 {
+		t.Errorf("changed transcript: %d POSTs, want 2", calls)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// ── View Analytics ──────────────────────────────────────────────────────────
+//
+// Shared Grain recordings show how often they were watched. The counts are
+// scraped from the meeting page into metadata (views, unique_viewers,
+// last_viewed_at). Already-exported meetings are normally skipped without
+// visiting the page; --refresh-analytics revisits them and rewrites just
+// those fields so owners can track which recordings get watched.
+
+// ViewAnalytics holds engagement figures scraped from a meeting page. Nil
+// counts mean "not shown" (as opposed to zero views).
+type ViewAnalytics struct {
+	Views         *int
+	UniqueViewers *int
+	LastViewedAt  string
+}
+
+// empty reports whether nothing was found on the page.
+func (a *ViewAnalytics) empty() bool {
+	return a == nil || (a.Views == nil && a.UniqueViewers == nil && a.LastViewedAt == "")
+}
+
+// applyTo copies the figures that were found into meta and reports whether
+// anything changed. Figures missing from the page leave meta untouched.
+func (a *ViewAnalytics) applyTo(meta *Metadata) bool {
+	if a.empty() {
+		return false
+	}
+	changed := false
+	if a.Views != nil && (meta.Views == nil || *meta.Views != *a.Views) {
+		meta.Views = a.Views
+		changed = true
+	}
+	if a.UniqueViewers != nil && (meta.UniqueViewers == nil || *meta.UniqueViewers != *a.UniqueViewers) {
+		meta.UniqueViewers = a.UniqueViewers
+		changed = true
+	}
+	if a.LastViewedAt != "" && meta.LastViewedAt != a.LastViewedAt {
+		meta.LastViewedAt = a.LastViewedAt
+		changed = true
+	}
+	return changed
+}
+
+var countRe = regexp.MustCompile(`(?i)(\d[\d,]*(?:\.\d+)?)\s*([km])?`)
+
+// parseViewCount extracts a count from text like "1,234 views", "1.2K
+// views", or "12". Returns nil when no number is present.
+func parseViewCount(s string) *int {
+	m := countRe.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return nil
+	}
+	switch strings.ToLower(m[2]) {
+	case "k":
+		f *= 1_000
+	case "m":
+		f *= 1_000_000
+	}
+	n := int(f + 0.5)
+	return &n
+}
+
+// analyticsJS pulls the raw view/viewer/last-viewed strings from the page,
+// preferring explicit test IDs and falling back to visible text.
+const analyticsJS = `() => {
+	const pick = (sels) => {
+		for (const s of sels) {
+			const el = document.querySelector(s);
+			if (el && el.textContent.trim()) return el.textContent.trim();
+		}
+		return '';
+	};
+	const text = document.body ? document.body.innerText : '';
+	const match = (re) => (text.match(re) || [''])[0];
+	let last = '';
+	const lv = document.querySelector('[data-testid="last-viewed"]');
+	if (lv) {
+		const t = lv.querySelector('time[datetime]');
+		last = t ? t.getAttribute('datetime') : lv.textContent.trim();
+	}
+	return {
+		views: pick(['[data-testid="view-count"]', '[data-testid="views"]', '.view-count'])
+			|| match(/[\d.,]+\s*[km]?\s+views?\b/i),
+		unique: pick(['[data-testid="unique-viewers"]', '.unique-viewers'])
+			|| match(/[\d.,]+\s*[km]?\s+unique\s+viewers?\b/i),
+		last: last,
+	};
+}`
+
+// scrapeAnalytics reads view analytics from the current page.
+func (b *Browser) scrapeAnalytics() *ViewAnalytics {
+	res, err := b.page.Eval(analyticsJS)
+	if err != nil {
+		slog.Debug("Analytics scrape failed", "error", err)
+		return nil
+	}
+	a := &ViewAnalytics{
+		Views:         parseViewCount(res.Value.Get("views").Str()),
+		UniqueViewers: parseViewCount(res.Value.Get("unique").Str()),
+		LastViewedAt:  normalizeViewedAt(res.Value.Get("last").Str()),
+	}
+	if a.empty() {
+		return nil
+	}
+	return a
+}
+
+// normalizeViewedAt converts an ISO timestamp to RFC 3339 UTC; other text
+// (e.g. "2 days ago") is kept as shown.
+func normalizeViewedAt(s string) string {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "Last viewed"))
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return s
+}
+
+// ScrapeAnalytics navigates to a meeting page and reads only its analytics.
+func (b *Browser) ScrapeAnalytics(ctx context.Context, pageURL string) (*ViewAnalytics, error) {
+	if err := rod.Try(func() {
+		b.page.Context(ctx).Timeout(20 * time.Second).MustNavigate(pageURL).MustWaitStable()
+	}); err != nil {
+		return nil, fmt.Errorf("navigate to meeting: %w", err)
+	}
+	if err := b.checkAuth(); err != nil {
+		return nil, err
+	}
+	return b.scrapeAnalytics(), nil
+}
+
+// refreshAnalytics updates the analytics fields of an already-exported
+// meeting's metadata in place (--refresh-analytics).
+func (e *Exporter) refreshAnalytics(ctx context.Context, ref MeetingRef, metaRelPath string, r *ExportResult) {
+	meta, err := readArchiveMetadata(e.storage.AbsPath(metaRelPath))
+	if err != nil {
+		slog.Warn("Analytics refresh: metadata unreadable", "id", ref.ID, "error", err)
+		return
+	}
+
+	var a *ViewAnalytics
+	err = e.withBrowser(ctx, func(b *Browser) error {
+		var err error
+		a, err = b.ScrapeAnalytics(ctx, coalesce(ref.URL, meta.Links.Grain, meetingURL(ref.ID)))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errAuthRequired) {
+			r.Status = "error"
+			r.ErrorMsg = err.Error()
+			r.authFailed = true
+		}
+		slog.Warn("Analytics refresh failed", "id", ref.ID, "error", err)
+		return
+	}
+	if !a.applyTo(meta) {
+		slog.Debug("Analytics unchanged", "id", ref.ID)
+		return
+	}
+
+	e.writeMetadata(meta, metaRelPath, r)
+	noteCompressed(e.storage, r)
+	recordChecksums(e.cfg.OutputDir, r)
+	rc := e.openReceipt(r)
+	defer e.saveReceipt(rc)
+	slog.Info("Analytics refreshed", "id", ref.ID, "views", derefInt(meta.Views), "unique_viewers", derefInt(meta.UniqueViewers))
+
+	if e.drive != nil && r.MetadataPath != "" {
+		e.queueDrive(ctx, &driveJob{meta: meta, r: r, rc: rc, paths: collectResultPaths(r), done: func(_ *UploadStats, err error) {
+			if err != nil {
+				slog.Warn("Drive upload of refreshed metadata failed", "id", ref.ID, "error", err)
+			}
+		}})
+	}
+}
+
+// derefInt renders an optional count for logging.
+func derefInt(p *int) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// ── parseViewCount ──────────────────────────────────────────────────────────
+
+func TestParseViewCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"12", 12, true},
+		{"1 view", 1, true},
+		{"1,234 views", 1234, true},
+		{"1.2K views", 1200, true},
+		{"3m views", 3_000_000, true},
+		{"0 unique viewers", 0, true},
+		{"  57 unique viewers ", 57, true},
+		{"", 0, false},
+		{"no views yet", 0, false},
+	}
+	for _, tt := range tests {
+		got := parseViewCount(tt.in)
+		if (got != nil) != tt.ok {
+			t.Errorf("parseViewCount(%q) = %v, want ok=%v", tt.in, got, tt.ok)
+			continue
+		}
+		if got != nil && *got != tt.want {
+			t.Errorf("parseViewCount(%q) = %d, want %d", tt.in, *got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeViewedAt(t *testing.T) {
+	tests := map[string]string{
+		"2025-03-01T10:00:00-05:00":          "2025-03-01T15:00:00Z",
+		"Last viewed 2025-03-01T15:00:00Z":   "2025-03-01T15:00:00Z",
+		"Last viewed 2 days ago":             "2 days ago",
+		"":                                   "",
+		"  2025-03-01T15:00:00.123456789Z  ": "2025-03-01T15:00:00Z",
+	}
+	for in, want := range tests {
+		if got := normalizeViewedAt(in); got != want {
+			t.Errorf("normalizeViewedAt(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// ── applyTo ─────────────────────────────────────────────────────────────────
+
+func intPtr(n int) *int { return &n }
+
+func TestViewAnalyticsApplyTo(t *testing.T) {
+	meta := &Metadata{ID: "m1", Views: intPtr(5), LastViewedAt: "2025-01-01T00:00:00Z"}
+
+	var nilA *ViewAnalytics
+	if nilA.applyTo(meta) {
+		t.Error("nil analytics reported a change")
+	}
+
+	same := &ViewAnalytics{Views: intPtr(5)}
+	if same.applyTo(meta) {
+		t.Error("unchanged view count reported a change")
+	}
+
+	fresh := &ViewAnalytics{Views: intPtr(9), UniqueViewers: intPtr(4)}
+	if !fresh.applyTo(meta) {
+		t.Fatal("new counts not reported as a change")
+	}
+	if *meta.Views != 9 || *meta.UniqueViewers != 4 {
+		t.Errorf("views=%d unique=%d, want 9/4", *meta.Views, *meta.UniqueViewers)
+	}
+	if meta.LastViewedAt != "2025-01-01T00:00:00Z" {
+		t.Errorf("LastViewedAt = %q, missing figure should leave it unchanged", meta.LastViewedAt)
+	}
+}
+
+func TestBuildScrapedMetadataAnalytics(t *testing.T) {
+	e := &Exporter{cfg: &Config{}}
+	scraped := &MeetingPageData{Analytics: &ViewAnalytics{Views: intPtr(42), LastViewedAt: "2025-02-02T12:00:00Z"}}
+
+	meta := e.buildScrapedMetadata(MeetingRef{ID: "m1"}, "https://grain.com/app/meetings/m1", scraped)
+	if meta.Views == nil || *meta.Views != 42 {
+		t.Errorf("Views = %v, want 42", meta.Views)
+	}
+	if meta.UniqueViewers != nil {
+		t.Errorf("UniqueViewers = %d, want nil (not shown on page)", *meta.UniqueViewers)
+	}
+	if meta.LastViewedAt != "2025-02-02T12:00:00Z" {
+		t.Errorf("LastViewedAt = %q", meta.LastViewedAt)
+	}
+}
+
+// ── --refresh-analytics ─────────────────────────────────────────────────────
+
+func TestExportOneRefreshAnalyticsKeepsMetadataOnScrapeFailure(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "m1", Title: "Kickoff", Views: intPtr(7)})
+
+	cfg := &Config{OutputDir: dir, SkipVideo: true, RefreshAnalytics: true, MaxDelaySec: 0.01}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+
+	// No browser in the test environment: the refresh fails softly and the
+	// existing metadata is left as it was.
+	r := e.exportOne(context.Background(), MeetingRef{ID: "m1", Date: "2025-01-15"})
+	if r.Status != "skipped" {
+		t.Errorf("status = %q, want skipped", r.Status)
+	}
+
+	meta, err := readArchiveMetadata(filepath.Join(dir, "2025-01-15", "m1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Title != "Kickoff" || meta.Views == nil || *meta.Views != 7 {
+		t.Errorf("metadata changed: title=%q views=%v", meta.Title, meta.Views)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+// ── Anki Deck ───────────────────────────────────────────────────────────────
+//
+// --anki-deck deck.txt turns the archive's highlights and action items into
+// flashcards in Anki's text import format (File → Import, or AnkiConnect's
+// importing tools): one Basic note per highlight, with the context on the
+// front and what was said on the back, and one per action item. The file is
+// rebuilt from the whole archive after every run. Each note carries a
+// stable GUID, so re-importing updates existing cards instead of adding
+// duplicates and keeps their review history.
+//
+// Native .apkg packages are SQLite databases; graindl has no SQLite driver
+// (rod is its only dependency), so it writes the text format instead.
+
+const (
+	ankiDeckName = "Grain"
+	ankiNoteType = "Basic"
+)
+
+// ankiCard is one Basic note.
+type ankiCard struct {
+	GUID  string
+	Front string // HTML
+	Back  string // HTML
+	Tags  []string
+}
+
+// validateAnkiDeckPath checks that path has a supported extension.
+func validateAnkiDeckPath(path string) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".txt", ".tsv", ".csv":
+		return nil
+	case ".apkg":
+		return fmt.Errorf("--anki-deck: .apkg is not supported (it needs SQLite); use a .txt, .tsv, or .csv file and import it in Anki")
+	}
+	return fmt.Errorf("--anki-deck: %q must end in .txt, .tsv, or .csv", path)
+}
+
+// ankiCards builds cards for every highlight and action item in entries.
+func ankiCards(entries []*ArchiveEntry, outputDir string) []ankiCard {
+	var cards []ankiCard
+	for _, e := range entries {
+		m := e.Meta
+		title := coalesce(m.Title, m.ID)
+		where := html.EscapeString(title) + " · " + e.Date().Format("Jan 2, 2006")
+		tags := ankiTags(m)
+
+		for i, c := range e.Highlights(outputDir) {
+			text := strings.TrimSpace(c.Text)
+			prompt := strings.TrimSpace(c.Title)
+			if text == "" {
+				text, prompt = prompt, ""
+			}
+			if text == "" {
+				continue
+			}
+			attr := formatTimestamp(c.StartSec)
+			if c.Speaker != "" {
+				attr = html.EscapeString(c.Speaker) + " @ " + attr
+			}
+			if prompt == "" || prompt == text {
+				prompt = "What was said here?"
+			}
+			back := ankiHTML(text)
+			if c.URL != "" {
+				back += `<br><br><a href="` + html.EscapeString(c.URL) + `">Watch clip</a>`
+			}
+			key := coalesce(c.ID, fmt.Sprintf("%d@%.0f", i, c.StartSec))
+			cards = append(cards, ankiCard{
+				GUID:  ankiGUID(m.ID, "highlight", key),
+				Front: ankiHTML(prompt) + "<br><br><small>" + where + " · " + attr + "</small>",
+				Back:  back,
+				Tags:  append([]string{"highlight"}, tags...),
+			})
+		}
+
+		for _, item := range m.ActionItems {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			cards = append(cards, ankiCard{
+				GUID:  ankiGUID(m.ID, "action", item),
+				Front: "Action item from<br><small>" + where + "</small>",
+				Back:  ankiHTML(item),
+				Tags:  append([]string{"action-item"}, tags...),
+			})
+		}
+	}
+	return cards
+}
+
+// ankiTags returns the tags shared by a meeting's cards. Anki tags cannot
+// contain spaces.
+func ankiTags(m *Metadata) []string {
+	tags := []string{"grain"}
+	for _, t := range flattenStringSlice(m.Tags) {
+		if t = strings.Join(strings.Fields(t), "-"); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// ankiGUID derives a stable note ID from the meeting and card key.
+func ankiGUID(meetingID, kind, key string) string {
+	sum := sha256.Sum256([]byte(meetingID + "\x00" + kind + "\x00" + key))
+	return "graindl-" + hex.EncodeToString(sum[:8])
+}
+
+func ankiHTML(s string) string {
+	return strings.ReplaceAll(html.EscapeString(s), "\n", "<br>")
+}
+
+// renderAnkiDeck writes cards in Anki's text import format. CSV paths use
+// commas, everything else tabs.
+func renderAnkiDeck(cards []ankiCard, path string) []byte {
+	sep, sepName := '\t', "tab"
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		sep, sepName = ',', "comma"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "#separator:%s\n#html:true\n#notetype:%s\n#deck:%s\n#guid column:1\n#tags column:4\n",
+		sepName, ankiNoteType, ankiDeckName)
+	w := csv.NewWriter(&b)
+	w.Comma = sep
+	for _, c := range cards {
+		w.Write([]string{c.GUID, c.Front, c.Back, strings.Join(c.Tags, " ")})
+	}
+	w.Flush()
+	return b.Bytes()
+}
+
+// writeAnkiDeck rebuilds the --anki-deck file from the archive.
+func (e *Exporter) writeAnkiDeck() {
+	if e.cfg.AnkiDeck == "" {
+		return
+	}
+	entries, err := scanArchive(e.cfg.dataDir())
+	if err != nil {
+		slog.Warn("Anki deck skipped", "error", err)
+		return
+	}
+	cards := ankiCards(entries, e.cfg.dataDir())
+	if err := writeFile(e.cfg.AnkiDeck, renderAnkiDeck(cards, e.cfg.AnkiDeck)); err != nil {
+		slog.Warn("Anki deck write failed", "path", e.cfg.AnkiDeck, "error", err)
+		return
+	}
+	slog.Info(fmt.Sprintf("Anki deck: %d card(s) → %s", len(cards), e.cfg.AnkiDeck))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readAnkiRows(t *testing.T, data []byte, comma rune) (header []string, rows [][]string) {
+	t.Helper()
+	var body []string
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(line, "#") {
+			header = append(header, strings.TrimSpace(line))
+		} else {
+			body = append(body, line)
+		}
+	}
+	r := csv.NewReader(strings.NewReader(strings.Join(body, "")))
+	r.Comma = comma
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("parse deck: %v", err)
+	}
+	return header, rows
+}
+
+func TestAnkiDeckFromArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-03-04", &Metadata{
+		ID: "m1", Title: "Acme <discovery>", Tags: []any{"sales call"},
+		ActionItems: []string{"Send pricing\nby Friday", " "},
+	})
+	clips := []HighlightClip{
+		{ID: "h1", Title: "Objection on price", Text: "It's too expensive, honestly.", Speaker: "Dana", StartSec: 75, URL: "https://grain.com/share/h1"},
+		{Text: "We need SSO first", StartSec: 130},
+		{Title: "", Text: ""},
+	}
+	data, _ := json.Marshal(clips)
+	os.WriteFile(filepath.Join(dir, "2025-03-04", "m1.highlights.json"), data, 0o600)
+
+	entries, err := scanArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards := ankiCards(entries, dir)
+	if len(cards) != 3 {
+		t.Fatalf("cards = %d, want 2 highlights + 1 action item", len(cards))
+	}
+
+	deck := renderAnkiDeck(cards, "deck.txt")
+	header, rows := readAnkiRows(t, deck, '\t')
+	for _, want := range []string{"#separator:tab", "#html:true", "#guid column:1", "#tags column:4"} {
+		if !strings.Contains(strings.Join(header, "\n"), want) {
+			t.Errorf("header missing %q: %q", want, header)
+		}
+	}
+	if len(rows) != 3 || len(rows[0]) != 4 {
+		t.Fatalf("rows = %q", rows)
+	}
+
+	h := rows[0]
+	if !strings.Contains(h[1], "Objection on price") || !strings.Contains(h[1], "Acme &lt;discovery&gt; · Mar 4, 2025 · Dana @ 00:01:15") {
+		t.Errorf("front = %q", h[1])
+	}
+	if !strings.Contains(h[2], "It&#39;s too expensive") || !strings.Contains(h[2], `href="https://grain.com/share/h1"`) {
+		t.Errorf("back = %q", h[2])
+	}
+	if h[3] != "highlight grain sales-call" {
+		t.Errorf("tags = %q", h[3])
+	}
+	if !strings.HasPrefix(rows[1][1], "What was said here?") {
+		t.Errorf("untitled highlight front = %q", rows[1][1])
+	}
+	if rows[2][2] != "Send pricing<br>by Friday" || !strings.HasPrefix(rows[2][3], "action-item") {
+		t.Errorf("action item row = %q", rows[2])
+	}
+
+	// GUIDs are stable across rebuilds so re-imports update cards.
+	again := ankiCards(entries, dir)
+	for i := range cards {
+		if cards[i].GUID != again[i].GUID {
+			t.Errorf("GUID %d changed: %s → %s", i, cards[i].GUID, again[i].GUID)
+		}
+	}
+	if cards[0].GUID == cards[1].GUID {
+		t.Error("GUIDs must differ between cards")
+	}
+
+	_, csvRows := readAnkiRows(t, renderAnkiDeck(cards, "deck.CSV"), ',')
+	if len(csvRows) != 3 {
+		t.Errorf("csv rows = %d", len(csvRows))
+	}
+}
+
+func TestValidateAnkiDeckPath(t *testing.T) {
+	for path, ok := range map[string]bool{
+		"deck.txt": true, "deck.TSV": true, "out/deck.csv": true,
+		"deck.apkg": false, "deck": false, "deck.json": false,
+	} {
+		if err := validateAnkiDeckPath(path); (err == nil) != ok {
+			t.Errorf("%s: err = %v, want ok=%v", path, err, ok)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ── Request Identification ──────────────────────────────────────────────────
+//
+// Enterprise proxies often drop traffic that does not identify the deployment
+// it comes from. --api-user-agent replaces the User-Agent and --api-header
+// adds "Name: value" headers on every request graindl sends towards Grain and
+// its CDNs: the browser's page loads and XHRs (set over CDP per page), direct
+// video downloads, native HLS downloads, and ffmpeg pulls of encrypted HLS
+// streams. Uploads to Drive, WebDAV, and alert webhooks go to other services
+// and are sent as before.
+
+// apiHeaderList is the repeatable --api-header flag.
+type apiHeaderList []string
+
+func (l *apiHeaderList) String() string { return strings.Join(*l, ", ") }
+
+func (l *apiHeaderList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// parseAPIHeaders parses "Name: value" entries into a header set. Names must
+// be HTTP tokens and values may not contain control characters, so a header
+// can never smuggle a second one into the request.
+func parseAPIHeaders(entries []string) (http.Header, error) {
+	h := http.Header{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || !validHeaderName(name) {
+			return nil, fmt.Errorf("invalid --api-header %q (want \"Name: value\")", entry)
+		}
+		if strings.ContainsFunc(value, func(r rune) bool { return r < ' ' && r != '\t' || r == 0x7f }) {
+			return nil, fmt.Errorf("invalid --api-header %q: control character in value", name)
+		}
+		switch strings.ToLower(name) {
+		case "host", "content-length", "transfer-encoding", "connection", "range", "cookie":
+			return nil, fmt.Errorf("--api-header %s is managed by graindl and cannot be set", name)
+		}
+		h.Add(name, value)
+	}
+	return h, nil
+}
+
+// validHeaderName reports whether name is a non-empty RFC 9110 token.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range []byte(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// hasAPIIdentity reports whether any request identification is configured.
+func hasAPIIdentity(cfg *Config) bool {
+	return cfg != nil && (cfg.APIUserAgent != "" || len(cfg.APIHeaders) > 0)
+}
+
+// identTransport adds the configured identification to each request.
+type identTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	headers   http.Header
+}
+
+func (t *identTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	if t.userAgent != "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withAPIIdentity wraps base (nil = http.DefaultTransport) so its requests
+// carry cfg's user agent and headers. base is returned unchanged when none
+// are configured.
+func withAPIIdentity(cfg *Config, base http.RoundTripper) http.RoundTripper {
+	if !hasAPIIdentity(cfg) {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &identTransport{base: base, userAgent: cfg.APIUserAgent, headers: cfg.APIHeaders}
+}
+
+// identifyPage applies cfg's user agent and headers to every request page
+// makes. Overrides are per target, so each new page needs its own call.
+func identifyPage(cfg *Config, page *rod.Page) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.APIUserAgent != "" {
+		if err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{UserAgent: cfg.APIUserAgent}); err != nil {
+			return fmt.Errorf("user agent override: %w", err)
+		}
+	}
+	if len(cfg.APIHeaders) > 0 {
+		var dict []string
+		for _, name := range sortedHeaderNames(cfg.APIHeaders) {
+			dict = append(dict, name, strings.Join(cfg.APIHeaders[name], ", "))
+		}
+		if _, err := page.SetExtraHeaders(dict); err != nil {
+			return fmt.Errorf("extra headers: %w", err)
+		}
+	}
+	return nil
+}
+
+// ffmpegIdentityArgs returns the ffmpeg input options that send cfg's user
+// agent and headers with an HTTP input. They go before its -i.
+func ffmpegIdentityArgs(cfg *Config) []string {
+	if !hasAPIIdentity(cfg) {
+		return nil
+	}
+	var args []string
+	if cfg.APIUserAgent != "" {
+		args = append(args, "-user_agent", cfg.APIUserAgent)
+	}
+	if len(cfg.APIHeaders) > 0 {
+		var b strings.Builder
+		for _, name := range sortedHeaderNames(cfg.APIHeaders) {
+			for _, v := range cfg.APIHeaders[name] {
+				fmt.Fprintf(&b, "%s: %s\r\n", name, v)
+			}
+		}
+		args = append(args, "-headers", b.String())
+	}
+	return args
+}
+
+func sortedHeaderNames(h http.Header) []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
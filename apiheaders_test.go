@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseAPIHeaders(t *testing.T) {
+	h, err := parseAPIHeaders([]string{"X-Org: foo", " x-org : bar ", "X-Client-Id:abc:123", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(h.Values("X-Org"), ","); got != "foo,bar" {
+		t.Errorf("X-Org = %q", got)
+	}
+	if got := h.Get("X-Client-Id"); got != "abc:123" {
+		t.Errorf("X-Client-Id = %q", got)
+	}
+
+	for _, bad := range []string{"X-Org", ": foo", "X Org: foo", "X-Org: foo\r\nX-Evil: 1", "Host: grain.com", "cookie: a=b"} {
+		if _, err := parseAPIHeaders([]string{bad}); err == nil {
+			t.Errorf("parseAPIHeaders(%q) accepted", bad)
+		}
+	}
+}
+
+func TestWithAPIIdentity(t *testing.T) {
+	if rt := withAPIIdentity(&Config{}, http.DefaultTransport); rt != http.DefaultTransport {
+		t.Error("transport wrapped without identification")
+	}
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Write([]byte("video"))
+	}))
+	defer srv.Close()
+
+	cfg := &Config{APIUserAgent: "graindl-acme/1.0", APIHeaders: http.Header{"X-Org": {"acme"}}}
+	d := newVideoDownloader(nil, nil)
+	d.client.Transport = withAPIIdentity(cfg, d.client.Transport)
+	if _, err := d.Download(t.Context(), srv.URL+"/v.mp4", filepath.Join(t.TempDir(), "v.mp4")); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("User-Agent") != "graindl-acme/1.0" || got.Get("X-Org") != "acme" {
+		t.Errorf("request headers = %v", got)
+	}
+
+	args := strings.Join(ffmpegIdentityArgs(cfg), " ")
+	if args != "-user_agent graindl-acme/1.0 -headers X-Org: acme\r\n" {
+		t.Errorf("ffmpeg args = %q", args)
+	}
+}
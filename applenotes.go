@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ── Apple Notes ─────────────────────────────────────────────────────────────
+//
+// On macOS, --apple-notes pushes each meeting's formatted markdown note
+// (--output-format) into Apple Notes. By default it drives Notes.app with
+// osascript: the note is created in --apple-notes-folder, or updated in
+// place when a note with the same name is already there, so re-exports do
+// not pile up duplicates. With --apple-notes-shortcut, the markdown file is
+// handed to a user-defined Shortcut instead (`shortcuts run`), which can
+// file it wherever the team's knowledge base lives.
+//
+// Note content never appears in a command line: the body is written to a
+// private temp file and read from AppleScript, and the folder and title are
+// passed as script arguments rather than spliced into the source.
+
+// appleNotesDefaultFolder is the Notes folder used when none is configured.
+const appleNotesDefaultFolder = "Grain"
+
+// appleNotesScript creates or updates one note. argv: folder, title, path to
+// a UTF-8 HTML body file.
+const appleNotesScript = `on run argv
+	set folderName to item 1 of argv
+	set noteTitle to item 2 of argv
+	set noteBody to read (POSIX file (item 3 of argv)) as «class utf8»
+	tell application "Notes"
+		if not (exists folder folderName) then make new folder with properties {name:folderName}
+		set targetFolder to folder folderName
+		set existing to (notes of targetFolder whose name is noteTitle)
+		if (count of existing) > 0 then
+			set body of item 1 of existing to noteBody
+		else
+			make new note at targetFolder with properties {name:noteTitle, body:noteBody}
+		end if
+	end tell
+end run`
+
+// AppleNotes pushes markdown notes into Apple Notes.
+type AppleNotes struct {
+	folder   string
+	shortcut string
+	// run executes an external command; replaced in tests.
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// NewAppleNotes returns a pusher for cfg, or an error off macOS or when the
+// required command-line tool is missing.
+func NewAppleNotes(cfg *Config) (*AppleNotes, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("--apple-notes is only supported on macOS")
+	}
+	tool := "osascript"
+	if cfg.AppleNotesShortcut != "" {
+		tool = "shortcuts"
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return nil, fmt.Errorf("%s not found in PATH (required for --apple-notes): %w", tool, err)
+	}
+	return newAppleNotes(cfg), nil
+}
+
+func newAppleNotes(cfg *Config) *AppleNotes {
+	return &AppleNotes{
+		folder:   coalesce(cfg.AppleNotesFolder, appleNotesDefaultFolder),
+		shortcut: cfg.AppleNotesShortcut,
+		run:      runQuiet,
+	}
+}
+
+// Push creates or updates the note for meta from the markdown file at
+// mdPath.
+func (n *AppleNotes) Push(ctx context.Context, meta *Metadata, mdPath string) error {
+	if n.shortcut != "" {
+		if err := n.run(ctx, "shortcuts", "run", n.shortcut, "--input-path", mdPath); err != nil {
+			return fmt.Errorf("shortcut %q: %w", n.shortcut, err)
+		}
+		return nil
+	}
+
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		return fmt.Errorf("read note: %w", err)
+	}
+	tmp, err := os.CreateTemp("", "graindl-note-*.html")
+	if err != nil {
+		return fmt.Errorf("note temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(markdownToNotesHTML(string(md)))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("note temp file: %w", err)
+	}
+
+	if err := n.run(ctx, "osascript", "-e", appleNotesScript, n.folder, appleNoteTitle(meta), tmp.Name()); err != nil {
+		return fmt.Errorf("osascript: %w", err)
+	}
+	return nil
+}
+
+// appleNoteTitle names a meeting's note. The date prefix keeps recurring
+// meetings ("Weekly sync") from overwriting each other's notes.
+func appleNoteTitle(meta *Metadata) string {
+	title := coalesce(meta.Title, meta.ID)
+	if len(meta.Date) >= 10 {
+		return dateFromISO(meta.Date) + " " + title
+	}
+	return title
+}
+
+// markdownToNotesHTML converts a formatted markdown note into the small HTML
+// subset Notes understands: headings, bullet lists, and one <div> per line.
+// YAML frontmatter is dropped: Notes would show it as plain text.
+func markdownToNotesHTML(md string) string {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	if strings.HasPrefix(md, "---\n") {
+		if end := strings.Index(md[4:], "\n---\n"); end >= 0 {
+			md = md[4+end+5:]
+		}
+	}
+
+	var b strings.Builder
+	inList := false
+	for _, line := range strings.Split(strings.TrimSpace(md), "\n") {
+		item, isItem := strings.CutPrefix(line, "- ")
+		if isItem != inList {
+			if isItem {
+				b.WriteString("<ul>")
+			} else {
+				b.WriteString("</ul>")
+			}
+			inList = isItem
+		}
+		switch {
+		case isItem:
+			b.WriteString("<li>" + html.EscapeString(item) + "</li>")
+		case strings.HasPrefix(line, "### "):
+			b.WriteString("<h3>" + html.EscapeString(line[4:]) + "</h3>")
+		case strings.HasPrefix(line, "## "):
+			b.WriteString("<h2>" + html.EscapeString(line[3:]) + "</h2>")
+		case strings.HasPrefix(line, "# "):
+			b.WriteString("<h1>" + html.EscapeString(line[2:]) + "</h1>")
+		case strings.TrimSpace(line) == "":
+			b.WriteString("<div><br></div>")
+		default:
+			b.WriteString("<div>" + html.EscapeString(line) + "</div>")
+		}
+	}
+	if inList {
+		b.WriteString("</ul>")
+	}
+	return b.String()
+}
+
+// runQuiet runs an external command, returning its stderr in the error.
+func runQuiet(ctx context.Context, name string, args ...string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// pushAppleNote sends the meeting's markdown note to Apple Notes unless rc
+// shows this version was pushed already. Failures are logged and leave the
+// export itself intact.
+func (e *Exporter) pushAppleNote(ctx context.Context, meta *Metadata, r *ExportResult, rc *ExportReceipt) {
+	if e.notes == nil || r.MarkdownPath == "" {
+		return
+	}
+	note := []string{r.MarkdownPath}
+	if len(rc.pending(deliveryAppleNotes, note)) == 0 {
+		r.AppleNotes = true
+		slog.Debug("Apple Notes already has this note", "id", meta.ID)
+		return
+	}
+	if err := e.notes.Push(ctx, meta, filepath.Clean(e.storage.AbsPath(r.MarkdownPath))); err != nil {
+		slog.Warn("Apple Notes push failed", "id", meta.ID, "error", err)
+		rc.failed(deliveryAppleNotes, err)
+		return
+	}
+	rc.delivered(deliveryAppleNotes, note)
+	r.AppleNotes = true
+	slog.Debug("Pushed to Apple Notes", "id", meta.ID)
+}
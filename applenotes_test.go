@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// recordRun returns a run func that captures its invocation and, for
+// osascript, the HTML body file it was given.
+func recordRun(calls *[][]string, body *string) func(context.Context, string, ...string) error {
+	return func(_ context.Context, name string, args ...string) error {
+		*calls = append(*calls, append([]string{name}, args...))
+		if name == "osascript" {
+			data, err := os.ReadFile(args[len(args)-1])
+			if err != nil {
+				return err
+			}
+			*body = string(data)
+		}
+		return nil
+	}
+}
+
+func TestMarkdownToNotesHTML(t *testing.T) {
+	md := "---\ntitle: Sync\ngrain_id: m1\n---\n\n# Sync <Q1>\n\n## Highlights\n\n- one & two\n- three\nplain line\n"
+	got := markdownToNotesHTML(md)
+	want := "<h1>Sync &lt;Q1&gt;</h1><div><br></div><h2>Highlights</h2><div><br></div>" +
+		"<ul><li>one &amp; two</li><li>three</li></ul><div>plain line</div>"
+	if got != want {
+		t.Errorf("markdownToNotesHTML =\n%s\nwant\n%s", got, want)
+	}
+	if got := markdownToNotesHTML("- a\n- b"); got != "<ul><li>a</li><li>b</li></ul>" {
+		t.Errorf("trailing list not closed: %s", got)
+	}
+}
+
+func TestAppleNoteTitle(t *testing.T) {
+	if got := appleNoteTitle(&Metadata{ID: "m1", Title: "Weekly sync", Date: "2025-01-15T10:00:00Z"}); got != "2025-01-15 Weekly sync" {
+		t.Errorf("title = %q", got)
+	}
+	if got := appleNoteTitle(&Metadata{ID: "m1"}); got != "m1" {
+		t.Errorf("title without date/title = %q", got)
+	}
+}
+
+func TestAppleNotesPushOsascript(t *testing.T) {
+	md := filepath.Join(t.TempDir(), "note.md")
+	if err := os.WriteFile(md, []byte("# Sync\n\nhello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var calls [][]string
+	var body string
+	n := newAppleNotes(&Config{AppleNotesFolder: "Team"})
+	n.run = recordRun(&calls, &body)
+
+	meta := &Metadata{ID: "m1", Title: `Sync "quoted" end tell`, Date: "2025-01-15"}
+	if err := n.Push(context.Background(), meta, md); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("calls = %v", calls)
+	}
+	args := calls[0]
+	if args[0] != "osascript" || args[2] != appleNotesScript {
+		t.Errorf("command = %v", args[:2])
+	}
+	// Folder and title are script arguments, never part of the source.
+	if args[3] != "Team" || args[4] != `2025-01-15 Sync "quoted" end tell` {
+		t.Errorf("argv = %q", args[3:])
+	}
+	if body != "<h1>Sync</h1><div><br></div><div>hello</div>" {
+		t.Errorf("body = %q", body)
+	}
+	if _, err := os.Stat(args[5]); !os.IsNotExist(err) {
+		t.Error("temp body file not removed")
+	}
+}
+
+func TestAppleNotesPushShortcut(t *testing.T) {
+	var calls [][]string
+	var body string
+	n := newAppleNotes(&Config{AppleNotesShortcut: "Save Meeting"})
+	n.run = recordRun(&calls, &body)
+
+	if err := n.Push(context.Background(), &Metadata{ID: "m1"}, "/out/note.md"); err != nil {
+		t.Fatal(err)
+	}
+	want := "shortcuts run Save Meeting --input-path /out/note.md"
+	if len(calls) != 1 || strings.Join(calls[0], " ") != want {
+		t.Errorf("calls = %v, want %q", calls, want)
+	}
+
+	n.run = func(context.Context, string, ...string) error { return errors.New("exit status 1") }
+	if err := n.Push(context.Background(), &Metadata{ID: "m1"}, "/out/note.md"); err == nil || !strings.Contains(err.Error(), "Save Meeting") {
+		t.Errorf("err = %v, want shortcut name in error", err)
+	}
+}
+
+func TestAppleNotesDefaultFolder(t *testing.T) {
+	if n := newAppleNotes(&Config{}); n.folder != appleNotesDefaultFolder {
+		t.Errorf("folder = %q, want %q", n.folder, appleNotesDefaultFolder)
+	}
+}
+
+func TestNewAppleNotesRequiresMacOS(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("macOS")
+	}
+	if _, err := NewAppleNotes(&Config{AppleNotes: true}); err == nil {
+		t.Error("expected error off macOS")
+	}
+}
+
+func TestExporterPushAppleNote(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, SkipVideo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+
+	var calls [][]string
+	var body string
+	e.notes = newAppleNotes(&Config{})
+	e.notes.run = recordRun(&calls, &body)
+
+	meta := &Metadata{ID: "m1", Title: "Sync", Date: "2025-01-15"}
+	r := &ExportResult{ID: "m1"}
+	e.pushAppleNote(context.Background(), meta, r, nil)
+	if len(calls) != 0 || r.AppleNotes {
+		t.Error("pushed without a markdown note")
+	}
+
+	e.cfg.OutputFormat = "obsidian"
+	e.writeFormattedMarkdown(meta, "", "2025-01-15/m1", r)
+	e.pushAppleNote(context.Background(), meta, r, nil)
+	if !r.AppleNotes || len(calls) != 1 || !strings.Contains(body, "<h1>Sync</h1>") {
+		t.Errorf("AppleNotes=%v calls=%d body=%q", r.AppleNotes, len(calls), body)
+	}
+}
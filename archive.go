@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Archive Scanning ────────────────────────────────────────────────────────
+//
+// Offline subcommands (digest, stats, ...) work from what's already on disk
+// rather than the browser. scanArchive walks <output>/<date>/<id>.json
+// metadata files written by exportOne.
+
+// ArchiveEntry is one exported meeting found on disk.
+type ArchiveEntry struct {
+	DateDir  string    // "2024-11-15" (or "unknown-date", or "shared/2024-11-15")
+	RelBase  string    // "2024-11-15/<id>", the prefix shared by all artifacts
+	Meta     *Metadata // parsed <RelBase>.json
+	Modified time.Time // metadata file mtime
+}
+
+// Date returns the meeting date: the date directory when it parses,
+// otherwise the metadata date, otherwise the metadata file mtime.
+func (a *ArchiveEntry) Date() time.Time {
+	if t, err := time.Parse("2006-01-02", filepath.Base(a.DateDir)); err == nil {
+		return t
+	}
+	if a.Meta != nil {
+		if t, err := time.Parse("2006-01-02", dateFromISO(a.Meta.Date)); err == nil {
+			return t
+		}
+	}
+	return a.Modified
+}
+
+// Highlights loads the sibling <RelBase>.highlights.json, if present.
+func (a *ArchiveEntry) Highlights(outputDir string) []HighlightClip {
+	data, err := readArtifact(filepath.Join(outputDir, a.RelBase+".highlights.json"))
+	if err != nil {
+		return nil
+	}
+	var clips []HighlightClip
+	if json.Unmarshal(data, &clips) != nil {
+		return nil
+	}
+	return clips
+}
+
+// Transcript loads the sibling <RelBase>.transcript.txt, if present.
+func (a *ArchiveEntry) Transcript(outputDir string) string {
+	data, err := readArtifact(filepath.Join(outputDir, a.RelBase+".transcript.txt"))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// scanArchive returns every exported meeting under outputDir, sorted by date
+// then ID. Unreadable metadata files are skipped.
+func scanArchive(outputDir string) ([]*ArchiveEntry, error) {
+	dirs, err := archiveDirs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*ArchiveEntry
+	for _, d := range dirs {
+		files, err := os.ReadDir(filepath.Join(outputDir, d))
+		if err != nil {
+			continue
+		}
+		seen := map[string]bool{}
+		for _, f := range files {
+			name := trimCompressed(f.Name())
+			if f.IsDir() || filepath.Ext(name) != ".json" || classifyContent(name) != "metadata" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			relPath := filepath.Join(d, name)
+			meta, err := readArchiveMetadata(filepath.Join(outputDir, relPath))
+			if err != nil || meta.ID == "" {
+				continue
+			}
+			var mod time.Time
+			if info, err := f.Info(); err == nil {
+				mod = info.ModTime()
+			}
+			entries = append(entries, &ArchiveEntry{
+				DateDir:  d,
+				RelBase:  strings.TrimSuffix(relPath, ".json"),
+				Meta:     meta,
+				Modified: mod,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		di, dj := entries[i].Date(), entries[j].Date()
+		if !di.Equal(dj) {
+			return di.Before(dj)
+		}
+		return entries[i].Meta.ID < entries[j].Meta.ID
+	})
+	return entries, nil
+}
+
+// archiveDirs lists the meeting directories under outputDir, relative to it:
+// each <date>/ and, with --shared-subdir archives, each shared/<date>/.
+// Hidden and underscore-prefixed directories (_claims, _gc, ...) are skipped.
+func archiveDirs(outputDir string) ([]string, error) {
+	top, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("read output dir: %w", err)
+	}
+	var dirs []string
+	for _, d := range top {
+		if !d.IsDir() || strings.HasPrefix(d.Name(), "_") || strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+		if d.Name() != sharedDir {
+			dirs = append(dirs, d.Name())
+			continue
+		}
+		sub, _ := os.ReadDir(filepath.Join(outputDir, sharedDir))
+		for _, sd := range sub {
+			if sd.IsDir() && !strings.HasPrefix(sd.Name(), "_") && !strings.HasPrefix(sd.Name(), ".") {
+				dirs = append(dirs, filepath.Join(sharedDir, sd.Name()))
+			}
+		}
+	}
+	return dirs, nil
+}
+
+// readArchiveMetadata parses the metadata at path, or at its compressed
+// form (see readArtifact).
+func readArchiveMetadata(path string) (*Metadata, error) {
+	data, err := readArtifact(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// filterSince keeps entries dated on or after since (zero = keep all).
+func filterSince(entries []*ArchiveEntry, since time.Time) []*ArchiveEntry {
+	if since.IsZero() {
+		return entries
+	}
+	var out []*ArchiveEntry
+	for _, e := range entries {
+		if !e.Date().Before(since) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ── Relative Time Parsing ───────────────────────────────────────────────────
+
+var sinceRe = regexp.MustCompile(`^(\d+)([dw])$`)
+
+// parseSince converts a --since value into an absolute cutoff relative to
+// now. Accepts "7d", "2w", Go durations ("36h"), or a date ("2024-11-01").
+// Day-based values are truncated to local midnight so "1d" means
+// "yesterday and today".
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if m := sinceRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		y, mo, d := now.Date()
+		return time.Date(y, mo, d, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -n), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use e.g. 7d, 2w, 36h, or 2024-11-01)", s)
+}
+
+// ── Duration Parsing ────────────────────────────────────────────────────────
+
+var (
+	clockDurRe = regexp.MustCompile(`^(?:(\d+):)?(\d{1,2}):(\d{2})$`)
+	unitDurRe  = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(h|hr|hrs|hours?|m|min|mins|minutes?|s|sec|secs|seconds?)\b`)
+)
+
+// durationSeconds interprets Metadata.DurationSeconds, which is numeric when
+// it came from an API and free text ("45:12", "1h 2m", "62 min") when
+// scraped. Returns 0 when unknown.
+func durationSeconds(v any) float64 {
+	switch d := v.(type) {
+	case float64:
+		return d
+	case int:
+		return float64(d)
+	case int64:
+		return float64(d)
+	case string:
+		s := strings.ToLower(strings.TrimSpace(d))
+		if s == "" {
+			return 0
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+		if m := clockDurRe.FindStringSubmatch(s); m != nil {
+			h, _ := strconv.Atoi(m[1])
+			mi, _ := strconv.Atoi(m[2])
+			se, _ := strconv.Atoi(m[3])
+			return float64(h*3600 + mi*60 + se)
+		}
+		var total float64
+		for _, m := range unitDurRe.FindAllStringSubmatch(s, -1) {
+			n, _ := strconv.ParseFloat(m[1], 64)
+			switch m[2][0] {
+			case 'h':
+				total += n * 3600
+			case 'm':
+				total += n * 60
+			case 's':
+				total += n
+			}
+		}
+		return total
+	}
+	return 0
+}
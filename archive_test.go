@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeArchiveMeta writes <dir>/<date>/<id>.json like exportOne does.
+func writeArchiveMeta(t *testing.T, dir, date string, meta *Metadata) {
+	t.Helper()
+	sub := filepath.Join(dir, date)
+	if err := ensureDirPrivate(sub); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(meta)
+	if err := os.WriteFile(filepath.Join(sub, meta.ID+".json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ── scanArchive ─────────────────────────────────────────────────────────────
+
+func TestScanArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-16", &Metadata{ID: "b", Title: "Second"})
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "a", Title: "First"})
+	writeArchiveMeta(t, dir, "2025-01-16", &Metadata{ID: "c", Title: "Third"})
+
+	// Sidecars and internal dirs must be ignored.
+	_ = os.WriteFile(filepath.Join(dir, "2025-01-15", "a.highlights.json"), []byte(`[]`), 0o600)
+	_ = os.WriteFile(filepath.Join(dir, "2025-01-15", "junk.json"), []byte(`{not json`), 0o600)
+	writeArchiveMeta(t, dir, "_claims", &Metadata{ID: "x"})
+	_ = os.WriteFile(filepath.Join(dir, "_export-manifest.json"), []byte(`{}`), 0o600)
+
+	entries, err := scanArchive(dir)
+	if err != nil {
+		t.Fatalf("scanArchive: %v", err)
+	}
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.Meta.ID)
+	}
+	if got, want := len(ids), 3; got != want {
+		t.Fatalf("entries = %v, want 3", ids)
+	}
+	for i, want := range []string{"a", "b", "c"} {
+		if ids[i] != want {
+			t.Errorf("entries[%d] = %q, want %q", i, ids[i], want)
+		}
+	}
+	if got := entries[0].RelBase; got != filepath.Join("2025-01-15", "a") {
+		t.Errorf("RelBase = %q", got)
+	}
+}
+
+func TestScanArchiveMissingDir(t *testing.T) {
+	if _, err := scanArchive(filepath.Join(t.TempDir(), "nope")); err == nil {
+		t.Error("expected error for missing output dir")
+	}
+}
+
+func TestArchiveEntryHighlights(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "a"})
+	clips := []HighlightClip{{Text: "hello", StartSec: 5}}
+	data, _ := json.Marshal(clips)
+	_ = os.WriteFile(filepath.Join(dir, "2025-01-15", "a.highlights.json"), data, 0o600)
+
+	entries, _ := scanArchive(dir)
+	got := entries[0].Highlights(dir)
+	if len(got) != 1 || got[0].Text != "hello" {
+		t.Errorf("Highlights = %+v", got)
+	}
+}
+
+func TestFilterSince(t *testing.T) {
+	entries := []*ArchiveEntry{
+		{DateDir: "2025-01-10", Meta: &Metadata{ID: "old"}},
+		{DateDir: "2025-01-15", Meta: &Metadata{ID: "edge"}},
+		{DateDir: "2025-01-20", Meta: &Metadata{ID: "new"}},
+	}
+	got := filterSince(entries, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+	if len(got) != 2 || got[0].Meta.ID != "edge" || got[1].Meta.ID != "new" {
+		t.Errorf("filterSince kept %d entries", len(got))
+	}
+	if got := filterSince(entries, time.Time{}); len(got) != 3 {
+		t.Errorf("zero cutoff should keep all, got %d", len(got))
+	}
+}
+
+// ── parseSince ──────────────────────────────────────────────────────────────
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 1, 20, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"7d", time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"2w", time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"2025-01-01", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("parseSince(%q) error: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"soon", "-5h", "7x"} {
+		if _, err := parseSince(bad, now); err == nil {
+			t.Errorf("parseSince(%q) should fail", bad)
+		}
+	}
+}
+
+// ── durationSeconds ─────────────────────────────────────────────────────────
+
+func TestDurationSeconds(t *testing.T) {
+	tests := []struct {
+		in   any
+		want float64
+	}{
+		{nil, 0},
+		{float64(90), 90},
+		{3600, 3600},
+		{"125", 125},
+		{"45:12", 45*60 + 12},
+		{"1:02:03", 3723},
+		{"1h 2m", 3720},
+		{"62 min", 3720},
+		{"30 seconds", 30},
+		{"unknown", 0},
+	}
+	for _, tt := range tests {
+		if got := durationSeconds(tt.in); got != tt.want {
+			t.Errorf("durationSeconds(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
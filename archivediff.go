@@ -0,0 +1,328 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// ── Archive Diff ────────────────────────────────────────────────────────────
+//
+// `graindl diff --baseline <dir>` compares the archive at --output with an
+// older snapshot of it, or with an archive exported on another machine, and
+// lists the meetings that are new, removed, or changed. Meetings are matched
+// by Grain ID, so a meeting filed under another date directory is the same
+// meeting. Each meeting's artifacts are matched by kind (mp4, transcript.txt,
+// ...) and compared by SHA-256. Compressed sidecars are hashed decompressed,
+// so an archive written with --compress compares equal to one without.
+// Markdown notes are matched by file name, so a note renamed under
+// --slug-style shows as one removed and one added. Uncompressed files reuse
+// the hash in the archive's checksum state (see integrity.go) when the file
+// hasn't changed since it was recorded; --rehash hashes everything.
+
+// archiveMeeting is one meeting's artifacts in an archive being diffed.
+type archiveMeeting struct {
+	ID        string
+	Title     string
+	RelBase   string
+	Artifacts map[string]string // artifact kind or note file name → SHA-256
+}
+
+// DiffEntry is one meeting that differs between the two archives.
+type DiffEntry struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Path         string   `json:"path"`                    // <date>/<id> in the archive that has it (the output dir if both do)
+	BaselinePath string   `json:"baseline_path,omitempty"` // set when the baseline files it under another path
+	Added        []string `json:"added,omitempty"`         // artifacts only the output dir has
+	Removed      []string `json:"removed,omitempty"`       // artifacts only the baseline has
+	Changed      []string `json:"changed,omitempty"`       // artifacts whose content differs
+}
+
+// ArchiveDiff is the `graindl diff` report. It is also the --format json
+// output.
+type ArchiveDiff struct {
+	Meetings         int         `json:"meetings"`          // in the output dir
+	BaselineMeetings int         `json:"baseline_meetings"` // in the baseline
+	New              []DiffEntry `json:"new"`
+	Removed          []DiffEntry `json:"removed"`
+	Changed          []DiffEntry `json:"changed"`
+	Identical        int         `json:"identical"`
+}
+
+func runDiff(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to compare")
+	baseline := fs.String("baseline", "", "Older archive (or another machine's) to compare against")
+	format := fs.String("format", "text", "Output format: text (differences and a summary), json")
+	rehash := fs.Bool("rehash", false, "Hash every file instead of reusing recorded checksums")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	asJSON := false
+	switch strings.ToLower(*format) {
+	case "text":
+	case "json":
+		asJSON = true
+	default:
+		slog.Error("Invalid --format (must be text or json)", "value", *format)
+		return 2
+	}
+	if *baseline == "" {
+		slog.Error("--baseline is required")
+		return 2
+	}
+	for _, dir := range []string{*outputDir, *baseline} {
+		if _, err := os.Stat(dir); err != nil {
+			slog.Error("Archive directory not found", "path", dir)
+			return 1
+		}
+		if err := checkUnrouted("diff", dir); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+	}
+	if a, b := absPath(*outputDir), absPath(*baseline); a == b {
+		slog.Error("--baseline is the output directory", "path", a)
+		return 2
+	}
+
+	current, err := snapshotArchive(*outputDir, *rehash)
+	if err != nil {
+		slog.Error("diff failed", "error", err)
+		return 1
+	}
+	base, err := snapshotArchive(*baseline, *rehash)
+	if err != nil {
+		slog.Error("diff failed", "error", err)
+		return 1
+	}
+	d := diffArchives(current, base)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(d)
+	} else {
+		err = writeDiffText(os.Stdout, d)
+	}
+	if err != nil {
+		slog.Error("diff failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// snapshotArchive hashes the artifacts of every meeting under dir, keyed by
+// meeting ID. When a meeting was exported under more than one date, the
+// newest copy is used.
+func snapshotArchive(dir string, rehash bool) (map[string]*archiveMeeting, error) {
+	entries, err := scanArchive(dir)
+	if err != nil {
+		return nil, err
+	}
+	checksumMu.Lock()
+	sums, err := loadChecksums(dir)
+	checksumMu.Unlock()
+	if err != nil {
+		slog.Warn("Unreadable checksum state; hashing every file", "dir", dir, "error", err)
+		sums = &ChecksumState{Files: map[string]*FileChecksum{}}
+	}
+
+	// Which files belong to which meeting, one date directory at a time.
+	owners := map[string]map[string]string{} // date dir → rel path → meeting ID
+	for _, e := range entries {
+		if owners[e.DateDir] == nil {
+			owners[e.DateDir] = meetingFiles(dir, e.DateDir)
+		}
+	}
+
+	meetings := map[string]*archiveMeeting{}
+	for _, e := range entries {
+		m := &archiveMeeting{ID: e.Meta.ID, Title: e.Meta.Title, RelBase: e.RelBase, Artifacts: map[string]string{}}
+		base := filepath.Base(e.RelBase)
+		for rel, id := range owners[e.DateDir] {
+			if id != m.ID {
+				continue
+			}
+			name := filepath.Base(rel)
+			kind := name
+			if suffix := artifactSuffix(name); strings.TrimSuffix(name, suffix) == base {
+				kind = strings.TrimPrefix(trimCompressed(suffix), ".")
+			}
+			if _, dup := m.Artifacts[kind]; dup {
+				continue // plain and compressed copies of one sidecar
+			}
+			sum, err := artifactHash(dir, rel, sums, rehash)
+			if err != nil {
+				return nil, fmt.Errorf("hash %s: %w", filepath.Join(dir, rel), err)
+			}
+			m.Artifacts[kind] = sum
+		}
+		slog.Debug("Hashed meeting", "dir", dir, "id", m.ID, "artifacts", len(m.Artifacts))
+		meetings[m.ID] = m // entries are sorted by date, so the newest wins
+	}
+	return meetings, nil
+}
+
+// meetingFiles maps each graindl artifact in a date directory to the ID of
+// its meeting: the ID in its <id>.json metadata, or a note's grain_id
+// frontmatter. Partial downloads and files of no meeting are left out.
+func meetingFiles(dir, dateDir string) map[string]string {
+	files, err := os.ReadDir(filepath.Join(dir, dateDir))
+	if err != nil {
+		return nil
+	}
+	anchors := map[string]string{} // <id> base → meeting ID
+	for _, f := range files {
+		name := trimCompressed(f.Name())
+		if f.IsDir() || filepath.Ext(name) != ".json" || classifyContent(name) != "metadata" {
+			continue
+		}
+		if meta, err := readArchiveMetadata(filepath.Join(dir, dateDir, f.Name())); err == nil && meta.ID != "" {
+			anchors[strings.TrimSuffix(name, ".json")] = meta.ID
+		}
+	}
+
+	owners := map[string]string{}
+	for _, f := range files {
+		suffix := artifactSuffix(f.Name())
+		if f.IsDir() || suffix == "" || strings.HasSuffix(suffix, ".part") {
+			continue
+		}
+		rel := filepath.Join(dateDir, f.Name())
+		id := anchors[strings.TrimSuffix(f.Name(), suffix)]
+		if suffix == ".md" {
+			if noteID := noteGrainID(filepath.Join(dir, rel)); noteID != "" {
+				id = noteID
+			}
+		}
+		if id != "" {
+			owners[rel] = id
+		}
+	}
+	return owners
+}
+
+// artifactHash returns the SHA-256 of the artifact at rel under dir.
+// Compressed files are hashed decompressed. An uncompressed file unchanged
+// since the checksum state recorded it keeps the recorded hash.
+func artifactHash(dir, rel string, sums *ChecksumState, rehash bool) (string, error) {
+	path := filepath.Join(dir, rel)
+	if compressionExt(rel) != "" {
+		data, err := readArtifact(path)
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	if !rehash {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		if c := sums.Files[filepath.Clean(rel)]; c != nil && c.Size == info.Size() && !info.ModTime().After(c.HashedAt) {
+			return c.SHA256, nil
+		}
+	}
+	sum, _, err := hashFile(path)
+	return sum, err
+}
+
+// diffArchives compares two snapshots by meeting ID. Each list is sorted by
+// path.
+func diffArchives(current, baseline map[string]*archiveMeeting) *ArchiveDiff {
+	d := &ArchiveDiff{
+		Meetings:         len(current),
+		BaselineMeetings: len(baseline),
+		New:              []DiffEntry{},
+		Removed:          []DiffEntry{},
+		Changed:          []DiffEntry{},
+	}
+	for _, id := range slices.Sorted(maps.Keys(current)) {
+		cur := current[id]
+		old, ok := baseline[id]
+		if !ok {
+			d.New = append(d.New, DiffEntry{ID: id, Title: cur.Title, Path: filepath.ToSlash(cur.RelBase)})
+			continue
+		}
+		e := DiffEntry{ID: id, Title: cur.Title, Path: filepath.ToSlash(cur.RelBase)}
+		if cur.RelBase != old.RelBase {
+			e.BaselinePath = filepath.ToSlash(old.RelBase)
+		}
+		for _, kind := range slices.Sorted(maps.Keys(cur.Artifacts)) {
+			switch sum, had := old.Artifacts[kind]; {
+			case !had:
+				e.Added = append(e.Added, kind)
+			case sum != cur.Artifacts[kind]:
+				e.Changed = append(e.Changed, kind)
+			}
+		}
+		for _, kind := range slices.Sorted(maps.Keys(old.Artifacts)) {
+			if _, has := cur.Artifacts[kind]; !has {
+				e.Removed = append(e.Removed, kind)
+			}
+		}
+		if e.BaselinePath == "" && len(e.Added)+len(e.Removed)+len(e.Changed) == 0 {
+			d.Identical++
+			continue
+		}
+		d.Changed = append(d.Changed, e)
+	}
+	for _, id := range slices.Sorted(maps.Keys(baseline)) {
+		if _, ok := current[id]; !ok {
+			old := baseline[id]
+			d.Removed = append(d.Removed, DiffEntry{ID: id, Title: old.Title, Path: filepath.ToSlash(old.RelBase)})
+		}
+	}
+	for _, list := range [][]DiffEntry{d.New, d.Removed, d.Changed} {
+		slices.SortStableFunc(list, func(a, b DiffEntry) int { return strings.Compare(a.Path, b.Path) })
+	}
+	return d
+}
+
+// writeDiffText prints one line per differing meeting and a summary.
+func writeDiffText(w io.Writer, d *ArchiveDiff) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, e := range d.New {
+		fmt.Fprintf(tw, "NEW\t%s\t%s\t\n", e.Path, e.Title)
+	}
+	for _, e := range d.Removed {
+		fmt.Fprintf(tw, "REMOVED\t%s\t%s\t\n", e.Path, e.Title)
+	}
+	for _, e := range d.Changed {
+		var parts []string
+		if e.BaselinePath != "" {
+			parts = append(parts, "moved from "+e.BaselinePath)
+		}
+		for _, p := range []struct {
+			label string
+			kinds []string
+		}{{"changed", e.Changed}, {"added", e.Added}, {"removed", e.Removed}} {
+			if len(p.kinds) > 0 {
+				parts = append(parts, p.label+": "+strings.Join(p.kinds, ", "))
+			}
+		}
+		fmt.Fprintf(tw, "CHANGED\t%s\t%s\t%s\n", e.Path, e.Title, strings.Join(parts, "; "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "diff: %d meeting(s), %d in baseline; %d new, %d removed, %d changed, %d identical\n",
+		d.Meetings, d.BaselineMeetings, len(d.New), len(d.Removed), len(d.Changed), d.Identical)
+	return err
+}
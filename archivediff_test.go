@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffArchives(t *testing.T) {
+	cur, base := t.TempDir(), t.TempDir()
+	for _, dir := range []string{cur, base} {
+		writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "same", Title: "Same"})
+		writeArchiveFile(t, dir, "2025-01-15/same.mp4", "video", 0)
+		writeArchiveFile(t, dir, "2025-01-15/same.mp4.part", "partial "+dir, 0)
+		writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "edit", Title: "Edited"})
+	}
+	gz, err := compressArtifact("gzip", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compression alone is not a change.
+	writeArchiveFile(t, cur, "2025-01-15/same.transcript.txt.gz", string(gz), 0)
+	writeArchiveFile(t, base, "2025-01-15/same.transcript.txt", "hello", 0)
+
+	writeArchiveFile(t, cur, "2025-01-15/edit.transcript.txt", "new words", 0)
+	writeArchiveFile(t, base, "2025-01-15/edit.transcript.txt", "old words", 0)
+	writeArchiveFile(t, cur, "2025-01-15/edit.mp4", "video", 0)
+	writeArchiveFile(t, base, "2025-01-15/edit.highlights.json", "[]", 0)
+	writeArchiveFile(t, cur, "2025-01-15/edit-renamed.md", "---\ngrain_id: edit\n---\n", 0)
+	writeArchiveFile(t, base, "2025-01-15/edited.md", "---\ngrain_id: edit\n---\n", 0)
+
+	writeArchiveMeta(t, cur, "2025-01-20", &Metadata{ID: "moved", Title: "Moved"})
+	writeArchiveMeta(t, base, "2025-01-19", &Metadata{ID: "moved", Title: "Moved"})
+	writeArchiveMeta(t, cur, "2025-02-01", &Metadata{ID: "fresh", Title: "Fresh"})
+	writeArchiveMeta(t, base, "2024-12-01", &Metadata{ID: "gone", Title: "Gone"})
+
+	snap := func(dir string) map[string]*archiveMeeting {
+		m, err := snapshotArchive(dir, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	d := diffArchives(snap(cur), snap(base))
+
+	if d.Meetings != 4 || d.BaselineMeetings != 4 || d.Identical != 1 {
+		t.Errorf("counts = %d/%d, %d identical", d.Meetings, d.BaselineMeetings, d.Identical)
+	}
+	if len(d.New) != 1 || d.New[0].ID != "fresh" || d.New[0].Path != "2025-02-01/fresh" {
+		t.Errorf("new = %+v", d.New)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].ID != "gone" {
+		t.Errorf("removed = %+v", d.Removed)
+	}
+	want := []DiffEntry{
+		{ID: "edit", Title: "Edited", Path: "2025-01-15/edit",
+			Added: []string{"edit-renamed.md", "mp4"}, Removed: []string{"edited.md", "highlights.json"}, Changed: []string{"transcript.txt"}},
+		{ID: "moved", Title: "Moved", Path: "2025-01-20/moved", BaselinePath: "2025-01-19/moved"},
+	}
+	if !reflect.DeepEqual(d.Changed, want) {
+		t.Errorf("changed =\n%+v\nwant\n%+v", d.Changed, want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeDiffText(&buf, d); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"NEW      2025-02-01/fresh",
+		"REMOVED  2024-12-01/gone",
+		"changed: transcript.txt; added: edit-renamed.md, mp4; removed: edited.md, highlights.json",
+		"moved from 2025-01-19/moved",
+		"diff: 4 meeting(s), 4 in baseline; 1 new, 1 removed, 2 changed, 1 identical",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("text output missing %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestArtifactHashRecorded(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveFile(t, dir, "2025-01-15/a.mp4", "video", time.Hour)
+	rel := filepath.Join("2025-01-15", "a.mp4")
+	real, _, err := hashFile(filepath.Join(dir, rel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums := &ChecksumState{Files: map[string]*FileChecksum{
+		rel: {SHA256: "recorded", Size: 5, HashedAt: time.Now()},
+	}}
+
+	if got, _ := artifactHash(dir, rel, sums, false); got != "recorded" {
+		t.Errorf("unchanged file: hash = %q, want the recorded one", got)
+	}
+	if got, _ := artifactHash(dir, rel, sums, true); got != real {
+		t.Errorf("--rehash: hash = %q, want %q", got, real)
+	}
+	sums.Files[rel].HashedAt = time.Now().Add(-2 * time.Hour) // file modified since
+	if got, _ := artifactHash(dir, rel, sums, false); got != real {
+		t.Errorf("modified file: hash = %q, want %q", got, real)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ── Authentication Failure Detection ────────────────────────────────────────
+//
+// If the Grain session is revoked or expires mid-run, every remaining
+// meeting would otherwise fail slowly one by one (or worse, be exported as
+// empty shells that later runs skip). Meeting pages that redirect to login
+// are reported as errAuthRequired; after authFailureThreshold consecutive
+// failures the run aborts, the rest of the batch is recorded as
+// "auth-blocked" in the manifest, and the process exits with exitAuthBlocked.
+
+// authFailureThreshold is how many consecutive auth failures abort the run.
+const authFailureThreshold = 3
+
+// exitAuthBlocked is the process exit code when a run aborts on auth.
+const exitAuthBlocked = 3
+
+// statusAuthBlocked marks meetings not attempted because the run aborted.
+const statusAuthBlocked = "auth-blocked"
+
+var (
+	// errAuthRequired is returned when a Grain page redirects to login.
+	errAuthRequired = errors.New("grain session expired or revoked (redirected to login)")
+
+	// errAuthBlocked is returned by Run when the batch aborted on auth.
+	errAuthBlocked = errors.New("aborted after repeated authentication failures; log in again")
+)
+
+// isLoginURL reports whether a page URL is a Grain login/OAuth page.
+func isLoginURL(u string) bool {
+	return containsAny(u, "login", "signin", "oauth")
+}
+
+// isAuthStatus reports whether an HTTP status means the credentials were
+// rejected.
+func isAuthStatus(code int) bool {
+	return code == http.StatusUnauthorized || code == http.StatusForbidden
+}
+
+// authGuard counts consecutive auth failures across (possibly parallel)
+// meeting exports. Any success resets the count. A nil guard never trips.
+type authGuard struct {
+	mu          sync.Mutex
+	threshold   int
+	consecutive int
+	tripped     bool
+}
+
+func newAuthGuard(threshold int) *authGuard {
+	return &authGuard{threshold: max(threshold, 1)}
+}
+
+// Record notes the outcome of one export and reports whether the guard is
+// (now) tripped. Skipped meetings carry no signal and are ignored.
+func (g *authGuard) Record(r *ExportResult) bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	switch {
+	case r.Status == statusAuthBlocked || r.Status == "skipped":
+	case r.authFailed:
+		g.consecutive++
+		if g.consecutive >= g.threshold {
+			g.tripped = true
+		}
+	default:
+		g.consecutive = 0
+	}
+	return g.tripped
+}
+
+// Tripped reports whether the run should stop.
+func (g *authGuard) Tripped() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.tripped
+}
+
+// authBlockedResult is the manifest entry for a meeting never attempted.
+func authBlockedResult(ref MeetingRef) *ExportResult {
+	r := &ExportResult{
+		ID:       ref.ID,
+		Title:    ref.Title,
+		Status:   statusAuthBlocked,
+		ErrorMsg: errAuthBlocked.Error(),
+	}
+	if ref.Date != "" {
+		r.DateDir = dateFromISO(ref.Date)
+	}
+	return r
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// ── Detection helpers ───────────────────────────────────────────────────────
+
+func TestIsLoginURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://grain.com/login?next=/app/meetings/abc": true,
+		"https://accounts.google.com/o/oauth2/auth":      true,
+		"https://grain.com/signin":                       true,
+		"https://grain.com/app/meetings/abc-123":         false,
+	}
+	for u, want := range tests {
+		if got := isLoginURL(u); got != want {
+			t.Errorf("isLoginURL(%q) = %v, want %v", u, got, want)
+		}
+	}
+}
+
+func TestIsAuthStatus(t *testing.T) {
+	for code, want := range map[int]bool{200: false, 302: false, 401: true, 403: true, 404: false, 500: false} {
+		if got := isAuthStatus(code); got != want {
+			t.Errorf("isAuthStatus(%d) = %v, want %v", code, got, want)
+		}
+	}
+}
+
+// ── authGuard ───────────────────────────────────────────────────────────────
+
+func TestAuthGuardConsecutive(t *testing.T) {
+	g := newAuthGuard(3)
+	fail := &ExportResult{Status: "error", authFailed: true}
+	ok := &ExportResult{Status: "ok"}
+	skipped := &ExportResult{Status: "skipped"}
+
+	g.Record(fail)
+	g.Record(fail)
+	g.Record(ok) // success resets the streak
+	g.Record(fail)
+	g.Record(skipped) // no signal either way
+	if g.Record(fail) {
+		t.Fatal("guard tripped after 2 consecutive failures")
+	}
+	if !g.Record(fail) {
+		t.Fatal("guard should trip on the 3rd consecutive failure")
+	}
+	if !g.Record(ok) || !g.Tripped() {
+		t.Error("tripped guard should stay tripped")
+	}
+}
+
+func TestAuthGuardNil(t *testing.T) {
+	var g *authGuard
+	if g.Record(&ExportResult{authFailed: true}) || g.Tripped() {
+		t.Error("nil guard should never trip")
+	}
+}
+
+// ── Batch abort ─────────────────────────────────────────────────────────────
+
+// newTrippedExporter returns an exporter whose auth guard has already
+// tripped, as after repeated login redirects.
+func newTrippedExporter(t *testing.T, parallel int) *Exporter {
+	t.Helper()
+	cfg := &Config{OutputDir: t.TempDir(), SkipVideo: true, Parallel: parallel, MaxDelaySec: 0.01}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+	e.auth = newAuthGuard(1)
+	e.auth.Record(&ExportResult{Status: "error", authFailed: true})
+	return e
+}
+
+func TestExportSequentialAuthBlocked(t *testing.T) {
+	e := newTrippedExporter(t, 1)
+	meetings := []MeetingRef{{ID: "a", Date: "2025-01-15"}, {ID: "b"}, {ID: "c"}}
+
+	e.exportSequential(context.Background(), meetings)
+
+	if e.manifest.AuthBlocked != 3 || e.manifest.Errors != 0 {
+		t.Errorf("manifest auth_blocked=%d errors=%d, want 3/0", e.manifest.AuthBlocked, e.manifest.Errors)
+	}
+	if len(e.manifest.Meetings) != 3 {
+		t.Fatalf("manifest has %d meetings, want 3", len(e.manifest.Meetings))
+	}
+	for _, r := range e.manifest.Meetings {
+		if r.Status != statusAuthBlocked {
+			t.Errorf("%s status = %q, want %q", r.ID, r.Status, statusAuthBlocked)
+		}
+	}
+	if got := e.manifest.Meetings[0].DateDir; got != "2025-01-15" {
+		t.Errorf("DateDir = %q, want 2025-01-15", got)
+	}
+	if got := e.manifest.Meetings[1].DateDir; got != "" {
+		t.Errorf("DateDir without date = %q, want empty", got)
+	}
+}
+
+func TestExportParallelAuthBlocked(t *testing.T) {
+	e := newTrippedExporter(t, 2)
+	meetings := []MeetingRef{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+
+	e.exportParallel(context.Background(), meetings)
+
+	if e.manifest.AuthBlocked != 4 {
+		t.Errorf("auth_blocked = %d, want 4", e.manifest.AuthBlocked)
+	}
+	for i, r := range e.manifest.Meetings {
+		if r.ID != meetings[i].ID {
+			t.Errorf("manifest[%d] = %s, want %s (order preserved)", i, r.ID, meetings[i].ID)
+		}
+	}
+}
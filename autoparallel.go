@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── Auto Parallelism ────────────────────────────────────────────────────────
+//
+// --auto-parallel sizes the worker pool from the machine instead of a fixed
+// --parallel: one worker per spare CPU core, capped by available memory
+// (each worker can hold a Chromium page and an ffmpeg process) and by
+// autoParallelMax. The run starts at half that ceiling and adapts as
+// meetings finish, in the additive-increase / multiplicative-decrease style
+// of TCP congestion control:
+//
+//   - a browser timeout or new swapping halves the limit;
+//   - per-meeting latency (wall time between finished meetings while every
+//     slot is busy, as in the progress ETA) above twice the best seen so
+//     far lowers it by one;
+//   - autoParallelWindow healthy meetings in a row raise it by one.
+//
+// Memory and swap readings come from /proc and are Linux-only; elsewhere
+// sizing falls back to CPU count and adaptation to timeouts and latency.
+
+const (
+	autoParallelMax          = 8
+	autoParallelMemPerWorker = 768 << 20 // bytes: Chromium page + ffmpeg remux
+	autoParallelWindow       = 4         // healthy results before growing
+	autoParallelLatencyRatio = 2.0       // slowdown vs. best EMA that counts as contention
+)
+
+// autoParallelCeiling returns the largest worker count the machine can take:
+// CPU cores minus one for Chromium's own processes, limited by available
+// memory (0 = unknown) and autoParallelMax. It is at least 1.
+func autoParallelCeiling(cpus int, availMem uint64) int {
+	n := cpus - 1
+	if availMem > 0 {
+		n = min(n, int(availMem/autoParallelMemPerWorker))
+	}
+	return max(1, min(n, autoParallelMax))
+}
+
+// availableMemory returns MemAvailable from /proc/meminfo in bytes, or 0
+// when it cannot be read.
+func availableMemory() uint64 {
+	v, ok := procField("/proc/meminfo", "MemAvailable:")
+	if !ok {
+		return 0
+	}
+	return uint64(v) * 1024 // reported in kB
+}
+
+// swappedOut returns the pages swapped out since boot (pswpout in
+// /proc/vmstat), or -1 when unknown.
+func swappedOut() int64 {
+	v, ok := procField("/proc/vmstat", "pswpout")
+	if !ok {
+		return -1
+	}
+	return v
+}
+
+// procField reads the first number after key in a /proc table.
+func procField(path, key string) (int64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 && fields[0] == key {
+			v, err := strconv.ParseInt(fields[1], 10, 64)
+			return v, err == nil
+		}
+	}
+	return 0, false
+}
+
+// resolveAutoParallel sets cfg.Parallel to the machine's ceiling when
+// --auto-parallel is on.
+func resolveAutoParallel(cfg *Config) {
+	if !cfg.AutoParallel {
+		return
+	}
+	mem := availableMemory()
+	cfg.Parallel = autoParallelCeiling(runtime.NumCPU(), mem)
+	slog.Debug("Auto parallel sized", "cpus", runtime.NumCPU(), "mem_available_mb", mem>>20, "ceiling", cfg.Parallel)
+}
+
+// ── Worker gate ─────────────────────────────────────────────────────────────
+
+// workerGate limits how many meetings export at once. A fixed gate behaves
+// like a semaphore of size max; an adaptive one moves its limit between 1
+// and max based on how exports go.
+type workerGate struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	active   int
+	limit    int
+	max      int
+	adaptive bool
+
+	healthy  int       // consecutive healthy results since the last change
+	avg      float64   // EMA of seconds between exported meetings
+	best     float64   // lowest avg seen since the last decrease
+	last     time.Time // when the previous meeting finished
+	swap     int64     // last swappedOut reading
+	readSwap func() int64
+	now      func() time.Time
+}
+
+func newWorkerGate(n int, adaptive bool) *workerGate {
+	g := &workerGate{limit: n, max: n, adaptive: adaptive, readSwap: swappedOut, now: time.Now}
+	if adaptive {
+		g.limit = max(1, n/2)
+		g.swap = g.readSwap()
+	}
+	g.last = g.now()
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+// acquire blocks until a worker slot is free.
+func (g *workerGate) acquire() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for g.active >= g.limit {
+		g.cond.Wait()
+	}
+	g.active++
+}
+
+// release frees a slot and, for an adaptive gate, folds the finished
+// meeting into the limit.
+func (g *workerGate) release(r *ExportResult) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.active--
+	if g.adaptive && r != nil {
+		g.adapt(r)
+	}
+	g.cond.Broadcast()
+}
+
+// Limit returns the current worker limit.
+func (g *workerGate) Limit() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.limit
+}
+
+// adapt applies one result to the limit. Caller holds g.mu.
+func (g *workerGate) adapt(r *ExportResult) {
+	if r.Status == "skipped" || r.Status == statusAuthBlocked {
+		return // instant; says nothing about load
+	}
+	now := g.now()
+	sample := now.Sub(g.last).Seconds()
+	g.last = now
+	// With a slot idle (the queue was waiting on discovery or search), the
+	// gap says nothing about load.
+	saturated := g.active+1 >= g.limit
+
+	if r.browserTimeout {
+		g.shrink(g.limit/2, "browser timed out")
+		return
+	}
+	if s := g.readSwap(); s >= 0 && g.swap >= 0 && s > g.swap {
+		g.swap = s
+		g.shrink(g.limit/2, "system is swapping")
+		return
+	}
+
+	if !saturated {
+		return
+	}
+	if g.avg == 0 {
+		g.avg = sample
+	} else {
+		g.avg = progressAlpha*sample + (1-progressAlpha)*g.avg
+	}
+	if g.best == 0 || g.avg < g.best {
+		g.best = g.avg
+	}
+	if g.avg > autoParallelLatencyRatio*g.best {
+		g.shrink(g.limit-1, fmt.Sprintf("latency %.1fs/meeting vs. best %.1fs", g.avg, g.best))
+		return
+	}
+
+	g.healthy++
+	if g.healthy >= autoParallelWindow && g.limit < g.max {
+		g.limit++
+		g.healthy = 0
+		slog.Debug("Raising parallelism", "workers", g.limit)
+	}
+}
+
+// shrink lowers the limit to n (at least 1). Caller holds g.mu.
+func (g *workerGate) shrink(n int, reason string) {
+	g.healthy = 0
+	n = max(1, n)
+	if n >= g.limit {
+		return
+	}
+	slog.Warn(fmt.Sprintf("Reducing parallelism to %d worker(s): %s", n, reason))
+	g.limit = n
+	// Fewer workers finish meetings less often; re-learn the baseline.
+	g.best = g.avg
+}
+
+// isTimeout reports whether err is a browser or network timeout.
+func isTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "timeout") || strings.Contains(msg, "timed out")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAutoParallelCeiling(t *testing.T) {
+	const gb = 1 << 30
+	tests := []struct {
+		cpus int
+		mem  uint64
+		want int
+	}{
+		{8, 16 * gb, 7},
+		{16, 64 * gb, autoParallelMax},
+		{8, 2 * gb, 2},    // memory-bound
+		{8, 0, 7},         // memory unknown
+		{1, 16 * gb, 1},   // never below one
+		{4, 100 << 20, 1}, // almost no memory
+		{2, 16 * gb, 1},   // one core left for Chromium
+		{32, 0, autoParallelMax},
+	}
+	for _, tc := range tests {
+		if got := autoParallelCeiling(tc.cpus, tc.mem); got != tc.want {
+			t.Errorf("autoParallelCeiling(%d, %d) = %d, want %d", tc.cpus, tc.mem, got, tc.want)
+		}
+	}
+}
+
+func TestProcField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meminfo")
+	os.WriteFile(path, []byte("MemTotal:       16000000 kB\nMemAvailable:    8000000 kB\n"), 0o600)
+	if v, ok := procField(path, "MemAvailable:"); !ok || v != 8000000 {
+		t.Errorf("procField = %d, %v", v, ok)
+	}
+	if _, ok := procField(path, "SwapFree:"); ok {
+		t.Error("missing key should not be found")
+	}
+	if _, ok := procField(filepath.Join(t.TempDir(), "nope"), "x"); ok {
+		t.Error("missing file should not be found")
+	}
+}
+
+// newTestGate returns an adaptive gate over max workers with a fake clock
+// and swap counter, and every slot busy.
+func newTestGate(max int) (g *workerGate, advance func(time.Duration), swap *int64) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	var s int64
+	g = newWorkerGate(max, true)
+	g.now = func() time.Time { return now }
+	g.readSwap = func() int64 { return s }
+	g.last, g.swap = now, 0
+	return g, func(d time.Duration) { now = now.Add(d) }, &s
+}
+
+// finish completes one busy meeting after d.
+func finish(g *workerGate, advance func(time.Duration), d time.Duration, r *ExportResult) {
+	for g.active < g.Limit() {
+		g.acquire()
+	}
+	advance(d)
+	g.release(r)
+}
+
+func TestWorkerGateStartsAtHalfAndGrows(t *testing.T) {
+	g, advance, _ := newTestGate(6)
+	if g.Limit() != 3 {
+		t.Fatalf("initial limit = %d, want 3", g.Limit())
+	}
+	for range autoParallelWindow {
+		finish(g, advance, 10*time.Second, &ExportResult{Status: "ok"})
+	}
+	if g.Limit() != 4 {
+		t.Errorf("limit after a healthy window = %d, want 4", g.Limit())
+	}
+	for range 10 * autoParallelWindow {
+		finish(g, advance, 10*time.Second, &ExportResult{Status: "ok"})
+	}
+	if g.Limit() != 6 {
+		t.Errorf("limit should stop at max, got %d", g.Limit())
+	}
+}
+
+func TestWorkerGateHalvesOnTimeoutAndSwap(t *testing.T) {
+	g, advance, swap := newTestGate(8)
+	finish(g, advance, time.Second, &ExportResult{Status: "ok", browserTimeout: true})
+	if g.Limit() != 2 {
+		t.Errorf("limit after timeout = %d, want 2", g.Limit())
+	}
+	*swap = 100
+	finish(g, advance, time.Second, &ExportResult{Status: "ok"})
+	if g.Limit() != 1 {
+		t.Errorf("limit after swapping = %d, want 1", g.Limit())
+	}
+	finish(g, advance, time.Second, &ExportResult{Status: "ok", browserTimeout: true})
+	if g.Limit() != 1 {
+		t.Errorf("limit must not drop below 1, got %d", g.Limit())
+	}
+}
+
+func TestWorkerGateBacksOffOnLatency(t *testing.T) {
+	g, advance, _ := newTestGate(8)
+	for range 3 {
+		finish(g, advance, 10*time.Second, &ExportResult{Status: "ok"})
+	}
+	// One very slow meeting pushes the average past twice the best.
+	finish(g, advance, 200*time.Second, &ExportResult{Status: "ok"})
+	if g.Limit() != 3 {
+		t.Errorf("limit = %d, want 3 after latency rose past %.0fx the best", g.Limit(), autoParallelLatencyRatio)
+	}
+}
+
+func TestWorkerGateIgnoresSkippedAndIdle(t *testing.T) {
+	g, advance, _ := newTestGate(8)
+	// Skipped meetings and gaps while slots sit idle are not load signals.
+	for range 2 * autoParallelWindow {
+		advance(10 * time.Minute)
+		g.acquire()
+		g.release(&ExportResult{Status: "skipped"})
+		g.acquire()
+		g.release(&ExportResult{Status: "ok"})
+	}
+	if g.Limit() != 4 || g.avg != 0 {
+		t.Errorf("limit = %d avg = %.1f, want 4 and no samples", g.Limit(), g.avg)
+	}
+}
+
+func TestWorkerGateFixed(t *testing.T) {
+	g := newWorkerGate(3, false)
+	for range 3 {
+		g.acquire()
+	}
+	g.release(&ExportResult{Status: "ok", browserTimeout: true})
+	if g.Limit() != 3 {
+		t.Errorf("fixed gate limit changed to %d", g.Limit())
+	}
+	done := make(chan struct{})
+	g.acquire() // the released slot
+	go func() { g.acquire(); close(done) }()
+	select {
+	case <-done:
+		t.Fatal("acquire should block while all slots are taken")
+	case <-time.After(20 * time.Millisecond):
+	}
+	g.release(nil)
+	<-done
+}
+
+func TestIsTimeout(t *testing.T) {
+	for err, want := range map[error]bool{
+		nil:                      false,
+		context.DeadlineExceeded: true,
+		fmt.Errorf("scrape: %w", context.DeadlineExceeded): true,
+		errors.New("navigation Timeout exceeded"):          true,
+		errors.New("element timed out"):                    true,
+		errors.New("page crashed"):                         false,
+	} {
+		if got := isTimeout(err); got != want {
+			t.Errorf("isTimeout(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 )
 
@@ -27,6 +29,11 @@ type Browser struct {
 	page     *rod.Page
 	cfg      *Config
 	throttle *Throttle
+	recorder *httpRecorder // --record-http
+	replayer *httpReplayer // --replay-http
+	depth    scrollDepth   // how far list pages are scrolled
+	conn     io.Closer     // --browser-remote: the DevTools connection, closed instead of the browser
+	meeting  meetingPage   // meeting page the tab has loaded (see pagecache.go)
 }
 
 func NewBrowser(cfg *Config, throttle *Throttle) (*Browser, error) {
@@ -41,21 +48,60 @@ func NewBrowser(cfg *Config, throttle *Throttle) (*Browser, error) {
 		return nil, fmt.Errorf("session dir: %w", err)
 	}
 
-	u, err := launcher.New().
-		Headless(cfg.Headless).
-		UserDataDir(profileDir).
-		Set("disable-blink-features", "AutomationControlled").
-		Launch()
+	recorder, replayer, err := newHTTPTap(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("launch chromium: %w", err)
+		return nil, err
 	}
 
-	b := rod.New().ControlURL(u)
-	if err := b.Connect(); err != nil {
-		return nil, fmt.Errorf("connect: %w", err)
+	var b *rod.Browser
+	var conn io.Closer
+	if cfg.BrowserRemote != "" {
+		rb, ws, err := connectRemote(context.Background(), cfg.BrowserRemote)
+		if err != nil {
+			return nil, err
+		}
+		b, conn = rb, ws
+		slog.Info("Attached to remote browser", "url", cfg.BrowserRemote)
+	} else {
+		l := launcher.New().
+			Headless(cfg.Headless).
+			UserDataDir(profileDir).
+			Set("disable-blink-features", "AutomationControlled")
+		if l.Get(flags.Bin) == "" {
+			bin, err := resolveBrowserBin(context.Background(), cfg, rodBrowserSource())
+			if err != nil {
+				return nil, err
+			}
+			l = l.Bin(bin)
+		}
+		u, err := l.Launch()
+		if err != nil {
+			return nil, fmt.Errorf("launch chromium: %w", err)
+		}
+
+		b = rod.New().ControlURL(u)
+		if err := b.Connect(); err != nil {
+			return nil, fmt.Errorf("connect: %w", err)
+		}
 	}
 
-	page, err := b.Page(proto.TargetCreateTarget{URL: "about:blank"})
+	page, err := newStealthPage(b, cfg)
+	if err != nil {
+		if conn != nil {
+			_ = conn.Close()
+		}
+		return nil, err
+	}
+
+	br := &Browser{browser: b, page: page, cfg: cfg, throttle: throttle, recorder: recorder, replayer: replayer, depth: defaultScrollDepth, conn: conn}
+	br.attachHTTPTap(page)
+	return br, nil
+}
+
+// newStealthPage opens a blank page in rb with the webdriver flag hidden and
+// cfg's request identification applied.
+func newStealthPage(rb *rod.Browser, cfg *Config) (*rod.Page, error) {
+	page, err := rb.Page(proto.TargetCreateTarget{URL: "about:blank"})
 	if err != nil {
 		return nil, fmt.Errorf("page: %w", err)
 	}
@@ -66,14 +112,53 @@ func NewBrowser(cfg *Config, throttle *Throttle) (*Browser, error) {
 		page.Close()
 		return nil, fmt.Errorf("stealth setup: %w", err)
 	}
+	if err := identifyPage(cfg, page); err != nil {
+		page.Close()
+		return nil, err
+	}
+	return page, nil
+}
+
+// NewWorker returns a Browser that shares b's Chromium process but runs in
+// its own incognito context, seeded with b's cookies so the Grain login
+// carries over. Navigation state (and any cookies a page sets later) stay
+// isolated from other workers. Close disposes the context, not the process.
+func (b *Browser) NewWorker() (*Browser, error) {
+	cookies, err := b.browser.GetCookies()
+	if err != nil {
+		return nil, fmt.Errorf("get cookies: %w", err)
+	}
 
-	return &Browser{browser: b, page: page, cfg: cfg, throttle: throttle}, nil
+	inc, err := b.browser.Incognito()
+	if err != nil {
+		return nil, fmt.Errorf("incognito context: %w", err)
+	}
+	if len(cookies) > 0 {
+		if err := inc.SetCookies(proto.CookiesToParams(cookies)); err != nil {
+			_ = inc.Close()
+			return nil, fmt.Errorf("copy cookies: %w", err)
+		}
+	}
+
+	page, err := newStealthPage(inc, b.cfg)
+	if err != nil {
+		_ = inc.Close()
+		return nil, err
+	}
+	wb := &Browser{browser: inc, page: page, cfg: b.cfg, throttle: b.throttle, recorder: b.recorder, replayer: b.replayer, depth: b.depth}
+	wb.attachHTTPTap(page)
+	return wb, nil
 }
 
 func (b *Browser) Close() {
 	if b.page != nil {
 		b.page.Close()
 	}
+	if b.conn != nil {
+		// A remote browser is shared: leave it running.
+		_ = b.conn.Close()
+		return
+	}
 	if b.browser != nil {
 		b.browser.Close()
 	}
@@ -84,7 +169,7 @@ func (b *Browser) Close() {
 func (b *Browser) Login(ctx context.Context) ([]*http.Cookie, error) {
 	if err := rod.Try(func() {
 		b.page.Timeout(20 * time.Second).
-			MustNavigate("https://grain.com/app/meetings").
+			MustNavigate(grainPageURL("/app/meetings")).
 			MustWaitStable()
 	}); err != nil {
 		return nil, fmt.Errorf("navigate: %w", err)
@@ -95,10 +180,10 @@ func (b *Browser) Login(ctx context.Context) ([]*http.Cookie, error) {
 		return nil, fmt.Errorf("page info: %w", err)
 	}
 	pageURL := info.URL
-	if containsAny(pageURL, "login", "signin", "oauth") {
-		fmt.Println("\n━━━ LOGIN REQUIRED ━━━")
-		fmt.Println("Complete login in the browser window. (120s timeout)")
-		fmt.Println("━━━━━━━━━━━━━━━━━━━━━━")
+	if isLoginURL(pageURL) {
+		fmt.Fprintln(os.Stderr, "\n━━━ LOGIN REQUIRED ━━━")
+		fmt.Fprintln(os.Stderr, "Complete login in the browser window. (120s timeout)")
+		fmt.Fprintln(os.Stderr, "━━━━━━━━━━━━━━━━━━━━━━")
 
 		deadline := time.Now().Add(120 * time.Second)
 		for time.Now().Before(deadline) {
@@ -142,22 +227,69 @@ func (b *Browser) exportCookies() ([]*http.Cookie, error) {
 
 // ── Meeting Discovery ───────────────────────────────────────────────────────
 
-func (b *Browser) DiscoverMeetings(ctx context.Context) ([]MeetingRef, error) {
+// scrollDepth bounds how far infinite-scroll lists are loaded. Watch
+// catch-up cycles use catchUpScrollDepth, since the meetings they are after
+// may be far down the list.
+type scrollDepth struct {
+	stableRounds int // unchanged result counts before a list is fully loaded
+	maxScrolls   int // scroll cap for the search page
+}
+
+var (
+	defaultScrollDepth = scrollDepth{stableRounds: 3, maxScrolls: 50}
+	catchUpScrollDepth = scrollDepth{stableRounds: 8, maxScrolls: 500}
+)
+
+// DiscoverMeetings collects the account's meetings, newest first. With a
+// limit > 0 it stops scrolling once that many are loaded.
+func (b *Browser) DiscoverMeetings(ctx context.Context, limit int) ([]MeetingRef, error) {
+	return b.discoverList(ctx, grainPageURL("/app/meetings"), "", limit)
+}
+
+// sharedMeetingsPath is Grain's "Shared with me" view. If the route moves,
+// discoverList falls back to clicking the tab by its label.
+const sharedMeetingsPath = "/app/meetings/shared-with-me"
+
+// DiscoverSharedMeetings collects recordings other users have shared with
+// the account (--include-shared). The refs are marked Shared.
+func (b *Browser) DiscoverSharedMeetings(ctx context.Context, limit int) ([]MeetingRef, error) {
+	meetings, err := b.discoverList(ctx, grainPageURL(sharedMeetingsPath), "shared with me", limit)
+	for i := range meetings {
+		meetings[i].Shared = true
+	}
+	return meetings, err
+}
+
+// discoverList opens a meeting list, optionally switches to the tab
+// labelled tab, scrolls until no new links load, and returns the meetings.
+// With a limit > 0 scrolling stops as soon as limit links are loaded; the
+// list is newest first, so those are the same meetings a full load would
+// put first.
+func (b *Browser) discoverList(ctx context.Context, listURL, tab string, limit int) ([]MeetingRef, error) {
 	if err := rod.Try(func() {
 		b.page.Timeout(20 * time.Second).
-			MustNavigate("https://grain.com/app/meetings").
+			MustNavigate(listURL).
 			MustWaitStable()
 	}); err != nil {
 		return nil, fmt.Errorf("navigate: %w", err)
 	}
 	time.Sleep(2 * time.Second)
 
+	if tab != "" && b.clickTab(tab) {
+		slog.Debug("Switched meeting list tab", "tab", tab)
+		time.Sleep(2 * time.Second)
+	}
+
 	prevCount, stable := 0, 0
-	for stable < 3 {
+	for stable < b.depth.stableRounds {
 		if err := ctx.Err(); err != nil {
 			return nil, fmt.Errorf("cancelled during scroll: %w", err)
 		}
 		count := b.countLinks()
+		if limit > 0 && count >= limit {
+			slog.Debug("Meeting list loaded up to --max", "loaded", count, "max", limit)
+			break
+		}
 		if count == prevCount {
 			stable++
 		} else {
@@ -199,6 +331,26 @@ func (b *Browser) DiscoverMeetings(ctx context.Context) ([]MeetingRef, error) {
 	return meetings, nil
 }
 
+// clickTab clicks the first tab, link, or button whose text contains label
+// (case-insensitive), unless it is already selected.
+func (b *Browser) clickTab(label string) bool {
+	result, err := b.page.Eval(`(label) => {
+		const els = document.querySelectorAll('[role="tab"], nav a, a, button');
+		for (const el of els) {
+			const text = (el.textContent || '').trim().toLowerCase();
+			if (!text.includes(label)) continue;
+			if (el.getAttribute('aria-selected') === 'true' || el.getAttribute('aria-current')) return false;
+			el.click();
+			return true;
+		}
+		return false;
+	}`, label)
+	if err != nil {
+		return false
+	}
+	return result.Value.Bool()
+}
+
 func (b *Browser) countLinks() int {
 	result, err := b.page.Eval(`() => {
 		const links = document.querySelectorAll('a[href*="/app/meetings/"]');
@@ -215,44 +367,34 @@ func (b *Browser) countLinks() int {
 
 // ── Video Source Discovery ──────────────────────────────────────────────────
 
-// FindVideoSource navigates to a meeting page and tries to locate a video URL
-// without downloading the file. Used by --audio-only to let ffmpeg stream
-// audio directly from the source, saving bandwidth.
+// FindVideoSource tries to locate a meeting's video URL without
+// downloading the file. Used by --audio-only to let ffmpeg stream audio
+// directly from the source, saving bandwidth. A source already found while
+// scraping the page is returned without loading it again.
 func (b *Browser) FindVideoSource(ctx context.Context, pageURL string) string {
-	if err := rod.Try(func() {
-		b.page.Timeout(20 * time.Second).MustNavigate(pageURL).MustWaitStable()
-	}); err != nil {
-		return ""
-	}
-	time.Sleep(2 * time.Second)
-
-	if u := b.extractVideoURL(); u != "" {
-		return u
+	if src, ok := b.meeting.videoSource(pageURL); ok {
+		return src
 	}
-	if u := b.interceptNetwork(pageURL); u != "" {
-		return u
+	if err := b.openMeetingPage(pageURL); err != nil {
+		return ""
 	}
-	return ""
+	return b.pageVideoSource(pageURL)
 }
 
 // ── Video Download ──────────────────────────────────────────────────────────
 
+// DownloadVideo saves a meeting's video, reusing the meeting page when the
+// tab still has it loaded from scraping.
 func (b *Browser) DownloadVideo(ctx context.Context, pageURL, outputPath string) (method, result string) {
-	if err := rod.Try(func() {
-		b.page.Timeout(20 * time.Second).MustNavigate(pageURL).MustWaitStable()
-	}); err != nil {
+	if err := b.openMeetingPage(pageURL); err != nil {
 		return "failed", ""
 	}
-	time.Sleep(2 * time.Second)
 
 	if p := b.tryDownloadBtn(ctx, outputPath); p != "" {
 		return "button", p
 	}
-	if u := b.extractVideoURL(); u != "" {
-		return b.resolveURL(u, outputPath)
-	}
-	if u := b.interceptNetwork(pageURL); u != "" {
-		return b.resolveURL(u, outputPath)
+	if u := b.pageVideoSource(pageURL); u != "" {
+		return b.resolveURL(ctx, u, outputPath)
 	}
 	return "failed", ""
 }
@@ -344,6 +486,9 @@ func (b *Browser) extractVideoURL() string {
 var videoRe = regexp.MustCompile(`\.(mp4|webm|m3u8)`)
 
 func (b *Browser) interceptNetwork(pageURL string) string {
+	if b.replayer != nil {
+		return "" // the page's request hijacking is taken by --replay-http
+	}
 	var found atomic.Value
 
 	router := b.page.HijackRequests()
@@ -361,6 +506,7 @@ func (b *Browser) interceptNetwork(pageURL string) string {
 		b.page.Timeout(20 * time.Second).MustNavigate(pageURL).MustWaitStable()
 	})
 	time.Sleep(2 * time.Second)
+	b.markMeetingPage(pageURL)
 	// Trigger video playback to provoke network requests.
 	_, _ = b.page.Eval(`() => {
 		const v = document.querySelector('video');
@@ -374,12 +520,18 @@ func (b *Browser) interceptNetwork(pageURL string) string {
 	return ""
 }
 
-func (b *Browser) resolveURL(videoURL, outputPath string) (string, string) {
+// resolveURL saves a video found by URL. HLS playlists are recorded for
+// conversion; direct URLs are downloaded with Go's http.Client, falling back
+// to an in-page fetch for URLs only the page can read (e.g. blob:).
+func (b *Browser) resolveURL(ctx context.Context, videoURL, outputPath string) (string, string) {
 	if strings.Contains(videoURL, ".m3u8") {
 		p := strings.TrimSuffix(outputPath, ".mp4") + ".m3u8.url"
 		_ = writeFile(p, []byte(videoURL))
 		return "hls", p
 	}
+	if b.fetchViaHTTP(ctx, videoURL, outputPath) {
+		return "direct", outputPath
+	}
 	if b.fetchViaJS(videoURL, outputPath) {
 		return "direct", outputPath
 	}
@@ -388,9 +540,9 @@ func (b *Browser) resolveURL(videoURL, outputPath string) (string, string) {
 	return "url-saved", p
 }
 
-// maxFetchViaJSBytes is the maximum video size fetchViaJS will attempt.
-// Larger files should be downloaded via Go's http.Client or Rod's download API
-// to avoid exhausting the browser's JS heap.
+// maxFetchViaJSBytes is the maximum video size fetchViaJS will attempt. It
+// only applies to the in-page fallback; http(s) URLs go through
+// fetchViaHTTP, which streams to disk without a limit.
 const maxFetchViaJSBytes = 50 * 1024 * 1024 // 50 MB
 
 func (b *Browser) fetchViaJS(videoURL, outputPath string) bool {
@@ -446,21 +598,35 @@ type MeetingPageData struct {
 	Date         string
 	Duration     string
 	Participants []string
+	Tags         []string
 	Transcript   string
 	Highlights   []Highlight
+	Analytics    *ViewAnalytics   // view counts, when the page shows them
+	Sharing      *Sharing         // share dialog state, when the page has one
+	Platform     *MeetingPlatform // recording platform, when the page says
+	AINotes      *AINotesPayload  // AI notes panel, when present
+	Extra        map[string]any   // --extract-script results
 }
 
 // ScrapeMeetingPage navigates to a meeting page and extracts transcript text,
-// highlights, and any additional metadata visible on the page.
+// highlights, and any additional metadata visible on the page. The page
+// stays loaded for the video download (see pagecache.go).
 func (b *Browser) ScrapeMeetingPage(ctx context.Context, pageURL string) (*MeetingPageData, error) {
-	if err := rod.Try(func() {
-		b.page.Timeout(20 * time.Second).MustNavigate(pageURL).MustWaitStable()
-	}); err != nil {
+	b.meeting = meetingPage{} // always scrape a fresh load
+	if err := b.openMeetingPage(pageURL); err != nil {
 		return nil, fmt.Errorf("navigate to meeting: %w", err)
 	}
-	time.Sleep(2 * time.Second)
+	if err := b.checkAuth(); err != nil {
+		b.meeting = meetingPage{}
+		return nil, err
+	}
 
 	data := &MeetingPageData{}
+	// A video element in the DOM saves the video step a lookup; the
+	// network fallback is left to that step.
+	if src := b.extractVideoURL(); src != "" {
+		b.meeting.setSource(pageURL, src)
+	}
 
 	// Extract page metadata (title, date, duration, participants).
 	data.Title = b.scrapeText(`h1, [data-testid="meeting-title"], .meeting-title`)
@@ -470,17 +636,39 @@ func (b *Browser) ScrapeMeetingPage(ctx context.Context, pageURL string) (*Meeti
 	}
 	data.Duration = b.scrapeText(`[data-testid="meeting-duration"], .duration`)
 	data.Participants = b.scrapeParticipants()
+	data.Tags = b.scrapeTags()
+	data.Analytics = b.scrapeAnalytics()
+	data.Sharing = b.scrapeSharing()
+	data.Platform = b.scrapePlatform()
+	data.AINotes = b.scrapeAINotes()
 
 	// Click transcript tab/section if present.
 	b.clickElement(`[data-testid="transcript-tab"], button:has-text("Transcript"), [role="tab"]:has-text("Transcript")`)
 	time.Sleep(1 * time.Second)
 
-	data.Transcript = b.scrapeTranscript()
+	data.Transcript = b.scrapeTranscript(ctx)
 	data.Highlights = b.scrapeHighlights(ctx)
+	b.markMeetingPage(pageURL) // opening the transcript tab may change the URL
 
 	return data, nil
 }
 
+// checkAuth returns errAuthRequired if the current page redirected to login
+// or its document was served with 401/403.
+func (b *Browser) checkAuth() error {
+	if info, err := b.page.Info(); err == nil && isLoginURL(info.URL) {
+		return errAuthRequired
+	}
+	res, err := b.page.Eval(`() => {
+		const nav = performance.getEntriesByType('navigation')[0];
+		return (nav && nav.responseStatus) || 0;
+	}`)
+	if err == nil && isAuthStatus(res.Value.Int()) {
+		return errAuthRequired
+	}
+	return nil
+}
+
 // scrapeText returns the trimmed text content of the first matching element.
 func (b *Browser) scrapeText(selectors string) string {
 	for _, sel := range strings.Split(selectors, ",") {
@@ -543,32 +731,88 @@ func (b *Browser) scrapeParticipants() []string {
 	return participants
 }
 
+// maxExtractScriptBytes bounds --extract-script files; they are inlined into
+// every page evaluation.
+const maxExtractScriptBytes = 1 << 20 // 1 MB
+
+// extractScriptWrapper wraps a user --extract-script in a CommonJS-style
+// module scope. Every function on exports (or module.exports) is called with
+// no arguments in the page context and may be async; non-function exports
+// are passed through. A throwing function yields null for its key and the
+// message under "_errors".
+const extractScriptWrapper = `async () => {
+	const module = { exports: {} };
+	(function (exports, module) {
+%s
+	})(module.exports, module);
+	const out = {};
+	const errors = {};
+	for (const [key, val] of Object.entries(module.exports || {})) {
+		if (typeof val !== 'function') { out[key] = val; continue; }
+		try {
+			out[key] = await val();
+		} catch (e) {
+			out[key] = null;
+			errors[key] = String(e && e.message || e);
+		}
+	}
+	if (Object.keys(errors).length) out._errors = errors;
+	return JSON.stringify(out, (k, v) => v === undefined ? null : v);
+}`
+
+// RunExtractScript evaluates a user extraction script (see
+// extractScriptWrapper) on the current page and returns its fields.
+func (b *Browser) RunExtractScript(src string) (map[string]any, error) {
+	result, err := b.page.Timeout(30 * time.Second).Eval(fmt.Sprintf(extractScriptWrapper, src))
+	if err != nil {
+		return nil, fmt.Errorf("extract script: %w", err)
+	}
+	var extra map[string]any
+	if err := json.Unmarshal([]byte(result.Value.Str()), &extra); err != nil {
+		return nil, fmt.Errorf("extract script result: %w", err)
+	}
+	return extra, nil
+}
+
+// scrapeTags extracts meeting tag labels (Grain renders them as chips near
+// the title).
+func (b *Browser) scrapeTags() []string {
+	result, err := b.page.Eval(`() => {
+		const tags = new Set();
+		document.querySelectorAll(
+			'[data-testid="meeting-tag"], [data-testid="tag"], ' +
+			'[class*="meeting-tag"], [class*="MeetingTag"], [class*="tag-chip"], [class*="TagChip"]'
+		).forEach(el => {
+			const t = (el.textContent || '').trim().replace(/^#/, '');
+			if (t && t.length < 60) tags.add(t);
+		});
+		return Array.from(tags);
+	}`)
+	if err != nil {
+		return nil
+	}
+	var tags []string
+	for _, item := range result.Value.Arr() {
+		if s := item.Str(); s != "" {
+			tags = append(tags, s)
+		}
+	}
+	return tags
+}
+
 // scrapeTranscript extracts transcript text from the meeting page.
-// Grain typically renders transcript segments as individual elements.
-func (b *Browser) scrapeTranscript() string {
+// Grain typically renders transcript segments as individual elements; long
+// transcripts are scrolled into view first (see transcriptscroll.go).
+func (b *Browser) scrapeTranscript(ctx context.Context) string {
+	if lines := b.expandTranscript(ctx); len(lines) > 0 {
+		return strings.Join(lines, "\n\n")
+	}
+
 	// Try structured transcript segments first.
 	result, err := b.page.Eval(`() => {
-		const segments = [];
-
-		// Common transcript container selectors.
-		const containers = document.querySelectorAll(
-			'[data-testid="transcript-segment"], ' +
-			'.transcript-segment, ' +
-			'[class*="transcript"] [class*="segment"], ' +
-			'[class*="transcript"] [class*="block"], ' +
-			'[class*="Transcript"] [class*="Segment"]'
-		);
-
-		if (containers.length > 0) {
-			containers.forEach(seg => {
-				const speaker = (seg.querySelector('[class*="speaker"], [class*="Speaker"], [data-testid="speaker-name"]') || {}).textContent || '';
-				const text = (seg.querySelector('[class*="text"], [class*="Text"], [class*="content"], p') || seg).textContent || '';
-				const clean = text.replace(speaker, '').trim();
-				if (clean) {
-					segments.push(speaker.trim() ? (speaker.trim() + ': ' + clean) : clean);
-				}
-			});
-			return segments.join('\n\n');
+		const segments = (` + transcriptSegmentsJS + `)();
+		if (segments.length > 0) {
+			return segments.map(s => s.line).join('\n\n');
 		}
 
 		// Fallback: look for a transcript container and get all its text.
@@ -600,6 +844,17 @@ func (b *Browser) scrapeTranscript() string {
 	return result.Value.Str()
 }
 
+// CaptureSnapshot serializes the current page as a single-file MHTML archive
+// (CDP Page.captureSnapshot), including styles, images, and iframes. Call it
+// after ScrapeMeetingPage so tabs opened during scraping are in the DOM.
+func (b *Browser) CaptureSnapshot() ([]byte, error) {
+	res, err := proto.PageCaptureSnapshot{Format: proto.PageCaptureSnapshotFormatMhtml}.Call(b.page)
+	if err != nil {
+		return nil, fmt.Errorf("capture snapshot: %w", err)
+	}
+	return []byte(res.Data), nil
+}
+
 // scrapeHighlights extracts highlights/clips from the meeting page.
 func (b *Browser) scrapeHighlights(ctx context.Context) []Highlight {
 	// Try clicking the highlights tab.
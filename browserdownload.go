@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// ── Browser Download ────────────────────────────────────────────────────────
+//
+// Without --browser-bin, Rod downloads its pinned Chromium the first time a
+// browser is needed. On an air-gapped machine, or behind a proxy that blocks
+// the download hosts, that used to fail with a fetch error that said nothing
+// about what to do. The browser is now resolved in steps: --browser-bin,
+// then Rod's Chromium if it is already downloaded, then the download. When
+// the download fails, an installed Chrome, Chromium, or Edge is used
+// instead; when there is none, the run stops with instructions.
+// --no-download-browser skips the download and goes straight to the
+// installed browser.
+//
+// graindl has no export path that works without a browser, so there is
+// nothing to fall back to beyond that; a Grain workspace export zip can be
+// imported offline with `graindl import-grain-zip`.
+
+// errNoBrowser is returned when no browser can be launched.
+var errNoBrowser = errors.New("no Chromium-based browser available")
+
+// browserSource finds browser binaries; replaced in tests.
+type browserSource struct {
+	dir      string                // where Rod keeps its Chromium
+	cached   func() (string, bool) // Rod's Chromium, when downloaded and working
+	download func(ctx context.Context) (string, error)
+	system   func() (string, bool) // an installed Chrome, Chromium, or Edge
+}
+
+// rodBrowserSource is the browserSource backed by Rod's launcher.
+func rodBrowserSource() browserSource {
+	lb := launcher.NewBrowser()
+	return browserSource{
+		dir: lb.Dir(),
+		cached: func() (string, bool) {
+			return lb.BinPath(), lb.Validate() == nil
+		},
+		download: func(ctx context.Context) (string, error) {
+			lb.Context = ctx
+			return lb.Get()
+		},
+		system: launcher.LookPath,
+	}
+}
+
+// resolveBrowserBin returns the browser binary to launch for cfg.
+func resolveBrowserBin(ctx context.Context, cfg *Config, src browserSource) (string, error) {
+	if cfg.BrowserBin != "" {
+		return cfg.BrowserBin, nil
+	}
+	if bin, ok := src.cached(); ok {
+		return bin, nil
+	}
+	if cfg.NoDownloadBrowser {
+		if sys, ok := src.system(); ok {
+			slog.Info("Using the installed browser (--no-download-browser)", "path", sys)
+			return sys, nil
+		}
+		logNoBrowserHelp(src.dir)
+		return "", fmt.Errorf("%w: Chromium is not downloaded and --no-download-browser is set", errNoBrowser)
+	}
+
+	slog.Info("Downloading Chromium (first browser run only)", "dir", src.dir)
+	bin, err := src.download(ctx)
+	if err == nil {
+		return bin, nil
+	}
+	if sys, ok := src.system(); ok {
+		slog.Warn("Chromium download failed; using the installed browser instead", "path", sys, "error", err)
+		return sys, nil
+	}
+	logNoBrowserHelp(src.dir)
+	return "", fmt.Errorf("%w: Chromium download failed: %w", errNoBrowser, err)
+}
+
+// logNoBrowserHelp explains how to get a browser on a machine that can't
+// download one.
+func logNoBrowserHelp(dir string) {
+	slog.Error("No browser to drive: graindl needs Chromium, Chrome, or Edge to log in to Grain and export meetings. On a machine without internet access:")
+	for _, fix := range []string{
+		"Install Chrome, Chromium, or Edge; it is found automatically, or pass its path with --browser-bin",
+		"Attach to a browser on another machine with --browser-remote ws://host:9222",
+		fmt.Sprintf("Copy Rod's Chromium from a connected machine into %s", dir),
+		"Import a Grain workspace export zip without a browser: graindl import-grain-zip export.zip",
+	} {
+		slog.Info("  • " + fix)
+	}
+}
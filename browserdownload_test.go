@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeBrowserSource reports the given binaries; an empty name is missing.
+func fakeBrowserSource(cached, downloaded, system string, downloads *int) browserSource {
+	return browserSource{
+		dir:    "/cache/rod/browser/chromium-1",
+		cached: func() (string, bool) { return cached, cached != "" },
+		download: func(context.Context) (string, error) {
+			*downloads++
+			if downloaded == "" {
+				return "", errors.New("dial tcp: lookup storage.googleapis.com: no such host")
+			}
+			return downloaded, nil
+		},
+		system: func() (string, bool) { return system, system != "" },
+	}
+}
+
+func TestResolveBrowserBin(t *testing.T) {
+	for _, tc := range []struct {
+		name                       string
+		cfg                        Config
+		cached, downloaded, system string
+		want                       string
+		downloads                  int
+		wantErr                    bool
+	}{
+		{name: "browser-bin", cfg: Config{BrowserBin: "/opt/chrome"}, cached: "/rod/chrome", want: "/opt/chrome"},
+		{name: "cached download", cached: "/rod/chrome", system: "/usr/bin/chromium", want: "/rod/chrome"},
+		{name: "first run downloads", downloaded: "/rod/chrome", system: "/usr/bin/chromium", want: "/rod/chrome", downloads: 1},
+		{name: "blocked download falls back", system: "/usr/bin/chromium", want: "/usr/bin/chromium", downloads: 1},
+		{name: "blocked download, nothing installed", downloads: 1, wantErr: true},
+		{name: "no download uses installed", cfg: Config{NoDownloadBrowser: true}, downloaded: "/rod/chrome", system: "/usr/bin/chromium", want: "/usr/bin/chromium"},
+		{name: "no download keeps cached", cfg: Config{NoDownloadBrowser: true}, cached: "/rod/chrome", system: "/usr/bin/chromium", want: "/rod/chrome"},
+		{name: "no download, nothing installed", cfg: Config{NoDownloadBrowser: true}, downloaded: "/rod/chrome", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			downloads := 0
+			got, err := resolveBrowserBin(context.Background(), &tc.cfg, fakeBrowserSource(tc.cached, tc.downloaded, tc.system, &downloads))
+			if tc.wantErr {
+				if !errors.Is(err, errNoBrowser) {
+					t.Errorf("err = %v, want errNoBrowser", err)
+				}
+			} else if err != nil || got != tc.want {
+				t.Errorf("got %q, %v; want %q", got, err, tc.want)
+			}
+			if downloads != tc.downloads {
+				t.Errorf("%d download attempt(s), want %d", downloads, tc.downloads)
+			}
+		})
+	}
+}
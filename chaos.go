@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── Failure Injection ───────────────────────────────────────────────────────
+//
+// --chaos p=0.1 makes a run fail on purpose, so operators can watch their
+// retries, alerts, and manifest handling deal with failures before trusting
+// graindl with a multi-year archive. It is hidden: -h and shell completion
+// leave it out. The faults are:
+//
+//	scrape    the meeting page scrape fails, as a browser error would
+//	upload    a Google Drive API request answers 503
+//	download  a video or HLS request answers 503
+//	slow      a video or HLS response stalls, then trickles its body
+//
+// "p=0.1" gives every fault that probability per opportunity; a fault's own
+// key overrides it ("p=0.1,slow=0,upload=0.5"). "seed=42" makes the
+// sequence repeatable. Each injected fault is logged as a warning and
+// counted in the run's manifest under "chaos", next to the errors it caused.
+
+// Injectable faults.
+const (
+	chaosScrape   = "scrape"
+	chaosUpload   = "upload"
+	chaosDownload = "download"
+	chaosSlow     = "slow"
+)
+
+var chaosFaults = []string{chaosScrape, chaosUpload, chaosDownload, chaosSlow}
+
+// chaosStall is how long a slow response waits before its headers; its body
+// then arrives at about chaosTrickleRate.
+const (
+	chaosStall       = 5 * time.Second
+	chaosTrickleRate = 256 << 10 // bytes per second
+)
+
+// errChaos marks an injected failure.
+var errChaos = errors.New("chaos: injected failure")
+
+// Chaos injects faults at configured probabilities. A nil *Chaos injects
+// nothing.
+type Chaos struct {
+	prob map[string]float64
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	injected map[string]int
+}
+
+// parseChaos parses --chaos: comma-separated key=value pairs, where the
+// keys are p, seed, and the fault names.
+func parseChaos(s string) (*Chaos, error) {
+	c := &Chaos{prob: map[string]float64{}, injected: map[string]int{}}
+	seed := uint64(time.Now().UnixNano())
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+		if !ok {
+			return nil, fmt.Errorf("invalid --chaos %q: want key=value", part)
+		}
+		if key == "seed" {
+			n, err := strconv.ParseUint(val, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --chaos seed %q", val)
+			}
+			seed = n
+			continue
+		}
+		if key != "p" && !slices.Contains(chaosFaults, key) {
+			return nil, fmt.Errorf("invalid --chaos key %q (use p, seed, or %s)", key, strings.Join(chaosFaults, ", "))
+		}
+		p, err := strconv.ParseFloat(val, 64)
+		if err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("invalid --chaos %s=%q: want a probability from 0 to 1", key, val)
+		}
+		if key == "p" {
+			for _, f := range chaosFaults {
+				if _, set := c.prob[f]; !set {
+					c.prob[f] = p
+				}
+			}
+			continue
+		}
+		c.prob[key] = p
+	}
+	if !slices.ContainsFunc(slices.Collect(maps.Values(c.prob)), func(p float64) bool { return p > 0 }) {
+		return nil, fmt.Errorf("invalid --chaos %q: no fault has a probability above 0", s)
+	}
+	c.rng = rand.New(rand.NewPCG(seed, seed))
+	return c, nil
+}
+
+// roll reports whether fault fires this time, logging and counting it.
+func (c *Chaos) roll(fault, what string) bool {
+	if c == nil || c.prob[fault] <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	hit := c.rng.Float64() < c.prob[fault]
+	if hit {
+		c.injected[fault]++
+	}
+	c.mu.Unlock()
+	if hit {
+		slog.Warn("Chaos: injected "+fault+" fault", "target", what)
+	}
+	return hit
+}
+
+// fail returns an injected error when fault fires, nil otherwise.
+func (c *Chaos) fail(fault, what string) error {
+	if c.roll(fault, what) {
+		return fmt.Errorf("%w (%s)", errChaos, fault)
+	}
+	return nil
+}
+
+// drain returns how many faults of each kind were injected since the last
+// call (one run, in watch mode), or nil.
+func (c *Chaos) drain() map[string]int {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.injected) == 0 {
+		return nil
+	}
+	out := c.injected
+	c.injected = map[string]int{}
+	return out
+}
+
+// withChaos wraps base (nil = http.DefaultTransport) so its requests fail
+// with fault, and with slow also stall and trickle. It returns base
+// unchanged when c is nil.
+func withChaos(c *Chaos, fault string, slow bool, base http.RoundTripper) http.RoundTripper {
+	if c == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &chaosTransport{base: base, chaos: c, fault: fault, slow: slow}
+}
+
+type chaosTransport struct {
+	base  http.RoundTripper
+	chaos *Chaos
+	fault string
+	slow  bool
+}
+
+func (t *chaosTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	what := req.URL.Host + req.URL.Path
+	if t.chaos.roll(t.fault, what) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return &http.Response{
+			Status:     "503 Service Unavailable",
+			StatusCode: http.StatusServiceUnavailable,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader(errChaos.Error())),
+			Request:    req,
+		}, nil
+	}
+	if !t.slow || !t.chaos.roll(chaosSlow, what) {
+		return t.base.RoundTrip(req)
+	}
+	select {
+	case <-time.After(chaosStall):
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil {
+		resp.Body = &trickleBody{ReadCloser: resp.Body}
+	}
+	return resp, err
+}
+
+// trickleBody slows reads to about chaosTrickleRate.
+type trickleBody struct {
+	io.ReadCloser
+}
+
+func (b *trickleBody) Read(p []byte) (int, error) {
+	const chunk = chaosTrickleRate / 10
+	if len(p) > chunk {
+		p = p[:chunk]
+	}
+	time.Sleep(100 * time.Millisecond)
+	return b.ReadCloser.Read(p)
+}
+
+// hideFlagsUsage returns a flag.Usage for fs that leaves out the named
+// flags.
+func hideFlagsUsage(fs *flag.FlagSet, hidden ...string) func() {
+	return func() {
+		visible := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+		visible.SetOutput(fs.Output())
+		fs.VisitAll(func(f *flag.Flag) {
+			if !slices.Contains(hidden, f.Name) {
+				visible.Var(f.Value, f.Name, f.Usage)
+				visible.Lookup(f.Name).DefValue = f.DefValue
+			}
+		})
+		fmt.Fprintf(fs.Output(), "Usage of %s:\n", os.Args[0])
+		visible.PrintDefaults()
+	}
+}
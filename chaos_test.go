@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseChaos(t *testing.T) {
+	c, err := parseChaos("p=0.1, upload=0.5,slow=0,seed=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{chaosScrape: 0.1, chaosDownload: 0.1, chaosUpload: 0.5, chaosSlow: 0}
+	for f, p := range want {
+		if c.prob[f] != p {
+			t.Errorf("%s = %v, want %v", f, c.prob[f], p)
+		}
+	}
+	for _, in := range []string{"", "p", "p=2", "p=-0.1", "boom=0.1", "seed=x,p=0.1", "p=0"} {
+		if _, err := parseChaos(in); err == nil {
+			t.Errorf("parseChaos(%q): want error", in)
+		}
+	}
+}
+
+func TestChaosSeededAndCounted(t *testing.T) {
+	roll := func() []bool {
+		c, _ := parseChaos("scrape=0.5,seed=42")
+		var hits []bool
+		for range 20 {
+			hits = append(hits, c.fail(chaosScrape, "m1") != nil)
+		}
+		return hits
+	}
+	a, b := roll(), roll()
+	if !slices.Equal(a, b) {
+		t.Errorf("same seed, different faults: %v vs %v", a, b)
+	}
+
+	c, _ := parseChaos("scrape=1")
+	if err := c.fail(chaosScrape, "m1"); !errors.Is(err, errChaos) {
+		t.Errorf("fail = %v", err)
+	}
+	if err := c.fail(chaosUpload, "m1"); err != nil {
+		t.Errorf("unset fault fired: %v", err)
+	}
+	if got := c.drain(); got[chaosScrape] != 1 || len(got) != 1 {
+		t.Errorf("drain = %v", got)
+	}
+	if got := c.drain(); got != nil {
+		t.Errorf("second drain = %v, want nil", got)
+	}
+
+	var none *Chaos
+	if none.fail(chaosScrape, "m1") != nil || none.drain() != nil {
+		t.Error("nil Chaos injected")
+	}
+}
+
+func TestChaosTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	c, _ := parseChaos("upload=1")
+	client := &http.Client{Transport: withChaos(c, chaosUpload, false, nil)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", resp.StatusCode)
+	}
+
+	// Other faults leave the transport alone.
+	client.Transport = withChaos(c, chaosDownload, false, nil)
+	resp, err = client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("passthrough = %d %q", resp.StatusCode, body)
+	}
+
+	if rt := withChaos(nil, chaosUpload, true, http.DefaultTransport); rt != http.DefaultTransport {
+		t.Error("nil Chaos wrapped the transport")
+	}
+}
+
+func TestHideFlagsUsage(t *testing.T) {
+	fs := flag.NewFlagSet("graindl", flag.ContinueOnError)
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.String("output", "./recordings", "Output directory")
+	fs.String("chaos", "", "Inject failures")
+	hideFlagsUsage(fs, "chaos")()
+	if got := out.String(); strings.Contains(got, "chaos") || !strings.Contains(got, `-output string`) || !strings.Contains(got, `(default "./recordings")`) {
+		t.Errorf("usage:\n%s", got)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ── Per-meeting Claims ──────────────────────────────────────────────────────
+//
+// --claim-ttl lets several graindl instances (e.g. on different machines)
+// share one output directory over NFS or similar. Before exporting a meeting
+// an instance creates _claims/<id>.claim with O_EXCL; whoever creates it
+// owns the meeting until the claim is released or expires. Held claims are
+// refreshed in the background so long video downloads don't lapse, and a
+// crashed instance's claims become available again after the TTL.
+
+// claimDirName is the claims directory under the output root.
+const claimDirName = "_claims"
+
+// errClaimHeld is returned by Acquire when another live instance owns the
+// meeting.
+var errClaimHeld = errors.New("claimed by another instance")
+
+// claimInfo is the on-disk claim file content.
+type claimInfo struct {
+	Owner     string    `json:"owner"`
+	ClaimedAt time.Time `json:"claimed_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ClaimStore creates and releases claim files for one graindl instance.
+type ClaimStore struct {
+	dir    string
+	ttl    time.Duration
+	owner  string
+	suffix string // random per-instance tag for temp file names
+}
+
+// NewClaimStore returns a store rooted at outputDir/_claims. The owner tag
+// identifies this instance in claim files (host, pid, random suffix).
+func NewClaimStore(outputDir string, ttl time.Duration) *ClaimStore {
+	host, _ := os.Hostname()
+	suffix := randomHex(4)
+	return &ClaimStore{
+		dir:    filepath.Join(outputDir, claimDirName),
+		ttl:    ttl,
+		owner:  fmt.Sprintf("%s:%d:%s", coalesce(host, "unknown"), os.Getpid(), suffix),
+		suffix: suffix,
+	}
+}
+
+// Claim is a held per-meeting claim. Release it when the export finishes.
+type Claim struct {
+	store *ClaimStore
+	path  string
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// Acquire claims meeting id, taking over an expired claim if necessary.
+// Returns errClaimHeld if another instance holds a live claim.
+func (c *ClaimStore) Acquire(id string) (*Claim, error) {
+	if err := ensureDirPrivate(c.dir); err != nil {
+		return nil, fmt.Errorf("claims dir: %w", err)
+	}
+	path := filepath.Join(c.dir, sanitize(id)+".claim")
+
+	if err := c.create(path); err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if !c.takeOver(path) {
+			return nil, errClaimHeld
+		}
+		if err := c.create(path); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				return nil, errClaimHeld // lost the takeover race
+			}
+			return nil, err
+		}
+	}
+
+	cl := &Claim{store: c, path: path, stop: make(chan struct{})}
+	cl.wg.Add(1)
+	go cl.heartbeat()
+	return cl, nil
+}
+
+// create writes a fresh claim file, failing with os.ErrExist if one exists.
+func (c *ClaimStore) create(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(c.newInfo()); err != nil {
+		f.Close()
+		_ = os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+func (c *ClaimStore) newInfo() claimInfo {
+	now := time.Now().UTC()
+	return claimInfo{Owner: c.owner, ClaimedAt: now, ExpiresAt: now.Add(c.ttl)}
+}
+
+// takeOver removes an expired claim at path. To avoid deleting a claim
+// another instance just took over, the file is first renamed to a private
+// name and re-checked; a live claim is linked back.
+func (c *ClaimStore) takeOver(path string) bool {
+	if c.live(path) {
+		return false
+	}
+
+	stale := path + ".stale-" + randomHex(4)
+	if err := os.Rename(path, stale); err != nil {
+		return false // someone else moved it first
+	}
+	defer os.Remove(stale)
+
+	if c.live(stale) {
+		// Grabbed a fresh claim by mistake; put it back unless replaced.
+		_ = os.Link(stale, path)
+		return false
+	}
+	slog.Debug("Took over expired claim", "path", path)
+	return true
+}
+
+// live reports whether the claim at path is unexpired. A claim that can't be
+// parsed (e.g. caught mid-write) is judged by its mtime instead.
+func (c *ClaimStore) live(path string) bool {
+	if info, err := readClaim(path); err == nil {
+		return time.Now().Before(info.ExpiresAt)
+	}
+	st, err := os.Stat(path)
+	return err == nil && time.Since(st.ModTime()) < c.ttl
+}
+
+func readClaim(path string) (*claimInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info claimInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// heartbeat extends the claim every ttl/3 until Release.
+func (cl *Claim) heartbeat() {
+	defer cl.wg.Done()
+	ticker := time.NewTicker(max(cl.store.ttl/3, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cl.stop:
+			return
+		case <-ticker.C:
+			if err := cl.refresh(); err != nil {
+				slog.Warn("Claim refresh failed", "path", cl.path, "error", err)
+			}
+		}
+	}
+}
+
+// refresh rewrites the claim with a new expiry (atomic tmp+rename).
+func (cl *Claim) refresh() error {
+	info := cl.store.newInfo()
+	if cur, err := readClaim(cl.path); err == nil {
+		if cur.Owner != cl.store.owner {
+			return fmt.Errorf("claim now owned by %s", cur.Owner)
+		}
+		info.ClaimedAt = cur.ClaimedAt
+	}
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	tmp := cl.path + ".tmp-" + cl.store.suffix
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cl.path)
+}
+
+// Release stops the heartbeat and removes the claim file if still owned.
+func (cl *Claim) Release() {
+	close(cl.stop)
+	cl.wg.Wait()
+	if cur, err := readClaim(cl.path); err == nil && cur.Owner != cl.store.owner {
+		return
+	}
+	if err := os.Remove(cl.path); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Claim release failed", "path", cl.path, "error", err)
+	}
+}
+
+// randomHex returns n random bytes, hex-encoded.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
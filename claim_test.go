@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ── ClaimStore ──────────────────────────────────────────────────────────────
+
+func TestClaimAcquireRelease(t *testing.T) {
+	dir := t.TempDir()
+	a := NewClaimStore(dir, time.Minute)
+	b := NewClaimStore(dir, time.Minute)
+
+	cl, err := a.Acquire("meeting-1")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	path := filepath.Join(dir, claimDirName, "meeting-1.claim")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("claim file missing: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("claim perms = %o, want 600", perm)
+	}
+
+	if _, err := b.Acquire("meeting-1"); !errors.Is(err, errClaimHeld) {
+		t.Errorf("second instance Acquire = %v, want errClaimHeld", err)
+	}
+
+	cl.Release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("claim file should be removed on release, stat err = %v", err)
+	}
+
+	cl2, err := b.Acquire("meeting-1")
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	cl2.Release()
+}
+
+func TestClaimTakesOverExpired(t *testing.T) {
+	dir := t.TempDir()
+	claimsDir := filepath.Join(dir, claimDirName)
+	if err := ensureDirPrivate(claimsDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crashed instance's claim that expired an hour ago.
+	stale := claimInfo{
+		Owner:     "dead-host:1:deadbeef",
+		ClaimedAt: time.Now().Add(-2 * time.Hour),
+		ExpiresAt: time.Now().Add(-1 * time.Hour),
+	}
+	data, _ := json.Marshal(stale)
+	path := filepath.Join(claimsDir, "meeting-2.claim")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewClaimStore(dir, time.Minute)
+	cl, err := s.Acquire("meeting-2")
+	if err != nil {
+		t.Fatalf("Acquire over expired claim: %v", err)
+	}
+	defer cl.Release()
+
+	info, err := readClaim(path)
+	if err != nil {
+		t.Fatalf("readClaim: %v", err)
+	}
+	if info.Owner != s.owner {
+		t.Errorf("owner = %q, want %q", info.Owner, s.owner)
+	}
+
+	// No stale leftovers in the claims dir.
+	entries, _ := os.ReadDir(claimsDir)
+	if len(entries) != 1 {
+		t.Errorf("claims dir has %d entries, want 1", len(entries))
+	}
+}
+
+func TestClaimUnreadableFreshFileIsHeld(t *testing.T) {
+	dir := t.TempDir()
+	claimsDir := filepath.Join(dir, claimDirName)
+	_ = ensureDirPrivate(claimsDir)
+	// An empty file looks like a claim caught mid-write.
+	_ = os.WriteFile(filepath.Join(claimsDir, "meeting-3.claim"), nil, 0o600)
+
+	s := NewClaimStore(dir, time.Minute)
+	if _, err := s.Acquire("meeting-3"); !errors.Is(err, errClaimHeld) {
+		t.Errorf("Acquire = %v, want errClaimHeld for fresh unreadable claim", err)
+	}
+}
+
+func TestClaimRefreshExtendsExpiry(t *testing.T) {
+	s := NewClaimStore(t.TempDir(), time.Minute)
+	cl, err := s.Acquire("meeting-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Release()
+
+	before, _ := readClaim(cl.path)
+	time.Sleep(10 * time.Millisecond)
+	if err := cl.refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	after, _ := readClaim(cl.path)
+	if !after.ExpiresAt.After(before.ExpiresAt) {
+		t.Errorf("expiry not extended: %v -> %v", before.ExpiresAt, after.ExpiresAt)
+	}
+	if !after.ClaimedAt.Equal(before.ClaimedAt) {
+		t.Errorf("claimed_at changed on refresh")
+	}
+}
+
+// ── exportOne with claims ───────────────────────────────────────────────────
+
+func TestExportOneSkipsClaimedMeeting(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{
+		OutputDir:   dir,
+		SkipVideo:   true,
+		ClaimTTL:    time.Minute,
+		MinDelaySec: 0,
+		MaxDelaySec: 0.01,
+	}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer e.Close()
+
+	other := NewClaimStore(dir, time.Minute)
+	cl, err := other.Acquire("claimed-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Release()
+
+	r := e.exportOne(context.Background(), MeetingRef{ID: "claimed-1", Date: "2025-01-15"})
+	if r.Status != "skipped" {
+		t.Errorf("Status = %q, want skipped", r.Status)
+	}
+	if fileExists(filepath.Join(dir, "2025-01-15", "claimed-1.json")) {
+		t.Error("claimed meeting should not be exported")
+	}
+}
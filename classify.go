@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ── Access Classification ───────────────────────────────────────────────────
+//
+// --classify "*@customer.com->external,*@acme.com->internal" labels each
+// meeting by who was on it, for downstream ACLs: the first rule whose
+// pattern matches one of the meeting's email addresses sets the label, which
+// is stored in the metadata ("classification") and the manifest. Addresses
+// come from the scraped participants and the people the recording is shared
+// with. A pattern without "@" is a domain (customer.com = *@customer.com);
+// patterns are globs, so *@*.customer.com covers subdomains. Put the more
+// restrictive labels first. --classify-route "external->Restricted" sends a
+// label's files to a Drive subfolder, ahead of --gdrive-route.
+
+// classifyRule is a single parsed --classify rule.
+type classifyRule struct {
+	Pattern string // lowercased glob matched against whole email addresses
+	Label   string
+}
+
+var emailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+var classifyLabelRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// parseClassifyRules parses a comma-separated list of pattern->label rules.
+func parseClassifyRules(s string) ([]classifyRule, error) {
+	var rules []classifyRule
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		pattern, label, ok := strings.Cut(rule, "->")
+		if !ok {
+			return nil, fmt.Errorf("rule %q: missing '->'", rule)
+		}
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		label = strings.ToLower(strings.TrimSpace(label))
+		if pattern == "" {
+			return nil, fmt.Errorf("rule %q: empty pattern", rule)
+		}
+		if !strings.Contains(pattern, "@") {
+			pattern = "*@" + pattern
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("rule %q: bad pattern: %w", rule, err)
+		}
+		if !classifyLabelRe.MatchString(label) {
+			return nil, fmt.Errorf("rule %q: label must be letters, digits, '.', '_', or '-'", rule)
+		}
+		rules = append(rules, classifyRule{Pattern: pattern, Label: label})
+	}
+	return rules, nil
+}
+
+// parseClassifyRoutes parses a comma-separated list of label->Folder Drive
+// routes.
+func parseClassifyRoutes(s string) (map[string]string, error) {
+	routes := map[string]string{}
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		label, folder, ok := strings.Cut(rule, "->")
+		if !ok {
+			return nil, fmt.Errorf("route %q: missing '->'", rule)
+		}
+		label = strings.ToLower(strings.TrimSpace(label))
+		if !classifyLabelRe.MatchString(label) {
+			return nil, fmt.Errorf("route %q: invalid label %q", rule, label)
+		}
+		folder = strings.Trim(strings.TrimSpace(folder), "/")
+		if folder == "" {
+			return nil, fmt.Errorf("route %q: empty folder", rule)
+		}
+		for _, seg := range strings.Split(folder, "/") {
+			if seg == "" || seg == "." || seg == ".." {
+				return nil, fmt.Errorf("route %q: invalid folder path %q", rule, folder)
+			}
+		}
+		routes[label] = folder
+	}
+	return routes, nil
+}
+
+// meetingEmails returns the lowercased email addresses in meta's
+// participants and sharing invites.
+func meetingEmails(meta *Metadata) []string {
+	var emails []string
+	seen := map[string]bool{}
+	add := func(s string) {
+		for _, m := range emailRe.FindAllString(s, -1) {
+			m = strings.ToLower(m)
+			if !seen[m] {
+				seen[m] = true
+				emails = append(emails, m)
+			}
+		}
+	}
+	for _, p := range flattenStringSlice(meta.Participants) {
+		add(p)
+	}
+	if meta.Sharing != nil {
+		for _, e := range meta.Sharing.Emails {
+			add(e)
+		}
+	}
+	return emails
+}
+
+// classifyMeeting returns the label of the first rule matching one of
+// meta's email addresses, or "".
+func classifyMeeting(rules []classifyRule, meta *Metadata) string {
+	if len(rules) == 0 || meta == nil {
+		return ""
+	}
+	emails := meetingEmails(meta)
+	for _, rule := range rules {
+		for _, email := range emails {
+			if ok, _ := path.Match(rule.Pattern, email); ok {
+				return rule.Label
+			}
+		}
+	}
+	return ""
+}
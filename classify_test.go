@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseClassifyRules(t *testing.T) {
+	rules, err := parseClassifyRules(" *@Customer.com->External, acme.com->internal ,*@*.partner.io->partner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []classifyRule{
+		{"*@customer.com", "external"},
+		{"*@acme.com", "internal"},
+		{"*@*.partner.io", "partner"},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("rules = %+v", rules)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"customer.com", "->external", "*@x.com->", "[@x.com->external", "*@x.com->a b"} {
+		if _, err := parseClassifyRules(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestParseClassifyRoutes(t *testing.T) {
+	routes, err := parseClassifyRoutes("External->/Restricted/Calls/, internal->Team")
+	if err != nil || routes["external"] != "Restricted/Calls" || routes["internal"] != "Team" {
+		t.Fatalf("routes = %v, %v", routes, err)
+	}
+	for _, bad := range []string{"external", "external->", "external->a/../b", "->Team"} {
+		if _, err := parseClassifyRoutes(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+func TestClassifyMeeting(t *testing.T) {
+	rules, _ := parseClassifyRules("*@customer.com->external,*@*.partner.io->partner,acme.com->internal")
+	for name, tc := range map[string]struct {
+		meta *Metadata
+		want string
+	}{
+		"internal only":   {&Metadata{Participants: []string{"ann@acme.com", "Bob"}}, "internal"},
+		"first rule wins": {&Metadata{Participants: []any{"ann@acme.com", "Carol <Carol@Customer.com>"}}, "external"},
+		"subdomain glob":  {&Metadata{Participants: []string{"dee@eu.partner.io"}}, "partner"},
+		"no subdomain":    {&Metadata{Participants: []string{"eve@eu.customer.com"}}, ""},
+		"shared with": {
+			&Metadata{Participants: []string{"Ann"}, Sharing: &Sharing{Emails: []string{"frank@customer.com"}}},
+			"external",
+		},
+		"names only": {&Metadata{Participants: []string{"Ann", "Bob"}}, ""},
+	} {
+		if got := classifyMeeting(rules, tc.meta); got != tc.want {
+			t.Errorf("%s: label = %q, want %q", name, got, tc.want)
+		}
+	}
+	if got := classifyMeeting(nil, &Metadata{Participants: []string{"ann@acme.com"}}); got != "" {
+		t.Errorf("no rules: label = %q", got)
+	}
+}
+
+func TestClassifyDriveRoute(t *testing.T) {
+	routes, _ := parseDriveRoutes("tag:customer->Customers")
+	d := &DriveUploader{routes: routes, labels: map[string]string{"external": "Restricted"}}
+	if got := d.Route(&Metadata{Tags: []string{"customer"}, Classification: "external"}); got != "Restricted" {
+		t.Errorf("labelled route = %q, want Restricted", got)
+	}
+	if got := d.Route(&Metadata{Tags: []string{"customer"}, Classification: "internal"}); got != "Customers" {
+		t.Errorf("unrouted label = %q, want Customers", got)
+	}
+}
+
+func TestClassificationInFrontmatter(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Renewal", Classification: "external"}
+	for _, format := range []string{"obsidian", "notion", "minutes"} {
+		if md := renderFormattedMarkdown(format, meta, ""); !strings.Contains(md, "classification: external\n") {
+			t.Errorf("%s frontmatter missing classification:\n%s", format, md)
+		}
+	}
+}
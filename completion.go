@@ -0,0 +1,442 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ── Shell Completion ────────────────────────────────────────────────────────
+//
+// `graindl completion bash|zsh|fish` prints a completion script for the
+// subcommands, their flags, and the values of enum-like flags. The
+// exporter's flags are read from the main flag set. Each subcommand
+// declares its flags locally, so they are collected by running it with -h
+// and reading the usage it prints; nothing else runs before flag parsing.
+
+// completionShells are the shells a script can be generated for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// commandSummaries describe the subcommands in zsh and fish completions.
+var commandSummaries = map[string]string{
+	"completion":       "Print a shell completion script",
+	"diff":             "Compare the archive with an older snapshot or another machine's",
+	"digest":           "Markdown summary of recent meetings",
+	"download-videos":  "Download videos queued by --defer-videos",
+	"export":           "Export meetings (the default command)",
+	"gc":               "Find and remove orphaned archive files",
+	"gdrive":           "Upload an existing archive to Google Drive",
+	"hls-convert":      "Convert saved HLS streams to MP4",
+	"import-grain-zip": "Import a Grain workspace export zip",
+	"manifest":         "Query the export manifest by status, date, and video method",
+	"members":          "Export the workspace member directory to members.json",
+	"pick":             "Choose meetings to export interactively",
+	"plan":             "Schedule a rate-limited backfill of the unexported archive",
+	"relink":           "Rewrite absolute paths after moving an archive",
+	"retry":            "Export the dead-lettered meetings again (--dead-letter)",
+	"serve":            "Read-only REST API over the archive",
+	"service":          "Install, check, or remove watch mode as a systemd, launchd, or Windows service",
+	"share":            "Presigned links to a meeting's files",
+	"state":            "Export or import sync and export state for a new machine",
+	"stats":            "Archive-wide meeting statistics",
+	"verify-local":     "Re-hash the archive and report missing or corrupted files",
+	"version":          "Build, tool, and backend report",
+}
+
+// subcommandArgs are the arguments a subcommand needs before its flags.
+// Alternatives share their flags, so only the first is probed.
+var subcommandArgs = map[string][]string{"gdrive": {"sync"}, "manifest": {"query"}, "state": {"export", "import"}}
+
+// completionValues are the accepted values of enum-like flags.
+var completionValues = map[string][]string{
+	"output-format":      {"obsidian", "notion", "minutes"},
+	"notes-format":       {notesFormatJSON, notesFormatMD, notesFormatText},
+	"slug-style":         {slugStyleASCII, slugStyleUnicode},
+	"log-format":         {"color", "json"},
+	"gdrive-conflict":    {"local-wins", "skip", "newer-wins"},
+	"healthcheck-format": {"text", "json"},
+	"compress":           {"zstd", "gzip", "none"},
+	"format":             {"text", "json", "markdown"},
+}
+
+// completionDirs are the flags that take a directory.
+var completionDirs = map[string]bool{
+	"output":      true,
+	"session-dir": true,
+	"watch-dir":   true,
+	"icloud-path": true,
+	"record-http": true,
+	"replay-http": true,
+}
+
+// completionHidden are main flag set flags not worth offering: go-rod
+// registers -rod for its own debugging options, and --chaos is hidden.
+var completionHidden = map[string]bool{"rod": true, "chaos": true}
+
+// completionFlag is one completable flag. Type is the value placeholder
+// flag.PrintDefaults shows ("string", "int", "duration", …); "" is a bool.
+type completionFlag struct {
+	Name  string
+	Usage string
+	Type  string
+}
+
+// kind says how a flag's value is completed: "" (no value), "values",
+// "dir", "file", or "text" for numbers and durations.
+func (f completionFlag) kind() string {
+	switch {
+	case f.Type == "":
+		return ""
+	case completionValues[f.Name] != nil:
+		return "values"
+	case completionDirs[f.Name]:
+		return "dir"
+	case f.Type == "int" || f.Type == "uint" || f.Type == "float" || f.Type == "duration":
+		return "text"
+	}
+	return "file"
+}
+
+// completionCommand is a subcommand and its flags; Name "" is the exporter.
+type completionCommand struct {
+	Name    string
+	Summary string
+	Args    []string // fixed arguments before the flags (gdrive sync)
+	Flags   []completionFlag
+}
+
+func runCompletion(args []string, exporterFlags *flag.FlagSet) int {
+	if len(args) != 1 || !slices.Contains(completionShells, args[0]) {
+		fmt.Fprintf(os.Stderr, "usage: graindl completion %s\n", strings.Join(completionShells, "|"))
+		return 2
+	}
+	cmds := completionCommands(exporterFlags)
+	var err error
+	switch args[0] {
+	case "bash":
+		err = writeBashCompletion(os.Stdout, cmds)
+	case "zsh":
+		err = writeZshCompletion(os.Stdout, cmds)
+	case "fish":
+		err = writeFishCompletion(os.Stdout, cmds)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// exporterCommands are the subcommands that run the exporter and take its
+// flags.
+var exporterCommands = []string{"download-videos", "export", "members", "pick", "plan", "retry"}
+
+// completionCommands collects the exporter and every subcommand, sorted by
+// name. The exporterCommands take the exporter's flags.
+func completionCommands(exporterFlags *flag.FlagSet) []completionCommand {
+	var mainFlags []completionFlag
+	exporterFlags.VisitAll(func(f *flag.Flag) {
+		if completionHidden[f.Name] {
+			return
+		}
+		typ, usage := flag.UnquoteUsage(f)
+		mainFlags = append(mainFlags, completionFlag{Name: f.Name, Usage: usage, Type: typ})
+	})
+
+	cmds := []completionCommand{
+		{Name: "", Flags: mainFlags},
+		{Name: "completion", Summary: commandSummaries["completion"], Args: completionShells},
+	}
+	for _, name := range exporterCommands {
+		cmds = append(cmds, completionCommand{Name: name, Summary: commandSummaries[name], Flags: mainFlags})
+	}
+	for name, run := range subcommands {
+		args := subcommandArgs[name]
+		cmds = append(cmds, completionCommand{
+			Name:    name,
+			Summary: commandSummaries[name],
+			Args:    args,
+			Flags:   probeSubcommandFlags(run, args[:min(len(args), 1)]),
+		})
+	}
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name < cmds[j].Name })
+	return cmds
+}
+
+// probeSubcommandFlags runs a subcommand with -h and parses the flag usage
+// it writes to stderr.
+func probeSubcommandFlags(run func([]string) int, args []string) []completionFlag {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		r.Close()
+		out <- string(data)
+	}()
+	stderr := os.Stderr
+	os.Stderr = w
+	run(append(slices.Clone(args), "-h"))
+	os.Stderr = stderr
+	w.Close()
+	return parseFlagUsage(<-out)
+}
+
+// parseFlagUsage reads flag.PrintDefaults output: "  -name type" lines,
+// each followed by an indented usage line ending in the default value.
+func parseFlagUsage(usage string) []completionFlag {
+	var flags []completionFlag
+	for _, line := range strings.Split(usage, "\n") {
+		switch {
+		case strings.HasPrefix(line, "  -"):
+			fields := strings.Fields(line)
+			f := completionFlag{Name: strings.TrimLeft(fields[0], "-")}
+			if len(fields) > 1 {
+				f.Type = fields[1]
+			}
+			flags = append(flags, f)
+		case strings.HasPrefix(line, "    \t") && len(flags) > 0 && flags[len(flags)-1].Usage == "":
+			flags[len(flags)-1].Usage = usageDefault.ReplaceAllString(strings.TrimSpace(line), "")
+		}
+	}
+	return flags
+}
+
+var usageDefault = regexp.MustCompile(` \(default .*\)$`)
+
+// flagsByKind groups the names of every flag across cmds by kind().
+func flagsByKind(cmds []completionCommand) map[string][]string {
+	kinds := map[string][]string{}
+	seen := map[string]bool{}
+	for _, c := range cmds {
+		for _, f := range c.Flags {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				kinds[f.kind()] = append(kinds[f.kind()], f.Name)
+			}
+		}
+	}
+	for _, names := range kinds {
+		sort.Strings(names)
+	}
+	return kinds
+}
+
+func flagWords(flags []completionFlag) string {
+	words := make([]string, len(flags))
+	for i, f := range flags {
+		words[i] = "--" + f.Name
+	}
+	return strings.Join(words, " ")
+}
+
+func commandNames(cmds []completionCommand) string {
+	var names []string
+	for _, c := range cmds {
+		if c.Name != "" {
+			names = append(names, c.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// shellQuote single-quotes s for sh, zsh and fish.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeBashCompletion(w io.Writer, cmds []completionCommand) error {
+	var b strings.Builder
+	b.WriteString("# bash completion for graindl. Load with: source <(graindl completion bash)\n\n")
+	b.WriteString("_graindl() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    local cmd=\"\"\n")
+	fmt.Fprintf(&b, "    [[ $COMP_CWORD -gt 1 ]] && case \" %s \" in *\" ${COMP_WORDS[1]} \"*) cmd=\"${COMP_WORDS[1]}\" ;; esac\n\n", commandNames(cmds))
+
+	kinds := flagsByKind(cmds)
+	b.WriteString("    case \"$prev\" in\n")
+	for _, name := range kinds["values"] {
+		fmt.Fprintf(&b, "    %s) COMPREPLY=($(compgen -W %s -- \"$cur\")); return ;;\n", bashFlagPattern([]string{name}), shellQuote(strings.Join(completionValues[name], " ")))
+	}
+	for _, k := range []struct{ kind, reply string }{
+		{"dir", `($(compgen -d -- "$cur"))`},
+		{"file", `($(compgen -f -- "$cur"))`},
+		{"text", "()"},
+	} {
+		if len(kinds[k.kind]) > 0 {
+			fmt.Fprintf(&b, "    %s) COMPREPLY=%s; return ;;\n", bashFlagPattern(kinds[k.kind]), k.reply)
+		}
+	}
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    local flags words\n")
+	b.WriteString("    case \"$cmd\" in\n")
+	for _, c := range cmds {
+		if c.Name == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s)\n", c.Name)
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&b, "        if [[ $COMP_CWORD -eq 2 ]]; then COMPREPLY=($(compgen -W %s -- \"$cur\")); return; fi\n", shellQuote(strings.Join(c.Args, " ")))
+		}
+		fmt.Fprintf(&b, "        flags=%s ;;\n", shellQuote(flagWords(c.Flags)))
+	}
+	for _, c := range cmds {
+		if c.Name == "" {
+			b.WriteString("    *)\n")
+			fmt.Fprintf(&b, "        flags=%s\n", shellQuote(flagWords(c.Flags)))
+			fmt.Fprintf(&b, "        [[ $COMP_CWORD -eq 1 ]] && words=%s ;;\n", shellQuote(commandNames(cmds)))
+		}
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n")
+	b.WriteString("    else\n")
+	b.WriteString("        COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	b.WriteString("        [[ ${#COMPREPLY[@]} -eq 0 ]] && COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	b.WriteString("    fi\n")
+	b.WriteString("}\n\n")
+	b.WriteString("complete -F _graindl graindl\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bashFlagPattern is a case pattern matching --name and -name for each flag.
+func bashFlagPattern(names []string) string {
+	var alts []string
+	for _, n := range names {
+		alts = append(alts, "--"+n, "-"+n)
+	}
+	return strings.Join(alts, "|")
+}
+
+func writeZshCompletion(w io.Writer, cmds []completionCommand) error {
+	var b strings.Builder
+	b.WriteString("#compdef graindl\n")
+	b.WriteString("# zsh completion for graindl. Save as _graindl in a directory on $fpath.\n\n")
+	b.WriteString("_graindl() {\n")
+	b.WriteString("    local -a subcmds args\n")
+	b.WriteString("    subcmds=(\n")
+	for _, c := range cmds {
+		if c.Name != "" {
+			fmt.Fprintf(&b, "        %s\n", shellQuote(c.Name+":"+c.Summary))
+		}
+	}
+	b.WriteString("    )\n\n")
+	b.WriteString("    case $words[2] in\n")
+	for _, c := range cmds {
+		if c.Name == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s)\n", c.Name)
+		b.WriteString("        shift words; (( CURRENT-- ))\n")
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&b, "        if (( CURRENT == 2 )); then compadd -- %s; return; fi\n", strings.Join(c.Args, " "))
+			if c.Name != "completion" {
+				b.WriteString("        shift words; (( CURRENT-- ))\n")
+			}
+		}
+		writeZshArgs(&b, c.Flags)
+		b.WriteString("        ;;\n")
+	}
+	for _, c := range cmds {
+		if c.Name == "" {
+			b.WriteString("    *)\n")
+			b.WriteString("        if (( CURRENT == 2 )) && [[ $PREFIX != -* ]]; then\n")
+			b.WriteString("            _describe -t commands 'graindl command' subcmds\n")
+			b.WriteString("            return\n")
+			b.WriteString("        fi\n")
+			writeZshArgs(&b, c.Flags)
+			b.WriteString("        ;;\n")
+		}
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    (( $#args )) && _arguments -S $args\n")
+	b.WriteString("}\n\n")
+	b.WriteString("_graindl \"$@\"\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeZshArgs(b *strings.Builder, flags []completionFlag) {
+	if len(flags) == 0 {
+		return
+	}
+	b.WriteString("        args=(\n")
+	for _, f := range flags {
+		desc := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(f.Usage)
+		spec := "--" + f.Name + "[" + desc + "]"
+		switch f.kind() {
+		case "values":
+			spec += ":" + f.Name + ":(" + strings.Join(completionValues[f.Name], " ") + ")"
+		case "dir":
+			spec += ":directory:_files -/"
+		case "file":
+			spec += ":" + f.Name + ":_files"
+		case "text":
+			spec += ":" + f.Name + ": "
+		}
+		fmt.Fprintf(b, "            %s\n", shellQuote(spec))
+	}
+	b.WriteString("        )\n")
+}
+
+func writeFishCompletion(w io.Writer, cmds []completionCommand) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for graindl. Load with: graindl completion fish | source\n\n")
+	b.WriteString("complete -c graindl -f\n")
+
+	var others []string // subcommands with their own flags
+	for _, c := range cmds {
+		if c.Name != "" && !slices.Contains(exporterCommands, c.Name) {
+			others = append(others, c.Name)
+		}
+	}
+	for _, c := range cmds {
+		if c.Name != "" {
+			fmt.Fprintf(&b, "complete -c graindl -n __fish_use_subcommand -a %s -d %s\n", c.Name, shellQuote(c.Summary))
+		}
+	}
+	for _, c := range cmds {
+		var cond string
+		switch c.Name {
+		case "":
+			cond = "not __fish_seen_subcommand_from " + strings.Join(others, " ")
+		default:
+			cond = "__fish_seen_subcommand_from " + c.Name
+		}
+		b.WriteByte('\n')
+		if len(c.Args) > 0 {
+			fmt.Fprintf(&b, "complete -c graindl -n %s -a %s\n", shellQuote(cond+"; and not __fish_seen_subcommand_from "+strings.Join(c.Args, " ")), shellQuote(strings.Join(c.Args, " ")))
+		}
+		if slices.Contains(exporterCommands, c.Name) {
+			continue // shares the exporter's flags (condition above)
+		}
+		for _, f := range c.Flags {
+			line := fmt.Sprintf("complete -c graindl -n %s -l %s", shellQuote(cond), f.Name)
+			switch f.kind() {
+			case "values":
+				line += " -x -a " + shellQuote(strings.Join(completionValues[f.Name], " "))
+			case "dir":
+				line += " -x -a '(__fish_complete_directories)'"
+			case "file":
+				line += " -r -F"
+			case "text":
+				line += " -x"
+			}
+			if f.Usage != "" {
+				line += " -d " + shellQuote(f.Usage)
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testExporterFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("graindl", flag.ContinueOnError)
+	fs.String("output", "./recordings", "Output directory")
+	fs.String("output-format", "", "Export format: obsidian, notion")
+	fs.Int("max", 0, "Max meetings (0=all)")
+	fs.Bool("verbose", false, "Verbose output")
+	fs.Bool("rod", false, "go-rod debug options")
+	return fs
+}
+
+func TestParseFlagUsage(t *testing.T) {
+	fs := flag.NewFlagSet("x", flag.ContinueOnError)
+	fs.String("since", "7d", "Include meetings since")
+	fs.Bool("apply", false, "Remove files")
+	fs.Duration("interval", time.Minute, "Rescan interval")
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.PrintDefaults()
+
+	got := parseFlagUsage(out.String())
+	want := []completionFlag{
+		{Name: "apply", Usage: "Remove files"},
+		{Name: "interval", Usage: "Rescan interval", Type: "duration"},
+		{Name: "since", Usage: "Include meetings since", Type: "string"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("flag %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCompletionCommands(t *testing.T) {
+	t.Chdir(t.TempDir()) // no .env
+	cmds := completionCommands(testExporterFlags())
+	byName := map[string]completionCommand{}
+	for _, c := range cmds {
+		byName[c.Name] = c
+	}
+	for name := range subcommands {
+		if _, ok := byName[name]; !ok {
+			t.Errorf("subcommand %s missing", name)
+		}
+		if commandSummaries[name] == "" {
+			t.Errorf("subcommand %s has no summary", name)
+		}
+	}
+	if !strings.Contains(flagWords(byName["digest"].Flags), "--since") {
+		t.Errorf("digest flags = %+v", byName["digest"].Flags)
+	}
+	if !strings.Contains(flagWords(byName["gdrive"].Flags), "--gdrive-conflict") {
+		t.Errorf("gdrive sync flags not probed: %+v", byName["gdrive"].Flags)
+	}
+	if got := flagWords(byName["pick"].Flags); got != "--max --output --output-format --verbose" {
+		t.Errorf("pick flags = %q", got)
+	}
+}
+
+// TestBashCompletion sources the generated script and completes a few
+// command lines.
+func TestBashCompletion(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	t.Chdir(t.TempDir())
+	var script bytes.Buffer
+	if err := writeBashCompletion(&script, completionCommands(testExporterFlags())); err != nil {
+		t.Fatal(err)
+	}
+	for line, want := range map[string]string{
+		"graindl dig":                    "digest",
+		"graindl --output-f":             "--output-format",
+		"graindl --output-format no":     "notion",
+		"graindl pick --ve":              "--verbose",
+		"graindl digest --si":            "--since",
+		"graindl gdrive s":               "sync",
+		"graindl completion z":           "zsh",
+		"graindl gc --log-format j":      "json",
+		"graindl import-grain-zip --ove": "--overwrite",
+	} {
+		words := strings.Fields(line)
+		cmd := exec.Command(bash, "-c", script.String()+`
+COMP_WORDS=("$@"); COMP_CWORD=$(( $# - 1 )); _graindl; echo "${COMPREPLY[*]}"`, "_")
+		cmd.Args = append(cmd.Args, words...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", line, err, out)
+		}
+		if got := strings.TrimSpace(string(out)); got != want {
+			t.Errorf("%q completes to %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestZshAndFishCompletion(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cmds := completionCommands(testExporterFlags())
+	var zsh, fish bytes.Buffer
+	if err := writeZshCompletion(&zsh, cmds); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFishCompletion(&fish, cmds); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"#compdef graindl",
+		`'--output-format[Export format: obsidian, notion]:output-format:(obsidian notion minutes)'`,
+		`'--output[Output directory]:directory:_files -/'`,
+		`'share:Presigned links to a meeting'\''s files'`,
+	} {
+		if !strings.Contains(zsh.String(), want) {
+			t.Errorf("zsh script missing %s", want)
+		}
+	}
+	for _, want := range []string{
+		"complete -c graindl -n __fish_use_subcommand -a pick",
+		"-l output-format -x -a 'obsidian notion minutes'",
+		"'__fish_seen_subcommand_from digest' -l since -r -F",
+	} {
+		if !strings.Contains(fish.String(), want) {
+			t.Errorf("fish script missing %s", want)
+		}
+	}
+	for _, sh := range []struct {
+		name   string
+		script string
+	}{{"zsh", zsh.String()}, {"fish", fish.String()}} {
+		if path, err := exec.LookPath(sh.name); err == nil {
+			if out, err := exec.Command(path, "-n", "-c", sh.script).CombinedOutput(); err != nil {
+				t.Errorf("%s syntax: %v\n%s", sh.name, err, out)
+			}
+		}
+	}
+}
+
+func TestRunCompletionUsage(t *testing.T) {
+	if code := runCompletion([]string{"powershell"}, testExporterFlags()); code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+}
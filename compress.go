@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ── Artifact Compression ────────────────────────────────────────────────────
+//
+// --compress zstd|gzip stores a meeting's text artifacts — metadata, the
+// transcript, highlights, and raw AI notes — compressed, as <name>.zst or
+// <name>.gz next to where the plain file would be. Videos, audio, notes,
+// snapshots, the export manifest, and state files stay plain. The manifest
+// lists the stored names and, under "compressed", each file's stored and
+// original size.
+//
+// Everything that reads the archive back (digest, stats, topics, gc,
+// --min-quality, --refresh-analytics, ...) goes through readArtifact, which
+// finds the plain, .zst, or .gz form, so archives can mix all three. A plain
+// file wins when more than one form exists.
+
+// compressionSuffixes maps --compress values to the suffix of stored files.
+var compressionSuffixes = map[string]string{
+	"zstd": ".zst",
+	"gzip": ".gz",
+}
+
+// parseCompress validates a --compress value; "" and "none" turn it off.
+func parseCompress(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" {
+		return "", nil
+	}
+	if _, ok := compressionSuffixes[s]; !ok {
+		return "", fmt.Errorf("invalid --compress %q (must be zstd, gzip, or none)", s)
+	}
+	return s, nil
+}
+
+// compressedExts are the suffixes readArtifact recognizes, in lookup order.
+var compressedExts = []string{".zst", ".gz"}
+
+// CompressedFile is the manifest record of one compressed artifact.
+type CompressedFile struct {
+	Size    int64 `json:"size"`     // bytes on disk
+	RawSize int64 `json:"raw_size"` // bytes after decompression
+}
+
+// CompressedFiles maps stored paths to their compressed and original sizes.
+type CompressedFiles map[string]CompressedFile
+
+// compressedWrite is one compressible file written through CompressedStorage.
+type compressedWrite struct {
+	stored string
+	file   CompressedFile
+}
+
+// CompressedStorage wraps a Storage and compresses text artifacts on write.
+type CompressedStorage struct {
+	Storage
+	codec  string // "zstd" or "gzip"
+	suffix string
+
+	mu      sync.Mutex
+	written map[string]compressedWrite // plain relPath → stored file
+}
+
+// NewCompressedStorage returns s with text artifacts compressed by codec.
+func NewCompressedStorage(s Storage, codec string) *CompressedStorage {
+	return &CompressedStorage{Storage: s, codec: codec, suffix: compressionSuffixes[codec], written: map[string]compressedWrite{}}
+}
+
+// compressible reports whether relPath is a per-meeting text artifact:
+// metadata or sidecar JSON, or a transcript, inside a meeting directory.
+// Root files (_export-manifest.json, _delta.json, ...) and hidden or
+// underscore-prefixed files are never compressed.
+func compressible(relPath string) bool {
+	base := filepath.Base(relPath)
+	if filepath.Dir(relPath) == "." || strings.HasPrefix(base, "_") || strings.HasPrefix(base, ".") {
+		return false
+	}
+	return strings.HasSuffix(base, ".json") || strings.HasSuffix(base, ".transcript.txt")
+}
+
+func (s *CompressedStorage) WriteFile(relPath string, data []byte) error {
+	if !compressible(relPath) {
+		return s.Storage.WriteFile(relPath, data)
+	}
+	enc, err := compressArtifact(s.codec, data)
+	if err != nil {
+		return fmt.Errorf("compress %s: %w", relPath, err)
+	}
+	stored := relPath + s.suffix
+	if err := s.Storage.WriteFile(stored, enc); err != nil {
+		return err
+	}
+	s.removeOtherForms(relPath, stored)
+
+	s.mu.Lock()
+	s.written[relPath] = compressedWrite{stored: stored, file: CompressedFile{Size: int64(len(enc)), RawSize: int64(len(data))}}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *CompressedStorage) WriteJSON(relPath string, v any) error {
+	if !compressible(relPath) {
+		return s.Storage.WriteJSON(relPath, v)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	return s.WriteFile(relPath, data)
+}
+
+// WriteStream streams files that stay uncompressed. Compressible ones are
+// buffered, since they are compressed whole.
+func (s *CompressedStorage) WriteStream(relPath string, render func(w io.Writer) error) error {
+	if !compressible(relPath) {
+		return writeStreamed(s.Storage, relPath, render)
+	}
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	return s.WriteFile(relPath, buf.Bytes())
+}
+
+// BackendStatus forwards to the wrapped storage's mirrors, if any.
+func (s *CompressedStorage) BackendStatus(paths []string) map[string]string {
+	if br, ok := s.Storage.(backendReporter); ok {
+		return br.BackendStatus(paths)
+	}
+	return nil
+}
+
+// removeOtherForms deletes local copies of relPath other than stored — the
+// plain file or the other codec's — left by an export with different
+// --compress settings. Sealed files are kept.
+func (s *CompressedStorage) removeOtherForms(relPath, stored string) {
+	for _, p := range append([]string{relPath}, relPath+".zst", relPath+".gz") {
+		if p == stored {
+			continue
+		}
+		if abs := s.AbsPath(p); !sealed(abs) {
+			_ = os.Remove(abs)
+		}
+	}
+}
+
+// take returns and forgets the stored form of a file written as relPath.
+func (s *CompressedStorage) take(relPath string) (compressedWrite, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.written[relPath]
+	delete(s.written, relPath)
+	return w, ok
+}
+
+// noteCompressed points r's text artifact paths at the compressed files
+// written for them and records their sizes. No-op without --compress.
+func noteCompressed(storage Storage, r *ExportResult) {
+	cs, ok := storage.(*CompressedStorage)
+	if !ok {
+		return
+	}
+	swap := func(p *string) {
+		if *p == "" {
+			return
+		}
+		w, ok := cs.take(*p)
+		if !ok {
+			return
+		}
+		if r.Compressed == nil {
+			r.Compressed = make(CompressedFiles)
+		}
+		*p = w.stored
+		r.Compressed[w.stored] = w.file
+	}
+	swap(&r.MetadataPath)
+	swap(&r.HighlightsPath)
+	swap(&r.AINotesPath)
+	for k, p := range r.TranscriptPaths {
+		swap(&p)
+		r.TranscriptPaths[k] = p
+	}
+}
+
+// artifactExists reports whether relPath exists in storage, plain or
+// compressed.
+func artifactExists(storage Storage, relPath string) bool {
+	if storage.FileExists(relPath) {
+		return true
+	}
+	for _, ext := range compressedExts {
+		if storage.FileExists(relPath + ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressionExt returns the compression suffix of name, or "".
+func compressionExt(name string) string {
+	for _, ext := range compressedExts {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// trimCompressed strips a compression suffix from name.
+func trimCompressed(name string) string {
+	return strings.TrimSuffix(name, compressionExt(name))
+}
+
+// readArtifact reads the artifact at path. A path naming a compressed file
+// is decompressed; a plain path that doesn't exist falls back to its .zst
+// and .gz forms.
+func readArtifact(path string) ([]byte, error) {
+	if ext := compressionExt(path); ext != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return decompressArtifact(ext, data)
+	}
+	data, err := os.ReadFile(path)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return data, err
+	}
+	for _, ext := range compressedExts {
+		if data, cerr := os.ReadFile(path + ext); cerr == nil {
+			return decompressArtifact(ext, data)
+		}
+	}
+	return nil, err
+}
+
+// openArtifact opens the artifact at path for streaming, finding it the way
+// readArtifact does. A compressed file comes back behind a decompressing
+// reader; fi describes the file on disk either way.
+func openArtifact(path string) (rc io.ReadCloser, fi os.FileInfo, err error) {
+	ext := compressionExt(path)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && ext == "" {
+		for _, e := range compressedExts {
+			if cf, cerr := os.Open(path + e); cerr == nil {
+				f, ext, err = cf, e, nil
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if fi, err = f.Stat(); err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	var dec io.ReadCloser
+	switch ext {
+	case "":
+		return f, fi, nil
+	case ".zst":
+		dec, err = newZstdReader(f)
+	case ".gz":
+		dec, err = gzip.NewReader(f)
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return artifactReader{dec, f}, fi, nil
+}
+
+// artifactReader is a decompressing reader that also closes its file.
+type artifactReader struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (r artifactReader) Close() error {
+	r.ReadCloser.Close()
+	return r.file.Close()
+}
+
+// compressArtifact encodes data with codec.
+func compressArtifact(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case "zstd":
+		return zstdCompress(data), nil
+	case "gzip":
+		var buf bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+		if _, err := zw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown codec %q", codec)
+}
+
+// decompressArtifact decodes data stored with the compression suffix ext.
+func decompressArtifact(ext string, data []byte) ([]byte, error) {
+	switch ext {
+	case ".zst":
+		return zstdDecompress(data)
+	case ".gz":
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(io.LimitReader(zr, zstdMaxDecodedSize))
+	}
+	return data, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressible(t *testing.T) {
+	for path, want := range map[string]bool{
+		"2025-01-15/m1.json":               true,
+		"2025-01-15/m1.transcript.txt":     true,
+		"2025-01-15/m1.highlights.json":    true,
+		"2025-01-15/m1.ai-notes.raw.json":  true,
+		"shared/2025-01-15/m1.json":        true,
+		"2025-01-15/m1.md":                 false,
+		"2025-01-15/m1.mp4":                false,
+		"2025-01-15/m1.mhtml":              false,
+		"_export-manifest.json":            false,
+		"_delta.json":                      false,
+		"2025-01-15/_tasks.json":           false,
+		"2025-01-15/.graindl-partial.json": false,
+	} {
+		if got := compressible(path); got != want {
+			t.Errorf("compressible(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestParseCompress(t *testing.T) {
+	for in, want := range map[string]string{"": "", "none": "", "ZSTD": "zstd", " gzip ": "gzip"} {
+		if got, err := parseCompress(in); err != nil || got != want {
+			t.Errorf("parseCompress(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := parseCompress("brotli"); err == nil {
+		t.Error("parseCompress(brotli) accepted")
+	}
+}
+
+func TestCompressedStorage(t *testing.T) {
+	for _, codec := range []string{"zstd", "gzip"} {
+		t.Run(codec, func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := newStorage(&Config{OutputDir: dir, Compress: codec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ext := compressionSuffixes[codec]
+			transcript := longTranscript(200)
+
+			// A plain copy from an uncompressed run is replaced.
+			plain := filepath.Join(dir, "2025-01-15", "m1.transcript.txt")
+			os.MkdirAll(filepath.Dir(plain), 0o755)
+			os.WriteFile(plain, []byte("old"), 0o600)
+
+			if err := s.WriteFile("2025-01-15/m1.transcript.txt", []byte(transcript)); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.WriteJSON("2025-01-15/m1.json", &Metadata{ID: "m1", Title: "Weekly"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.WriteFile("2025-01-15/m1.md", []byte("# Weekly\n")); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.WriteJSON("_export-manifest.json", &ExportManifest{}); err != nil {
+				t.Fatal(err)
+			}
+
+			if fileExists(plain) {
+				t.Error("plain transcript left next to the compressed one")
+			}
+			for _, p := range []string{"2025-01-15/m1.transcript.txt" + ext, "2025-01-15/m1.json" + ext, "2025-01-15/m1.md", "_export-manifest.json"} {
+				if !fileExists(filepath.Join(dir, p)) {
+					t.Errorf("%s not written", p)
+				}
+			}
+			if !artifactExists(s, "2025-01-15/m1.json") {
+				t.Error("artifactExists misses compressed metadata")
+			}
+
+			got, err := readArtifact(plain)
+			if err != nil || string(got) != transcript {
+				t.Errorf("readArtifact = %d bytes, %v", len(got), err)
+			}
+			meta, err := readArchiveMetadata(filepath.Join(dir, "2025-01-15", "m1.json"))
+			if err != nil || meta.Title != "Weekly" {
+				t.Errorf("readArchiveMetadata = %+v, %v", meta, err)
+			}
+
+			r := &ExportResult{MetadataPath: "2025-01-15/m1.json", MarkdownPath: "2025-01-15/m1.md",
+				TranscriptPaths: map[string]string{"text": "2025-01-15/m1.transcript.txt"}}
+			noteCompressed(s, r)
+			if r.MetadataPath != "2025-01-15/m1.json"+ext || r.TranscriptPaths["text"] != "2025-01-15/m1.transcript.txt"+ext || r.MarkdownPath != "2025-01-15/m1.md" {
+				t.Errorf("paths = %s, %v, %s", r.MetadataPath, r.TranscriptPaths, r.MarkdownPath)
+			}
+			c := r.Compressed[r.TranscriptPaths["text"]]
+			if c.RawSize != int64(len(transcript)) || c.Size == 0 || c.Size*3 > c.RawSize {
+				t.Errorf("transcript sizes = %+v", c)
+			}
+			if len(r.Compressed) != 2 {
+				t.Errorf("compressed = %v, want metadata and transcript", r.Compressed)
+			}
+		})
+	}
+}
+
+func TestCompressedArchiveReaders(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := newStorage(&Config{OutputDir: dir, Compress: "zstd"})
+	s.WriteJSON("2025-01-15/m1.json", &Metadata{ID: "m1", Title: "Zipped"})
+	s.WriteFile("2025-01-15/m1.transcript.txt", []byte("00:00:01 Ana: compressed transcript"))
+	s.WriteJSON("2025-01-15/m1.highlights.json", []HighlightClip{{Title: "Clip"}})
+	gz, _ := newStorage(&Config{OutputDir: dir, Compress: "gzip"})
+	gz.WriteJSON("2025-01-16/m2.json", &Metadata{ID: "m2", Title: "Gzipped"})
+	plain := NewLocalStorage(dir)
+	plain.WriteJSON("2025-01-17/m3.json", &Metadata{ID: "m3", Title: "Plain"})
+
+	entries, err := scanArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(entries))
+	}
+	if entries[0].RelBase != filepath.Join("2025-01-15", "m1") {
+		t.Errorf("RelBase = %q", entries[0].RelBase)
+	}
+	if tr := entries[0].Transcript(dir); !strings.Contains(tr, "compressed transcript") {
+		t.Errorf("Transcript = %q", tr)
+	}
+	if hs := entries[0].Highlights(dir); len(hs) != 1 || hs[0].Title != "Clip" {
+		t.Errorf("Highlights = %+v", hs)
+	}
+
+	// gc recognizes compressed artifacts and their meeting.
+	os.Remove(filepath.Join(dir, "2025-01-15", "m1.json.zst"))
+	items, err := findOrphans(dir, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orphans []string
+	for _, it := range items {
+		orphans = append(orphans, it.RelPath)
+	}
+	want := filepath.Join("2025-01-15", "m1.highlights.json.zst") + "," + filepath.Join("2025-01-15", "m1.transcript.txt.zst")
+	if got := strings.Join(orphans, ","); got != want {
+		t.Errorf("orphans = %s, want %s", got, want)
+	}
+}
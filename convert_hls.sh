@@ -6,6 +6,9 @@
 # with status "hls_pending", extracts the HLS URL from the corresponding
 # .m3u8.url file, and converts each stream to MP4 via ffmpeg.
 #
+# Superseded by `graindl hls-convert`, which needs neither jq nor bash 4 and
+# can keep watching the output directory; kept for existing setups.
+#
 # Usage:
 #   ./convert_hls.sh [OPTIONS] [OUTPUT_DIR]
 #
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// ── parseCron ───────────────────────────────────────────────────────────────
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@reboot",
+	} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) should fail", expr)
+		}
+	}
+}
+
+// ── CronSchedule.Next ───────────────────────────────────────────────────────
+
+func TestCronNext(t *testing.T) {
+	loc := time.UTC
+	// 2025-01-15 is a Wednesday.
+	wed := func(h, m int) time.Time { return time.Date(2025, 1, 15, h, m, 30, 0, loc) }
+	at := func(mo time.Month, d, h, m int) time.Time { return time.Date(2025, mo, d, h, m, 0, 0, loc) }
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", wed(10, 0), at(1, 15, 10, 1)},
+		{"0 */2 * * 1-5", wed(10, 0), at(1, 15, 12, 0)},
+		{"0 */2 * * 1-5", wed(23, 0), at(1, 16, 0, 0)},
+		{"0 */2 * * 1-5", time.Date(2025, 1, 17, 23, 0, 0, 0, loc), at(1, 20, 0, 0)}, // Fri → Mon
+		{"30 9-17 * * mon-fri", wed(17, 45), at(1, 16, 9, 30)},
+		{"15,45 * * * *", wed(10, 20), at(1, 15, 10, 45)},
+		{"0 0 1 * *", wed(10, 0), at(2, 1, 0, 0)},
+		{"0 0 * * 0", wed(10, 0), at(1, 19, 0, 0)},
+		{"0 0 * * 7", wed(10, 0), at(1, 19, 0, 0)}, // 7 = Sunday
+		{"@daily", wed(10, 0), at(1, 16, 0, 0)},
+		{"0 12 * jun *", wed(10, 0), at(6, 1, 12, 0)},
+		{"5/20 * * * *", wed(10, 6), at(1, 15, 10, 25)},
+		// Both day fields restricted: either matches (the 20th or any Friday).
+		{"0 0 20 * fri", wed(10, 0), at(1, 17, 0, 0)},
+	}
+	for _, tt := range tests {
+		s, err := parseCron(tt.expr)
+		if err != nil {
+			t.Errorf("parseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.expr, tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	s, err := parseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Next(time.Now()); !got.IsZero() {
+		t.Errorf("31 Feb should never fire, got %v", got)
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ── Custom Fields ───────────────────────────────────────────────────────────
+//
+// Notes a human keeps about a meeting (deal id, account, decisions) live in
+// a sidecar next to its metadata, <date>/<id>.custom.yaml, which graindl
+// only ever reads. Its keys are merged into the formatted note's
+// frontmatter every time the note is rendered, so they survive re-exports
+// and --overwrite instead of being lost with the regenerated .md file:
+//
+//	deal_id: 4711
+//	account: "Acme, Inc."
+//	decisions:
+//	  - Go with vendor A
+//	  - Revisit pricing in Q3
+//
+// A custom key replaces the generated field of the same name, except tags
+// and aliases, whose items are added to the generated ones. grain_id is
+// reserved. The sidecar is a flat mapping of scalars, lists ("- item" or
+// [a, b]) and block scalars (| or >); nested mappings are rejected.
+
+// customFieldsSuffix is appended to a meeting's <date>/<id> base.
+const customFieldsSuffix = ".custom.yaml"
+
+// customMergedLists are frontmatter lists that custom items extend rather
+// than replace.
+var customMergedLists = map[string]bool{"tags": true, "aliases": true}
+
+// customKey matches keys that are safe to write back unquoted.
+var customKey = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// customField is one top-level key of a frontmatter-style mapping.
+type customField struct {
+	Key    string
+	Value  string
+	Items  []string
+	IsList bool
+	plain  bool // unquoted scalar: numbers and booleans keep their type
+}
+
+// write renders f with the frontmatter helpers.
+func (f customField) write(b noteWriter) {
+	switch {
+	case f.IsList:
+		writeYAMLList(b, f.Key, f.Items)
+	case f.plain && isYAMLLiteral(f.Value):
+		b.WriteString(f.Key + ": " + f.Value + "\n")
+	default:
+		writeYAMLField(b, f.Key, f.Value)
+	}
+}
+
+// isYAMLLiteral reports whether s reads as a YAML boolean or number.
+func isYAMLLiteral(s string) bool {
+	switch s {
+	case "true", "false":
+		return true
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// loadCustomFields reads the sidecar at path. A missing sidecar is not an
+// error.
+func loadCustomFields(path string) ([]customField, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseCustomYAML(string(data))
+}
+
+// parseCustomYAML parses the flat YAML subset described above. A repeated
+// key keeps its last value.
+func parseCustomYAML(text string) ([]customField, error) {
+	lines := strings.Split(strings.ReplaceAll(strings.TrimPrefix(text, "\ufeff"), "\r\n", "\n"), "\n")
+	var fields []customField
+	index := map[string]int{}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			return nil, fmt.Errorf("line %d: unexpected indentation", i+1)
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || (value != "" && value[0] != ' ' && value[0] != '\t') {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", i+1)
+		}
+		key = strings.TrimSpace(key)
+		if !customKey.MatchString(key) {
+			return nil, fmt.Errorf("line %d: unsupported key %q", i+1, key)
+		}
+		value = strings.TrimSpace(value)
+
+		f := customField{Key: key}
+		switch {
+		case value == "" || strings.HasPrefix(value, "#"):
+			// Block list (or an empty value).
+			for i+1 < len(lines) && isIndented(lines[i+1]) {
+				i++
+				item := strings.TrimSpace(lines[i])
+				if item == "" || strings.HasPrefix(item, "#") {
+					continue
+				}
+				rest, ok := strings.CutPrefix(item, "-")
+				if !ok || (rest != "" && rest[0] != ' ') {
+					return nil, fmt.Errorf("line %d: nested mappings are not supported", i+1)
+				}
+				v, _, err := parseYAMLScalar(strings.TrimSpace(rest))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+				f.Items = append(f.Items, v)
+			}
+			f.IsList = len(f.Items) > 0
+		case value[0] == '|' || value[0] == '>':
+			var block []string
+			for i+1 < len(lines) && (isIndented(lines[i+1]) || strings.TrimSpace(lines[i+1]) == "") {
+				i++
+				block = append(block, lines[i])
+			}
+			f.Value = blockScalar(block, value[0] == '>')
+		case value[0] == '[':
+			inner, ok := strings.CutSuffix(value, "]")
+			if !ok {
+				return nil, fmt.Errorf("line %d: unterminated list", i+1)
+			}
+			for _, item := range strings.Split(inner[1:], ",") {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				v, _, err := parseYAMLScalar(item)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", i+1, err)
+				}
+				f.Items = append(f.Items, v)
+			}
+			f.IsList = true
+		case value[0] == '{':
+			return nil, fmt.Errorf("line %d: nested mappings are not supported", i+1)
+		default:
+			v, plain, err := parseYAMLScalar(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			f.Value, f.plain = v, plain
+		}
+
+		if j, dup := index[key]; dup {
+			fields[j] = f
+			continue
+		}
+		index[key] = len(fields)
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseYAMLScalar decodes a quoted or plain scalar. plain reports whether
+// it was unquoted.
+func parseYAMLScalar(s string) (value string, plain bool, err error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", false, fmt.Errorf("bad double-quoted string %s", s)
+		}
+		return v, false, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", false, fmt.Errorf("bad single-quoted string %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), false, nil
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == "~" || s == "null" {
+		return "", true, nil
+	}
+	return s, true, nil
+}
+
+// blockScalar joins the lines of a | (literal) or > (folded) block,
+// removing their common indentation.
+func blockScalar(lines []string, folded bool) string {
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if n := len(l) - len(strings.TrimLeft(l, " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if len(l) >= indent && indent >= 0 {
+			l = l[indent:]
+		}
+		out[i] = strings.TrimRight(l, " \t")
+	}
+	text := strings.Trim(strings.Join(out, "\n"), "\n")
+	if !folded {
+		return text
+	}
+	paras := strings.Split(text, "\n\n")
+	for i, p := range paras {
+		paras[i] = strings.Join(strings.Fields(p), " ")
+	}
+	return strings.Join(paras, "\n")
+}
+
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// mergeCustomFields merges custom into md's frontmatter. md is returned
+// unchanged when it has no frontmatter or there is nothing to merge.
+func mergeCustomFields(md string, custom []customField) string {
+	if len(custom) == 0 {
+		return md
+	}
+	body, ok := strings.CutPrefix(md, "---\n")
+	if !ok {
+		return md
+	}
+	front, rest, ok := strings.Cut(body, "\n---\n")
+	if !ok {
+		return md
+	}
+	fields, err := parseCustomYAML(front)
+	if err != nil {
+		return md
+	}
+
+	index := make(map[string]int, len(fields))
+	for i, f := range fields {
+		index[f.Key] = i
+	}
+	for _, c := range custom {
+		if c.Key == "grain_id" {
+			slog.Warn("Custom field grain_id is reserved, ignoring")
+			continue
+		}
+		i, exists := index[c.Key]
+		switch {
+		case !exists:
+			index[c.Key] = len(fields)
+			fields = append(fields, c)
+		case customMergedLists[c.Key]:
+			fields[i].Items = appendUnique(fields[i].Items, c.Items, c.Value)
+			fields[i].IsList = len(fields[i].Items) > 0
+		default:
+			fields[i] = c
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, f := range fields {
+		f.write(&b)
+	}
+	b.WriteString("---\n")
+	b.WriteString(rest)
+	return b.String()
+}
+
+// appendUnique appends the non-empty items not already in list.
+func appendUnique(list []string, items []string, more ...string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, s := range list {
+		seen[s] = true
+	}
+	for _, group := range [][]string{items, more} {
+		for _, s := range group {
+			if s != "" && !seen[s] {
+				seen[s] = true
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
+// noteCustomFields reads the meeting's sidecar, if any.
+func (e *Exporter) noteCustomFields(relBase, id string) []customField {
+	custom, err := loadCustomFields(e.storage.AbsPath(relBase + customFieldsSuffix))
+	if err != nil {
+		slog.Warn("Custom fields ignored", "id", id, "path", relBase+customFieldsSuffix, "error", err)
+		return nil
+	}
+	return custom
+}
+
+// customFieldsWriter merges custom fields into a note streamed through it.
+// The frontmatter is held back until its closing "---" line and written
+// merged; the rest passes straight through. Close writes whatever is still
+// held back.
+type customFieldsWriter struct {
+	w      io.Writer
+	custom []customField
+	head   []byte
+	done   bool
+}
+
+func (c *customFieldsWriter) Write(p []byte) (int, error) {
+	if c.done {
+		return c.w.Write(p)
+	}
+	c.head = append(c.head, p...)
+	end := frontmatterEnd(c.head)
+	if end < 0 {
+		return len(p), nil
+	}
+	if err := c.release(end); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *customFieldsWriter) Close() error {
+	if c.done {
+		return nil
+	}
+	return c.release(len(c.head))
+}
+
+// release writes the first end bytes held back merged, then the rest.
+func (c *customFieldsWriter) release(end int) error {
+	c.done = true
+	head, rest := c.head[:end], c.head[end:]
+	c.head = nil
+	if _, err := io.WriteString(c.w, mergeCustomFields(string(head), c.custom)); err != nil {
+		return err
+	}
+	_, err := c.w.Write(rest)
+	return err
+}
+
+// frontmatterEnd returns the length of the frontmatter md starts with, 0
+// when it has none, or -1 when more of md is needed to tell.
+func frontmatterEnd(md []byte) int {
+	const fence = "---\n"
+	if len(md) < len(fence) {
+		if strings.HasPrefix(fence, string(md)) {
+			return -1
+		}
+		return 0
+	}
+	if string(md[:len(fence)]) != fence {
+		return 0
+	}
+	i := bytes.Index(md[len(fence):], []byte("\n"+fence))
+	if i < 0 {
+		return -1
+	}
+	return len(fence) + i + 1 + len(fence)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ── parseCustomYAML ─────────────────────────────────────────────────────────
+
+func TestParseCustomYAML(t *testing.T) {
+	text := `# CRM notes
+deal_id: 4711
+account: "Acme, Inc."
+owner: 'O''Brien'
+closed: false
+stage: negotiation # updated weekly
+decisions:
+  - Go with vendor A
+  - "Revisit: pricing"
+tags: [acme, renewal]
+summary: |
+  Line one
+  Line two
+folded: >
+  wrapped
+  text
+`
+	fields, err := parseCustomYAML(text)
+	if err != nil {
+		t.Fatalf("parseCustomYAML: %v", err)
+	}
+	got := map[string]customField{}
+	for _, f := range fields {
+		got[f.Key] = f
+	}
+	scalars := map[string]string{
+		"deal_id": "4711",
+		"account": "Acme, Inc.",
+		"owner":   "O'Brien",
+		"closed":  "false",
+		"stage":   "negotiation",
+		"summary": "Line one\nLine two",
+		"folded":  "wrapped text",
+	}
+	for k, want := range scalars {
+		if got[k].Value != want {
+			t.Errorf("%s = %q, want %q", k, got[k].Value, want)
+		}
+	}
+	if !got["deal_id"].plain || got["account"].plain {
+		t.Error("plain should be set for unquoted scalars only")
+	}
+	if want := []string{"Go with vendor A", "Revisit: pricing"}; !reflect.DeepEqual(got["decisions"].Items, want) {
+		t.Errorf("decisions = %q, want %q", got["decisions"].Items, want)
+	}
+	if want := []string{"acme", "renewal"}; !reflect.DeepEqual(got["tags"].Items, want) {
+		t.Errorf("tags = %q, want %q", got["tags"].Items, want)
+	}
+	if fields[0].Key != "deal_id" {
+		t.Errorf("order not preserved: first key %q", fields[0].Key)
+	}
+}
+
+func TestParseCustomYAMLRejectsUnsupported(t *testing.T) {
+	for name, text := range map[string]string{
+		"nested mapping": "crm:\n  deal: 1\n",
+		"flow mapping":   "crm: {deal: 1}\n",
+		"bad key":        "\"quoted key\": 1\n",
+		"no colon":       "just text\n",
+		"bad quote":      "a: \"open\n",
+	} {
+		if _, err := parseCustomYAML(text); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// ── mergeCustomFields ───────────────────────────────────────────────────────
+
+func TestMergeCustomFields(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Weekly: Sync", Date: "2025-06-01T10:00:00Z"}
+	md := renderFormattedMarkdown("obsidian", meta, "")
+	custom, err := parseCustomYAML("deal_id: 4711\ntitle: Renamed\ntags: [acme, grain]\ngrain_id: other\nclosed: true\nnote: \"true\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := mergeCustomFields(md, custom)
+
+	front, body, _ := strings.Cut(strings.TrimPrefix(out, "---\n"), "\n---\n")
+	for _, want := range []string{
+		"title: Renamed\n",
+		"grain_id: m1\n",
+		"deal_id: 4711\n",
+		"closed: true\n",
+		"note: \"true\"\n",
+		"tags:\n  - grain\n  - meeting\n  - acme\n",
+	} {
+		if !strings.Contains(front+"\n", want) {
+			t.Errorf("frontmatter missing %q:\n%s", want, front)
+		}
+	}
+	if strings.Contains(front, "other") {
+		t.Error("grain_id must not be overridden")
+	}
+	if strings.Count(front, "  - grain\n") != 1 {
+		t.Error("merged tags should not repeat")
+	}
+	// Generated fields keep their place; the body is untouched.
+	if !strings.HasPrefix(front, "title: Renamed\ndate: 2025-06-01\n") {
+		t.Errorf("field order changed:\n%s", front)
+	}
+	if !strings.HasPrefix(body, "\n# Weekly: Sync\n") {
+		t.Errorf("body changed: %q", body)
+	}
+}
+
+func TestMergeCustomFieldsRoundTripsGenerated(t *testing.T) {
+	meta := &Metadata{
+		ID:           "m2",
+		Title:        `He said "hi" # 1`,
+		Date:         "2025-06-01",
+		Participants: []any{"Alice", "Bob: PM"},
+		Links:        Links{Grain: "https://grain.com/app/meetings/m2"},
+	}
+	for _, md := range []string{renderFormattedMarkdown("obsidian", meta, ""), renderFormattedMarkdown("notion", meta, "")} {
+		out := mergeCustomFields(md, []customField{{Key: "x", Value: "y"}})
+		if want := strings.Replace(md, "\n---\n", "\nx: y\n---\n", 1); out != want {
+			t.Errorf("generated fields changed:\n got %q\nwant %q", out, want)
+		}
+	}
+}
+
+func TestCustomFieldsWriter(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Weekly: Sync", Date: "2025-06-01"}
+	md := renderFormattedMarkdown("obsidian", meta, strings.Repeat("Ana: hello\n\n", 50))
+	custom := []customField{{Key: "deal_id", Value: "4711", plain: true}}
+	want := mergeCustomFields(md, custom)
+
+	// Whatever the write sizes, the result matches merging the whole note.
+	for _, chunk := range []int{1, 3, 7, 64, len(md)} {
+		var b strings.Builder
+		cw := &customFieldsWriter{w: &b, custom: custom}
+		for rest := md; rest != ""; {
+			n := min(chunk, len(rest))
+			if _, err := io.WriteString(cw, rest[:n]); err != nil {
+				t.Fatal(err)
+			}
+			rest = rest[n:]
+		}
+		if err := cw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != want {
+			t.Errorf("chunk %d: got %q", chunk, b.String())
+		}
+	}
+
+	// Text without frontmatter passes through, also when it ends early.
+	for _, text := range []string{"# Title\nbody\n", "--", "---\ntitle: x\n"} {
+		var b strings.Builder
+		cw := &customFieldsWriter{w: &b, custom: custom}
+		_, _ = io.WriteString(cw, text)
+		_ = cw.Close()
+		if b.String() != text {
+			t.Errorf("%q: got %q", text, b.String())
+		}
+	}
+}
+
+// ── Integration ─────────────────────────────────────────────────────────────
+
+func TestExportOneCustomFieldsSurviveOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, SkipVideo: true, OutputFormat: "obsidian", Overwrite: true, MaxDelaySec: 0.01}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	ref := MeetingRef{ID: "cf-id", Title: "Customer Call", Date: "2025-06-01T10:00:00Z"}
+
+	r := e.exportOne(context.Background(), ref)
+	if r.MarkdownPath == "" {
+		t.Fatalf("no markdown written (status %q: %s)", r.Status, r.ErrorMsg)
+	}
+	sidecar := filepath.Join(dir, strings.TrimSuffix(r.MetadataPath, ".json")+customFieldsSuffix)
+	if err := os.WriteFile(sidecar, []byte("account: Acme\ndecisions:\n  - Renew\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		r = e.exportOne(context.Background(), ref)
+		data, err := os.ReadFile(filepath.Join(dir, r.MarkdownPath))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "account: Acme\ndecisions:\n  - Renew\n---\n") {
+			t.Errorf("export %d: custom fields missing:\n%s", i+1, data)
+		}
+	}
+	if data, _ := os.ReadFile(sidecar); string(data) != "account: Acme\ndecisions:\n  - Renew\n" {
+		t.Error("sidecar must not be modified")
+	}
+}
+
+func TestExportOneInvalidCustomFieldsIgnored(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir, OutputFormat: "notion"}, storage: NewLocalStorage(dir)}
+	if err := os.WriteFile(filepath.Join(dir, "bad"+customFieldsSuffix), []byte("crm:\n  deal: 1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r := &ExportResult{}
+	e.writeFormattedMarkdown(&Metadata{ID: "bad", Title: "T"}, "", "bad", r)
+	data, err := os.ReadFile(filepath.Join(dir, r.MarkdownPath))
+	if err != nil {
+		t.Fatalf("markdown not written: %v", err)
+	}
+	if strings.Contains(string(data), "crm") {
+		t.Error("invalid sidecar should be ignored")
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ── Dead Letter ─────────────────────────────────────────────────────────────
+//
+// A meeting whose page won't scrape or whose video won't download is tried
+// again whenever it is exported again: on every run while nothing could be
+// written for it, and on every run under --overwrite or --min-quality. One
+// that can never succeed (a deleted recording, a page Grain can't render)
+// then costs each run its pacing delay and browser time. With
+// --dead-letter-after N (off by default), after N consecutive failed runs
+// the meeting is dead-lettered instead: failureStateFile marks it, the
+// export skips it after discovery, and _dead-letter/<id>/ keeps what is
+// needed to find out why:
+//
+//	error.json       attempts, first and last failure, the last error
+//	screenshot.png   the meeting page as graindl's browser saw it
+//	page.html        that page's DOM
+//
+// Only Grain-side failures count (ExportResult.grainErr). Local errors — a
+// full disk, an unmounted output directory, a lost claim — and auth failures
+// say nothing about the meeting and leave its entry alone.
+//
+// `graindl retry --dead-letter` re-exports only the dead-lettered meetings; a
+// success takes a meeting off the list and removes its directory. --id
+// always attempts the meeting it names.
+
+// failureStateFile counts consecutive failed runs per meeting. Hidden, like
+// the other state files, so mirrors and Drive sync leave it alone.
+const failureStateFile = ".graindl-failures.json"
+
+// deadLetterDir holds the error artifacts of dead-lettered meetings.
+const deadLetterDir = "_dead-letter"
+
+// deadLetterCaptureTimeout bounds the screenshot and DOM capture.
+const deadLetterCaptureTimeout = 20 * time.Second
+
+// FailureState is the persisted list of failing meetings.
+type FailureState struct {
+	Meetings map[string]*MeetingFailure `json:"meetings"` // meeting ID →
+}
+
+// MeetingFailure is one meeting whose last run(s) failed.
+type MeetingFailure struct {
+	Title        string    `json:"title,omitempty"`
+	Date         string    `json:"date,omitempty"`
+	Attempts     int       `json:"attempts"` // consecutive failed runs
+	FirstFailed  time.Time `json:"first_failed"`
+	LastFailed   time.Time `json:"last_failed"`
+	LastError    string    `json:"last_error"`
+	DeadLettered time.Time `json:"dead_lettered,omitzero"`
+}
+
+// failureStateMu serializes read-modify-write of the failure state between
+// --parallel workers.
+var failureStateMu sync.Mutex
+
+// loadFailureState reads the failure state, falling back to its backup. A
+// missing or unreadable file yields an empty state (nothing dead-lettered).
+func loadFailureState(outputDir string) *FailureState {
+	st := &FailureState{}
+	path := filepath.Join(outputDir, failureStateFile)
+	err := readStateFile(path, func(data []byte) error {
+		st = &FailureState{}
+		return json.Unmarshal(data, st)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Failure state unreadable, no meetings dead-lettered", "path", path, "error", err)
+	}
+	if st.Meetings == nil {
+		st.Meetings = map[string]*MeetingFailure{}
+	}
+	return st
+}
+
+func saveFailureState(outputDir string, st *FailureState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStateFile(filepath.Join(outputDir, failureStateFile), data)
+}
+
+// hasDeadLettered reports whether dead-lettering is on and has skipped
+// meetings, which discovery must then load past --max for.
+func (e *Exporter) hasDeadLettered() bool {
+	if e.cfg.DeadLetterAfter <= 0 {
+		return false
+	}
+	failureStateMu.Lock()
+	defer failureStateMu.Unlock()
+	return len(loadFailureState(e.cfg.OutputDir).deadLettered()) > 0
+}
+
+// deadLettered returns the dead-lettered meetings, oldest first.
+func (st *FailureState) deadLettered() []MeetingRef {
+	var refs []MeetingRef
+	for id, f := range st.Meetings {
+		if !f.DeadLettered.IsZero() {
+			refs = append(refs, MeetingRef{ID: id, Title: f.Title, Date: f.Date, URL: meetingURL(id)})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := st.Meetings[refs[i].ID].DeadLettered, st.Meetings[refs[j].ID].DeadLettered
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return refs[i].ID < refs[j].ID
+	})
+	return refs
+}
+
+// skipDeadLettered drops dead-lettered meetings from a discovered list and
+// counts them in the manifest.
+func (e *Exporter) skipDeadLettered(meetings []MeetingRef) []MeetingRef {
+	if e.cfg.DeadLetterAfter <= 0 {
+		return meetings
+	}
+	failureStateMu.Lock()
+	st := loadFailureState(e.cfg.OutputDir)
+	failureStateMu.Unlock()
+	kept := make([]MeetingRef, 0, len(meetings))
+	for _, m := range meetings {
+		if f := st.Meetings[m.ID]; f != nil && !f.DeadLettered.IsZero() {
+			slog.Debug("Dead-lettered, skipping", "id", m.ID, "attempts", f.Attempts)
+			e.manifest.DeadLettered++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if n := len(meetings) - len(kept); n > 0 {
+		slog.Info("Skipped dead-lettered meetings; retry them with graindl retry --dead-letter", "count", n, "remaining", len(kept))
+	}
+	return kept
+}
+
+// recordFailure updates ref's entry after an export: a success clears it,
+// a Grain-side failure counts one more failed run and dead-letters the
+// meeting at --dead-letter-after. Skips, local and auth errors, exports cut
+// short by shutdown, and videos left alone by videostate.go leave it as is.
+func (e *Exporter) recordFailure(ctx context.Context, ref MeetingRef, r *ExportResult) {
+	// graindl retry keeps the list up to date even with --dead-letter-after 0.
+	if e.cfg.DeadLetterAfter <= 0 && !e.cfg.Retry {
+		return
+	}
+	if ctx.Err() != nil || r.authFailed || r.Status == "skipped" || r.Status == statusAuthBlocked {
+		return
+	}
+	// An error status or unwritten metadata is a local problem: the output
+	// directory, a claim, or the disk.
+	if failedStatus(r.Status) || r.MetadataPath == "" {
+		return
+	}
+	failed := r.grainErr != ""
+	if !failed && r.VideoStatus == videoUnavailable {
+		return // download not attempted, see videostate.go
+	}
+	now := time.Now().UTC()
+	failureStateMu.Lock()
+	st := loadFailureState(e.cfg.OutputDir)
+	f := st.Meetings[ref.ID]
+	var dead *MeetingFailure
+	switch {
+	case !failed && f == nil:
+		failureStateMu.Unlock()
+		return
+	case !failed:
+		delete(st.Meetings, ref.ID)
+		if !f.DeadLettered.IsZero() {
+			slog.Info("Dead-lettered meeting exported; removed from the dead-letter list", "id", ref.ID, "after_attempts", f.Attempts)
+		}
+		_ = os.RemoveAll(filepath.Join(e.cfg.OutputDir, deadLetterDir, sanitize(ref.ID)))
+	default:
+		if f == nil {
+			f = &MeetingFailure{FirstFailed: now}
+			st.Meetings[ref.ID] = f
+		}
+		f.Title, f.Date = coalesce(r.Title, ref.Title, f.Title), coalesce(ref.Date, f.Date)
+		f.Attempts++
+		f.LastFailed, f.LastError = now, redactSecrets(r.grainErr)
+		if f.Attempts >= e.cfg.DeadLetterAfter {
+			if f.DeadLettered.IsZero() {
+				f.DeadLettered = now
+				slog.Warn("Meeting dead-lettered; later runs skip it", "id", ref.ID, "failed_runs", f.Attempts, "error", f.LastError)
+			}
+			copied := *f
+			dead = &copied
+		}
+	}
+	if err := saveFailureState(e.cfg.OutputDir, st); err != nil {
+		slog.Warn("Failure state write failed", "error", err)
+	}
+	failureStateMu.Unlock()
+
+	if dead != nil {
+		e.writeDeadLetter(ctx, ref, dead)
+	}
+}
+
+// writeDeadLetter saves a dead-lettered meeting's error and a capture of
+// its page to _dead-letter/<id>/. Capture failures are recorded in
+// error.json rather than returned.
+func (e *Exporter) writeDeadLetter(ctx context.Context, ref MeetingRef, f *MeetingFailure) {
+	dir := filepath.Join(e.cfg.OutputDir, deadLetterDir, sanitize(ref.ID))
+	if err := ensureDirPrivate(dir); err != nil {
+		slog.Warn("Dead-letter directory creation failed", "id", ref.ID, "error", err)
+		return
+	}
+	pageURL := coalesce(ref.URL, meetingURL(ref.ID))
+	var png []byte
+	var html string
+	captureErr := e.withBrowser(ctx, func(b *Browser) error {
+		var err error
+		png, html, err = b.CaptureFailure(pageURL)
+		return err
+	})
+	for name, data := range map[string][]byte{"screenshot.png": png, "page.html": []byte(html)} {
+		path := filepath.Join(dir, name)
+		if len(data) == 0 {
+			_ = os.Remove(path) // from an earlier failure
+			continue
+		}
+		if err := writeFile(path, data); err != nil {
+			slog.Warn("Dead-letter capture write failed", "path", path, "error", err)
+		}
+	}
+
+	report := struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+		*MeetingFailure
+		CaptureError string `json:"capture_error,omitempty"`
+	}{ID: ref.ID, URL: pageURL, MeetingFailure: f}
+	if captureErr != nil {
+		report.CaptureError = redactSecrets(captureErr.Error())
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = writeFile(filepath.Join(dir, "error.json"), data)
+	}
+	if err != nil {
+		slog.Warn("Dead-letter error write failed", "id", ref.ID, "error", err)
+	}
+}
+
+// CaptureFailure loads a meeting page and returns a full-page screenshot
+// and its DOM. A page that fails to load is captured as it stands.
+func (b *Browser) CaptureFailure(pageURL string) (png []byte, html string, err error) {
+	if err := b.openMeetingPage(pageURL); err != nil {
+		slog.Debug("Meeting page did not load for the dead-letter capture", "url", pageURL, "error", err)
+	}
+	p := b.page.Timeout(deadLetterCaptureTimeout)
+	if png, err = p.Screenshot(true, nil); err != nil {
+		return nil, "", fmt.Errorf("screenshot: %w", err)
+	}
+	if html, err = p.HTML(); err != nil {
+		return png, "", fmt.Errorf("page html: %w", err)
+	}
+	return png, html, nil
+}
+
+// runRetryDeadLetter exports the dead-lettered meetings (graindl retry
+// --dead-letter).
+func (e *Exporter) runRetryDeadLetter(ctx context.Context) error {
+	failureStateMu.Lock()
+	refs := loadFailureState(e.cfg.OutputDir).deadLettered()
+	failureStateMu.Unlock()
+	if len(refs) == 0 {
+		slog.Info("No dead-lettered meetings")
+		return nil
+	}
+	if e.cfg.MaxMeetings > 0 && len(refs) > e.cfg.MaxMeetings {
+		refs = refs[:e.cfg.MaxMeetings]
+	}
+	if e.cfg.DryRun {
+		e.printDryRun(refs)
+		return nil
+	}
+
+	slog.Info("Retrying dead-lettered meetings", "count", len(refs))
+	e.progress = newProgressTracker(len(refs), e.cfg.ProgressInterval)
+	defer func() { e.progress = nil }()
+	e.batchDrive(ctx, func() {
+		if e.cfg.Parallel > 1 {
+			e.exportParallel(ctx, refs)
+		} else {
+			e.exportSequential(ctx, refs)
+		}
+	})
+	e.manifest.Total = len(refs)
+	e.finalizeManifest(ctx)
+	if e.auth.Tripped() {
+		return errAuthBlocked
+	}
+	if e.failures.Exhausted() {
+		return errTooManyErrors
+	}
+	return e.strictErr()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newDeadLetterExporter returns an exporter that dead-letters after two
+// failed runs. Its browser points at a closed port, so page captures fail
+// fast instead of launching Chromium.
+func newDeadLetterExporter(t *testing.T) *Exporter {
+	t.Helper()
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, SessionDir: filepath.Join(dir, ".session"), DeadLetterAfter: 2, BrowserRemote: "ws://127.0.0.1:1"}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+	return e
+}
+
+// grainFailure is an export that wrote minimal metadata after Grain failed.
+func grainFailure(msg string) *ExportResult {
+	return &ExportResult{ID: "m1", Status: "ok", MetadataPath: "2025-03-01/m1.json", grainErr: msg}
+}
+
+func TestRecordFailureDeadLetters(t *testing.T) {
+	e := newDeadLetterExporter(t)
+	ctx := context.Background()
+	ref := MeetingRef{ID: "m1", Title: "Broken Call", Date: "2025-03-01"}
+	dlDir := filepath.Join(e.cfg.OutputDir, deadLetterDir, "m1")
+
+	e.recordFailure(ctx, ref, grainFailure("scrape: first"))
+	if f := loadFailureState(e.cfg.OutputDir).Meetings["m1"]; f == nil || f.Attempts != 1 || !f.DeadLettered.IsZero() {
+		t.Fatalf("after one failure: %+v", f)
+	}
+	if fileExists(dlDir) {
+		t.Error("dead-letter directory written before the threshold")
+	}
+
+	// Skips, local and auth errors, shutdowns, and videos not attempted
+	// don't count, and don't clear the entry either.
+	e.recordFailure(ctx, ref, &ExportResult{ID: "m1", Status: "skipped"})
+	e.recordFailure(ctx, ref, &ExportResult{ID: "m1", Status: "error", ErrorMsg: "mkdir: no space left on device"})
+	e.recordFailure(ctx, ref, &ExportResult{ID: "m1", Status: "ok", grainErr: "video download failed"}) // metadata unwritten
+	e.recordFailure(ctx, ref, &ExportResult{ID: "m1", Status: "error", authFailed: true})
+	e.recordFailure(ctx, ref, &ExportResult{ID: "m1", Status: "ok", MetadataPath: "2025-03-01/m1.json", VideoStatus: videoUnavailable})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	e.recordFailure(cancelled, ref, grainFailure("scrape: context canceled"))
+	if f := loadFailureState(e.cfg.OutputDir).Meetings["m1"]; f == nil || f.Attempts != 1 {
+		t.Fatalf("after ignored results: %+v", f)
+	}
+
+	e.recordFailure(ctx, ref, grainFailure("scrape: page crashed"))
+	st := loadFailureState(e.cfg.OutputDir)
+	if f := st.Meetings["m1"]; f.Attempts != 2 || f.DeadLettered.IsZero() || f.LastError != "scrape: page crashed" || f.Title != "Broken Call" {
+		t.Fatalf("after two failures: %+v", f)
+	}
+	if refs := st.deadLettered(); len(refs) != 1 || refs[0].URL != meetingURL("m1") || refs[0].Date != "2025-03-01" {
+		t.Errorf("deadLettered = %+v", refs)
+	}
+	data, err := os.ReadFile(filepath.Join(dlDir, "error.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		ID           string `json:"id"`
+		Attempts     int    `json:"attempts"`
+		LastError    string `json:"last_error"`
+		CaptureError string `json:"capture_error"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil || report.ID != "m1" || report.Attempts != 2 || report.LastError != "scrape: page crashed" || report.CaptureError == "" {
+		t.Errorf("error.json = %s (%v)", data, err)
+	}
+
+	// A success takes the meeting off the list.
+	e.recordFailure(ctx, ref, &ExportResult{ID: "m1", Status: "ok", MetadataPath: "2025-03-01/m1.json"})
+	if f := loadFailureState(e.cfg.OutputDir).Meetings["m1"]; f != nil {
+		t.Errorf("entry left after success: %+v", f)
+	}
+	if fileExists(dlDir) {
+		t.Error("dead-letter directory left after success")
+	}
+}
+
+func TestSkipDeadLettered(t *testing.T) {
+	e := newDeadLetterExporter(t)
+	now := time.Now().UTC()
+	st := &FailureState{Meetings: map[string]*MeetingFailure{
+		"late":    {Attempts: 3, DeadLettered: now},
+		"early":   {Attempts: 2, DeadLettered: now.Add(-time.Hour)},
+		"failing": {Attempts: 1},
+	}}
+	if err := saveFailureState(e.cfg.OutputDir, st); err != nil {
+		t.Fatal(err)
+	}
+	if refs := st.deadLettered(); len(refs) != 2 || refs[0].ID != "early" || refs[1].ID != "late" {
+		t.Errorf("deadLettered order = %+v", refs)
+	}
+
+	kept := e.skipDeadLettered([]MeetingRef{{ID: "late"}, {ID: "failing"}, {ID: "new"}, {ID: "early"}})
+	var ids []string
+	for _, m := range kept {
+		ids = append(ids, m.ID)
+	}
+	if got := strings.Join(ids, ","); got != "failing,new" {
+		t.Errorf("kept = %s", got)
+	}
+	if e.manifest.DeadLettered != 2 {
+		t.Errorf("manifest.DeadLettered = %d, want 2", e.manifest.DeadLettered)
+	}
+
+	// Discovery loads past --max, since skipped meetings would make it short.
+	e.cfg.MaxMeetings = 5
+	if got := e.discoverLimit(); got != 0 {
+		t.Errorf("discoverLimit = %d with dead-lettered meetings, want 0", got)
+	}
+
+	// --dead-letter-after 0 turns the list off.
+	e.cfg.DeadLetterAfter = 0
+	if got := e.discoverLimit(); got != 5 {
+		t.Errorf("discoverLimit = %d with dead-lettering off, want 5", got)
+	}
+	if kept := e.skipDeadLettered([]MeetingRef{{ID: "late"}}); len(kept) != 1 {
+		t.Error("--dead-letter-after 0 still skipped a meeting")
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ── Deferred Videos ─────────────────────────────────────────────────────────
+//
+// --defer-videos splits an export in two: metadata, transcripts, and notes
+// are written right away, and each meeting's video (or audio, with
+// --audio-only) is queued in videoQueueFile instead of downloaded. `graindl
+// download-videos` — the exporter with the same flags, typically run from
+// cron overnight — works through the queue: it downloads each video with
+// the usual fallback chain, updates the meeting's metadata and manifest
+// entry, and syncs the file to mirrors and Drive. An interrupted run leaves
+// the rest queued. Meetings without a video go to the video state (see
+// videostate.go) and leave the queue; failed audio extractions stay queued
+// for the next run.
+
+// videoQueueFile holds the videos waiting for download-videos. Hidden, like
+// the other state files, so mirrors and Drive sync leave it alone.
+const videoQueueFile = ".graindl-video-queue.json"
+
+// videoDeferred is the ExportResult.VideoStatus of a meeting whose video
+// was queued by --defer-videos.
+const videoDeferred = "deferred"
+
+// VideoQueue is the persisted queue of deferred downloads.
+type VideoQueue struct {
+	Meetings map[string]*QueuedVideo `json:"meetings"` // meeting ID →
+}
+
+// QueuedVideo is one deferred download.
+type QueuedVideo struct {
+	Title     string     `json:"title,omitempty"`
+	URL       string     `json:"url"`
+	RelBase   string     `json:"rel_base"` // artifact path without extension, relative to the output dir
+	Audio     bool       `json:"audio,omitempty"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	Attempts  int        `json:"attempts,omitempty"`
+	LastTried *time.Time `json:"last_tried,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// videoQueueMu serializes read-modify-write of the queue between
+// --parallel workers.
+var videoQueueMu sync.Mutex
+
+// loadVideoQueue reads the queue, falling back to its backup. A missing
+// file yields an empty queue.
+func loadVideoQueue(outputDir string) (*VideoQueue, error) {
+	q := &VideoQueue{}
+	err := readStateFile(filepath.Join(outputDir, videoQueueFile), func(data []byte) error {
+		q = &VideoQueue{}
+		return json.Unmarshal(data, q)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if q.Meetings == nil {
+		q.Meetings = map[string]*QueuedVideo{}
+	}
+	return q, nil
+}
+
+// updateVideoQueue applies fn to the queue and saves it.
+func updateVideoQueue(outputDir string, fn func(q *VideoQueue)) error {
+	videoQueueMu.Lock()
+	defer videoQueueMu.Unlock()
+	q, err := loadVideoQueue(outputDir)
+	if err != nil {
+		return fmt.Errorf("video queue: %w", err)
+	}
+	fn(q)
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStateFile(filepath.Join(outputDir, videoQueueFile), data)
+}
+
+// deferVideo queues ref's video for download-videos and marks r.
+func (e *Exporter) deferVideo(ref MeetingRef, relBase string, r *ExportResult) {
+	item := &QueuedVideo{
+		Title:    ref.Title,
+		URL:      coalesce(ref.URL, meetingURL(ref.ID)),
+		RelBase:  relBase,
+		Audio:    e.cfg.AudioOnly,
+		QueuedAt: time.Now().UTC(),
+	}
+	err := updateVideoQueue(e.cfg.OutputDir, func(q *VideoQueue) { q.Meetings[ref.ID] = item })
+	if err != nil {
+		slog.Warn("Video deferral failed, run without --defer-videos to download it", "id", ref.ID, "error", err)
+		return
+	}
+	r.VideoStatus = videoDeferred
+	slog.Info("Video deferred to graindl download-videos", "id", ref.ID)
+}
+
+// runDeferredVideos downloads the queued videos, oldest first. --id limits
+// the run to one meeting and --max to that many downloads.
+func (e *Exporter) runDeferredVideos(ctx context.Context) error {
+	q, err := loadVideoQueue(e.cfg.OutputDir)
+	if err != nil {
+		return fmt.Errorf("video queue: %w", err)
+	}
+	var ids []string
+	for id := range q.Meetings {
+		if e.cfg.MeetingID == "" || id == e.cfg.MeetingID {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int { return q.Meetings[a].QueuedAt.Compare(q.Meetings[b].QueuedAt) })
+	if e.cfg.MaxMeetings > 0 && len(ids) > e.cfg.MaxMeetings {
+		ids = ids[:e.cfg.MaxMeetings]
+	}
+	if len(ids) == 0 {
+		slog.Info("No deferred videos queued")
+		return nil
+	}
+	if e.cfg.DryRun {
+		fmt.Printf("\n  DRY RUN — %d deferred videos would be downloaded:\n\n", len(ids))
+		for i, id := range ids {
+			item := q.Meetings[id]
+			fmt.Printf("  %3d. %-14s  %s  (queued %s)\n", i+1, id, coalesce(item.Title, "(untitled)"), item.QueuedAt.Format(time.DateOnly))
+		}
+		fmt.Println()
+		return nil
+	}
+
+	slog.Info("Downloading deferred videos", "count", len(ids), "queued", len(q.Meetings))
+	e.manifest.Total = len(ids)
+	var results []*ExportResult
+	e.batchDrive(ctx, func() {
+		for i, id := range ids {
+			if ctx.Err() != nil {
+				break
+			}
+			if e.failures.Exhausted() {
+				slog.Error("Aborting: too many failed downloads", "max_errors", e.cfg.MaxErrors, "remaining", len(ids)-i)
+				break
+			}
+			item := q.Meetings[id]
+			slog.Info(fmt.Sprintf("[%d/%d] %s", i+1, len(ids), coalesce(item.Title, id)))
+			r := e.downloadDeferred(ctx, id, item)
+			if r == nil {
+				break // interrupted while waiting to access Grain
+			}
+			e.failures.Record(r)
+			e.tally(r)
+			e.manifest.Meetings = append(e.manifest.Meetings, r)
+			results = append(results, r)
+		}
+	})
+
+	if err := updateManifestVideos(e.cfg.OutputDir, results); err != nil {
+		slog.Warn("Manifest update failed", "error", err)
+	}
+	left := 0
+	if q, err := loadVideoQueue(e.cfg.OutputDir); err == nil {
+		left = len(q.Meetings)
+	}
+	slog.Info("Deferred videos done", "ok", e.manifest.OK, "hls_pending", e.manifest.HLSPending, "errors", e.manifest.Errors, "still_queued", left)
+	if e.auth.Tripped() {
+		return errAuthBlocked
+	}
+	if e.failures.Exhausted() {
+		return errTooManyErrors
+	}
+	return e.strictErr()
+}
+
+// downloadDeferred downloads one queued video and settles its queue entry.
+// It returns nil when ctx ends before the download starts.
+func (e *Exporter) downloadDeferred(ctx context.Context, id string, item *QueuedVideo) *ExportResult {
+	r := &ExportResult{ID: id, Title: item.Title, DateDir: filepath.Dir(item.RelBase), TranscriptPaths: make(map[string]string)}
+	if !e.paceGrain(ctx, r) {
+		return nil
+	}
+	metaRelPath := item.RelBase + ".json"
+	meta, err := readArchiveMetadata(e.storage.AbsPath(metaRelPath))
+	metaOK := err == nil
+	if !metaOK {
+		slog.Debug("Deferred video: metadata unreadable, downloading without it", "id", id, "error", err)
+		meta = &Metadata{ID: id, Title: item.Title}
+	}
+
+	ref := MeetingRef{ID: id, Title: item.Title, URL: item.URL}
+	if item.Audio {
+		e.writeAudio(ctx, ref, item.RelBase+".m4a", r)
+	} else {
+		e.writeVideo(ctx, ref, meta, item.RelBase+".mp4", r)
+	}
+	if ctx.Err() != nil && r.VideoPath == "" && r.AudioPath == "" {
+		r.Status, r.ErrorMsg = "error", "interrupted"
+		return r // still queued
+	}
+
+	done := r.VideoPath != "" || r.AudioPath != "" || r.VideoStatus == videoUnavailable
+	switch {
+	case r.Status != "":
+	case done:
+		r.Status = "ok"
+	default:
+		r.Status, r.ErrorMsg = "error", "download failed; still queued"
+	}
+	if r.Media != nil && metaOK && !sealed(e.storage.AbsPath(metaRelPath)) {
+		meta.Media = r.Media
+		e.writeMetadata(meta, metaRelPath, r)
+	}
+	e.finishResult(ctx, meta, r)
+
+	err = updateVideoQueue(e.cfg.OutputDir, func(q *VideoQueue) {
+		if done {
+			delete(q.Meetings, id)
+			return
+		}
+		if queued := q.Meetings[id]; queued != nil {
+			now := time.Now().UTC()
+			queued.Attempts++
+			queued.LastTried, queued.LastError = &now, r.ErrorMsg
+		}
+	})
+	if err != nil {
+		slog.Warn("Video queue update failed", "id", id, "error", err)
+	}
+	return r
+}
+
+// updateManifestVideos copies the video outcome of download-videos results
+// into the manifest entries of the export that deferred them. A missing
+// manifest is not an error.
+func updateManifestVideos(dir string, results []*ExportResult) error {
+	const name = "_export-manifest.json"
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	var m ExportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("parse manifest: %w", err)
+	}
+
+	byID := map[string]*ExportResult{}
+	for _, r := range results {
+		if r.Status != "error" {
+			byID[r.ID] = r
+		}
+	}
+	changed := 0
+	for _, entry := range m.Meetings {
+		r := byID[entry.ID]
+		if r == nil || entry.VideoStatus != videoDeferred {
+			continue
+		}
+		entry.VideoPath, entry.VideoMethod, entry.Media, entry.AssetsPath = r.VideoPath, r.VideoMethod, r.Media, r.AssetsPath
+		entry.AudioPath, entry.AudioMethod = r.AudioPath, r.AudioMethod
+		entry.VideoStatus, entry.VideoRetryAt, entry.VideoDuration = r.VideoStatus, r.VideoRetryAt, r.VideoDuration
+		if r.VideoStatus == videoDurationMismatch {
+			m.DurationMismatch++
+		}
+		for path, sum := range r.Checksums {
+			if entry.Checksums == nil {
+				entry.Checksums = map[string]string{}
+			}
+			entry.Checksums[path] = sum
+		}
+		if r.Status == "hls_pending" && entry.Status == "ok" {
+			entry.Status = r.Status
+			m.HLSPending++
+		}
+		changed++
+	}
+	if changed == 0 {
+		return nil
+	}
+	if err := NewLocalStorage(dir).WriteJSON(name, &m); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	slog.Debug("Manifest updated", "videos", changed)
+	return nil
+}
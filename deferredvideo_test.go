@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeferVideoQueuesDownload(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, SessionDir: t.TempDir(), DeferVideos: true, MaxDelaySec: 0.01}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := MeetingRef{ID: "m1", Title: "Sync", Date: "2025-01-15T10:00:00Z", URL: "https://grain.com/app/meetings/m1"}
+	r := e.exportOne(context.Background(), ref)
+	if r.Status != "ok" || r.VideoStatus != videoDeferred || r.VideoPath != "" {
+		t.Fatalf("result = %+v", r)
+	}
+
+	q, err := loadVideoQueue(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := q.Meetings["m1"]
+	if item == nil || item.RelBase != filepath.Join(r.DateDir, "m1") || item.URL != ref.URL || item.Audio {
+		t.Fatalf("queued = %+v", item)
+	}
+}
+
+func TestRunDeferredVideos(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, SessionDir: t.TempDir(), DownloadVideos: true, MaxMeetings: 1}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	updateVideoQueue(dir, func(q *VideoQueue) {
+		q.Meetings["older"] = &QueuedVideo{URL: meetingURL("older"), RelBase: "2025-01-15/older", QueuedAt: now.Add(-time.Hour)}
+		q.Meetings["newer"] = &QueuedVideo{URL: meetingURL("newer"), RelBase: "2025-01-16/newer", QueuedAt: now}
+	})
+	// Known to have no video, so the download settles without a browser.
+	st := &VideoState{Meetings: map[string]*UnavailableVideo{
+		"older": {Status: videoUnavailable, RetryAt: now.Add(time.Hour)},
+	}}
+	data, _ := json.Marshal(st)
+	os.WriteFile(filepath.Join(dir, videoStateFile), data, 0o600)
+
+	if err := e.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.manifest.Meetings) != 1 || e.manifest.Meetings[0].ID != "older" || e.manifest.Meetings[0].VideoStatus != videoUnavailable {
+		t.Fatalf("results = %+v", e.manifest.Meetings)
+	}
+	q, _ := loadVideoQueue(dir)
+	if q.Meetings["older"] != nil || q.Meetings["newer"] == nil {
+		t.Errorf("queue after run = %v", q.Meetings)
+	}
+}
+
+func TestUpdateManifestVideos(t *testing.T) {
+	dir := t.TempDir()
+	m := &ExportManifest{OK: 2, Meetings: []*ExportResult{
+		{ID: "m1", Status: "ok", VideoStatus: videoDeferred},
+		{ID: "m2", Status: "ok", VideoStatus: videoDeferred},
+		{ID: "m3", Status: "ok", VideoPath: "2025-01-15/m3.mp4"},
+	}}
+	NewLocalStorage(dir).WriteJSON("_export-manifest.json", m)
+
+	err := updateManifestVideos(dir, []*ExportResult{
+		{ID: "m1", Status: "ok", VideoPath: "2025-01-15/m1.mp4", VideoMethod: "direct", Checksums: map[string]string{"2025-01-15/m1.mp4": "ab"}},
+		{ID: "m2", Status: "hls_pending", VideoPath: "2025-01-15/m2.m3u8.url", VideoMethod: "hls"},
+		{ID: "m3", Status: "error", ErrorMsg: "download failed; still queued"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ExportManifest
+	data, _ := os.ReadFile(filepath.Join(dir, "_export-manifest.json"))
+	json.Unmarshal(data, &got)
+	m1, m2, m3 := got.Meetings[0], got.Meetings[1], got.Meetings[2]
+	if m1.VideoPath != "2025-01-15/m1.mp4" || m1.VideoStatus != "" || m1.Checksums["2025-01-15/m1.mp4"] != "ab" {
+		t.Errorf("m1 = %+v", m1)
+	}
+	if m2.Status != "hls_pending" || got.HLSPending != 1 || got.OK != 2 {
+		t.Errorf("m2 = %+v, totals ok=%d hls=%d", m2, got.OK, got.HLSPending)
+	}
+	if m3.VideoPath != "2025-01-15/m3.mp4" || m3.Status != "ok" {
+		t.Errorf("m3 = %+v", m3)
+	}
+}
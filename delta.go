@@ -0,0 +1,63 @@
+package main
+
+import "log/slog"
+
+// ── Delta Feed ──────────────────────────────────────────────────────────────
+//
+// _delta.json is rewritten at the end of every run (and every watch cycle)
+// with just the meetings that changed in that run, so downstream scripts
+// can pick up new exports without diffing manifests:
+//
+//	new      exported for the first time
+//	updated  re-exported over existing files (--overwrite) or metadata
+//	         refreshed in place (--refresh-analytics)
+//	failed   errors and auth-blocked meetings
+//
+// Skipped meetings are left out. A run that exports nothing still writes
+// an empty delta, so a consumer never reprocesses the previous run's items.
+
+const deltaFile = "_delta.json"
+
+// ExportDelta lists the meetings that changed in one run. Entries have the
+// same shape as the manifest's meetings.
+type ExportDelta struct {
+	RunAt   string          `json:"run_at"`
+	New     []*ExportResult `json:"new"`
+	Updated []*ExportResult `json:"updated"`
+	Failed  []*ExportResult `json:"failed"`
+}
+
+// buildDelta sorts a manifest's results into new, updated, and failed.
+func buildDelta(m *ExportManifest) *ExportDelta {
+	d := &ExportDelta{RunAt: m.ExportedAt, New: []*ExportResult{}, Updated: []*ExportResult{}, Failed: []*ExportResult{}}
+	for _, r := range m.Meetings {
+		if r == nil {
+			continue // parallel slot never filled (cancelled)
+		}
+		switch r.Status {
+		case "ok", "hls_pending":
+			if r.existed {
+				d.Updated = append(d.Updated, r)
+			} else {
+				d.New = append(d.New, r)
+			}
+		case "skipped":
+			if r.MetadataPath != "" { // refreshed in place
+				d.Updated = append(d.Updated, r)
+			}
+		default:
+			d.Failed = append(d.Failed, r)
+		}
+	}
+	return d
+}
+
+// writeDelta writes this run's delta feed.
+func (e *Exporter) writeDelta() {
+	d := buildDelta(e.manifest)
+	if err := e.storage.WriteJSON(deltaFile, d); err != nil {
+		slog.Error("Delta write failed", "error", err)
+		return
+	}
+	slog.Debug("Delta written", "new", len(d.New), "updated", len(d.Updated), "failed", len(d.Failed))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildDelta(t *testing.T) {
+	m := &ExportManifest{ExportedAt: "2025-03-01T10:00:00Z", Meetings: []*ExportResult{
+		{ID: "new1", Status: "ok"},
+		{ID: "new2", Status: "hls_pending"},
+		{ID: "over", Status: "ok", existed: true},
+		{ID: "skip", Status: "skipped"},
+		{ID: "refresh", Status: "skipped", MetadataPath: "2025-03-01/refresh.json"},
+		{ID: "bad", Status: "error", ErrorMsg: "boom"},
+		{ID: "auth", Status: statusAuthBlocked},
+		nil,
+	}}
+	d := buildDelta(m)
+
+	ids := func(rs []*ExportResult) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+	check := func(name string, got []*ExportResult, want ...string) {
+		t.Helper()
+		g := ids(got)
+		if len(g) != len(want) {
+			t.Errorf("%s = %v, want %v", name, g, want)
+			return
+		}
+		for i := range want {
+			if g[i] != want[i] {
+				t.Errorf("%s = %v, want %v", name, g, want)
+				return
+			}
+		}
+	}
+	check("new", d.New, "new1", "new2")
+	check("updated", d.Updated, "over", "refresh")
+	check("failed", d.Failed, "bad", "auth")
+	if d.RunAt != m.ExportedAt {
+		t.Errorf("RunAt = %q", d.RunAt)
+	}
+}
+
+func TestBuildDeltaEmptyListsNotNull(t *testing.T) {
+	raw, err := json.Marshal(buildDelta(&ExportManifest{ExportedAt: "t"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"run_at":"t","new":[],"updated":[],"failed":[]}`
+	if string(raw) != want {
+		t.Errorf("delta = %s, want %s", raw, want)
+	}
+}
+
+func readDelta(t *testing.T, dir string) ExportDelta {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join(dir, deltaFile))
+	if err != nil {
+		t.Fatalf("read delta: %v", err)
+	}
+	var d ExportDelta
+	if err := json.Unmarshal(raw, &d); err != nil {
+		t.Fatalf("unmarshal delta: %v", err)
+	}
+	return d
+}
+
+func TestRunWritesDeltaPerRun(t *testing.T) {
+	dir := t.TempDir()
+	run := func(overwrite bool) ExportDelta {
+		t.Helper()
+		cfg := &Config{OutputDir: dir, MeetingID: "delta-id", SkipVideo: true, Overwrite: overwrite, MaxDelaySec: 0.01}
+		e, err := NewExporter(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("NewExporter: %v", err)
+		}
+		defer e.Close()
+		if err := e.Run(context.Background()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		return readDelta(t, dir)
+	}
+
+	d := run(false)
+	if len(d.New) != 1 || d.New[0].ID != "delta-id" || d.New[0].MetadataPath == "" {
+		t.Errorf("first run: new = %+v, want delta-id with paths", d.New)
+	}
+
+	// Nothing changes on the second run: the delta must not repeat the
+	// first run's items.
+	d = run(false)
+	if len(d.New)+len(d.Updated)+len(d.Failed) != 0 {
+		t.Errorf("second run: delta = %+v, want empty", d)
+	}
+
+	d = run(true)
+	if len(d.New) != 0 || len(d.Updated) != 1 || d.Updated[0].ID != "delta-id" {
+		t.Errorf("overwrite run: new=%d updated=%+v, want delta-id updated", len(d.New), d.Updated)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, deltaFile)); info.Mode().Perm() != 0o600 {
+		t.Errorf("delta perm = %o, want 600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ── Digest ──────────────────────────────────────────────────────────────────
+//
+// `graindl digest --since 7d` summarizes recently exported meetings into a
+// single markdown file suitable for a team channel or weekly report. It
+// reads only the local archive; no browser or network access.
+
+// digestOptions controls renderDigest.
+type digestOptions struct {
+	Since      time.Time
+	Until      time.Time
+	Highlights int // max highlights per meeting (0 = none)
+}
+
+func runDigest(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to summarize")
+	since := fs.String("since", "7d", "Include meetings since (e.g. 7d, 2w, 36h, 2024-11-01)")
+	out := fs.String("out", "", "Digest file path (default <output>/digest.md, - for stdout)")
+	highlights := fs.Int("highlights", 3, "Top highlights per meeting (0 = none)")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	now := time.Now()
+	cutoff, err := parseSince(*since, now)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+
+	if err := checkUnrouted("digest", *outputDir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	entries, err := scanArchive(*outputDir)
+	if err != nil {
+		slog.Error("Digest failed", "error", err)
+		return 1
+	}
+	entries = filterSince(entries, cutoff)
+
+	md := renderDigest(entries, *outputDir, digestOptions{
+		Since:      cutoff,
+		Until:      now,
+		Highlights: max(*highlights, 0),
+	})
+
+	if *out == "-" {
+		fmt.Print(md)
+		return 0
+	}
+	path := coalesce(*out, filepath.Join(*outputDir, "digest.md"))
+	if err := os.WriteFile(path, []byte(md), 0o600); err != nil {
+		slog.Error("Digest write failed", "error", err)
+		return 1
+	}
+	slog.Info(fmt.Sprintf("Digest: %d meeting(s) → %s", len(entries), path))
+	return 0
+}
+
+// renderDigest builds the digest markdown for entries (already filtered and
+// sorted by date).
+func renderDigest(entries []*ArchiveEntry, outputDir string, opts digestOptions) string {
+	var b strings.Builder
+
+	b.WriteString("# Meeting Digest\n\n")
+	if !opts.Since.IsZero() {
+		fmt.Fprintf(&b, "_%s – %s_\n\n", opts.Since.Format("Jan 2, 2006"), opts.Until.Format("Jan 2, 2006"))
+	}
+
+	if len(entries) == 0 {
+		b.WriteString("No meetings exported in this period.\n")
+		return b.String()
+	}
+
+	var total float64
+	for _, e := range entries {
+		total += durationSeconds(e.Meta.DurationSeconds)
+	}
+	fmt.Fprintf(&b, "**%d meeting(s)**", len(entries))
+	if total > 0 {
+		fmt.Fprintf(&b, " · %s total", formatDuration(total))
+	}
+	b.WriteString("\n")
+
+	for _, e := range entries {
+		m := e.Meta
+		fmt.Fprintf(&b, "\n## %s · %s\n\n", e.Date().Format("Mon Jan 2"), coalesce(m.Title, m.ID))
+
+		if d := durationSeconds(m.DurationSeconds); d > 0 {
+			fmt.Fprintf(&b, "- **Duration:** %s\n", formatDuration(d))
+		}
+		if people := flattenStringSlice(m.Participants); len(people) > 0 {
+			fmt.Fprintf(&b, "- **Participants:** %s\n", strings.Join(people, ", "))
+		}
+		if link := coalesce(m.Links.Share, m.Links.Grain); link != "" {
+			fmt.Fprintf(&b, "- **Link:** %s\n", link)
+		}
+
+		if opts.Highlights > 0 {
+			writeDigestHighlights(&b, e.Highlights(outputDir), opts.Highlights)
+		}
+	}
+	return b.String()
+}
+
+// writeDigestHighlights appends up to n highlights that have text, as
+// blockquotes with speaker and timestamp.
+func writeDigestHighlights(b *strings.Builder, clips []HighlightClip, n int) {
+	var picked []HighlightClip
+	for _, c := range clips {
+		if strings.TrimSpace(coalesce(c.Text, c.Title)) == "" {
+			continue
+		}
+		picked = append(picked, c)
+		if len(picked) == n {
+			break
+		}
+	}
+	if len(picked) == 0 {
+		return
+	}
+
+	b.WriteString("\n**Highlights**\n")
+	for _, c := range picked {
+		text := strings.Join(strings.Fields(coalesce(c.Text, c.Title)), " ")
+		fmt.Fprintf(b, "\n> %s\n", truncateRunes(text, 280))
+		attr := formatTimestamp(c.StartSec)
+		if c.Speaker != "" {
+			attr = c.Speaker + " @ " + attr
+		}
+		if c.URL != "" {
+			attr = fmt.Sprintf("[%s](%s)", attr, c.URL)
+		}
+		fmt.Fprintf(b, "> — %s\n", attr)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ── renderDigest ────────────────────────────────────────────────────────────
+
+func TestRenderDigest(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{
+		ID:              "m1",
+		Title:           "Weekly Sync",
+		DurationSeconds: float64(1800),
+		Participants:    []string{"Alice", "Bob"},
+		Links:           Links{Grain: "https://grain.com/app/meetings/m1"},
+	})
+	writeArchiveMeta(t, dir, "2025-01-16", &Metadata{
+		ID:              "m2",
+		Title:           "Customer Call",
+		DurationSeconds: "45:00",
+		Links:           Links{Grain: "https://grain.com/app/meetings/m2", Share: "https://grain.com/share/m2"},
+	})
+	clips := []HighlightClip{
+		{Text: "", StartSec: 1},
+		{Text: "We ship on Friday.", Speaker: "Alice", StartSec: 75},
+		{Text: "Second point", StartSec: 120},
+	}
+	data, _ := json.Marshal(clips)
+	_ = os.WriteFile(filepath.Join(dir, "2025-01-15", "m1.highlights.json"), data, 0o600)
+
+	entries, err := scanArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := renderDigest(entries, dir, digestOptions{
+		Since:      time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC),
+		Until:      time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		Highlights: 1,
+	})
+
+	for _, want := range []string{
+		"# Meeting Digest",
+		"_Jan 13, 2025 – Jan 20, 2025_",
+		"**2 meeting(s)** · 1h15m00s total",
+		"## Wed Jan 15 · Weekly Sync",
+		"- **Duration:** 30m00s",
+		"- **Participants:** Alice, Bob",
+		"- **Link:** https://grain.com/app/meetings/m1",
+		"> We ship on Friday.\n> — Alice @ 00:01:15",
+		"## Thu Jan 16 · Customer Call",
+		"- **Link:** https://grain.com/share/m2",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("digest missing %q\n---\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Second point") {
+		t.Error("highlights should be capped at 1 per meeting")
+	}
+	if strings.Index(md, "Weekly Sync") > strings.Index(md, "Customer Call") {
+		t.Error("meetings should be in date order")
+	}
+}
+
+func TestRenderDigestEmpty(t *testing.T) {
+	md := renderDigest(nil, t.TempDir(), digestOptions{})
+	if !strings.Contains(md, "No meetings exported") {
+		t.Errorf("empty digest = %q", md)
+	}
+}
+
+// ── runDigest ───────────────────────────────────────────────────────────────
+
+func TestRunDigestWritesFile(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Now().Format("2006-01-02")
+	writeArchiveMeta(t, dir, today, &Metadata{ID: "new", Title: "Today"})
+	writeArchiveMeta(t, dir, "2001-01-01", &Metadata{ID: "old", Title: "Ancient"})
+
+	if code := runDigest([]string{"--output", dir, "--since", "7d"}); code != 0 {
+		t.Fatalf("runDigest exit = %d", code)
+	}
+	path := filepath.Join(dir, "digest.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("digest.md: %v", err)
+	}
+	if !strings.Contains(string(data), "Today") || strings.Contains(string(data), "Ancient") {
+		t.Errorf("digest content:\n%s", data)
+	}
+	info, _ := os.Stat(path)
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("digest perms = %o, want 600", perm)
+	}
+}
+
+func TestRunDigestBadSince(t *testing.T) {
+	if code := runDigest([]string{"--output", t.TempDir(), "--since", "whenever"}); code != 1 {
+		t.Errorf("exit = %d, want 1", code)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ── .env ────────────────────────────────────────────────────────────────────
+// GO-6: returns a map instead of mutating global os.Setenv.
+//
+// The syntax follows what docker compose and most dotenv loaders accept:
+//
+//	# a comment: only at the start of a line
+//	KEY=value                  unquoted: trimmed, otherwise taken as is
+//	export KEY=value           the export prefix is ignored
+//	KEY="multi
+//	line, with \"escapes\" and ${OTHER}/sub"
+//	KEY='literal: no ${EXPANSION}, no \escapes'
+//	include shared.env         relative to the including file
+//
+// Only double-quoted values are rewritten: backslash escapes resolve and
+// ${VAR} / ${VAR:-default} expand, taking the real environment first, then
+// keys defined earlier in the .env (includes included), matching envGet's
+// precedence. Unquoted and single-quoted values are kept byte for byte, so
+// a secret containing "#" or "$" works as it always has. A later definition
+// of a key replaces an earlier one, so an include at the top provides
+// defaults the file can override. A line that doesn't parse is skipped with
+// a warning; the rest of the file still applies.
+
+const (
+	dotEnvMaxBytes = 1 << 20 // per file
+	dotEnvMaxDepth = 8       // nested includes
+)
+
+func loadDotEnv(path string) map[string]string {
+	env := make(map[string]string)
+	warn := func(err error) { slog.Warn("Skipping .env line", "error", err) }
+	if err := readDotEnvFile(path, env, nil, warn); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("Problem reading .env", "error", err)
+	}
+	return env
+}
+
+// readDotEnvFile parses path into env, passing each line it skips to warn.
+// The returned error means the file itself could not be read. stack holds
+// the files including it, outermost first, to catch include cycles.
+func readDotEnvFile(path string, env map[string]string, stack []string, warn func(error)) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(stack, abs) {
+		return fmt.Errorf("%s: include cycle", path)
+	}
+	if len(stack) >= dotEnvMaxDepth {
+		return fmt.Errorf("%s: includes nested more than %d deep", path, dotEnvMaxDepth)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if fi.Size() > dotEnvMaxBytes {
+		return fmt.Errorf("%s: larger than %d bytes", path, dotEnvMaxBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	stack = append(stack, abs)
+	include := func(name string) error {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(filepath.Dir(path), name)
+		}
+		err := readDotEnvFile(name, env, stack, warn)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s not found", name)
+		}
+		return err
+	}
+	for _, err := range parseDotEnv(string(data), env, include) {
+		warn(fmt.Errorf("%s: %w", path, err))
+	}
+	return nil
+}
+
+// parseDotEnv parses src into env, calling include for each include line.
+// It returns one error per line it had to skip.
+func parseDotEnv(src string, env map[string]string, include func(string) error) []error {
+	var errs []error
+	p := &dotEnvParser{src: strings.ReplaceAll(src, "\r\n", "\n"), line: 1, env: env}
+	for p.pos < len(p.src) {
+		start := p.line
+		head := strings.TrimSpace(p.restOfLine())
+		if head == "" || head[0] == '#' {
+			p.nextLine()
+			continue
+		}
+		if name, ok := includeDirective(head); ok {
+			p.nextLine()
+			if name == "" {
+				errs = append(errs, fmt.Errorf("line %d: include needs a file name", start))
+			} else if err := include(name); err != nil {
+				errs = append(errs, fmt.Errorf("line %d: include: %w", start, err))
+			}
+			continue
+		}
+
+		head = strings.TrimPrefix(head, "export ")
+		eq := strings.IndexByte(head, '=')
+		if eq < 0 {
+			p.nextLine()
+			continue
+		}
+		key := strings.TrimSpace(head[:eq])
+		// Position the parser just after the '=' on the raw line.
+		p.pos += strings.IndexByte(p.restOfLine(), '=') + 1
+		val, err := p.value()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %s: %w", start, key, err))
+			continue
+		}
+		if key != "" {
+			env[key] = val
+		}
+	}
+	return errs
+}
+
+// includeDirective reports whether line is "include <file>", returning the
+// file name with any quotes removed.
+func includeDirective(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, "include")
+	if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' || strings.HasPrefix(strings.TrimSpace(rest), "=") {
+		return "", false
+	}
+	name := strings.TrimSpace(rest)
+	if len(name) >= 2 && (name[0] == '"' || name[0] == '\'') && name[len(name)-1] == name[0] {
+		name = name[1 : len(name)-1]
+	}
+	return name, true
+}
+
+// dotEnvParser walks a .env file, tracking the line for error messages.
+type dotEnvParser struct {
+	src  string
+	pos  int
+	line int
+	env  map[string]string
+}
+
+// restOfLine returns the text from pos to the end of the line.
+func (p *dotEnvParser) restOfLine() string {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		return p.src[p.pos : p.pos+i]
+	}
+	return p.src[p.pos:]
+}
+
+// nextLine moves pos past the end of the current line.
+func (p *dotEnvParser) nextLine() {
+	p.pos += len(p.restOfLine())
+	if p.pos < len(p.src) {
+		p.pos++
+		p.line++
+	}
+}
+
+// value reads the value starting at pos and moves past its last line. On
+// an error it moves past the bad line instead; for an unterminated quote
+// that is the line the quote opened on, so the lines after it still parse.
+func (p *dotEnvParser) value() (string, error) {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos == len(p.src) || (p.src[p.pos] != '"' && p.src[p.pos] != '\'') {
+		raw := p.restOfLine()
+		p.nextLine()
+		return strings.TrimSpace(raw), nil
+	}
+
+	quote := p.src[p.pos]
+	openPos, openLine := p.pos, p.line
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos == len(p.src) {
+			p.pos, p.line = openPos, openLine
+			p.nextLine()
+			return "", fmt.Errorf("unterminated %c quote", quote)
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			tail := strings.TrimSpace(p.restOfLine())
+			p.nextLine()
+			if tail != "" && tail[0] != '#' {
+				return "", fmt.Errorf("unexpected %q after closing quote", tail)
+			}
+			if quote == '\'' {
+				return b.String(), nil
+			}
+			return p.expand(b.String())
+		case c == '\\' && quote == '"' && p.pos+1 < len(p.src):
+			// Keep the escape for expand, which resolves it; only the
+			// closing-quote check needs it here.
+			b.WriteByte(c)
+			b.WriteByte(p.src[p.pos+1])
+			if p.src[p.pos+1] == '\n' {
+				p.line++
+			}
+			p.pos += 2
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// expand resolves ${VAR} and ${VAR:-default} in the double-quoted value s,
+// along with the backslash escapes \n \t \r \" \\ and \$.
+func (p *dotEnvParser) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\', '$':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ in %q", s)
+			}
+			name, def, hasDef := strings.Cut(s[i+2:i+end], ":-")
+			if !dotEnvKeyValid(name) {
+				return "", fmt.Errorf("invalid variable name %q", name)
+			}
+			v := p.lookup(name)
+			if v == "" && hasDef {
+				v = def
+			}
+			b.WriteString(v)
+			i += end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// lookup returns name from the real environment, else from the keys
+// parsed so far.
+func (p *dotEnvParser) lookup(name string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return p.env[name]
+}
+
+// dotEnvKeyValid reports whether name is a usable variable name.
+func dotEnvKeyValid(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
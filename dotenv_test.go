@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	t.Setenv("GRAIN_TEST_HOME", "/home/me")
+	src := "# comment\r\n" +
+		"BASE=/data\n" +
+		"  # indented comment\n" +
+		"URL=https://x.test/#anchor\n" +
+		"OUT=\"${BASE}/rec\"\n" +
+		"FROM_ENV=\"${GRAIN_TEST_HOME}/sessions\"\n" +
+		"DEFAULTED=\"${GRAIN_TEST_UNSET:-fallback}\"\n" +
+		"PASSWORD=pa$$word\n" +
+		"SECRET=s3cr #et ${BASE}\n" +
+		"MULTI=\"line one\n" +
+		"line two # not a comment\"\n" +
+		"ESCAPES=\"tab\\there \\\"quoted\\\" \\${BASE} \\\\\"  # comment\n" +
+		"LITERAL='${BASE} \\n as is'\n" +
+		"HASH=\"a # b\"\n" +
+		"SINGLE_MULTI='x\n" +
+		"y'\n" +
+		"AFTER=ok\n"
+	env := map[string]string{}
+	if errs := parseDotEnv(src, env, nil); errs != nil {
+		t.Fatal(errs)
+	}
+	want := map[string]string{
+		"BASE":         "/data",
+		"URL":          "https://x.test/#anchor",
+		"OUT":          "/data/rec",
+		"FROM_ENV":     "/home/me/sessions",
+		"DEFAULTED":    "fallback",
+		"PASSWORD":     "pa$$word",
+		"SECRET":       "s3cr #et ${BASE}",
+		"MULTI":        "line one\nline two # not a comment",
+		"ESCAPES":      "tab\there \"quoted\" ${BASE} \\",
+		"LITERAL":      `${BASE} \n as is`,
+		"HASH":         "a # b",
+		"SINGLE_MULTI": "x\ny",
+		"AFTER":        "ok",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+	if len(env) != len(want) {
+		t.Errorf("got %d keys, want %d: %v", len(env), len(want), env)
+	}
+}
+
+func TestParseDotEnvErrors(t *testing.T) {
+	for _, tc := range []struct{ src, want string }{
+		{"A=1\nB=\"never closed\nC=3\n", "line 2: B: unterminated"},
+		{"A='x' junk\n", "after closing quote"},
+		{"A=\"${UNCLOSED\"\n", "unterminated ${"},
+		{"A=\"${1BAD}\"\n", "invalid variable name"},
+		{"include\n", "line 1: include needs a file name"},
+	} {
+		env := map[string]string{}
+		errs := parseDotEnv(tc.src, env, func(string) error { return nil })
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.want) {
+			t.Errorf("%q: errors %v, want %q", tc.src, errs, tc.want)
+		}
+	}
+	// A bad line is skipped; the lines around it still apply.
+	env := map[string]string{}
+	errs := parseDotEnv("A=1\nC='x' junk\nD=\"${1BAD}\"\nB=\"open\nE=5\n", env, nil)
+	if len(errs) != 3 || env["A"] != "1" || env["E"] != "5" || len(env) != 2 {
+		t.Errorf("partial parse = %v, errors %v", env, errs)
+	}
+}
+
+func TestLoadDotEnvIncludes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("shared/base.env", "GRAIN_OUTPUT_DIR=/archive\nGRAIN_HEADLESS=true\ninclude \"secrets.env\"\n")
+	write("shared/secrets.env", "GRAIN_PASSWORD=s3cret\n")
+	write(".env", "include shared/base.env\nGRAIN_HEADLESS=false\nGRAIN_LOG=\"${GRAIN_OUTPUT_DIR}/log\"\ninclude=not a directive\n")
+
+	env := loadDotEnv(filepath.Join(dir, ".env"))
+	want := map[string]string{
+		"GRAIN_OUTPUT_DIR": "/archive",
+		"GRAIN_HEADLESS":   "false", // the including file overrides
+		"GRAIN_PASSWORD":   "s3cret",
+		"GRAIN_LOG":        "/archive/log",
+		"include":          "not a directive",
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+
+	// A cycle or a missing include is a warning on the include line; the
+	// rest of the file still applies.
+	var warnings []string
+	warn := func(err error) { warnings = append(warnings, err.Error()) }
+	write("a.env", "A=1\ninclude b.env\nAFTER=x\n")
+	write("b.env", "B=2\ninclude a.env\n")
+	env = map[string]string{}
+	err := readDotEnvFile(filepath.Join(dir, "a.env"), env, nil, warn)
+	if err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "include cycle") || env["A"] != "1" || env["B"] != "2" || env["AFTER"] != "x" {
+		t.Errorf("cycle: err %v, warnings %v, env %v", err, warnings, env)
+	}
+	warnings = nil
+	write("c.env", "include missing.env\nC=3\n")
+	env = map[string]string{}
+	err = readDotEnvFile(filepath.Join(dir, "c.env"), env, nil, warn)
+	if err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "c.env: line 1: include: ") || !strings.Contains(warnings[0], "missing.env not found") || env["C"] != "3" {
+		t.Errorf("missing include: err %v, warnings %v, env %v", err, warnings, env)
+	}
+}
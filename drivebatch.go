@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// ── Drive Upload Batches ────────────────────────────────────────────────────
+//
+// An export pass holds its Drive uploads back until every meeting is
+// exported, then checks the bytes the whole batch still has to send against
+// the remaining Drive quota in one reserveQuota call, before the first
+// upload. With --gdrive-quota-guard an over-quota batch uploads nothing,
+// rather than filling Drive with the early meetings and failing on a later
+// one. Outside a batch (export --id) a delivery is a batch of one.
+
+// driveJob is one meeting's Drive delivery waiting for its batch.
+type driveJob struct {
+	meta  *Metadata
+	r     *ExportResult
+	rc    *ExportReceipt
+	paths []string
+	done  func(*UploadStats, error) // records the outcome; nil to ignore it
+
+	todo []string // paths the receipt shows Drive lacks
+	plan *uploadPlan
+}
+
+// driveQueue collects a batch's jobs; parallel workers append to it.
+type driveQueue struct {
+	mu   sync.Mutex
+	jobs []*driveJob
+}
+
+// batchDrive runs export with Drive deliveries queued, then delivers them
+// all against a single quota reservation.
+func (e *Exporter) batchDrive(ctx context.Context, export func()) {
+	if e.drive == nil {
+		export()
+		return
+	}
+	q := &driveQueue{}
+	e.driveQueue = q
+	export()
+	e.driveQueue = nil
+	e.deliverDriveJobs(ctx, q.jobs)
+}
+
+// queueDrive delivers job at the end of the current batch, or right away
+// outside one.
+func (e *Exporter) queueDrive(ctx context.Context, job *driveJob) {
+	if q := e.driveQueue; q != nil {
+		q.mu.Lock()
+		q.jobs = append(q.jobs, job)
+		q.mu.Unlock()
+		return
+	}
+	e.deliverDriveJobs(ctx, []*driveJob{job})
+}
+
+// deliverDriveJobs uploads the artifacts each job's receipt shows Drive has
+// not received yet, reserving quota for all of them first, and records the
+// outcomes in the receipts.
+func (e *Exporter) deliverDriveJobs(ctx context.Context, jobs []*driveJob) {
+	if len(jobs) == 0 {
+		return
+	}
+	var need int64
+	for _, j := range jobs {
+		j.r.DriveRoute = e.drive.Route(j.meta)
+		j.todo = j.rc.pending(deliveryDrive, j.paths)
+		j.plan = e.drive.planUpload(e.cfg.OutputDir, j.todo)
+		need += j.plan.bytes
+	}
+	quotaErr := e.drive.reserveQuota(ctx, need)
+
+	for _, j := range jobs {
+		stats, err := j.plan.stats, quotaErr
+		if err == nil {
+			stats, err = e.drive.sendUpload(ctx, j.r.ID, j.r.DriveRoute, j.plan)
+		}
+		if err != nil {
+			j.rc.failed(deliveryDrive, err)
+		} else {
+			for _, p := range j.paths {
+				if p != "" && !slices.Contains(j.todo, p) {
+					stats.Skipped++ // delivered earlier
+				}
+			}
+			j.rc.delivered(deliveryDrive, j.paths)
+		}
+		if j.done != nil {
+			j.done(stats, err)
+		}
+		e.saveReceipt(j.rc)
+	}
+}
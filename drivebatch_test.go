@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// driveBatchExporter returns an exporter whose Drive has remaining bytes
+// of quota left, with --gdrive-quota-guard on.
+func driveBatchExporter(dir string, f *fakeDriveFiles, remaining int64) *Exporter {
+	return &Exporter{
+		cfg: &Config{OutputDir: dir},
+		drive: &DriveUploader{
+			client:         &http.Client{Transport: f},
+			token:          &oauthToken{AccessToken: "t", Expiry: time.Now().Add(time.Hour)},
+			folderID:       "root",
+			folderMap:      map[string]string{"2025-01-15": "day"},
+			state:          &DriveSyncState{Files: map[string]*SyncEntry{}},
+			statePath:      filepath.Join(dir, "gdrive-sync.json"),
+			quotaGuard:     true,
+			quotaChecked:   true,
+			quotaRemaining: remaining,
+		},
+	}
+}
+
+// queueBatch exports ids as one batch, returning each meeting's outcome.
+func queueBatch(t *testing.T, e *Exporter, dir string, ids ...string) map[string]error {
+	t.Helper()
+	outcomes := map[string]error{}
+	e.batchDrive(context.Background(), func() {
+		for _, id := range ids {
+			r := driveTxnFixture(t, dir, id)
+			rc := loadReceipt(filepath.Join(dir, id+receiptSuffix), id)
+			e.queueDrive(context.Background(), &driveJob{r: r, rc: rc, paths: collectResultPaths(r), done: func(_ *UploadStats, err error) {
+				outcomes[id] = err
+			}})
+			if len(outcomes) > 0 {
+				t.Fatalf("%s delivered before the batch ended", id)
+			}
+		}
+	})
+	return outcomes
+}
+
+func TestDriveBatchQuotaGuard(t *testing.T) {
+	dir := t.TempDir()
+	f := &fakeDriveFiles{}
+	// Each fixture meeting is 63 bytes: one fits, two don't.
+	e := driveBatchExporter(dir, f, 100)
+
+	outcomes := queueBatch(t, e, dir, "m1", "m2")
+	if len(f.uploaded) != 0 {
+		t.Errorf("over-quota batch uploaded %d file(s)", len(f.uploaded))
+	}
+	for _, id := range []string{"m1", "m2"} {
+		var qe *quotaExceededError
+		if !errors.As(outcomes[id], &qe) || qe.Need != 126 {
+			t.Errorf("%s: err = %v, want the batch's quotaExceededError", id, outcomes[id])
+		}
+		rc := loadReceipt(filepath.Join(dir, id+receiptSuffix), id)
+		if d := rc.Deliveries[deliveryDrive]; d == nil || d.Error == "" {
+			t.Errorf("%s: receipt did not record the failure: %+v", id, d)
+		}
+	}
+}
+
+func TestDriveBatchUploads(t *testing.T) {
+	dir := t.TempDir()
+	f := &fakeDriveFiles{}
+	e := driveBatchExporter(dir, f, 1000)
+
+	outcomes := queueBatch(t, e, dir, "m1", "m2")
+	for id, err := range outcomes {
+		if err != nil {
+			t.Errorf("%s: %v", id, err)
+		}
+	}
+	if len(f.uploaded) != 6 {
+		t.Errorf("uploaded %d file(s), want 6", len(f.uploaded))
+	}
+	if e.drive.quotaRemaining != 1000-126 {
+		t.Errorf("remaining = %d, want %d", e.drive.quotaRemaining, 1000-126)
+	}
+	rc := loadReceipt(filepath.Join(dir, "m2"+receiptSuffix), "m2")
+	if d := rc.Deliveries[deliveryDrive]; d == nil || d.Error != "" || d.DeliveredAt == nil {
+		t.Errorf("receipt delivery = %+v", d)
+	}
+}
+
+func TestUploadCost(t *testing.T) {
+	old := &SyncEntry{Size: 400}
+	tests := []struct {
+		name     string
+		action   string
+		entry    *SyncEntry
+		preserve string
+		size     int64
+		want     int64
+	}{
+		{"create", "create", nil, "", 500, 500},
+		{"update grows", "update", old, "", 500, 100},
+		{"update shrinks", "update", old, "", 300, 0},
+		{"update keeps revision", "update", old, "keep-forever", 500, 500},
+		{"update keeps copy", "update", old, "copy", 300, 300},
+	}
+	for _, tt := range tests {
+		d := &DriveUploader{preserve: tt.preserve}
+		if got := d.uploadCost(tt.action, tt.entry, tt.size); got != tt.want {
+			t.Errorf("%s: cost = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ── Drive Upload Transactions ───────────────────────────────────────────────
+//
+// A meeting's files go to Drive one at a time, metadata first and the video
+// last. When an upload fails partway, the Drive folder holds half a meeting:
+// a transcript with no video, a note whose embed is broken. Each meeting's
+// upload is therefore a transaction recorded in the Drive sync state before
+// the first file is sent and removed once the last one is in. A failed
+// upload leaves its transaction open; at the start of the next run, open
+// transactions are resumed from the local files. A transaction that can't
+// be completed, because its local files are gone or it has failed
+// driveTxnMaxAttempts times, is rolled back: the files it created on Drive
+// are moved to the Drive trash (restorable for 30 days) and dropped from the
+// sync state, so a later run or `graindl gdrive sync` uploads the meeting
+// again from scratch. Files the transaction overwrote are left in place;
+// their previous content is in Drive's revision history. Outcomes are
+// reported in the export manifest.
+
+// driveTxnMaxAttempts is how many times a meeting's upload is tried before
+// its partial upload is rolled back.
+const driveTxnMaxAttempts = 3
+
+// Transaction states reported in the manifest.
+const (
+	driveTxnCommitted  = "committed"   // every file uploaded
+	driveTxnPending    = "pending"     // failed; resumed on the next run
+	driveTxnRolledBack = "rolled_back" // created files trashed
+)
+
+// DriveTxn is one meeting's upload in progress.
+type DriveTxn struct {
+	Route     string   `json:"route,omitempty"`   // --gdrive-route folder the meeting goes to
+	Files     []string `json:"files"`             // every file of the meeting, relative to the output dir
+	Created   []string `json:"created,omitempty"` // files this transaction created on Drive
+	StartedAt string   `json:"started_at"`
+	Attempts  int      `json:"attempts"`
+	Error     string   `json:"error,omitempty"` // last failure
+}
+
+// DriveTxnReport is the outcome of resuming an open transaction.
+type DriveTxnReport struct {
+	ID      string `json:"id"`
+	State   string `json:"state"`             // committed, pending, rolled_back
+	Trashed int    `json:"trashed,omitempty"` // files moved to the Drive trash by a rollback
+	Error   string `json:"error,omitempty"`
+}
+
+// beginTxn opens (or reopens, on retry) the transaction for a meeting's
+// upload of files.
+func (d *DriveUploader) beginTxn(id, route string, files []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.state.Transactions == nil {
+		d.state.Transactions = map[string]*DriveTxn{}
+	}
+	tx := d.state.Transactions[id]
+	if tx == nil {
+		tx = &DriveTxn{StartedAt: time.Now().UTC().Format(time.RFC3339)}
+		d.state.Transactions[id] = tx
+	}
+	tx.Route = route
+	for _, f := range files {
+		if !slices.Contains(tx.Files, f) {
+			tx.Files = append(tx.Files, f)
+		}
+	}
+	tx.Attempts++
+}
+
+// txnCreated records that the transaction created relPath on Drive.
+func (d *DriveUploader) txnCreated(id, relPath string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if tx := d.state.Transactions[id]; tx != nil && !slices.Contains(tx.Created, relPath) {
+		tx.Created = append(tx.Created, relPath)
+	}
+}
+
+// endTxn commits the transaction when err is nil, and otherwise records the
+// failure and leaves it open.
+func (d *DriveUploader) endTxn(id string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err == nil {
+		delete(d.state.Transactions, id)
+	} else if tx := d.state.Transactions[id]; tx != nil {
+		tx.Error = redactSecrets(err.Error())
+	}
+}
+
+// ResumeTransactions completes or rolls back the uploads left open by
+// earlier runs, and saves the sync state when any were open.
+func (d *DriveUploader) ResumeTransactions(ctx context.Context, outputDir string) []DriveTxnReport {
+	d.mu.Lock()
+	ids := make([]string, 0, len(d.state.Transactions))
+	for id := range d.state.Transactions {
+		ids = append(ids, id)
+	}
+	d.mu.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Strings(ids)
+
+	var reports []DriveTxnReport
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		rep := d.resumeTxn(ctx, outputDir, id)
+		switch rep.State {
+		case driveTxnCommitted:
+			slog.Info("Drive: completed an interrupted meeting upload", "id", id)
+		case driveTxnRolledBack:
+			slog.Warn("Drive: rolled back a partial meeting upload", "id", id, "trashed", rep.Trashed, "reason", rep.Error)
+		default:
+			slog.Warn("Drive: meeting upload still incomplete; retrying next run", "id", id, "error", rep.Error)
+		}
+		reports = append(reports, rep)
+	}
+	if err := d.saveSyncState(); err != nil {
+		slog.Warn("Failed to save Drive sync state", "error", err)
+	}
+	return reports
+}
+
+// resumeTxn retries one open transaction, rolling it back when it can't
+// complete.
+func (d *DriveUploader) resumeTxn(ctx context.Context, outputDir, id string) DriveTxnReport {
+	d.mu.Lock()
+	tx := *d.state.Transactions[id]
+	d.mu.Unlock()
+
+	var missing []string
+	for _, rel := range tx.Files {
+		if _, err := os.Stat(filepath.Join(outputDir, rel)); err != nil {
+			missing = append(missing, rel)
+		}
+	}
+	switch {
+	case len(missing) > 0:
+		return d.rollbackTxn(ctx, id, fmt.Sprintf("local file missing: %s", missing[0]))
+	case tx.Attempts >= driveTxnMaxAttempts:
+		return d.rollbackTxn(ctx, id, fmt.Sprintf("failed %d times: %s", tx.Attempts, tx.Error))
+	}
+
+	d.beginTxn(id, tx.Route, nil)
+	var err error
+	for _, rel := range tx.Files {
+		local := filepath.Join(outputDir, rel)
+		action, entry := d.shouldUpload(local, rel)
+		if _, err = d.uploadWithHint(ctx, local, rel, filepath.Join(tx.Route, rel), action, entry); err != nil {
+			err = fmt.Errorf("upload %s: %w", rel, err)
+			break
+		}
+		if action == "create" {
+			d.txnCreated(id, rel)
+		}
+	}
+	d.endTxn(id, err)
+	if err != nil {
+		return DriveTxnReport{ID: id, State: driveTxnPending, Error: redactSecrets(err.Error())}
+	}
+	return DriveTxnReport{ID: id, State: driveTxnCommitted}
+}
+
+// rollbackTxn trashes the files a transaction created and forgets them.
+// The transaction stays open if a file can't be trashed.
+func (d *DriveUploader) rollbackTxn(ctx context.Context, id, reason string) DriveTxnReport {
+	d.mu.Lock()
+	created := slices.Clone(d.state.Transactions[id].Created)
+	d.mu.Unlock()
+
+	rep := DriveTxnReport{ID: id, State: driveTxnRolledBack, Error: reason}
+	for _, rel := range created {
+		d.mu.Lock()
+		entry := d.state.Files[rel]
+		d.mu.Unlock()
+		if entry != nil && entry.DriveFileID != "" {
+			if err := d.trashFile(ctx, entry.DriveFileID); err != nil {
+				d.endTxn(id, fmt.Errorf("roll back %s: %w", rel, err))
+				return DriveTxnReport{ID: id, State: driveTxnPending, Trashed: rep.Trashed, Error: redactSecrets(err.Error())}
+			}
+			rep.Trashed++
+		}
+		d.mu.Lock()
+		delete(d.state.Files, rel)
+		if tx := d.state.Transactions[id]; tx != nil {
+			tx.Created = slices.DeleteFunc(tx.Created, func(s string) bool { return s == rel })
+		}
+		d.mu.Unlock()
+	}
+	d.endTxn(id, nil)
+	return rep
+}
+
+// trashFile moves a Drive file to the trash. A file that is already gone
+// counts as trashed.
+func (d *DriveUploader) trashFile(ctx context.Context, fileID string) error {
+	apiURL := fmt.Sprintf("%s/files/%s?fields=id", driveAPIBase, url.PathEscape(fileID))
+	resp, err := d.driveRequest(ctx, "PATCH", apiURL, bytes.NewReader([]byte(`{"trashed":true}`)), "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return &driveAPIError{Code: resp.StatusCode, Body: string(readErrorBody(resp.Body))}
+	}
+	return nil
+}
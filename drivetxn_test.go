@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDriveFiles accepts uploads, except of files named in fail, and
+// records trashed file IDs.
+type fakeDriveFiles struct {
+	fail     map[string]bool
+	uploaded []string
+	trashed  []string
+}
+
+func (f *fakeDriveFiles) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+	switch {
+	case req.Method == "POST" && strings.HasPrefix(req.URL.Path, "/upload/drive/v3/files"):
+		for name := range f.fail {
+			if strings.Contains(string(body), `"name":"`+name+`"`) {
+				rec.WriteHeader(http.StatusForbidden)
+				rec.WriteString(`{"error":"storage quota"}`)
+				return rec.Result(), nil
+			}
+		}
+		id := fmt.Sprintf("file-%d", len(f.uploaded)+1)
+		f.uploaded = append(f.uploaded, id)
+		fmt.Fprintf(rec, `{"id":%q}`, id)
+	case req.Method == "PATCH" && strings.HasPrefix(req.URL.Path, "/drive/v3/files/"):
+		if string(body) != `{"trashed":true}` {
+			rec.WriteHeader(http.StatusBadRequest)
+			break
+		}
+		f.trashed = append(f.trashed, strings.TrimPrefix(req.URL.Path, "/drive/v3/files/"))
+		rec.WriteString(`{}`)
+	default:
+		rec.WriteHeader(http.StatusNotImplemented)
+	}
+	return rec.Result(), nil
+}
+
+// driveTxnFixture writes a meeting's files and returns its export result.
+func driveTxnFixture(t *testing.T, dir, id string) *ExportResult {
+	t.Helper()
+	for _, name := range []string{id + ".json", id + ".transcript.txt", id + ".mp4"} {
+		writeArchiveFile(t, dir, "2025-01-15/"+name, "content of "+name, 0)
+	}
+	return &ExportResult{
+		ID:              id,
+		MetadataPath:    filepath.Join("2025-01-15", id+".json"),
+		TranscriptPaths: map[string]string{"text": filepath.Join("2025-01-15", id+".transcript.txt")},
+		VideoPath:       filepath.Join("2025-01-15", id+".mp4"),
+	}
+}
+
+func TestDriveUploadTransactions(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	f := &fakeDriveFiles{fail: map[string]bool{"m1.mp4": true}}
+	d := &DriveUploader{
+		client:    &http.Client{Transport: f},
+		token:     &oauthToken{AccessToken: "t", Expiry: time.Now().Add(time.Hour)},
+		folderID:  "root",
+		folderMap: map[string]string{"2025-01-15": "day"},
+		state:     &DriveSyncState{Files: map[string]*SyncEntry{}},
+		statePath: filepath.Join(dir, "gdrive-sync.json"),
+	}
+
+	// The video fails after the metadata and transcript went up.
+	r := driveTxnFixture(t, dir, "m1")
+	if _, err := d.UploadExportResult(ctx, dir, r); err == nil {
+		t.Fatal("upload with a failing video succeeded")
+	}
+	tx := d.state.Transactions["m1"]
+	if tx == nil || len(tx.Files) != 3 || len(tx.Created) != 2 || tx.Attempts != 1 || !strings.Contains(tx.Error, "m1.mp4") {
+		t.Fatalf("open transaction = %+v", tx)
+	}
+
+	// The next run finishes it, uploading only the video.
+	delete(f.fail, "m1.mp4")
+	reports := d.ResumeTransactions(ctx, dir)
+	if len(reports) != 1 || reports[0].State != driveTxnCommitted {
+		t.Fatalf("resume = %+v", reports)
+	}
+	if len(f.uploaded) != 3 || len(d.state.Transactions) != 0 {
+		t.Errorf("%d upload(s), transactions %v", len(f.uploaded), d.state.Transactions)
+	}
+	if _, err := os.Stat(d.statePath); err != nil {
+		t.Errorf("sync state not saved: %v", err)
+	}
+
+	// One that keeps failing is rolled back: created files are trashed
+	// and forgotten, so the meeting is uploaded afresh later.
+	f.fail["m2.mp4"] = true
+	r = driveTxnFixture(t, dir, "m2")
+	d.UploadExportResult(ctx, dir, r)
+	for range driveTxnMaxAttempts - 1 {
+		if reports := d.ResumeTransactions(ctx, dir); reports[0].State != driveTxnPending {
+			t.Fatalf("retry = %+v", reports)
+		}
+	}
+	reports = d.ResumeTransactions(ctx, dir)
+	if len(reports) != 1 || reports[0].State != driveTxnRolledBack || reports[0].Trashed != 2 {
+		t.Fatalf("rollback = %+v", reports)
+	}
+	if len(f.trashed) != 2 || d.state.Files[r.MetadataPath] != nil || d.state.Transactions["m2"] != nil {
+		t.Errorf("trashed %v; metadata entry %+v; transactions %v", f.trashed, d.state.Files[r.MetadataPath], d.state.Transactions)
+	}
+	if d.state.Files[filepath.Join("2025-01-15", "m1.json")] == nil {
+		t.Error("rollback touched another meeting's files")
+	}
+
+	// Local files gone: nothing to complete from, so roll back at once.
+	f.fail["m3.mp4"] = true
+	r = driveTxnFixture(t, dir, "m3")
+	d.UploadExportResult(ctx, dir, r)
+	os.Remove(filepath.Join(dir, r.VideoPath))
+	if reports := d.ResumeTransactions(ctx, dir); reports[0].State != driveTxnRolledBack || !strings.Contains(reports[0].Error, "missing") {
+		t.Errorf("missing local file: %+v", reports)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ── Duration Check ──────────────────────────────────────────────────────────
+//
+// A download cut short by a dropped connection or an expiring signed URL
+// still leaves a valid-looking file: the container sniff passes and the
+// size is plausible. After each video download, ffprobe reads the file's
+// real duration and it is compared with the meeting length Grain reports.
+// A difference beyond --duration-tolerance seconds marks the video
+// "duration_mismatch" (video_status in the manifest), counts it in the
+// manifest's duration_mismatch total, and fails --strict. The file is kept;
+// re-download it with --overwrite. Without ffprobe on PATH, or without a
+// known meeting length, nothing is checked.
+
+// videoDurationMismatch is the ExportResult.VideoStatus of a video whose
+// length differs from the meeting's by more than --duration-tolerance.
+const videoDurationMismatch = "duration_mismatch"
+
+// durationFunc returns the duration in seconds of the media file at path.
+type durationFunc func(ctx context.Context, path string) (float64, error)
+
+// ffprobeDuration returns a durationFunc reading the container duration
+// with ffprobe, or nil when ffprobe is not on PATH.
+func ffprobeDuration() durationFunc {
+	if _, err := exec.LookPath("ffprobe"); err != nil {
+		return nil
+	}
+	return func(ctx context.Context, path string) (float64, error) {
+		out, err := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+			"-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", path).Output()
+		if err != nil {
+			return 0, fmt.Errorf("ffprobe %s: %w", path, err)
+		}
+		d, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+		if err != nil || d <= 0 || math.IsInf(d, 0) {
+			return 0, fmt.Errorf("ffprobe %s: no duration in %q", path, strings.TrimSpace(string(out)))
+		}
+		return d, nil
+	}
+}
+
+// checkDuration probes the downloaded video's length into r.VideoDuration
+// and flags it when it disagrees with the meeting's.
+func (e *Exporter) checkDuration(ctx context.Context, id string, meta *Metadata, r *ExportResult) {
+	if e.probe == nil || e.cfg.DurationTolerance <= 0 || r.VideoPath == "" {
+		return
+	}
+	actual, err := e.probe(ctx, e.storage.AbsPath(r.VideoPath))
+	if err != nil {
+		slog.Debug("Duration probe failed", "id", id, "error", err)
+		return
+	}
+	r.VideoDuration = math.Round(actual*10) / 10
+	expected := durationSeconds(meta.DurationSeconds)
+	if expected <= 0 || math.Abs(actual-expected) <= e.cfg.DurationTolerance {
+		return
+	}
+	r.VideoStatus = videoDurationMismatch
+	slog.Warn("Video length differs from the meeting's; the download may be truncated",
+		"id", id, "video_seconds", r.VideoDuration, "meeting_seconds", expected, "path", r.VideoPath)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckDuration(t *testing.T) {
+	dir := t.TempDir()
+	var probed string
+	e := &Exporter{
+		cfg:      &Config{DurationTolerance: 60},
+		storage:  NewLocalStorage(dir),
+		manifest: &ExportManifest{},
+		probe: func(_ context.Context, path string) (float64, error) {
+			probed = path
+			return 1200.04, nil
+		},
+	}
+	for _, tc := range []struct {
+		name     string
+		duration any
+		want     string
+	}{
+		{"truncated", "45:00", videoDurationMismatch},
+		{"within tolerance", 1250.0, ""},
+		{"unknown length", nil, ""},
+	} {
+		r := &ExportResult{VideoPath: "2025-01-15/m1.mp4"}
+		e.checkDuration(context.Background(), "m1", &Metadata{DurationSeconds: tc.duration}, r)
+		if r.VideoStatus != tc.want || r.VideoDuration != 1200 {
+			t.Errorf("%s: status %q, duration %v", tc.name, r.VideoStatus, r.VideoDuration)
+		}
+		e.tally(r)
+	}
+	if probed != filepath.Join(dir, "2025-01-15", "m1.mp4") {
+		t.Errorf("probed %q", probed)
+	}
+	if e.manifest.DurationMismatch != 1 {
+		t.Errorf("manifest counts %d mismatch(es)", e.manifest.DurationMismatch)
+	}
+	e.cfg.Strict = true
+	if err := e.strictErr(); !errors.Is(err, errStrictFailed) {
+		t.Errorf("strict with a mismatch: %v", err)
+	}
+
+	// Unprobeable files and a zero tolerance are left alone.
+	e.probe = func(context.Context, string) (float64, error) { return 0, errors.New("no duration") }
+	r := &ExportResult{VideoPath: "m2.mp4"}
+	e.checkDuration(context.Background(), "m2", &Metadata{DurationSeconds: 3600.0}, r)
+	e.cfg.DurationTolerance = 0
+	e.probe = func(context.Context, string) (float64, error) { return 1, nil }
+	e.checkDuration(context.Background(), "m2", &Metadata{DurationSeconds: 3600.0}, r)
+	if r.VideoStatus != "" || r.VideoDuration != 0 {
+		t.Errorf("unchecked video flagged: %+v", r)
+	}
+}
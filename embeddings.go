@@ -0,0 +1,514 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ── Embeddings ──────────────────────────────────────────────────────────────
+//
+// --embeddings openai|local turns the archive's transcripts into a vector
+// file for RAG tooling. After each run, every transcript is cut into chunks
+// of whole speaker turns (about embeddingChunkChars each), the chunks are
+// embedded in batches, and --embeddings-out is rebuilt: one row per chunk
+// with the vector and the metadata needed to cite it (meeting, date,
+// speakers, and a link to the moment in the recording). .parquet files
+// load into pandas, DuckDB, or LanceDB; .jsonl is one JSON object per line.
+//
+// openai calls the OpenAI embeddings API with OPENAI_API_KEY. local calls
+// any server with the same API, Ollama by default, and costs nothing.
+// Vectors are cached in embeddingsStateFile by model and chunk text, so a
+// chunk is embedded once and only new or changed transcripts cost anything.
+// Before calling OpenAI, the run's cost is estimated from the chunk sizes;
+// past --embeddings-max-cost, the newest meetings are embedded first and
+// the rest wait for later runs.
+
+// Embedding backends accepted by --embeddings.
+const (
+	embeddingsOpenAI = "openai"
+	embeddingsLocal  = "local"
+)
+
+// Backend defaults: API base URL and model.
+const (
+	embeddingsOpenAIURL   = "https://api.openai.com/v1"
+	embeddingsOpenAIModel = "text-embedding-3-small"
+	embeddingsLocalURL    = "http://localhost:11434/v1"
+	embeddingsLocalModel  = "nomic-embed-text"
+)
+
+// embeddingsStateFile caches the vectors computed so far. Hidden, like the
+// other state files.
+const embeddingsStateFile = ".graindl-embeddings.json"
+
+// embeddingChunkChars is the target chunk size, about 500 tokens: small
+// enough to retrieve precisely, large enough to carry an exchange.
+const embeddingChunkChars = 2000
+
+// Batch limits per embeddings request.
+const (
+	embeddingBatchInputs = 64
+	embeddingBatchTokens = 100_000
+)
+
+// embeddingPrices is OpenAI's price per million input tokens, in USD.
+var embeddingPrices = map[string]float64{
+	"text-embedding-3-small": 0.02,
+	"text-embedding-3-large": 0.13,
+	"text-embedding-ada-002": 0.10,
+}
+
+// configureEmbeddings validates the --embeddings flags and fills in the
+// backend defaults.
+func configureEmbeddings(cfg *Config) error {
+	cfg.Embeddings = strings.ToLower(strings.TrimSpace(cfg.Embeddings))
+	switch cfg.Embeddings {
+	case embeddingsOpenAI:
+		cfg.EmbeddingsURL = coalesce(cfg.EmbeddingsURL, embeddingsOpenAIURL)
+		cfg.EmbeddingsModel = coalesce(cfg.EmbeddingsModel, embeddingsOpenAIModel)
+		if cfg.OpenAIKey == "" {
+			return fmt.Errorf("--embeddings openai needs an API key in OPENAI_API_KEY (env or .env)")
+		}
+		if _, ok := embeddingPrices[cfg.EmbeddingsModel]; !ok && cfg.EmbeddingsMaxCost > 0 {
+			return fmt.Errorf("--embeddings-model %s: price unknown, so --embeddings-max-cost can't be enforced; set --embeddings-max-cost 0 to embed without a budget", cfg.EmbeddingsModel)
+		}
+	case embeddingsLocal:
+		cfg.EmbeddingsURL = coalesce(cfg.EmbeddingsURL, embeddingsLocalURL)
+		cfg.EmbeddingsModel = coalesce(cfg.EmbeddingsModel, embeddingsLocalModel)
+	default:
+		return fmt.Errorf("invalid --embeddings %q: must be openai or local", cfg.Embeddings)
+	}
+	if !strings.HasPrefix(cfg.EmbeddingsURL, "https://") && !strings.HasPrefix(cfg.EmbeddingsURL, "http://") {
+		return fmt.Errorf("--embeddings-url must be an http(s) URL")
+	}
+	if cfg.EmbeddingsMaxCost < 0 {
+		return fmt.Errorf("--embeddings-max-cost must be 0 (no limit) or more")
+	}
+	cfg.EmbeddingsOut = coalesce(cfg.EmbeddingsOut, filepath.Join(cfg.OutputDir, "embeddings.parquet"))
+	switch strings.ToLower(filepath.Ext(cfg.EmbeddingsOut)) {
+	case ".parquet", ".jsonl":
+		return nil
+	}
+	return fmt.Errorf("--embeddings-out: %q must end in .parquet or .jsonl", cfg.EmbeddingsOut)
+}
+
+// embeddingChunk is one piece of a transcript and its citation metadata.
+type embeddingChunk struct {
+	ID         string  `json:"chunk_id"` // <meeting id>#<n>
+	MeetingID  string  `json:"meeting_id"`
+	Title      string  `json:"title"`
+	Date       string  `json:"date"`
+	Start      float64 `json:"start_sec"` // -1 when unknown
+	Speakers   string  `json:"speakers"`  // comma-separated, in order of appearance
+	URL        string  `json:"url"`
+	Transcript string  `json:"transcript"` // archive-relative path
+	Text       string  `json:"text"`
+}
+
+// transcriptChunks cuts a meeting's transcript into chunks of whole turns.
+// A turn longer than a chunk is split between words.
+func transcriptChunks(a *ArchiveEntry, text string) []embeddingChunk {
+	turns := parseTranscript(text)
+	if turns == nil {
+		for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+			if p = strings.TrimSpace(p); p != "" {
+				turns = append(turns, transcriptTurn{Start: -1, Lines: []string{p}})
+			}
+		}
+	}
+	m := a.Meta
+	var chunks []embeddingChunk
+	var cur []string
+	var speakers []string
+	start := -1.0
+	size := 0
+	flush := func() {
+		if len(cur) == 0 {
+			return
+		}
+		c := embeddingChunk{
+			ID:         fmt.Sprintf("%s#%d", m.ID, len(chunks)),
+			MeetingID:  m.ID,
+			Title:      coalesce(m.Title, m.ID),
+			Date:       a.Date().Format("2006-01-02"),
+			Start:      start,
+			Speakers:   strings.Join(speakers, ", "),
+			URL:        m.Links.Grain,
+			Transcript: filepath.ToSlash(a.RelBase) + ".transcript.txt",
+			Text:       strings.Join(cur, "\n"),
+		}
+		if start >= 0 {
+			c.URL = coalesce(timestampURL(m.Links.Grain, start), c.URL)
+		}
+		chunks = append(chunks, c)
+		cur, speakers, start, size = nil, nil, -1, 0
+	}
+	for _, t := range turns {
+		line := strings.Join(strings.Fields(strings.Join(t.Lines, " ")), " ")
+		if t.Speaker != "" {
+			line = t.Speaker + ": " + line
+		}
+		for _, piece := range splitWords(line, embeddingChunkChars) {
+			if size > 0 && size+1+len(piece) > embeddingChunkChars {
+				flush()
+			}
+			if len(cur) == 0 {
+				start = t.Start
+			}
+			if t.Speaker != "" && !slices.Contains(speakers, t.Speaker) {
+				speakers = append(speakers, t.Speaker)
+			}
+			cur = append(cur, piece)
+			size += len(piece) + 1
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitWords splits s into pieces of at most max bytes at spaces. A single
+// longer word becomes its own piece.
+func splitWords(s string, max int) []string {
+	var pieces []string
+	for len(s) > max {
+		cut := strings.LastIndexByte(s[:max+1], ' ')
+		if cut <= 0 {
+			if cut = strings.IndexByte(s, ' '); cut < 0 {
+				break
+			}
+		}
+		pieces = append(pieces, s[:cut])
+		s = strings.TrimLeft(s[cut:], " ")
+	}
+	return append(pieces, s)
+}
+
+// estimateTokens approximates a text's token count: about four characters
+// per token for English.
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// Embedder computes embeddings through an OpenAI-compatible API.
+type Embedder struct {
+	client  *http.Client
+	base    string
+	model   string
+	key     string  // sent as a bearer token when set
+	price   float64 // USD per million tokens; 0 for local models
+	maxCost float64 // USD per run; 0 = no limit
+	out     string
+}
+
+// NewEmbedder returns an embedder for the configured backend.
+func NewEmbedder(cfg *Config) *Embedder {
+	em := &Embedder{
+		client: &http.Client{Timeout: 2 * time.Minute},
+		base:   strings.TrimSuffix(cfg.EmbeddingsURL, "/"),
+		model:  cfg.EmbeddingsModel,
+		out:    cfg.EmbeddingsOut,
+	}
+	if cfg.Embeddings == embeddingsOpenAI {
+		em.key = cfg.OpenAIKey
+		em.price = embeddingPrices[cfg.EmbeddingsModel]
+		em.maxCost = cfg.EmbeddingsMaxCost
+	}
+	return em
+}
+
+// cost returns the price of tokens.
+func (em *Embedder) cost(tokens int) float64 {
+	return float64(tokens) / 1e6 * em.price
+}
+
+// Embed returns the vectors for texts, in order, and the tokens billed.
+func (em *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, int, error) {
+	body, err := json.Marshal(map[string]any{"model": em.model, "input": texts})
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, em.base+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if em.key != "" {
+		req.Header.Set("Authorization", "Bearer "+em.key)
+	}
+	resp, err := em.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 256<<20))
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.Unmarshal(data, &e)
+		return nil, 0, fmt.Errorf("embeddings: %s: %s", resp.Status, truncateRunes(coalesce(e.Error.Message, strings.TrimSpace(string(data))), 200))
+	}
+	var out struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, 0, fmt.Errorf("embeddings: decode response: %w", err)
+	}
+	vecs := make([][]float32, len(texts))
+	for _, d := range out.Data {
+		if d.Index >= 0 && d.Index < len(vecs) {
+			vecs[d.Index] = d.Embedding
+		}
+	}
+	for i, v := range vecs {
+		if len(v) == 0 {
+			return nil, 0, fmt.Errorf("embeddings: no vector for input %d", i)
+		}
+	}
+	return vecs, out.Usage.TotalTokens, nil
+}
+
+// EmbeddingsState caches computed vectors.
+type EmbeddingsState struct {
+	Vectors map[string][]byte `json:"vectors"` // embeddingKey → little-endian float32s
+}
+
+// embeddingKey identifies a chunk's vector: the model and the exact text.
+func embeddingKey(model, text string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + text))
+	return hex.EncodeToString(sum[:16])
+}
+
+func loadEmbeddingsState(outputDir string) *EmbeddingsState {
+	st := &EmbeddingsState{}
+	path := filepath.Join(outputDir, embeddingsStateFile)
+	err := readStateFile(path, func(data []byte) error {
+		st = &EmbeddingsState{}
+		return json.Unmarshal(data, st)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Embeddings cache unreadable, re-embedding", "path", path, "error", err)
+	}
+	if st.Vectors == nil {
+		st.Vectors = map[string][]byte{}
+	}
+	return st
+}
+
+func saveEmbeddingsState(outputDir string, st *EmbeddingsState) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	return writeStateFile(filepath.Join(outputDir, embeddingsStateFile), data)
+}
+
+func packVector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(f))
+	}
+	return b
+}
+
+func unpackVector(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
+
+// embeddingsBudget picks the pending chunks this run can afford, newest
+// meetings first (chunks are in archive order, oldest first). It returns
+// their indexes and the estimated tokens of all pending chunks.
+func (em *Embedder) embeddingsBudget(pending []embeddingChunk) (picked []int, tokens int) {
+	for _, c := range pending {
+		tokens += estimateTokens(c.Text)
+	}
+	if em.maxCost == 0 || em.cost(tokens) <= em.maxCost {
+		for i := range pending {
+			picked = append(picked, i)
+		}
+		return picked, tokens
+	}
+	spent := 0
+	for i := len(pending) - 1; i >= 0; i-- {
+		n := estimateTokens(pending[i].Text)
+		if em.cost(spent+n) > em.maxCost {
+			break
+		}
+		spent += n
+		picked = append(picked, i)
+	}
+	slices.Sort(picked)
+	return picked, tokens
+}
+
+// writeEmbeddings embeds new transcript chunks and rebuilds the vector file.
+func (e *Exporter) writeEmbeddings(ctx context.Context) {
+	em := e.embedder
+	if em == nil {
+		return
+	}
+	entries, err := scanArchive(e.cfg.dataDir())
+	if err != nil {
+		slog.Warn("Embeddings skipped", "error", err)
+		return
+	}
+	var chunks []embeddingChunk
+	for _, a := range entries {
+		if text := a.Transcript(e.cfg.dataDir()); strings.TrimSpace(text) != "" {
+			chunks = append(chunks, transcriptChunks(a, text)...)
+		}
+	}
+	st := loadEmbeddingsState(e.cfg.OutputDir)
+
+	var pending []embeddingChunk
+	for _, c := range chunks {
+		if _, ok := st.Vectors[embeddingKey(em.model, c.Text)]; !ok {
+			pending = append(pending, c)
+		}
+	}
+	picked, tokens := em.embeddingsBudget(pending)
+	if len(picked) < len(pending) {
+		slog.Warn(fmt.Sprintf("Embeddings: %d new chunk(s) would cost ~$%.4f, over --embeddings-max-cost $%.2f; embedding the newest %d now, the rest on later runs",
+			len(pending), em.cost(tokens), em.maxCost, len(picked)))
+	} else if len(pending) > 0 && em.price > 0 {
+		slog.Info(fmt.Sprintf("Embeddings: %d new chunk(s), ~%d tokens, ~$%.4f", len(pending), tokens, em.cost(tokens)))
+	}
+
+	billed, embedded := 0, 0
+	for len(picked) > 0 && ctx.Err() == nil {
+		n, est := 0, 0
+		for n < len(picked) && n < embeddingBatchInputs {
+			t := estimateTokens(pending[picked[n]].Text)
+			if n > 0 && est+t > embeddingBatchTokens {
+				break
+			}
+			est += t
+			n++
+		}
+		texts := make([]string, n)
+		for i, idx := range picked[:n] {
+			texts[i] = pending[idx].Text
+		}
+		vecs, used, err := em.Embed(ctx, texts)
+		if err != nil {
+			slog.Warn("Embeddings request failed; the rest are retried on the next run", "error", err)
+			break
+		}
+		for i, v := range vecs {
+			st.Vectors[embeddingKey(em.model, texts[i])] = packVector(v)
+		}
+		billed += used
+		embedded += n
+		picked = picked[n:]
+	}
+
+	// Keep only the vectors of chunks that still exist.
+	var rows []embeddingChunk
+	var vecs [][]float32
+	keep := map[string][]byte{}
+	for _, c := range chunks {
+		k := embeddingKey(em.model, c.Text)
+		if b, ok := st.Vectors[k]; ok {
+			keep[k] = b
+			rows = append(rows, c)
+			vecs = append(vecs, unpackVector(b))
+		}
+	}
+	st.Vectors = keep
+	if err := saveEmbeddingsState(e.cfg.OutputDir, st); err != nil {
+		slog.Warn("Embeddings cache write failed", "error", err)
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	data, err := renderEmbeddings(em.out, em.model, rows, vecs)
+	if err == nil {
+		err = writeFile(em.out, data)
+	}
+	if err != nil {
+		slog.Warn("Embeddings write failed", "path", em.out, "error", err)
+		return
+	}
+	msg := fmt.Sprintf("Embeddings: %d chunk(s) → %s (%d embedded this run", len(rows), em.out, embedded)
+	if em.price > 0 && embedded > 0 {
+		msg += fmt.Sprintf(", %d tokens, ~$%.4f", billed, em.cost(billed))
+	}
+	slog.Info(msg + ")")
+}
+
+// renderEmbeddings encodes the vector file in the format named by path's
+// extension.
+func renderEmbeddings(path, model string, rows []embeddingChunk, vecs [][]float32) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(path), ".jsonl") {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for i, c := range rows {
+			row := struct {
+				embeddingChunk
+				Model     string    `json:"model"`
+				Embedding []float32 `json:"embedding"`
+			}{c, model, vecs[i]}
+			if err := enc.Encode(row); err != nil {
+				return nil, err
+			}
+		}
+		return buf.Bytes(), nil
+	}
+
+	col := func(name string, get func(c embeddingChunk) string) parquetColumn {
+		vals := make([]string, len(rows))
+		for i, c := range rows {
+			vals[i] = get(c)
+		}
+		return parquetColumn{Name: name, Strings: vals}
+	}
+	starts := make([]float64, len(rows))
+	for i, c := range rows {
+		starts[i] = c.Start
+	}
+	return encodeParquet([]parquetColumn{
+		col("chunk_id", func(c embeddingChunk) string { return c.ID }),
+		col("meeting_id", func(c embeddingChunk) string { return c.MeetingID }),
+		col("title", func(c embeddingChunk) string { return c.Title }),
+		col("date", func(c embeddingChunk) string { return c.Date }),
+		{Name: "start_sec", Doubles: starts},
+		col("speakers", func(c embeddingChunk) string { return c.Speakers }),
+		col("url", func(c embeddingChunk) string { return c.URL }),
+		col("transcript", func(c embeddingChunk) string { return c.Transcript }),
+		col("text", func(c embeddingChunk) string { return c.Text }),
+		col("model", func(embeddingChunk) string { return model }),
+		{Name: "embedding", Floats: vecs},
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscriptChunks(t *testing.T) {
+	a := &ArchiveEntry{DateDir: "2025-03-05", RelBase: "2025-03-05/m1", Meta: &Metadata{ID: "m1", Title: "Acme", Links: Links{Grain: "https://grain.com/app/meetings/m1"}}}
+	long := strings.Repeat("word ", embeddingChunkChars/5+50)
+	text := "00:10 Dana: Hello there.\n\n00:20 Sam: Hi Dana.\n\n01:00 Dana: " + long
+	chunks := transcriptChunks(a, text)
+	if len(chunks) != 3 {
+		t.Fatalf("%d chunks, want 3", len(chunks))
+	}
+	c := chunks[0]
+	if c.ID != "m1#0" || c.Start != 10 || c.Speakers != "Dana, Sam" || c.Date != "2025-03-05" || c.Transcript != "2025-03-05/m1.transcript.txt" {
+		t.Errorf("first chunk = %+v", c)
+	}
+	if c.Text != "Dana: Hello there.\nSam: Hi Dana." {
+		t.Errorf("first chunk text = %q", c.Text)
+	}
+	if c.URL != "https://grain.com/app/meetings/m1?t=10" {
+		t.Errorf("url = %q", c.URL)
+	}
+	for _, c := range chunks {
+		if len(c.Text) > embeddingChunkChars {
+			t.Errorf("%s is %d bytes", c.ID, len(c.Text))
+		}
+	}
+	// The long turn doesn't fit beside the others and is split.
+	for _, c := range chunks[1:] {
+		if c.Start != 60 || c.Speakers != "Dana" || !strings.Contains(c.Text, "word word") {
+			t.Errorf("split turn chunk %s: start %v, speakers %q", c.ID, c.Start, c.Speakers)
+		}
+	}
+
+	plain := transcriptChunks(a, "just some words\n\nand more")
+	if len(plain) != 1 || plain[0].Start != -1 || plain[0].URL != "https://grain.com/app/meetings/m1" || plain[0].Text != "just some words\nand more" {
+		t.Errorf("plain transcript chunks = %+v", plain)
+	}
+}
+
+// fakeEmbeddings answers with two-dimensional vectors: the input's length
+// and its position in the request.
+type fakeEmbeddings struct {
+	t      *testing.T
+	inputs [][]string
+}
+
+func (f *fakeEmbeddings) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+		f.t.Errorf("Authorization = %q", got)
+	}
+	var req struct {
+		Model string   `json:"model"`
+		Input []string `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "text-embedding-3-small" {
+		f.t.Fatalf("bad request: %+v, %v", req, err)
+	}
+	f.inputs = append(f.inputs, req.Input)
+	type item struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	}
+	var resp struct {
+		Data  []item         `json:"data"`
+		Usage map[string]int `json:"usage"`
+	}
+	for i, in := range req.Input {
+		resp.Data = append(resp.Data, item{i, []float32{float32(len(in)), float32(i)}})
+	}
+	resp.Usage = map[string]int{"total_tokens": 10 * len(req.Input)}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestWriteEmbeddings(t *testing.T) {
+	f := &fakeEmbeddings{t: t}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "vectors.jsonl")
+	cfg := &Config{OutputDir: dir, Embeddings: "openai", EmbeddingsURL: srv.URL, EmbeddingsOut: out, OpenAIKey: "sk-test", EmbeddingsMaxCost: 1}
+	if err := configureEmbeddings(cfg); err != nil {
+		t.Fatal(err)
+	}
+	writeArchiveMeta(t, dir, "2025-01-10", &Metadata{ID: "old", Title: "Old"})
+	writeArchiveFile(t, dir, "2025-01-10/old.transcript.txt", "00:05 Dana: "+strings.Repeat("a", 1500)+"\n\n00:09 Sam: "+strings.Repeat("b", 1500), 0)
+	writeArchiveMeta(t, dir, "2025-02-10", &Metadata{ID: "new", Title: "New"})
+	writeArchiveFile(t, dir, "2025-02-10/new.transcript.txt", "00:01 Dana: Budget is approved.", 0)
+
+	// A budget that covers only the newest meeting's chunk.
+	e := &Exporter{cfg: cfg, embedder: NewEmbedder(cfg)}
+	e.embedder.price = 1e6 // $1 per token
+	e.embedder.maxCost = 10
+	e.writeEmbeddings(context.Background())
+	if len(f.inputs) != 1 || len(f.inputs[0]) != 1 || f.inputs[0][0] != "Dana: Budget is approved." {
+		t.Fatalf("budgeted run sent %q", f.inputs)
+	}
+	rows := readEmbeddingsJSONL(t, out)
+	if len(rows) != 1 || rows[0]["chunk_id"] != "new#0" || rows[0]["model"] != "text-embedding-3-small" || rows[0]["url"] != "" {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	// Without a limit the older chunks follow; cached ones aren't resent.
+	e.embedder.maxCost = 0
+	e.writeEmbeddings(context.Background())
+	if len(f.inputs) != 2 || len(f.inputs[1]) != 2 {
+		t.Fatalf("second run sent %d request(s): %v", len(f.inputs)-1, f.inputs[1:])
+	}
+	rows = readEmbeddingsJSONL(t, out)
+	if len(rows) != 3 || rows[0]["chunk_id"] != "old#0" || rows[2]["chunk_id"] != "new#0" {
+		t.Fatalf("rows after second run = %d", len(rows))
+	}
+	if v := rows[2]["embedding"].([]any); len(v) != 2 || v[0] != float64(len("Dana: Budget is approved.")) {
+		t.Errorf("embedding = %v", v)
+	}
+	e.writeEmbeddings(context.Background())
+	if len(f.inputs) != 2 {
+		t.Errorf("third run re-embedded cached chunks: %v", f.inputs[2:])
+	}
+
+	// Removed transcripts drop out of the cache and the file.
+	os.Remove(filepath.Join(dir, "2025-01-10", "old.transcript.txt"))
+	e.writeEmbeddings(context.Background())
+	if n := len(loadEmbeddingsState(dir).Vectors); n != 1 {
+		t.Errorf("cache holds %d vector(s), want 1", n)
+	}
+	if rows := readEmbeddingsJSONL(t, out); len(rows) != 1 {
+		t.Errorf("%d row(s) after removal", len(rows))
+	}
+}
+
+func readEmbeddingsJSONL(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	fh, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fh.Close()
+	var rows []map[string]any
+	sc := bufio.NewScanner(fh)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var row map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestConfigureEmbeddings(t *testing.T) {
+	cfg := &Config{OutputDir: "out", Embeddings: "Local"}
+	if err := configureEmbeddings(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.EmbeddingsURL != embeddingsLocalURL || cfg.EmbeddingsModel != embeddingsLocalModel || cfg.EmbeddingsOut != filepath.Join("out", "embeddings.parquet") {
+		t.Errorf("local defaults = %+v", cfg)
+	}
+	if em := NewEmbedder(cfg); em.key != "" || em.price != 0 {
+		t.Errorf("local embedder sends a key or costs money: %+v", em)
+	}
+	for _, bad := range []Config{
+		{Embeddings: "cohere"},
+		{Embeddings: "openai"}, // no API key
+		{Embeddings: "openai", OpenAIKey: "k", EmbeddingsModel: "custom", EmbeddingsMaxCost: 1},
+		{Embeddings: "local", EmbeddingsOut: "vectors.csv"},
+		{Embeddings: "local", EmbeddingsMaxCost: -1},
+		{Embeddings: "local", EmbeddingsURL: "localhost:11434"},
+	} {
+		if err := configureEmbeddings(&bad); err == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+	custom := &Config{Embeddings: "openai", OpenAIKey: "k", EmbeddingsModel: "custom"}
+	if err := configureEmbeddings(custom); err != nil {
+		t.Errorf("unpriced model without a budget: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ── Config Checks ───────────────────────────────────────────────────────────
+//
+// envInt, envFloat, and envBool fall back to their defaults when a value
+// doesn't parse, so a typo like GRAIN_MAX_DELAY=5s would go unnoticed.
+// They record each value they ignore, and envGet records every variable
+// it is asked for. At startup reportEnvProblems warns about the
+// ignored values and about GRAIN_* variables nothing reads (usually
+// typos), then the run goes on. --check-config stops after validation:
+// every problem is reported, nothing is exported, and the exit code says
+// whether the configuration is usable.
+
+// envSubcommandKeys are variables read only by subcommands. The exporter
+// never asks for them, but they are not typos.
+var envSubcommandKeys = []string{
+	"GRAIN_HLS_JOBS",
+	"GRAIN_S3_ACCESS_KEY_ID",
+	"GRAIN_S3_BUCKET",
+	"GRAIN_S3_ENDPOINT",
+	"GRAIN_S3_PREFIX",
+	"GRAIN_S3_REGION",
+	"GRAIN_S3_SECRET_ACCESS_KEY",
+	"GRAIN_S3_SESSION_TOKEN",
+	"GRAIN_SERVE_ADDR",
+	"GRAIN_SERVE_TOKEN",
+}
+
+// envProblem is an environment variable whose value was ignored.
+type envProblem struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+// envLog tracks the variables read and the values ignored.
+var envLog = struct {
+	sync.Mutex
+	read     map[string]bool
+	problems []envProblem
+}{read: map[string]bool{}}
+
+// envRead records that key is a variable graindl uses.
+func envRead(key string) {
+	envLog.Lock()
+	envLog.read[key] = true
+	envLog.Unlock()
+}
+
+// envIgnored records a value that didn't parse and was replaced by a
+// default.
+func envIgnored(key, value, reason string) {
+	envLog.Lock()
+	defer envLog.Unlock()
+	for _, p := range envLog.problems {
+		if p.Key == key && p.Value == value {
+			return
+		}
+	}
+	envLog.problems = append(envLog.problems, envProblem{Key: key, Value: value, Reason: reason})
+}
+
+// envProblems returns the ignored values, then one problem per GRAIN_*
+// variable in the environment or dotenv that nothing read.
+func envProblems(dotenv map[string]string) []envProblem {
+	envLog.Lock()
+	defer envLog.Unlock()
+	problems := append([]envProblem(nil), envLog.problems...)
+
+	set := map[string]string{}
+	for k, v := range dotenv {
+		set[k] = v
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok && v != "" {
+			set[k] = v
+		}
+	}
+	var unknown []string
+	for k := range set {
+		if strings.HasPrefix(k, "GRAIN_") && !envLog.read[k] && !slices.Contains(envSubcommandKeys, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		reason := "unknown variable, ignored"
+		if near := nearestEnvKey(k, envLog.read); near != "" {
+			reason += fmt.Sprintf(" (did you mean %s?)", near)
+		}
+		problems = append(problems, envProblem{Key: k, Value: set[k], Reason: reason})
+	}
+	return problems
+}
+
+// reportEnvProblems logs every envProblem as a warning. Values of
+// credential variables are not logged.
+func reportEnvProblems(dotenv map[string]string) {
+	for _, p := range envProblems(dotenv) {
+		value := p.Value
+		if isSecretLogKey(p.Key) {
+			value = redactedValue
+		}
+		slog.Warn(fmt.Sprintf("%s: %s", p.Key, p.Reason), "value", value)
+	}
+}
+
+// nearestEnvKey returns the known variable within two edits of key, if
+// there is exactly one.
+func nearestEnvKey(key string, known map[string]bool) string {
+	best, bestDist, ties := "", 3, 0
+	for k := range known {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist, ties = k, d, 0
+		} else if d == bestDist {
+			ties++
+		}
+	}
+	if ties > 0 {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// ── --check-config ──────────────────────────────────────────────────────────
+
+// warnCounter counts the warnings logged through it, so --check-config can
+// report flag combinations that were ignored as well as env problems.
+type warnCounter struct {
+	slog.Handler
+	n *atomic.Int64
+}
+
+func newWarnCounter(h slog.Handler) *warnCounter {
+	return &warnCounter{Handler: h, n: new(atomic.Int64)}
+}
+
+func (w *warnCounter) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level == slog.LevelWarn {
+		w.n.Add(1)
+	}
+	return w.Handler.Handle(ctx, r)
+}
+
+func (w *warnCounter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &warnCounter{Handler: w.Handler.WithAttrs(attrs), n: w.n}
+}
+
+func (w *warnCounter) WithGroup(name string) slog.Handler {
+	return &warnCounter{Handler: w.Handler.WithGroup(name), n: w.n}
+}
+
+// Warnings returns the number of warnings logged so far.
+func (w *warnCounter) Warnings() int {
+	return int(w.n.Load())
+}
+
+// checkConfigResult reports the outcome of --check-config and returns the
+// exit code: 0 when the configuration is usable, exitStrictFailed when
+// --strict is set and there were warnings. Invalid settings never get here;
+// main exits 1 on them.
+func checkConfigResult(warnings int, strict bool) int {
+	if warnings == 0 {
+		slog.Info("Configuration OK")
+		return 0
+	}
+	if strict {
+		slog.Error(fmt.Sprintf("Configuration has %d warning(s) (--strict)", warnings))
+		return exitStrictFailed
+	}
+	slog.Warn(fmt.Sprintf("Configuration usable with %d warning(s)", warnings))
+	return 0
+}
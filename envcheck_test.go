@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// resetEnvLog clears what earlier tests recorded.
+func resetEnvLog(t *testing.T) {
+	t.Helper()
+	envLog.Lock()
+	envLog.read, envLog.problems = map[string]bool{}, nil
+	envLog.Unlock()
+}
+
+func TestEnvProblems(t *testing.T) {
+	resetEnvLog(t)
+	dotenv := map[string]string{
+		"GRAIN_MAX_DELAY":  "5s",
+		"GRAIN_PARALLEL":   "two",
+		"GRAIN_HEADLESS":   "ture",
+		"GRAIN_VERBOSE":    "no",
+		"GRAIN_HEADLES":    "true",
+		"GRAIN_HLS_JOBS":   "2",
+		"GRAIN_OUTPUT_DIR": "./out",
+		"OTHER_TOOL":       "x",
+	}
+	envFloat(dotenv, "GRAIN_MAX_DELAY", 6)
+	envInt(dotenv, "GRAIN_PARALLEL", 1)
+	envInt(dotenv, "GRAIN_PARALLEL", 1) // reported once
+	envBool(dotenv, "GRAIN_HEADLESS")
+	envBool(dotenv, "GRAIN_VERBOSE")
+	envGet(dotenv, "GRAIN_OUTPUT_DIR")
+
+	var got []string
+	for _, p := range envProblems(dotenv) {
+		got = append(got, p.Key+"="+p.Value+": "+p.Reason)
+	}
+	want := []string{
+		"GRAIN_MAX_DELAY=5s: not a number, using 6",
+		"GRAIN_PARALLEL=two: not a whole number, using 1",
+		"GRAIN_HEADLESS=ture: not true/false, 1/0, or yes/no; using false",
+		"GRAIN_HEADLES=true: unknown variable, ignored (did you mean GRAIN_HEADLESS?)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNearestEnvKey(t *testing.T) {
+	known := map[string]bool{"GRAIN_MAX_DELAY": true, "GRAIN_MIN_DELAY": true, "GRAIN_OUTPUT_DIR": true}
+	for key, want := range map[string]string{
+		"GRAIN_OUTPUT_DIRR": "GRAIN_OUTPUT_DIR",
+		"GRAIN_MAX_DELYA":   "GRAIN_MAX_DELAY",
+		"GRAIN_M_DELAY":     "", // as close to MIN as to MAX
+		"GRAIN_SOMETHING":   "",
+	} {
+		if got := nearestEnvKey(key, known); got != want {
+			t.Errorf("nearestEnvKey(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCheckConfigResult(t *testing.T) {
+	for _, tc := range []struct {
+		warnings int
+		strict   bool
+		want     int
+	}{
+		{0, true, 0},
+		{2, false, 0},
+		{2, true, exitStrictFailed},
+	} {
+		if got := checkConfigResult(tc.warnings, tc.strict); got != tc.want {
+			t.Errorf("checkConfigResult(%d, %v) = %d, want %d", tc.warnings, tc.strict, got, tc.want)
+		}
+	}
+}
+
+// Variables the exporter never reads at startup must be listed in
+// envSubcommandKeys, or --check-config would call them unknown.
+func TestEnvSubcommandKeysComplete(t *testing.T) {
+	keyRe := regexp.MustCompile(`"(GRAIN_[A-Z0-9_]+)"`)
+	mainSrc, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob("*.go")
+	for _, f := range files {
+		if f == "main.go" || strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		src, _ := os.ReadFile(f)
+		for _, m := range keyRe.FindAllStringSubmatch(string(src), -1) {
+			if !strings.Contains(string(mainSrc), m[0]) && !slices.Contains(envSubcommandKeys, m[1]) {
+				t.Errorf("%s reads %s, which is missing from envSubcommandKeys", f, m[1])
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ── Export Events ───────────────────────────────────────────────────────────
+//
+// --events-sock streams export progress as NDJSON (one JSON object per line)
+// so a GUI or tray companion can show live progress without scraping logs.
+// When the path is an existing named pipe, events are written to it while a
+// reader has it open. Otherwise graindl listens on a unix socket at the path
+// (mode 0600, removed on exit) and sends every event to each connected
+// client. Events never hold up the export: while nobody is reading, or a
+// reader falls behind, they are dropped.
+
+// Event types.
+const (
+	eventMeetingStarted  = "meeting_started"
+	eventArtifactWritten = "artifact_written"
+	eventMeetingDone     = "meeting_done"
+	eventCycleDone       = "cycle_done"
+)
+
+// eventClientBuffer is how many events a slow socket client may lag behind
+// before further events are dropped for it.
+const eventClientBuffer = 256
+
+// Event is one NDJSON line on the events socket.
+type Event struct {
+	Type   string       `json:"type"`
+	Time   string       `json:"time"` // RFC 3339, UTC
+	ID     string       `json:"id,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Index  int          `json:"index,omitempty"` // 1-based position in the run
+	Total  int          `json:"total,omitempty"` // meetings in the run, when known
+	Kind   string       `json:"kind,omitempty"`  // artifact_written: metadata, transcript, video, ...
+	Path   string       `json:"path,omitempty"`  // artifact_written: relative to the output dir
+	Status string       `json:"status,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Counts *EventCounts `json:"counts,omitempty"` // cycle_done
+}
+
+// EventCounts tallies a finished export cycle.
+type EventCounts struct {
+	Total   int `json:"total"`
+	OK      int `json:"ok"`
+	Skipped int `json:"skipped"`
+	Errors  int `json:"errors"`
+}
+
+// EventSink delivers events to a named pipe or to unix socket clients. A
+// nil *EventSink discards everything.
+type EventSink struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	pipe    *os.File // named pipe mode: open while a reader is attached
+	ln      net.Listener
+	clients map[chan []byte]struct{}
+	closed  bool
+}
+
+// openEventSink opens path for events: an existing named pipe is written
+// to, anything else becomes a listening unix socket. A stale socket left
+// by an earlier run is replaced; other existing files are refused.
+func openEventSink(path string) (*EventSink, error) {
+	if path == "" {
+		return nil, nil
+	}
+	s := &EventSink{path: path, now: time.Now}
+	fi, err := os.Lstat(path)
+	switch {
+	case err == nil && fi.Mode()&os.ModeNamedPipe != 0:
+		return s, nil
+	case err == nil && fi.Mode()&os.ModeSocket != 0:
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	case err == nil:
+		return nil, fmt.Errorf("%s exists and is not a socket or named pipe", path)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("chmod: %w", err)
+	}
+	s.ln = ln
+	s.clients = make(map[chan []byte]struct{})
+	go s.accept()
+	return s, nil
+}
+
+// accept serves socket clients until the listener closes.
+func (s *EventSink) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		ch := make(chan []byte, eventClientBuffer)
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.clients[ch] = struct{}{}
+		s.mu.Unlock()
+		slog.Debug("Events client connected")
+		go s.serve(conn, ch)
+	}
+}
+
+// serve writes queued events to one client until it disconnects or the
+// sink closes.
+func (s *EventSink) serve(conn net.Conn, ch chan []byte) {
+	defer conn.Close()
+	for line := range ch {
+		if _, err := conn.Write(line); err != nil {
+			s.mu.Lock()
+			if _, ok := s.clients[ch]; ok {
+				delete(s.clients, ch)
+				close(ch)
+			}
+			s.mu.Unlock()
+			slog.Debug("Events client disconnected", "error", err)
+			for range ch {
+			}
+			return
+		}
+	}
+}
+
+// Emit stamps ev with the current time and sends it.
+func (s *EventSink) Emit(ev Event) {
+	if s == nil {
+		return
+	}
+	ev.Time = s.now().UTC().Format(time.RFC3339)
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.ln != nil {
+		for ch := range s.clients {
+			select {
+			case ch <- line:
+			default: // client is behind; drop rather than block the export
+			}
+		}
+		return
+	}
+	s.writePipe(line)
+}
+
+// writePipe writes line to the named pipe, opening it when a reader has
+// attached. Opening and writing are non-blocking: without a reader, or
+// with a full pipe, the event is dropped. Called with s.mu held.
+func (s *EventSink) writePipe(line []byte) {
+	if s.pipe == nil {
+		f, err := os.OpenFile(s.path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			return // ENXIO: no reader yet
+		}
+		s.pipe = f
+	}
+	if _, err := s.pipe.Write(line); err != nil {
+		if !errors.Is(err, syscall.EAGAIN) {
+			// Reader went away; reopen once another attaches.
+			s.pipe.Close()
+			s.pipe = nil
+		}
+	}
+}
+
+// Close stops the sink, disconnecting clients and removing the socket.
+func (s *EventSink) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.pipe != nil {
+		s.pipe.Close()
+	}
+	if s.ln == nil {
+		return nil
+	}
+	for ch := range s.clients {
+		delete(s.clients, ch)
+		close(ch)
+	}
+	err := s.ln.Close()
+	if rmErr := os.Remove(s.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// meetingStarted reports that the meeting at 0-based index began exporting.
+func (s *EventSink) meetingStarted(index, total int, ref MeetingRef) {
+	s.Emit(Event{Type: eventMeetingStarted, ID: ref.ID, Title: ref.Title, Index: index + 1, Total: total})
+}
+
+// artifactsWritten reports every artifact in r, in a stable order.
+func (s *EventSink) artifactsWritten(r *ExportResult) {
+	if s == nil {
+		return
+	}
+	for _, a := range resultArtifacts(r) {
+		s.Emit(Event{Type: eventArtifactWritten, ID: r.ID, Kind: a[0], Path: a[1]})
+	}
+}
+
+// meetingDone reports a meeting's final status.
+func (s *EventSink) meetingDone(index, total int, r *ExportResult) {
+	s.Emit(Event{Type: eventMeetingDone, ID: r.ID, Title: r.Title, Index: index + 1, Total: total, Status: r.Status, Error: r.ErrorMsg})
+}
+
+// cycleDone reports the end of an export run (one watch cycle).
+func (s *EventSink) cycleDone(m *ExportManifest, err error) {
+	ev := Event{Type: eventCycleDone, Status: "ok", Counts: &EventCounts{Total: m.Total, OK: m.OK, Skipped: m.Skipped, Errors: m.Errors}}
+	if err != nil {
+		ev.Status, ev.Error = "error", err.Error()
+	}
+	s.Emit(ev)
+}
+
+// resultArtifacts lists r's artifacts as kind/path pairs.
+func resultArtifacts(r *ExportResult) [][2]string {
+	var out [][2]string
+	add := func(kind, path string) {
+		if path != "" {
+			out = append(out, [2]string{kind, path})
+		}
+	}
+	add("metadata", r.MetadataPath)
+	formats := make([]string, 0, len(r.TranscriptPaths))
+	for f := range r.TranscriptPaths {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	for _, f := range formats {
+		add("transcript", r.TranscriptPaths[f])
+	}
+	add("highlights", r.HighlightsPath)
+	add("ai_notes", r.AINotesPath)
+	add("markdown", r.MarkdownPath)
+	for _, p := range r.MarkdownParts {
+		add("markdown", p)
+	}
+	add("video", r.VideoPath)
+	add("assets", r.AssetsPath)
+	add("audio", r.AudioPath)
+	add("snapshot", r.SnapshotPath)
+	for _, p := range r.PreviewPaths {
+		add("preview", p)
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// eventsSockPath returns a short socket path; unix socket paths are limited
+// to ~104 bytes, which t.TempDir can exceed.
+func eventsSockPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "gev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "e.sock")
+}
+
+func TestEventSinkSocket(t *testing.T) {
+	path := eventsSockPath(t)
+	s, err := openEventSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2024, 11, 15, 10, 0, 0, 0, time.UTC) }
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Fatalf("socket mode = %v, %v", fi, err)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Wait until the sink has registered the client.
+	for i := 0; ; i++ {
+		s.mu.Lock()
+		n := len(s.clients)
+		s.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("client never registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	r := &ExportResult{ID: "m1", Title: "Standup", Status: "ok", MetadataPath: "2024-11-15/m1.json",
+		TranscriptPaths: map[string]string{"text": "2024-11-15/m1.transcript.txt"}, VideoPath: "2024-11-15/m1.mp4"}
+	s.meetingStarted(0, 2, MeetingRef{ID: "m1", Title: "Standup"})
+	s.artifactsWritten(r)
+	s.meetingDone(0, 2, r)
+	s.cycleDone(&ExportManifest{Total: 2, OK: 1, Errors: 1}, errors.New("search: boom"))
+
+	sc := bufio.NewScanner(conn)
+	var got []Event
+	for len(got) < 6 && sc.Scan() {
+		var ev Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		got = append(got, ev)
+	}
+	want := []string{eventMeetingStarted, eventArtifactWritten, eventArtifactWritten, eventArtifactWritten, eventMeetingDone, eventCycleDone}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events: %+v", len(got), got)
+	}
+	for i, ev := range got {
+		if ev.Type != want[i] || ev.Time != "2024-11-15T10:00:00Z" {
+			t.Errorf("event %d = %+v, want type %s", i, ev, want[i])
+		}
+	}
+	if got[0].Index != 1 || got[0].Total != 2 || got[1].Kind != "metadata" || got[2].Path != "2024-11-15/m1.transcript.txt" || got[3].Kind != "video" {
+		t.Errorf("events = %+v", got[:4])
+	}
+	if c := got[5].Counts; c == nil || c.OK != 1 || c.Errors != 1 || got[5].Status != "error" || got[5].Error != "search: boom" {
+		t.Errorf("cycle_done = %+v", got[5])
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket not removed: %v", err)
+	}
+}
+
+func TestEventSinkPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.fifo")
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Skip("mkfifo:", err)
+	}
+	s, err := openEventSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.Emit(Event{Type: eventCycleDone}) // no reader: dropped, not blocking
+
+	reader, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	s.meetingStarted(0, 1, MeetingRef{ID: "m1"})
+
+	buf := make([]byte, 512)
+	n, err := reader.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ev Event
+	if err := json.Unmarshal(buf[:n], &ev); err != nil || ev.Type != eventMeetingStarted || ev.ID != "m1" {
+		t.Errorf("pipe read %q (%v)", buf[:n], err)
+	}
+}
+
+func TestOpenEventSinkRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openEventSink(path); err == nil {
+		t.Error("regular file accepted")
+	}
+	if s, err := openEventSink(""); s != nil || err != nil {
+		t.Errorf("empty path = %v, %v", s, err)
+	}
+	var nilSink *EventSink
+	nilSink.Emit(Event{Type: eventCycleDone})
+	if err := nilSink.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"text/tabwriter"
 	"time"
 )
@@ -18,31 +22,50 @@ import (
 var validID = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]{0,127}$`)
 
 type Exporter struct {
-	browser      *Browser
-	browserMu    sync.Mutex
-	cfg          *Config
-	throttle     *Throttle
-	manifest     *ExportManifest
-	storage      Storage
-	searchFilter map[string]bool // nil = export all, non-nil = only matched IDs
-	drive        *DriveUploader  // nil when --gdrive is not set
+	browser   *Browser
+	browserMu sync.Mutex
+	cfg       *Config
+	throttle  *Throttle
+	manifest  *ExportManifest
+	storage   Storage
+	drive     *DriveUploader // nil when --gdrive is not set
+	alerter   *Alerter       // nil when --alert-keywords is not set
+	hls       *HLSDownloader // nil when --hls-download is not set
+	remux     remuxFunc      // nil when ffmpeg is not on PATH
+	probe     durationFunc   // nil when ffprobe is not on PATH
+	tagMP4    tagFunc        // nil without ffmpeg or with --no-video-tags
+	poster    posterFunc     // nil without ffmpeg or with --no-video-tags
+	preview   previewFunc    // nil without ffmpeg or --highlight-previews
+	notes     *AppleNotes    // nil when --apple-notes is not set
+	spotlight *Spotlight     // nil when --spotlight is not set
+	notify    notifyFunc     // nil without --notify-desktop or the platform's notifier
+	topics    *topicIndex    // nil when --topics is not set
+	readwise  *Readwise      // nil when --readwise-token is not set
+	embedder  *Embedder      // nil when --embeddings is not set
+
+	extractScript string           // --extract-script source, loaded once
+	claims        *ClaimStore      // nil when --claim-ttl is not set
+	auth          *authGuard       // consecutive auth failures; reset each watch cycle
+	failures      *errorBudget     // --max-errors; nil without a limit, reset each watch cycle
+	progress      *progressTracker // per-run ETA; nil outside Run
+	scrollDepth   scrollDepth      // applied to the browser; deeper during watch catch-up
+	health        healthState      // status for --healthcheck-format json
+	events        *EventSink       // nil when --events-sock is not set
+	pacer         *grainPacer      // delays between Grain-bound meetings; sequential runs only
+	driveQueue    *driveQueue      // Drive uploads held for the batch (see drivebatch.go); nil outside one
+	members       *MemberDirectory // members.json; nil until graindl members writes it
 
 	// TUI callbacks (nil when --tui is not set).
 	tuiSendTotal  func(int)
+	tuiGrowTotal  func(int) // raises the total during a streamed --search
 	tuiSendStart  func(int, string)
 	tuiSendResult func(int, string, string)
 }
 
 func NewExporter(ctx context.Context, cfg *Config) (*Exporter, error) {
-	var storage Storage
-	if cfg.ICloud && cfg.ICloudPath != "" {
-		s, err := NewICloudStorage(cfg.OutputDir, cfg.ICloudPath)
-		if err != nil {
-			return nil, fmt.Errorf("icloud storage: %w", err)
-		}
-		storage = s
-	} else {
-		storage = NewLocalStorage(cfg.OutputDir)
+	storage, err := newStorage(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	exp := &Exporter{
@@ -51,8 +74,62 @@ func NewExporter(ctx context.Context, cfg *Config) (*Exporter, error) {
 			Min: time.Duration(cfg.MinDelaySec * float64(time.Second)),
 			Max: time.Duration(cfg.MaxDelaySec * float64(time.Second)),
 		},
-		manifest: &ExportManifest{ExportedAt: time.Now().UTC().Format(time.RFC3339)},
+		manifest: &ExportManifest{ExportedAt: time.Now().UTC().Format(time.RFC3339), OutputRoots: outputRoots(cfg).manifestRoots()},
 		storage:  storage,
+		alerter:  NewAlerter(cfg),
+		remux:    ffmpegRemuxer(cfg.Verbose),
+		probe:    ffprobeDuration(),
+		auth:     newAuthGuard(authFailureThreshold),
+		failures: newErrorBudget(cfg.MaxErrors),
+	}
+	if !cfg.NoVideoTags {
+		exp.tagMP4, exp.poster = ffmpegTagger(cfg.Verbose)
+	}
+	if cfg.HighlightPreviews != "" {
+		exp.preview = ffmpegPreviewer(cfg.HighlightPreviews, cfg.Verbose)
+	}
+	for _, hd := range append(defaultHostDelays(), cfg.HostDelays...) {
+		exp.throttle.SetHost(hd.Host, hd.Min, hd.Max)
+	}
+	if cfg.HLSDownload {
+		exp.hls = NewHLSDownloader(cfg.HLSConcurrency, cfg.Verbose)
+		exp.hls.pace = exp.throttle
+		exp.hls.client.Transport = withChaos(cfg.Chaos, chaosDownload, true, withAPIIdentity(cfg, exp.hls.client.Transport))
+		exp.hls.inputArgs = ffmpegIdentityArgs(cfg)
+	}
+	if cfg.ClaimTTL > 0 {
+		exp.claims = NewClaimStore(cfg.OutputDir, cfg.ClaimTTL)
+	}
+	if cfg.ExtractScript != "" {
+		src, err := loadExtractScript(cfg.ExtractScript)
+		if err != nil {
+			return nil, err
+		}
+		exp.extractScript = src
+	}
+
+	if cfg.AppleNotes {
+		n, err := NewAppleNotes(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("apple notes: %w", err)
+		}
+		exp.notes = n
+	}
+	if cfg.Spotlight {
+		sp, err := NewSpotlight()
+		if err != nil {
+			return nil, fmt.Errorf("spotlight: %w", err)
+		}
+		exp.spotlight = sp
+	}
+	if cfg.NotifyDesktop {
+		exp.notify = desktopNotifier()
+	}
+	if cfg.Topics > 0 {
+		exp.topics = loadTopicIndex(storage.AbsPath(""))
+	}
+	if exp.members, err = loadMemberDirectory(cfg.OutputDir); err != nil {
+		slog.Warn("Member directory ignored", "error", err)
 	}
 
 	if cfg.GDrive {
@@ -62,14 +139,45 @@ func NewExporter(ctx context.Context, cfg *Config) (*Exporter, error) {
 		}
 		exp.drive = d
 	}
+	if cfg.ReadwiseToken != "" {
+		rw, err := NewReadwise(ctx, cfg.ReadwiseToken)
+		if err != nil {
+			return nil, fmt.Errorf("readwise: %w", err)
+		}
+		exp.readwise = rw
+	}
+	if cfg.Embeddings != "" {
+		exp.embedder = NewEmbedder(cfg)
+	}
+	if exp.events, err = openEventSink(cfg.EventsSock); err != nil {
+		return nil, fmt.Errorf("events socket: %w", err)
+	}
 
 	return exp, nil
 }
 
+// Run exports one cycle and reports it on the events socket and, with
+// --notify-desktop, in a desktop notification.
 func (e *Exporter) Run(ctx context.Context) error {
+	start := time.Now()
+	err := e.run(ctx)
+	e.events.cycleDone(e.manifest, err)
+	e.notifyRun(ctx, err, time.Since(start))
+	return err
+}
+
+func (e *Exporter) run(ctx context.Context) error {
+	// --stdout writes nothing to the archive.
+	if e.cfg.Stdout != "" {
+		return e.runStdout(ctx)
+	}
+
 	if err := e.storage.EnsureDir(""); err != nil {
 		return fmt.Errorf("output dir: %w", err)
 	}
+	if err := outputRoots(e.cfg).writeMarkers(e.cfg.OutputDir); err != nil {
+		slog.Warn("Output root marker write failed", "error", err)
+	}
 
 	// Drive verification before export (optional).
 	if e.drive != nil && e.cfg.GDriveVerify {
@@ -86,16 +194,46 @@ func (e *Exporter) Run(ctx context.Context) error {
 		}
 	}
 
+	// Meeting uploads interrupted in an earlier run are completed or
+	// rolled back before new ones start.
+	if e.drive != nil {
+		e.manifest.DriveTransactions = e.drive.ResumeTransactions(ctx, e.cfg.OutputDir)
+	}
+
+	// graindl download-videos works through the --defer-videos queue.
+	if e.cfg.DownloadVideos {
+		return e.runDeferredVideos(ctx)
+	}
+
+	// graindl plan only discovers and schedules.
+	if e.cfg.Plan {
+		return e.runPlan(ctx)
+	}
+
+	// graindl members only exports the workspace member directory.
+	if e.cfg.Members {
+		return e.runMembers(ctx)
+	}
+
+	// graindl retry --dead-letter exports only the dead-lettered meetings.
+	if e.cfg.Retry {
+		return e.runRetryDeadLetter(ctx)
+	}
+
 	// Single meeting mode: --id skips discovery entirely.
 	if e.cfg.MeetingID != "" {
 		return e.runSingle(ctx)
 	}
 
-	// Search filter: if --search is set, resolve matching IDs before discovery.
+	// Search: with --search, Grain's search page is scrolled on its own tab
+	// while discovery runs, and matches are exported as they are found.
+	var search *searchStream
 	if e.cfg.SearchQuery != "" {
-		if err := e.buildSearchFilter(ctx); err != nil {
+		var err error
+		if search, err = e.startSearch(ctx); err != nil {
 			return fmt.Errorf("search: %w", err)
 		}
+		defer search.stop()
 	}
 
 	meetings, err := e.discover(ctx)
@@ -104,62 +242,123 @@ func (e *Exporter) Run(ctx context.Context) error {
 	}
 	if len(meetings) == 0 {
 		slog.Warn("No meetings found")
+		e.writeDelta()
 		return nil
 	}
+	meetings = e.ignoreMeetings(meetings)
+	meetings = e.skipDeadLettered(meetings)
 
-	// Apply search filter.
-	if e.searchFilter != nil {
-		filtered := meetings[:0]
-		for _, m := range meetings {
-			if e.searchFilter[m.ID] {
-				filtered = append(filtered, m)
-			} else {
-				slog.Debug("Skipping (not in search results)", "id", m.ID)
+	// A saved backfill plan holds back planned meetings whose slot hasn't
+	// come (see plan.go).
+	plan, err := loadBackfillPlan(e.cfg.OutputDir)
+	if err != nil {
+		slog.Warn("Backfill plan ignored", "error", err)
+	}
+	if plan != nil && search == nil {
+		meetings = plan.admit(meetings, e.refExported, time.Now())
+	} else {
+		plan = nil
+	}
+
+	var q *meetingQueue
+	if search != nil {
+		// The total grows as matches stream in.
+		progress, growTotal := newProgressTracker(0, e.cfg.ProgressInterval), e.tuiGrowTotal
+		e.progress = progress
+		q = e.searchQueue(ctx, meetings, search, func(total int) {
+			progress.Grow(1)
+			if growTotal != nil {
+				growTotal(total)
 			}
+		})
+		slog.Info("Exporting search matches as they are found", "query", e.cfg.SearchQuery, "output", absPath(e.cfg.OutputDir))
+	} else {
+		if e.cfg.MaxMeetings > 0 && len(meetings) > e.cfg.MaxMeetings {
+			meetings = meetings[:e.cfg.MaxMeetings]
 		}
-		meetings = filtered
-		if len(meetings) == 0 {
-			slog.Warn("No meetings matched search filter after discovery")
-			return nil
+		if e.cfg.Pick {
+			if meetings, err = e.pickMeetings(ctx, meetings); err != nil {
+				return err
+			}
+		}
+		q = queueMeetings(meetings)
+		e.progress = newProgressTracker(len(meetings), e.cfg.ProgressInterval)
+		if e.tuiSendTotal != nil {
+			e.tuiSendTotal(len(meetings))
+		}
+		if !e.cfg.DryRun {
+			slog.Info("Exporting meetings", "count", len(meetings), "output", absPath(e.cfg.OutputDir))
 		}
-		slog.Info("Search filter applied", "matched", len(meetings))
-	}
-
-	if e.cfg.MaxMeetings > 0 && len(meetings) > e.cfg.MaxMeetings {
-		meetings = meetings[:e.cfg.MaxMeetings]
 	}
+	defer func() { e.progress = nil }()
 
 	// Dry-run: list what would be exported and exit.
 	if e.cfg.DryRun {
-		e.printDryRun(meetings)
-		return nil
+		var list []MeetingRef
+		for m := range q.refs {
+			list = append(list, m)
+		}
+		e.printDryRun(list)
+		return search.Err()
 	}
 
-	slog.Info("Exporting meetings", "count", len(meetings), "output", absPath(e.cfg.OutputDir))
-	e.manifest.Total = len(meetings)
-	if e.tuiSendTotal != nil {
-		e.tuiSendTotal(len(meetings))
+	e.batchDrive(ctx, func() {
+		if e.cfg.Parallel > 1 {
+			e.exportParallelQueue(ctx, q)
+		} else {
+			e.exportSequentialQueue(ctx, q)
+		}
+	})
+	e.manifest.Total = q.Total()
+	if plan != nil {
+		plan.settle(e.manifest.Meetings, time.Now())
+		if err := saveBackfillPlan(e.cfg.OutputDir, plan); err != nil {
+			slog.Warn("Backfill plan save failed", "error", err)
+		}
 	}
 
-	if e.cfg.Parallel > 1 {
-		e.exportParallel(ctx, meetings)
-	} else {
-		e.exportSequential(ctx, meetings)
+	if search != nil {
+		if q.Total() == 0 {
+			if err := search.Err(); err != nil {
+				return fmt.Errorf("search: %w", err)
+			}
+			slog.Warn("No meetings matched search filter after discovery")
+			e.writeDelta()
+			return nil
+		}
+		slog.Info("Search filter applied", "matched", q.Total())
 	}
 
 	e.finalizeManifest(ctx)
 	if e.manifest.HLSPending > 0 {
-		fmt.Println("  Run ./convert_hls.sh to convert HLS streams to MP4")
+		fmt.Println("  Run graindl hls-convert --once to convert HLS streams to MP4")
+	}
+	if e.auth.Tripped() {
+		return errAuthBlocked
+	}
+	if e.failures.Exhausted() {
+		return errTooManyErrors
+	}
+	if err := search.Err(); err != nil {
+		return fmt.Errorf("search: %w", err)
 	}
-	return nil
+	return e.strictErr()
 }
 
-// finalizeManifest writes the export manifest, uploads to Drive if enabled,
-// and logs the summary. Shared by Run and runSingle.
+// finalizeManifest applies --gdrive-keep-videos, writes the export manifest,
+// uploads to Drive if enabled, and logs the summary. Shared by Run and runSingle.
 func (e *Exporter) finalizeManifest(ctx context.Context) {
+	e.applyVideoRetention(ctx)
+	e.manifest.Chaos = e.cfg.Chaos.drain()
 	if err := e.storage.WriteJSON("_export-manifest.json", e.manifest); err != nil {
 		slog.Error("Manifest write failed", "error", err)
 	}
+	e.writeDelta()
+	e.writeAnkiDeck()
+	e.writeTasksRollup()
+	e.writeHighlightPages()
+	e.syncReadwise(ctx)
+	e.writeEmbeddings(ctx)
 
 	if e.drive != nil {
 		manifestPath := filepath.Join(e.cfg.OutputDir, "_export-manifest.json")
@@ -174,41 +373,117 @@ func (e *Exporter) finalizeManifest(ctx context.Context) {
 	slog.Info("Done",
 		"ok", e.manifest.OK,
 		"skipped", e.manifest.Skipped,
+		"ignored", e.manifest.Ignored,
 		"errors", e.manifest.Errors,
 		"hls_pending", e.manifest.HLSPending,
+		"auth_blocked", e.manifest.AuthBlocked,
+		"low_quality", e.manifest.LowQuality,
+		"duration_mismatch", e.manifest.DurationMismatch,
 	)
 }
 
+// tally counts one result into the manifest totals and run progress.
+func (e *Exporter) tally(r *ExportResult) {
+	r.ErrorMsg = redactSecrets(r.ErrorMsg)
+	r.DriveError = redactSecrets(r.DriveError)
+	for name, status := range r.Backends {
+		r.Backends[name] = redactSecrets(status)
+	}
+	e.recordProgress(r)
+	switch r.Status {
+	case "ok":
+		e.manifest.OK++
+	case "skipped":
+		e.manifest.Skipped++
+	case "hls_pending":
+		e.manifest.HLSPending++
+		e.manifest.OK++
+	case statusAuthBlocked:
+		e.manifest.AuthBlocked++
+	default:
+		e.manifest.Errors++
+	}
+	if r.ScrapeQuality != nil && *r.ScrapeQuality < lowQualityThreshold {
+		e.manifest.LowQuality++
+	}
+	if r.VideoStatus == videoDurationMismatch {
+		e.manifest.DurationMismatch++
+	}
+}
+
+// meetingQueue feeds meetings to the export loops. Total counts the meetings
+// queued so far: fixed for a discovered list, growing while a streamed
+// --search is still finding matches.
+type meetingQueue struct {
+	refs  <-chan MeetingRef
+	total atomic.Int64
+}
+
+// queueMeetings returns a closed queue holding meetings.
+func queueMeetings(meetings []MeetingRef) *meetingQueue {
+	ch := make(chan MeetingRef, len(meetings))
+	for _, m := range meetings {
+		ch <- m
+	}
+	close(ch)
+	q := &meetingQueue{refs: ch}
+	q.total.Store(int64(len(meetings)))
+	return q
+}
+
+func (q *meetingQueue) Total() int { return int(q.total.Load()) }
+
 // exportSequential exports meetings one at a time (the default).
 func (e *Exporter) exportSequential(ctx context.Context, meetings []MeetingRef) {
-	for i, m := range meetings {
+	e.exportSequentialQueue(ctx, queueMeetings(meetings))
+}
+
+func (e *Exporter) exportSequentialQueue(ctx context.Context, q *meetingQueue) {
+	e.pacer = &grainPacer{throttle: e.throttle}
+	defer func() { e.pacer = nil }()
+	i := 0
+	for m := range q.refs {
 		if err := ctx.Err(); err != nil {
-			slog.Warn("Cancelled", "completed", i, "total", len(meetings))
+			slog.Warn("Cancelled", "completed", i, "total", q.Total())
+			break
+		}
+		if e.auth.Tripped() {
+			slog.Error("Aborting: authentication failed repeatedly", "remaining", q.Total()-i)
+			block := func(ref MeetingRef) {
+				r := authBlockedResult(ref)
+				e.manifest.Meetings = append(e.manifest.Meetings, r)
+				e.tally(r)
+				if e.tuiSendResult != nil {
+					e.tuiSendResult(i, coalesce(ref.Title, ref.ID), r.Status)
+				}
+				e.events.meetingDone(i, q.Total(), r)
+				i++
+			}
+			block(m)
+			for rest := range q.refs {
+				block(rest)
+			}
 			break
 		}
-		slog.Info(fmt.Sprintf("[%d/%d] %s", i+1, len(meetings), coalesce(m.Title, m.ID)))
+		if e.failures.Exhausted() {
+			slog.Error("Aborting: too many failed meetings", "max_errors", e.cfg.MaxErrors, "remaining", q.Total()-i)
+			break
+		}
+		slog.Info(fmt.Sprintf("[%d/%d] %s", i+1, q.Total(), coalesce(m.Title, m.ID)))
 		if e.tuiSendStart != nil {
 			e.tuiSendStart(i, coalesce(m.Title, m.ID))
 		}
+		e.events.meetingStarted(i, q.Total(), m)
 		r := e.exportOne(ctx, m)
+		e.auth.Record(r)
+		e.failures.Record(r)
 		e.manifest.Meetings = append(e.manifest.Meetings, r)
-		switch r.Status {
-		case "ok":
-			e.manifest.OK++
-		case "skipped":
-			e.manifest.Skipped++
-		case "hls_pending":
-			e.manifest.HLSPending++
-			e.manifest.OK++
-		default:
-			e.manifest.Errors++
-		}
+		e.tally(r)
 		if e.tuiSendResult != nil {
 			e.tuiSendResult(i, coalesce(m.Title, m.ID), r.Status)
 		}
-		if i < len(meetings)-1 {
-			_ = e.throttle.Wait(ctx)
-		}
+		e.events.meetingDone(i, q.Total(), r)
+		i++
 	}
 }
 
@@ -224,38 +499,82 @@ type indexedResult struct {
 // Results are collected via a channel so that manifest updates happen in a
 // single goroutine (no mutex needed).
 func (e *Exporter) exportParallel(ctx context.Context, meetings []MeetingRef) {
-	n := e.cfg.Parallel
-	total := len(meetings)
+	e.exportParallelQueue(ctx, queueMeetings(meetings))
+}
 
-	// Pre-allocate manifest slots so results can be placed by index.
-	e.manifest.Meetings = make([]*ExportResult, total)
+func (e *Exporter) exportParallelQueue(ctx context.Context, q *meetingQueue) {
+	n := e.cfg.Parallel
+	gate := newWorkerGate(n, e.cfg.AutoParallel)
+
+	// Result slots are placed by index; the slice grows as results arrive.
+	e.manifest.Meetings = make([]*ExportResult, 0, q.Total())
+
+	// Isolated workers: one incognito context per worker, so browser work
+	// runs concurrently instead of queuing on the shared page.
+	var pool chan *Browser
+	if e.cfg.IsolateWorkers {
+		var err error
+		if pool, err = e.newWorkerPool(n); err != nil {
+			slog.Warn("Isolated worker contexts unavailable, sharing one page", "error", err)
+		} else {
+			defer closeWorkerPool(pool)
+		}
+	}
 
-	sem := make(chan struct{}, n)
 	results := make(chan indexedResult, n)
 
 	var wg sync.WaitGroup
 
 	// Producer: dispatch meetings to workers, limited by semaphore.
 	go func() {
-		for i, m := range meetings {
-			if err := ctx.Err(); err != nil {
+		i := 0
+		for m := range q.refs {
+			if err := ctx.Err(); err != nil || e.failures.Exhausted() {
 				break
 			}
 
-			sem <- struct{}{} // acquire slot (blocks when N workers are active)
+			gate.acquire() // blocks while the worker limit is reached
 			wg.Add(1)
 
 			go func(idx int, ref MeetingRef) {
 				defer wg.Done()
-				defer func() { <-sem }() // release slot
 
-				slog.Info(fmt.Sprintf("[%d/%d] %s", idx+1, total, coalesce(ref.Title, ref.ID)))
+				// Once auth has failed repeatedly, don't even try the rest.
+				if e.auth.Tripped() {
+					gate.release(nil)
+					results <- indexedResult{index: idx, result: authBlockedResult(ref)}
+					return
+				}
+				// Meetings queued before --max-errors tripped are left
+				// for the next run.
+				if e.failures.Exhausted() {
+					gate.release(nil)
+					return
+				}
+
+				wctx := ctx
+				if pool != nil {
+					wb := <-pool
+					defer func() { pool <- wb }()
+					wctx = context.WithValue(ctx, workerBrowserKey{}, wb)
+				}
+
+				// Tag this meeting's console lines with the worker's
+				// color and its short ID (or hold them, when grouping).
+				defer logMeetings.start(ref.ID)()
+
+				slog.Info(fmt.Sprintf("[%d/%d] %s", idx+1, q.Total(), coalesce(ref.Title, ref.ID)), "id", ref.ID)
 				if e.tuiSendStart != nil {
 					e.tuiSendStart(idx, coalesce(ref.Title, ref.ID))
 				}
-				r := e.exportOne(ctx, ref)
+				e.events.meetingStarted(idx, q.Total(), ref)
+				r := e.exportOne(wctx, ref)
+				e.auth.Record(r)
+				e.failures.Record(r)
+				gate.release(r)
 				results <- indexedResult{index: idx, result: r}
 			}(i, m)
+			i++
 		}
 
 		wg.Wait()
@@ -264,21 +583,15 @@ func (e *Exporter) exportParallel(ctx context.Context, meetings []MeetingRef) {
 
 	// Consumer: collect results in the main goroutine (single-writer).
 	for ir := range results {
-		e.manifest.Meetings[ir.index] = ir.result
-		switch ir.result.Status {
-		case "ok":
-			e.manifest.OK++
-		case "skipped":
-			e.manifest.Skipped++
-		case "hls_pending":
-			e.manifest.HLSPending++
-			e.manifest.OK++
-		default:
-			e.manifest.Errors++
+		for len(e.manifest.Meetings) <= ir.index {
+			e.manifest.Meetings = append(e.manifest.Meetings, nil)
 		}
+		e.manifest.Meetings[ir.index] = ir.result
+		e.tally(ir.result)
 		if e.tuiSendResult != nil {
 			e.tuiSendResult(ir.index, coalesce(ir.result.Title, ir.result.ID), ir.result.Status)
 		}
+		e.events.meetingDone(ir.index, q.Total(), ir.result)
 	}
 
 	// Compact: remove nil slots left by meetings that were never dispatched
@@ -291,6 +604,12 @@ func (e *Exporter) exportParallel(ctx context.Context, meetings []MeetingRef) {
 		}
 	}
 	e.manifest.Meetings = compacted
+
+	if e.auth.Tripped() {
+		slog.Error("Aborted: authentication failed repeatedly", "auth_blocked", e.manifest.AuthBlocked)
+	} else if e.failures.Exhausted() {
+		slog.Error("Aborted: too many failed meetings", "max_errors", e.cfg.MaxErrors, "exported", len(e.manifest.Meetings), "total", q.Total())
+	}
 }
 
 // printDryRun lists the meetings that would be exported without doing it.
@@ -311,6 +630,9 @@ func (e *Exporter) Close() {
 	if e.browser != nil {
 		e.browser.Close()
 	}
+	if err := e.events.Close(); err != nil {
+		slog.Warn("Events socket close failed", "error", err)
+	}
 	if e.storage != nil {
 		if err := e.storage.Close(); err != nil {
 			slog.Error("Storage close failed", "error", err)
@@ -346,52 +668,105 @@ func (e *Exporter) runSingle(ctx context.Context) error {
 	if e.tuiSendStart != nil {
 		e.tuiSendStart(0, coalesce(ref.Title, ref.ID))
 	}
+	e.events.meetingStarted(0, 1, ref)
 	r := e.exportOne(ctx, ref)
 	e.manifest.Meetings = append(e.manifest.Meetings, r)
-
-	switch r.Status {
-	case "ok":
-		e.manifest.OK++
-	case "skipped":
-		e.manifest.Skipped++
-	case "hls_pending":
-		e.manifest.HLSPending++
-		e.manifest.OK++
-	default:
-		e.manifest.Errors++
-	}
+	e.tally(r)
 	if e.tuiSendResult != nil {
 		e.tuiSendResult(0, coalesce(r.Title, r.ID), r.Status)
 	}
+	e.events.meetingDone(0, 1, r)
 
 	e.finalizeManifest(ctx)
-	return nil
+	if r.authFailed {
+		return errAuthBlocked
+	}
+	return e.strictErr()
 }
 
-// buildSearchFilter runs a Grain search and populates the filter map.
-func (e *Exporter) buildSearchFilter(ctx context.Context) error {
+// searchStreamBuffer is how many search matches are held while discovery
+// is still running before the search page pauses scrolling.
+const searchStreamBuffer = 500
+
+// searchStream is a --search running on its own browser page.
+type searchStream struct {
+	matches <-chan SearchResult
+	cancel  context.CancelFunc
+	done    chan struct{} // closed once the search has returned
+	err     error         // valid after done is closed
+}
+
+// startSearch begins streaming --search matches.
+func (e *Exporter) startSearch(ctx context.Context) (*searchStream, error) {
 	b, err := e.lazyBrowser()
 	if err != nil {
-		return fmt.Errorf("browser init for search: %w", err)
+		return nil, fmt.Errorf("browser init for search: %w", err)
 	}
+	sctx, cancel := context.WithCancel(ctx)
+	matches := make(chan SearchResult, searchStreamBuffer)
+	s := &searchStream{matches: matches, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		s.err = b.SearchStream(sctx, e.cfg.SearchQuery, matches)
+		close(s.done)
+	}()
+	return s, nil
+}
 
-	results, err := b.Search(ctx, e.cfg.SearchQuery)
-	if err != nil {
-		return err
+// stop cancels the search and waits for it to return.
+func (s *searchStream) stop() {
+	s.cancel()
+	for range s.matches {
 	}
-	if len(results) == 0 {
-		slog.Info("No meetings matched search query", "query", e.cfg.SearchQuery)
-		e.searchFilter = make(map[string]bool) // empty = export nothing
+	<-s.done
+}
+
+// Err stops the search and reports how it failed, ignoring cancellation
+// (an early stop for --max-meetings or shutdown). A nil stream has no error.
+func (s *searchStream) Err() error {
+	if s == nil {
 		return nil
 	}
-
-	e.searchFilter = make(map[string]bool, len(results))
-	for _, r := range results {
-		e.searchFilter[r.ID] = true
-		slog.Debug("Search match", "id", r.ID, "title", r.Title)
+	s.stop()
+	if errors.Is(s.err, context.Canceled) {
+		return nil
 	}
-	slog.Info("Search filter active", "query", e.cfg.SearchQuery, "matches", len(e.searchFilter))
-	return nil
+	return s.err
+}
+
+// searchQueue queues the discovered meetings that search matches, in match
+// order, as the search finds them. onMatch is called with the new total
+// before each meeting is queued. Matches outside the discovered list (such
+// as shared meetings without --include-shared) are skipped, and the search
+// is stopped once --max-meetings are queued.
+func (e *Exporter) searchQueue(ctx context.Context, meetings []MeetingRef, search *searchStream, onMatch func(total int)) *meetingQueue {
+	byID := make(map[string]MeetingRef, len(meetings))
+	for _, m := range meetings {
+		byID[m.ID] = m
+	}
+	refs := make(chan MeetingRef)
+	q := &meetingQueue{refs: refs}
+	go func() {
+		defer close(refs)
+		for r := range search.matches {
+			m, ok := byID[r.ID]
+			if !ok {
+				slog.Debug("Skipping (not in discovered meetings)", "id", r.ID)
+				continue
+			}
+			slog.Debug("Search match", "id", r.ID, "title", r.Title)
+			onMatch(int(q.total.Add(1)))
+			select {
+			case refs <- m:
+			case <-ctx.Done():
+				return
+			}
+			if e.cfg.MaxMeetings > 0 && q.Total() >= e.cfg.MaxMeetings {
+				search.cancel()
+				return
+			}
+		}
+	}()
+	return q
 }
 
 // ── Discovery ───────────────────────────────────────────────────────────────
@@ -400,6 +775,21 @@ func (e *Exporter) discover(ctx context.Context) ([]MeetingRef, error) {
 	return e.discoverViaBrowser(ctx)
 }
 
+// discoverLimit is how many meetings discovery needs to load: --max, since
+// only the first --max discovered meetings are exported. It is 0 (load the
+// full list) without --max, with --search, whose matches are looked up
+// among all discovered meetings, and with title ignore rules, a backfill
+// plan, or dead-lettered meetings, which may drop some of the first --max.
+func (e *Exporter) discoverLimit() int {
+	if e.cfg.MaxMeetings <= 0 || e.cfg.SearchQuery != "" || len(e.cfg.IgnoreTitles) > 0 || e.hasDeadLettered() {
+		return 0
+	}
+	if plan, _ := loadBackfillPlan(e.cfg.OutputDir); plan != nil {
+		return 0
+	}
+	return e.cfg.MaxMeetings
+}
+
 func (e *Exporter) discoverViaBrowser(ctx context.Context) ([]MeetingRef, error) {
 	slog.Info("Launching browser")
 	b, err := e.lazyBrowser()
@@ -409,10 +799,21 @@ func (e *Exporter) discoverViaBrowser(ctx context.Context) ([]MeetingRef, error)
 	if _, err := b.Login(ctx); err != nil {
 		return nil, fmt.Errorf("login: %w", err)
 	}
-	meetings, err := b.DiscoverMeetings(ctx)
+	limit := e.discoverLimit()
+	meetings, err := b.DiscoverMeetings(ctx, limit)
 	if err != nil {
 		return nil, fmt.Errorf("discover: %w", err)
 	}
+	// Shared meetings are merged after the account's own, so they cannot
+	// make the --max cut once the own list fills it.
+	if e.cfg.IncludeShared && (limit == 0 || len(meetings) < limit) {
+		shared, err := b.DiscoverSharedMeetings(ctx, limit)
+		if err != nil {
+			return nil, fmt.Errorf("discover shared: %w", err)
+		}
+		slog.Info("Shared meetings found", "count", len(shared))
+		meetings = mergeSharedMeetings(meetings, shared)
+	}
 	slog.Info("Browser discovery complete", "count", len(meetings))
 	return meetings, nil
 }
@@ -421,22 +822,61 @@ func (e *Exporter) discoverViaBrowser(ctx context.Context) ([]MeetingRef, error)
 
 func (e *Exporter) exportOne(ctx context.Context, ref MeetingRef) *ExportResult {
 	r := &ExportResult{ID: ref.ID, Title: ref.Title, TranscriptPaths: make(map[string]string)}
-	dateStr := dateFromISO(coalesce(ref.Date, time.Now().Format("2006-01-02")))
-	r.DateDir = dateStr
+	defer e.recordFailure(ctx, ref, r)
+	dateDir := e.meetingDir(ref, dateFromISO(coalesce(ref.Date, time.Now().Format("2006-01-02"))))
+	r.DateDir = dateDir
 
-	if err := e.storage.EnsureDir(dateStr); err != nil {
+	if err := e.storage.EnsureDir(dateDir); err != nil {
 		r.Status = "error"
 		r.ErrorMsg = err.Error()
 		slog.Error("Dir creation failed", "error", err)
 		return r
 	}
 
-	relBase := filepath.Join(dateStr, sanitize(ref.ID))
+	relBase := filepath.Join(dateDir, sanitize(ref.ID))
 	metaRelPath := relBase + ".json"
+	r.existed = artifactExists(e.storage, metaRelPath)
+
+	// --min-quality re-exports meetings whose earlier scrape fell short.
+	// Nothing is re-exported under --immutable.
+	// graindl retry re-exports the dead-lettered meetings it was given.
+	overwrite := !e.cfg.Immutable && (e.cfg.Overwrite || e.cfg.Retry || r.existed && e.belowMinQuality(ref.ID, metaRelPath))
 
-	if !e.cfg.Overwrite && e.storage.FileExists(metaRelPath) {
+	if !overwrite && r.existed {
 		slog.Debug("Already exported, skipping", "id", ref.ID)
 		r.Status = "skipped"
+		if e.cfg.RefreshAnalytics && e.paceGrain(ctx, r) {
+			e.refreshAnalytics(ctx, ref, metaRelPath, r)
+		}
+		e.redeliver(ctx, relBase, r)
+		return r
+	}
+
+	// Shared archives: claim the meeting so other instances skip it.
+	if e.claims != nil {
+		claim, err := e.claims.Acquire(ref.ID)
+		if errors.Is(err, errClaimHeld) {
+			slog.Info("Claimed by another instance, skipping", "id", ref.ID)
+			r.Status = "skipped"
+			return r
+		}
+		if err != nil {
+			r.Status = "error"
+			r.ErrorMsg = err.Error()
+			slog.Error("Claim failed", "id", ref.ID, "error", err)
+			return r
+		}
+		defer claim.Release()
+
+		// Another instance may have finished between our check and claim.
+		if !overwrite && artifactExists(e.storage, metaRelPath) {
+			slog.Debug("Exported by another instance, skipping", "id", ref.ID)
+			r.Status = "skipped"
+			return r
+		}
+	}
+
+	if !e.paceGrain(ctx, r) {
 		return r
 	}
 
@@ -445,59 +885,195 @@ func (e *Exporter) exportOne(ctx context.Context, ref MeetingRef) *ExportResult
 	// concurrent page navigations when --parallel > 1.
 	pageURL := coalesce(ref.URL, meetingURL(ref.ID))
 	var scraped *MeetingPageData
-	_ = e.withBrowser(func(b *Browser) error {
-		data, err := b.ScrapeMeetingPage(ctx, pageURL)
+	var snapshot []byte
+	var authErr error
+	_ = e.withBrowser(ctx, func(b *Browser) error {
+		var data *MeetingPageData
+		err := e.cfg.Chaos.fail(chaosScrape, ref.ID)
+		if err == nil {
+			data, err = b.ScrapeMeetingPage(ctx, pageURL)
+		}
+		if errors.Is(err, errAuthRequired) {
+			authErr = err
+			return nil
+		}
 		if err != nil {
 			slog.Warn("Meeting page scrape failed, continuing with minimal data", "id", ref.ID, "error", err)
+			r.browserTimeout = isTimeout(err)
+			r.grainErr = "scrape: " + err.Error()
 			return nil // non-fatal
 		}
 		scraped = data
+		if e.extractScript != "" {
+			if scraped.Extra, err = b.RunExtractScript(e.extractScript); err != nil {
+				slog.Warn("Extract script failed", "id", ref.ID, "error", err)
+			}
+		}
+		if e.cfg.SnapshotHTML {
+			if snapshot, err = b.CaptureSnapshot(); err != nil {
+				slog.Warn("HTML snapshot failed", "id", ref.ID, "error", err)
+			}
+		}
 		return nil
 	})
 
+	// A login redirect means the session is gone: writing minimal metadata
+	// now would make later runs skip this meeting, so fail it instead.
+	if authErr != nil {
+		r.Status = "error"
+		r.ErrorMsg = authErr.Error()
+		r.authFailed = true
+		slog.Error("Authentication required", "id", ref.ID, "error", authErr)
+		return r
+	}
+
+	transcriptText := ""
+	if scraped != nil {
+		transcriptText = scraped.Transcript
+	}
+
 	meta := e.buildScrapedMetadata(ref, pageURL, scraped)
+	meta.Ownership = e.ownership(ref)
+	meta.Classification = classifyMeeting(e.cfg.ClassifyRules, meta)
+	r.Classification = meta.Classification
+	e.members.apply(meta)
+	if r.ExternalParticipants = len(meta.ExternalParticipants); r.ExternalParticipants > 0 {
+		slog.Info("External attendees on the call", "id", ref.ID, "external", strings.Join(meta.ExternalParticipants, ", "))
+	}
+	meta.Retention = e.retention(time.Now())
+	meta.assess(sourceScrape, transcriptText)
+	r.ScrapeQuality, r.ScrapeFallbacks = meta.ScrapeQuality, meta.fallbackFields()
+	if *meta.ScrapeQuality < lowQualityThreshold {
+		slog.Warn("Low scrape quality; page selectors may need updating", "id", ref.ID, "quality", *meta.ScrapeQuality, "missing", strings.Join(r.ScrapeFallbacks, ","))
+	}
+	e.extractTopics(meta, scraped, relBase)
 
 	e.writeMetadata(meta, metaRelPath, r)
 	e.writeTranscript(scraped, ref.ID, relBase, r)
 	e.writeHighlights(scraped, ref.ID, relBase, r)
+	e.writeAINotesRaw(scraped, ref.ID, relBase, r)
+	e.writeSnapshot(snapshot, ref.ID, relBase, r)
 
-	transcriptText := ""
-	if scraped != nil {
-		transcriptText = scraped.Transcript
-	}
 	if e.cfg.OutputFormat != "" {
 		e.writeFormattedMarkdown(meta, transcriptText, relBase, r)
 	}
 	if !e.cfg.SkipVideo {
-		if e.cfg.AudioOnly {
+		if e.cfg.DeferVideos {
+			e.deferVideo(ref, relBase, r)
+		} else if e.cfg.AudioOnly {
 			e.writeAudio(ctx, ref, relBase+".m4a", r)
 		} else {
-			e.writeVideo(ctx, ref, relBase+".mp4", r)
+			e.writeVideo(ctx, ref, meta, relBase+".mp4", r)
 		}
 	}
+	previews := e.writeHighlightPreviews(ctx, meta, relBase, r)
+	if r.Media != nil || previews {
+		meta.Media = r.Media
+		e.writeMetadata(meta, metaRelPath, r)
+	}
+	if previews && e.cfg.OutputFormat != "" {
+		e.writeFormattedMarkdown(meta, transcriptText, relBase, r)
+	}
 	if r.Status == "" {
 		r.Status = "ok"
 	}
 
-	// Upload to Google Drive (if enabled).
+	rc := e.finishResult(ctx, meta, r)
+	if e.alerter != nil && scraped != nil {
+		e.alertMeeting(ctx, meta, r, rc, transcriptText, normalizeHighlights(scraped.Highlights))
+	}
+	return r
+}
+
+// finishResult runs what follows a meeting's downloads: compression notes,
+// events, checksums and the receipt, Spotlight attributes, backend status,
+// sealing, the Apple Notes push, and the Drive upload. Shared by exportOne
+// and download-videos. Returns the meeting's receipt, already saved.
+func (e *Exporter) finishResult(ctx context.Context, meta *Metadata, r *ExportResult) *ExportReceipt {
+	noteCompressed(e.storage, r)
+	e.events.artifactsWritten(r)
+	recordChecksums(e.cfg.OutputDir, r)
+	rc := e.openReceipt(r)
+	defer e.saveReceipt(rc)
+	e.tagSpotlight(ctx, meta, r)
+	if br, ok := e.storage.(backendReporter); ok {
+		r.Backends = br.BackendStatus(collectResultPaths(r))
+	}
+	if e.cfg.Immutable {
+		seal(e.storage, collectResultPaths(r))
+	}
+	e.pushAppleNote(ctx, meta, r, rc)
+
+	// Upload to Google Drive (if enabled) once the batch is exported (see
+	// drivebatch.go); the receipt skips artifacts Drive already has.
 	if e.drive != nil {
-		stats, err := e.drive.UploadExportResult(ctx, e.cfg.OutputDir, r)
-		if err != nil {
-			slog.Warn("Drive upload failed", "id", ref.ID, "error", err)
-			r.DriveError = err.Error()
-		} else {
-			r.DriveUploaded = true
-			r.DriveSkipped = stats.Skipped
-			r.DriveUpdated = stats.Updated
-			slog.Info("Synced to Google Drive", "id", ref.ID,
-				"created", stats.Created, "updated", stats.Updated, "skipped", stats.Skipped)
-			if e.cfg.GDriveCleanLocal {
-				e.cleanLocalFiles(r)
-			}
-		}
+		e.queueDrive(ctx, &driveJob{meta: meta, r: r, rc: rc, paths: collectResultPaths(r), done: func(stats *UploadStats, err error) {
+			e.recordDrive(r, stats, err)
+		}})
 	}
+	return rc
+}
 
-	return r
+// recordDrive notes a meeting's Drive upload outcome on r. It can run after
+// tally, so the error is redacted here too.
+func (e *Exporter) recordDrive(r *ExportResult, stats *UploadStats, err error) {
+	if err != nil {
+		slog.Warn("Drive upload failed", "id", r.ID, "error", err)
+		r.DriveError = redactSecrets(err.Error())
+		r.DriveTxn = driveTxnPending
+		r.setBackend("gdrive", redactSecrets(backendFailed+": "+err.Error()))
+		return
+	}
+	if stats.Created+stats.Updated > 0 {
+		r.DriveTxn = driveTxnCommitted
+	}
+	r.setBackend("gdrive", backendOK)
+	r.DriveUploaded = true
+	r.DriveSkipped = stats.Skipped
+	r.DriveUpdated = stats.Updated
+	slog.Info("Synced to Google Drive", "id", r.ID,
+		"created", stats.Created, "updated", stats.Updated, "skipped", stats.Skipped)
+	if e.cfg.GDriveCleanLocal {
+		e.cleanLocalFiles(r)
+	}
+}
+
+// belowMinQuality reports whether an exported meeting's scrape quality is
+// under --min-quality. Unreadable metadata is left alone.
+func (e *Exporter) belowMinQuality(id, metaRelPath string) bool {
+	if e.cfg.MinQuality <= 0 {
+		return false
+	}
+	q, err := storedQuality(e.cfg.dataDir(), metaRelPath)
+	if err != nil {
+		slog.Debug("Cannot read scrape quality", "id", id, "error", err)
+		return false
+	}
+	if q >= e.cfg.MinQuality {
+		return false
+	}
+	slog.Info("Re-exporting low-quality scrape", "id", id, "quality", q, "min", e.cfg.MinQuality)
+	return true
+}
+
+// loadExtractScript reads an --extract-script file, rejecting oversized or
+// empty scripts up front rather than failing on every meeting.
+func loadExtractScript(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("extract script: %w", err)
+	}
+	if info.Size() > maxExtractScriptBytes {
+		return "", fmt.Errorf("extract script %s is too large (%d bytes, max %d)", path, info.Size(), maxExtractScriptBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("extract script: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("extract script %s is empty", path)
+	}
+	return string(data), nil
 }
 
 func (e *Exporter) writeMetadata(meta *Metadata, relPath string, r *ExportResult) {
@@ -538,9 +1114,25 @@ func (e *Exporter) buildScrapedMetadata(ref MeetingRef, pageURL string, scraped
 	if len(scraped.Participants) > 0 {
 		meta.Participants = scraped.Participants
 	}
+	if len(scraped.Tags) > 0 {
+		meta.Tags = scraped.Tags
+	}
+	if len(scraped.Extra) > 0 {
+		meta.Extra = scraped.Extra
+	}
 	if len(scraped.Highlights) > 0 {
 		meta.Highlights = scraped.Highlights
 	}
+	scraped.Analytics.applyTo(meta)
+	if scraped.Sharing != nil {
+		meta.Sharing = scraped.Sharing
+	}
+	if scraped.Platform != nil {
+		meta.Platform = scraped.Platform
+	}
+	if scraped.AINotes != nil {
+		scraped.AINotes.applyTo(meta, e.cfg.NotesFormat)
+	}
 
 	return meta
 }
@@ -550,8 +1142,14 @@ func (e *Exporter) writeTranscript(scraped *MeetingPageData, id, relBase string,
 		return
 	}
 
+	// Streamed rather than copied into one []byte: a multi-hour transcript
+	// can run to hundreds of megabytes.
 	relPath := relBase + ".transcript.txt"
-	if err := e.storage.WriteFile(relPath, []byte(scraped.Transcript)); err != nil {
+	err := writeStreamed(e.storage, relPath, func(w io.Writer) error {
+		_, err := io.WriteString(w, scraped.Transcript)
+		return err
+	})
+	if err != nil {
 		slog.Error("Transcript write failed", "error", err, "id", id)
 		return
 	}
@@ -564,10 +1162,7 @@ func (e *Exporter) writeHighlights(scraped *MeetingPageData, id, relBase string,
 		return
 	}
 
-	clips := make([]HighlightClip, len(scraped.Highlights))
-	for i, h := range scraped.Highlights {
-		clips[i] = normalizeHighlight(h, i)
-	}
+	clips := normalizeHighlights(scraped.Highlights)
 
 	relPath := relBase + ".highlights.json"
 	if err := e.storage.WriteJSON(relPath, clips); err != nil {
@@ -578,25 +1173,89 @@ func (e *Exporter) writeHighlights(scraped *MeetingPageData, id, relBase string,
 	slog.Info("Highlights exported", "id", id, "count", len(clips))
 }
 
+func (e *Exporter) writeSnapshot(snapshot []byte, id, relBase string, r *ExportResult) {
+	if len(snapshot) == 0 {
+		return
+	}
+
+	relPath := relBase + ".mhtml"
+	if err := e.storage.WriteFile(relPath, snapshot); err != nil {
+		slog.Error("Snapshot write failed", "error", err, "id", id)
+		return
+	}
+	r.SnapshotPath = relPath
+	slog.Info("HTML snapshot exported", "id", id)
+}
+
 func (e *Exporter) writeFormattedMarkdown(meta *Metadata, transcriptText, relBase string, r *ExportResult) {
-	md := renderFormattedMarkdown(e.cfg.OutputFormat, meta, transcriptText)
-	if md == "" {
+	custom := e.noteCustomFields(relBase, meta.ID)
+	relPath := e.noteRelPath(meta, relBase)
+
+	// A Notion note that may need splitting is rendered whole: every part
+	// links to the part count. Other notes are streamed to disk as they are
+	// rendered, so a long transcript isn't held twice over in memory.
+	if e.cfg.OutputFormat == "notion" && e.cfg.NotionMaxSize > 0 {
+		md := mergeCustomFields(renderFormattedMarkdown(e.cfg.OutputFormat, meta, transcriptText), custom)
+		parts := []string{md}
+		if split := splitNotionMarkdown(md, e.cfg.NotionMaxSize, meta, filepath.Base(strings.TrimSuffix(relPath, ".md"))); split != nil {
+			parts = split
+			slog.Info("Notion note split", "id", meta.ID, "parts", len(parts))
+		}
+		if err := e.storage.WriteFile(relPath, []byte(parts[0])); err != nil {
+			slog.Error("Markdown write failed", "error", err, "id", meta.ID)
+			return
+		}
+		r.MarkdownPath = relPath
+		e.writeNotionParts(relPath, parts, r)
+		slog.Debug("Formatted markdown written", "format", e.cfg.OutputFormat, "id", meta.ID)
 		return
 	}
 
-	relPath := relBase + ".md"
-	if err := e.storage.WriteFile(relPath, []byte(md)); err != nil {
+	err := writeStreamed(e.storage, relPath, func(w io.Writer) error {
+		cw := &customFieldsWriter{w: w, custom: custom, done: len(custom) == 0}
+		bw := bufio.NewWriterSize(cw, streamBufferSize)
+		writeFormattedNote(bw, e.cfg.OutputFormat, meta, transcriptText)
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		return cw.Close()
+	})
+	if err != nil {
 		slog.Error("Markdown write failed", "error", err, "id", meta.ID)
 		return
 	}
 	r.MarkdownPath = relPath
+	if e.cfg.OutputFormat == "notion" {
+		e.writeNotionParts(relPath, make([]string, 1), r) // unsplit: drop an earlier split's parts
+	}
 	slog.Debug("Formatted markdown written", "format", e.cfg.OutputFormat, "id", meta.ID)
 }
 
-func (e *Exporter) writeVideo(ctx context.Context, ref MeetingRef, relPath string, r *ExportResult) {
+// noteRelPath returns where the formatted markdown note goes: <relBase>.md,
+// or <date>/<title-slug>.md with --slug-style. A slug already taken by a
+// different meeting's note gets the meeting ID appended.
+func (e *Exporter) noteRelPath(meta *Metadata, relBase string) string {
+	if e.cfg.SlugStyle == "" {
+		return relBase + ".md"
+	}
+	slug := slugify(meta.Title, e.cfg.SlugStyle)
+	if slug == "" {
+		return relBase + ".md"
+	}
+	relPath := filepath.Join(filepath.Dir(relBase), slug+".md")
+	if existing, err := os.ReadFile(e.storage.AbsPath(relPath)); err == nil && !strings.Contains(string(existing), meta.ID) {
+		relPath = filepath.Join(filepath.Dir(relBase), slug+"-"+sanitize(meta.ID)+".md")
+	}
+	return relPath
+}
+
+func (e *Exporter) writeVideo(ctx context.Context, ref MeetingRef, meta *Metadata, relPath string, r *ExportResult) {
+	if e.skipUnavailableVideo(ref.ID, r) {
+		return
+	}
 	absVideoPath := e.storage.AbsPath(relPath)
 	slog.Debug("Downloading video", "id", ref.ID)
-	_ = e.withBrowser(func(b *Browser) error {
+	_ = e.withBrowser(ctx, func(b *Browser) error {
 		method, path := b.DownloadVideo(ctx, coalesce(ref.URL, meetingURL(ref.ID)), absVideoPath)
 		r.VideoMethod = method
 		resultRelPath := e.relPath(path)
@@ -604,12 +1263,13 @@ func (e *Exporter) writeVideo(ctx context.Context, ref MeetingRef, relPath strin
 		case "button", "direct":
 			r.VideoPath = resultRelPath
 			slog.Info("Video downloaded", "method", method, "id", ref.ID)
-			e.storage.SyncExternalFile(resultRelPath)
 		case "hls":
 			r.VideoPath = resultRelPath
 			r.Status = "hls_pending"
-			slog.Warn("HLS stream — run convert_hls.sh", "id", ref.ID)
-			e.storage.SyncExternalFile(resultRelPath)
+			if e.hls == nil {
+				slog.Warn("HLS stream — run graindl hls-convert", "id", ref.ID)
+				e.storage.SyncExternalFile(resultRelPath)
+			}
 		case "url-saved":
 			r.VideoPath = resultRelPath
 			slog.Warn("URL saved (manual download needed)", "id", ref.ID)
@@ -619,19 +1279,99 @@ func (e *Exporter) writeVideo(ctx context.Context, ref MeetingRef, relPath strin
 		}
 		return nil
 	})
+
+	// Sniffing, remuxing, and native HLS downloads run outside the browser
+	// lock: they only need the file or playlist URL and can take minutes
+	// for long meetings.
+	switch {
+	case r.VideoMethod == "button" || r.VideoMethod == "direct":
+		e.checkVideo(ctx, ref.ID, meta, r)
+	case r.VideoMethod == "hls" && e.hls != nil:
+		e.downloadHLS(ctx, ref.ID, meta, relPath, r)
+	}
+	switch r.VideoMethod {
+	case "button", "direct", "hls-native":
+		e.checkDuration(ctx, ref.ID, meta, r)
+	}
+	e.recordVideoOutcome(ctx, ref.ID, r.VideoPath != "", r)
+	if r.VideoPath == "" && r.grainErr == "" {
+		r.grainErr = "video download failed"
+	}
+}
+
+// checkVideo fixes the container of a downloaded video (see mediasniff.go),
+// records its media info, tags it (see mp4tags.go), and syncs it. A
+// download that is not a video is dropped.
+func (e *Exporter) checkVideo(ctx context.Context, id string, meta *Metadata, r *ExportResult) {
+	abs := e.storage.AbsPath(r.VideoPath)
+	final, assets, media, err := e.normalizeVideo(ctx, abs)
+	if assets != "" {
+		r.AssetsPath = e.relPath(assets)
+		e.storage.SyncExternalFile(r.AssetsPath)
+	}
+	if err != nil {
+		if _, statErr := os.Stat(abs); statErr == nil {
+			slog.Warn("Video check failed, keeping download as is", "id", id, "error", err)
+			e.storage.SyncExternalFile(r.VideoPath)
+			return
+		}
+		slog.Warn("Video download unusable", "id", id, "error", err)
+		r.VideoPath, r.VideoMethod = "", "failed"
+		return
+	}
+	r.VideoPath, r.Media = e.relPath(final), media
+	if media.Container != "mp4" || media.ConvertedFrom != "" {
+		slog.Info("Video container", "id", id, "container", media.Container, "from", media.ConvertedFrom, "path", r.VideoPath)
+	}
+	e.tagVideo(ctx, id, meta, r.VideoPath)
+	e.storage.SyncExternalFile(r.VideoPath)
+}
+
+// downloadHLS replaces a saved .m3u8.url with a downloaded MP4. On failure
+// the URL file is left in place and the meeting stays hls_pending so
+// graindl hls-convert can still pick it up.
+func (e *Exporter) downloadHLS(ctx context.Context, id string, meta *Metadata, relPath string, r *ExportResult) {
+	urlPath := e.storage.AbsPath(r.VideoPath)
+	data, err := os.ReadFile(urlPath)
+	if err != nil {
+		slog.Warn("HLS URL file unreadable", "id", id, "error", err)
+		return
+	}
+
+	slog.Info("Downloading HLS stream", "id", id)
+	out, err := e.hls.Download(ctx, strings.TrimSpace(string(data)), e.storage.AbsPath(relPath))
+	if err != nil {
+		slog.Warn("HLS download failed, leaving for hls-convert", "id", id, "error", err)
+		e.storage.SyncExternalFile(r.VideoPath)
+		return
+	}
+
+	_ = os.Remove(urlPath)
+	r.VideoPath = e.relPath(out)
+	r.VideoMethod = "hls-native"
+	r.Status = ""
+	slog.Info("Video downloaded", "method", r.VideoMethod, "id", id)
+	e.tagVideo(ctx, id, meta, r.VideoPath)
+	e.storage.SyncExternalFile(r.VideoPath)
 }
 
 func (e *Exporter) writeAudio(ctx context.Context, ref MeetingRef, relPath string, r *ExportResult) {
+	if e.skipUnavailableVideo(ref.ID, r) {
+		return
+	}
 	absAudioPath := e.storage.AbsPath(relPath)
 	pageURL := coalesce(ref.URL, meetingURL(ref.ID))
 	slog.Debug("Finding video source for audio extraction", "id", ref.ID)
 
 	// Find video URL under browser lock, then release for ffmpeg work.
-	var videoURL string
-	_ = e.withBrowser(func(b *Browser) error {
+	var videoURL, btnPath string
+	_ = e.withBrowser(ctx, func(b *Browser) error {
 		videoURL = b.FindVideoSource(ctx, pageURL)
 		return nil
 	})
+	// Only a meeting with no source at all counts as unavailable; ffmpeg
+	// failures are retried on the next export.
+	defer func() { e.recordVideoOutcome(ctx, ref.ID, videoURL != "" || btnPath != "", r) }()
 
 	verbose := e.cfg.Verbose
 	if videoURL != "" {
@@ -668,8 +1408,7 @@ func (e *Exporter) writeAudio(ctx context.Context, ref MeetingRef, relPath strin
 
 	// Fallback: download the full video via button (under browser lock), extract audio, then delete.
 	tmpVideo := absAudioPath + ".tmp.mp4"
-	var btnPath string
-	_ = e.withBrowser(func(b *Browser) error {
+	_ = e.withBrowser(ctx, func(b *Browser) error {
 		btnPath = b.tryDownloadBtn(ctx, tmpVideo)
 		return nil
 	})
@@ -686,6 +1425,9 @@ func (e *Exporter) writeAudio(ctx context.Context, ref MeetingRef, relPath strin
 	}
 
 	slog.Warn("Audio extraction failed", "id", ref.ID)
+	if r.grainErr == "" {
+		r.grainErr = "audio extraction failed"
+	}
 }
 
 // cleanLocalFiles removes local files after successful Drive upload.
@@ -702,6 +1444,7 @@ func (e *Exporter) cleanLocalFiles(r *ExportResult) {
 			slog.Debug("Removed local file", "path", relPath)
 		}
 	}
+	forgetChecksums(e.cfg.OutputDir, paths)
 	// Try to remove empty date directory.
 	if r.DateDir != "" {
 		dir := filepath.Join(e.cfg.OutputDir, r.DateDir)
@@ -709,12 +1452,19 @@ func (e *Exporter) cleanLocalFiles(r *ExportResult) {
 	}
 }
 
-func (e *Exporter) relPath(abs string) string {
-	rel, err := filepath.Rel(e.cfg.OutputDir, abs)
-	if err != nil {
-		return abs
+// paceGrain waits out the delay before a meeting's first Grain access. It
+// reports false, with r failed, when ctx is cancelled meanwhile.
+func (e *Exporter) paceGrain(ctx context.Context, r *ExportResult) bool {
+	if err := e.pacer.wait(ctx); err != nil {
+		r.Status = "error"
+		r.ErrorMsg = err.Error()
+		return false
 	}
-	return rel
+	return true
+}
+
+func (e *Exporter) relPath(abs string) string {
+	return outputRoots(e.cfg).rel(e.cfg.OutputDir, abs)
 }
 
 func (e *Exporter) lazyBrowser() (*Browser, error) {
@@ -733,14 +1483,32 @@ func (e *Exporter) getBrowserLocked() (*Browser, error) {
 	if err != nil {
 		return nil, err
 	}
+	if e.scrollDepth != (scrollDepth{}) {
+		b.depth = e.scrollDepth
+	}
 	e.browser = b
 	return b, nil
 }
 
+// setScrollDepth changes how far discovery and search scroll, for the
+// current browser and any launched later. Call it only between runs.
+func (e *Exporter) setScrollDepth(d scrollDepth) {
+	e.browserMu.Lock()
+	defer e.browserMu.Unlock()
+	e.scrollDepth = d
+	if e.browser != nil {
+		e.browser.depth = d
+	}
+}
+
 // withBrowser serializes all browser operations via browserMu.
 // This prevents concurrent page navigations when --parallel > 1,
-// since Browser holds a single shared *rod.Page.
-func (e *Exporter) withBrowser(fn func(b *Browser) error) error {
+// since Browser holds a single shared *rod.Page. When ctx carries an
+// isolated worker browser (--isolate-workers), fn runs on it directly.
+func (e *Exporter) withBrowser(ctx context.Context, fn func(b *Browser) error) error {
+	if wb, ok := ctx.Value(workerBrowserKey{}).(*Browser); ok {
+		return fn(wb)
+	}
 	e.browserMu.Lock()
 	defer e.browserMu.Unlock()
 	b, err := e.getBrowserLocked()
@@ -749,3 +1517,35 @@ func (e *Exporter) withBrowser(fn func(b *Browser) error) error {
 	}
 	return fn(b)
 }
+
+// workerBrowserKey is the context key for a per-worker isolated Browser.
+type workerBrowserKey struct{}
+
+// newWorkerPool creates n isolated worker browsers sharing the main
+// browser's Chromium process and login cookies.
+func (e *Exporter) newWorkerPool(n int) (chan *Browser, error) {
+	b, err := e.lazyBrowser()
+	if err != nil {
+		return nil, err
+	}
+	pool := make(chan *Browser, n)
+	for range n {
+		wb, err := b.NewWorker()
+		if err != nil {
+			closeWorkerPool(pool)
+			return nil, err
+		}
+		pool <- wb
+	}
+	slog.Debug("Isolated worker contexts ready", "workers", n)
+	return pool, nil
+}
+
+// closeWorkerPool disposes every worker context in pool. Callers must
+// ensure all workers have been returned.
+func closeWorkerPool(pool chan *Browser) {
+	close(pool)
+	for wb := range pool {
+		wb.Close()
+	}
+}
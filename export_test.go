@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -154,6 +156,69 @@ func TestBuildScrapedMetadataPreservesRefTitle(t *testing.T) {
 
 // ── writeTranscript ─────────────────────────────────────────────────────────
 
+func TestBuildScrapedMetadataExtra(t *testing.T) {
+	e := &Exporter{}
+	ref := MeetingRef{ID: "m1", Title: "Call"}
+	scraped := &MeetingPageData{Extra: map[string]any{"crm_id": "ACME-42"}}
+
+	meta := e.buildScrapedMetadata(ref, "https://grain.com/app/meetings/m1", scraped)
+	if meta.Extra["crm_id"] != "ACME-42" {
+		t.Errorf("Extra = %v, want crm_id=ACME-42", meta.Extra)
+	}
+
+	data, _ := json.Marshal(meta)
+	if !strings.Contains(string(data), `"extra":{"crm_id":"ACME-42"}`) {
+		t.Errorf("metadata JSON missing extra: %s", data)
+	}
+}
+
+// ── loadExtractScript ───────────────────────────────────────────────────────
+
+func TestLoadExtractScript(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "extract.js")
+	src := "exports.crm_id = () => document.body.dataset.crm;\n"
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadExtractScript(path)
+	if err != nil {
+		t.Fatalf("loadExtractScript: %v", err)
+	}
+	if got != src {
+		t.Errorf("script = %q, want %q", got, src)
+	}
+	if wrapped := fmt.Sprintf(extractScriptWrapper, got); !strings.Contains(wrapped, src) {
+		t.Error("wrapper does not embed the script source")
+	}
+}
+
+func TestLoadExtractScriptErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadExtractScript(filepath.Join(dir, "missing.js")); err == nil {
+		t.Error("expected error for missing script")
+	}
+
+	empty := filepath.Join(dir, "empty.js")
+	_ = os.WriteFile(empty, []byte("  \n"), 0o600)
+	if _, err := loadExtractScript(empty); err == nil {
+		t.Error("expected error for empty script")
+	}
+
+	big := filepath.Join(dir, "big.js")
+	_ = os.WriteFile(big, make([]byte, maxExtractScriptBytes+1), 0o600)
+	if _, err := loadExtractScript(big); err == nil {
+		t.Error("expected error for oversized script")
+	}
+}
+
+func TestNewExporterBadExtractScript(t *testing.T) {
+	cfg := &Config{OutputDir: t.TempDir(), ExtractScript: filepath.Join(t.TempDir(), "nope.js")}
+	if _, err := NewExporter(context.Background(), cfg); err == nil {
+		t.Error("NewExporter should fail when --extract-script is unreadable")
+	}
+}
+
 func TestWriteTranscript(t *testing.T) {
 	dir := t.TempDir()
 	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
@@ -289,6 +354,43 @@ func TestWriteHighlightsEmpty(t *testing.T) {
 	}
 }
 
+func TestWriteSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
+	r := &ExportResult{TranscriptPaths: make(map[string]string)}
+
+	mhtml := []byte("From: <Saved by Blink>\r\nContent-Type: multipart/related;\r\n\r\n<html></html>")
+	e.writeSnapshot(mhtml, "test-id", "test-id", r)
+
+	if r.SnapshotPath != "test-id.mhtml" {
+		t.Fatalf("SnapshotPath = %q, want test-id.mhtml", r.SnapshotPath)
+	}
+	path := filepath.Join(dir, "test-id.mhtml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if string(data) != string(mhtml) {
+		t.Errorf("snapshot content mismatch")
+	}
+	info, _ := os.Stat(path)
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("snapshot perms = %o, want 600", perm)
+	}
+}
+
+func TestWriteSnapshotEmpty(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
+	r := &ExportResult{TranscriptPaths: make(map[string]string)}
+
+	e.writeSnapshot(nil, "test-id", "test-id", r)
+
+	if r.SnapshotPath != "" {
+		t.Errorf("SnapshotPath should be empty without a snapshot, got %q", r.SnapshotPath)
+	}
+}
+
 func TestExportOneSkipExisting(t *testing.T) {
 	dir := t.TempDir()
 	cfg := &Config{
@@ -962,3 +1064,167 @@ func TestValidIDAcceptsValid(t *testing.T) {
 		}
 	}
 }
+
+// ── withBrowser ─────────────────────────────────────────────────────────────
+
+func TestWithBrowserUsesWorkerFromContext(t *testing.T) {
+	e := &Exporter{cfg: &Config{}}
+	wb := &Browser{}
+
+	// Hold the shared-browser lock: a worker-scoped call must not need it.
+	e.browserMu.Lock()
+	defer e.browserMu.Unlock()
+
+	ctx := context.WithValue(context.Background(), workerBrowserKey{}, wb)
+	var got *Browser
+	done := make(chan error, 1)
+	go func() {
+		done <- e.withBrowser(ctx, func(b *Browser) error {
+			got = b
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("withBrowser: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("withBrowser blocked on browserMu despite worker browser in context")
+	}
+	if got != wb {
+		t.Error("withBrowser did not pass the worker browser to fn")
+	}
+}
+
+// ── Streamed search ─────────────────────────────────────────────────────────
+
+// fakeSearch returns a searchStream that has already found results.
+func fakeSearch(results ...SearchResult) (*searchStream, *bool) {
+	ch := make(chan SearchResult, len(results))
+	for _, r := range results {
+		ch <- r
+	}
+	close(ch)
+	cancelled := false
+	s := &searchStream{matches: ch, done: make(chan struct{}), cancel: func() { cancelled = true }}
+	close(s.done)
+	return s, &cancelled
+}
+
+func TestSearchQueueFiltersAndOrders(t *testing.T) {
+	e := &Exporter{cfg: &Config{}}
+	discovered := []MeetingRef{{ID: "a", Date: "2025-01-01"}, {ID: "b", Date: "2025-01-02"}, {ID: "c"}}
+	search, _ := fakeSearch(SearchResult{ID: "b"}, SearchResult{ID: "zzz"}, SearchResult{ID: "a"})
+
+	var totals []int
+	q := e.searchQueue(context.Background(), discovered, search, func(n int) { totals = append(totals, n) })
+	var got []string
+	for m := range q.refs {
+		got = append(got, m.ID+"@"+m.Date)
+	}
+
+	if strings.Join(got, ",") != "b@2025-01-02,a@2025-01-01" {
+		t.Errorf("queued %v, want b then a with discovered refs", got)
+	}
+	if q.Total() != 2 || len(totals) != 2 || totals[1] != 2 {
+		t.Errorf("total = %d, onMatch totals = %v", q.Total(), totals)
+	}
+}
+
+func TestSearchQueueMaxMeetingsStopsSearch(t *testing.T) {
+	e := &Exporter{cfg: &Config{MaxMeetings: 1}}
+	search, cancelled := fakeSearch(SearchResult{ID: "a"}, SearchResult{ID: "b"})
+
+	q := e.searchQueue(context.Background(), []MeetingRef{{ID: "a"}, {ID: "b"}}, search, func(int) {})
+	n := 0
+	for range q.refs {
+		n++
+	}
+	if n != 1 || !*cancelled {
+		t.Errorf("queued %d (cancelled=%v), want 1 and the search stopped", n, *cancelled)
+	}
+}
+
+func TestSearchStreamErrIgnoresCancel(t *testing.T) {
+	s, _ := fakeSearch()
+	s.err = context.Canceled
+	if err := s.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil for cancellation", err)
+	}
+	s.err = errors.New("navigating to search: timeout")
+	if err := s.Err(); err == nil {
+		t.Error("Err() should report real failures")
+	}
+	if err := (*searchStream)(nil).Err(); err != nil {
+		t.Errorf("nil stream Err() = %v", err)
+	}
+}
+
+func TestExportSequentialQueueStreamsMatches(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, SkipVideo: true, MaxDelaySec: 0.01})
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer e.Close()
+
+	// Matches arrive one at a time; the first export starts before the
+	// second match exists.
+	refs := make(chan MeetingRef)
+	q := &meetingQueue{refs: refs}
+	exported := make(chan struct{})
+	go func() {
+		defer close(refs)
+		q.total.Add(1)
+		refs <- MeetingRef{ID: "first", Date: "2025-08-01"}
+		for !fileExists(filepath.Join(dir, "2025-08-01", "first.json")) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		close(exported)
+		q.total.Add(1)
+		refs <- MeetingRef{ID: "second", Date: "2025-08-02"}
+	}()
+
+	e.exportSequentialQueue(context.Background(), q)
+	select {
+	case <-exported:
+	default:
+		t.Fatal("first match was not exported before the second arrived")
+	}
+	if e.manifest.OK != 2 || len(e.manifest.Meetings) != 2 {
+		t.Errorf("ok = %d, meetings = %d, want 2/2", e.manifest.OK, len(e.manifest.Meetings))
+	}
+}
+
+func TestDiscoverLimit(t *testing.T) {
+	for _, tc := range []struct {
+		max    int
+		search string
+		want   int
+	}{
+		{0, "", 0},
+		{5, "", 5},
+		{5, "standup", 0}, // search matches need the full list
+	} {
+		e := &Exporter{cfg: &Config{MaxMeetings: tc.max, SearchQuery: tc.search}}
+		if got := e.discoverLimit(); got != tc.want {
+			t.Errorf("max=%d search=%q: discoverLimit = %d, want %d", tc.max, tc.search, got, tc.want)
+		}
+	}
+	// Title rules may drop some of the first --max.
+	rules, _ := parseTitleRules([]string{"standup"}, "")
+	if got := (&Exporter{cfg: &Config{MaxMeetings: 5, IgnoreTitles: rules}}).discoverLimit(); got != 0 {
+		t.Errorf("with ignore rules: discoverLimit = %d, want 0", got)
+	}
+	// So may a backfill plan; --max applies after it.
+	dir := t.TempDir()
+	plan := newBackfillPlan([]MeetingRef{{ID: "m1"}}, 1, time.Hour, "1/hour", time.Now())
+	if err := saveBackfillPlan(dir, plan); err != nil {
+		t.Fatal(err)
+	}
+	if got := (&Exporter{cfg: &Config{MaxMeetings: 5, OutputDir: dir}}).discoverLimit(); got != 0 {
+		t.Errorf("with a backfill plan: discoverLimit = %d, want 0", got)
+	}
+}
@@ -2,61 +2,86 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 )
 
+// noteWriter is what the markdown renderers write to: a strings.Builder
+// for a note held in memory, or a bufio.Writer streaming it to disk.
+type noteWriter interface {
+	io.Writer
+	io.StringWriter
+}
+
 // renderFormattedMarkdown produces a markdown document with YAML frontmatter
-// tailored to the given output format ("obsidian" or "notion").
+// tailored to the given output format ("obsidian", "notion", or "minutes").
 // It combines metadata, transcripts, and notes into a single .md file
 // ready for import into the target knowledge management tool.
 func renderFormattedMarkdown(format string, meta *Metadata, transcriptText string) string {
+	var b strings.Builder
+	writeFormattedNote(&b, format, meta, transcriptText)
+	return b.String()
+}
+
+// writeFormattedNote renders the note for format to b. Nothing is written
+// for an unknown format.
+func writeFormattedNote(b noteWriter, format string, meta *Metadata, transcriptText string) {
 	switch format {
 	case "obsidian":
-		return renderObsidian(meta, transcriptText)
+		writeObsidian(b, meta, transcriptText)
 	case "notion":
-		return renderNotion(meta, transcriptText)
-	default:
-		return ""
+		writeNotion(b, meta, transcriptText)
+	case "minutes":
+		writeMinutes(b, meta, transcriptText)
 	}
 }
 
 // ── Obsidian ─────────────────────────────────────────────────────────────────
 
-func renderObsidian(meta *Metadata, transcriptText string) string {
-	var b strings.Builder
-
+func writeObsidian(b noteWriter, meta *Metadata, transcriptText string) {
 	b.WriteString("---\n")
-	writeYAMLField(&b, "title", meta.Title)
+	writeYAMLField(b, "title", meta.Title)
 	if meta.Date != "" {
-		writeYAMLField(&b, "date", dateFromISO(meta.Date))
+		writeYAMLField(b, "date", dateFromISO(meta.Date))
+	}
+	writeYAMLField(b, "grain_id", meta.ID)
+	if meta.Ownership != "" {
+		writeYAMLField(b, "ownership", meta.Ownership)
 	}
-	writeYAMLField(&b, "grain_id", meta.ID)
+	if meta.Classification != "" {
+		writeYAMLField(b, "classification", meta.Classification)
+	}
+	if meta.Sharing != nil {
+		writeYAMLField(b, "sharing", meta.Sharing.Visibility)
+	}
+	writePlatformFields(b, meta)
 
 	tags := flattenStringSlice(meta.Tags)
 	tags = append([]string{"grain", "meeting"}, tags...)
-	writeYAMLList(&b, "tags", tags)
+	tags = topicTags(tags, meta.Topics)
+	writeYAMLList(b, "tags", tags)
 
 	if participants := flattenStringSlice(meta.Participants); len(participants) > 0 {
-		writeYAMLList(&b, "participants", participants)
+		writeYAMLList(b, "participants", participants)
 	}
 
 	if dur := formatDuration(meta.DurationSeconds); dur != "" {
-		writeYAMLField(&b, "duration", dur)
+		writeYAMLField(b, "duration", dur)
 	}
 
 	if meta.Title != "" {
-		writeYAMLList(&b, "aliases", []string{meta.Title})
+		writeYAMLList(b, "aliases", []string{meta.Title})
 	}
 
 	if meta.Links.Grain != "" {
-		writeYAMLField(&b, "grain_url", meta.Links.Grain)
+		writeYAMLField(b, "grain_url", meta.Links.Grain)
 	}
 	if meta.Links.Share != "" {
-		writeYAMLField(&b, "share_url", meta.Links.Share)
+		writeYAMLField(b, "share_url", meta.Links.Share)
 	}
 	if meta.Links.Video != "" {
-		writeYAMLField(&b, "video_url", meta.Links.Video)
+		writeYAMLField(b, "video_url", meta.Links.Video)
 	}
 
 	b.WriteString("---\n\n")
@@ -72,55 +97,61 @@ func renderObsidian(meta *Metadata, transcriptText string) string {
 		b.WriteString("\n")
 	}
 
+	writeTaskSection(b, meta)
+
 	if highlights := formatAny(meta.Highlights); highlights != "" {
 		b.WriteString("\n## Highlights\n\n")
 		b.WriteString(highlights)
 		b.WriteString("\n")
+		writePreviewEmbeds(b, meta)
 	}
 
-	if transcriptText != "" {
-		b.WriteString("\n## Transcript\n\n")
-		b.WriteString(transcriptText)
-		b.WriteString("\n")
-	}
-
-	return b.String()
+	writeTranscriptSection(b, "obsidian", meta, transcriptText)
 }
 
 // ── Notion ───────────────────────────────────────────────────────────────────
 
-func renderNotion(meta *Metadata, transcriptText string) string {
-	var b strings.Builder
-
+func writeNotion(b noteWriter, meta *Metadata, transcriptText string) {
 	b.WriteString("---\n")
-	writeYAMLField(&b, "title", meta.Title)
-	writeYAMLField(&b, "type", "Meeting")
-	writeYAMLField(&b, "status", "Exported")
+	writeYAMLField(b, "title", meta.Title)
+	writeYAMLField(b, "type", "Meeting")
+	writeYAMLField(b, "status", "Exported")
 	if meta.Date != "" {
-		writeYAMLField(&b, "date", dateFromISO(meta.Date))
+		writeYAMLField(b, "date", dateFromISO(meta.Date))
+	}
+	writeYAMLField(b, "grain_id", meta.ID)
+	if meta.Ownership != "" {
+		writeYAMLField(b, "ownership", meta.Ownership)
 	}
-	writeYAMLField(&b, "grain_id", meta.ID)
+	if meta.Classification != "" {
+		writeYAMLField(b, "classification", meta.Classification)
+	}
+	if meta.Sharing != nil {
+		writeYAMLField(b, "sharing", meta.Sharing.Visibility)
+	}
+	writePlatformFields(b, meta)
 
 	tags := flattenStringSlice(meta.Tags)
 	tags = append([]string{"grain", "meeting"}, tags...)
-	writeYAMLList(&b, "tags", tags)
+	tags = topicTags(tags, meta.Topics)
+	writeYAMLList(b, "tags", tags)
 
 	if participants := flattenStringSlice(meta.Participants); len(participants) > 0 {
-		writeYAMLList(&b, "participants", participants)
+		writeYAMLList(b, "participants", participants)
 	}
 
 	if dur := formatDuration(meta.DurationSeconds); dur != "" {
-		writeYAMLField(&b, "duration", dur)
+		writeYAMLField(b, "duration", dur)
 	}
 
 	if meta.Links.Grain != "" {
-		writeYAMLField(&b, "grain_url", meta.Links.Grain)
+		writeYAMLField(b, "grain_url", meta.Links.Grain)
 	}
 	if meta.Links.Share != "" {
-		writeYAMLField(&b, "share_url", meta.Links.Share)
+		writeYAMLField(b, "share_url", meta.Links.Share)
 	}
 	if meta.Links.Video != "" {
-		writeYAMLField(&b, "video_url", meta.Links.Video)
+		writeYAMLField(b, "video_url", meta.Links.Video)
 	}
 
 	b.WriteString("---\n\n")
@@ -170,24 +201,21 @@ func renderNotion(meta *Metadata, transcriptText string) string {
 		b.WriteString("\n")
 	}
 
+	writeTaskSection(b, meta)
+
 	if highlights := formatAny(meta.Highlights); highlights != "" {
 		b.WriteString("\n## Highlights\n\n")
 		b.WriteString(highlights)
 		b.WriteString("\n")
+		writePreviewEmbeds(b, meta)
 	}
 
-	if transcriptText != "" {
-		b.WriteString("\n## Transcript\n\n")
-		b.WriteString(transcriptText)
-		b.WriteString("\n")
-	}
-
-	return b.String()
+	writeTranscriptSection(b, "notion", meta, transcriptText)
 }
 
 // ── YAML helpers ─────────────────────────────────────────────────────────────
 
-func writeYAMLField(b *strings.Builder, key, value string) {
+func writeYAMLField(b noteWriter, key, value string) {
 	if value == "" {
 		return
 	}
@@ -205,7 +233,7 @@ func writeYAMLField(b *strings.Builder, key, value string) {
 	}
 }
 
-func writeYAMLList(b *strings.Builder, key string, items []string) {
+func writeYAMLList(b noteWriter, key string, items []string) {
 	if len(items) == 0 {
 		return
 	}
@@ -332,6 +360,8 @@ func formatAny(v any) string {
 	switch val := v.(type) {
 	case string:
 		return strings.TrimSpace(val)
+	case []AINoteSection:
+		return aiNotesMarkdown(val)
 	case []any:
 		var lines []string
 		for _, item := range val {
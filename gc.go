@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ── Garbage Collection ──────────────────────────────────────────────────────
+//
+// `graindl gc` finds artifacts in an archive that nothing references any
+// more and, with --apply, removes them. Every meeting's artifacts share the
+// <date>/<id> prefix of its metadata file, which anchors them:
+//
+//   - an artifact whose <date>/<id>.json is gone is orphaned (the metadata
+//     was deleted, or the meeting was re-exported under another date);
+//   - a markdown note is tied to its meeting by the grain_id frontmatter, so
+//     when --slug-style notes are renamed after a title change only the
+//     newest note per meeting is kept;
+//   - .part files older than gcPartialAge are leftovers of interrupted
+//     downloads.
+//
+// Paths listed in _export-manifest.json are never treated as orphans.
+// --check-grain additionally logs in and lists the account's meetings;
+// exported meetings missing from that list (deleted in Grain) are removed
+// with all their artifacts. The default is a dry run.
+
+// gcPartialAge is how old a .part file must be before gc treats it as an
+// abandoned download rather than one in progress.
+const gcPartialAge = 24 * time.Hour
+
+// gcArtifactSuffixes are the file suffixes exportOne and hls-convert append
+// to a meeting's <date>/<id> base, longest first. Files without one of these
+// suffixes are not graindl's and are never touched.
+var gcArtifactSuffixes = []string{
+	".ai-notes.raw.json.zst",
+	".ai-notes.raw.json.gz",
+	".ai-notes.raw.json",
+	".highlights.json.zst",
+	".transcript.txt.zst",
+	".highlights.json.gz",
+	".transcript.txt.gz",
+	".highlights.json",
+	".transcript.txt",
+	".receipt.json",
+	".assets.zip",
+	".m3u8.url",
+	".mp4.part",
+	".m4a.part",
+	".json.zst",
+	".mhtml",
+	".json.gz",
+	".json",
+	".webm",
+	".mp4",
+	".m4a",
+	".mkv",
+	".mov",
+	".md",
+	".ts",
+}
+
+// gcItem is one file gc would remove.
+type gcItem struct {
+	RelPath string
+	Reason  string
+	Size    int64
+}
+
+func runGC(args []string) int {
+	dotenv := loadDotEnv(".env")
+	var cfg Config
+	fset := flag.NewFlagSet("gc", flag.ContinueOnError)
+	fset.StringVar(&cfg.OutputDir, "output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to clean")
+	dryRun := fset.Bool("dry-run", false, "List orphaned files without removing them (default)")
+	apply := fset.Bool("apply", false, "Remove orphaned files")
+	checkGrain := fset.Bool("check-grain", false, "Also remove meetings no longer listed in Grain (logs in via the browser)")
+	fset.StringVar(&cfg.SessionDir, "session-dir", coalesce(envGet(dotenv, "GRAIN_SESSION_DIR"), "./.grain-session"), "Browser session dir (with --check-grain)")
+	fset.BoolVar(&cfg.Headless, "headless", envBool(dotenv, "GRAIN_HEADLESS"), "Headless browser (with --check-grain)")
+	fset.StringVar(&cfg.GrainBaseURL, "grain-base-url", envGet(dotenv, "GRAIN_BASE_URL"), "Grain web app base URL (with --check-grain; default "+defaultGrainBaseURL+")")
+	fset.StringVar(&cfg.GrainAPIURL, "grain-api-url", envGet(dotenv, "GRAIN_API_URL"), "Grain public API base URL (with --check-grain; default "+defaultGrainAPIURL+")")
+	fset.BoolVar(&cfg.Verbose, "verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	fset.StringVar(&cfg.LogFormat, "log-format", envGet(dotenv, "GRAIN_LOG_FORMAT"), "Log format: color (default), json")
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(cfg.LogFormat, cfg.Verbose)
+
+	if *dryRun && *apply {
+		slog.Error("--dry-run and --apply are mutually exclusive")
+		return 2
+	}
+	if _, err := os.Stat(cfg.OutputDir); err != nil {
+		slog.Error("Archive directory not found", "path", cfg.OutputDir)
+		return 1
+	}
+	if err := checkUnrouted("gc", cfg.OutputDir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if err := setGrainURLs(cfg.GrainBaseURL, cfg.GrainAPIURL); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+
+	var live map[string]bool
+	if *checkGrain {
+		sess, err := unlockSession(&cfg, envGet(dotenv, "GRAIN_SESSION_PASSPHRASE"))
+		if err != nil {
+			slog.Error("Encrypted session", "error", err)
+			return 1
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ids, err := liveMeetingIDs(ctx, &cfg)
+		sealed := closeSession(sess)
+		if err != nil {
+			slog.Error("Grain check failed", "error", err)
+			return 1
+		}
+		if !sealed {
+			return 1
+		}
+		live = ids
+	}
+
+	items, err := findOrphans(cfg.OutputDir, live, time.Now())
+	if err != nil {
+		slog.Error("gc failed", "error", err)
+		return 1
+	}
+
+	var total int64
+	verb := "Would remove"
+	if *apply {
+		verb = "Removed"
+	}
+	failed := 0
+	for _, it := range items {
+		if *apply {
+			if err := os.Remove(filepath.Join(cfg.OutputDir, it.RelPath)); err != nil && !os.IsNotExist(err) {
+				slog.Warn("Remove failed", "path", it.RelPath, "error", err)
+				failed++
+				continue
+			}
+		}
+		total += it.Size
+		slog.Info(fmt.Sprintf("%s %s (%s)", verb, it.RelPath, it.Reason))
+	}
+	if *apply {
+		removed := make([]string, 0, len(items))
+		for _, it := range items {
+			removed = append(removed, it.RelPath)
+		}
+		forgetChecksums(cfg.OutputDir, removed)
+		pruneEmptyDateDirs(cfg.OutputDir, items)
+	}
+
+	summary := fmt.Sprintf("gc: %d orphaned file(s), %s", len(items)-failed, formatBytes(total))
+	if !*apply && len(items) > 0 {
+		summary += " — rerun with --apply to remove"
+	}
+	slog.Info(summary)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// liveMeetingIDs logs in and returns the IDs of every meeting the account
+// can see, including "Shared with me" (archiveDirs scans shared/ too). An
+// empty listing is treated as an error: it is far more likely a broken
+// discovery than an account with every meeting deleted.
+func liveMeetingIDs(ctx context.Context, cfg *Config) (map[string]bool, error) {
+	if err := ensureDirPrivate(cfg.SessionDir); err != nil {
+		return nil, fmt.Errorf("session dir: %w", err)
+	}
+	cfg.SkipVideo = true
+	cfg.IncludeShared = true
+	e, err := NewExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer e.Close()
+
+	refs, err := e.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("discovery returned no meetings; refusing to treat the whole archive as deleted")
+	}
+	ids := make(map[string]bool, len(refs))
+	for _, r := range refs {
+		ids[r.ID] = true
+	}
+	return ids, nil
+}
+
+// gcFile is a candidate artifact in a date directory.
+type gcFile struct {
+	relPath string
+	relBase string // relPath minus its artifact suffix
+	size    int64
+	mod     time.Time
+}
+
+// findOrphans returns the files under outputDir that gc would remove,
+// sorted by path. live, when non-nil, is the set of meeting IDs that still
+// exist in Grain.
+func findOrphans(outputDir string, live map[string]bool, now time.Time) ([]gcItem, error) {
+	dirs, err := archiveDirs(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	protected := manifestPaths(outputDir)
+
+	anchors := map[string]string{} // relBase → meeting ID
+	var files []gcFile
+	for _, d := range dirs {
+		entries, err := os.ReadDir(filepath.Join(outputDir, d))
+		if err != nil {
+			continue
+		}
+		for _, f := range entries {
+			if f.IsDir() {
+				continue
+			}
+			suffix := artifactSuffix(f.Name())
+			if suffix == "" {
+				continue
+			}
+			info, err := f.Info()
+			if err != nil {
+				continue
+			}
+			relPath := filepath.Join(d, f.Name())
+			gf := gcFile{relPath: relPath, relBase: strings.TrimSuffix(relPath, suffix), size: info.Size(), mod: info.ModTime()}
+			if trimCompressed(suffix) == ".json" {
+				if meta, err := readArchiveMetadata(filepath.Join(outputDir, relPath)); err == nil && meta.ID != "" {
+					anchors[gf.relBase] = meta.ID
+				}
+			}
+			files = append(files, gf)
+		}
+	}
+
+	exists := map[string]bool{} // meeting IDs with metadata on disk
+	for _, id := range anchors {
+		exists[id] = true
+	}
+
+	var items []gcItem
+	add := func(f gcFile, reason string) {
+		if sealed(filepath.Join(outputDir, f.relPath)) {
+			slog.Debug("Sealed by --immutable, keeping", "path", f.relPath, "reason", reason)
+			return
+		}
+		items = append(items, gcItem{RelPath: f.relPath, Reason: reason, Size: f.size})
+	}
+	notes := map[string][]gcFile{} // meeting ID → markdown notes
+
+	for _, f := range files {
+		id, anchored := anchors[f.relBase]
+		switch {
+		case strings.HasSuffix(f.relPath, ".part"):
+			if now.Sub(f.mod) >= gcPartialAge && !protected[f.relPath] {
+				add(f, "abandoned partial download")
+			}
+			continue
+		case strings.HasSuffix(f.relPath, ".md"):
+			noteID := noteGrainID(filepath.Join(outputDir, f.relPath))
+			if noteID == "" && anchored {
+				noteID = id
+			}
+			if noteID == "" {
+				continue // not a graindl note
+			}
+			if live != nil && exists[noteID] && !live[noteID] {
+				add(f, "meeting deleted in Grain")
+			} else if !exists[noteID] {
+				if !protected[f.relPath] {
+					add(f, "no metadata for meeting "+noteID)
+				}
+			} else {
+				notes[noteID] = append(notes[noteID], f)
+			}
+			continue
+		}
+
+		if anchored {
+			if live != nil && !live[id] {
+				add(f, "meeting deleted in Grain")
+			}
+			continue
+		}
+		if plain := trimCompressed(f.relPath); protected[f.relPath] || strings.HasSuffix(plain, ".json") && !isArtifactJSON(plain) {
+			continue
+		}
+		add(f, "no metadata for "+filepath.Base(f.relBase))
+	}
+
+	// A meeting keeps only its newest note; older ones were left behind by
+	// a title change under --slug-style.
+	for _, ns := range notes {
+		sort.Slice(ns, func(i, j int) bool { return ns[i].mod.After(ns[j].mod) })
+		for _, n := range ns[1:] {
+			if !protected[n.relPath] {
+				add(n, "superseded by "+filepath.Base(ns[0].relPath))
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].RelPath < items[j].RelPath })
+	return items, nil
+}
+
+// artifactSuffix returns the graindl artifact suffix of name, or "".
+func artifactSuffix(name string) string {
+	for _, s := range gcArtifactSuffixes {
+		if strings.HasSuffix(name, s) && len(name) > len(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// isArtifactJSON reports whether a .json file is a per-meeting sidecar
+// rather than (unreadable) metadata. Unreadable metadata is left for a
+// human to look at.
+func isArtifactJSON(relPath string) bool {
+	return strings.HasSuffix(relPath, ".highlights.json") || strings.HasSuffix(relPath, ".ai-notes.raw.json") || strings.HasSuffix(relPath, receiptSuffix)
+}
+
+// manifestPaths returns every artifact path the export manifest references.
+func manifestPaths(outputDir string) map[string]bool {
+	paths := map[string]bool{}
+	data, err := os.ReadFile(filepath.Join(outputDir, "_export-manifest.json"))
+	if err != nil {
+		return paths
+	}
+	var m ExportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		slog.Warn("Unreadable export manifest; ignoring it", "error", err)
+		return paths
+	}
+	for _, r := range m.Meetings {
+		for _, p := range collectResultPaths(r) {
+			if p != "" {
+				paths[filepath.Clean(p)] = true
+			}
+		}
+	}
+	return paths
+}
+
+// pruneEmptyDateDirs removes date directories emptied by gc.
+func pruneEmptyDateDirs(outputDir string, items []gcItem) {
+	seen := map[string]bool{}
+	for _, it := range items {
+		dir := filepath.Dir(it.RelPath)
+		if !seen[dir] {
+			seen[dir] = true
+			_ = os.Remove(filepath.Join(outputDir, dir)) // only succeeds if empty
+		}
+	}
+}
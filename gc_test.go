@@ -0,0 +1,164 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeArchiveFile writes rel under dir with the given mtime offset from now.
+func writeArchiveFile(t *testing.T, dir, rel, content string, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := ensureDirPrivate(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Now().Add(-age)
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func orphanPaths(items []gcItem) map[string]string {
+	out := map[string]string{}
+	for _, it := range items {
+		out[filepath.ToSlash(it.RelPath)] = it.Reason
+	}
+	return out
+}
+
+func TestFindOrphans(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "keep", Title: "Weekly sync"})
+	writeArchiveFile(t, dir, "2025-01-15/keep.transcript.txt", "hi", 0)
+	writeArchiveFile(t, dir, "2025-01-15/keep.highlights.json", "[]", 0)
+	writeArchiveFile(t, dir, "2025-01-15/keep.mp4", "video", 0)
+	writeArchiveFile(t, dir, "2025-01-15/keep.mp4.part", "in progress", time.Minute)
+	// Title-slug notes for "keep": the older one predates a rename.
+	writeArchiveFile(t, dir, "2025-01-15/weekly-sync.md", "---\ngrain_id: keep\n---\n", 0)
+	writeArchiveFile(t, dir, "2025-01-15/weekly-standup.md", "---\ngrain_id: keep\n---\n", time.Hour)
+
+	// Artifacts of a meeting whose metadata is gone.
+	writeArchiveFile(t, dir, "2025-01-15/gone.transcript.txt", "bye", 0)
+	writeArchiveFile(t, dir, "2025-01-15/gone.ai-notes.raw.json", "{}", 0)
+	writeArchiveFile(t, dir, "2025-01-15/gone.md", "---\ngrain_id: gone\n---\n", 0)
+	writeArchiveFile(t, dir, "2025-01-16/old.mp4.part", "stale", 48*time.Hour)
+
+	// Not graindl's, or not safe to judge: left alone.
+	writeArchiveFile(t, dir, "2025-01-15/notes.txt", "mine", 0)
+	writeArchiveFile(t, dir, "2025-01-15/readme.md", "# my notes", 0)
+	writeArchiveFile(t, dir, "2025-01-15/broken.json", "{not json", 0)
+	writeArchiveFile(t, dir, "_claims/x.claim", "", 0)
+
+	items, err := findOrphans(dir, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := orphanPaths(items)
+	want := map[string]string{
+		"2025-01-15/gone.transcript.txt":    "no metadata for gone",
+		"2025-01-15/gone.ai-notes.raw.json": "no metadata for gone",
+		"2025-01-15/gone.md":                "no metadata for meeting gone",
+		"2025-01-15/weekly-standup.md":      "superseded by weekly-sync.md",
+		"2025-01-16/old.mp4.part":           "abandoned partial download",
+	}
+	if len(got) != len(want) {
+		t.Errorf("orphans = %v, want %v", got, want)
+	}
+	for p, reason := range want {
+		if got[p] != reason {
+			t.Errorf("%s: reason %q, want %q", p, got[p], reason)
+		}
+	}
+	if items[0].Size == 0 {
+		t.Error("sizes not recorded")
+	}
+}
+
+func TestFindOrphansManifestProtects(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveFile(t, dir, "2025-01-15/m1.mp4", "video", 0)
+	writeManifest(t, dir, &ExportManifest{Meetings: []*ExportResult{
+		{ID: "m1", VideoPath: filepath.Join("2025-01-15", "m1.mp4")},
+	}})
+
+	items, err := findOrphans(dir, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("manifest-referenced file reported: %v", items)
+	}
+}
+
+func TestFindOrphansDeletedInGrain(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "live"})
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "deleted"})
+	writeArchiveFile(t, dir, "2025-01-15/deleted.transcript.txt", "x", 0)
+	writeArchiveFile(t, dir, "2025-01-15/deleted-title.md", "---\ngrain_id: deleted\n---\n", 0)
+
+	items, err := findOrphans(dir, map[string]bool{"live": true}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := orphanPaths(items)
+	for _, p := range []string{"2025-01-15/deleted.json", "2025-01-15/deleted.transcript.txt", "2025-01-15/deleted-title.md"} {
+		if got[p] != "meeting deleted in Grain" {
+			t.Errorf("%s: reason %q", p, got[p])
+		}
+	}
+	if _, ok := got["2025-01-15/live.json"]; ok || len(got) != 3 {
+		t.Errorf("orphans = %v", got)
+	}
+}
+
+func TestRunGCApply(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "keep"})
+	writeArchiveFile(t, dir, "2025-01-16/gone.transcript.txt", "bye", 0)
+
+	if code := runGC([]string{"--output", dir, "--dry-run"}); code != 0 {
+		t.Fatalf("dry run exit = %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025-01-16", "gone.transcript.txt")); err != nil {
+		t.Fatal("dry run removed a file")
+	}
+
+	if code := runGC([]string{"--output", dir, "--apply"}); code != 0 {
+		t.Fatalf("apply exit = %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025-01-16")); !os.IsNotExist(err) {
+		t.Error("emptied date dir not pruned")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025-01-15", "keep.json")); err != nil {
+		t.Error("metadata removed")
+	}
+
+	if code := runGC([]string{"--output", dir, "--dry-run", "--apply"}); code != 2 {
+		t.Errorf("--dry-run with --apply exit = %d, want 2", code)
+	}
+	if code := runGC([]string{"--output", dir, "--check-grain", "--grain-base-url", "ftp://grain.example"}); code != 2 {
+		t.Errorf("invalid --grain-base-url exit = %d, want 2", code)
+	}
+}
+
+func TestArtifactSuffix(t *testing.T) {
+	for name, want := range map[string]string{
+		"m1.ai-notes.raw.json": ".ai-notes.raw.json",
+		"m1.highlights.json":   ".highlights.json",
+		"m1.json":              ".json",
+		"m1.mp4.part":          ".mp4.part",
+		"weekly-sync.md":       ".md",
+		"notes.txt":            "",
+		".md":                  "",
+	} {
+		if got := artifactSuffix(name); got != want {
+			t.Errorf("artifactSuffix(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
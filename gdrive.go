@@ -12,6 +12,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -22,6 +23,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -39,15 +41,33 @@ const (
 	googleTokenURL  = "https://oauth2.googleapis.com/token"
 )
 
+// Files larger than driveResumableThreshold are uploaded through a resumable
+// session in driveChunkSize pieces (Drive wants multiples of 256 KiB) rather
+// than one multipart request. Before the session starts and before each
+// chunk, an access token that expires within driveTokenMargin is refreshed,
+// so a long transfer never sends a chunk with a token about to lapse.
+const (
+	driveResumableThreshold = 8 << 20
+	driveChunkSize          = 8 << 20
+	driveTokenMargin        = 5 * time.Minute
+)
+
 // ── Sync State ──────────────────────────────────────────────────────────────
 
 // DriveSyncState tracks which files have been uploaded to Google Drive.
 // Persisted to .grain-session/gdrive-sync.json.
 type DriveSyncState struct {
-	Version  int                   `json:"version"`
-	LastSync string                `json:"last_sync"`
-	FolderID string                `json:"folder_id"`
-	Files    map[string]*SyncEntry `json:"files"`
+	Version     int                   `json:"version"`
+	LastSync    string                `json:"last_sync"`
+	CompactedAt string                `json:"compacted_at,omitempty"`
+	FolderID    string                `json:"folder_id"`
+	FolderPath  string                `json:"folder_path,omitempty"` // --gdrive-folder-path that resolved to FolderID
+	FolderRoot  string                `json:"folder_root,omitempty"` // folder the path was resolved under
+	Files       map[string]*SyncEntry `json:"files"`
+
+	// Transactions are meeting uploads still in progress, by meeting ID
+	// (see drivetxn.go).
+	Transactions map[string]*DriveTxn `json:"transactions,omitempty"`
 }
 
 // SyncEntry records a single uploaded file's state.
@@ -64,6 +84,7 @@ type UploadStats struct {
 	Created int
 	Updated int
 	Skipped int
+	Failed  int // gdrive sync only; UploadExportResult stops at the first failure
 }
 
 // VerifyReport summarizes the result of a Drive-side verification.
@@ -90,10 +111,26 @@ type DriveUploader struct {
 	conflict  string // "local-wins", "skip", "newer-wins"
 	mu        sync.Mutex
 
-	// Fields for token refresh (user OAuth2 only).
+	localRoot  string // output dir; sync state keys are relative to it
+	cleanLocal bool   // --gdrive-clean-local or --gdrive-keep-videos: missing local files are expected
+
+	// Storage quota tracking (see reserveQuota). Guarded by mu.
+	quotaGuard     bool
+	quotaChecked   bool
+	quotaRemaining int64 // bytes; -1 = unlimited or unknown
+
+	preserve string            // "", "keep-forever", "copy" (--gdrive-preserve-revisions)
+	routes   []driveRoute      // --gdrive-route rules; first match wins
+	labels   map[string]string // --classify-route: access label → folder, ahead of routes
+
+	// Fields for token refresh: the refresh token for user OAuth2, or the
+	// key a new JWT is signed with for service accounts.
 	clientID     string
 	clientSecret string
 	refreshToken string
+	saKey        *rsa.PrivateKey
+	saEmail      string
+	saTokenURI   string
 }
 
 // oauthToken holds an access token and its expiry.
@@ -109,10 +146,19 @@ type oauthToken struct {
 // and loads any existing sync state.
 func NewDriveUploader(ctx context.Context, cfg *Config) (*DriveUploader, error) {
 	d := &DriveUploader{
-		client:    &http.Client{Timeout: 5 * time.Minute},
+		client:    &http.Client{Timeout: 5 * time.Minute, Transport: withChaos(cfg.Chaos, chaosUpload, false, nil)},
 		folderID:  cfg.GDriveFolderID,
 		folderMap: map[string]string{".": cfg.GDriveFolderID},
 		conflict:  cfg.GDriveConflict,
+
+		localRoot:  cfg.OutputDir,
+		cleanLocal: cfg.GDriveCleanLocal || cfg.GDriveKeepVideos > 0,
+
+		quotaGuard:     cfg.GDriveQuotaGuard,
+		quotaRemaining: -1,
+		preserve:       cfg.GDrivePreserve,
+		routes:         cfg.GDriveRoutes,
+		labels:         cfg.ClassifyRoutes,
 	}
 
 	// Warn if credentials file has overly permissive permissions.
@@ -141,13 +187,28 @@ func NewDriveUploader(ctx context.Context, cfg *Config) (*DriveUploader, error)
 		return nil, fmt.Errorf("load sync state: %w", err)
 	}
 
+	folderID := cfg.GDriveFolderID
+	if cfg.GDriveFolderPath != "" {
+		folderID, err = d.resolveFolderPath(ctx, state, cfg.GDriveFolderID, cfg.GDriveFolderPath, cfg.GDriveCreateDir)
+		if err != nil {
+			return nil, fmt.Errorf("resolve --gdrive-folder-path: %w", err)
+		}
+		d.folderID = folderID
+		d.folderMap["."] = folderID
+	}
+
 	// Detect folder ID change — reset state if user switched target folders.
-	if state.FolderID != "" && state.FolderID != cfg.GDriveFolderID {
+	if state.FolderID != "" && state.FolderID != folderID {
 		slog.Warn("Drive folder ID changed, resetting sync state",
-			"old", state.FolderID, "new", cfg.GDriveFolderID)
+			"old", state.FolderID, "new", folderID)
 		state = &DriveSyncState{Version: 1, Files: make(map[string]*SyncEntry)}
 	}
-	state.FolderID = cfg.GDriveFolderID
+	state.FolderID = folderID
+	state.FolderPath = cfg.GDriveFolderPath
+	state.FolderRoot = ""
+	if cfg.GDriveFolderPath != "" {
+		state.FolderRoot = cfg.GDriveFolderID
+	}
 
 	d.state = state
 	d.statePath = statePath
@@ -205,6 +266,7 @@ func (d *DriveUploader) authServiceAccount(ctx context.Context, credPath string)
 		return err
 	}
 	d.token = tok
+	d.saKey, d.saEmail, d.saTokenURI = rsaKey, key.ClientEmail, tokenURI
 	return nil
 }
 
@@ -396,20 +458,28 @@ func saveCachedToken(path string, tok *oauthToken) error {
 
 // accessToken returns a valid access token, refreshing if expired.
 func (d *DriveUploader) accessToken(ctx context.Context) (string, error) {
+	return d.tokenFor(ctx, time.Minute)
+}
+
+// tokenFor returns an access token that stays valid for at least validFor,
+// refreshing it first when it expires sooner. Without a way to refresh, the
+// current token is returned and the API decides.
+func (d *DriveUploader) tokenFor(ctx context.Context, validFor time.Duration) (string, error) {
 	d.tokenMu.Lock()
 	defer d.tokenMu.Unlock()
 
-	if d.token != nil && time.Now().Before(d.token.Expiry.Add(-1*time.Minute)) {
+	if d.token != nil && time.Now().Add(validFor).Before(d.token.Expiry) {
 		return d.token.AccessToken, nil
 	}
 
-	// Token expired — refresh.
-	if d.refreshToken != "" {
-		tok, err := d.refreshAccessToken(ctx)
+	// Token expired or about to — refresh.
+	if d.refreshToken != "" || d.saKey != nil {
+		tok, err := d.renewToken(ctx)
 		if err != nil {
 			return "", fmt.Errorf("refresh token: %w", err)
 		}
 		d.token = tok
+		slog.Debug("Drive access token refreshed", "expires", tok.Expiry.Format(time.TimeOnly))
 		return tok.AccessToken, nil
 	}
 
@@ -420,6 +490,27 @@ func (d *DriveUploader) accessToken(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("no valid access token")
 }
 
+// renewToken fetches a new access token: with the refresh token for user
+// OAuth2, or by signing a new JWT for a service account.
+func (d *DriveUploader) renewToken(ctx context.Context) (*oauthToken, error) {
+	if d.refreshToken != "" {
+		return d.refreshAccessToken(ctx)
+	}
+	return exchangeJWT(ctx, d.client, d.saKey, d.saEmail, d.saTokenURI)
+}
+
+// expireToken marks the current access token as expired, so the next
+// request refreshes it. Used when Drive rejects a token before its expiry.
+func (d *DriveUploader) expireToken() {
+	d.tokenMu.Lock()
+	defer d.tokenMu.Unlock()
+	if d.token != nil {
+		tok := *d.token
+		tok.Expiry = time.Time{}
+		d.token = &tok
+	}
+}
+
 func (d *DriveUploader) refreshAccessToken(ctx context.Context) (*oauthToken, error) {
 	form := url.Values{
 		"client_id":     {d.clientID},
@@ -473,6 +564,15 @@ type driveFileList struct {
 }
 
 func (d *DriveUploader) driveRequest(ctx context.Context, method, url string, body io.Reader, contentType string) (*http.Response, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return d.driveRequestHeader(ctx, method, url, body, header)
+}
+
+// driveRequestHeader is driveRequest with arbitrary request headers.
+func (d *DriveUploader) driveRequestHeader(ctx context.Context, method, url string, body io.Reader, header http.Header) (*http.Response, error) {
 	token, err := d.accessToken(ctx)
 	if err != nil {
 		return nil, err
@@ -482,10 +582,10 @@ func (d *DriveUploader) driveRequest(ctx context.Context, method, url string, bo
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	if contentType != "" {
-		req.Header.Set("Content-Type", contentType)
+	for k, v := range header {
+		req.Header[k] = v
 	}
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	return d.client.Do(req)
 }
@@ -549,6 +649,9 @@ func (d *DriveUploader) uploadFile(ctx context.Context, localPath, fileName, mim
 		return "", err
 	}
 	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > driveResumableThreshold {
+		return d.uploadResumable(ctx, f, info.Size(), fileName, mimeType, parentID, existingID)
+	}
 
 	var buf bytes.Buffer
 	w := multipart.NewWriter(&buf)
@@ -607,6 +710,122 @@ func (d *DriveUploader) uploadFile(ctx context.Context, localPath, fileName, mim
 	return result.ID, nil
 }
 
+// uploadResumable uploads f through a resumable session, one driveChunkSize
+// piece per request. The access token is refreshed ahead of its expiry
+// before the session starts and before every chunk; a chunk rejected with
+// 401 anyway gets one retry with a new token, resuming from the offset Drive
+// reports.
+func (d *DriveUploader) uploadResumable(ctx context.Context, f *os.File, size int64, fileName, mimeType, parentID, existingID string) (string, error) {
+	if _, err := d.tokenFor(ctx, driveTokenMargin); err != nil {
+		return "", err
+	}
+
+	meta := map[string]any{"name": fileName}
+	apiURL := fmt.Sprintf("%s/files?uploadType=resumable&fields=id,md5Checksum", driveUploadBase)
+	method := "POST"
+	if existingID != "" {
+		apiURL = fmt.Sprintf("%s/files/%s?uploadType=resumable&fields=id,md5Checksum", driveUploadBase, existingID)
+		method = "PATCH"
+	} else {
+		meta["parents"] = []string{parentID}
+	}
+	body, _ := json.Marshal(meta)
+	header := http.Header{}
+	header.Set("Content-Type", "application/json; charset=UTF-8")
+	header.Set("X-Upload-Content-Type", mimeType)
+	header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	resp, err := d.driveRequestHeader(ctx, method, apiURL, bytes.NewReader(body), header)
+	if err != nil {
+		return "", err
+	}
+	session := resp.Header.Get("Location")
+	if resp.StatusCode != http.StatusOK {
+		body := readErrorBody(resp.Body)
+		resp.Body.Close()
+		return "", &driveAPIError{Code: resp.StatusCode, Body: string(body)}
+	}
+	resp.Body.Close()
+	if session == "" {
+		return "", fmt.Errorf("resumable upload: no session URI")
+	}
+
+	buf := make([]byte, driveChunkSize)
+	var offset int64
+	authRetried := false
+	for {
+		if offset >= size {
+			return "", fmt.Errorf("resumable upload: Drive stored all %d bytes but did not finish", size)
+		}
+		if _, err := d.tokenFor(ctx, driveTokenMargin); err != nil {
+			return "", err
+		}
+		n, err := f.ReadAt(buf[:min(int64(len(buf)), size-offset)], offset)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		header := http.Header{}
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(n)-1, size))
+		resp, err := d.driveRequestHeader(ctx, "PUT", session, bytes.NewReader(buf[:n]), header)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+			var result driveFile
+			err := json.NewDecoder(resp.Body).Decode(&result)
+			resp.Body.Close()
+			return result.ID, err
+		case resp.StatusCode == http.StatusPermanentRedirect: // 308: chunk stored, more to send
+			resp.Body.Close()
+			offset = resumeOffset(resp.Header.Get("Range"))
+			authRetried = false
+		case resp.StatusCode == http.StatusUnauthorized && !authRetried:
+			resp.Body.Close()
+			slog.Debug("Drive rejected the access token mid-upload, refreshing", "file", fileName, "offset", offset)
+			d.expireToken()
+			authRetried = true
+			if offset, err = d.resumableStatus(ctx, session, size); err != nil {
+				return "", err
+			}
+		default:
+			body := readErrorBody(resp.Body)
+			resp.Body.Close()
+			return "", &driveAPIError{Code: resp.StatusCode, Body: string(body)}
+		}
+	}
+}
+
+// resumableStatus asks Drive how much of a resumable upload it has stored
+// and returns the offset to continue from.
+func (d *DriveUploader) resumableStatus(ctx context.Context, session string, size int64) (int64, error) {
+	header := http.Header{}
+	header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	resp, err := d.driveRequestHeader(ctx, "PUT", session, http.NoBody, header)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPermanentRedirect {
+		body := readErrorBody(resp.Body)
+		return 0, &driveAPIError{Code: resp.StatusCode, Body: string(body)}
+	}
+	return resumeOffset(resp.Header.Get("Range")), nil
+}
+
+// resumeOffset returns the next byte to send after a 308 whose Range header
+// is "bytes=0-N". No header means Drive stored nothing yet.
+func resumeOffset(rangeHeader string) int64 {
+	_, last, ok := strings.Cut(strings.TrimPrefix(rangeHeader, "bytes="), "-")
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n + 1
+}
+
 // driveAPIError represents an HTTP error from the Drive API.
 type driveAPIError struct {
 	Code int
@@ -619,44 +838,52 @@ func (e *driveAPIError) Error() string {
 
 // ── Sync State Persistence ──────────────────────────────────────────────────
 
+// loadDriveSyncState reads the Drive sync state, falling back to its .bak
+// copy when the primary is missing or corrupt.
 func loadDriveSyncState(path string) (*DriveSyncState, error) {
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
+	var state *DriveSyncState
+	err := readStateFile(path, func(data []byte) error {
+		var s DriveSyncState
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("unmarshal sync state: %w", err)
+		}
+		state = &s
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
 		return &DriveSyncState{Version: 1, Files: make(map[string]*SyncEntry)}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	var state DriveSyncState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("unmarshal sync state: %w", err)
-	}
 	if state.Files == nil {
 		state.Files = make(map[string]*SyncEntry)
 	}
-	return &state, nil
+	return state, nil
 }
 
+// saveSyncState writes the sync state atomically, keeping the previous
+// version as a .bak copy. Entries whose local file is gone are dropped once
+// per syncCompactInterval, except with --gdrive-clean-local, which removes
+// local files on purpose.
 func (d *DriveUploader) saveSyncState() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	d.state.LastSync = time.Now().UTC().Format(time.RFC3339)
+	now := time.Now().UTC().Format(time.RFC3339)
+	d.state.LastSync = now
+	if d.localRoot != "" && !d.cleanLocal && compactDue(d.state.CompactedAt) {
+		if n := compactSyncFiles(d.state.Files, d.localRoot, nil); n > 0 {
+			slog.Info("Drive sync state compacted", "dropped", n)
+		}
+		d.state.CompactedAt = now
+	}
 
 	data, err := json.MarshalIndent(d.state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal sync state: %w", err)
 	}
-
-	// Atomic write: temp file + rename.
-	tmp := d.statePath + ".tmp"
-	if err := writeFile(tmp, data); err != nil {
-		return fmt.Errorf("write temp sync state: %w", err)
-	}
-	if err := os.Rename(tmp, d.statePath); err != nil {
-		return fmt.Errorf("rename sync state: %w", err)
-	}
-	return nil
+	return writeStateFile(d.statePath, data)
 }
 
 // ── Upload Decision ─────────────────────────────────────────────────────────
@@ -765,6 +992,105 @@ func (d *DriveUploader) EnsureFolder(ctx context.Context, relDir string) (string
 	return parentID, nil
 }
 
+// splitFolderPath splits a --gdrive-folder-path into folder names. Empty
+// segments from leading, trailing, or doubled slashes are dropped; "." and
+// ".." are rejected rather than treated as Drive folder names.
+func splitFolderPath(p string) ([]string, error) {
+	var names []string
+	for _, part := range strings.Split(p, "/") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "":
+			continue
+		case ".", "..":
+			return nil, fmt.Errorf("%q: relative segments are not allowed", p)
+		}
+		names = append(names, part)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%q: no folder names", p)
+	}
+	return names, nil
+}
+
+// driveFolderLabel describes the configured Drive target for log lines.
+func driveFolderLabel(cfg *Config) string {
+	if cfg.GDriveFolderPath != "" {
+		return cfg.GDriveFolderPath
+	}
+	return cfg.GDriveFolderID
+}
+
+// resolveFolderPath returns the ID of the folder at path, walked by name
+// from rootID (or My Drive when empty). The ID cached in state is reused
+// while it still names a live folder; otherwise the path is walked again,
+// creating missing folders when create is set.
+func (d *DriveUploader) resolveFolderPath(ctx context.Context, state *DriveSyncState, rootID, path string, create bool) (string, error) {
+	if state.FolderPath == path && state.FolderRoot == rootID && state.FolderID != "" {
+		ok, err := d.folderExists(ctx, state.FolderID)
+		if err != nil {
+			return "", fmt.Errorf("check cached folder: %w", err)
+		}
+		if ok {
+			return state.FolderID, nil
+		}
+		slog.Warn("Cached Drive folder is gone, resolving path again", "path", path, "id", state.FolderID)
+	}
+
+	names, err := splitFolderPath(path)
+	if err != nil {
+		return "", err
+	}
+	parentID := coalesce(rootID, "root")
+	for i, name := range names {
+		id, err := d.findFolder(ctx, parentID, name)
+		if err != nil {
+			return "", fmt.Errorf("find folder %q: %w", name, err)
+		}
+		if id == "" {
+			walked := strings.Join(names[:i+1], "/")
+			if !create {
+				return "", fmt.Errorf("folder %q not found (use --gdrive-folder-create to create it)", walked)
+			}
+			if id, err = d.createFolder(ctx, name, parentID); err != nil {
+				return "", fmt.Errorf("create folder %q: %w", name, err)
+			}
+			slog.Info("Created Drive folder", "path", walked, "id", id)
+		}
+		parentID = id
+	}
+	slog.Info("Resolved Drive folder", "path", path, "id", parentID)
+	return parentID, nil
+}
+
+// folderExists reports whether id names a folder that is not in the trash.
+func (d *DriveUploader) folderExists(ctx context.Context, id string) (bool, error) {
+	apiURL := fmt.Sprintf("%s/files/%s?fields=%s", driveAPIBase, url.PathEscape(id), url.QueryEscape("id,mimeType,trashed"))
+	resp, err := d.driveRequest(ctx, "GET", apiURL, nil, "")
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		body := readErrorBody(resp.Body)
+		return false, fmt.Errorf("get folder failed (%d): %s", resp.StatusCode, body)
+	}
+
+	var f struct {
+		MIMEType string `json:"mimeType"`
+		Trashed  bool   `json:"trashed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return false, err
+	}
+	return f.MIMEType == "application/vnd.google-apps.folder" && !f.Trashed, nil
+}
+
 // findFolder searches for an existing folder by name within a parent folder.
 // Uses a targeted Drive API query instead of listing all children, avoiding
 // pagination issues when parents contain more than 100 items.
@@ -801,13 +1127,14 @@ func (d *DriveUploader) findFolder(ctx context.Context, parentID, name string) (
 // Returns the Drive file ID.
 func (d *DriveUploader) Upload(ctx context.Context, localPath, relPath string) (string, error) {
 	action, entry := d.shouldUpload(localPath, relPath)
-	return d.uploadWithHint(ctx, localPath, relPath, action, entry)
+	return d.uploadWithHint(ctx, localPath, relPath, relPath, action, entry)
 }
 
 // uploadWithHint performs the upload using a pre-computed action/entry pair,
 // avoiding redundant shouldUpload (and MD5) calls when the caller already
-// knows the decision (e.g. UploadExportResult).
-func (d *DriveUploader) uploadWithHint(ctx context.Context, localPath, relPath, action string, entry *SyncEntry) (string, error) {
+// knows the decision (e.g. UploadExportResult). remotePath is relPath with
+// any --gdrive-route prefix applied; sync state stays keyed by relPath.
+func (d *DriveUploader) uploadWithHint(ctx context.Context, localPath, relPath, remotePath, action string, entry *SyncEntry) (string, error) {
 	if action == "skip" {
 		slog.Debug("Drive upload skipped (in sync)", "path", relPath)
 		return "", nil
@@ -818,7 +1145,7 @@ func (d *DriveUploader) uploadWithHint(ctx context.Context, localPath, relPath,
 		return "", fmt.Errorf("stat %s: %w", localPath, err)
 	}
 
-	relDir := filepath.Dir(relPath)
+	relDir := filepath.Dir(remotePath)
 	parentID, err := d.EnsureFolder(ctx, relDir)
 	if err != nil {
 		return "", fmt.Errorf("ensure folder %s: %w", relDir, err)
@@ -831,6 +1158,12 @@ func (d *DriveUploader) uploadWithHint(ctx context.Context, localPath, relPath,
 	if action == "update" && entry != nil {
 		existingID = entry.DriveFileID
 	}
+	if existingID != "" && d.preserve != "" {
+		// Refuse to overwrite if the previous version can't be kept.
+		if err := d.preservePrevious(ctx, existingID, remotePath); err != nil {
+			return "", fmt.Errorf("preserve previous %s: %w", relPath, err)
+		}
+	}
 
 	driveFileID, err := d.retryUpload(ctx, localPath, fileName, mimeType, parentID, existingID)
 	if err != nil {
@@ -898,24 +1231,88 @@ func isTransientCode(code int) bool {
 
 // UploadExportResult uploads all files referenced by an ExportResult.
 func (d *DriveUploader) UploadExportResult(ctx context.Context, outputDir string, r *ExportResult) (*UploadStats, error) {
-	stats := &UploadStats{}
+	return d.UploadPaths(ctx, outputDir, r.ID, r.DriveRoute, collectResultPaths(r))
+}
+
+// UploadPaths uploads one meeting's files under its --gdrive-route folder.
+// The bytes that actually need uploading are checked against the remaining
+// Drive quota first, so a full Drive fails fast instead of mid-batch.
+func (d *DriveUploader) UploadPaths(ctx context.Context, outputDir, id, route string, paths []string) (*UploadStats, error) {
+	plan := d.planUpload(outputDir, paths)
+	if err := d.reserveQuota(ctx, plan.bytes); err != nil {
+		return plan.stats, err
+	}
+	return d.sendUpload(ctx, id, route, plan)
+}
+
+// pendingUpload is one file an uploadPlan still has to send.
+type pendingUpload struct {
+	localPath, relPath, action string
+	entry                      *SyncEntry
+}
 
-	paths := collectResultPaths(r)
+// uploadPlan is one meeting's files sorted into skips and pending uploads,
+// with the Drive bytes the pending ones will take (see uploadCost).
+type uploadPlan struct {
+	stats   *UploadStats
+	pending []pendingUpload
+	files   []string
+	bytes   int64
+}
 
+// planUpload decides what each of paths needs without touching Drive, so a
+// caller can reserve quota for several meetings before the first upload.
+func (d *DriveUploader) planUpload(outputDir string, paths []string) *uploadPlan {
+	plan := &uploadPlan{stats: &UploadStats{}}
 	for _, relPath := range paths {
 		if relPath == "" {
 			continue
 		}
 		localPath := filepath.Join(outputDir, relPath)
-		if !fileExists(localPath) {
+		info, err := os.Stat(localPath)
+		if err != nil {
 			continue
 		}
 
+		plan.files = append(plan.files, relPath)
 		action, entry := d.shouldUpload(localPath, relPath)
-		switch action {
-		case "skip":
-			stats.Skipped++
+		if action == "skip" {
+			plan.stats.Skipped++
 			continue
+		}
+		plan.pending = append(plan.pending, pendingUpload{localPath, relPath, action, entry})
+		plan.bytes += d.uploadCost(action, entry, info.Size())
+	}
+	return plan
+}
+
+// uploadCost is the Drive quota an upload of size bytes consumes. A create
+// takes its full size; an update replaces the old content, so only the
+// growth counts, unless --gdrive-preserve-revisions keeps the old version
+// around too.
+func (d *DriveUploader) uploadCost(action string, entry *SyncEntry, size int64) int64 {
+	if action != "update" || entry == nil {
+		return size
+	}
+	cost := size - entry.Size
+	if d.preserve != "" {
+		cost += entry.Size
+	}
+	return max(cost, 0)
+}
+
+// sendUpload uploads a planned meeting as one transaction (see
+// drivetxn.go). Quota must already be reserved.
+func (d *DriveUploader) sendUpload(ctx context.Context, id, route string, plan *uploadPlan) (*UploadStats, error) {
+	stats := plan.stats
+	if len(plan.pending) == 0 {
+		d.endTxn(id, nil) // an interrupted upload is complete after all
+		return stats, nil
+	}
+
+	d.beginTxn(id, route, plan.files)
+	for _, p := range plan.pending {
+		switch p.action {
 		case "update":
 			stats.Updated++
 		case "create":
@@ -923,10 +1320,17 @@ func (d *DriveUploader) UploadExportResult(ctx context.Context, outputDir string
 		}
 
 		// Pass pre-computed action/entry to avoid redundant MD5 in Upload.
-		if _, err := d.uploadWithHint(ctx, localPath, relPath, action, entry); err != nil {
-			return stats, fmt.Errorf("upload %s: %w", relPath, err)
+		remotePath := filepath.Join(route, p.relPath)
+		if _, err := d.uploadWithHint(ctx, p.localPath, p.relPath, remotePath, p.action, p.entry); err != nil {
+			err = fmt.Errorf("upload %s: %w", p.relPath, err)
+			d.endTxn(id, err)
+			return stats, err
+		}
+		if p.action == "create" {
+			d.txnCreated(id, p.relPath)
 		}
 	}
+	d.endTxn(id, nil)
 	return stats, nil
 }
 
@@ -938,9 +1342,14 @@ func collectResultPaths(r *ExportResult) []string {
 		paths = append(paths, p)
 	}
 	paths = append(paths, r.HighlightsPath)
+	paths = append(paths, r.AINotesPath)
 	paths = append(paths, r.MarkdownPath)
+	paths = append(paths, r.MarkdownParts...)
 	paths = append(paths, r.VideoPath)
+	paths = append(paths, r.AssetsPath)
 	paths = append(paths, r.AudioPath)
+	paths = append(paths, r.SnapshotPath)
+	paths = append(paths, r.PreviewPaths...)
 	return paths
 }
 
@@ -954,6 +1363,281 @@ func (d *DriveUploader) UploadManifest(ctx context.Context, outputDir, manifestP
 	return err
 }
 
+// ── Folder Routing ──────────────────────────────────────────────────────────
+//
+// --gdrive-route "tag:customer->Customers,title:interview->Hiring/Interviews"
+// places each meeting's files under a subfolder of the root Drive folder,
+// chosen by the first rule that matches the meeting's tags or title.
+// Unmatched meetings go to the root as before.
+
+// driveRoute is a single parsed --gdrive-route rule.
+type driveRoute struct {
+	Kind   string // "tag" (exact, case-insensitive) or "title" (substring)
+	Value  string // lowercased match value
+	Folder string // relative Drive folder path, slash-separated
+}
+
+// parseDriveRoutes parses a comma-separated list of kind:value->Folder rules.
+func parseDriveRoutes(s string) ([]driveRoute, error) {
+	var routes []driveRoute
+	for _, rule := range strings.Split(s, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		match, folder, ok := strings.Cut(rule, "->")
+		if !ok {
+			return nil, fmt.Errorf("route %q: missing '->'", rule)
+		}
+		kind, value, ok := strings.Cut(strings.TrimSpace(match), ":")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("route %q: expected kind:value before '->'", rule)
+		}
+		if kind != "tag" && kind != "title" {
+			return nil, fmt.Errorf("route %q: unknown match kind %q (want tag or title)", rule, kind)
+		}
+		folder = strings.Trim(strings.TrimSpace(folder), "/")
+		if folder == "" {
+			return nil, fmt.Errorf("route %q: empty folder", rule)
+		}
+		for _, seg := range strings.Split(folder, "/") {
+			if seg == "" || seg == "." || seg == ".." {
+				return nil, fmt.Errorf("route %q: invalid folder path %q", rule, folder)
+			}
+		}
+		routes = append(routes, driveRoute{Kind: kind, Value: value, Folder: folder})
+	}
+	return routes, nil
+}
+
+// Route returns the Drive subfolder for meta, or "" for the root folder.
+// A --classify-route for meta's classification wins over --gdrive-route.
+func (d *DriveUploader) Route(meta *Metadata) string {
+	if meta != nil && d.labels[meta.Classification] != "" {
+		return d.labels[meta.Classification]
+	}
+	return matchDriveRoute(d.routes, meta)
+}
+
+func matchDriveRoute(routes []driveRoute, meta *Metadata) string {
+	if len(routes) == 0 || meta == nil {
+		return ""
+	}
+	title := strings.ToLower(meta.Title)
+	tags := flattenStringSlice(meta.Tags)
+	for _, rt := range routes {
+		switch rt.Kind {
+		case "tag":
+			for _, t := range tags {
+				if strings.EqualFold(strings.TrimSpace(t), rt.Value) {
+					return rt.Folder
+				}
+			}
+		case "title":
+			if strings.Contains(title, rt.Value) {
+				return rt.Folder
+			}
+		}
+	}
+	return ""
+}
+
+// ── Revision Preservation ───────────────────────────────────────────────────
+//
+// --gdrive-preserve-revisions makes updates non-destructive:
+//   keep-forever  pins the current head revision (keepForever) before the
+//                 update, so Drive never auto-purges it.
+//   copy          copies the current file to _previous/<dir>/ with a
+//                 timestamp suffix before the update.
+
+// preservePrevious keeps the current Drive version of fileID according to
+// d.preserve. Called right before an update overwrites it.
+func (d *DriveUploader) preservePrevious(ctx context.Context, fileID, relPath string) error {
+	switch d.preserve {
+	case "keep-forever":
+		return d.pinHeadRevision(ctx, fileID)
+	case "copy":
+		relDir := filepath.Join("_previous", filepath.Dir(relPath))
+		parentID, err := d.EnsureFolder(ctx, relDir)
+		if err != nil {
+			return fmt.Errorf("ensure folder %s: %w", relDir, err)
+		}
+		name := previousCopyName(filepath.Base(relPath), time.Now())
+		if err := d.copyFile(ctx, fileID, name, parentID); err != nil {
+			return err
+		}
+		slog.Debug("Drive previous version copied", "path", relPath, "name", name)
+		return nil
+	}
+	return nil
+}
+
+// pinHeadRevision marks the file's current head revision keepForever.
+func (d *DriveUploader) pinHeadRevision(ctx context.Context, fileID string) error {
+	apiURL := fmt.Sprintf("%s/files/%s?fields=headRevisionId", driveAPIBase, url.PathEscape(fileID))
+	resp, err := d.driveRequest(ctx, "GET", apiURL, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &driveAPIError{Code: resp.StatusCode, Body: string(readErrorBody(resp.Body))}
+	}
+	var file struct {
+		HeadRevisionID string `json:"headRevisionId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return err
+	}
+	if file.HeadRevisionID == "" {
+		// Google Docs-native files have no binary revisions to pin.
+		return nil
+	}
+
+	apiURL = fmt.Sprintf("%s/files/%s/revisions/%s?fields=id", driveAPIBase,
+		url.PathEscape(fileID), url.PathEscape(file.HeadRevisionID))
+	body := strings.NewReader(`{"keepForever":true}`)
+	resp2, err := d.driveRequest(ctx, "PATCH", apiURL, body, "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp2.Body.Close()
+	if resp2.StatusCode != http.StatusOK {
+		return &driveAPIError{Code: resp2.StatusCode, Body: string(readErrorBody(resp2.Body))}
+	}
+	return nil
+}
+
+// copyFile copies fileID into parentID under a new name.
+func (d *DriveUploader) copyFile(ctx context.Context, fileID, name, parentID string) error {
+	meta := map[string]any{
+		"name":    name,
+		"parents": []string{parentID},
+	}
+	body, _ := json.Marshal(meta)
+
+	apiURL := fmt.Sprintf("%s/files/%s/copy?fields=id", driveAPIBase, url.PathEscape(fileID))
+	resp, err := d.driveRequest(ctx, "POST", apiURL, bytes.NewReader(body), "application/json")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &driveAPIError{Code: resp.StatusCode, Body: string(readErrorBody(resp.Body))}
+	}
+	return nil
+}
+
+// previousCopyName inserts a UTC timestamp before the extension:
+// "abc.transcript.txt" → "abc.transcript.20240102T150405Z.txt".
+func previousCopyName(name string, t time.Time) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + t.UTC().Format("20060102T150405Z") + ext
+}
+
+// ── Storage Quota ───────────────────────────────────────────────────────────
+
+// driveQuota mirrors the about.storageQuota response. Limit is absent for
+// unlimited (e.g. some Workspace) accounts; values are int64 strings.
+type driveQuota struct {
+	Limit int64 `json:"limit,string"`
+	Usage int64 `json:"usage,string"`
+}
+
+// quotaExceededError is returned by reserveQuota when --gdrive-quota-guard
+// is set and an upload would not fit.
+type quotaExceededError struct {
+	Need      int64
+	Remaining int64
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("drive quota exceeded: need %s, %s remaining",
+		formatBytes(e.Need), formatBytes(e.Remaining))
+}
+
+// fetchQuota queries Drive's about.storageQuota.
+func (d *DriveUploader) fetchQuota(ctx context.Context) (*driveQuota, error) {
+	apiURL := driveAPIBase + "/about?fields=" + url.QueryEscape("storageQuota(limit,usage)")
+	resp, err := d.driveRequest(ctx, "GET", apiURL, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body := readErrorBody(resp.Body)
+		return nil, fmt.Errorf("about failed (%d): %s", resp.StatusCode, body)
+	}
+
+	var about struct {
+		StorageQuota driveQuota `json:"storageQuota"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&about); err != nil {
+		return nil, err
+	}
+	return &about.StorageQuota, nil
+}
+
+// remaining returns the bytes left under q, or -1 if unlimited.
+func (q *driveQuota) remaining() int64 {
+	if q.Limit <= 0 {
+		return -1
+	}
+	return max(q.Limit-q.Usage, 0)
+}
+
+// quotaAllows reports whether need bytes fit in remaining (-1 = unlimited).
+func quotaAllows(remaining, need int64) bool {
+	return remaining < 0 || need <= remaining
+}
+
+// reserveQuota checks need bytes against the remaining Drive quota and
+// deducts them. The quota is fetched once per run and then tracked locally,
+// so large batches cost a single about call. Without --gdrive-quota-guard an
+// overrun only warns; with it, a quotaExceededError is returned and nothing
+// is uploaded.
+func (d *DriveUploader) reserveQuota(ctx context.Context, need int64) error {
+	if need == 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	checked := d.quotaChecked
+	d.mu.Unlock()
+
+	if !checked {
+		q, err := d.fetchQuota(ctx)
+		d.mu.Lock()
+		d.quotaChecked = true
+		if err != nil {
+			slog.Warn("Drive quota check failed, continuing without it", "error", err)
+		} else {
+			d.quotaRemaining = q.remaining()
+			if d.quotaRemaining >= 0 {
+				slog.Debug("Drive storage quota", "used", formatBytes(q.Usage), "limit", formatBytes(q.Limit))
+			}
+		}
+		d.mu.Unlock()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !quotaAllows(d.quotaRemaining, need) {
+		if d.quotaGuard {
+			return &quotaExceededError{Need: need, Remaining: d.quotaRemaining}
+		}
+		slog.Warn("Upload exceeds remaining Drive quota",
+			"need", formatBytes(need), "remaining", formatBytes(d.quotaRemaining))
+	}
+	if d.quotaRemaining >= 0 {
+		d.quotaRemaining = max(d.quotaRemaining-need, 0)
+	}
+	return nil
+}
+
 // ── Verification ────────────────────────────────────────────────────────────
 
 // Verify reconciles local sync state against actual files on Drive.
@@ -1071,6 +1755,20 @@ func readErrorBody(r io.Reader) []byte {
 	return body
 }
 
+// formatBytes renders a byte count with a binary-unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func md5File(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -1102,6 +1800,8 @@ func detectMIME(path string) string {
 		return "video/webm"
 	case ".url":
 		return "text/plain"
+	case ".mhtml":
+		return "multipart/related"
 	}
 	if t := mime.TypeByExtension(ext); t != "" {
 		return t
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
@@ -339,6 +346,7 @@ func TestCollectResultPaths(t *testing.T) {
 		MarkdownPath:    "2025-01-15/abc.md",
 		VideoPath:       "2025-01-15/abc.mp4",
 		AudioPath:       "",
+		SnapshotPath:    "2025-01-15/abc.mhtml",
 	}
 
 	paths := collectResultPaths(r)
@@ -349,6 +357,7 @@ func TestCollectResultPaths(t *testing.T) {
 		"2025-01-15/abc.highlights.json": true,
 		"2025-01-15/abc.md":              true,
 		"2025-01-15/abc.mp4":             true,
+		"2025-01-15/abc.mhtml":           true,
 	}
 
 	found := 0
@@ -469,3 +478,453 @@ func TestBase64URLEncode(t *testing.T) {
 		t.Errorf("base64URLEncode = %q, want dGVzdA", got)
 	}
 }
+
+// ── Storage quota ───────────────────────────────────────────────────────────
+
+func TestDriveQuotaRemaining(t *testing.T) {
+	tests := []struct {
+		q    driveQuota
+		want int64
+	}{
+		{driveQuota{Limit: 100, Usage: 40}, 60},
+		{driveQuota{Limit: 100, Usage: 150}, 0},
+		{driveQuota{Limit: 0, Usage: 999}, -1}, // unlimited
+	}
+	for _, tt := range tests {
+		if got := tt.q.remaining(); got != tt.want {
+			t.Errorf("remaining(%+v) = %d, want %d", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestDriveQuotaParse(t *testing.T) {
+	var about struct {
+		StorageQuota driveQuota `json:"storageQuota"`
+	}
+	body := `{"storageQuota":{"limit":"16106127360","usage":"1073741824"}}`
+	if err := json.Unmarshal([]byte(body), &about); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if about.StorageQuota.Limit != 16106127360 || about.StorageQuota.Usage != 1073741824 {
+		t.Errorf("quota = %+v", about.StorageQuota)
+	}
+}
+
+func TestQuotaAllows(t *testing.T) {
+	if !quotaAllows(-1, 1<<40) {
+		t.Error("unlimited quota should allow any upload")
+	}
+	if !quotaAllows(100, 100) {
+		t.Error("exact fit should be allowed")
+	}
+	if quotaAllows(100, 101) {
+		t.Error("overrun should not be allowed")
+	}
+}
+
+func TestReserveQuotaGuard(t *testing.T) {
+	d := &DriveUploader{quotaGuard: true, quotaChecked: true, quotaRemaining: 1000}
+
+	if err := d.reserveQuota(context.Background(), 600); err != nil {
+		t.Fatalf("first reserve: %v", err)
+	}
+	if d.quotaRemaining != 400 {
+		t.Errorf("remaining = %d, want 400", d.quotaRemaining)
+	}
+
+	err := d.reserveQuota(context.Background(), 500)
+	var qe *quotaExceededError
+	if !errors.As(err, &qe) {
+		t.Fatalf("expected quotaExceededError, got %v", err)
+	}
+	if qe.Need != 500 || qe.Remaining != 400 {
+		t.Errorf("error = %+v", qe)
+	}
+	if d.quotaRemaining != 400 {
+		t.Errorf("refused reservation should not deduct: remaining = %d", d.quotaRemaining)
+	}
+}
+
+func TestReserveQuotaWarnOnly(t *testing.T) {
+	d := &DriveUploader{quotaChecked: true, quotaRemaining: 100}
+	if err := d.reserveQuota(context.Background(), 500); err != nil {
+		t.Errorf("without guard, overrun should only warn: %v", err)
+	}
+	if d.quotaRemaining != 0 {
+		t.Errorf("remaining = %d, want 0", d.quotaRemaining)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{512, "512 B"},
+		{1536, "1.5 KiB"},
+		{5 << 30, "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// ── Revision preservation ───────────────────────────────────────────────────
+
+func TestPreviousCopyName(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		in, want string
+	}{
+		{"abc.json", "abc.20240102T150405Z.json"},
+		{"abc.transcript.txt", "abc.transcript.20240102T150405Z.txt"},
+		{"README", "README.20240102T150405Z"},
+	}
+	for _, tt := range tests {
+		if got := previousCopyName(tt.in, ts); got != tt.want {
+			t.Errorf("previousCopyName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPreservePreviousDisabled(t *testing.T) {
+	d := &DriveUploader{}
+	if err := d.preservePrevious(context.Background(), "file-id", "2024-01-02/abc.json"); err != nil {
+		t.Errorf("preservePrevious with preserve unset = %v, want nil", err)
+	}
+}
+
+// ── Folder routing ──────────────────────────────────────────────────────────
+
+func TestParseDriveRoutes(t *testing.T) {
+	routes, err := parseDriveRoutes(" tag:Customer->Customers , title:Interview->/Hiring/Interviews/ ,")
+	if err != nil {
+		t.Fatalf("parseDriveRoutes: %v", err)
+	}
+	want := []driveRoute{
+		{Kind: "tag", Value: "customer", Folder: "Customers"},
+		{Kind: "title", Value: "interview", Folder: "Hiring/Interviews"},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("routes = %+v, want %+v", routes, want)
+	}
+	for i := range want {
+		if routes[i] != want[i] {
+			t.Errorf("routes[%d] = %+v, want %+v", i, routes[i], want[i])
+		}
+	}
+}
+
+func TestParseDriveRoutesInvalid(t *testing.T) {
+	bad := []string{
+		"tag:customer",            // no arrow
+		"customer->Customers",     // no kind
+		"owner:bob->Bob",          // unknown kind
+		"tag:->Customers",         // empty value
+		"tag:customer->",          // empty folder
+		"tag:customer->../Escape", // traversal
+		"tag:customer->A//B",      // empty segment
+	}
+	for _, s := range bad {
+		if _, err := parseDriveRoutes(s); err == nil {
+			t.Errorf("parseDriveRoutes(%q) should fail", s)
+		}
+	}
+}
+
+func TestMatchDriveRoute(t *testing.T) {
+	routes, _ := parseDriveRoutes("tag:customer->Customers,tag:internal->Internal,title:interview->Hiring")
+
+	tests := []struct {
+		name string
+		meta *Metadata
+		want string
+	}{
+		{"tag match", &Metadata{Title: "Sync", Tags: []string{"Customer"}}, "Customers"},
+		{"first rule wins", &Metadata{Title: "Sync", Tags: []any{"internal", "customer"}}, "Customers"},
+		{"title substring", &Metadata{Title: "Onsite Interview – Jane"}, "Hiring"},
+		{"tag is exact not substring", &Metadata{Title: "Sync", Tags: []string{"customers-old"}}, ""},
+		{"no match", &Metadata{Title: "Standup"}, ""},
+		{"nil meta", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := matchDriveRoute(routes, tt.meta); got != tt.want {
+			t.Errorf("%s: matchDriveRoute = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDriveSaveSyncStateCompaction(t *testing.T) {
+	for _, cleanLocal := range []bool{false, true} {
+		dir := t.TempDir()
+		os.MkdirAll(filepath.Join(dir, "2025-01-15"), 0o755)
+		writeFile(filepath.Join(dir, "2025-01-15", "m1.json"), []byte("{}"))
+		d := &DriveUploader{
+			state: &DriveSyncState{Version: 1, Files: map[string]*SyncEntry{
+				"2025-01-15/m1.json": {DriveFileID: "a"},
+				"2025-01-15/m2.json": {DriveFileID: "b"},
+			}},
+			statePath:  filepath.Join(t.TempDir(), "gdrive-sync.json"),
+			localRoot:  dir,
+			cleanLocal: cleanLocal,
+		}
+		if err := d.saveSyncState(); err != nil {
+			t.Fatal(err)
+		}
+		want := 1
+		if cleanLocal {
+			want = 2 // local files are removed on purpose; keep the Drive IDs
+		}
+		if len(d.state.Files) != want {
+			t.Errorf("cleanLocal=%v: files = %d, want %d", cleanLocal, len(d.state.Files), want)
+		}
+	}
+}
+
+func TestLoadDriveSyncStateRecoversFromBackup(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "gdrive-sync.json")
+	writeFile(statePath+stateBackupSuffix, []byte(`{"version":1,"folder_id":"f1","files":{"a.json":{"drive_file_id":"x"}}}`))
+	writeFile(statePath, []byte(`{"version":1,"fold`))
+
+	state, err := loadDriveSyncState(statePath)
+	if err != nil {
+		t.Fatalf("loadDriveSyncState: %v", err)
+	}
+	if state.FolderID != "f1" || state.Files["a.json"] == nil {
+		t.Fatalf("expected backup contents, got %+v", state)
+	}
+
+	os.Remove(statePath + stateBackupSuffix)
+	if _, err := loadDriveSyncState(statePath); err == nil {
+		t.Fatal("corrupt state without a backup should error")
+	}
+}
+
+// ── Folder path resolution ──────────────────────────────────────────────────
+
+func TestSplitFolderPath(t *testing.T) {
+	names, err := splitFolderPath(" /Team/ Recordings//Grain/ ")
+	if err != nil {
+		t.Fatalf("splitFolderPath: %v", err)
+	}
+	if strings.Join(names, "|") != "Team|Recordings|Grain" {
+		t.Errorf("names = %q", names)
+	}
+	for _, bad := range []string{"", "/", "Team/../Grain", "./Grain"} {
+		if _, err := splitFolderPath(bad); err == nil {
+			t.Errorf("splitFolderPath(%q): expected error", bad)
+		}
+	}
+}
+
+// fakeDriveFolders serves the folder lookups used by resolveFolderPath from
+// an in-memory tree keyed by "parentID/name".
+type fakeDriveFolders struct {
+	folders  map[string]string // "parentID/name" → ID
+	live     map[string]bool   // ID → exists (not trashed)
+	requests int
+}
+
+var fakeDriveQuery = regexp.MustCompile(`'([^']*)' in parents and name = '([^']*)'`)
+
+func (f *fakeDriveFolders) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.requests++
+	rec := httptest.NewRecorder()
+	path := strings.TrimPrefix(req.URL.Path, "/drive/v3/files")
+	switch {
+	case req.Method == "GET" && path == "":
+		m := fakeDriveQuery.FindStringSubmatch(req.URL.Query().Get("q"))
+		var files []driveFile
+		if id, ok := f.folders[m[1]+"/"+m[2]]; ok {
+			files = append(files, driveFile{ID: id})
+		}
+		json.NewEncoder(rec).Encode(driveFileList{Files: files})
+	case req.Method == "POST":
+		var body struct {
+			Name    string   `json:"name"`
+			Parents []string `json:"parents"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		id := "id-" + body.Name
+		f.folders[body.Parents[0]+"/"+body.Name] = id
+		f.live[id] = true
+		json.NewEncoder(rec).Encode(driveFile{ID: id})
+	case req.Method == "GET":
+		if !f.live[strings.TrimPrefix(path, "/")] {
+			rec.WriteHeader(http.StatusNotFound)
+			break
+		}
+		rec.WriteString(`{"mimeType":"application/vnd.google-apps.folder","trashed":false}`)
+	}
+	return rec.Result(), nil
+}
+
+func newFakeDriveUploader(f *fakeDriveFolders) *DriveUploader {
+	return &DriveUploader{
+		client: &http.Client{Transport: f},
+		token:  &oauthToken{AccessToken: "t", Expiry: time.Now().Add(time.Hour)},
+	}
+}
+
+func TestResolveFolderPath(t *testing.T) {
+	ctx := context.Background()
+	f := &fakeDriveFolders{
+		folders: map[string]string{"root/Team": "team"},
+		live:    map[string]bool{"team": true},
+	}
+	d := newFakeDriveUploader(f)
+	state := &DriveSyncState{}
+
+	if _, err := d.resolveFolderPath(ctx, state, "", "Team/Recordings", false); err == nil ||
+		!strings.Contains(err.Error(), `"Team/Recordings" not found`) {
+		t.Fatalf("missing folder without create: err = %v", err)
+	}
+
+	id, err := d.resolveFolderPath(ctx, state, "", "Team/Recordings/Grain", true)
+	if err != nil {
+		t.Fatalf("resolve with create: %v", err)
+	}
+	if id != "id-Grain" || f.folders["team/Recordings"] != "id-Recordings" || f.folders["id-Recordings/Grain"] != "id-Grain" {
+		t.Fatalf("id = %q, folders = %v", id, f.folders)
+	}
+
+	// A cached ID that still exists is reused with a single lookup.
+	state = &DriveSyncState{FolderID: id, FolderPath: "Team/Recordings/Grain"}
+	f.requests = 0
+	if got, err := d.resolveFolderPath(ctx, state, "", "Team/Recordings/Grain", false); err != nil || got != id {
+		t.Fatalf("cached resolve = %q, %v", got, err)
+	}
+	if f.requests != 1 {
+		t.Errorf("cached resolve made %d requests, want 1", f.requests)
+	}
+
+	// The cache is ignored when the root folder changed.
+	state.FolderRoot = "other-root"
+	if _, err := d.resolveFolderPath(ctx, state, "", "Team/Recordings/Grain", false); err != nil {
+		t.Fatalf("resolve after root change: %v", err)
+	}
+
+	// A cached folder that disappeared is resolved again by path.
+	delete(f.live, id)
+	delete(f.folders, "id-Recordings/Grain")
+	state = &DriveSyncState{FolderID: id, FolderPath: "Team/Recordings/Grain"}
+	got, err := d.resolveFolderPath(ctx, state, "", "Team/Recordings/Grain", true)
+	if err != nil {
+		t.Fatalf("resolve after deletion: %v", err)
+	}
+	if got != "id-Grain" || !f.live[got] {
+		t.Errorf("re-resolved id = %q, live = %v", got, f.live)
+	}
+}
+
+func TestResolveFolderPathUnderRoot(t *testing.T) {
+	f := &fakeDriveFolders{
+		folders: map[string]string{"shared/Grain": "grain"},
+		live:    map[string]bool{},
+	}
+	d := newFakeDriveUploader(f)
+	id, err := d.resolveFolderPath(context.Background(), &DriveSyncState{}, "shared", "Grain", false)
+	if err != nil || id != "grain" {
+		t.Errorf("resolve under --gdrive-folder-id = %q, %v", id, err)
+	}
+}
+
+// fakeResumable serves the token endpoint and one resumable upload session,
+// recording which access token each chunk was sent with.
+type fakeResumable struct {
+	d         *DriveUploader
+	size      int64
+	stored    int64
+	refreshes int
+	chunkAuth []string
+	reject    int // chunk number (1-based) rejected once with 401
+	chunks    int
+}
+
+func (f *fakeResumable) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	storedRange := func() {
+		if f.stored > 0 {
+			rec.Header().Set("Range", fmt.Sprintf("bytes=0-%d", f.stored-1))
+		}
+		rec.WriteHeader(http.StatusPermanentRedirect)
+	}
+	switch {
+	case req.URL.Host == "oauth2.googleapis.com":
+		f.refreshes++
+		fmt.Fprintf(rec, `{"access_token":"t%d","expires_in":3600}`, f.refreshes)
+	case req.Method == "POST":
+		if req.URL.Query().Get("uploadType") != "resumable" || req.Header.Get("X-Upload-Content-Length") != fmt.Sprint(f.size) {
+			rec.WriteHeader(http.StatusBadRequest)
+			break
+		}
+		rec.Header().Set("Location", "https://upload.example/session")
+		rec.WriteHeader(http.StatusOK)
+	case req.Header.Get("Content-Range") == fmt.Sprintf("bytes */%d", f.size):
+		storedRange()
+	default:
+		f.chunks++
+		f.chunkAuth = append(f.chunkAuth, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+		if f.chunks == f.reject {
+			rec.WriteHeader(http.StatusUnauthorized)
+			break
+		}
+		var start int64
+		fmt.Sscanf(req.Header.Get("Content-Range"), "bytes %d-", &start)
+		n, _ := io.Copy(io.Discard, req.Body)
+		if start != f.stored {
+			rec.WriteHeader(http.StatusBadRequest)
+			break
+		}
+		f.stored += n
+		if f.stored == f.size {
+			rec.WriteString(`{"id":"big-file"}`)
+			break
+		}
+		if f.chunks == 1 {
+			// The transfer outlives the token: it now expires within the margin.
+			f.d.token.Expiry = time.Now().Add(time.Minute)
+		}
+		storedRange()
+	}
+	return rec.Result(), nil
+}
+
+func TestUploadResumableRefreshesToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.mp4")
+	size := int64(driveChunkSize*2 + 1024)
+	if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeResumable{size: size, reject: 3}
+	d := &DriveUploader{
+		client:       &http.Client{Transport: f},
+		token:        &oauthToken{AccessToken: "t0", Expiry: time.Now().Add(2 * time.Minute)},
+		refreshToken: "r",
+	}
+	f.d = d
+
+	id, err := d.uploadFile(context.Background(), path, "big.mp4", "video/mp4", "parent", "")
+	if err != nil || id != "big-file" {
+		t.Fatalf("uploadFile = %q, %v", id, err)
+	}
+	// t1 before the session (t0 expires within the margin), t2 once the
+	// first chunk crossed the margin, t3 after the third chunk's 401.
+	if got := strings.Join(f.chunkAuth, ","); got != "t1,t2,t2,t3" {
+		t.Errorf("chunk tokens = %s, want t1,t2,t2,t3", got)
+	}
+	if f.refreshes != 3 {
+		t.Errorf("refreshes = %d, want 3", f.refreshes)
+	}
+}
+
+func TestResumeOffset(t *testing.T) {
+	for in, want := range map[string]int64{"": 0, "bytes=0-8388607": 8388608, "garbage": 0} {
+		if got := resumeOffset(in); got != want {
+			t.Errorf("resumeOffset(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
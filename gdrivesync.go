@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// ── Upload-only Sync ────────────────────────────────────────────────────────
+//
+// `graindl gdrive sync --output dir` uploads an existing local archive to
+// Drive without an export pass: files missing from or changed since the
+// sync state are uploaded, everything else is skipped. The archive can come
+// from an older run or another machine; --gdrive-route is applied using the
+// meeting metadata on disk. An encrypted session (--encrypt-session) is
+// unlocked for the run, so the token and sync state inside it are used.
+
+func runGDrive(args []string) int {
+	if len(args) == 0 || args[0] != "sync" {
+		fmt.Fprintln(os.Stderr, "usage: graindl gdrive sync [flags]")
+		return 2
+	}
+
+	dotenv := loadDotEnv(".env")
+	var cfg Config
+	fset := flag.NewFlagSet("gdrive sync", flag.ContinueOnError)
+	fset.StringVar(&cfg.OutputDir, "output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to upload")
+	fset.StringVar(&cfg.SessionDir, "session-dir", coalesce(envGet(dotenv, "GRAIN_SESSION_DIR"), "./.grain-session"), "Session dir (Drive token and sync state)")
+	routes := registerGDriveFlags(fset, &cfg, dotenv)
+	dryRun := fset.Bool("dry-run", envBool(dotenv, "GRAIN_DRY_RUN"), "List files that would be uploaded without uploading")
+	fset.BoolVar(&cfg.Verbose, "verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	fset.StringVar(&cfg.LogFormat, "log-format", envGet(dotenv, "GRAIN_LOG_FORMAT"), "Log format: color (default), json")
+	if err := fset.Parse(args[1:]); err != nil {
+		return 2
+	}
+	setupLogger(cfg.LogFormat, cfg.Verbose)
+
+	cfg.GDrive = true
+	if err := finishGDriveConfig(&cfg, *routes); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if _, err := os.Stat(cfg.OutputDir); err != nil {
+		slog.Error("Archive directory not found", "path", cfg.OutputDir)
+		return 1
+	}
+	sess, err := unlockSession(&cfg, envGet(dotenv, "GRAIN_SESSION_PASSPHRASE"))
+	if err != nil {
+		slog.Error("Encrypted session", "error", err)
+		return 1
+	}
+	code := syncToDrive(&cfg, *dryRun)
+	if !closeSession(sess) {
+		code = max(code, 1)
+	}
+	return code
+}
+
+// syncToDrive runs gdrive sync with a ready session dir and returns the
+// exit code.
+func syncToDrive(cfg *Config, dryRun bool) int {
+	if err := ensureDirPrivate(cfg.SessionDir); err != nil {
+		slog.Error("Session dir", "error", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	d, err := NewDriveUploader(ctx, cfg)
+	if err != nil {
+		slog.Error("Google Drive init failed", "error", err)
+		return 1
+	}
+
+	if cfg.GDriveVerify && !dryRun {
+		report, err := d.Verify(ctx, cfg.OutputDir)
+		if err != nil {
+			slog.Warn("Drive verification failed", "error", err)
+		} else {
+			slog.Info("Drive verification complete",
+				"in_sync", report.InSync,
+				"re_uploaded", report.ReUploaded,
+				"deleted_remotely", report.DeletedRemotely,
+				"modified_remotely", report.ModifiedRemotely,
+				"untracked", report.Untracked)
+		}
+	}
+
+	slog.Info(fmt.Sprintf("Syncing %s → Drive folder %s", absPath(cfg.OutputDir), driveFolderLabel(cfg)))
+	stats, err := d.SyncArchive(ctx, cfg.OutputDir, dryRun)
+	if !dryRun {
+		if serr := d.saveSyncState(); serr != nil {
+			slog.Warn("Failed to save Drive sync state", "error", serr)
+		}
+	}
+	if err != nil {
+		slog.Error("Drive sync failed", "error", err)
+		return 1
+	}
+
+	verb := "Synced"
+	if dryRun {
+		verb = "Would sync"
+	}
+	slog.Info(fmt.Sprintf("%s: %d created, %d updated, %d unchanged, %d failed",
+		verb, stats.Created, stats.Updated, stats.Skipped, stats.Failed))
+	if stats.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// SyncArchive uploads every archive file under outputDir that is missing
+// from or changed since the sync state, honoring the conflict strategy,
+// quota check, and folder routes. Individual upload failures are logged and
+// counted; the error return is reserved for failures that stop the whole
+// sync (unreadable archive, quota guard, cancellation).
+func (d *DriveUploader) SyncArchive(ctx context.Context, outputDir string, dryRun bool) (*UploadStats, error) {
+	stats := &UploadStats{}
+
+	// Finish or roll back interrupted meeting uploads first, so the files
+	// they uploaded aren't counted as pending below.
+	if !dryRun {
+		d.ResumeTransactions(ctx, outputDir)
+	}
+
+	paths, err := archiveUploadPaths(outputDir)
+	if err != nil {
+		return stats, err
+	}
+	routeFor := d.archiveRoutes(outputDir)
+
+	var pending []pendingUpload
+	var pendingBytes int64
+
+	for _, relPath := range paths {
+		localPath := filepath.Join(outputDir, relPath)
+		info, err := os.Stat(localPath)
+		if err != nil {
+			continue
+		}
+		action, entry := d.shouldUpload(localPath, relPath)
+		if action == "skip" {
+			stats.Skipped++
+			continue
+		}
+		pending = append(pending, pendingUpload{localPath, relPath, action, entry})
+		pendingBytes += d.uploadCost(action, entry, info.Size())
+	}
+
+	if dryRun {
+		for _, p := range pending {
+			slog.Info("Would upload", "path", p.relPath, "action", p.action, "route", routeFor(p.relPath))
+			if p.action == "update" {
+				stats.Updated++
+			} else {
+				stats.Created++
+			}
+		}
+		return stats, nil
+	}
+
+	if err := d.reserveQuota(ctx, pendingBytes); err != nil {
+		return stats, err
+	}
+
+	for _, p := range pending {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		remotePath := filepath.Join(routeFor(p.relPath), p.relPath)
+		if _, err := d.uploadWithHint(ctx, p.localPath, p.relPath, remotePath, p.action, p.entry); err != nil {
+			slog.Warn("Drive upload failed", "path", p.relPath, "error", err)
+			stats.Failed++
+			continue
+		}
+		if p.action == "update" {
+			stats.Updated++
+		} else {
+			stats.Created++
+		}
+		slog.Debug("Synced", "path", p.relPath, "action", p.action)
+	}
+	return stats, nil
+}
+
+// archiveUploadPaths lists the uploadable files under outputDir, relative
+// and sorted. Hidden files, internal "_" dirs (claims etc.), in-progress
+// HLS part dirs, temp files, and receipts are skipped; the root manifest is
+// kept.
+func archiveUploadPaths(outputDir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(outputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != outputDir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || strings.HasSuffix(name, ".hls-parts")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || isTempFileName(name) || strings.HasSuffix(name, receiptSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan archive: %w", err)
+	}
+	return paths, nil
+}
+
+// isTempFileName reports leftovers from interrupted writes and downloads.
+func isTempFileName(name string) bool {
+	for _, marker := range []string{".tmp", ".part", ".crdownload", ".stale-"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveRoutes returns a lookup from archive file to --gdrive-route folder,
+// using each meeting's metadata on disk. Files are matched to meetings by
+// their "<date>/<id>" prefix, or for title-named notes (--slug-style) by
+// the grain_id in their frontmatter.
+func (d *DriveUploader) archiveRoutes(outputDir string) func(relPath string) string {
+	if len(d.routes) == 0 {
+		return func(string) string { return "" }
+	}
+	byBase := make(map[string]string)
+	byID := make(map[string]string)
+	if entries, err := scanArchive(outputDir); err == nil {
+		for _, e := range entries {
+			route := d.Route(e.Meta)
+			byBase[e.RelBase] = route
+			byID[filepath.Dir(e.RelBase)+"/"+e.Meta.ID] = route
+		}
+	}
+	return func(relPath string) string {
+		dir, base := filepath.Dir(relPath), filepath.Base(relPath)
+		stem, _, _ := strings.Cut(base, ".")
+		if route, ok := byBase[filepath.Join(dir, stem)]; ok {
+			return route
+		}
+		if filepath.Ext(base) == ".md" {
+			if id := noteGrainID(filepath.Join(outputDir, relPath)); id != "" {
+				return byID[dir+"/"+id]
+			}
+		}
+		return ""
+	}
+}
+
+// noteGrainID reads the grain_id frontmatter field of a markdown note.
+func noteGrainID(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for i := 0; i < 40 && s.Scan(); i++ {
+		if v, ok := strings.CutPrefix(s.Text(), "grain_id:"); ok {
+			return strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newSyncArchive builds a small archive tree for gdrive sync tests.
+func newSyncArchive(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "m1", Title: "Acme kickoff", Tags: []any{"customer"}})
+	writeArchiveMeta(t, dir, "2025-01-16", &Metadata{ID: "m2", Title: "Standup"})
+	files := map[string]string{
+		"2025-01-15/m1.transcript.txt":         "hello",
+		"2025-01-15/acme-kickoff.md":           "---\ngrain_id: \"m1\"\n---\n# Acme kickoff\n",
+		"2025-01-15/m1.mp4.part":               "partial",
+		"2025-01-15/m1.mp4.hls-parts/00000.ts": "seg",
+		"2025-01-16/.DS_Store":                 "junk",
+		"_claims/m3.claim":                     "{}",
+		".hidden/x.json":                       "{}",
+		"_export-manifest.json":                "{}",
+	}
+	for rel, body := range files {
+		p := filepath.Join(dir, rel)
+		if err := ensureDirPrivate(filepath.Dir(p)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// ── archiveUploadPaths ──────────────────────────────────────────────────────
+
+func TestArchiveUploadPaths(t *testing.T) {
+	dir := newSyncArchive(t)
+	got, err := archiveUploadPaths(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"2025-01-15/acme-kickoff.md",
+		"2025-01-15/m1.json",
+		"2025-01-15/m1.transcript.txt",
+		"2025-01-16/m2.json",
+		"_export-manifest.json",
+	}
+	for i := range want {
+		want[i] = filepath.FromSlash(want[i])
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("archiveUploadPaths =\n%v\nwant\n%v", got, want)
+	}
+}
+
+// ── archiveRoutes ───────────────────────────────────────────────────────────
+
+func TestArchiveRoutes(t *testing.T) {
+	dir := newSyncArchive(t)
+	routes, err := parseDriveRoutes("tag:customer->Customers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &DriveUploader{routes: routes}
+	routeFor := d.archiveRoutes(dir)
+
+	tests := map[string]string{
+		"2025-01-15/m1.json":           "Customers",
+		"2025-01-15/m1.transcript.txt": "Customers",
+		"2025-01-15/acme-kickoff.md":   "Customers", // via grain_id frontmatter
+		"2025-01-16/m2.json":           "",
+		"_export-manifest.json":        "",
+	}
+	for rel, want := range tests {
+		if got := routeFor(filepath.FromSlash(rel)); got != want {
+			t.Errorf("route(%s) = %q, want %q", rel, got, want)
+		}
+	}
+
+	if got := (&DriveUploader{}).archiveRoutes(dir)("2025-01-15/m1.json"); got != "" {
+		t.Errorf("no routes configured should give root, got %q", got)
+	}
+}
+
+// ── SyncArchive ─────────────────────────────────────────────────────────────
+
+func TestSyncArchiveDryRun(t *testing.T) {
+	dir := newSyncArchive(t)
+
+	checksum, err := md5File(filepath.Join(dir, "2025-01-16", "m2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &DriveUploader{
+		conflict: "local-wins",
+		state: &DriveSyncState{Files: map[string]*SyncEntry{
+			filepath.Join("2025-01-16", "m2.json"):           {DriveFileID: "a", MD5Checksum: checksum},
+			filepath.Join("2025-01-15", "m1.transcript.txt"): {DriveFileID: "b", MD5Checksum: "stale"},
+		}},
+	}
+
+	stats, err := d.SyncArchive(context.Background(), dir, true)
+	if err != nil {
+		t.Fatalf("SyncArchive: %v", err)
+	}
+	if stats.Skipped != 1 || stats.Updated != 1 || stats.Created != 3 || stats.Failed != 0 {
+		t.Errorf("stats = %+v, want 3 created, 1 updated, 1 skipped", *stats)
+	}
+	if len(d.state.Files) != 2 {
+		t.Errorf("dry run must not modify sync state, files = %d", len(d.state.Files))
+	}
+}
+
+// ── finishGDriveConfig ──────────────────────────────────────────────────────
+
+func TestFinishGDriveConfig(t *testing.T) {
+	base := Config{GDriveFolderID: "f", GDriveCredentials: "c.json", GDriveConflict: "local-wins", SessionDir: "/s"}
+
+	cfg := base
+	if err := finishGDriveConfig(&cfg, "tag:a->A"); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+	if cfg.GDriveTokenFile != filepath.Join("/s", "gdrive-token.json") {
+		t.Errorf("token file = %q", cfg.GDriveTokenFile)
+	}
+	if len(cfg.GDriveRoutes) != 1 {
+		t.Errorf("routes = %v", cfg.GDriveRoutes)
+	}
+
+	cfg = base
+	cfg.GDriveFolderID = ""
+	cfg.GDriveFolderPath = "/Team//Grain/"
+	cfg.GDriveCreateDir = true
+	if err := finishGDriveConfig(&cfg, ""); err != nil {
+		t.Fatalf("path-only config: %v", err)
+	}
+	if cfg.GDriveFolderPath != "Team/Grain" {
+		t.Errorf("folder path = %q, want normalized", cfg.GDriveFolderPath)
+	}
+
+	bad := []func(*Config) string{
+		func(c *Config) string { c.GDriveFolderID = ""; return "" },
+		func(c *Config) string { c.GDriveCredentials = ""; return "" },
+		func(c *Config) string { c.GDriveConflict = "yolo"; return "" },
+		func(c *Config) string { c.GDrivePreserve = "forever"; return "" },
+		func(c *Config) string { return "tag:a->../x" },
+		func(c *Config) string { c.GDriveCreateDir = true; return "" },
+		func(c *Config) string { c.GDriveFolderPath = "Team/../Grain"; return "" },
+	}
+	for i, mutate := range bad {
+		cfg := base
+		routes := mutate(&cfg)
+		if err := finishGDriveConfig(&cfg, routes); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func TestRunGDriveUsage(t *testing.T) {
+	if code := runGDrive(nil); code != 2 {
+		t.Errorf("no subcommand exit = %d, want 2", code)
+	}
+	if code := runGDrive([]string{"upload"}); code != 2 {
+		t.Errorf("unknown subcommand exit = %d, want 2", code)
+	}
+}
+
+func TestRunGDriveEncryptedSession(t *testing.T) {
+	fastSessionKDF(t)
+	archive := t.TempDir()
+	writeArchiveMeta(t, archive, "2025-01-15", &Metadata{ID: "m1", Title: "Acme kickoff"})
+	creds := filepath.Join(t.TempDir(), "creds.json")
+	if err := os.WriteFile(creds, []byte(`{"installed":{"client_id":"c","client_secret":"s"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The token and a complete sync state exist only inside the container.
+	sessionDir := filepath.Join(t.TempDir(), "sess")
+	s, err := openEncryptedSession(sessionDir, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel := filepath.Join("2025-01-15", "m1.json")
+	sum, err := md5File(filepath.Join(archive, rel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &DriveSyncState{Version: 1, FolderID: "folder", Files: map[string]*SyncEntry{rel: {DriveFileID: "f1", MD5Checksum: sum}}}
+	data, _ := json.Marshal(state)
+	writeSessionFile(t, s.Dir(), "gdrive-sync.json", string(data))
+	writeSessionFile(t, s.Dir(), "gdrive-token.json", `{"access_token":"tok","refresh_token":"r"}`)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"sync", "--output", archive, "--session-dir", sessionDir, "--gdrive-folder-id", "folder", "--gdrive-credentials", creds}
+	t.Setenv("GRAIN_SESSION_PASSPHRASE", "")
+	if code := runGDrive(args); code != 1 {
+		t.Errorf("without a passphrase: exit %d, want 1", code)
+	}
+
+	// With the session unlocked, nothing needs uploading and no OAuth prompt
+	// is needed; a plaintext session would find neither token nor state.
+	t.Setenv("GRAIN_SESSION_PASSPHRASE", "pw")
+	if code := runGDrive(args); code != 0 {
+		t.Errorf("exit %d, want 0", code)
+	}
+	if fileExists(sessionDir) {
+		t.Error("plaintext session dir created beside the container")
+	}
+	s, err = openEncryptedSession(sessionDir, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Discard()
+	got, err := loadDriveSyncState(filepath.Join(s.Dir(), "gdrive-sync.json"))
+	if err != nil || got.Files[rel] == nil || got.Files[rel].DriveFileID != "f1" {
+		t.Errorf("sync state after run = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// withGrainURLs applies base and api for the rest of the test.
+func withGrainURLs(t *testing.T, base, api string) {
+	t.Helper()
+	saved := grainSite
+	t.Cleanup(func() { grainSite = saved })
+	if err := setGrainURLs(base, api); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseGrainURL(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://eu.grain.com/":             "https://eu.grain.com",
+		" https://grain.example.com ":       "https://grain.example.com",
+		"https://api.eu.grain.com/_/public": "https://api.eu.grain.com/_/public",
+		"http://127.0.0.1:8080":             "http://127.0.0.1:8080",
+	} {
+		if got, err := parseGrainURL("grain-base-url", raw); err != nil || got != want {
+			t.Errorf("parseGrainURL(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"grain.com", "ftp://grain.com", "http://grain.example.com", "https://u:p@grain.com", "https://grain.com/?x=1", "https://grain.com/#top"} {
+		if _, err := parseGrainURL("grain-base-url", raw); err == nil || !strings.Contains(err.Error(), "--grain-base-url") {
+			t.Errorf("parseGrainURL(%q): err = %v, want flag error", raw, err)
+		}
+	}
+}
+
+func TestSetGrainURLs(t *testing.T) {
+	withGrainURLs(t, "", "")
+	if got := meetingURL("abc"); got != "https://grain.com/app/meetings/abc" {
+		t.Errorf("default meetingURL = %q", got)
+	}
+	if got := strings.Join(grainHosts(), ","); got != "grain.com" {
+		t.Errorf("default hosts = %q, want grain.com (api.grain.com is covered)", got)
+	}
+
+	withGrainURLs(t, "https://grain.example.eu/", "https://api.example.eu/_/public-api")
+	if got := meetingURL("abc"); got != "https://grain.example.eu/app/meetings/abc" {
+		t.Errorf("meetingURL = %q", got)
+	}
+	if got := strings.Join(grainHosts(), ","); got != "grain.example.eu,api.example.eu" {
+		t.Errorf("hosts = %q", got)
+	}
+	if hd := defaultHostDelays(); hd[0].Host != "api.example.eu" {
+		t.Errorf("default API bucket = %q", hd[0].Host)
+	}
+	for host, want := range map[string]bool{
+		"grain.example.eu":      true,
+		"WWW.grain.example.eu.": true,
+		"api.example.eu":        true,
+		"grain.com":             false,
+		"example.eu":            false,
+	} {
+		if got := isGrainDomain(host); got != want {
+			t.Errorf("isGrainDomain(%q) = %v, want %v", host, got, want)
+		}
+	}
+
+	// A bad value leaves the previous settings in place.
+	if err := setGrainURLs("https://ok.example", "nope"); err == nil {
+		t.Fatal("bad --grain-api-url: want error")
+	}
+	if got := grainPageURL("/app"); got != "https://grain.example.eu/app" {
+		t.Errorf("after error: %q", got)
+	}
+}
@@ -0,0 +1,689 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Grain Zip Import ────────────────────────────────────────────────────────
+//
+// `graindl import-grain-zip export.zip` ingests a workspace export
+// downloaded from Grain into the graindl layout, so a historical bulk
+// export and incremental graindl runs share one archive. Each recording in
+// the zip (a folder, or files sharing a name) becomes <date>/<id>.json
+// metadata, <id>.transcript.txt (WebVTT/SRT captions are converted to
+// "HH:MM:SS Speaker: text" segments), <id>.highlights.json and the video or
+// audio file, written through the same storage backends (and sync state)
+// as an export. Imported meetings are merged into _export-manifest.json.
+//
+// Metadata field names vary between export versions, so each field is
+// looked up under several names. The meeting ID is taken from the metadata
+// or a Grain URL or UUID in the file names; only then do later exports
+// recognize the meeting. Meetings already in the archive (under any date)
+// are skipped unless --overwrite is set. Paths inside the zip are never
+// used as output paths.
+
+// grainZipMaxText caps how much of a metadata or transcript entry is read.
+const grainZipMaxText = 64 << 20
+
+var (
+	grainZipUUID  = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	grainZipURLID = regexp.MustCompile(`/(?:app/meetings|recordings?|share/recording)/([A-Za-z0-9_-]+)`)
+	grainZipDate  = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2})\b`)
+)
+
+// zipRecording is the set of zip entries that make up one recording.
+type zipRecording struct {
+	name       string // folder or shared file stem, for titles and logs
+	meta       *zip.File
+	transcript *zip.File // plain text
+	captions   *zip.File // .vtt / .srt
+	video      *zip.File
+	audio      *zip.File
+}
+
+func runImportGrainZip(args []string) int {
+	dotenv := loadDotEnv(".env")
+	var cfg Config
+	fs := flag.NewFlagSet("import-grain-zip", flag.ContinueOnError)
+	fs.StringVar(&cfg.OutputDir, "output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to import into")
+	fs.BoolVar(&cfg.Overwrite, "overwrite", envBool(dotenv, "GRAIN_OVERWRITE"), "Re-import meetings already in the archive")
+	fs.BoolVar(&cfg.DryRun, "dry-run", envBool(dotenv, "GRAIN_DRY_RUN"), "List what would be imported without writing")
+	fs.StringVar(&cfg.Compress, "compress", envGet(dotenv, "GRAIN_COMPRESS"), "Store metadata, transcripts, and highlights compressed: zstd, gzip, none")
+	registerMirrorFlags(fs, &cfg, dotenv)
+	fs.StringVar(&cfg.GrainBaseURL, "grain-base-url", envGet(dotenv, "GRAIN_BASE_URL"), "Grain web app base URL for meeting links (default "+defaultGrainBaseURL+")")
+	fs.BoolVar(&cfg.Verbose, "verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	fs.StringVar(&cfg.LogFormat, "log-format", envGet(dotenv, "GRAIN_LOG_FORMAT"), "Log format: color (default), json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(cfg.LogFormat, cfg.Verbose)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: graindl import-grain-zip [flags] <export.zip>")
+		return 2
+	}
+	if err := finishMirrorConfig(&cfg, dotenv); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if err := setGrainURLs(cfg.GrainBaseURL, ""); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	var err error
+	if cfg.Compress, err = parseCompress(cfg.Compress); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+
+	zr, err := zip.OpenReader(fs.Arg(0))
+	if err != nil {
+		slog.Error("Cannot open zip", "path", fs.Arg(0), "error", err)
+		return 1
+	}
+	defer zr.Close()
+
+	storage, err := newStorage(&cfg)
+	if err != nil {
+		slog.Error("Storage init failed", "error", err)
+		return 1
+	}
+	defer storage.Close()
+
+	m, err := importGrainZip(&zr.Reader, &cfg, storage)
+	if err != nil {
+		slog.Error("Import failed", "error", err)
+		return 1
+	}
+	slog.Info(fmt.Sprintf("Imported %d meeting(s), skipped %d, %d error(s) → %s", m.OK, m.Skipped, m.Errors, absPath(cfg.OutputDir)))
+	if m.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// importGrainZip imports every recording in zr and returns this import's
+// results. Unless cfg.DryRun, they are merged into the archive manifest.
+func importGrainZip(zr *zip.Reader, cfg *Config, storage Storage) (*ExportManifest, error) {
+	recs := groupZipRecordings(zr.File)
+	if len(recs) == 0 {
+		return nil, errors.New("no recordings found in zip (expected metadata JSON, transcripts, or video files)")
+	}
+
+	existing := map[string]string{} // meeting ID → RelBase already in the archive
+	if entries, err := scanArchive(cfg.OutputDir); err == nil {
+		for _, a := range entries {
+			existing[a.Meta.ID] = a.RelBase
+		}
+	}
+
+	m := &ExportManifest{ExportedAt: time.Now().UTC().Format(time.RFC3339)}
+	for _, rec := range recs {
+		r := importZipRecording(rec, cfg, storage, existing)
+		r.ErrorMsg = redactSecrets(r.ErrorMsg)
+		switch r.Status {
+		case "ok":
+			m.OK++
+		case "skipped":
+			m.Skipped++
+		default:
+			m.Errors++
+		}
+		m.Meetings = append(m.Meetings, r)
+	}
+	m.Total = len(m.Meetings)
+	if cfg.DryRun {
+		return m, nil
+	}
+	for _, r := range m.Meetings {
+		if r.Status == "ok" {
+			recordChecksums(cfg.OutputDir, r)
+			rc := loadReceipt(storage.AbsPath(receiptRelPath(r)), r.ID)
+			rc.record(r.Checksums)
+			if err := rc.save(); err != nil {
+				slog.Warn("Receipt write failed", "id", r.ID, "error", err)
+			}
+		}
+	}
+	if err := mergeManifest(storage, m.Meetings); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+// groupZipRecordings splits zip entries into recordings. A folder holding
+// at most one metadata file, transcript and media file is one recording;
+// a folder with more is split by file stem.
+func groupZipRecordings(files []*zip.File) []*zipRecording {
+	byDir := map[string][]*zip.File{}
+	for _, f := range files {
+		name := f.Name
+		if f.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		if zipFileKind(name) == "" {
+			continue
+		}
+		byDir[path.Dir(name)] = append(byDir[path.Dir(name)], f)
+	}
+
+	var recs []*zipRecording
+	for dir, dirFiles := range byDir {
+		counts := map[string]int{}
+		for _, f := range dirFiles {
+			counts[zipFileKind(f.Name)]++
+		}
+		if dir != "." && counts["meta"] <= 1 && counts["transcript"] <= 1 && counts["captions"] <= 1 && counts["video"]+counts["audio"] <= 1 {
+			recs = append(recs, newZipRecording(path.Base(dir), dirFiles))
+			continue
+		}
+		byStem := map[string][]*zip.File{}
+		for _, f := range dirFiles {
+			stem := zipStem(path.Base(f.Name))
+			byStem[stem] = append(byStem[stem], f)
+		}
+		for stem, stemFiles := range byStem {
+			recs = append(recs, newZipRecording(stem, stemFiles))
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].name < recs[j].name })
+	return recs
+}
+
+func newZipRecording(name string, files []*zip.File) *zipRecording {
+	rec := &zipRecording{name: name}
+	for _, f := range files {
+		switch zipFileKind(f.Name) {
+		case "meta":
+			rec.meta = coalesceZip(rec.meta, f)
+		case "transcript":
+			rec.transcript = coalesceZip(rec.transcript, f)
+		case "captions":
+			rec.captions = coalesceZip(rec.captions, f)
+		case "video":
+			rec.video = coalesceZip(rec.video, f)
+		case "audio":
+			rec.audio = coalesceZip(rec.audio, f)
+		}
+	}
+	return rec
+}
+
+func coalesceZip(cur, f *zip.File) *zip.File {
+	if cur != nil {
+		return cur
+	}
+	return f
+}
+
+// zipFileKind classifies a zip entry by extension; "" means ignored.
+func zipFileKind(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return "meta"
+	case ".txt":
+		return "transcript"
+	case ".vtt", ".srt":
+		return "captions"
+	case ".mp4", ".webm":
+		return "video"
+	case ".m4a":
+		return "audio"
+	}
+	return ""
+}
+
+// zipStem strips the extension and a transcript/metadata/video suffix, so
+// "Call.transcript.txt", "Call - metadata.json" and "Call.mp4" group
+// together.
+func zipStem(base string) string {
+	stem := strings.TrimSuffix(base, path.Ext(base))
+	lower := strings.ToLower(stem)
+	for _, suffix := range []string{"transcript", "captions", "metadata", "recording", "video", "audio"} {
+		if i := strings.LastIndex(lower, suffix); i > 0 && i+len(suffix) == len(lower) {
+			stem = strings.TrimRight(stem[:i], " ._-")
+			break
+		}
+	}
+	return stem
+}
+
+// importZipRecording writes one recording into the archive.
+func importZipRecording(rec *zipRecording, cfg *Config, storage Storage, existing map[string]string) *ExportResult {
+	r := &ExportResult{TranscriptPaths: make(map[string]string)}
+	fields := map[string]any{}
+	if rec.meta != nil {
+		data, err := readZipFile(rec.meta)
+		if err == nil {
+			err = json.Unmarshal(data, &fields)
+		}
+		if err != nil {
+			slog.Warn("Unreadable metadata in zip, using file names", "file", rec.meta.Name, "error", err)
+			fields = map[string]any{}
+		}
+	}
+	transcript, err := zipTranscript(rec, fields)
+	if err != nil {
+		slog.Warn("Transcript unreadable", "recording", rec.name, "error", err)
+	}
+	meta := normalizeZipMetadata(fields, rec, transcript)
+	r.ID, r.Title = meta.ID, meta.Title
+	r.ScrapeQuality, r.ScrapeFallbacks = meta.ScrapeQuality, meta.fallbackFields()
+
+	relBase := filepath.Join(dateFromISO(meta.Date), sanitize(meta.ID))
+	if prev, ok := existing[meta.ID]; ok {
+		if !cfg.Overwrite {
+			slog.Debug("Already in archive, skipping", "id", meta.ID, "path", prev)
+			r.Status = "skipped"
+			r.DateDir = filepath.Dir(prev)
+			return r
+		}
+		relBase = prev // re-import in place rather than duplicating under another date
+	}
+	r.DateDir = filepath.Dir(relBase)
+	existing[meta.ID] = relBase
+
+	if cfg.DryRun {
+		slog.Info(fmt.Sprintf("Would import %s → %s", rec.name, relBase))
+		r.Status = "ok"
+		return r
+	}
+
+	fail := func(err error) *ExportResult {
+		r.Status = "error"
+		r.ErrorMsg = err.Error()
+		slog.Error("Import failed", "id", meta.ID, "error", err)
+		return r
+	}
+	if err := storage.EnsureDir(r.DateDir); err != nil {
+		return fail(err)
+	}
+	if err := storage.WriteJSON(relBase+".json", meta); err != nil {
+		return fail(fmt.Errorf("write metadata: %w", err))
+	}
+	r.MetadataPath = relBase + ".json"
+
+	if transcript != "" {
+		if err := storage.WriteFile(relBase+".transcript.txt", []byte(transcript)); err != nil {
+			return fail(fmt.Errorf("write transcript: %w", err))
+		}
+		r.TranscriptPaths["text"] = relBase + ".transcript.txt"
+	}
+	if hs := parseHighlights(meta.Highlights); len(hs) > 0 {
+		if err := storage.WriteJSON(relBase+".highlights.json", normalizeHighlights(hs)); err != nil {
+			return fail(fmt.Errorf("write highlights: %w", err))
+		}
+		r.HighlightsPath = relBase + ".highlights.json"
+	}
+	if rec.video != nil {
+		rel := relBase + strings.ToLower(path.Ext(rec.video.Name))
+		if err := copyZipFile(rec.video, storage, rel); err != nil {
+			return fail(fmt.Errorf("copy video: %w", err))
+		}
+		r.VideoPath, r.VideoMethod = rel, "grain-zip"
+	}
+	if rec.audio != nil {
+		rel := relBase + ".m4a"
+		if err := copyZipFile(rec.audio, storage, rel); err != nil {
+			return fail(fmt.Errorf("copy audio: %w", err))
+		}
+		r.AudioPath, r.AudioMethod = rel, "grain-zip"
+	}
+
+	noteCompressed(storage, r)
+	if br, ok := storage.(backendReporter); ok {
+		r.Backends = br.BackendStatus(collectResultPaths(r))
+	}
+	r.Status = "ok"
+	slog.Info("Imported", "id", meta.ID, "title", meta.Title, "path", relBase)
+	return r
+}
+
+// normalizeZipMetadata maps a Grain export record onto Metadata. Fields
+// from the export's JSON count as API provenance; titles and dates taken
+// from file names or times are fallbacks.
+func normalizeZipMetadata(f map[string]any, rec *zipRecording, transcript string) *Metadata {
+	grainURL := zipString(f, "url", "grain_url", "recording_url", "link")
+	title := zipString(f, "title", "name", "recording_title")
+	date, dateFromMeta := zipDate(f, rec)
+	meta := &Metadata{
+		ID:      zipRecordingID(f, rec, grainURL),
+		Title:   coalesce(title, rec.name),
+		Date:    date,
+		Summary: zipString(f, "summary", "ai_summary"),
+		Links: Links{
+			Share: zipString(f, "share_url", "public_url", "shared_url"),
+			Video: zipString(f, "video_url", "download_url"),
+		},
+	}
+	meta.Links.Grain = coalesce(grainURL, meetingURL(meta.ID))
+
+	switch {
+	case f["duration_seconds"] != nil:
+		meta.DurationSeconds = f["duration_seconds"]
+	case f["duration_ms"] != nil:
+		meta.DurationSeconds = toFloat64(f["duration_ms"]) / 1000
+	case f["duration"] != nil:
+		meta.DurationSeconds = f["duration"]
+	}
+	if people := zipNames(firstNonNil(f["participants"], f["attendees"], f["speakers"])); len(people) > 0 {
+		meta.Participants = people
+	}
+	if tags := zipNames(f["tags"]); len(tags) > 0 {
+		meta.Tags = tags
+	}
+	meta.AINotes = firstNonNil(f["ai_notes"], f["notes"])
+	meta.ActionItems = zipNames(f["action_items"])
+	meta.Highlights = firstNonNil(f["highlights"], f["clips"])
+
+	meta.assess(sourceAPI, transcript)
+	if title == "" {
+		meta.setProvenance("title", sourceFallback, confidenceUnparsed)
+	}
+	if date != "" && !dateFromMeta {
+		meta.setProvenance("date", sourceFallback, confidenceUnparsed)
+	}
+	return meta
+}
+
+// zipRecordingID finds the Grain meeting ID: metadata, then a Grain URL,
+// then a UUID in the recording's file names. Without one, a stable ID is
+// derived from the names, which later exports will not recognize.
+func zipRecordingID(f map[string]any, rec *zipRecording, grainURL string) string {
+	candidates := []string{zipString(f, "id", "recording_id", "meeting_id", "uuid")}
+	if m := grainZipURLID.FindStringSubmatch(grainURL); m != nil {
+		candidates = append(candidates, m[1])
+	}
+	var names []string
+	for _, zf := range []*zip.File{rec.meta, rec.transcript, rec.captions, rec.video, rec.audio} {
+		if zf != nil {
+			names = append(names, zf.Name)
+		}
+	}
+	candidates = append(candidates, grainZipUUID.FindString(strings.Join(names, " ")))
+	for _, id := range candidates {
+		if id != "" && validID.MatchString(id) {
+			return id
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(names, "\n")))
+	id := "zip-" + hex.EncodeToString(sum[:6])
+	slog.Warn("No Grain ID for recording; later exports will not recognize it", "recording", rec.name, "id", id)
+	return id
+}
+
+// zipDate returns the recording start as RFC 3339, from metadata (string
+// or Unix seconds/milliseconds), a date in the names, or the zip entry time,
+// and whether it came from the metadata.
+func zipDate(f map[string]any, rec *zipRecording) (string, bool) {
+	for _, k := range []string{"start_datetime", "started_at", "start_time", "recorded_at", "created_at", "date"} {
+		switch v := f[k].(type) {
+		case string:
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+				if t, err := time.Parse(layout, v); err == nil {
+					return t.UTC().Format(time.RFC3339), true
+				}
+			}
+		case float64:
+			if v > 1e12 {
+				v /= 1000 // milliseconds
+			}
+			if v > 0 {
+				return time.Unix(int64(v), 0).UTC().Format(time.RFC3339), true
+			}
+		}
+	}
+	if m := grainZipDate.FindStringSubmatch(rec.name); m != nil {
+		return m[1], false
+	}
+	for _, zf := range []*zip.File{rec.meta, rec.video, rec.audio, rec.transcript, rec.captions} {
+		if zf != nil && !zf.Modified.IsZero() {
+			return zf.Modified.UTC().Format(time.RFC3339), false
+		}
+	}
+	return "", false
+}
+
+// zipString returns the first non-empty string field among keys.
+func zipString(f map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := f[k].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+// zipNames flattens a list of strings or objects (name/email/title/text)
+// into strings.
+func zipNames(v any) []string {
+	list, ok := v.([]any)
+	if !ok {
+		return flattenStringSlice(v)
+	}
+	var out []string
+	for _, item := range list {
+		switch x := item.(type) {
+		case string:
+			if x != "" {
+				out = append(out, x)
+			}
+		case map[string]any:
+			if s := zipString(x, "name", "display_name", "email", "title", "text"); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// zipTranscript returns the recording's transcript in graindl's format:
+// the text file as-is, converted captions, or transcript segments from the
+// metadata.
+func zipTranscript(rec *zipRecording, f map[string]any) (string, error) {
+	if rec.transcript != nil {
+		data, err := readZipFile(rec.transcript)
+		return strings.TrimSpace(string(data)), err
+	}
+	if rec.captions != nil {
+		data, err := readZipFile(rec.captions)
+		return captionsToTranscript(string(data)), err
+	}
+	switch t := f["transcript"].(type) {
+	case string:
+		return strings.TrimSpace(t), nil
+	case []any:
+		var segs []string
+		for _, item := range t {
+			seg, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			text := zipString(seg, "text", "content")
+			if text == "" {
+				continue
+			}
+			if sp := zipString(seg, "speaker", "speaker_name", "name"); sp != "" {
+				text = sp + ": " + text
+			}
+			if v := firstNonNil(seg["start"], seg["start_seconds"], seg["timestamp"]); v != nil {
+				text = formatTimestamp(toFloat64(v)) + " " + text
+			}
+			segs = append(segs, text)
+		}
+		return strings.Join(segs, "\n\n"), nil
+	}
+	return "", nil
+}
+
+var captionTag = regexp.MustCompile(`<[^>]*>`)
+var captionVoice = regexp.MustCompile(`^<v(?:\.[^ >]*)?\s+([^>]+)>`)
+
+// captionsToTranscript converts WebVTT or SRT cues to "HH:MM:SS Speaker:
+// text" segments separated by blank lines.
+func captionsToTranscript(text string) string {
+	var segs []string
+	for _, block := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		timing := -1
+		for i, l := range lines {
+			if strings.Contains(l, "-->") {
+				timing = i
+				break
+			}
+		}
+		if timing < 0 {
+			continue // header, NOTE, STYLE
+		}
+		start, _, _ := strings.Cut(lines[timing], "-->")
+		cue := strings.Join(lines[timing+1:], " ")
+		speaker := ""
+		if m := captionVoice.FindStringSubmatch(cue); m != nil {
+			speaker = strings.TrimSpace(m[1])
+		}
+		cue = strings.Join(strings.Fields(captionTag.ReplaceAllString(cue, "")), " ")
+		if cue == "" {
+			continue
+		}
+		if speaker != "" {
+			cue = speaker + ": " + cue
+		}
+		segs = append(segs, formatTimestamp(captionSeconds(strings.TrimSpace(start)))+" "+cue)
+	}
+	return strings.Join(segs, "\n\n")
+}
+
+// captionSeconds parses "HH:MM:SS.mmm", "MM:SS.mmm" or SRT's "HH:MM:SS,mmm".
+func captionSeconds(ts string) float64 {
+	ts, _, _ = strings.Cut(strings.ReplaceAll(ts, ",", "."), " ")
+	secs := 0.0
+	for _, p := range strings.Split(ts, ":") {
+		n, _ := strconv.ParseFloat(p, 64)
+		secs = secs*60 + n
+	}
+	return secs
+}
+
+// readZipFile reads a text entry, refusing ones over grainZipMaxText.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, grainZipMaxText+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > grainZipMaxText {
+		return nil, fmt.Errorf("%s is larger than %d MB", f.Name, grainZipMaxText>>20)
+	}
+	return data, nil
+}
+
+// copyZipFile streams a media entry to relPath (via a .part file) and
+// syncs it to the storage mirrors.
+func copyZipFile(f *zip.File, storage Storage, relPath string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dst := storage.AbsPath(relPath)
+	tmp := dst + ".part"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	storage.SyncExternalFile(relPath)
+	return nil
+}
+
+// mergeManifest adds results to _export-manifest.json, replacing earlier
+// entries for the same meetings, and recounts the totals.
+func mergeManifest(storage Storage, results []*ExportResult) error {
+	const name = "_export-manifest.json"
+	var m ExportManifest
+	data, err := os.ReadFile(storage.AbsPath(name))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &m); err != nil {
+			return fmt.Errorf("parse manifest: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("read manifest: %w", err)
+	}
+
+	index := make(map[string]int, len(m.Meetings))
+	for i, r := range m.Meetings {
+		index[r.ID] = i
+	}
+	for _, r := range results {
+		if r.Status == "skipped" {
+			continue // the existing entry (if any) still describes the files
+		}
+		if i, ok := index[r.ID]; ok {
+			m.Meetings[i] = r
+			continue
+		}
+		index[r.ID] = len(m.Meetings)
+		m.Meetings = append(m.Meetings, r)
+	}
+
+	m.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+	m.Total, m.OK, m.Skipped, m.Errors, m.HLSPending, m.AuthBlocked, m.LowQuality = len(m.Meetings), 0, 0, 0, 0, 0, 0
+	m.DurationMismatch = 0
+	for _, r := range m.Meetings {
+		if r.ScrapeQuality != nil && *r.ScrapeQuality < lowQualityThreshold {
+			m.LowQuality++
+		}
+		if r.VideoStatus == videoDurationMismatch {
+			m.DurationMismatch++
+		}
+		switch r.Status {
+		case "ok":
+			m.OK++
+		case "skipped":
+			m.Skipped++
+		case "hls_pending":
+			m.HLSPending++
+			m.OK++
+		case statusAuthBlocked:
+			m.AuthBlocked++
+		default:
+			m.Errors++
+		}
+	}
+	if err := storage.WriteJSON(name, &m); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// grainZip builds an in-memory zip from name → content.
+func grainZip(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+func TestCaptionsToTranscript(t *testing.T) {
+	vtt := "WEBVTT\n\nNOTE exported by Grain\n\n1\n00:00:05.120 --> 00:00:08.000\n<v Alice Smith>Hello <b>there</b></v>\n\n01:02.500 --> 01:04.000\nNo speaker\nsecond line\n"
+	want := "00:00:05 Alice Smith: Hello there\n\n00:01:02 No speaker second line"
+	if got := captionsToTranscript(vtt); got != want {
+		t.Errorf("vtt =\n%q\nwant\n%q", got, want)
+	}
+	srt := "1\r\n01:00:01,900 --> 01:00:03,000\r\nBob: Hi\r\n"
+	if got := captionsToTranscript(srt); got != "01:00:01 Bob: Hi" {
+		t.Errorf("srt = %q", got)
+	}
+}
+
+func TestGroupZipRecordings(t *testing.T) {
+	zr := grainZip(t, map[string]string{
+		"Export/Weekly Sync/metadata.json":       "{}",
+		"Export/Weekly Sync/transcript.txt":      "x",
+		"Export/Weekly Sync/recording.mp4":       "x",
+		"Export/flat/Call A.json":                "{}",
+		"Export/flat/Call A - transcript.vtt":    "x",
+		"Export/flat/Call B.json":                "{}",
+		"Export/flat/Call B.m4a":                 "x",
+		"__MACOSX/Export/Weekly Sync/._metadata": "x",
+		"Export/Weekly Sync/.DS_Store":           "x",
+		"Export/Weekly Sync/thumbnail.png":       "x",
+	})
+	recs := groupZipRecordings(zr.File)
+	if len(recs) != 3 {
+		t.Fatalf("got %d recordings, want 3", len(recs))
+	}
+	a, b, sync := recs[0], recs[1], recs[2]
+	if a.name != "Call A" || a.meta == nil || a.captions == nil || a.video != nil {
+		t.Errorf("Call A = %+v", a)
+	}
+	if b.name != "Call B" || b.meta == nil || b.audio == nil {
+		t.Errorf("Call B = %+v", b)
+	}
+	if sync.name != "Weekly Sync" || sync.meta == nil || sync.transcript == nil || sync.video == nil {
+		t.Errorf("Weekly Sync = %+v", sync)
+	}
+}
+
+func TestNormalizeZipMetadata(t *testing.T) {
+	rec := &zipRecording{name: "2025-03-04 Planning"}
+	meta := normalizeZipMetadata(map[string]any{
+		"name":         "Planning",
+		"url":          "https://grain.com/share/recording/rec-42/abc",
+		"created_at":   float64(1741082400000), // ms
+		"duration_ms":  float64(90000),
+		"participants": []any{map[string]any{"name": "Alice"}, map[string]any{"email": "bob@example.com"}, "Carol"},
+	}, rec, "")
+	if meta.ID != "rec-42" || meta.Title != "Planning" {
+		t.Errorf("id/title = %q/%q", meta.ID, meta.Title)
+	}
+	if meta.Date != "2025-03-04T10:00:00Z" || toFloat64(meta.DurationSeconds) != 90 {
+		t.Errorf("date/duration = %q/%v", meta.Date, meta.DurationSeconds)
+	}
+	if got := strings.Join(flattenStringSlice(meta.Participants), ","); got != "Alice,bob@example.com,Carol" {
+		t.Errorf("participants = %s", got)
+	}
+
+	// No ID anywhere: derived from the names, and stable.
+	first := normalizeZipMetadata(map[string]any{}, rec, "")
+	if !strings.HasPrefix(first.ID, "zip-") || normalizeZipMetadata(map[string]any{}, rec, "").ID != first.ID {
+		t.Errorf("fallback ID = %q", first.ID)
+	}
+	if first.Title != rec.name || first.Date != "2025-03-04" {
+		t.Errorf("fallback title/date = %q/%q", first.Title, first.Date)
+	}
+	if meta.Provenance["title"].Source != sourceAPI || first.Provenance["title"].Source != sourceFallback || first.Provenance["date"].Source != sourceFallback {
+		t.Errorf("provenance = %+v / %+v", meta.Provenance, first.Provenance)
+	}
+}
+
+func TestImportGrainZip(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir}
+	storage, err := newStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+
+	// A meeting exported earlier by graindl; the import must not duplicate it.
+	if err := os.MkdirAll(filepath.Join(dir, "2025-01-01"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(filepath.Join(dir, "2025-01-01", "old1.json"), &Metadata{ID: "old1", Title: "Old"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSON(filepath.Join(dir, "_export-manifest.json"), &ExportManifest{Total: 1, OK: 1, Meetings: []*ExportResult{{ID: "old1", Status: "ok"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	zr := grainZip(t, map[string]string{
+		"Weekly Sync/metadata.json": `{"id":"new1","title":"Weekly Sync","start_datetime":"2025-06-01T10:00:00Z",` +
+			`"highlights":[{"text":"Ship it","timestamp":12}]}`,
+		"Weekly Sync/captions.vtt": "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<v Alice>Hi\n",
+		"Weekly Sync/video.mp4":    "video-bytes",
+		"Again/metadata.json":      `{"id":"old1","title":"Old"}`,
+	})
+	m, err := importGrainZip(zr, cfg, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.OK != 1 || m.Skipped != 1 || m.Errors != 0 {
+		t.Fatalf("ok/skipped/errors = %d/%d/%d", m.OK, m.Skipped, m.Errors)
+	}
+
+	base := filepath.Join(dir, "2025-06-01", "new1")
+	if meta, err := readArchiveMetadata(base + ".json"); err != nil || meta.Title != "Weekly Sync" {
+		t.Errorf("metadata = %+v, %v", meta, err)
+	}
+	if data, _ := os.ReadFile(base + ".transcript.txt"); string(data) != "00:00:01 Alice: Hi" {
+		t.Errorf("transcript = %q", data)
+	}
+	if !fileExists(base + ".highlights.json") {
+		t.Error("highlights not written")
+	}
+	info, err := os.Stat(base + ".mp4")
+	if err != nil || info.Mode().Perm() != 0o600 || fileExists(base+".mp4.part") {
+		t.Errorf("video = %v, %v", info, err)
+	}
+
+	var manifest ExportManifest
+	data, _ := os.ReadFile(filepath.Join(dir, "_export-manifest.json"))
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Total != 2 || manifest.OK != 2 || manifest.Meetings[1].VideoMethod != "grain-zip" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	// Importing again skips everything and leaves the manifest alone.
+	if m, err := importGrainZip(zr, cfg, storage); err != nil || m.Skipped != 2 {
+		t.Errorf("second import: %+v, %v", m, err)
+	}
+}
+
+func TestImportGrainZipDryRun(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, DryRun: true}
+	storage, err := newStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storage.Close()
+	zr := grainZip(t, map[string]string{"a/metadata.json": `{"id":"x1","date":"2025-06-01"}`})
+	if m, err := importGrainZip(zr, cfg, storage); err != nil || m.OK != 1 {
+		t.Fatalf("dry run: %+v, %v", m, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("dry run wrote %d entries", len(entries))
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ── Highlight Pages ─────────────────────────────────────────────────────────
+//
+// --highlight-pages collects highlights by tag into highlights/<tag>.md at
+// the archive root: one page per tag, listing every highlight tagged with it
+// (or from a meeting tagged with it), grouped by meeting, newest first. Each
+// highlight links to its clip and shows where in the meeting it was said;
+// each meeting heading links to its note (or to Grain when there is none).
+// The pages are rebuilt from the archive after every run, but only pages
+// whose content changed are written, and pages of tags no longer in use
+// are removed.
+
+// highlightPagesDir holds the per-tag pages. It has no metadata files, so
+// archive scans pass over it.
+const highlightPagesDir = "highlights"
+
+// highlightPageMarker identifies pages graindl generated; only those are
+// ever replaced or removed.
+const highlightPageMarker = "_Highlights tagged"
+
+// highlightPage is the content of one tag's page.
+type highlightPage struct {
+	Tag     string
+	Entries []*ArchiveEntry
+	Clips   map[*ArchiveEntry][]HighlightClip
+}
+
+// highlightTags returns the tags a clip is filed under: its own, then its
+// meeting's, without duplicates (case-insensitive).
+func highlightTags(meta *Metadata, c HighlightClip) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, t := range append(flattenStringSlice(c.Tags), flattenStringSlice(meta.Tags)...) {
+		t = strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(t), "#")), " ")
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// highlightPageSlug returns the file name (without .md) of tag's page.
+func highlightPageSlug(tag string) string {
+	return coalesce(slugify(tag, slugStyleASCII), slugify(tag, slugStyleUnicode))
+}
+
+// buildHighlightPages files every highlight in entries under its tags,
+// keyed by page slug. Tags that differ only in case or punctuation share a
+// page, named after the spelling in the newest meeting.
+func buildHighlightPages(entries []*ArchiveEntry, outputDir string) map[string]*highlightPage {
+	sorted := append([]*ArchiveEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date().After(sorted[j].Date()) })
+
+	pages := map[string]*highlightPage{}
+	for _, a := range sorted {
+		for _, c := range a.Highlights(outputDir) {
+			if strings.TrimSpace(c.Text) == "" && strings.TrimSpace(c.Title) == "" {
+				continue
+			}
+			for _, tag := range highlightTags(a.Meta, c) {
+				slug := highlightPageSlug(tag)
+				if slug == "" {
+					continue
+				}
+				p := pages[slug]
+				if p == nil {
+					p = &highlightPage{Tag: tag, Clips: map[*ArchiveEntry][]HighlightClip{}}
+					pages[slug] = p
+				}
+				if p.Clips[a] == nil {
+					p.Entries = append(p.Entries, a)
+				}
+				p.Clips[a] = append(p.Clips[a], c)
+			}
+		}
+	}
+	return pages
+}
+
+// renderHighlightPage renders p. notes maps entries to their note paths
+// relative to the output dir.
+func renderHighlightPage(p *highlightPage, notes map[*ArchiveEntry]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Highlights: %s\n\n", p.Tag)
+	fmt.Fprintf(&b, "%s “%s” in exported Grain meetings, rebuilt by graindl after every run._\n", highlightPageMarker, p.Tag)
+	for _, a := range p.Entries {
+		title := escapeMarkdownLinkText(coalesce(a.Meta.Title, a.Meta.ID))
+		if note := notes[a]; note != "" {
+			title = "[" + title + "](../" + markdownLinkPath(note) + ")"
+		} else if a.Meta.Links.Grain != "" {
+			title = "[" + title + "](" + a.Meta.Links.Grain + ")"
+		}
+		fmt.Fprintf(&b, "\n## %s · %s\n\n", a.Date().Format("2006-01-02"), title)
+		for _, c := range p.Clips[a] {
+			b.WriteString("- ")
+			if ts := formatTimestamp(c.StartSec); c.URL != "" {
+				b.WriteString("[" + ts + "](" + c.URL + ")")
+			} else {
+				b.WriteString(ts)
+			}
+			if c.Speaker != "" {
+				b.WriteString(" **" + c.Speaker + ":**")
+			}
+			text := strings.Join(strings.Fields(coalesce(strings.TrimSpace(c.Text), c.Title)), " ")
+			b.WriteString(" " + text + "\n")
+		}
+	}
+	return b.String()
+}
+
+// writeHighlightPages rebuilds highlights/<tag>.md from the archive.
+func (e *Exporter) writeHighlightPages() {
+	if !e.cfg.HighlightPages {
+		return
+	}
+	entries, err := scanArchive(e.cfg.dataDir())
+	if err != nil {
+		slog.Warn("Highlight pages skipped", "error", err)
+		return
+	}
+	notes := make(map[*ArchiveEntry]string, len(entries))
+	for _, a := range entries {
+		if p := e.noteRelPath(a.Meta, a.RelBase); e.storage.FileExists(p) {
+			notes[a] = p
+		}
+	}
+
+	pages := buildHighlightPages(entries, e.cfg.dataDir())
+	written := 0
+	for slug, p := range pages {
+		relPath := filepath.Join(highlightPagesDir, slug+".md")
+		md := []byte(renderHighlightPage(p, notes))
+		if old, err := os.ReadFile(e.storage.AbsPath(relPath)); err == nil {
+			if bytes.Equal(old, md) {
+				continue
+			}
+			if !bytes.Contains(old, []byte("\n"+highlightPageMarker)) {
+				slog.Warn("Highlight page skipped: file not written by graindl", "path", relPath)
+				continue
+			}
+		}
+		if err := e.storage.WriteFile(relPath, md); err != nil {
+			slog.Warn("Highlight page write failed", "path", relPath, "error", err)
+			continue
+		}
+		written++
+	}
+	removed := removeStaleHighlightPages(e.storage.AbsPath(highlightPagesDir), pages)
+	slog.Debug("Highlight pages updated", "tags", len(pages), "written", written, "removed", removed)
+}
+
+// removeStaleHighlightPages deletes generated pages in dir whose tag is no
+// longer in pages. Files graindl didn't write are left alone.
+func removeStaleHighlightPages(dir string, pages map[string]*highlightPage) int {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	removed := 0
+	for _, f := range files {
+		slug, ok := strings.CutSuffix(f.Name(), ".md")
+		if f.IsDir() || !ok || pages[slug] != nil {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := os.ReadFile(path)
+		if err != nil || !bytes.Contains(data, []byte("\n"+highlightPageMarker)) || sealed(path) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			slog.Warn("Stale highlight page not removed", "path", path, "error", err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
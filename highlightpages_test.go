@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHighlightPages(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-03-04", &Metadata{ID: "old", Title: "Acme [renewal]", Tags: []any{"customer"}})
+	writeArchiveMeta(t, dir, "2025-03-05", &Metadata{ID: "new", Title: "Beta sync", Links: Links{Grain: "https://grain.com/share/recording/new"}})
+	clips, _ := json.Marshal([]HighlightClip{
+		{Text: "It's too\nexpensive.", Speaker: "Dana", StartSec: 75, URL: "https://grain.com/share/h1", Tags: []any{"Pricing Objections"}},
+		{Title: "Needs SSO", StartSec: 130},
+	})
+	os.WriteFile(filepath.Join(dir, "2025-03-04", "old.highlights.json"), clips, 0o600)
+	clips, _ = json.Marshal([]HighlightClip{{Text: "Budget is frozen", StartSec: 3725, Tags: []any{"#pricing-objections"}}})
+	os.WriteFile(filepath.Join(dir, "2025-03-05", "new.highlights.json"), clips, 0o600)
+	os.WriteFile(filepath.Join(dir, "2025-03-04", "old.md"), []byte("# Acme\n"), 0o600)
+
+	// A page for a tag nobody uses any more, and a hand-written one.
+	os.MkdirAll(filepath.Join(dir, highlightPagesDir), 0o755)
+	os.WriteFile(filepath.Join(dir, highlightPagesDir, "gone.md"), []byte("# Highlights: gone\n\n"+highlightPageMarker+" “gone”._\n"), 0o600)
+	os.WriteFile(filepath.Join(dir, highlightPagesDir, "mine.md"), []byte("# My notes\n"), 0o600)
+
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, HighlightPages: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	e.writeHighlightPages()
+
+	data, err := os.ReadFile(filepath.Join(dir, highlightPagesDir, "pricing-objections.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"# Highlights: pricing-objections\n", // the newest meeting's spelling
+		"## 2025-03-05 · [Beta sync](https://grain.com/share/recording/new)\n\n- 01:02:05 Budget is frozen\n",
+		"## 2025-03-04 · [Acme \\[renewal\\]](../2025-03-04/old.md)\n\n- [00:01:15](https://grain.com/share/h1) **Dana:** It's too expensive.\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("page missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "2025-03-05") > strings.Index(md, "2025-03-04") {
+		t.Error("meetings should be newest first")
+	}
+	customer, _ := os.ReadFile(filepath.Join(dir, highlightPagesDir, "customer.md"))
+	if !strings.Contains(string(customer), "- [00:01:15]") || !strings.Contains(string(customer), "- 00:02:10 Needs SSO\n") {
+		t.Errorf("meeting tag page:\n%s", customer)
+	}
+	if fileExists(filepath.Join(dir, highlightPagesDir, "gone.md")) {
+		t.Error("stale page kept")
+	}
+	if !fileExists(filepath.Join(dir, highlightPagesDir, "mine.md")) {
+		t.Error("hand-written page removed")
+	}
+
+	// Unchanged pages are not rewritten.
+	page := filepath.Join(dir, highlightPagesDir, "customer.md")
+	info, _ := os.Stat(page)
+	os.Chtimes(page, info.ModTime().Add(-time.Hour), info.ModTime().Add(-time.Hour))
+	e.writeHighlightPages()
+	if after, _ := os.Stat(page); !after.ModTime().Equal(info.ModTime().Add(-time.Hour)) {
+		t.Error("unchanged page rewritten")
+	}
+
+	entries, _ := scanArchive(dir)
+	if len(entries) != 2 {
+		t.Errorf("highlights dir scanned as meetings: %d entries", len(entries))
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ── Highlight Previews ──────────────────────────────────────────────────────
+//
+// --highlight-previews gif|webp cuts a short looping animation of each
+// highlight from the downloaded video with ffmpeg, saved next to it as
+// <id>.highlight-<n>.<gif|webp>. Obsidian and Notion notes embed them under
+// the highlights, so a clip can be skimmed without opening the full video;
+// the metadata JSON lists them as "highlight_previews". Previews are cut
+// when the video is downloaded in the export run, so they need ffmpeg and
+// don't apply with --skip-video, --audio-only, or --defer-videos. A
+// failed preview is skipped; it never fails the export.
+
+// Preview formats accepted by --highlight-previews.
+const (
+	highlightPreviewGIF  = "gif"
+	highlightPreviewWebP = "webp"
+)
+
+const (
+	highlightPreviewMaxSec     = 6.0 // longest preview; longer clips show their opening
+	highlightPreviewDefaultSec = 4.0 // preview length for clips without an end time
+	highlightPreviewMaxClips   = 20  // previews per meeting
+	highlightPreviewWidth      = 480 // pixels; height keeps the aspect ratio
+	highlightPreviewFPS        = 10
+)
+
+// HighlightPreview is an animated preview of one highlight.
+type HighlightPreview struct {
+	ID       string  `json:"id"`
+	Title    string  `json:"title,omitempty"`
+	StartSec float64 `json:"start_sec"`
+	Path     string  `json:"path"` // relative to the output dir, beside the video
+}
+
+// previewFunc cuts length seconds from start of the video at in into the
+// animation at out.
+type previewFunc func(ctx context.Context, in, out string, start, length float64) error
+
+// parseHighlightPreviews validates a --highlight-previews value, returning
+// it lowercased ("" when off).
+func parseHighlightPreviews(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", highlightPreviewGIF, highlightPreviewWebP:
+		return s, nil
+	}
+	return "", fmt.Errorf("invalid --highlight-previews %q (must be gif or webp)", s)
+}
+
+// ffmpegPreviewer returns an ffmpeg-backed previewFunc for format, or nil
+// when ffmpeg is not on PATH.
+func ffmpegPreviewer(format string, verbose bool) previewFunc {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil
+	}
+	return func(ctx context.Context, in, out string, start, length float64) error {
+		return runFFmpeg(ctx, verbose, previewArgs(format, in, out, start, length)...)
+	}
+}
+
+// previewArgs builds the ffmpeg arguments for a looping preview. GIFs get
+// a palette generated from the clip itself; a fixed 256-colour palette
+// bands badly on video.
+func previewArgs(format, in, out string, start, length float64) []string {
+	scale := fmt.Sprintf("fps=%d,scale=%d:-2:flags=lanczos", highlightPreviewFPS, highlightPreviewWidth)
+	args := []string{
+		"-ss", strconv.FormatFloat(start, 'f', 2, 64),
+		"-t", strconv.FormatFloat(length, 'f', 2, 64),
+		"-i", in, "-an",
+	}
+	if format == highlightPreviewWebP {
+		args = append(args, "-vf", scale, "-c:v", "libwebp", "-quality", "70", "-loop", "0", "-f", "webp")
+	} else {
+		args = append(args, "-vf", scale+",split[a][b];[a]palettegen=stats_mode=diff[p];[b][p]paletteuse=dither=bayer",
+			"-loop", "0", "-f", "gif")
+	}
+	return append(args, "-y", out)
+}
+
+// writeHighlightPreviews cuts previews of meta's highlights from the video
+// in r and records them in meta and r. It reports whether any were made.
+func (e *Exporter) writeHighlightPreviews(ctx context.Context, meta *Metadata, relBase string, r *ExportResult) bool {
+	if e.preview == nil || r.VideoPath == "" {
+		return false
+	}
+	switch r.VideoMethod {
+	case "button", "direct", "hls-native":
+	default:
+		return false
+	}
+	in := e.storage.AbsPath(r.VideoPath)
+	clips := normalizeHighlights(parseHighlights(meta.Highlights))
+	for i, c := range clips {
+		if i == highlightPreviewMaxClips {
+			slog.Info("Highlight previews capped", "id", meta.ID, "made", len(meta.HighlightPreviews), "highlights", len(clips))
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		length := c.DurationSec
+		if length <= 0 {
+			length = highlightPreviewDefaultSec
+		}
+		length = min(length, highlightPreviewMaxSec)
+		if c.StartSec < 0 || r.VideoDuration > 0 && c.StartSec >= r.VideoDuration {
+			continue
+		}
+
+		rel := fmt.Sprintf("%s.highlight-%d.%s", relBase, i+1, e.cfg.HighlightPreviews)
+		out := e.storage.AbsPath(rel)
+		if err := e.preview(ctx, in, out, c.StartSec, length); err != nil {
+			slog.Warn("Highlight preview failed", "id", meta.ID, "highlight", c.ID, "error", err)
+			_ = os.Remove(out)
+			continue
+		}
+		if fi, err := os.Stat(out); err != nil || fi.Size() == 0 {
+			_ = os.Remove(out)
+			continue
+		}
+		_ = fixPerms(out)
+		e.storage.SyncExternalFile(rel)
+		meta.HighlightPreviews = append(meta.HighlightPreviews, HighlightPreview{ID: c.ID, Title: coalesce(c.Title, truncateRunes(c.Text, 60)), StartSec: c.StartSec, Path: rel})
+		r.PreviewPaths = append(r.PreviewPaths, rel)
+	}
+	if len(r.PreviewPaths) > 0 {
+		slog.Debug("Highlight previews written", "id", meta.ID, "count", len(r.PreviewPaths))
+	}
+	return len(r.PreviewPaths) > 0
+}
+
+// writePreviewEmbeds adds the "### Previews" images to a note's highlights
+// section. Paths are relative to the note, which sits beside the video.
+func writePreviewEmbeds(b noteWriter, meta *Metadata) {
+	if len(meta.HighlightPreviews) == 0 {
+		return
+	}
+	b.WriteString("\n### Previews\n\n")
+	for _, p := range meta.HighlightPreviews {
+		alt := strings.NewReplacer("[", "(", "]", ")", "\n", " ").Replace(coalesce(p.Title, p.ID))
+		fmt.Fprintf(b, "![%s — %s](%s)\n", alt, formatTimestamp(p.StartSec), url.PathEscape(filepath.Base(p.Path)))
+	}
+}
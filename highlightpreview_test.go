@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteHighlightPreviews(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveFile(t, dir, "2025-01-15/m1.mp4", "video", 0)
+	type cut struct{ start, length float64 }
+	var cuts []cut
+	e := &Exporter{
+		cfg:     &Config{OutputDir: dir, HighlightPreviews: highlightPreviewGIF},
+		storage: NewLocalStorage(dir),
+		preview: func(_ context.Context, in, out string, start, length float64) error {
+			if in != filepath.Join(dir, "2025-01-15", "m1.mp4") {
+				t.Errorf("cut from %s", in)
+			}
+			cuts = append(cuts, cut{start, length})
+			if start == 300 {
+				return errors.New("ffmpeg failed")
+			}
+			return os.WriteFile(out, []byte("GIF89a"), 0o644)
+		},
+	}
+	meta := &Metadata{ID: "m1", Title: "Acme", Highlights: []any{
+		map[string]any{"id": "h1", "title": "Pricing [draft]", "start_time": 12.0, "end_time": 15.0},
+		map[string]any{"id": "h2", "text": "A long point about the roadmap", "start": 60.0, "duration": 45.0},
+		map[string]any{"id": "h3", "text": "Fails", "start": 300.0},
+		map[string]any{"id": "h4", "text": "After the end", "start": 4000.0},
+	}}
+	r := &ExportResult{VideoPath: "2025-01-15/m1.mp4", VideoMethod: "button", VideoDuration: 3600}
+	if !e.writeHighlightPreviews(context.Background(), meta, "2025-01-15/m1", r) {
+		t.Fatal("no previews made")
+	}
+	if want := []cut{{12, 3}, {60, highlightPreviewMaxSec}, {300, highlightPreviewDefaultSec}}; !slices.Equal(cuts, want) {
+		t.Errorf("cuts = %v, want %v", cuts, want)
+	}
+	want := []string{"2025-01-15/m1.highlight-1.gif", "2025-01-15/m1.highlight-2.gif"}
+	if !slices.Equal(r.PreviewPaths, want) || len(meta.HighlightPreviews) != 2 || meta.HighlightPreviews[1].Title != "A long point about the roadmap" {
+		t.Fatalf("paths %v, previews %+v", r.PreviewPaths, meta.HighlightPreviews)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025-01-15", "m1.highlight-3.gif")); err == nil {
+		t.Error("failed preview left behind")
+	}
+
+	md := renderFormattedMarkdown("obsidian", meta, "")
+	if !strings.Contains(md, "### Previews\n\n![Pricing (draft) — 00:00:12](m1.highlight-1.gif)\n") {
+		t.Errorf("note embeds:\n%s", md)
+	}
+	if md := renderFormattedMarkdown("notion", meta, ""); !strings.Contains(md, "](m1.highlight-2.gif)") {
+		t.Errorf("notion note embeds:\n%s", md)
+	}
+
+	// A saved stream URL is not a video to cut from.
+	r = &ExportResult{VideoPath: "2025-01-15/m1.m3u8.url", VideoMethod: "hls"}
+	if e.writeHighlightPreviews(context.Background(), &Metadata{ID: "m1", Highlights: meta.Highlights}, "2025-01-15/m1", r) {
+		t.Error("previews cut from an HLS URL file")
+	}
+}
+
+func TestPreviewArgs(t *testing.T) {
+	gif := strings.Join(previewArgs(highlightPreviewGIF, "in.mp4", "out.gif", 12.5, 4), " ")
+	if !strings.HasPrefix(gif, "-ss 12.50 -t 4.00 -i in.mp4 -an ") || !strings.Contains(gif, "paletteuse") || !strings.HasSuffix(gif, "-loop 0 -f gif -y out.gif") {
+		t.Errorf("gif args: %s", gif)
+	}
+	if webp := strings.Join(previewArgs(highlightPreviewWebP, "in.mp4", "out.webp", 0, 6), " "); !strings.Contains(webp, "-c:v libwebp") {
+		t.Errorf("webp args: %s", webp)
+	}
+	for in, want := range map[string]string{"": "", "GIF": "gif", " webp ": "webp"} {
+		if got, err := parseHighlightPreviews(in); err != nil || got != want {
+			t.Errorf("parseHighlightPreviews(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := parseHighlightPreviews("mp4"); err == nil {
+		t.Error("mp4 accepted")
+	}
+}
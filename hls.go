@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── Native HLS Download ─────────────────────────────────────────────────────
+//
+// --hls-download replaces the "save .m3u8.url and run convert_hls.sh later"
+// path with an in-process downloader: the media playlist is fetched, segments
+// are pulled concurrently with retries, concatenated in order, and remuxed to
+// MP4 with ffmpeg (stream copy, no re-encode). Over high-latency links this is
+// much faster than a single-threaded ffmpeg pull.
+//
+// Encrypted streams (EXT-X-KEY with a method other than NONE) are handed to
+// ffmpeg directly, which knows how to fetch keys and decrypt. So are
+// byte-range playlists (EXT-X-BYTERANGE), whose segments are slices of one
+// file that a whole-URI fetch would download again for every segment.
+
+const (
+	hlsSegmentRetries  = 3
+	hlsPlaylistMaxSize = 4 * 1024 * 1024 // 4 MB — playlists are tiny; guard against junk
+)
+
+// errHLSEncrypted signals that the playlist uses segment encryption and the
+// native path cannot be used.
+var errHLSEncrypted = errors.New("hls: encrypted stream")
+
+// errHLSByteRange signals that the playlist addresses segments by byte range
+// and the native path cannot be used.
+var errHLSByteRange = errors.New("hls: byte-range segments")
+
+// hlsPlaylist is a parsed HLS media playlist.
+type hlsPlaylist struct {
+	InitURI   string   // EXT-X-MAP URI (fMP4 streams), resolved
+	Segments  []string // segment URIs, resolved, in playback order
+	Encrypted bool
+	ByteRange bool // EXT-X-BYTERANGE segments
+}
+
+// HLSDownloader fetches HLS streams segment-by-segment.
+type HLSDownloader struct {
+	client      *http.Client
+	concurrency int
+	verbose     bool
+	backoff     time.Duration // base retry delay; doubled per attempt
+	pace        *Throttle     // per-host pacing for playlist requests; nil = none
+	inputArgs   []string      // ffmpeg options for remote inputs (--api-user-agent, --api-header)
+}
+
+// NewHLSDownloader returns a downloader with the given segment concurrency.
+func NewHLSDownloader(concurrency int, verbose bool) *HLSDownloader {
+	return &HLSDownloader{
+		client:      &http.Client{Timeout: 2 * time.Minute},
+		concurrency: max(concurrency, 1),
+		verbose:     verbose,
+		backoff:     time.Second,
+	}
+}
+
+// Download fetches the stream at playlistURL and writes it to outputPath.
+// With ffmpeg available the result is an MP4 at outputPath; without it the
+// concatenated transport stream is kept next to it with a .ts extension.
+// Returns the path actually written.
+func (d *HLSDownloader) Download(ctx context.Context, playlistURL, outputPath string) (string, error) {
+	pl, err := d.resolvePlaylist(ctx, playlistURL)
+	if err != nil {
+		return "", err
+	}
+	if pl.Encrypted || pl.ByteRange {
+		if checkFFmpeg() != nil {
+			if pl.Encrypted {
+				return "", errHLSEncrypted
+			}
+			return "", errHLSByteRange
+		}
+		slog.Debug("HLS stream needs ffmpeg, delegating", "url", playlistURL, "encrypted", pl.Encrypted, "byte_range", pl.ByteRange)
+		args := append(append([]string{}, d.inputArgs...), "-i", playlistURL, "-c", "copy", "-y", outputPath)
+		if err := runFFmpeg(ctx, d.verbose, args...); err != nil {
+			return "", fmt.Errorf("ffmpeg hls pull: %w", err)
+		}
+		return outputPath, fixPerms(outputPath)
+	}
+	if len(pl.Segments) == 0 {
+		return "", errors.New("hls: playlist has no segments")
+	}
+
+	partsDir := outputPath + ".hls-parts"
+	if err := ensureDirPrivate(partsDir); err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(partsDir)
+
+	uris := pl.Segments
+	if pl.InitURI != "" {
+		uris = append([]string{pl.InitURI}, uris...)
+	}
+	slog.Debug("Downloading HLS segments", "segments", len(uris), "concurrency", d.concurrency)
+
+	if err := d.fetchSegments(ctx, uris, partsDir); err != nil {
+		return "", err
+	}
+
+	ext := ".ts"
+	if pl.InitURI != "" {
+		ext = ".m4s"
+	}
+	joined := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ext
+	if err := concatParts(partsDir, len(uris), joined); err != nil {
+		return "", err
+	}
+
+	if checkFFmpeg() != nil {
+		slog.Warn("ffmpeg not found, keeping unmuxed stream", "path", joined)
+		return joined, nil
+	}
+	args := []string{"-i", joined, "-c", "copy"}
+	if ext == ".ts" {
+		args = append(args, "-bsf:a", "aac_adtstoasc")
+	}
+	args = append(args, "-movflags", "+faststart", "-y", outputPath)
+	if err := runFFmpeg(ctx, d.verbose, args...); err != nil {
+		_ = os.Remove(joined)
+		return "", fmt.Errorf("ffmpeg remux: %w", err)
+	}
+	_ = os.Remove(joined)
+	return outputPath, fixPerms(outputPath)
+}
+
+// resolvePlaylist fetches playlistURL and, if it is a master playlist,
+// follows the highest-bandwidth variant.
+func (d *HLSDownloader) resolvePlaylist(ctx context.Context, playlistURL string) (*hlsPlaylist, error) {
+	body, err := d.get(ctx, playlistURL, hlsPlaylistMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("fetch playlist: %w", err)
+	}
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if variant := pickHLSVariant(string(body), base); variant != "" {
+		slog.Debug("HLS master playlist, using variant", "url", variant)
+		body, err = d.get(ctx, variant, hlsPlaylistMaxSize)
+		if err != nil {
+			return nil, fmt.Errorf("fetch variant playlist: %w", err)
+		}
+		if base, err = url.Parse(variant); err != nil {
+			return nil, err
+		}
+	}
+	return parseHLSMediaPlaylist(string(body), base)
+}
+
+// pickHLSVariant returns the resolved URI of the highest-bandwidth variant in
+// a master playlist, or "" if body is a media playlist.
+func pickHLSVariant(body string, base *url.URL) string {
+	var best string
+	bestBW := -1
+	pendingBW := -1
+	for _, line := range hlsLines(body) {
+		if strings.HasPrefix(line, "#EXT-X-STREAM-INF:") {
+			pendingBW = 0
+			if bw, err := strconv.Atoi(hlsAttr(line, "BANDWIDTH")); err == nil {
+				pendingBW = bw
+			}
+			continue
+		}
+		if pendingBW >= 0 && !strings.HasPrefix(line, "#") {
+			if pendingBW > bestBW {
+				bestBW = pendingBW
+				best = resolveRef(base, line)
+			}
+			pendingBW = -1
+		}
+	}
+	return best
+}
+
+// parseHLSMediaPlaylist extracts segment URIs from a media playlist.
+func parseHLSMediaPlaylist(body string, base *url.URL) (*hlsPlaylist, error) {
+	lines := hlsLines(body)
+	if len(lines) == 0 || lines[0] != "#EXTM3U" {
+		return nil, errors.New("hls: not an m3u8 playlist")
+	}
+	pl := &hlsPlaylist{}
+	for _, line := range lines[1:] {
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-KEY:"):
+			if m := hlsAttr(line, "METHOD"); m != "" && m != "NONE" {
+				pl.Encrypted = true
+			}
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			if uri := hlsAttr(line, "URI"); uri != "" {
+				pl.InitURI = resolveRef(base, uri)
+			}
+			if hlsAttr(line, "BYTERANGE") != "" {
+				pl.ByteRange = true
+			}
+		case strings.HasPrefix(line, "#EXT-X-BYTERANGE:"):
+			pl.ByteRange = true
+		case strings.HasPrefix(line, "#"):
+			// Other tags (EXTINF, TARGETDURATION, ...) don't affect download order.
+		default:
+			pl.Segments = append(pl.Segments, resolveRef(base, line))
+		}
+	}
+	return pl, nil
+}
+
+// hlsLines splits a playlist into trimmed, non-empty lines.
+func hlsLines(body string) []string {
+	var out []string
+	s := bufio.NewScanner(strings.NewReader(body))
+	s.Buffer(make([]byte, 0, 4096), 64*1024)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// hlsAttr returns the value of key in a tag's attribute list, unquoted.
+func hlsAttr(line, key string) string {
+	_, attrs, ok := strings.Cut(line, ":")
+	if !ok {
+		return ""
+	}
+	for attrs != "" {
+		var val string
+		// Quoted values may contain commas, so split by hand.
+		eq := strings.IndexByte(attrs, '=')
+		if eq < 0 {
+			return ""
+		}
+		k := strings.TrimSpace(attrs[:eq])
+		rest := attrs[eq+1:]
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return ""
+			}
+			val = rest[1 : end+1]
+			rest = strings.TrimPrefix(rest[end+2:], ",")
+		} else {
+			val, rest, _ = strings.Cut(rest, ",")
+		}
+		if k == key {
+			return val
+		}
+		attrs = rest
+	}
+	return ""
+}
+
+func resolveRef(base *url.URL, ref string) string {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return base.ResolveReference(u).String()
+}
+
+// fetchSegments downloads uris[i] to partsDir/%06d with bounded concurrency.
+// The first failure cancels the remaining downloads.
+func (d *HLSDownloader) fetchSegments(ctx context.Context, uris []string, partsDir string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for range min(d.concurrency, len(uris)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := d.fetchSegment(ctx, uris[i], partPath(partsDir, i)); err != nil {
+					errOnce.Do(func() {
+						firstErr = fmt.Errorf("segment %d: %w", i, err)
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for i := range uris {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// fetchSegment downloads a single segment with exponential-backoff retries.
+func (d *HLSDownloader) fetchSegment(ctx context.Context, uri, dest string) error {
+	var err error
+	for attempt := range hlsSegmentRetries {
+		if attempt > 0 {
+			select {
+			case <-time.After(d.backoff << (attempt - 1)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err = d.download(ctx, uri, dest); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Debug("HLS segment retry", "attempt", attempt+1, "error", err)
+	}
+	return err
+}
+
+func (d *HLSDownloader) download(ctx context.Context, uri, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (d *HLSDownloader) get(ctx context.Context, uri string, limit int64) ([]byte, error) {
+	if d.pace != nil {
+		if err := d.pace.WaitHost(ctx, hostOf(uri)); err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+func partPath(dir string, i int) string {
+	return filepath.Join(dir, fmt.Sprintf("%06d", i))
+}
+
+// concatParts appends parts 0..n-1 from dir into dest in order.
+func concatParts(dir string, n int, dest string) error {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	for i := range n {
+		in, err := os.Open(partPath(dir, i))
+		if err != nil {
+			out.Close()
+			return err
+		}
+		_, err = io.Copy(out, in)
+		in.Close()
+		if err != nil {
+			out.Close()
+			return fmt.Errorf("concat segment %d: %w", i, err)
+		}
+	}
+	return out.Close()
+}
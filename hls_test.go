@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ── Playlist parsing ────────────────────────────────────────────────────────
+
+func TestPickHLSVariant(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example.com/v/master.m3u8")
+	master := "#EXTM3U\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360,CODECS=\"avc1.4d401e,mp4a.40.2\"\n" +
+		"low/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=2500000,RESOLUTION=1280x720\n" +
+		"hi/index.m3u8\n"
+	if got, want := pickHLSVariant(master, base), "https://cdn.example.com/v/hi/index.m3u8"; got != want {
+		t.Errorf("pickHLSVariant = %q, want %q", got, want)
+	}
+
+	media := "#EXTM3U\n#EXTINF:6.0,\nseg0.ts\n"
+	if got := pickHLSVariant(media, base); got != "" {
+		t.Errorf("media playlist should have no variant, got %q", got)
+	}
+}
+
+func TestParseHLSMediaPlaylist(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example.com/v/hi/index.m3u8")
+	body := "#EXTM3U\n#EXT-X-TARGETDURATION:6\n" +
+		"#EXT-X-MAP:URI=\"init.mp4\"\n" +
+		"#EXTINF:6.0,\nseg0.m4s\n" +
+		"#EXTINF:6.0,\n/abs/seg1.m4s?sig=abc\n" +
+		"#EXTINF:6.0,\nhttps://other.example.com/seg2.m4s\n" +
+		"#EXT-X-ENDLIST\n"
+
+	pl, err := parseHLSMediaPlaylist(body, base)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pl.InitURI != "https://cdn.example.com/v/hi/init.mp4" {
+		t.Errorf("InitURI = %q", pl.InitURI)
+	}
+	want := []string{
+		"https://cdn.example.com/v/hi/seg0.m4s",
+		"https://cdn.example.com/abs/seg1.m4s?sig=abc",
+		"https://other.example.com/seg2.m4s",
+	}
+	if len(pl.Segments) != len(want) {
+		t.Fatalf("segments = %v, want %v", pl.Segments, want)
+	}
+	for i := range want {
+		if pl.Segments[i] != want[i] {
+			t.Errorf("segment[%d] = %q, want %q", i, pl.Segments[i], want[i])
+		}
+	}
+	if pl.Encrypted {
+		t.Error("playlist should not be marked encrypted")
+	}
+}
+
+func TestParseHLSMediaPlaylistEncrypted(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example.com/index.m3u8")
+	body := "#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key.bin\"\n#EXTINF:6.0,\nseg0.ts\n"
+	pl, err := parseHLSMediaPlaylist(body, base)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !pl.Encrypted {
+		t.Error("AES-128 playlist should be marked encrypted")
+	}
+}
+
+func TestParseHLSMediaPlaylistRejectsJunk(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example.com/index.m3u8")
+	if _, err := parseHLSMediaPlaylist("<html>nope</html>", base); err == nil {
+		t.Error("expected error for non-m3u8 body")
+	}
+}
+
+func TestHLSAttrQuotedComma(t *testing.T) {
+	line := `#EXT-X-STREAM-INF:CODECS="avc1,mp4a",BANDWIDTH=42`
+	if got := hlsAttr(line, "BANDWIDTH"); got != "42" {
+		t.Errorf("BANDWIDTH = %q, want 42", got)
+	}
+	if got := hlsAttr(line, "CODECS"); got != "avc1,mp4a" {
+		t.Errorf("CODECS = %q, want avc1,mp4a", got)
+	}
+}
+
+// ── Segment download ────────────────────────────────────────────────────────
+
+// newHLSServer serves a media playlist with n segments whose bodies are
+// "seg<i>;". The first request for segment 1 fails to exercise retries.
+func newHLSServer(t *testing.T, n int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var failed atomic.Bool
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		b.WriteString("#EXTM3U\n#EXT-X-TARGETDURATION:6\n")
+		for i := range n {
+			fmt.Fprintf(&b, "#EXTINF:6.0,\nseg%d.ts\n", i)
+		}
+		b.WriteString("#EXT-X-ENDLIST\n")
+		fmt.Fprint(w, b.String())
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".ts")
+		if name == "seg1" && failed.CompareAndSwap(false, true) {
+			http.Error(w, "flaky", http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintf(w, "%s;", name)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestHLSDownloaderFetchesInOrderWithRetry(t *testing.T) {
+	const n = 12
+	srv, hits := newHLSServer(t, n)
+
+	d := NewHLSDownloader(4, false)
+	d.backoff = time.Millisecond
+
+	pl, err := d.resolvePlaylist(context.Background(), srv.URL+"/index.m3u8")
+	if err != nil {
+		t.Fatalf("resolvePlaylist: %v", err)
+	}
+
+	dir := t.TempDir()
+	parts := filepath.Join(dir, "parts")
+	if err := ensureDirPrivate(parts); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.fetchSegments(context.Background(), pl.Segments, parts); err != nil {
+		t.Fatalf("fetchSegments: %v", err)
+	}
+	if got := hits.Load(); got != n+1 {
+		t.Errorf("segment requests = %d, want %d (one retry)", got, n+1)
+	}
+
+	joined := filepath.Join(dir, "out.ts")
+	if err := concatParts(parts, n, joined); err != nil {
+		t.Fatalf("concatParts: %v", err)
+	}
+	data, err := os.ReadFile(joined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want strings.Builder
+	for i := range n {
+		fmt.Fprintf(&want, "seg%d;", i)
+	}
+	if string(data) != want.String() {
+		t.Errorf("joined = %q, want %q", data, want.String())
+	}
+
+	info, _ := os.Stat(joined)
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("joined perms = %o, want 600", perm)
+	}
+}
+
+func TestHLSDownloaderGivesUpAfterRetries(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	d := NewHLSDownloader(2, false)
+	d.backoff = time.Millisecond
+	err := d.fetchSegments(context.Background(), []string{srv.URL + "/a.ts"}, t.TempDir())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got := hits.Load(); got != hlsSegmentRetries {
+		t.Errorf("attempts = %d, want %d", got, hlsSegmentRetries)
+	}
+}
+
+func TestParseHLSMediaPlaylistByteRange(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example.com/index.m3u8")
+	body := "#EXTM3U\n#EXTINF:6.0,\n#EXT-X-BYTERANGE:1000@0\nall.ts\n#EXTINF:6.0,\n#EXT-X-BYTERANGE:1000\nall.ts\n"
+	pl, err := parseHLSMediaPlaylist(body, base)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !pl.ByteRange {
+		t.Error("EXT-X-BYTERANGE playlist should be marked byte-range")
+	}
+}
+
+// fakeFFmpeg puts an ffmpeg on PATH that records its arguments in the
+// returned file and exits with code, creating its output file on success.
+func fakeFFmpeg(t *testing.T, code int) string {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %q\nfor a; do out=$a; done\n[ %d -eq 0 ] && : > \"$out\"\nexit %d\n", argsFile, code, code)
+	if err := os.WriteFile(filepath.Join(dir, "ffmpeg"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+	return argsFile
+}
+
+func TestHLSDownloaderRemuxFailureCleansUp(t *testing.T) {
+	srv, _ := newHLSServer(t, 3)
+	fakeFFmpeg(t, 1)
+
+	d := NewHLSDownloader(2, false)
+	d.backoff = time.Millisecond
+	dir := t.TempDir()
+	got, err := d.Download(context.Background(), srv.URL+"/index.m3u8", filepath.Join(dir, "out.mp4"))
+	if err == nil || got != "" {
+		t.Fatalf("Download = %q, %v; want a remux error and no path", got, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		t.Errorf("left behind after a failed remux: %s", e.Name())
+	}
+}
+
+func TestHLSDownloaderDelegatesByteRange(t *testing.T) {
+	var segHits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "#EXTM3U\n#EXTINF:6.0,\n#EXT-X-BYTERANGE:4@0\nall.ts\n#EXTINF:6.0,\n#EXT-X-BYTERANGE:4\nall.ts\n#EXT-X-ENDLIST\n")
+	})
+	mux.HandleFunc("/all.ts", func(w http.ResponseWriter, r *http.Request) {
+		segHits.Add(1)
+		fmt.Fprint(w, "abcdefgh")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	d := NewHLSDownloader(2, false)
+	out := filepath.Join(t.TempDir(), "out.mp4")
+
+	// Without ffmpeg the native path refuses rather than fetching the
+	// whole file once per segment.
+	t.Setenv("PATH", t.TempDir())
+	if _, err := d.Download(context.Background(), srv.URL+"/index.m3u8", out); err != errHLSByteRange {
+		t.Errorf("without ffmpeg: err = %v, want errHLSByteRange", err)
+	}
+
+	argsFile := fakeFFmpeg(t, 0)
+	if got, err := d.Download(context.Background(), srv.URL+"/index.m3u8", out); err != nil || got != out {
+		t.Fatalf("with ffmpeg: Download = %q, %v", got, err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("ffmpeg not run: %v", err)
+	}
+	if !strings.Contains(string(args), "-i "+srv.URL+"/index.m3u8") {
+		t.Errorf("ffmpeg args = %q, want the playlist as input", args)
+	}
+	if n := segHits.Load(); n != 0 {
+		t.Errorf("native path fetched %d segment(s)", n)
+	}
+}
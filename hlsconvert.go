@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ── HLS Conversion Queue ────────────────────────────────────────────────────
+//
+// `graindl hls-convert --watch-dir recordings/` replaces convert_hls.sh. It
+// treats every .m3u8.url file under the directory as a queued conversion:
+// the stream is remuxed to MP4 with ffmpeg (stream copy, retried with
+// backoff), the manifest entry moves from hls_pending to ok, and the URL
+// file is removed. By default the directory is rescanned every --interval
+// so it can run next to a watch-mode exporter; --once does a single pass.
+
+const hlsURLSuffix = ".m3u8.url"
+
+// hlsConverter drains .m3u8.url files under dir.
+type hlsConverter struct {
+	dir     string
+	jobs    int
+	retries int
+	backoff time.Duration // base retry delay; doubled per attempt
+	dryRun  bool
+
+	// convert remuxes the stream at playlistURL into outputPath.
+	convert func(ctx context.Context, playlistURL, outputPath string) error
+
+	// failed remembers URL files that exhausted their retries, keyed by
+	// path, so watch mode doesn't hammer a dead stream every cycle. A file
+	// is retried once its modification time changes.
+	failed map[string]time.Time
+}
+
+// hlsConvertStats summarizes one pass.
+type hlsConvertStats struct {
+	Converted int
+	Failed    int
+	Skipped   int // dry run, or failed earlier and unchanged since
+}
+
+func runHLSConvert(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fset := flag.NewFlagSet("hls-convert", flag.ContinueOnError)
+	dir := fset.String("watch-dir", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to scan for .m3u8.url files")
+	jobs := fset.Int("jobs", envInt(dotenv, "GRAIN_HLS_JOBS", 1), "Concurrent conversions")
+	retries := fset.Int("retries", 3, "Attempts per stream before giving up")
+	interval := fset.Duration("interval", 30*time.Second, "Rescan interval")
+	once := fset.Bool("once", false, "Convert what is queued now and exit")
+	dryRun := fset.Bool("dry-run", envBool(dotenv, "GRAIN_DRY_RUN"), "List queued streams without converting")
+	verbose := fset.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output (includes ffmpeg output)")
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	if *jobs < 1 || *retries < 1 {
+		slog.Error("--jobs and --retries must be at least 1")
+		return 2
+	}
+	if !*once && *interval < time.Second {
+		slog.Error("--interval must be at least 1s")
+		return 2
+	}
+	if _, err := os.Stat(*dir); err != nil {
+		slog.Error("Watch directory not found", "path", *dir)
+		return 1
+	}
+	if err := checkUnrouted("hls-convert", *dir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if !*dryRun {
+		if err := checkFFmpeg(); err != nil {
+			slog.Error(err.Error())
+			return 1
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := newHLSConverter(*dir, *jobs, *retries, *verbose)
+	c.dryRun = *dryRun
+
+	if *once || *dryRun {
+		stats, err := c.RunOnce(ctx)
+		if err != nil {
+			slog.Error("HLS conversion failed", "error", err)
+			return 1
+		}
+		slog.Info(fmt.Sprintf("HLS conversion: %d converted, %d failed, %d skipped", stats.Converted, stats.Failed, stats.Skipped))
+		if stats.Failed > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	slog.Info(fmt.Sprintf("Watching %s for HLS streams every %s", absPath(*dir), *interval))
+	for {
+		stats, err := c.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("HLS conversion pass failed", "error", err)
+		} else if stats.Converted+stats.Failed > 0 {
+			slog.Info(fmt.Sprintf("HLS conversion: %d converted, %d failed", stats.Converted, stats.Failed))
+		}
+		if !waitUntil(ctx, time.Now().Add(*interval)) {
+			slog.Info("HLS converter stopped")
+			return 0
+		}
+	}
+}
+
+func newHLSConverter(dir string, jobs, retries int, verbose bool) *hlsConverter {
+	return &hlsConverter{
+		dir:     dir,
+		jobs:    max(jobs, 1),
+		retries: max(retries, 1),
+		backoff: 5 * time.Second,
+		convert: func(ctx context.Context, playlistURL, outputPath string) error {
+			return runFFmpeg(ctx, verbose, "-i", playlistURL, "-c", "copy", "-movflags", "+faststart", "-f", "mp4", "-y", outputPath)
+		},
+		failed: make(map[string]time.Time),
+	}
+}
+
+// RunOnce converts every queued stream once and updates the manifest for
+// those that succeeded. Per-stream failures are counted, not returned.
+func (c *hlsConverter) RunOnce(ctx context.Context) (hlsConvertStats, error) {
+	var stats hlsConvertStats
+	queued, err := findHLSURLFiles(c.dir)
+	if err != nil {
+		return stats, err
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		converted = make(map[string]string) // url file rel path → mp4 rel path
+		sem       = make(chan struct{}, c.jobs)
+	)
+	for _, rel := range queued {
+		if ctx.Err() != nil {
+			break
+		}
+		mu.Lock()
+		skip := c.dryRun || c.failedBefore(rel)
+		if skip {
+			stats.Skipped++
+		}
+		mu.Unlock()
+		if skip {
+			if c.dryRun {
+				slog.Info("Would convert", "path", rel)
+			}
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(rel string) {
+			defer func() { <-sem; wg.Done() }()
+			mp4, err := c.convertOne(ctx, rel)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("HLS conversion failed", "path", rel, "error", err)
+					stats.Failed++
+					c.markFailed(rel)
+				}
+				return
+			}
+			converted[rel] = mp4
+			stats.Converted++
+		}(rel)
+	}
+	wg.Wait()
+
+	if len(converted) > 0 {
+		var urls, mp4s []string
+		for url, mp4 := range converted {
+			urls, mp4s = append(urls, url), append(mp4s, mp4)
+		}
+		if _, err := updateChecksums(c.dir, mp4s, urls); err != nil {
+			slog.Warn("Checksum state update failed", "error", err)
+		}
+		if err := updateManifestHLS(c.dir, converted); err != nil {
+			return stats, err
+		}
+	}
+	return stats, ctx.Err()
+}
+
+// convertOne converts one URL file to an MP4 next to it and removes the
+// URL file. The MP4 is written under a temporary name and renamed into
+// place, so an existing MP4 at the target is always complete and is taken
+// as an earlier conversion whose cleanup was interrupted.
+func (c *hlsConverter) convertOne(ctx context.Context, rel string) (string, error) {
+	urlPath := filepath.Join(c.dir, rel)
+	mp4Rel := strings.TrimSuffix(rel, hlsURLSuffix) + ".mp4"
+	mp4Path := filepath.Join(c.dir, mp4Rel)
+
+	if _, err := os.Stat(mp4Path); err == nil {
+		slog.Info("MP4 already present, dequeuing", "path", mp4Rel)
+		return mp4Rel, os.Remove(urlPath)
+	}
+
+	data, err := os.ReadFile(urlPath)
+	if err != nil {
+		return "", err
+	}
+	playlistURL := strings.TrimSpace(string(data))
+	if playlistURL == "" {
+		return "", errors.New("empty URL file")
+	}
+
+	tmp := mp4Path + ".part"
+	defer os.Remove(tmp)
+	slog.Info("Converting HLS stream", "path", rel)
+	for attempt := 1; ; attempt++ {
+		err = c.convert(ctx, playlistURL, tmp)
+		if err == nil || attempt >= c.retries || ctx.Err() != nil {
+			break
+		}
+		delay := c.backoff << (attempt - 1)
+		slog.Debug("HLS conversion attempt failed, retrying", "path", rel, "attempt", attempt, "delay", delay, "error", err)
+		if !waitUntil(ctx, time.Now().Add(delay)) {
+			break
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg: %w", err)
+	}
+
+	perm := os.FileMode(0o600)
+	if sealed(urlPath) {
+		perm = sealedPerm // the meeting is under --immutable
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, mp4Path); err != nil {
+		return "", err
+	}
+	_ = os.Remove(urlPath)
+	slog.Info("Video converted", "path", mp4Rel)
+	return mp4Rel, nil
+}
+
+func (c *hlsConverter) failedBefore(rel string) bool {
+	mtime, ok := c.failed[rel]
+	if !ok {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(c.dir, rel))
+	return err == nil && info.ModTime().Equal(mtime)
+}
+
+func (c *hlsConverter) markFailed(rel string) {
+	if info, err := os.Stat(filepath.Join(c.dir, rel)); err == nil {
+		c.failed[rel] = info.ModTime()
+	}
+}
+
+// findHLSURLFiles lists .m3u8.url files under dir, relative and sorted,
+// skipping hidden and internal "_" directories.
+func findHLSURLFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !strings.HasSuffix(name, hlsURLSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", dir, err)
+	}
+	return paths, nil
+}
+
+// updateManifestHLS marks manifest entries whose video was converted as ok
+// and points them at the MP4. A missing manifest is not an error: URL files
+// from older runs may outlive it.
+func updateManifestHLS(dir string, converted map[string]string) error {
+	const name = "_export-manifest.json"
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	var m ExportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("parse manifest: %w", err)
+	}
+
+	changed := 0
+	for _, r := range m.Meetings {
+		mp4, ok := converted[filepath.Clean(r.VideoPath)]
+		if !ok || r.Status != "hls_pending" {
+			continue
+		}
+		r.Status = "ok"
+		r.VideoPath = mp4
+		m.HLSPending = max(m.HLSPending-1, 0)
+		changed++
+	}
+	if changed == 0 {
+		return nil
+	}
+	if err := NewLocalStorage(dir).WriteJSON(name, &m); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	slog.Debug("Manifest updated", "converted", changed)
+	return nil
+}
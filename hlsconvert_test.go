@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHLSConverter returns a converter over dir whose conversions are
+// performed by fn instead of ffmpeg.
+func newTestHLSConverter(dir string, fn func(ctx context.Context, playlistURL, outputPath string) error) *hlsConverter {
+	c := newHLSConverter(dir, 2, 3, false)
+	c.backoff = time.Millisecond
+	c.convert = fn
+	return c
+}
+
+func writeURLFile(t *testing.T, dir, rel, url string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := ensureDirPrivate(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(url+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeManifest(t *testing.T, dir string, m *ExportManifest) {
+	t.Helper()
+	if err := NewLocalStorage(dir).WriteJSON("_export-manifest.json", m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readManifest(t *testing.T, dir string) *ExportManifest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "_export-manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m ExportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	return &m
+}
+
+func fakeRemux(_ context.Context, _, outputPath string) error {
+	return os.WriteFile(outputPath, []byte("mp4"), 0o644)
+}
+
+// ── findHLSURLFiles ─────────────────────────────────────────────────────────
+
+func TestFindHLSURLFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeURLFile(t, dir, "2025-01-15/a.m3u8.url", "https://x/a.m3u8")
+	writeURLFile(t, dir, "2025-01-16/b.m3u8.url", "https://x/b.m3u8")
+	writeURLFile(t, dir, "_claims/c.m3u8.url", "https://x/c.m3u8")
+	writeURLFile(t, dir, ".hidden/d.m3u8.url", "https://x/d.m3u8")
+	writeURLFile(t, dir, "2025-01-15/a.json", "{}")
+
+	got, err := findHLSURLFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join("2025-01-15", "a.m3u8.url"), filepath.Join("2025-01-16", "b.m3u8.url")}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("findHLSURLFiles = %v, want %v", got, want)
+	}
+}
+
+// ── RunOnce ─────────────────────────────────────────────────────────────────
+
+func TestHLSConvertRunOnceUpdatesManifest(t *testing.T) {
+	dir := t.TempDir()
+	aURL := filepath.Join("2025-01-15", "a.m3u8.url")
+	bURL := filepath.Join("2025-01-15", "b.m3u8.url")
+	writeURLFile(t, dir, aURL, "https://x/a.m3u8")
+	writeURLFile(t, dir, bURL, "https://x/b.m3u8")
+	writeManifest(t, dir, &ExportManifest{
+		Total: 3, OK: 3, HLSPending: 2,
+		Meetings: []*ExportResult{
+			{ID: "a", Status: "hls_pending", VideoPath: aURL, VideoMethod: "hls"},
+			{ID: "b", Status: "hls_pending", VideoPath: bURL, VideoMethod: "hls"},
+			{ID: "c", Status: "ok", VideoPath: filepath.Join("2025-01-15", "c.mp4")},
+		},
+	})
+
+	var calls atomic.Int32
+	c := newTestHLSConverter(dir, func(ctx context.Context, playlistURL, outputPath string) error {
+		calls.Add(1)
+		if playlistURL == "https://x/b.m3u8" {
+			return errors.New("stream gone")
+		}
+		return fakeRemux(ctx, playlistURL, outputPath)
+	})
+
+	stats, err := c.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if stats.Converted != 1 || stats.Failed != 1 {
+		t.Errorf("stats = %+v, want 1 converted, 1 failed", stats)
+	}
+	if n := calls.Load(); n != 1+3 {
+		t.Errorf("convert called %d times, want 4 (a once, b three attempts)", n)
+	}
+
+	// a: converted, URL file removed, MP4 private.
+	if _, err := os.Stat(filepath.Join(dir, aURL)); !os.IsNotExist(err) {
+		t.Error("a.m3u8.url not removed after conversion")
+	}
+	info, err := os.Stat(filepath.Join(dir, "2025-01-15", "a.mp4"))
+	if err != nil {
+		t.Fatalf("a.mp4: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("a.mp4 perm = %o, want 600", perm)
+	}
+	// b: failed, URL file kept for a later attempt, no partial output.
+	if _, err := os.Stat(filepath.Join(dir, bURL)); err != nil {
+		t.Error("b.m3u8.url removed after failed conversion")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025-01-15", "b.mp4.part")); !os.IsNotExist(err) {
+		t.Error("partial b.mp4.part left behind")
+	}
+
+	m := readManifest(t, dir)
+	if m.HLSPending != 1 || m.OK != 3 {
+		t.Errorf("manifest hls_pending=%d ok=%d, want 1/3", m.HLSPending, m.OK)
+	}
+	a := m.Meetings[0]
+	if a.Status != "ok" || a.VideoPath != filepath.Join("2025-01-15", "a.mp4") {
+		t.Errorf("a = %q %q, want ok with MP4 path", a.Status, a.VideoPath)
+	}
+	if m.Meetings[1].Status != "hls_pending" {
+		t.Errorf("b status = %q, want hls_pending", m.Meetings[1].Status)
+	}
+}
+
+func TestHLSConvertSkipsFailedUntilChanged(t *testing.T) {
+	dir := t.TempDir()
+	rel := filepath.Join("2025-01-15", "a.m3u8.url")
+	writeURLFile(t, dir, rel, "https://x/a.m3u8")
+
+	fail := true
+	c := newTestHLSConverter(dir, func(ctx context.Context, u, out string) error {
+		if fail {
+			return errors.New("403")
+		}
+		return fakeRemux(ctx, u, out)
+	})
+	c.jobs = 1
+
+	if stats, _ := c.RunOnce(context.Background()); stats.Failed != 1 {
+		t.Fatalf("first pass stats = %+v, want 1 failed", stats)
+	}
+	if stats, _ := c.RunOnce(context.Background()); stats.Skipped != 1 || stats.Failed != 0 {
+		t.Errorf("second pass stats = %+v, want unchanged file skipped", stats)
+	}
+
+	// A refreshed URL file (new mtime) is retried.
+	fail = false
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, rel), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := c.RunOnce(context.Background()); stats.Converted != 1 {
+		t.Errorf("third pass stats = %+v, want 1 converted", stats)
+	}
+}
+
+func TestHLSConvertExistingMP4Dequeues(t *testing.T) {
+	dir := t.TempDir()
+	rel := filepath.Join("2025-01-15", "a.m3u8.url")
+	writeURLFile(t, dir, rel, "https://x/a.m3u8")
+	if err := os.WriteFile(filepath.Join(dir, "2025-01-15", "a.mp4"), []byte("done"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestHLSConverter(dir, func(context.Context, string, string) error {
+		t.Error("convert called despite existing MP4")
+		return nil
+	})
+	stats, err := c.RunOnce(context.Background())
+	if err != nil || stats.Converted != 1 {
+		t.Fatalf("RunOnce = %+v, %v", stats, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, rel)); !os.IsNotExist(err) {
+		t.Error("URL file not removed")
+	}
+}
+
+func TestHLSConvertDryRun(t *testing.T) {
+	dir := t.TempDir()
+	writeURLFile(t, dir, filepath.Join("2025-01-15", "a.m3u8.url"), "https://x/a.m3u8")
+
+	c := newTestHLSConverter(dir, func(context.Context, string, string) error {
+		t.Error("convert called in dry run")
+		return nil
+	})
+	c.dryRun = true
+	stats, err := c.RunOnce(context.Background())
+	if err != nil || stats.Skipped != 1 || stats.Converted != 0 {
+		t.Errorf("dry run = %+v, %v", stats, err)
+	}
+}
+
+func TestUpdateManifestHLSMissingManifest(t *testing.T) {
+	if err := updateManifestHLS(t.TempDir(), map[string]string{"a.m3u8.url": "a.mp4"}); err != nil {
+		t.Errorf("missing manifest: %v", err)
+	}
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ── HTTP Record / Replay ────────────────────────────────────────────────────
+//
+// --record-http fixtures/ saves every Grain page and API exchange the
+// browser makes (documents, XHR, fetch) as one sanitized JSON fixture per
+// request. --replay-http fixtures/ answers those requests from the saved
+// fixtures instead of the network, so integration tests and bug reports can
+// reproduce Grain's behavior deterministically. Static assets and non-Grain
+// hosts always go to the network.
+//
+// Sanitizing drops all headers except Content-Type and Location (cookies and
+// auth never reach disk), redacts secret-looking query parameters and JSON
+// fields, and masks JWTs anywhere in bodies. Fixtures still contain meeting
+// content (titles, transcripts, participants): review before sharing.
+
+const redactedValue = "REDACTED"
+
+// httpFixture is one recorded request/response pair.
+type httpFixture struct {
+	Seq          int               `json:"seq"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	ResourceType string            `json:"resource_type,omitempty"`
+	RequestBody  string            `json:"request_body,omitempty"`
+	Status       int               `json:"status"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body"`
+	BodyBase64   bool              `json:"body_base64,omitempty"`
+}
+
+// fixtureKey identifies the request a fixture answers.
+func fixtureKey(method, rawURL string) string {
+	return strings.ToUpper(method) + " " + sanitizeURL(rawURL)
+}
+
+// ── Sanitizing ──────────────────────────────────────────────────────────────
+
+var (
+	sensitiveKeyRe = regexp.MustCompile(`(?i)token|secret|passw|cookie|credential|signature|^sig$|api_?key|^key$|key-pair-id|^policy$|authorization|session`)
+	jwtRe          = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`)
+)
+
+// keptHeaders are the only headers written to fixtures. Content-Encoding
+// is deliberately absent: CDP hands over decoded bodies.
+var keptHeaders = []string{"Content-Type", "Location"}
+
+// sanitizeURL drops the fragment and redacts secret query parameters.
+func sanitizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.Fragment = ""
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if sensitiveKeyRe.MatchString(k) {
+				q.Set(k, redactedValue)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// sanitizeHeaders keeps only keptHeaders (case-insensitively).
+func sanitizeHeaders(h map[string]string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range h {
+		for _, keep := range keptHeaders {
+			if strings.EqualFold(k, keep) {
+				out[keep] = v
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// sanitizeBody redacts secret-looking fields of a JSON body and masks JWTs
+// in any text body. Non-JSON bodies are otherwise kept verbatim.
+func sanitizeBody(body string) string {
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err == nil && !dec.More() {
+		if data, err := json.Marshal(redactJSON(v)); err == nil {
+			body = string(data)
+		}
+	}
+	return jwtRe.ReplaceAllString(body, redactedValue)
+}
+
+func redactJSON(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, child := range t {
+			if _, isObj := child.(map[string]any); !isObj && sensitiveKeyRe.MatchString(k) {
+				t[k] = redactedValue
+				continue
+			}
+			t[k] = redactJSON(child)
+		}
+	case []any:
+		for i, child := range t {
+			t[i] = redactJSON(child)
+		}
+	}
+	return v
+}
+
+// sanitize returns a copy of f that is safe to write to disk.
+func (f httpFixture) sanitize() *httpFixture {
+	f.URL = sanitizeURL(f.URL)
+	f.Method = strings.ToUpper(f.Method)
+	f.Headers = sanitizeHeaders(f.Headers)
+	if f.RequestBody != "" {
+		f.RequestBody = sanitizeBody(f.RequestBody)
+	}
+	if !f.BodyBase64 {
+		f.Body = sanitizeBody(f.Body)
+	}
+	return &f
+}
+
+// ── Recording ───────────────────────────────────────────────────────────────
+
+// httpRecorder writes fixtures into a directory, numbered in request order.
+type httpRecorder struct {
+	dir string
+	mu  sync.Mutex
+	seq int
+}
+
+// newHTTPRecorder prepares dir for recording. Numbering continues after any
+// fixtures already there, so several runs can share a directory.
+func newHTTPRecorder(dir string) (*httpRecorder, error) {
+	if err := ensureDirPrivate(dir); err != nil {
+		return nil, fmt.Errorf("record-http dir: %w", err)
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &httpRecorder{dir: dir, seq: len(existing)}, nil
+}
+
+// Record sanitizes f and writes it as the next fixture.
+func (r *httpRecorder) Record(f *httpFixture) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	clean := f.sanitize()
+	clean.Seq = r.seq
+
+	data, err := json.MarshalIndent(clean, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal fixture: %w", err)
+	}
+	name := fmt.Sprintf("%05d-%s-%s.json", clean.Seq, strings.ToLower(clean.Method), fixtureSlug(clean.URL))
+	return os.WriteFile(filepath.Join(r.dir, name), data, 0o600)
+}
+
+// fixtureSlug is a short, filesystem-safe hint of the URL path.
+func fixtureSlug(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "request"
+	}
+	s := strings.Trim(slugify(u.Host+" "+u.Path, slugStyleASCII), "-")
+	if r := []rune(s); len(r) > 60 {
+		s = strings.TrimRight(string(r[:60]), "-")
+	}
+	return coalesce(s, "request")
+}
+
+// ── Replay ──────────────────────────────────────────────────────────────────
+
+// httpReplayer serves recorded fixtures. Repeated requests for the same
+// method and URL get the recorded responses in order; once they run out,
+// the last one repeats.
+type httpReplayer struct {
+	mu     sync.Mutex
+	queues map[string][]*httpFixture
+	served map[string]int
+}
+
+// loadHTTPFixtures reads every fixture in dir.
+func loadHTTPFixtures(dir string) (*httpReplayer, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("replay-http: no fixtures in %s", dir)
+	}
+
+	var fixtures []*httpFixture
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var f httpFixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("replay-http: %s: %w", filepath.Base(p), err)
+		}
+		if f.Method == "" || f.URL == "" {
+			return nil, fmt.Errorf("replay-http: %s: missing method or url", filepath.Base(p))
+		}
+		fixtures = append(fixtures, &f)
+	}
+	sort.SliceStable(fixtures, func(i, j int) bool { return fixtures[i].Seq < fixtures[j].Seq })
+
+	p := &httpReplayer{queues: make(map[string][]*httpFixture), served: make(map[string]int)}
+	for _, f := range fixtures {
+		k := fixtureKey(f.Method, f.URL)
+		p.queues[k] = append(p.queues[k], f)
+	}
+	return p, nil
+}
+
+// Lookup returns the next fixture for a request, if one was recorded.
+func (p *httpReplayer) Lookup(method, rawURL string) (*httpFixture, bool) {
+	k := fixtureKey(method, rawURL)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q := p.queues[k]
+	if len(q) == 0 {
+		return nil, false
+	}
+	i := min(p.served[k], len(q)-1)
+	p.served[k]++
+	return q[i], true
+}
+
+// body returns the decoded response body.
+func (f *httpFixture) body() ([]byte, error) {
+	if f.BodyBase64 {
+		return base64.StdEncoding.DecodeString(f.Body)
+	}
+	return []byte(f.Body), nil
+}
+
+// ── Browser Integration ─────────────────────────────────────────────────────
+
+// isGrainHost reports whether rawURL points at a Grain host (see
+// --grain-base-url) or a subdomain.
+func isGrainHost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return isGrainDomain(u.Hostname())
+}
+
+// isCapturedType reports whether a resource type is recorded/replayed:
+// page documents and API calls, not scripts, styles, images, or media.
+func isCapturedType(t proto.NetworkResourceType) bool {
+	switch t {
+	case proto.NetworkResourceTypeDocument, proto.NetworkResourceTypeXHR, proto.NetworkResourceTypeFetch:
+		return true
+	}
+	return false
+}
+
+// newHTTPTap builds the recorder or replayer selected by cfg (nil, nil when
+// neither is enabled).
+func newHTTPTap(cfg *Config) (*httpRecorder, *httpReplayer, error) {
+	switch {
+	case cfg.RecordHTTP != "" && cfg.ReplayHTTP != "":
+		return nil, nil, errors.New("--record-http and --replay-http are mutually exclusive")
+	case cfg.RecordHTTP != "":
+		rec, err := newHTTPRecorder(cfg.RecordHTTP)
+		return rec, nil, err
+	case cfg.ReplayHTTP != "":
+		rep, err := loadHTTPFixtures(cfg.ReplayHTTP)
+		return nil, rep, err
+	}
+	return nil, nil, nil
+}
+
+// attachHTTPTap starts recording or replaying on page, per b's settings.
+func (b *Browser) attachHTTPTap(page *rod.Page) {
+	if b.recorder != nil {
+		go page.EachEvent(recordHandlers(page, b.recorder)...)()
+	}
+	if b.replayer != nil {
+		router := page.HijackRequests()
+		for _, host := range grainHosts() {
+			router.MustAdd("*"+host+"*", func(h *rod.Hijack) {
+				replayRequest(h, b.replayer)
+			})
+		}
+		go router.Run()
+	}
+}
+
+// recordHandlers returns CDP network event callbacks that assemble
+// fixtures from request, response, and loading-finished events. Callbacks
+// run sequentially, so pending needs no lock.
+func recordHandlers(page *rod.Page, rec *httpRecorder) []any {
+	pending := make(map[proto.NetworkRequestID]*httpFixture)
+	return []any{
+		func(e *proto.NetworkRequestWillBeSent) {
+			if e.Request == nil || !isCapturedType(e.Type) || !isGrainHost(e.Request.URL) {
+				return
+			}
+			pending[e.RequestID] = &httpFixture{
+				Method:       e.Request.Method,
+				URL:          e.Request.URL,
+				ResourceType: string(e.Type),
+				RequestBody:  e.Request.PostData,
+			}
+		},
+		func(e *proto.NetworkResponseReceived) {
+			f := pending[e.RequestID]
+			if f == nil || e.Response == nil {
+				return
+			}
+			f.Status = e.Response.Status
+			f.Headers = make(map[string]string, len(e.Response.Headers))
+			for k, v := range e.Response.Headers {
+				f.Headers[k] = v.Str()
+			}
+		},
+		func(e *proto.NetworkLoadingFinished) {
+			f := pending[e.RequestID]
+			delete(pending, e.RequestID)
+			if f == nil || f.Status == 0 {
+				return
+			}
+			if res, err := (proto.NetworkGetResponseBody{RequestID: e.RequestID}).Call(page); err == nil {
+				f.Body, f.BodyBase64 = res.Body, res.Base64Encoded
+			}
+			if err := rec.Record(f); err != nil {
+				slog.Warn("HTTP fixture write failed", "url", f.URL, "error", err)
+			}
+		},
+		func(e *proto.NetworkLoadingFailed) {
+			delete(pending, e.RequestID)
+		},
+	}
+}
+
+// replayRequest answers a hijacked Grain request from its fixture. Captured
+// types with no fixture fail as if offline, so a replay never silently
+// reaches the live service; other Grain assets load normally.
+func replayRequest(h *rod.Hijack, rep *httpReplayer) {
+	u := h.Request.URL().String()
+	if !isGrainHost(u) {
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+		return
+	}
+	f, ok := rep.Lookup(h.Request.Method(), u)
+	if !ok {
+		if isCapturedType(h.Request.Type()) {
+			slog.Warn("No HTTP fixture for request", "method", h.Request.Method(), "url", sanitizeURL(u))
+			h.Response.Fail(proto.NetworkErrorReasonInternetDisconnected)
+			return
+		}
+		h.ContinueRequest(&proto.FetchContinueRequest{})
+		return
+	}
+
+	body, err := f.body()
+	if err != nil {
+		slog.Warn("Corrupt HTTP fixture", "seq", f.Seq, "error", err)
+		h.Response.Fail(proto.NetworkErrorReasonFailed)
+		return
+	}
+	h.Response.Payload().ResponseCode = f.Status
+	for k, v := range f.Headers {
+		h.Response.SetHeader(k, v)
+	}
+	h.Response.SetBody(body)
+	slog.Debug("Replayed HTTP fixture", "seq", f.Seq, "url", f.URL)
+}
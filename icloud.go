@@ -116,6 +116,12 @@ func (s *ICloudStorage) SyncExternalFile(relPath string) {
 func (s *ICloudStorage) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if compactDue(s.state.CompactedAt) {
+		if n := compactSyncFiles(s.state.Files, s.local.root, s.local.roots); n > 0 {
+			slog.Info("iCloud sync state compacted", "dropped", n)
+		}
+		s.state.CompactedAt = time.Now().UTC().Format(time.RFC3339)
+	}
 	statePath := filepath.Join(s.icloudRoot, syncStateFile)
 	if err := saveSyncState(statePath, s.state); err != nil {
 		return fmt.Errorf("save icloud sync state: %w", err)
@@ -145,6 +151,25 @@ func (s *ICloudStorage) TrackedSize() int64 {
 	return total
 }
 
+// ── Mirror ──────────────────────────────────────────────────────────────────
+
+// ICloudStorage doubles as a MultiStorage mirror. Its local side is the same
+// output directory, so PutFile copies from there.
+
+func (s *ICloudStorage) Name() string { return "icloud" }
+
+func (s *ICloudStorage) Put(relPath string, data []byte) error {
+	return s.writeToICloud(relPath, data)
+}
+
+func (s *ICloudStorage) PutFile(relPath, _ string) error {
+	return s.CopyFileToICloud(relPath)
+}
+
+func (s *ICloudStorage) Mkdir(relPath string) error {
+	return os.MkdirAll(filepath.Join(s.icloudRoot, relPath), 0o755)
+}
+
 // ── Internal ────────────────────────────────────────────────────────────────
 
 // writeToICloud conditionally writes data to the iCloud directory.
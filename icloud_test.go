@@ -508,3 +508,30 @@ func TestICloudStorage_CopyFileToICloud(t *testing.T) {
 		t.Fatalf("tracked files = %d, want 1", s.TrackedFiles())
 	}
 }
+
+func TestICloudStorage_CloseCompactsVanishedFiles(t *testing.T) {
+	localDir := t.TempDir()
+	icloudDir := t.TempDir()
+
+	s, err := NewICloudStorage(localDir, icloudDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"keep.txt", "drop.txt"} {
+		if err := s.WriteFile(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Remove(filepath.Join(localDir, "drop.txt"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	state := loadSyncState(filepath.Join(icloudDir, syncStateFile))
+	if len(state.Files) != 1 || state.Files["keep.txt"] == nil {
+		t.Fatalf("tracked = %v, want only keep.txt", state.Files)
+	}
+	if state.CompactedAt == "" {
+		t.Error("CompactedAt should be set")
+	}
+}
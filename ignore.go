@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ── Title Ignore Rules ──────────────────────────────────────────────────────
+//
+// --ignore-title-regex (repeatable) and --ignore-file keep noisy recurring
+// meetings out of the pipeline: a discovered meeting whose title matches any
+// rule is dropped right after discovery, before --max, a backfill plan,
+// --pick, or --search see it. The manifest counts what was dropped, per rule.
+// --id names a meeting explicitly and is never ignored. graindl gc still sees
+// ignored meetings, so their earlier exports are not treated as orphans.
+
+// titleRegexList is the repeatable --ignore-title-regex flag.
+type titleRegexList []string
+
+func (l *titleRegexList) String() string { return strings.Join(*l, ", ") }
+
+func (l *titleRegexList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// titleRule is one compiled ignore pattern.
+type titleRule struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// parseTitleRules compiles the --ignore-title-regex patterns followed by the
+// lines of the ignore file, if any. Blank lines and lines starting with #
+// are skipped in the file.
+func parseTitleRules(patterns []string, file string) ([]titleRule, error) {
+	var rules []titleRule
+	add := func(p, where string) error {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid ignore pattern %q%s: %w", p, where, err)
+		}
+		rules = append(rules, titleRule{pattern: p, re: re})
+		return nil
+	}
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p != "" {
+			if err := add(p, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if file == "" {
+		return rules, nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("ignore file: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := add(line, fmt.Sprintf(" (%s:%d)", file, n)); err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("ignore file: %w", err)
+	}
+	return rules, nil
+}
+
+// matchTitleRule returns the first rule matching title.
+func matchTitleRule(rules []titleRule, title string) (titleRule, bool) {
+	for _, r := range rules {
+		if r.re.MatchString(title) {
+			return r, true
+		}
+	}
+	return titleRule{}, false
+}
+
+// ignoreMeetings drops meetings whose title matches an ignore rule and
+// counts them, per rule, in the manifest.
+func (e *Exporter) ignoreMeetings(meetings []MeetingRef) []MeetingRef {
+	if len(e.cfg.IgnoreTitles) == 0 {
+		return meetings
+	}
+	kept := make([]MeetingRef, 0, len(meetings))
+	for _, m := range meetings {
+		rule, ok := matchTitleRule(e.cfg.IgnoreTitles, m.Title)
+		if !ok {
+			kept = append(kept, m)
+			continue
+		}
+		slog.Debug("Ignoring by title", "id", m.ID, "title", m.Title, "rule", rule.pattern)
+		if e.manifest.IgnoredBy == nil {
+			e.manifest.IgnoredBy = make(map[string]int)
+		}
+		e.manifest.IgnoredBy[rule.pattern]++
+		e.manifest.Ignored++
+	}
+	if n := len(meetings) - len(kept); n > 0 {
+		slog.Info("Ignored meetings by title", "count", n, "remaining", len(kept))
+	}
+	return kept
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTitleRules(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ignore.txt")
+	_ = os.WriteFile(file, []byte("# recurring noise\n\n  ^Lunch\n(?i)all[- ]hands\n"), 0o600)
+
+	rules, err := parseTitleRules([]string{"(?i)standup|1:1", " "}, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range rules {
+		got = append(got, r.pattern)
+	}
+	if want := "(?i)standup|1:1,^Lunch,(?i)all[- ]hands"; strings.Join(got, ",") != want {
+		t.Errorf("patterns = %v, want %s", got, want)
+	}
+
+	if _, err := parseTitleRules([]string{"(unclosed"}, ""); err == nil {
+		t.Error("invalid flag pattern: want error")
+	}
+	_ = os.WriteFile(file, []byte("ok\n[bad\n"), 0o600)
+	if _, err := parseTitleRules(nil, file); err == nil || !strings.Contains(err.Error(), "ignore.txt:2") {
+		t.Errorf("invalid file pattern: err = %v, want file:line", err)
+	}
+	if _, err := parseTitleRules(nil, filepath.Join(t.TempDir(), "nope")); err == nil {
+		t.Error("missing file: want error")
+	}
+}
+
+func TestIgnoreMeetings(t *testing.T) {
+	rules, _ := parseTitleRules([]string{"(?i)standup", "1:1"}, "")
+	e := &Exporter{cfg: &Config{IgnoreTitles: rules}, manifest: &ExportManifest{}}
+	kept := e.ignoreMeetings([]MeetingRef{
+		{ID: "a", Title: "Daily Standup"},
+		{ID: "b", Title: "Acme renewal"},
+		{ID: "c", Title: "Ana / Bo 1:1"},
+		{ID: "d", Title: "standup retro"},
+		{ID: "e"},
+	})
+	if len(kept) != 2 || kept[0].ID != "b" || kept[1].ID != "e" {
+		t.Errorf("kept = %v, want b, e", kept)
+	}
+	if e.manifest.Ignored != 3 || e.manifest.IgnoredBy["(?i)standup"] != 2 || e.manifest.IgnoredBy["1:1"] != 1 {
+		t.Errorf("ignored = %d by %v", e.manifest.Ignored, e.manifest.IgnoredBy)
+	}
+
+	// Without rules the list is passed through and nothing is counted.
+	e = &Exporter{cfg: &Config{}, manifest: &ExportManifest{}}
+	if kept := e.ignoreMeetings([]MeetingRef{{ID: "a", Title: "Daily Standup"}}); len(kept) != 1 || e.manifest.IgnoredBy != nil {
+		t.Errorf("no rules: kept %v, ignored by %v", kept, e.manifest.IgnoredBy)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Immutable Exports ───────────────────────────────────────────────────────
+//
+// --immutable is a legal-hold mode for archives that must not change once
+// written. Each meeting's artifacts are sealed read-only (0o400) when its
+// export finishes, and a sealed file is never replaced: ImmutableStorage
+// refuses the write no matter which flag asked for it, gc leaves sealed
+// files alone, and share will not edit a sealed note. Metadata records the
+// hold under "retention" (mode, locked_at, and retain_until with
+// --retention).
+//
+// Sealing is enforced by graindl and by file permissions on the local
+// archive only. The S3 mirror uploads meeting artifacts under Object Lock
+// (see s3mirror.go); the other mirrors receive the same files without a lock.
+
+// sealedPerm is the mode of sealed artifacts: owner read-only.
+const sealedPerm = 0o400
+
+// errImmutable is returned for a write that would replace a sealed file.
+var errImmutable = errors.New("sealed by --immutable, refusing to overwrite")
+
+// Retention is the legal-hold record in a meeting's metadata.
+type Retention struct {
+	Mode        string `json:"mode"`                   // "immutable"
+	LockedAt    string `json:"locked_at"`              // RFC 3339
+	RetainUntil string `json:"retain_until,omitempty"` // RFC 3339; empty = indefinite
+}
+
+// sealed reports whether the file at path was sealed: it exists and has no
+// owner write permission.
+func sealed(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o200 == 0
+}
+
+// ImmutableStorage wraps a Storage and refuses writes over sealed files.
+type ImmutableStorage struct {
+	Storage
+}
+
+// NewImmutableStorage returns s with overwrites of sealed files refused.
+func NewImmutableStorage(s Storage) *ImmutableStorage {
+	return &ImmutableStorage{Storage: s}
+}
+
+func (s *ImmutableStorage) WriteFile(relPath string, data []byte) error {
+	if sealed(s.AbsPath(relPath)) {
+		return fmt.Errorf("%s: %w", relPath, errImmutable)
+	}
+	return s.Storage.WriteFile(relPath, data)
+}
+
+func (s *ImmutableStorage) WriteJSON(relPath string, v any) error {
+	if sealed(s.AbsPath(relPath)) {
+		return fmt.Errorf("%s: %w", relPath, errImmutable)
+	}
+	return s.Storage.WriteJSON(relPath, v)
+}
+
+func (s *ImmutableStorage) WriteStream(relPath string, render func(w io.Writer) error) error {
+	if sealed(s.AbsPath(relPath)) {
+		return fmt.Errorf("%s: %w", relPath, errImmutable)
+	}
+	return writeStreamed(s.Storage, relPath, render)
+}
+
+// BackendStatus forwards to the wrapped storage's mirrors, if any.
+func (s *ImmutableStorage) BackendStatus(paths []string) map[string]string {
+	if br, ok := s.Storage.(backendReporter); ok {
+		return br.BackendStatus(paths)
+	}
+	return nil
+}
+
+// seal makes relPaths read-only. Empty paths and missing files are
+// skipped; failures are logged and counted.
+func seal(storage Storage, relPaths []string) int {
+	failed := 0
+	for _, rel := range relPaths {
+		if rel == "" {
+			continue
+		}
+		if err := os.Chmod(storage.AbsPath(rel), sealedPerm); err != nil && !os.IsNotExist(err) {
+			slog.Warn("Seal failed", "path", rel, "error", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// retention returns the hold to record in a new export's metadata, or nil
+// without --immutable.
+func (e *Exporter) retention(now time.Time) *Retention {
+	if !e.cfg.Immutable {
+		return nil
+	}
+	r := &Retention{Mode: "immutable", LockedAt: now.UTC().Format(time.RFC3339)}
+	if !e.cfg.RetainUntil.IsZero() {
+		r.RetainUntil = e.cfg.RetainUntil.UTC().Format(time.RFC3339)
+	}
+	return r
+}
+
+var retentionRe = regexp.MustCompile(`^(\d+)([dwy])$`)
+
+// parseRetention converts a --retention value into the end of the hold.
+// Accepts whole days, weeks, or years ("90d", "12w", "7y") from now, or a
+// date ("2032-12-31").
+func parseRetention(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if m := retentionRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n > 0 {
+			switch m[2] {
+			case "y":
+				return now.AddDate(n, 0, 0), nil
+			case "w":
+				return now.AddDate(0, 0, 7*n), nil
+			default:
+				return now.AddDate(0, 0, n), nil
+			}
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil && t.After(now) {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --retention %q (use e.g. 90d, 7y, or a future date like 2032-12-31)", s)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportOneImmutable(t *testing.T) {
+	dir := t.TempDir()
+	until := time.Date(2032, 12, 31, 0, 0, 0, 0, time.UTC)
+	cfg := &Config{OutputDir: dir, SkipVideo: true, MaxDelaySec: 0.01, Immutable: true, RetainUntil: until}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	ref := MeetingRef{ID: "held", Title: "Board Call", Date: "2025-06-01T10:00:00Z"}
+
+	r := e.exportOne(context.Background(), ref)
+	if r.Status != "ok" {
+		t.Fatalf("status = %q (%s)", r.Status, r.ErrorMsg)
+	}
+	metaPath := filepath.Join(dir, r.MetadataPath)
+	info, err := os.Stat(metaPath)
+	if err != nil || info.Mode().Perm() != sealedPerm {
+		t.Fatalf("metadata perms = %v, %v; want 0400", info, err)
+	}
+	meta, err := readArchiveMetadata(metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Retention == nil || meta.Retention.Mode != "immutable" || meta.Retention.LockedAt == "" || meta.Retention.RetainUntil != "2032-12-31T00:00:00Z" {
+		t.Errorf("retention = %+v", meta.Retention)
+	}
+
+	// --overwrite is ignored: the meeting is skipped and the file untouched.
+	before, _ := os.ReadFile(metaPath)
+	cfg.Overwrite = true
+	if r := e.exportOne(context.Background(), ref); r.Status != "skipped" {
+		t.Errorf("re-export status = %q, want skipped", r.Status)
+	}
+	if after, _ := os.ReadFile(metaPath); string(after) != string(before) {
+		t.Error("sealed metadata was rewritten")
+	}
+}
+
+func TestImmutableStorageRefusesSealed(t *testing.T) {
+	dir := t.TempDir()
+	s := NewImmutableStorage(NewLocalStorage(dir))
+
+	if err := s.WriteFile("a.txt", []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteFile("a.txt", []byte("two")); err != nil {
+		t.Fatalf("unsealed rewrite: %v", err)
+	}
+	if failed := seal(s, []string{"a.txt", "", "missing.txt"}); failed != 0 {
+		t.Errorf("seal failures = %d", failed)
+	}
+	if err := s.WriteFile("a.txt", []byte("three")); !errors.Is(err, errImmutable) {
+		t.Errorf("WriteFile over sealed = %v", err)
+	}
+	if err := s.WriteJSON("a.txt", map[string]int{}); !errors.Is(err, errImmutable) {
+		t.Errorf("WriteJSON over sealed = %v", err)
+	}
+	if err := writeStreamed(s, "a.txt", func(w io.Writer) error { return nil }); !errors.Is(err, errImmutable) {
+		t.Errorf("streamed write over sealed = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(got) != "two" {
+		t.Errorf("content = %q", got)
+	}
+	if s.BackendStatus([]string{"a.txt"}) != nil {
+		t.Error("local-only storage should report no backends")
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for in, want := range map[string]time.Time{
+		"90d":        now.AddDate(0, 0, 90),
+		"12w":        now.AddDate(0, 0, 84),
+		"7y":         now.AddDate(7, 0, 0),
+		"2032-12-31": time.Date(2032, 12, 31, 0, 0, 0, 0, time.UTC),
+	} {
+		got, err := parseRetention(in, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseRetention(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0d", "7", "7m", "forever", "2020-01-01"} {
+		if _, err := parseRetention(in, now); err == nil {
+			t.Errorf("parseRetention(%q) should fail", in)
+		}
+	}
+}
+
+func TestSealedFilesSurviveGCAndShare(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveFile(t, dir, "2025-01-15/gone.transcript.txt", "bye", 0)
+	writeArchiveFile(t, dir, "2025-01-15/gone.md", "---\ngrain_id: gone\n---\n", 0)
+	seal(NewLocalStorage(dir), []string{"2025-01-15/gone.transcript.txt", "2025-01-15/gone.md"})
+
+	items, err := findOrphans(dir, nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 0 {
+		t.Errorf("sealed files reported as orphans: %v", items)
+	}
+
+	err = appendShareSection(filepath.Join(dir, "2025-01-15/gone.md"), []sharedLink{{Label: "Video", URL: "https://example.com"}}, time.Now())
+	if !errors.Is(err, errImmutable) {
+		t.Errorf("share on sealed note = %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// ── Integrity ───────────────────────────────────────────────────────────────
+//
+// Every artifact an export writes is hashed once it is complete, and its
+// SHA-256 goes into the meeting's manifest entry and into checksumFile. The
+// manifest only lists the meetings of the last run, so checksumFile is the
+// archive-wide record: `graindl verify-local` re-hashes every file in it and
+// reports the ones that are missing or no longer match — bit rot on a NAS,
+// a truncated copy, a file edited by hand. Commands that rewrite or remove
+// artifacts (hls-convert, relink, gc, --gdrive-clean-local) keep the record
+// current.
+
+// checksumFile records the SHA-256 of every exported artifact. Hidden, like
+// the other state files, so mirrors and Drive sync leave it alone.
+const checksumFile = ".graindl-checksums.json"
+
+// Verification outcomes.
+const (
+	verifyOK        = "ok"
+	verifyMissing   = "missing"
+	verifyCorrupted = "corrupted"
+)
+
+// ChecksumState is the persisted record of artifact checksums.
+type ChecksumState struct {
+	Files map[string]*FileChecksum `json:"files"` // stored path (relative to the output dir) →
+}
+
+// FileChecksum is one artifact's recorded hash.
+type FileChecksum struct {
+	SHA256   string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	HashedAt time.Time `json:"hashed_at"`
+}
+
+// checksumMu serializes read-modify-write of the checksum state between
+// --parallel workers.
+var checksumMu sync.Mutex
+
+// loadChecksums reads the checksum state, falling back to its backup. A
+// missing file yields an empty state.
+func loadChecksums(outputDir string) (*ChecksumState, error) {
+	st := &ChecksumState{}
+	err := readStateFile(filepath.Join(outputDir, checksumFile), func(data []byte) error {
+		st = &ChecksumState{}
+		return json.Unmarshal(data, st)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if st.Files == nil {
+		st.Files = map[string]*FileChecksum{}
+	}
+	return st, nil
+}
+
+// hashFile returns the hex SHA-256 and size of the file at path.
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// updateChecksums hashes the files at rehash, forgets the ones at forget
+// (both relative to outputDir), and saves the state. Files in rehash that
+// no longer exist are forgotten too. It returns the new hashes by path.
+func updateChecksums(outputDir string, rehash, forget []string) (map[string]string, error) {
+	now := time.Now().UTC()
+	sums := map[string]*FileChecksum{}
+	for _, rel := range rehash {
+		if rel == "" {
+			continue
+		}
+		sum, size, err := hashFile(filepath.Join(outputDir, rel))
+		if errors.Is(err, os.ErrNotExist) {
+			forget = append(forget, rel)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hash %s: %w", rel, err)
+		}
+		sums[filepath.Clean(rel)] = &FileChecksum{SHA256: sum, Size: size, HashedAt: now}
+	}
+
+	checksumMu.Lock()
+	defer checksumMu.Unlock()
+	st, err := loadChecksums(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", checksumFile, err)
+	}
+	for _, rel := range forget {
+		if rel != "" {
+			delete(st.Files, filepath.Clean(rel))
+		}
+	}
+	hashes := make(map[string]string, len(sums))
+	for rel, c := range sums {
+		st.Files[rel] = c
+		hashes[rel] = c.SHA256
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeStateFile(filepath.Join(outputDir, checksumFile), data); err != nil {
+		return nil, fmt.Errorf("write %s: %w", checksumFile, err)
+	}
+	return hashes, nil
+}
+
+// recordChecksums hashes r's artifacts into r.Checksums and the checksum
+// state. Paths must already be the stored ones (see noteCompressed). A
+// failure is logged; it never fails the export or import.
+func recordChecksums(outputDir string, r *ExportResult) {
+	paths := slices.DeleteFunc(collectResultPaths(r), func(p string) bool { return p == "" })
+	if len(paths) == 0 {
+		return
+	}
+	hashes, err := updateChecksums(outputDir, paths, nil)
+	if err != nil {
+		slog.Warn("Checksum recording failed", "id", r.ID, "error", err)
+		return
+	}
+	if len(hashes) > 0 {
+		r.Checksums = hashes
+	}
+}
+
+// rehashTracked re-hashes the files among paths that the checksum state
+// already records, leaving manifests and state files untracked.
+func rehashTracked(outputDir string, paths []string) error {
+	checksumMu.Lock()
+	st, err := loadChecksums(outputDir)
+	checksumMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("read %s: %w", checksumFile, err)
+	}
+	tracked := slices.DeleteFunc(slices.Clone(paths), func(p string) bool { return st.Files[filepath.Clean(p)] == nil })
+	if len(tracked) == 0 {
+		return nil
+	}
+	_, err = updateChecksums(outputDir, tracked, nil)
+	return err
+}
+
+// forgetChecksums drops paths from the checksum state, logging a failure.
+func forgetChecksums(outputDir string, paths []string) {
+	if _, err := updateChecksums(outputDir, nil, paths); err != nil {
+		slog.Warn("Checksum state update failed", "error", err)
+	}
+}
+
+// VerifyItem is one artifact checked by verify-local.
+type VerifyItem struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // ok, missing, corrupted
+	Detail string `json:"detail,omitempty"`
+}
+
+// verifyChecksums re-hashes every file recorded in outputDir's checksum
+// state, sorted by path.
+func verifyChecksums(outputDir string) ([]VerifyItem, error) {
+	checksumMu.Lock()
+	st, err := loadChecksums(outputDir)
+	checksumMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", checksumFile, err)
+	}
+	items := make([]VerifyItem, 0, len(st.Files))
+	for _, rel := range slices.Sorted(maps.Keys(st.Files)) {
+		want := st.Files[rel]
+		it := VerifyItem{Path: rel, Status: verifyOK}
+		sum, size, err := hashFile(filepath.Join(outputDir, rel))
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			it.Status = verifyMissing
+		case err != nil:
+			it.Status, it.Detail = verifyCorrupted, err.Error()
+		case size != want.Size:
+			it.Status, it.Detail = verifyCorrupted, fmt.Sprintf("size %d, recorded %d", size, want.Size)
+		case sum != want.SHA256:
+			it.Status, it.Detail = verifyCorrupted, "sha256 mismatch"
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+func runVerifyLocal(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("verify-local", flag.ContinueOnError)
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to verify")
+	format := fs.String("format", "text", "Output format: text (problems and a summary), json (every file)")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	asJSON := false
+	switch strings.ToLower(*format) {
+	case "text":
+	case "json":
+		asJSON = true
+	default:
+		slog.Error("Invalid --format (must be text or json)", "value", *format)
+		return 2
+	}
+	if _, err := os.Stat(*outputDir); err != nil {
+		slog.Error("Archive directory not found", "path", *outputDir)
+		return 1
+	}
+	if err := checkUnrouted("verify-local", *outputDir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+
+	items, err := verifyChecksums(*outputDir)
+	if err != nil {
+		slog.Error("verify-local failed", "error", err)
+		return 1
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(items)
+	} else {
+		err = writeVerifyText(os.Stdout, items)
+	}
+	if err != nil {
+		slog.Error("verify-local failed", "error", err)
+		return 1
+	}
+	for _, it := range items {
+		if it.Status != verifyOK {
+			return 1
+		}
+	}
+	return 0
+}
+
+// writeVerifyText prints the files that failed verification and a summary.
+func writeVerifyText(w io.Writer, items []VerifyItem) error {
+	counts := map[string]int{}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, it := range items {
+		counts[it.Status]++
+		if it.Status != verifyOK {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(it.Status), it.Path, it.Detail)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "verify-local: %d file(s) checked, %d ok, %d missing, %d corrupted\n",
+		len(items), counts[verifyOK], counts[verifyMissing], counts[verifyCorrupted])
+	return err
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordChecksums(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "2025-06-01"), 0o755)
+	meta := filepath.Join("2025-06-01", "m1.json")
+	os.WriteFile(filepath.Join(dir, meta), []byte(`{"id":"m1"}`), 0o600)
+
+	r := &ExportResult{ID: "m1", MetadataPath: meta, VideoPath: filepath.Join("2025-06-01", "m1.mp4")}
+	recordChecksums(dir, r)
+	sum := sha256.Sum256([]byte(`{"id":"m1"}`))
+	if len(r.Checksums) != 1 || r.Checksums[meta] != hex.EncodeToString(sum[:]) {
+		t.Fatalf("checksums = %v", r.Checksums)
+	}
+	st, err := loadChecksums(dir)
+	if err != nil || st.Files[meta] == nil || st.Files[meta].Size != 11 {
+		t.Fatalf("state = %+v, %v", st, err)
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"a.json": "alpha", "b.md": "bravo", "c.mp4": "charlie", "d.txt": "delta"}
+	var paths []string
+	for name, data := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600)
+		paths = append(paths, name)
+	}
+	if _, err := updateChecksums(dir, paths, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "b.md"), []byte("BRAVO"), 0o600) // same size, flipped bits
+	os.WriteFile(filepath.Join(dir, "c.mp4"), []byte("char"), 0o600) // truncated
+	os.Remove(filepath.Join(dir, "d.txt"))
+
+	items, err := verifyChecksums(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, it := range items {
+		got[it.Path] = it.Status
+	}
+	want := map[string]string{"a.json": verifyOK, "b.md": verifyCorrupted, "c.mp4": verifyCorrupted, "d.txt": verifyMissing}
+	for p, s := range want {
+		if got[p] != s {
+			t.Errorf("%s: status %q, want %q", p, got[p], s)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeVerifyText(&out, items); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "a.json") || !strings.Contains(out.String(), "4 file(s) checked, 1 ok, 1 missing, 2 corrupted") {
+		t.Errorf("text output:\n%s", out.String())
+	}
+}
+
+func TestChecksumUpkeep(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"m1.json", "m1.url", "_export-manifest.json"} {
+		os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600)
+	}
+	if _, err := updateChecksums(dir, []string{"m1.json", "m1.url"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// relink: only tracked files are rehashed.
+	os.WriteFile(filepath.Join(dir, "m1.json"), []byte("relinked"), 0o600)
+	if err := rehashTracked(dir, []string{"m1.json", "_export-manifest.json"}); err != nil {
+		t.Fatal(err)
+	}
+	// hls-convert: the URL file is replaced by the MP4.
+	os.WriteFile(filepath.Join(dir, "m1.mp4"), []byte("video"), 0o600)
+	if _, err := updateChecksums(dir, []string{"m1.mp4"}, []string{"m1.url"}); err != nil {
+		t.Fatal(err)
+	}
+
+	st, _ := loadChecksums(dir)
+	if len(st.Files) != 2 || st.Files["m1.json"] == nil || st.Files["m1.mp4"] == nil {
+		t.Fatalf("tracked = %v", st.Files)
+	}
+	items, _ := verifyChecksums(dir)
+	for _, it := range items {
+		if it.Status != verifyOK {
+			t.Errorf("%s: %s %s", it.Path, it.Status, it.Detail)
+		}
+	}
+
+	forgetChecksums(dir, []string{"m1.mp4"})
+	if st, _ := loadChecksums(dir); len(st.Files) != 1 {
+		t.Errorf("after forget: %v", st.Files)
+	}
+}
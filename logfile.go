@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── Log File Rotation ───────────────────────────────────────────────────────
+//
+// --log-file mirrors all log output to a file, for watch daemons running
+// without Docker's log driver or an external logrotate. The file is rotated
+// when it would grow past --log-max-size or when the clock enters a new
+// --log-rotate period (e.g. each UTC day for 24h), whichever comes first.
+// Rotated files are renamed <file>.<YYYYMMDD-HHMMSS>; only the newest
+// --log-keep of them are retained. The file gets plain text (or JSON with
+// --log-format json), never ANSI colors, and the same redaction as stderr.
+
+// rotatingFile is an io.Writer over a size- and time-rotated log file. It
+// is safe for concurrent use.
+type rotatingFile struct {
+	path    string
+	maxSize int64         // bytes; 0 = no size limit
+	period  time.Duration // rotate on period boundaries; 0 = never
+	keep    int           // rotated files to retain; 0 = keep all
+	now     func() time.Time
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	opened time.Time // start of the current file's contents
+}
+
+func newRotatingFile(path string, maxSize int64, period time.Duration, keep int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, period: period, keep: keep, now: time.Now}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens (or creates) the log file for appending. An existing file's
+// mtime stands in for when its period started, so a daemon restarted the
+// next day still rotates yesterday's log.
+func (r *rotatingFile) open() error {
+	if err := ensureDirPrivate(filepath.Dir(r.path)); err != nil {
+		return fmt.Errorf("log dir: %w", err)
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	r.f, r.size, r.opened = f, info.Size(), r.now()
+	if info.Size() > 0 {
+		r.opened = info.ModTime()
+	}
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.due(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			// Keep logging to the current file rather than losing lines.
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+			if r.f == nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// due reports whether writing n more bytes should first rotate the file.
+func (r *rotatingFile) due(n int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxSize > 0 && r.size+n > r.maxSize {
+		return true
+	}
+	return r.period > 0 && !r.now().Truncate(r.period).Equal(r.opened.Truncate(r.period))
+}
+
+// rotate renames the current file aside, reopens a fresh one, and prunes
+// old rotations.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	r.f = nil
+	stamp := r.now().Format("20060102-150405")
+	dest := r.path + "." + stamp
+	for i := 1; fileExists(dest); i++ {
+		dest = fmt.Sprintf("%s.%s-%d", r.path, stamp, i)
+	}
+	renameErr := os.Rename(r.path, dest)
+	if err := r.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	r.opened = r.now()
+	r.prune()
+	return nil
+}
+
+// prune removes all but the newest keep rotated files.
+func (r *rotatingFile) prune() {
+	if r.keep <= 0 {
+		return
+	}
+	old, err := filepath.Glob(globEscape(r.path) + ".[0-9]*")
+	if err != nil || len(old) <= r.keep {
+		return
+	}
+	sort.Strings(old) // timestamps sort chronologically
+	for _, p := range old[:len(old)-r.keep] {
+		_ = os.Remove(p)
+	}
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// globEscape escapes glob metacharacters in a literal path.
+func globEscape(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		if strings.ContainsRune(`*?[\`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// parseByteSize parses sizes like "10MB", "512k", "1G", or "1048576".
+// Units are binary (1 KB = 1024 bytes).
+func parseByteSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 10MB, 512KB)", size)
+	}
+	return n * mult, nil
+}
+
+// ── Log Fan-out ─────────────────────────────────────────────────────────────
+
+// logFileHandler receives every record in addition to the console handler
+// once --log-file is set; nil otherwise.
+var logFileHandler slog.Handler
+
+// openLogFile starts the rotating log file and routes the default logger to
+// it as well. The returned file must be closed on exit.
+func openLogFile(cfg *Config) (*rotatingFile, error) {
+	rf, err := newRotatingFile(cfg.LogFile, cfg.LogMaxSize, cfg.LogRotate, cfg.LogKeep)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if cfg.Verbose {
+		opts.Level = slog.LevelDebug
+	}
+	var h slog.Handler = slog.NewTextHandler(rf, opts)
+	if strings.ToLower(cfg.LogFormat) == "json" {
+		h = slog.NewJSONHandler(rf, opts)
+	}
+	logFileHandler = newRedactHandler(h)
+	slog.SetDefault(slog.New(withLogFile(slog.Default().Handler())))
+	return rf, nil
+}
+
+// withLogFile returns h, fanned out to the log file when one is open.
+func withLogFile(h slog.Handler) slog.Handler {
+	if logFileHandler == nil {
+		return h
+	}
+	return multiHandler{h, logFileHandler}
+}
+
+// multiHandler sends each record to every handler that accepts its level.
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, l) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	out := make(multiHandler, len(m))
+	for i, h := range m {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{
+		"10MB": 10 << 20, "512k": 512 << 10, "1G": 1 << 30, "1048576": 1 << 20, "0": 0, " 2 mb ": 2 << 20,
+	} {
+		got, err := parseByteSize(in)
+		if err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "MB", "-1MB", "ten"} {
+		if _, err := parseByteSize(bad); err == nil {
+			t.Errorf("parseByteSize(%q): expected error", bad)
+		}
+	}
+}
+
+func rotatedFiles(t *testing.T, path string) []string {
+	t.Helper()
+	m, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "graindl.log")
+	rf, err := newRotatingFile(path, 10, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rf.Close() })
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time { return now }
+
+	for _, line := range []string{"aaaaaa\n", "bbbbbb\n", "cccccc\n", "dddddd\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "dddddd\n" {
+		t.Errorf("current file = %q, want only the last line", data)
+	}
+	// Three rotations in the same second, pruned to the newest two.
+	old := rotatedFiles(t, path)
+	if len(old) != 2 {
+		t.Fatalf("rotated files = %v, want 2 kept", old)
+	}
+	if got, _ := os.ReadFile(old[len(old)-1]); string(got) != "cccccc\n" {
+		t.Errorf("newest rotation = %q", got)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("log perm = %o, want 600", info.Mode().Perm())
+	}
+}
+
+func TestRotatingFilePeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graindl.log")
+	// Yesterday's log left by a previous run rotates on the first write.
+	if err := os.WriteFile(path, []byte("yesterday\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Date(2025, 1, 14, 23, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, yesterday, yesterday); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 15, 0, 30, 0, 0, time.UTC)
+	rf, err := newRotatingFile(path, 0, 24*time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rf.Close() })
+	rf.now = func() time.Time { return now }
+
+	rf.Write([]byte("today 1\n"))
+	now = now.Add(20 * time.Hour)
+	rf.Write([]byte("today 2\n"))
+	now = now.Add(5 * time.Hour) // next day
+	rf.Write([]byte("tomorrow\n"))
+
+	old := rotatedFiles(t, path)
+	if len(old) != 2 {
+		t.Fatalf("rotated files = %v, want 2", old)
+	}
+	if got, _ := os.ReadFile(old[1]); string(got) != "today 1\ntoday 2\n" {
+		t.Errorf("second rotation = %q", got)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "tomorrow\n" {
+		t.Errorf("current = %q", got)
+	}
+}
+
+func TestOpenLogFileFanOut(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev); logFileHandler = nil })
+
+	var console bytes.Buffer
+	slog.SetDefault(slog.New(newRedactHandler(NewColorHandler(&console, slog.LevelInfo))))
+
+	path := filepath.Join(t.TempDir(), "graindl.log")
+	rf, err := openLogFile(&Config{LogFile: path, LogMaxSize: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	slog.Info("cycle done", "error", "Authorization: Bearer s3cret")
+	slog.Debug("not at info level")
+
+	data, _ := os.ReadFile(path)
+	file := string(data)
+	if !strings.Contains(file, "cycle done") || !strings.Contains(console.String(), "cycle done") {
+		t.Errorf("record missing: file=%q console=%q", file, console.String())
+	}
+	if strings.Contains(file, "\033[") {
+		t.Errorf("ANSI codes in log file: %q", file)
+	}
+	if strings.Contains(file+console.String(), "s3cret") {
+		t.Error("secret reached a log sink")
+	}
+	if strings.Contains(file, "not at info level") {
+		t.Error("debug record written at info level")
+	}
+}
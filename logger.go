@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -27,10 +28,12 @@ type ColorHandler struct {
 	mu    sync.Mutex
 	attrs []slog.Attr
 	group string
+
+	meetings *meetingLogs // per-meeting prefixes and grouping; nil disables
 }
 
 func NewColorHandler(w io.Writer, level slog.Level) *ColorHandler {
-	return &ColorHandler{w: w, level: level}
+	return &ColorHandler{w: w, level: level, meetings: logMeetings}
 }
 
 func (h *ColorHandler) Enabled(_ context.Context, l slog.Level) bool {
@@ -50,15 +53,20 @@ func (h *ColorHandler) Handle(_ context.Context, r slog.Record) error {
 		prefix, color = " ", cDim
 	}
 
+	// Collect all attrs: inherited + record-level.
+	attrs := h.collectAttrs(r)
+
 	var b strings.Builder
+	ml := h.meetings.lookup(attrs)
+	if ml != nil {
+		b.WriteString(ml.prefix)
+	}
 	b.WriteString(color)
 	b.WriteString(prefix)
 	b.WriteByte(' ')
 	b.WriteString(r.Message)
 	b.WriteString(cReset)
 
-	// Collect all attrs: inherited + record-level.
-	attrs := h.collectAttrs(r)
 	if len(attrs) > 0 {
 		b.WriteString(cDim)
 		for _, a := range attrs {
@@ -66,10 +74,14 @@ func (h *ColorHandler) Handle(_ context.Context, r slog.Record) error {
 		}
 		b.WriteString(cReset)
 	}
+	b.WriteByte('\n')
 
+	if h.meetings.hold(ml, h.w, b.String()) {
+		return nil
+	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	_, err := fmt.Fprintln(h.w, b.String())
+	_, err := io.WriteString(h.w, b.String())
 	return err
 }
 
@@ -106,6 +118,8 @@ func (h *ColorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		level: h.level,
 		attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
 		group: h.group,
+
+		meetings: h.meetings,
 	}
 }
 
@@ -119,5 +133,125 @@ func (h *ColorHandler) WithGroup(name string) slog.Handler {
 		level: h.level,
 		attrs: h.attrs,
 		group: newGroup,
+
+		meetings: h.meetings,
+	}
+}
+
+// ── Meeting Prefixes ────────────────────────────────────────────────────────
+//
+// With --parallel, lines from concurrent meetings interleave. Each worker
+// registers its meeting while exporting it, and ColorHandler prefixes every
+// line whose "id" attribute names a registered meeting with the worker's
+// color and the meeting's short ID. A worker keeps its slot, and so its
+// color, for as long as it runs. With --log-group-by-meeting those lines
+// are held back instead and written as one block when the meeting finishes.
+// Lines without an "id" (run-level progress, summaries) print as usual.
+
+// workerColors are the prefix colors, one per worker slot; red, yellow, and
+// green stay reserved for levels.
+var workerColors = []string{
+	"\033[36m", "\033[35m", "\033[34m", "\033[96m",
+	"\033[95m", "\033[94m", "\033[37m", "\033[90m",
+}
+
+// shortIDLen is how much of a meeting ID the prefix shows.
+const shortIDLen = 8
+
+// logMeetings is the registry the console handler consults.
+var logMeetings = &meetingLogs{}
+
+type meetingLogs struct {
+	mu     sync.Mutex
+	group  bool                   // --log-group-by-meeting
+	active map[string]*meetingLog // meeting ID →
+	slots  []bool                 // worker slots in use
+}
+
+type meetingLog struct {
+	slot   int
+	prefix string
+	w      io.Writer
+	lines  []string // held lines in grouping mode
+	done   bool     // finished; later lines print directly
+}
+
+// start registers id as exported by a worker and returns the function that
+// finishes it, flushing any held lines. It is safe on a nil registry.
+func (m *meetingLogs) start(id string) (finish func()) {
+	if m == nil || id == "" {
+		return func() {}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active == nil {
+		m.active = map[string]*meetingLog{}
+	}
+	if m.active[id] != nil {
+		return func() {}
+	}
+	slot := slices.Index(m.slots, false)
+	if slot < 0 {
+		slot = len(m.slots)
+		m.slots = append(m.slots, false)
+	}
+	m.slots[slot] = true
+	short := id
+	if len(short) > shortIDLen {
+		short = short[:shortIDLen]
+	}
+	m.active[id] = &meetingLog{
+		slot:   slot,
+		prefix: fmt.Sprintf("%s[%d·%s]%s ", workerColors[slot%len(workerColors)], slot+1, short, cReset),
+	}
+	return func() { m.finish(id) }
+}
+
+// finish unregisters id and writes its held lines as one block.
+func (m *meetingLogs) finish(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ml := m.active[id]
+	if ml == nil {
+		return
+	}
+	delete(m.active, id)
+	m.slots[ml.slot] = false
+	ml.done = true
+	if len(ml.lines) > 0 {
+		_, _ = io.WriteString(ml.w, strings.Join(ml.lines, ""))
+	}
+}
+
+// lookup returns the registered meeting named by attrs' "id", if any.
+func (m *meetingLogs) lookup(attrs []slog.Attr) *meetingLog {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.active) == 0 {
+		return nil
+	}
+	for _, a := range attrs {
+		if a.Key == "id" {
+			return m.active[a.Value.String()]
+		}
+	}
+	return nil
+}
+
+// hold buffers line for ml when grouping and reports whether it did.
+func (m *meetingLogs) hold(ml *meetingLog, w io.Writer, line string) bool {
+	if m == nil || ml == nil {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.group || ml.done {
+		return false
 	}
+	ml.w = w
+	ml.lines = append(ml.lines, line)
+	return true
 }
@@ -99,3 +99,71 @@ func TestColorHandlerEnabled(t *testing.T) {
 		t.Error("error should be enabled")
 	}
 }
+
+func TestColorHandlerMeetingPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	m := &meetingLogs{}
+	h := NewColorHandler(&buf, slog.LevelInfo)
+	h.meetings = m
+	logger := slog.New(h)
+
+	finishA := m.start("aaaaaaaa-1111")
+	finishB := m.start("bbbbbbbb-2222")
+	logger.Info("downloading", "id", "aaaaaaaa-1111")
+	logger.With("id", "bbbbbbbb-2222").Warn("slow")
+	logger.Info("run-level line")
+	out := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.Contains(out[0], workerColors[0]+"[1·aaaaaaaa]") {
+		t.Errorf("worker 1 prefix missing: %q", out[0])
+	}
+	if !strings.Contains(out[1], workerColors[1]+"[2·bbbbbbbb]") {
+		t.Errorf("worker 2 prefix missing: %q", out[1])
+	}
+	if strings.Contains(out[2], "·") {
+		t.Errorf("line without id got a prefix: %q", out[2])
+	}
+
+	// A freed slot is reused, so a worker keeps its color.
+	finishA()
+	defer m.start("cccccccc-3333")()
+	buf.Reset()
+	logger.Info("next", "id", "cccccccc-3333")
+	if !strings.Contains(buf.String(), workerColors[0]+"[1·cccccccc]") {
+		t.Errorf("slot not reused: %q", buf.String())
+	}
+	finishB()
+	buf.Reset()
+	logger.Info("late", "id", "bbbbbbbb-2222")
+	if strings.Contains(buf.String(), "[2·") {
+		t.Errorf("finished meeting still prefixed: %q", buf.String())
+	}
+}
+
+func TestColorHandlerGroupByMeeting(t *testing.T) {
+	var buf bytes.Buffer
+	m := &meetingLogs{group: true}
+	h := NewColorHandler(&buf, slog.LevelInfo)
+	h.meetings = m
+	logger := slog.New(h)
+
+	finishA := m.start("a")
+	finishB := m.start("b")
+	logger.Info("a1", "id", "a")
+	logger.Info("b1", "id", "b")
+	logger.Info("a2", "id", "a")
+	logger.Info("run-level")
+	if got := buf.String(); !strings.Contains(got, "run-level") || strings.Contains(got, "a1") {
+		t.Fatalf("meeting lines not held: %q", got)
+	}
+	finishB()
+	finishA()
+	got := buf.String()
+	ia1, ia2, ib1 := strings.Index(got, "a1"), strings.Index(got, "a2"), strings.Index(got, "b1")
+	if ib1 < 0 || ia1 < ib1 || ia2 < ia1 {
+		t.Errorf("want run-level, then b's block, then a's block: %q", got)
+	}
+	logger.Info("a3", "id", "a")
+	if !strings.Contains(buf.String(), "a3") {
+		t.Error("line after finish was held")
+	}
+}
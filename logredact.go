@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// ── Log Redaction ───────────────────────────────────────────────────────────
+//
+// Error strings sometimes carry a URL with a signed query, an OAuth
+// exchange body, or a response snippet. Every log handler (color, JSON,
+// TUI) is wrapped in a redactHandler that scrubs bearer/basic credentials,
+// Authorization and Cookie headers, OAuth codes and tokens, JWTs, and any
+// secret-looking URL parameter or JSON field before a record is written.
+// Redaction applies to the message, string attributes, errors, and any
+// other value whose printed form contains a secret. The same scrubbing is
+// applied to error messages recorded in the export manifest.
+
+var (
+	// logBearerRe matches credentials following an auth scheme.
+	logBearerRe = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/-]+=*`)
+	// logHeaderRe matches header-style auth/cookie lines to end of line.
+	logHeaderRe = regexp.MustCompile(`(?i)\b((?:proxy-)?authorization|(?:set-)?cookie)(\s*[:=]\s*)[^\r\n]+`)
+	// logParamRe matches key=value pairs in URLs, form bodies, and text.
+	logParamRe = regexp.MustCompile(`(^|[?&;#\s])([A-Za-z0-9_.-]+)=([^&#\s"'<>]+)`)
+	// logJSONRe matches "key": "value" pairs.
+	logJSONRe = regexp.MustCompile(`"([^"\\]+)"(\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	// logGoogleTokenRe matches Google OAuth access and refresh tokens.
+	logGoogleTokenRe = regexp.MustCompile(`\bya29\.[0-9A-Za-z_-]+|\b1//[0-9A-Za-z_-]{20,}`)
+)
+
+// isSecretLogKey reports whether a parameter or attribute name holds a
+// credential. OAuth authorization codes are named just "code".
+func isSecretLogKey(k string) bool {
+	return sensitiveKeyRe.MatchString(k) || strings.EqualFold(k, "code")
+}
+
+// redactSecrets masks credentials embedded in s.
+func redactSecrets(s string) string {
+	if s == "" {
+		return s
+	}
+	s = logJSONRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := logJSONRe.FindStringSubmatch(m)
+		if !isSecretLogKey(sub[1]) {
+			return m
+		}
+		return `"` + sub[1] + `"` + sub[2] + `"` + redactedValue + `"`
+	})
+	s = logHeaderRe.ReplaceAllString(s, "${1}${2}"+redactedValue)
+	s = logBearerRe.ReplaceAllString(s, "${1} "+redactedValue)
+	s = logParamRe.ReplaceAllStringFunc(s, func(m string) string {
+		sub := logParamRe.FindStringSubmatch(m)
+		if !isSecretLogKey(sub[2]) {
+			return m
+		}
+		return sub[1] + sub[2] + "=" + redactedValue
+	})
+	s = jwtRe.ReplaceAllString(s, redactedValue)
+	return logGoogleTokenRe.ReplaceAllString(s, redactedValue)
+}
+
+// redactHandler wraps another slog.Handler, scrubbing secrets from every
+// record before it is handled.
+type redactHandler struct {
+	inner slog.Handler
+}
+
+func newRedactHandler(inner slog.Handler) slog.Handler {
+	return &redactHandler{inner: inner}
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.inner.Enabled(ctx, l)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redactSecrets(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.inner.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	red := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		red[i] = redactAttr(a)
+	}
+	return &redactHandler{inner: h.inner.WithAttrs(red)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{inner: h.inner.WithGroup(name)}
+}
+
+// redactAttr scrubs one attribute. A secret-looking key masks the whole
+// value; otherwise strings, errors, and printable values are scanned.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup && isSecretLogKey(a.Key) {
+		a.Value = slog.StringValue(redactedValue)
+		return a
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redactSecrets(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		red := make([]slog.Attr, len(group))
+		for i, g := range group {
+			red[i] = redactAttr(g)
+		}
+		a.Value = slog.GroupValue(red...)
+	case slog.KindAny:
+		v := a.Value.Any()
+		if err, ok := v.(error); ok {
+			a.Value = slog.StringValue(redactSecrets(err.Error()))
+		} else if s := fmt.Sprint(v); redactSecrets(s) != s {
+			a.Value = slog.StringValue(redactSecrets(s))
+		}
+	}
+	return a
+}
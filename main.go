@@ -1,14 +1,16 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"syscall"
@@ -22,39 +24,9 @@ var (
 	commit  = "none"
 )
 
-// ── .env ────────────────────────────────────────────────────────────────────
-// GO-6: returns a map instead of mutating global os.Setenv.
-
-func loadDotEnv(path string) map[string]string {
-	env := make(map[string]string)
-	f, err := os.Open(path)
-	if err != nil {
-		return env
-	}
-	defer f.Close()
-
-	s := bufio.NewScanner(f)
-	s.Buffer(make([]byte, 0, 4096), 4096)
-
-	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		line = strings.TrimPrefix(line, "export ")
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		val := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
-		env[key] = val
-	}
-	return env
-}
-
 // envGet returns the first non-empty value: real env var, then dotenv map.
 func envGet(dotenv map[string]string, key string) string {
+	envRead(key)
 	if v := os.Getenv(key); v != "" {
 		return v
 	}
@@ -66,6 +38,7 @@ func envFloat(dotenv map[string]string, key string, fb float64) float64 {
 		if v, err := strconv.ParseFloat(s, 64); err == nil {
 			return v
 		}
+		envIgnored(key, s, fmt.Sprintf("not a number, using %g", fb))
 	}
 	return fb
 }
@@ -75,24 +48,196 @@ func envInt(dotenv map[string]string, key string, fb int) int {
 		if v, err := strconv.Atoi(s); err == nil {
 			return v
 		}
+		envIgnored(key, s, fmt.Sprintf("not a whole number, using %d", fb))
 	}
 	return fb
 }
 
 func envBool(dotenv map[string]string, key string) bool {
 	s := strings.ToLower(envGet(dotenv, key))
-	return s == "true" || s == "1" || s == "yes"
+	switch s {
+	case "true", "1", "yes":
+		return true
+	case "", "false", "0", "no":
+	default:
+		envIgnored(key, s, "not true/false, 1/0, or yes/no; using false")
+	}
+	return false
+}
+
+// setupLogger installs the default slog handler: color (default) or JSON,
+// level gated by verbose, with secrets redacted (see logredact.go).
+func setupLogger(format string, verbose bool) {
+	logLevel := slog.LevelInfo
+	if verbose {
+		logLevel = slog.LevelDebug
+	}
+	if strings.ToLower(format) == "json" {
+		slog.SetDefault(slog.New(newRedactHandler(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))))
+	} else {
+		slog.SetDefault(slog.New(newRedactHandler(NewColorHandler(os.Stderr, logLevel))))
+	}
+}
+
+// ── Google Drive Flags ──────────────────────────────────────────────────────
+// Shared by the exporter (--gdrive) and `graindl gdrive sync`.
+
+// registerGDriveFlags registers the Drive connection and upload-policy flags
+// on fs. It returns the raw --gdrive-route value for finishGDriveConfig.
+func registerGDriveFlags(fs *flag.FlagSet, cfg *Config, dotenv map[string]string) *string {
+	fs.StringVar(&cfg.GDriveFolderID, "gdrive-folder-id", envGet(dotenv, "GRAIN_GDRIVE_FOLDER_ID"), "Target Google Drive folder ID")
+	fs.StringVar(&cfg.GDriveFolderPath, "gdrive-folder-path", envGet(dotenv, "GRAIN_GDRIVE_FOLDER_PATH"), `Target Drive folder by path, e.g. "Team/Recordings/Grain" (under --gdrive-folder-id if set, else My Drive)`)
+	fs.BoolVar(&cfg.GDriveCreateDir, "gdrive-folder-create", envBool(dotenv, "GRAIN_GDRIVE_FOLDER_CREATE"), "Create missing folders on --gdrive-folder-path")
+	fs.StringVar(&cfg.GDriveCredentials, "gdrive-credentials", envGet(dotenv, "GRAIN_GDRIVE_CREDENTIALS"), "Path to Google OAuth2/service-account credentials JSON")
+	fs.StringVar(&cfg.GDriveTokenFile, "gdrive-token", envGet(dotenv, "GRAIN_GDRIVE_TOKEN"), "Path to cached OAuth2 token file")
+	fs.BoolVar(&cfg.GDriveServiceAcct, "gdrive-service-account", envBool(dotenv, "GRAIN_GDRIVE_SERVICE_ACCT"), "Use service account authentication")
+	fs.StringVar(&cfg.GDriveConflict, "gdrive-conflict", coalesce(envGet(dotenv, "GRAIN_GDRIVE_CONFLICT"), "local-wins"), "Conflict resolution: local-wins (default), skip, newer-wins")
+	fs.BoolVar(&cfg.GDriveVerify, "gdrive-verify", envBool(dotenv, "GRAIN_GDRIVE_VERIFY"), "Force Drive-side verification before uploading")
+	fs.BoolVar(&cfg.GDriveQuotaGuard, "gdrive-quota-guard", envBool(dotenv, "GRAIN_GDRIVE_QUOTA_GUARD"), "Refuse Drive uploads that would exceed remaining storage quota (default: warn)")
+	fs.StringVar(&cfg.GDrivePreserve, "gdrive-preserve-revisions", envGet(dotenv, "GRAIN_GDRIVE_PRESERVE_REVISIONS"), "Keep the previous Drive version on update: keep-forever, copy")
+	routes := envGet(dotenv, "GRAIN_GDRIVE_ROUTE")
+	fs.StringVar(&routes, "gdrive-route", routes, `Route meetings to Drive subfolders, e.g. "tag:customer->Customers,title:interview->Hiring"`)
+	return &routes
+}
+
+// finishGDriveConfig validates the Drive flags, parses routes, and fills in
+// the default token path.
+func finishGDriveConfig(cfg *Config, routes string) error {
+	if cfg.GDriveFolderID == "" && cfg.GDriveFolderPath == "" {
+		return errors.New("google drive requires --gdrive-folder-id or --gdrive-folder-path")
+	}
+	if cfg.GDriveFolderPath != "" {
+		names, err := splitFolderPath(cfg.GDriveFolderPath)
+		if err != nil {
+			return fmt.Errorf("invalid --gdrive-folder-path: %w", err)
+		}
+		cfg.GDriveFolderPath = strings.Join(names, "/")
+	} else if cfg.GDriveCreateDir {
+		return errors.New("--gdrive-folder-create requires --gdrive-folder-path")
+	}
+	if cfg.GDriveCredentials == "" {
+		return errors.New("google drive requires --gdrive-credentials")
+	}
+	switch cfg.GDriveConflict {
+	case "local-wins", "skip", "newer-wins":
+		// valid
+	default:
+		return errors.New("invalid --gdrive-conflict: must be 'local-wins', 'skip', or 'newer-wins'")
+	}
+	switch cfg.GDrivePreserve {
+	case "", "keep-forever", "copy":
+		// valid
+	default:
+		return errors.New("invalid --gdrive-preserve-revisions: must be 'keep-forever' or 'copy'")
+	}
+	parsed, err := parseDriveRoutes(routes)
+	if err != nil {
+		return fmt.Errorf("invalid --gdrive-route: %w", err)
+	}
+	cfg.GDriveRoutes = parsed
+	if cfg.GDriveTokenFile == "" {
+		cfg.GDriveTokenFile = filepath.Join(cfg.SessionDir, "gdrive-token.json")
+	}
+	return nil
+}
+
+// ── Mirror Flags ────────────────────────────────────────────────────────────
+// Shared by the exporter and `graindl import-grain-zip`.
+
+// registerMirrorFlags registers the iCloud, WebDAV, and S3 mirror flags on fs.
+func registerMirrorFlags(fs *flag.FlagSet, cfg *Config, dotenv map[string]string) {
+	fs.BoolVar(&cfg.ICloud, "icloud", envBool(dotenv, "GRAIN_ICLOUD"), "Copy exports to iCloud Drive")
+	fs.StringVar(&cfg.ICloudPath, "icloud-path", envGet(dotenv, "GRAIN_ICLOUD_PATH"), "Custom iCloud Drive path (auto-detected on macOS)")
+	fs.StringVar(&cfg.WebDAVURL, "webdav-url", envGet(dotenv, "GRAIN_WEBDAV_URL"), "Mirror exports to this WebDAV collection (credentials from GRAIN_WEBDAV_USER/GRAIN_WEBDAV_PASSWORD)")
+	registerS3Flags(fs, &cfg.S3, dotenv)
+}
+
+// finishMirrorConfig resolves and validates the iCloud path and loads the
+// WebDAV and S3 credentials from env/.env.
+func finishMirrorConfig(cfg *Config, dotenv map[string]string) error {
+	if cfg.ICloud {
+		if cfg.ICloudPath == "" {
+			resolved, err := detectICloudPath()
+			if err != nil {
+				return fmt.Errorf("iCloud path detection failed: %w", err)
+			}
+			cfg.ICloudPath = resolved
+		}
+		if err := validateICloudPath(cfg.ICloudPath); err != nil {
+			return fmt.Errorf("invalid iCloud path: %w", err)
+		}
+	}
+	if cfg.WebDAVURL != "" {
+		cfg.WebDAVUser = envGet(dotenv, "GRAIN_WEBDAV_USER")
+		cfg.WebDAVPassword = envGet(dotenv, "GRAIN_WEBDAV_PASSWORD")
+		if _, err := NewWebDAVMirror(cfg.WebDAVURL, cfg.WebDAVUser, cfg.WebDAVPassword); err != nil {
+			return err
+		}
+	}
+	if cfg.S3.Bucket != "" {
+		loadS3Credentials(&cfg.S3, dotenv)
+		if err := cfg.S3.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ── Subcommands ─────────────────────────────────────────────────────────────
+// Offline tools that work on an existing archive. Each parses its own flags
+// and returns a process exit code. The bare command (no subcommand) is the
+// exporter.
+
+var subcommands = map[string]func(args []string) int{
+	"diff":             runDiff,
+	"digest":           runDigest,
+	"gc":               runGC,
+	"gdrive":           runGDrive,
+	"hls-convert":      runHLSConvert,
+	"import-grain-zip": runImportGrainZip,
+	"manifest":         runManifest,
+	"relink":           runRelink,
+	"serve":            runServe,
+	"service":          runService,
+	"share":            runShare,
+	"state":            runState,
+	"stats":            runStats,
+	"verify-local":     runVerifyLocal,
+	"version":          runVersion,
 }
 
 // ── Main ────────────────────────────────────────────────────────────────────
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+
 	dotenv := loadDotEnv(".env")
 
 	var cfg Config
 	showVersion := false
+	checkConfig := false
 	noTUI := false
 	intervalStr := coalesce(envGet(dotenv, "GRAIN_WATCH_INTERVAL"), "30m")
+	scheduleStr := envGet(dotenv, "GRAIN_WATCH_SCHEDULE")
+	alertKeywords := envGet(dotenv, "GRAIN_ALERT_KEYWORDS")
+	claimTTLStr := envGet(dotenv, "GRAIN_CLAIM_TTL")
+	retentionStr := envGet(dotenv, "GRAIN_RETENTION")
+	keepVideosStr := envGet(dotenv, "GRAIN_GDRIVE_KEEP_VIDEOS")
+	chaosStr := ""
+	hostDelayStr := envGet(dotenv, "GRAIN_HOST_DELAY")
+	classifyStr := envGet(dotenv, "GRAIN_CLASSIFY")
+	classifyRouteStr := envGet(dotenv, "GRAIN_CLASSIFY_ROUTE")
+	var apiHeaders apiHeaderList
+	var ignoreTitles titleRegexList
+	ignoreFile := envGet(dotenv, "GRAIN_IGNORE_FILE")
+	logMaxSizeStr := coalesce(envGet(dotenv, "GRAIN_LOG_MAX_SIZE"), "10MB")
+	notionMaxSizeStr := coalesce(envGet(dotenv, "GRAIN_NOTION_MAX_SIZE"), defaultNotionMaxSize)
+	logRotateStr := coalesce(envGet(dotenv, "GRAIN_LOG_ROTATE"), "24h")
+	progressStr := coalesce(envGet(dotenv, "GRAIN_PROGRESS_INTERVAL"), "1m")
 
 	// TUI default: on when stderr is a real TTY (auto-detect), unless explicitly
 	// overridden by the GRAIN_TUI env var or the --no-tui flag.
@@ -102,42 +247,144 @@ func main() {
 	}
 
 	flag.StringVar(&cfg.OutputDir, "output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Output directory")
+	flag.StringVar(&cfg.MarkdownOutput, "markdown-output", envGet(dotenv, "GRAIN_MARKDOWN_OUTPUT"), "Write markdown notes and highlight previews under this dir instead of --output")
+	flag.StringVar(&cfg.VideoOutput, "video-output", envGet(dotenv, "GRAIN_VIDEO_OUTPUT"), "Write videos and audio under this dir instead of --output")
+	flag.StringVar(&cfg.DataOutput, "data-output", envGet(dotenv, "GRAIN_DATA_OUTPUT"), "Write metadata JSON, transcripts, and highlights under this dir instead of --output")
 	flag.StringVar(&cfg.SessionDir, "session-dir", coalesce(envGet(dotenv, "GRAIN_SESSION_DIR"), "./.grain-session"), "Browser session dir")
 	flag.IntVar(&cfg.MaxMeetings, "max", envInt(dotenv, "GRAIN_MAX_MEETINGS", 0), "Max meetings (0=all)")
+	flag.BoolVar(&cfg.Strict, "strict", envBool(dotenv, "GRAIN_STRICT"), "Exit non-zero when any meeting failed, left an HLS stream pending, or got a truncated-looking video")
+	flag.IntVar(&cfg.MaxErrors, "max-errors", envInt(dotenv, "GRAIN_MAX_ERRORS", 0), "Abort the run after this many failed meetings (0 = never)")
+	flag.IntVar(&cfg.DeadLetterAfter, "dead-letter-after", envInt(dotenv, "GRAIN_DEAD_LETTER_AFTER", 0), "Dead-letter a meeting after this many consecutive runs whose scrape or download failed; later runs skip it (0 = off)")
+	flag.BoolVar(&cfg.DeadLetter, "dead-letter", false, "With graindl retry: export the dead-lettered meetings")
 	flag.StringVar(&cfg.MeetingID, "id", envGet(dotenv, "GRAIN_MEETING_ID"), "Export a single meeting by ID")
+	flag.StringVar(&cfg.Stdout, "stdout", "", "Stream one artifact of the --id meeting to stdout instead of writing files: video, transcript")
 	flag.BoolVar(&cfg.DryRun, "dry-run", envBool(dotenv, "GRAIN_DRY_RUN"), "List meetings that would be exported without exporting")
 	flag.BoolVar(&cfg.SkipVideo, "skip-video", envBool(dotenv, "GRAIN_SKIP_VIDEO"), "Skip video downloads")
+	flag.BoolVar(&cfg.NoVideoTags, "no-video-tags", envBool(dotenv, "GRAIN_NO_VIDEO_TAGS"), "Don't write meeting tags and a poster frame into downloaded MP4s")
 	flag.BoolVar(&cfg.AudioOnly, "audio-only", envBool(dotenv, "GRAIN_AUDIO_ONLY"), "Export audio track only (requires ffmpeg)")
+	flag.BoolVar(&cfg.DeferVideos, "defer-videos", envBool(dotenv, "GRAIN_DEFER_VIDEOS"), "Queue video downloads for graindl download-videos instead of downloading them now")
+	flag.StringVar(&cfg.ExtractScript, "extract-script", envGet(dotenv, "GRAIN_EXTRACT_SCRIPT"), "JS file whose exported functions run on each meeting page (results go to metadata \"extra\")")
+	flag.BoolVar(&cfg.SnapshotHTML, "snapshot-html", envBool(dotenv, "GRAIN_SNAPSHOT_HTML"), "Save a single-file MHTML snapshot of each meeting page")
+	flag.StringVar(&cfg.RecordHTTP, "record-http", envGet(dotenv, "GRAIN_RECORD_HTTP"), "Save sanitized Grain request/response fixtures to this directory")
+	flag.StringVar(&cfg.ReplayHTTP, "replay-http", envGet(dotenv, "GRAIN_REPLAY_HTTP"), "Answer Grain requests from fixtures in this directory instead of the network")
+	flag.StringVar(&cfg.GrainBaseURL, "grain-base-url", envGet(dotenv, "GRAIN_BASE_URL"), "Grain web app base URL, for regional or enterprise hosts (default "+defaultGrainBaseURL+")")
+	flag.StringVar(&cfg.GrainAPIURL, "grain-api-url", envGet(dotenv, "GRAIN_API_URL"), "Grain public API base URL (default "+defaultGrainAPIURL+")")
+	flag.BoolVar(&cfg.RefreshAnalytics, "refresh-analytics", envBool(dotenv, "GRAIN_REFRESH_ANALYTICS"), "Re-scrape view analytics for meetings already exported")
+	flag.Float64Var(&cfg.MinQuality, "min-quality", envFloat(dotenv, "GRAIN_MIN_QUALITY", 0), "Re-export meetings whose scrape quality score is below this (0-1; 0 = off)")
+	flag.Float64Var(&cfg.DurationTolerance, "duration-tolerance", envFloat(dotenv, "GRAIN_DURATION_TOLERANCE", 60), "Flag videos whose ffprobe length differs from the meeting's by more than this many seconds (0 = off)")
 	flag.BoolVar(&cfg.Overwrite, "overwrite", envBool(dotenv, "GRAIN_OVERWRITE"), "Overwrite existing")
+	flag.BoolVar(&cfg.Immutable, "immutable", envBool(dotenv, "GRAIN_IMMUTABLE"), "Legal hold: seal exported files read-only and never overwrite them")
+	flag.StringVar(&retentionStr, "retention", retentionStr, "Retention period recorded with --immutable (e.g. 7y, 90d, or 2032-12-31)")
 	flag.BoolVar(&cfg.Headless, "headless", envBool(dotenv, "GRAIN_HEADLESS"), "Headless browser")
+	flag.StringVar(&cfg.BrowserBin, "browser-bin", envGet(dotenv, "GRAIN_BROWSER_BIN"), "Chromium, Chrome, or Edge binary to launch instead of Rod's downloaded Chromium")
+	flag.StringVar(&cfg.BrowserRemote, "browser-remote", envGet(dotenv, "GRAIN_BROWSER_REMOTE"), "Attach to a running browser's DevTools endpoint (e.g. ws://host:9222) instead of launching one")
+	flag.BoolVar(&cfg.NoDownloadBrowser, "no-download-browser", envBool(dotenv, "GRAIN_NO_DOWNLOAD_BROWSER"), "Never download Chromium; use an installed Chrome, Chromium, or Edge (for air-gapped machines)")
 	flag.BoolVar(&cfg.CleanSession, "clean-session", false, "Wipe browser session before run")
+	flag.BoolVar(&cfg.EncryptSession, "encrypt-session", envBool(dotenv, "GRAIN_ENCRYPT_SESSION"), "Keep the session dir encrypted at rest (passphrase from GRAIN_SESSION_PASSPHRASE)")
 	flag.BoolVar(&cfg.Verbose, "verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
 	flag.Float64Var(&cfg.MinDelaySec, "min-delay", envFloat(dotenv, "GRAIN_MIN_DELAY", 2.0), "Min delay (seconds)")
 	flag.Float64Var(&cfg.MaxDelaySec, "max-delay", envFloat(dotenv, "GRAIN_MAX_DELAY", 6.0), "Max delay (seconds)")
+	flag.StringVar(&hostDelayStr, "host-delay", hostDelayStr, "Per-host request pacing in seconds, e.g. api.grain.com=0.5-1.5,cdn=0-1 (cdn = video/CDN hosts)")
+	flag.StringVar(&cfg.APIUserAgent, "api-user-agent", envGet(dotenv, "GRAIN_API_USER_AGENT"), "User-Agent for browser and video download requests (for proxies that require identification)")
+	flag.Var(&apiHeaders, "api-header", `Extra "Name: value" header on browser and video download requests (repeatable; env GRAIN_API_HEADERS, comma-separated)`)
 	flag.IntVar(&cfg.Parallel, "parallel", envInt(dotenv, "GRAIN_PARALLEL", 1), "Number of meetings to export concurrently")
+	flag.BoolVar(&cfg.AutoParallel, "auto-parallel", envBool(dotenv, "GRAIN_AUTO_PARALLEL"), "Size --parallel from CPU and memory, and back off on browser timeouts, swapping, or rising latency")
+	flag.StringVar(&claimTTLStr, "claim-ttl", claimTTLStr, "Claim meetings via _claims/ files so several instances can share one output dir (e.g. 30m)")
+	flag.BoolVar(&cfg.IsolateWorkers, "isolate-workers", envBool(dotenv, "GRAIN_ISOLATE_WORKERS"), "Give each --parallel worker its own incognito browser context")
 	flag.StringVar(&cfg.SearchQuery, "search", envGet(dotenv, "GRAIN_SEARCH"), "Search query to filter meetings")
+	flag.Var(&ignoreTitles, "ignore-title-regex", `Skip discovered meetings whose title matches this regexp, e.g. "(?i)standup|1:1" (repeatable; env GRAIN_IGNORE_TITLE_REGEX, one per line)`)
+	flag.StringVar(&ignoreFile, "ignore-file", ignoreFile, "File of title regexps to skip, one per line (# comments)")
+	flag.BoolVar(&cfg.IncludeShared, "include-shared", envBool(dotenv, "GRAIN_INCLUDE_SHARED"), `Also export recordings from Grain's "Shared with me" view`)
+	flag.BoolVar(&cfg.SharedSubdir, "shared-subdir", envBool(dotenv, "GRAIN_SHARED_SUBDIR"), "With --include-shared, write shared meetings under shared/<date>/")
+	flag.StringVar(&classifyStr, "classify", classifyStr, `Label meetings by participant email, first match wins, e.g. "*@customer.com->external,acme.com->internal"`)
+	flag.StringVar(&classifyRouteStr, "classify-route", classifyRouteStr, `Route labelled meetings to Drive subfolders, e.g. "external->Restricted" (ahead of --gdrive-route)`)
 	flag.BoolVar(&cfg.Watch, "watch", envBool(dotenv, "GRAIN_WATCH"), "Run continuously, polling for new meetings")
 	flag.StringVar(&intervalStr, "interval", intervalStr, "Polling interval for watch mode (e.g. 5m, 30m, 1h)")
-	flag.StringVar(&cfg.OutputFormat, "output-format", envGet(dotenv, "GRAIN_OUTPUT_FORMAT"), "Export format: obsidian, notion (adds frontmatter markdown)")
+	flag.StringVar(&scheduleStr, "schedule", scheduleStr, `Cron schedule for watch mode instead of --interval (e.g. "0 9-17 * * 1-5")`)
+	flag.StringVar(&cfg.HighlightPreviews, "highlight-previews", envGet(dotenv, "GRAIN_HIGHLIGHT_PREVIEWS"), "Cut a looping gif or webp preview of each highlight from the video and embed it in notes")
+	flag.StringVar(&cfg.OutputFormat, "output-format", envGet(dotenv, "GRAIN_OUTPUT_FORMAT"), "Export format: obsidian, notion, minutes (adds frontmatter markdown)")
+	flag.StringVar(&notionMaxSizeStr, "notion-max-size", notionMaxSizeStr, "With --output-format notion, continue the transcript in <note>.part2.md, ... past this size (e.g. 1MB; 0 = never split)")
+	flag.StringVar(&cfg.Compress, "compress", envGet(dotenv, "GRAIN_COMPRESS"), "Store metadata, transcripts, and highlights compressed: zstd (.zst), gzip (.gz), none")
+	flag.IntVar(&cfg.Topics, "topics", envInt(dotenv, "GRAIN_TOPICS", 0), "Extract N topic keywords per meeting from its transcript (TF-IDF across the archive; 0 = off)")
+	flag.StringVar(&cfg.NotesFormat, "notes-format", coalesce(envGet(dotenv, "GRAIN_NOTES_FORMAT"), notesFormatJSON), "Shape of AI notes in metadata: json (sections), md, text")
+	flag.StringVar(&cfg.AnkiDeck, "anki-deck", envGet(dotenv, "GRAIN_ANKI_DECK"), "Write highlights and action items as Anki flashcards to this .txt/.tsv/.csv import file")
+	flag.BoolVar(&cfg.HighlightPages, "highlight-pages", envBool(dotenv, "GRAIN_HIGHLIGHT_PAGES"), "Collect highlights by tag into highlights/<tag>.md pages, updated after every run")
+	flag.StringVar(&cfg.SlugStyle, "slug-style", envGet(dotenv, "GRAIN_SLUG_STYLE"), "Name markdown notes after the meeting title: ascii (transliterated), unicode")
 	flag.StringVar(&cfg.HealthcheckFile, "healthcheck-file", envGet(dotenv, "GRAIN_HEALTHCHECK_FILE"), "File to touch after each watch cycle (for monitoring)")
+	flag.StringVar(&cfg.HealthcheckFormat, "healthcheck-format", coalesce(envGet(dotenv, "GRAIN_HEALTHCHECK_FORMAT"), "text"), "Healthcheck file format: text (default), json")
+	flag.StringVar(&cfg.EventsSock, "events-sock", envGet(dotenv, "GRAIN_EVENTS_SOCK"), "Stream NDJSON export events to this unix socket or named pipe")
+	flag.StringVar(&progressStr, "progress-interval", progressStr, "Log a progress summary with ETA this often during a run (0 = off)")
 	flag.StringVar(&cfg.LogFormat, "log-format", envGet(dotenv, "GRAIN_LOG_FORMAT"), "Log format: color (default), json")
+	flag.BoolVar(&cfg.LogGroupByMeeting, "log-group-by-meeting", envBool(dotenv, "GRAIN_LOG_GROUP_BY_MEETING"), "With --parallel, print each meeting's log lines as one block when it finishes")
+	flag.StringVar(&cfg.LogFile, "log-file", envGet(dotenv, "GRAIN_LOG_FILE"), "Also write logs to this file, with rotation")
+	flag.StringVar(&logMaxSizeStr, "log-max-size", logMaxSizeStr, "Rotate the log file when it exceeds this size (e.g. 10MB; 0 = no limit)")
+	flag.StringVar(&logRotateStr, "log-rotate", logRotateStr, "Rotate the log file every period (e.g. 24h; 0 = size only)")
+	flag.IntVar(&cfg.LogKeep, "log-keep", envInt(dotenv, "GRAIN_LOG_KEEP", 7), "Rotated log files to keep (0 = all)")
 	flag.BoolVar(&cfg.TUI, "tui", defaultTUI, "Enable interactive terminal UI (default: auto when stderr is a TTY)")
 	flag.BoolVar(&noTUI, "no-tui", false, "Disable interactive terminal UI")
-	flag.BoolVar(&cfg.ICloud, "icloud", envBool(dotenv, "GRAIN_ICLOUD"), "Copy exports to iCloud Drive")
-	flag.StringVar(&cfg.ICloudPath, "icloud-path", envGet(dotenv, "GRAIN_ICLOUD_PATH"), "Custom iCloud Drive path (auto-detected on macOS)")
+	registerMirrorFlags(flag.CommandLine, &cfg, dotenv)
+	flag.BoolVar(&cfg.AppleNotes, "apple-notes", envBool(dotenv, "GRAIN_APPLE_NOTES"), "Push each markdown note into Apple Notes (macOS; needs --output-format)")
+	flag.StringVar(&cfg.AppleNotesFolder, "apple-notes-folder", envGet(dotenv, "GRAIN_APPLE_NOTES_FOLDER"), "Apple Notes folder for exported notes (default: Grain)")
+	flag.StringVar(&cfg.AppleNotesShortcut, "apple-notes-shortcut", envGet(dotenv, "GRAIN_APPLE_NOTES_SHORTCUT"), "Run this Shortcut with each markdown file instead of writing to Notes directly")
+	flag.BoolVar(&cfg.Spotlight, "spotlight", envBool(dotenv, "GRAIN_SPOTLIGHT"), "Write Spotlight metadata and Finder tags onto exported files (macOS)")
+	flag.BoolVar(&cfg.NotifyDesktop, "notify-desktop", envBool(dotenv, "GRAIN_NOTIFY_DESKTOP"), "Show a desktop notification when a run ends, or when a watch cycle exports new meetings")
 	flag.BoolVar(&cfg.GDrive, "gdrive", envBool(dotenv, "GRAIN_GDRIVE"), "Enable Google Drive upload after export")
-	flag.StringVar(&cfg.GDriveFolderID, "gdrive-folder-id", envGet(dotenv, "GRAIN_GDRIVE_FOLDER_ID"), "Target Google Drive folder ID")
-	flag.StringVar(&cfg.GDriveCredentials, "gdrive-credentials", envGet(dotenv, "GRAIN_GDRIVE_CREDENTIALS"), "Path to Google OAuth2/service-account credentials JSON")
-	flag.StringVar(&cfg.GDriveTokenFile, "gdrive-token", envGet(dotenv, "GRAIN_GDRIVE_TOKEN"), "Path to cached OAuth2 token file")
+	gdriveRoutes := registerGDriveFlags(flag.CommandLine, &cfg, dotenv)
 	flag.BoolVar(&cfg.GDriveCleanLocal, "gdrive-clean-local", envBool(dotenv, "GRAIN_GDRIVE_CLEAN_LOCAL"), "Remove local files after successful Drive upload")
-	flag.BoolVar(&cfg.GDriveServiceAcct, "gdrive-service-account", envBool(dotenv, "GRAIN_GDRIVE_SERVICE_ACCT"), "Use service account authentication")
-	flag.StringVar(&cfg.GDriveConflict, "gdrive-conflict", coalesce(envGet(dotenv, "GRAIN_GDRIVE_CONFLICT"), "local-wins"), "Conflict resolution: local-wins (default), skip, newer-wins")
-	flag.BoolVar(&cfg.GDriveVerify, "gdrive-verify", envBool(dotenv, "GRAIN_GDRIVE_VERIFY"), "Force Drive-side verification before uploading")
+	flag.StringVar(&keepVideosStr, "gdrive-keep-videos", keepVideosStr, "Remove local videos this long after a verified Drive upload (e.g. 30d, 2w); metadata and transcripts stay")
+	flag.BoolVar(&cfg.HLSDownload, "hls-download", envBool(dotenv, "GRAIN_HLS_DOWNLOAD"), "Download HLS streams natively (parallel segments, ffmpeg remux)")
+	flag.IntVar(&cfg.HLSConcurrency, "hls-concurrency", envInt(dotenv, "GRAIN_HLS_CONCURRENCY", 8), "Concurrent HLS segment downloads")
+	flag.StringVar(&alertKeywords, "alert-keywords", alertKeywords, "Comma-separated keywords that trigger an alert when found in a new transcript")
+	flag.StringVar(&cfg.AlertWebhook, "alert-webhook", envGet(dotenv, "GRAIN_ALERT_WEBHOOK"), "Webhook URL (Slack-compatible) for keyword alerts")
+	// The token's env value is not the flag default, so --help doesn't print it.
+	flag.StringVar(&cfg.ReadwiseToken, "readwise-token", "", "Readwise access token: push highlights to Readwise after each run (prefer env GRAIN_READWISE_TOKEN)")
+	flag.StringVar(&cfg.Embeddings, "embeddings", envGet(dotenv, "GRAIN_EMBEDDINGS"), "Embed transcript chunks for RAG after each run: openai (needs OPENAI_API_KEY), local (Ollama or another OpenAI-compatible server)")
+	flag.StringVar(&cfg.EmbeddingsOut, "embeddings-out", envGet(dotenv, "GRAIN_EMBEDDINGS_OUT"), "Vector file for --embeddings: .parquet or .jsonl (default: <output>/embeddings.parquet)")
+	flag.StringVar(&cfg.EmbeddingsModel, "embeddings-model", envGet(dotenv, "GRAIN_EMBEDDINGS_MODEL"), "Embedding model (default: text-embedding-3-small for openai, nomic-embed-text for local)")
+	flag.StringVar(&cfg.EmbeddingsURL, "embeddings-url", envGet(dotenv, "GRAIN_EMBEDDINGS_URL"), "Embeddings API base URL (default: OpenAI, or http://localhost:11434/v1 for local)")
+	flag.Float64Var(&cfg.EmbeddingsMaxCost, "embeddings-max-cost", envFloat(dotenv, "GRAIN_EMBEDDINGS_MAX_COST", 1), "Most to spend on OpenAI embeddings per run, in USD; the newest meetings go first (0 = no limit)")
 	flag.BoolVar(&showVersion, "version", false, "Print version and exit")
+	flag.StringVar(&cfg.MaxRate, "max-rate", envGet(dotenv, "GRAIN_MAX_RATE"), "Backfill rate for graindl plan, e.g. 100meetings/day, 10/hour, 500/week")
+	flag.BoolVar(&checkConfig, "check-config", false, "Validate flags, .env, and GRAIN_* variables, report problems, and exit without exporting")
+	flag.StringVar(&chaosStr, "chaos", "", "Inject failures on purpose, e.g. p=0.1 or scrape=0.2,upload=0.5,seed=42 (hidden)")
+	flag.Usage = hideFlagsUsage(flag.CommandLine, "chaos")
+
+	// `graindl completion` lists the flags above; `graindl export` is the
+	// exporter itself, spelled out for pipelines; `graindl pick` is the
+	// exporter with a picker between discovery and export, `graindl
+	// download-videos` the exporter working through the --defer-videos queue,
+	// `graindl plan` the exporter's discovery scheduling a backfill,
+	// `graindl members` its login exporting the workspace member directory,
+	// and `graindl retry` the exporter working through a list of failures.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "completion":
+			os.Exit(runCompletion(os.Args[2:], flag.CommandLine))
+		case "export":
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "pick":
+			cfg.Pick = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "download-videos":
+			cfg.DownloadVideos = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "plan":
+			cfg.Plan = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "members":
+			cfg.Members = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "retry":
+			cfg.Retry = true
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		}
+	}
 	flag.Parse()
 
-	// --no-tui overrides any auto-detection or explicit --tui.
-	if noTUI {
+	// --no-tui overrides any auto-detection or explicit --tui. The picker
+	// needs the terminal, so pick always logs plainly, as do
+	// download-videos and plan, which have no meeting list to show.
+	if noTUI || cfg.Pick || cfg.DownloadVideos || cfg.Plan || cfg.Members || cfg.Retry || cfg.Stdout != "" {
 		cfg.TUI = false
 	}
 
@@ -147,19 +394,49 @@ func main() {
 	}
 
 	// GO-2: set up slog with color handler or JSON, level gated by --verbose
-	logLevel := slog.LevelInfo
-	if cfg.Verbose {
-		logLevel = slog.LevelDebug
+	setupLogger(cfg.LogFormat, cfg.Verbose)
+	logMeetings.group = cfg.LogGroupByMeeting
+
+	if cfg.LogFile != "" {
+		size, err := parseByteSize(logMaxSizeStr)
+		if err != nil {
+			slog.Error("Invalid --log-max-size", "error", err)
+			os.Exit(1)
+		}
+		rotate, err := time.ParseDuration(logRotateStr)
+		if err != nil || rotate < 0 {
+			slog.Error("Invalid --log-rotate value", "value", logRotateStr)
+			os.Exit(1)
+		}
+		cfg.LogMaxSize, cfg.LogRotate = size, rotate
+		lf, err := openLogFile(&cfg)
+		if err != nil {
+			slog.Error("Log file", "error", err)
+			os.Exit(1)
+		}
+		defer lf.Close()
 	}
-	if strings.ToLower(cfg.LogFormat) == "json" {
-		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
-	} else {
-		slog.SetDefault(slog.New(NewColorHandler(os.Stderr, logLevel)))
+
+	// --check-config counts the warnings validation logs from here on.
+	var warnings *warnCounter
+	if checkConfig {
+		warnings = newWarnCounter(slog.Default().Handler())
+		slog.SetDefault(slog.New(warnings))
+		cfg.TUI = false
 	}
 
 	if cfg.Parallel < 1 {
 		cfg.Parallel = 1
 	}
+	if cfg.AutoParallel {
+		if cfg.Parallel > 1 {
+			slog.Warn("--auto-parallel overrides --parallel", "parallel", cfg.Parallel)
+		}
+		resolveAutoParallel(&cfg)
+	}
+	if cfg.LogGroupByMeeting && (cfg.Parallel <= 1 || cfg.LogFormat == "json") {
+		slog.Warn("--log-group-by-meeting only applies to colored output with --parallel > 1")
+	}
 	if cfg.MinDelaySec < 0 {
 		cfg.MinDelaySec = 0
 	}
@@ -167,7 +444,119 @@ func main() {
 		cfg.MaxDelaySec = cfg.MinDelaySec + 1
 	}
 
+	if cfg.Pick {
+		switch {
+		case cfg.Watch:
+			slog.Error("graindl pick cannot be used with --watch")
+			os.Exit(1)
+		case cfg.MeetingID != "":
+			slog.Error("graindl pick cannot be used with --id")
+			os.Exit(1)
+		case cfg.SearchQuery != "":
+			slog.Error("graindl pick cannot be used with --search (type in the picker to filter)")
+			os.Exit(1)
+		}
+	}
+	if cfg.Plan {
+		switch {
+		case cfg.Watch:
+			slog.Error("graindl plan cannot be used with --watch (plan once, then run --watch)")
+			os.Exit(1)
+		case cfg.MeetingID != "":
+			slog.Error("graindl plan cannot be used with --id")
+			os.Exit(1)
+		case cfg.SearchQuery != "":
+			slog.Error("graindl plan cannot be used with --search")
+			os.Exit(1)
+		case cfg.MaxRate == "":
+			slog.Error("graindl plan requires --max-rate (e.g. --max-rate 100meetings/day)")
+			os.Exit(1)
+		}
+		var err error
+		if cfg.PlanRate, cfg.PlanWindow, err = parseMaxRate(cfg.MaxRate); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	} else if cfg.MaxRate != "" {
+		slog.Warn("--max-rate only applies to graindl plan (a saved plan keeps its own rate); ignoring")
+	}
+	if cfg.Members {
+		switch {
+		case cfg.Watch:
+			slog.Error("graindl members cannot be used with --watch")
+			os.Exit(1)
+		case cfg.MeetingID != "" || cfg.SearchQuery != "":
+			slog.Error("graindl members cannot be used with --id or --search")
+			os.Exit(1)
+		}
+	}
+	if cfg.Retry {
+		switch {
+		case !cfg.DeadLetter:
+			slog.Error("graindl retry needs a list to retry: --dead-letter")
+			os.Exit(1)
+		case cfg.Watch:
+			slog.Error("graindl retry cannot be used with --watch")
+			os.Exit(1)
+		case cfg.MeetingID != "" || cfg.SearchQuery != "":
+			slog.Error("graindl retry cannot be used with --id or --search")
+			os.Exit(1)
+		}
+	} else if cfg.DeadLetter {
+		slog.Error("--dead-letter only applies to graindl retry")
+		os.Exit(1)
+	}
+	if cfg.DeadLetterAfter < 0 {
+		cfg.DeadLetterAfter = 0
+	}
+	if cfg.Stdout != "" {
+		var err error
+		if cfg.Stdout, err = parseStdoutArtifact(cfg.Stdout); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		switch {
+		case cfg.MeetingID == "":
+			slog.Error("--stdout requires --id")
+			os.Exit(1)
+		case cfg.Watch:
+			slog.Error("--stdout cannot be used with --watch")
+			os.Exit(1)
+		case cfg.Pick || cfg.Plan || cfg.Members || cfg.DownloadVideos || cfg.Retry:
+			slog.Error("--stdout only applies to graindl export")
+			os.Exit(1)
+		case cfg.DryRun:
+			slog.Error("--stdout cannot be used with --dry-run")
+			os.Exit(1)
+		}
+	}
+	if cfg.DownloadVideos {
+		switch {
+		case cfg.Watch:
+			slog.Error("graindl download-videos cannot be used with --watch (schedule it with cron instead)")
+			os.Exit(1)
+		case cfg.SearchQuery != "":
+			slog.Error("graindl download-videos cannot be used with --search")
+			os.Exit(1)
+		case cfg.SkipVideo:
+			slog.Error("graindl download-videos cannot be used with --skip-video")
+			os.Exit(1)
+		}
+		if cfg.DeferVideos {
+			slog.Warn("--defer-videos does not apply to graindl download-videos; ignoring")
+			cfg.DeferVideos = false
+		}
+	}
+	if cfg.DeferVideos && cfg.SkipVideo {
+		slog.Warn("--defer-videos has no effect with --skip-video; ignoring")
+		cfg.DeferVideos = false
+	}
+
 	// Watch mode: parse interval and validate flag combinations.
+	if scheduleStr != "" && !cfg.Watch {
+		slog.Error("--schedule requires --watch")
+		os.Exit(1)
+	}
 	if cfg.Watch {
 		dur, err := time.ParseDuration(intervalStr)
 		if err != nil {
@@ -179,6 +568,18 @@ func main() {
 			os.Exit(1)
 		}
 		cfg.WatchInterval = dur
+		if scheduleStr != "" {
+			sched, err := parseCron(scheduleStr)
+			if err != nil {
+				slog.Error("Invalid --schedule", "error", err)
+				os.Exit(1)
+			}
+			if sched.Next(time.Now()).IsZero() {
+				slog.Error("--schedule never fires", "schedule", scheduleStr)
+				os.Exit(1)
+			}
+			cfg.WatchSchedule = sched
+		}
 		if cfg.MeetingID != "" {
 			slog.Error("--watch cannot be used with --id")
 			os.Exit(1)
@@ -191,58 +592,346 @@ func main() {
 			slog.Error("--watch cannot be used with --overwrite (would re-export every meeting every cycle)")
 			os.Exit(1)
 		}
+		if cfg.MinQuality > 0 {
+			slog.Error("--watch cannot be used with --min-quality (would re-scrape the same pages every cycle)")
+			os.Exit(1)
+		}
 	}
 
+	cfg.HealthcheckFormat = strings.ToLower(cfg.HealthcheckFormat)
+	if cfg.HealthcheckFormat != "text" && cfg.HealthcheckFormat != "json" {
+		slog.Error("Invalid --healthcheck-format (must be text or json)", "value", cfg.HealthcheckFormat)
+		os.Exit(1)
+	}
 	if cfg.OutputFormat != "" {
 		cfg.OutputFormat = strings.ToLower(cfg.OutputFormat)
-		if cfg.OutputFormat != "obsidian" && cfg.OutputFormat != "notion" {
-			slog.Error("Invalid --output-format. Must be 'obsidian' or 'notion'.")
+		if cfg.OutputFormat != "obsidian" && cfg.OutputFormat != "notion" && cfg.OutputFormat != "minutes" {
+			slog.Error("Invalid --output-format. Must be 'obsidian', 'notion', or 'minutes'.")
 			os.Exit(1)
 		}
 	}
+	notionMaxSize, err := parseByteSize(notionMaxSizeStr)
+	if err != nil || (notionMaxSize != 0 && notionMaxSize < minNotionMaxSize) {
+		slog.Error("Invalid --notion-max-size (use 0 or a size of at least 64KB)", "value", notionMaxSizeStr)
+		os.Exit(1)
+	}
+	cfg.NotionMaxSize = int(notionMaxSize)
+	if cfg.Compress, err = parseCompress(cfg.Compress); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if cfg.HighlightPreviews, err = parseHighlightPreviews(cfg.HighlightPreviews); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if cfg.HighlightPreviews != "" {
+		switch {
+		case cfg.SkipVideo || cfg.AudioOnly || cfg.DeferVideos || cfg.DownloadVideos:
+			slog.Warn("--highlight-previews needs the video downloaded in the export run; ignoring")
+			cfg.HighlightPreviews = ""
+		case checkFFmpeg() != nil:
+			slog.Warn("--highlight-previews needs ffmpeg; no previews will be made")
+		}
+	}
 
-	// iCloud: resolve and validate path.
-	if cfg.ICloud {
-		if cfg.ICloudPath == "" {
-			resolved, err := detectICloudPath()
-			if err != nil {
-				slog.Error("iCloud path detection failed", "error", err)
+	sessionPassphrase := envGet(dotenv, "GRAIN_SESSION_PASSPHRASE")
+	if cfg.EncryptSession && sessionPassphrase == "" {
+		slog.Error("--encrypt-session requires GRAIN_SESSION_PASSPHRASE (environment or .env)")
+		os.Exit(1)
+	}
+
+	if cfg.SharedSubdir && !cfg.IncludeShared {
+		slog.Warn("--shared-subdir only applies with --include-shared; ignoring")
+		cfg.SharedSubdir = false
+	}
+
+	if cfg.MinQuality < 0 || cfg.MinQuality > 1 {
+		slog.Error("--min-quality must be between 0 and 1")
+		os.Exit(1)
+	}
+	if cfg.DurationTolerance < 0 {
+		slog.Error("--duration-tolerance must not be negative")
+		os.Exit(1)
+	}
+
+	if retentionStr != "" {
+		if !cfg.Immutable {
+			slog.Error("--retention requires --immutable")
+			os.Exit(1)
+		}
+		until, err := parseRetention(retentionStr, time.Now())
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		cfg.RetainUntil = until
+	}
+	if cfg.Immutable {
+		for _, c := range []struct {
+			flag string
+			set  bool
+		}{
+			{"--overwrite", cfg.Overwrite},
+			{"--min-quality", cfg.MinQuality > 0},
+			{"--refresh-analytics", cfg.RefreshAnalytics},
+			{"--gdrive-clean-local", cfg.GDriveCleanLocal},
+			{"--gdrive-keep-videos", keepVideosStr != ""},
+		} {
+			if c.set {
+				slog.Error("--immutable cannot be used with " + c.flag + " (it would modify or remove sealed files)")
 				os.Exit(1)
 			}
-			cfg.ICloudPath = resolved
 		}
-		if err := validateICloudPath(cfg.ICloudPath); err != nil {
-			slog.Error("Invalid iCloud path", "error", err)
+	}
+
+	if cfg.Topics < 0 {
+		slog.Error("--topics must be 0 or more")
+		os.Exit(1)
+	}
+	if cfg.MaxErrors < 0 {
+		slog.Error("--max-errors must be 0 or more")
+		os.Exit(1)
+	}
+	if cfg.Strict && cfg.Watch {
+		slog.Warn("--strict does not apply to --watch (failed meetings are retried next cycle); ignoring")
+	}
+
+	cfg.NotesFormat = strings.ToLower(cfg.NotesFormat)
+	if cfg.NotesFormat != notesFormatJSON && cfg.NotesFormat != notesFormatMD && cfg.NotesFormat != notesFormatText {
+		slog.Error("Invalid --notes-format. Must be 'json', 'md', or 'text'.")
+		os.Exit(1)
+	}
+
+	if cfg.SlugStyle != "" {
+		cfg.SlugStyle = strings.ToLower(cfg.SlugStyle)
+		if cfg.SlugStyle != slugStyleASCII && cfg.SlugStyle != slugStyleUnicode {
+			slog.Error("Invalid --slug-style. Must be 'ascii' or 'unicode'.")
+			os.Exit(1)
+		}
+		if cfg.OutputFormat == "" {
+			slog.Warn("--slug-style only affects --output-format notes; ignoring")
+		}
+	}
+
+	if cfg.AnkiDeck != "" {
+		if err := validateAnkiDeckPath(cfg.AnkiDeck); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if err := setGrainURLs(cfg.GrainBaseURL, cfg.GrainAPIURL); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if hostDelayStr != "" {
+		hd, err := parseHostDelays(hostDelayStr)
+		if err != nil {
+			slog.Error(err.Error())
 			os.Exit(1)
 		}
+		cfg.HostDelays = hd
+	}
+
+	if len(apiHeaders) == 0 {
+		if env := envGet(dotenv, "GRAIN_API_HEADERS"); env != "" {
+			apiHeaders = strings.Split(env, ",")
+		}
+	}
+	if cfg.APIHeaders, err = parseAPIHeaders(apiHeaders); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if len(ignoreTitles) == 0 {
+		if env := envGet(dotenv, "GRAIN_IGNORE_TITLE_REGEX"); env != "" {
+			ignoreTitles = strings.Split(env, "\n")
+		}
+	}
+	if cfg.IgnoreTitles, err = parseTitleRules(ignoreTitles, ignoreFile); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if strings.ContainsAny(cfg.APIUserAgent, "\r\n") {
+		slog.Error("Invalid --api-user-agent: line break in value")
+		os.Exit(1)
+	}
+
+	if cfg.BrowserBin != "" && cfg.BrowserRemote != "" {
+		slog.Error("--browser-bin and --browser-remote are mutually exclusive")
+		os.Exit(1)
+	}
+	if cfg.BrowserBin != "" {
+		if err := validateBrowserBin(cfg.BrowserBin); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+	if cfg.BrowserRemote != "" {
+		if err := validateBrowserRemote(cfg.BrowserRemote); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+	if cfg.NoDownloadBrowser && (cfg.BrowserBin != "" || cfg.BrowserRemote != "") {
+		slog.Warn("--no-download-browser has no effect with --browser-bin or --browser-remote; ignoring it")
+		cfg.NoDownloadBrowser = false
+	}
+
+	if claimTTLStr != "" {
+		dur, err := time.ParseDuration(claimTTLStr)
+		if err != nil || dur < 0 {
+			slog.Error("Invalid --claim-ttl value", "value", claimTTLStr)
+			os.Exit(1)
+		}
+		if dur > 0 && dur < 1*time.Minute {
+			slog.Error("--claim-ttl must be at least 1m", "value", dur)
+			os.Exit(1)
+		}
+		cfg.ClaimTTL = dur
+	}
+
+	if dur, err := time.ParseDuration(progressStr); err != nil || dur < 0 {
+		slog.Error("Invalid --progress-interval value", "value", progressStr)
+		os.Exit(1)
+	} else {
+		cfg.ProgressInterval = dur
+	}
+
+	if cfg.HLSConcurrency < 1 {
+		cfg.HLSConcurrency = 1
+	}
+
+	if cfg.RecordHTTP != "" || cfg.ReplayHTTP != "" {
+		if _, _, err := newHTTPTap(&cfg); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		if cfg.RecordHTTP != "" && !cfg.TUI {
+			slog.Info(fmt.Sprintf("Recording HTTP fixtures to %s (review before sharing: they contain meeting content)", absPath(cfg.RecordHTTP)))
+		}
+		if cfg.ReplayHTTP != "" && !cfg.TUI {
+			slog.Info(fmt.Sprintf("Replaying HTTP fixtures from %s", absPath(cfg.ReplayHTTP)))
+		}
+	}
+	if cfg.HLSDownload && !cfg.SkipVideo && !cfg.AudioOnly && checkFFmpeg() != nil {
+		slog.Warn("--hls-download without ffmpeg: streams will be saved as .ts (no MP4 remux)")
+	}
+
+	cfg.ReadwiseToken = strings.TrimSpace(coalesce(cfg.ReadwiseToken, envGet(dotenv, "GRAIN_READWISE_TOKEN")))
+
+	if cfg.Embeddings != "" {
+		if strings.EqualFold(cfg.Embeddings, embeddingsOpenAI) {
+			cfg.OpenAIKey = strings.TrimSpace(envGet(dotenv, "OPENAI_API_KEY"))
+		}
+		if err := configureEmbeddings(&cfg); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+	} else if cfg.EmbeddingsOut != "" || cfg.EmbeddingsModel != "" || cfg.EmbeddingsURL != "" {
+		slog.Warn("--embeddings-out, --embeddings-model, and --embeddings-url need --embeddings; ignoring")
+	}
+
+	cfg.AlertKeywords = parseKeywords(alertKeywords)
+	if cfg.AlertWebhook != "" {
+		if len(cfg.AlertKeywords) == 0 {
+			slog.Error("--alert-webhook requires --alert-keywords")
+			os.Exit(1)
+		}
+		if !strings.HasPrefix(cfg.AlertWebhook, "https://") && !strings.HasPrefix(cfg.AlertWebhook, "http://") {
+			slog.Error("Invalid --alert-webhook. Must be an http(s) URL.")
+			os.Exit(1)
+		}
+	}
+
+	if err := finishMirrorConfig(&cfg, dotenv); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if cfg.AppleNotes {
+		if runtime.GOOS != "darwin" {
+			slog.Error("--apple-notes is only supported on macOS")
+			os.Exit(1)
+		}
+		if cfg.OutputFormat == "" {
+			slog.Error("--apple-notes requires --output-format (the note is the formatted markdown)")
+			os.Exit(1)
+		}
+	}
+	if cfg.Spotlight && runtime.GOOS != "darwin" {
+		slog.Error("--spotlight is only supported on macOS")
+		os.Exit(1)
+	}
+	if cfg.NotifyDesktop {
+		if _, err := exec.LookPath(notifyTool(runtime.GOOS)); err != nil {
+			slog.Warn(fmt.Sprintf("--notify-desktop needs %s on PATH; no notifications will be shown", notifyTool(runtime.GOOS)))
+		}
 	}
 	if cfg.GDrive {
-		if cfg.GDriveFolderID == "" {
-			slog.Error("--gdrive requires --gdrive-folder-id")
+		if err := finishGDriveConfig(&cfg, *gdriveRoutes); err != nil {
+			slog.Error(err.Error())
 			os.Exit(1)
 		}
-		if cfg.GDriveCredentials == "" {
-			slog.Error("--gdrive requires --gdrive-credentials")
+	}
+	if keepVideosStr != "" {
+		if cfg.GDriveKeepVideos, err = parseKeepVideos(keepVideosStr); err != nil {
+			slog.Error(err.Error())
 			os.Exit(1)
 		}
-		switch cfg.GDriveConflict {
-		case "local-wins", "skip", "newer-wins":
-			// valid
-		default:
-			slog.Error("Invalid --gdrive-conflict. Must be 'local-wins', 'skip', or 'newer-wins'.")
+		if cfg.GDriveCleanLocal {
+			slog.Error("--gdrive-keep-videos and --gdrive-clean-local are mutually exclusive")
 			os.Exit(1)
 		}
-		if cfg.GDriveTokenFile == "" {
-			cfg.GDriveTokenFile = filepath.Join(cfg.SessionDir, "gdrive-token.json")
+		if !cfg.GDrive {
+			slog.Warn("--gdrive-keep-videos only applies with --gdrive; ignoring")
 		}
 	}
+	if chaosStr != "" {
+		if cfg.Chaos, err = parseChaos(chaosStr); err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		slog.Warn("Chaos mode: failures will be injected on purpose", "chaos", chaosStr)
+	}
+	if err := checkOutputRoots(&cfg); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if cfg.ClassifyRules, err = parseClassifyRules(classifyStr); err != nil {
+		slog.Error(fmt.Sprintf("invalid --classify: %v", err))
+		os.Exit(1)
+	}
+	if cfg.ClassifyRoutes, err = parseClassifyRoutes(classifyRouteStr); err != nil {
+		slog.Error(fmt.Sprintf("invalid --classify-route: %v", err))
+		os.Exit(1)
+	}
+	if len(cfg.ClassifyRoutes) > 0 && (len(cfg.ClassifyRules) == 0 || !cfg.GDrive) {
+		slog.Warn("--classify-route only applies with --classify and --gdrive; ignoring")
+		cfg.ClassifyRoutes = nil
+	}
+
+	reportEnvProblems(dotenv)
 
 	if !cfg.TUI {
 		slog.Info(fmt.Sprintf("graindl %s", version))
 		slog.Info(fmt.Sprintf("Output: %s", absPath(cfg.OutputDir)))
+		for _, class := range []string{outputMarkdown, outputVideo, outputData} {
+			if dir := outputRoots(&cfg)[class]; dir != "" {
+				slog.Info(fmt.Sprintf("Output (%s): %s", class, absPath(dir)))
+			}
+		}
 		slog.Info(fmt.Sprintf("Throttle: %.1f–%.1fs random delay", cfg.MinDelaySec, cfg.MaxDelaySec))
 		if cfg.Parallel > 1 {
-			slog.Info(fmt.Sprintf("Parallel: %d workers", cfg.Parallel))
+			isolation := ""
+			if cfg.IsolateWorkers {
+				isolation = " (isolated browser contexts)"
+			}
+			if cfg.AutoParallel {
+				slog.Info(fmt.Sprintf("Parallel: auto, starting at %d of up to %d workers%s", max(1, cfg.Parallel/2), cfg.Parallel, isolation))
+			} else {
+				slog.Info(fmt.Sprintf("Parallel: %d workers%s", cfg.Parallel, isolation))
+			}
 		}
 	}
 	if cfg.AudioOnly {
@@ -256,8 +945,15 @@ func main() {
 	} else if cfg.SkipVideo && !cfg.TUI {
 		slog.Info("Video: skipped")
 	}
+	if cfg.DeferVideos && !cfg.TUI {
+		slog.Info("Video: deferred to graindl download-videos")
+	}
 	if cfg.Watch && !cfg.TUI {
-		slog.Info(fmt.Sprintf("Watch: polling every %s (Ctrl-C to stop)", cfg.WatchInterval))
+		if cfg.WatchSchedule != nil {
+			slog.Info(fmt.Sprintf("Watch: on schedule %q (Ctrl-C to stop)", cfg.WatchSchedule))
+		} else {
+			slog.Info(fmt.Sprintf("Watch: polling every %s (Ctrl-C to stop)", cfg.WatchInterval))
+		}
 	}
 	if cfg.OutputFormat != "" && !cfg.TUI {
 		slog.Info(fmt.Sprintf("Format: %s", cfg.OutputFormat))
@@ -265,38 +961,90 @@ func main() {
 	if cfg.ICloud && !cfg.TUI {
 		slog.Info(fmt.Sprintf("iCloud: %s", cfg.ICloudPath))
 	}
+	if cfg.WebDAVURL != "" && !cfg.TUI {
+		slog.Info(fmt.Sprintf("WebDAV: %s", cfg.WebDAVURL))
+	}
+	if cfg.S3.Bucket != "" && !cfg.TUI {
+		slog.Info(fmt.Sprintf("S3: %s", cfg.S3.objectURL(cfg.S3.Prefix)))
+	}
+	if cfg.AppleNotes && !cfg.TUI {
+		if cfg.AppleNotesShortcut != "" {
+			slog.Info(fmt.Sprintf("Apple Notes: via Shortcut %q", cfg.AppleNotesShortcut))
+		} else {
+			slog.Info(fmt.Sprintf("Apple Notes: folder %q", coalesce(cfg.AppleNotesFolder, appleNotesDefaultFolder)))
+		}
+	}
+	if len(cfg.AlertKeywords) > 0 && !cfg.TUI {
+		slog.Info(fmt.Sprintf("Alerts: %s", strings.Join(cfg.AlertKeywords, ", ")))
+	}
+	if cfg.ReadwiseToken != "" && !cfg.TUI {
+		slog.Info("Readwise: pushing new highlights after each run")
+	}
+	if cfg.Embeddings != "" && !cfg.TUI {
+		slog.Info(fmt.Sprintf("Embeddings: %s (%s) → %s after each run", cfg.Embeddings, cfg.EmbeddingsModel, cfg.EmbeddingsOut))
+	}
 	if cfg.GDrive && !cfg.TUI {
-		slog.Info(fmt.Sprintf("Google Drive: enabled (folder=%s, conflict=%s)", cfg.GDriveFolderID, cfg.GDriveConflict))
+		slog.Info(fmt.Sprintf("Google Drive: enabled (folder=%s, conflict=%s)", driveFolderLabel(&cfg), cfg.GDriveConflict))
+	}
+
+	if checkConfig {
+		os.Exit(checkConfigResult(warnings.Warnings(), cfg.Strict))
+	}
+
+	sess, err := unlockSession(&cfg, sessionPassphrase)
+	if err != nil {
+		slog.Error("Encrypted session", "error", err)
+		os.Exit(1)
+	}
+	if sess != nil && !cfg.TUI {
+		slog.Info(fmt.Sprintf("Session: encrypted (%s)", sess.path))
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	code := runExporter(ctx, &cfg)
+	stop()
+
+	// The browser has released the profile; seal it before exiting.
+	if !closeSession(sess) {
+		code = max(code, 1)
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+}
 
+// runExporter runs the export (or watch loop, or TUI) and returns the
+// process exit code.
+func runExporter(ctx context.Context, cfg *Config) int {
 	// TUI mode: delegate to Bubble Tea and exit.
 	if cfg.TUI {
-		if err := runTUI(ctx, &cfg); err != nil {
+		if err := runTUI(ctx, cfg); err != nil {
 			fmt.Fprintf(os.Stderr, "TUI error: %v\n", err)
-			os.Exit(1)
+			return exitCode(err)
 		}
-		return
+		return 0
 	}
 
-	exp, err := NewExporter(ctx, &cfg)
+	exp, err := NewExporter(ctx, cfg)
 	if err != nil {
 		slog.Error("Init failed", "error", err)
-		os.Exit(1)
+		return 1
 	}
 	defer exp.Close()
 
 	if cfg.Watch {
-		if err := exp.RunWatch(ctx); err != nil {
-			slog.Error("Fatal", "error", err)
-			os.Exit(1)
-		}
+		err = exp.RunWatch(ctx)
 	} else {
-		if err := exp.Run(ctx); err != nil {
-			slog.Error("Fatal", "error", err)
-			os.Exit(1)
-		}
+		err = exp.Run(ctx)
+	}
+	if errors.Is(err, errPickCancelled) {
+		slog.Info("Nothing selected; no meetings exported")
+		return 0
+	}
+	if errors.Is(err, errStrictFailed) {
+		slog.Error("Strict mode: failing the run", "error", err)
+	} else if err != nil {
+		slog.Error("Fatal", "error", err)
 	}
+	return exitCode(err)
 }
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ── Manifest Query ──────────────────────────────────────────────────────────
+//
+// `graindl manifest query` filters the meetings in _export-manifest.json
+// (or, with --delta, the last run's _delta.json) by status, date, and video
+// method and prints the fields asked for, so scripts don't have to depend on
+// the manifest's layout through jq:
+//
+//	graindl manifest query --status error --format json
+//	graindl manifest query --since 30d --video-method none --fields id,title
+//
+// Fields are the manifest's JSON keys plus "date" (the meeting date from
+// date_dir) and, with --delta, "change" (new, updated, or failed).
+
+// manifestDefaultFields are the columns printed without --fields in text
+// format; JSON prints every field by default.
+var manifestDefaultFields = []string{"id", "date", "status", "video_method", "title"}
+
+var manifestDateRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// manifestFilter selects manifest entries. Empty fields match everything.
+type manifestFilter struct {
+	Statuses     []string
+	VideoMethods []string // "none" matches meetings without a video method
+	Since, Until time.Time
+}
+
+// manifestRow is one queried entry: the export result and, for delta
+// entries, which delta list it came from.
+type manifestRow struct {
+	Result *ExportResult
+	Change string
+}
+
+// resultDate returns the meeting date in r's date_dir, or "".
+func resultDate(r *ExportResult) string {
+	dates := manifestDateRe.FindAllString(filepath.ToSlash(r.DateDir), -1)
+	if len(dates) == 0 {
+		return ""
+	}
+	return dates[len(dates)-1]
+}
+
+func (f manifestFilter) match(r *ExportResult) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, r.Status) {
+		return false
+	}
+	if len(f.VideoMethods) > 0 && !slices.Contains(f.VideoMethods, coalesce(r.VideoMethod, "none")) {
+		return false
+	}
+	if f.Since.IsZero() && f.Until.IsZero() {
+		return true
+	}
+	// Meeting dates are days, so the bounds compare by day (ISO dates sort
+	// as strings).
+	d := resultDate(r)
+	if d == "" {
+		return false
+	}
+	if !f.Since.IsZero() && d < f.Since.Format("2006-01-02") {
+		return false
+	}
+	return f.Until.IsZero() || d <= f.Until.Format("2006-01-02")
+}
+
+// loadManifestRows reads the manifest, or the delta feed when delta is set.
+func loadManifestRows(outputDir string, delta bool) ([]manifestRow, error) {
+	name := "_export-manifest.json"
+	if delta {
+		name = deltaFile
+	}
+	data, err := os.ReadFile(filepath.Join(outputDir, name))
+	if err != nil {
+		return nil, err
+	}
+	var rows []manifestRow
+	if delta {
+		var d ExportDelta
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		for _, list := range []struct {
+			change  string
+			results []*ExportResult
+		}{{"new", d.New}, {"updated", d.Updated}, {"failed", d.Failed}} {
+			for _, r := range list.results {
+				if r != nil {
+					rows = append(rows, manifestRow{Result: r, Change: list.change})
+				}
+			}
+		}
+		return rows, nil
+	}
+	var m ExportManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", name, err)
+	}
+	for _, r := range m.Meetings {
+		if r != nil {
+			rows = append(rows, manifestRow{Result: r})
+		}
+	}
+	return rows, nil
+}
+
+// manifestFields lists the fields a query can select.
+func manifestFields(delta bool) []string {
+	fields := []string{"date"}
+	if delta {
+		fields = append(fields, "change")
+	}
+	t := reflect.TypeOf(ExportResult{})
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// project returns row's values for fields, in order. Fields the entry
+// doesn't have are nil.
+func (row manifestRow) project(fields []string) ([]any, error) {
+	data, err := json.Marshal(row.Result)
+	if err != nil {
+		return nil, err
+	}
+	all := map[string]any{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&all); err != nil {
+		return nil, err
+	}
+	if d := resultDate(row.Result); d != "" {
+		all["date"] = d
+	}
+	if row.Change != "" {
+		all["change"] = row.Change
+	}
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		values[i] = all[f]
+	}
+	return values, nil
+}
+
+// orderedObject marshals as a JSON object with keys in the given order.
+type orderedObject struct {
+	keys   []string
+	values []any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func writeManifestJSON(w io.Writer, rows []manifestRow, fields []string) error {
+	out := make([]any, 0, len(rows))
+	for _, row := range rows {
+		if fields == nil {
+			out = append(out, row.Result)
+			continue
+		}
+		values, err := row.project(fields)
+		if err != nil {
+			return err
+		}
+		out = append(out, orderedObject{keys: fields, values: values})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeManifestText(w io.Writer, rows []manifestRow, fields []string) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(fields, "\t")))
+	for _, row := range rows {
+		values, err := row.project(fields)
+		if err != nil {
+			return err
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			switch v := v.(type) {
+			case nil:
+			case string:
+				cells[i] = v
+			default:
+				b, _ := json.Marshal(v)
+				cells[i] = string(b)
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func runManifest(args []string) int {
+	if len(args) == 0 || args[0] != "query" {
+		fmt.Fprintln(os.Stderr, "usage: graindl manifest query [flags]")
+		return 2
+	}
+
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("manifest query", flag.ContinueOnError)
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory holding the manifest")
+	delta := fs.Bool("delta", false, "Query the last run's _delta.json instead of the manifest")
+	status := fs.String("status", "", "Only meetings with these statuses, comma-separated (e.g. error,auth-blocked)")
+	videoMethod := fs.String("video-method", "", "Only meetings with these video methods, comma-separated (none = no video)")
+	since := fs.String("since", "", "Only meetings dated on or after (e.g. 30d, 2w, 2024-11-01)")
+	until := fs.String("until", "", "Only meetings dated on or before this day (YYYY-MM-DD)")
+	fieldList := fs.String("fields", "", "Fields to print, comma-separated (default: id,date,status,video_method,title; json: all)")
+	format := fs.String("format", "text", "Output format: text (table), json")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	asJSON := false
+	switch strings.ToLower(*format) {
+	case "text":
+	case "json":
+		asJSON = true
+	default:
+		slog.Error("Invalid --format (must be text or json)", "value", *format)
+		return 2
+	}
+	fields := parseKeywords(*fieldList)
+	valid := manifestFields(*delta)
+	for _, f := range fields {
+		if !slices.Contains(valid, f) {
+			slog.Error(fmt.Sprintf("Unknown --fields entry %q (valid: %s)", f, strings.Join(valid, ", ")))
+			return 2
+		}
+	}
+	if len(fields) == 0 && !asJSON {
+		fields = manifestDefaultFields
+		if *delta {
+			fields = append([]string{"change"}, fields...)
+		}
+	}
+
+	now := time.Now()
+	filter := manifestFilter{Statuses: parseKeywords(*status), VideoMethods: parseKeywords(*videoMethod)}
+	var err error
+	if filter.Since, err = parseSince(*since, now); err != nil {
+		slog.Error(err.Error())
+		return 2
+	}
+	if *until != "" {
+		if filter.Until, err = time.ParseInLocation("2006-01-02", *until, now.Location()); err != nil {
+			slog.Error("Invalid --until (use YYYY-MM-DD)", "value", *until)
+			return 2
+		}
+	}
+
+	rows, err := loadManifestRows(*outputDir, *delta)
+	if err != nil {
+		slog.Error("Manifest query failed", "error", err)
+		return 1
+	}
+	matched := rows[:0]
+	for _, row := range rows {
+		if filter.match(row.Result) {
+			matched = append(matched, row)
+		}
+	}
+	slog.Debug("Manifest query", "entries", len(rows), "matched", len(matched))
+
+	if asJSON {
+		err = writeManifestJSON(os.Stdout, matched, fields)
+	} else {
+		err = writeManifestText(os.Stdout, matched, fields)
+	}
+	if err != nil {
+		slog.Error("Manifest query failed", "error", err)
+		return 1
+	}
+	return 0
+}
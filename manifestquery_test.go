@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManifestFilter(t *testing.T) {
+	results := []*ExportResult{
+		{ID: "a", DateDir: "2024-11-15", Status: "ok", VideoMethod: "button"},
+		{ID: "b", DateDir: "shared/2024-11-20", Status: "error"},
+		{ID: "c", DateDir: "2024-12-02", Status: "skipped", VideoMethod: "direct"},
+		{ID: "d", Status: statusAuthBlocked},
+	}
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	for _, tc := range []struct {
+		name   string
+		filter manifestFilter
+		want   string
+	}{
+		{"all", manifestFilter{}, "a,b,c,d"},
+		{"status", manifestFilter{Statuses: []string{"error", statusAuthBlocked}}, "b,d"},
+		{"no video", manifestFilter{VideoMethods: []string{"none"}}, "b,d"},
+		{"video method", manifestFilter{VideoMethods: []string{"button", "direct"}}, "a,c"},
+		{"since", manifestFilter{Since: day("2024-11-20")}, "b,c"},
+		{"range", manifestFilter{Since: day("2024-11-01"), Until: day("2024-11-20")}, "a,b"},
+	} {
+		var got []string
+		for _, r := range results {
+			if tc.filter.match(r) {
+				got = append(got, r.ID)
+			}
+		}
+		if strings.Join(got, ",") != tc.want {
+			t.Errorf("%s: matched %v, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLoadManifestRowsDelta(t *testing.T) {
+	dir := t.TempDir()
+	d := ExportDelta{
+		New:    []*ExportResult{{ID: "n", DateDir: "2024-11-15", Status: "ok"}},
+		Failed: []*ExportResult{{ID: "f", DateDir: "2024-11-16", Status: "error"}},
+	}
+	data, _ := json.Marshal(d)
+	if err := os.WriteFile(filepath.Join(dir, deltaFile), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := loadManifestRows(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Change != "new" || rows[1].Change != "failed" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if _, err := loadManifestRows(dir, false); err == nil {
+		t.Error("missing manifest not reported")
+	}
+}
+
+func TestWriteManifestQuery(t *testing.T) {
+	rows := []manifestRow{
+		{Result: &ExportResult{ID: "a", Title: "Standup", DateDir: "2024-11-15", Status: "ok", AlertMatches: 2}},
+		{Result: &ExportResult{ID: "b", DateDir: "2024-11-20", Status: "error", ErrorMsg: "timeout"}, Change: "failed"},
+	}
+
+	var js strings.Builder
+	if err := writeManifestJSON(&js, rows, []string{"id", "date", "alert_matches", "change"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+  {
+    "id": "a",
+    "date": "2024-11-15",
+    "alert_matches": 2,
+    "change": null
+  },
+  {
+    "id": "b",
+    "date": "2024-11-20",
+    "alert_matches": null,
+    "change": "failed"
+  }
+]
+`
+	if js.String() != want {
+		t.Errorf("json =\n%s\nwant\n%s", js.String(), want)
+	}
+
+	var text strings.Builder
+	if err := writeManifestText(&text, rows, []string{"id", "status", "error_msg"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[2], "timeout") {
+		t.Errorf("text =\n%s", text.String())
+	}
+
+	if fields := manifestFields(false); !strings.Contains(strings.Join(fields, ","), "date,id,title,date_dir,status") {
+		t.Errorf("fields = %v", fields)
+	}
+}
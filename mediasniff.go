@@ -0,0 +1,375 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ── Media Sniffing ──────────────────────────────────────────────────────────
+//
+// Downloaded videos are saved as <id>.mp4, but Grain sometimes serves WebM,
+// another container, or a zip of assets. After a button or direct download
+// the file's magic bytes decide what it really is:
+//
+//	mp4                         kept as .mp4
+//	webm                        renamed to .webm
+//	matroska, quicktime, mpegts remuxed to .mp4 with ffmpeg (stream copy);
+//	                            kept under their own extension without it
+//	zip                         the largest video inside is extracted and
+//	                            sniffed in turn; other assets stay in
+//	                            <id>.assets.zip
+//	anything else (HTML, ...)   removed; the download failed
+//
+// The container and codecs are recorded as "media" in the metadata and
+// manifest. Codecs are read from the headers (MP4 sample descriptions,
+// Matroska CodecIDs) without ffprobe, so they are empty for MPEG-TS.
+
+// mediaSniffBytes is how much of each end of a file is read for codecs.
+// MP4's moov box, which describes the tracks, may sit at either end.
+const mediaSniffBytes = 1 << 20
+
+// maxUnpackedVideoBytes caps a video extracted from a downloaded zip.
+const maxUnpackedVideoBytes = 16 << 30
+
+// assetsSuffix is the zip of non-video assets kept next to an unpacked video.
+const assetsSuffix = ".assets.zip"
+
+// errNotVideo means a download was not a recognizable video.
+var errNotVideo = errors.New("downloaded file is not a video")
+
+// MediaInfo is the real format of a downloaded video.
+type MediaInfo struct {
+	Container     string `json:"container"` // mp4, webm, matroska, quicktime, mpegts
+	MIME          string `json:"mime"`
+	VideoCodec    string `json:"video_codec,omitempty"`
+	AudioCodec    string `json:"audio_codec,omitempty"`
+	ConvertedFrom string `json:"converted_from,omitempty"` // original container when remuxed or unpacked
+}
+
+// containerExts maps containers to the extension a file of that kind gets.
+var containerExts = map[string]string{
+	"mp4":       ".mp4",
+	"webm":      ".webm",
+	"matroska":  ".mkv",
+	"quicktime": ".mov",
+	"mpegts":    ".ts",
+}
+
+var containerMIME = map[string]string{
+	"mp4":       "video/mp4",
+	"webm":      "video/webm",
+	"matroska":  "video/x-matroska",
+	"quicktime": "video/quicktime",
+	"mpegts":    "video/mp2t",
+}
+
+// mp4Codecs maps ISO BMFF sample entry types to codec names.
+var mp4Codecs = map[string]struct{ kind, name string }{
+	"avc1": {"video", "h264"}, "avc3": {"video", "h264"},
+	"hvc1": {"video", "hevc"}, "hev1": {"video", "hevc"},
+	"av01": {"video", "av1"}, "vp09": {"video", "vp9"}, "mp4v": {"video", "mpeg4"},
+	"mp4a": {"audio", "aac"}, "Opus": {"audio", "opus"}, "fLaC": {"audio", "flac"},
+	"ac-3": {"audio", "ac3"}, "ec-3": {"audio", "eac3"},
+}
+
+// matroskaCodecs maps Matroska CodecID prefixes to codec names.
+var matroskaCodecs = []struct{ prefix, kind, name string }{
+	{"V_VP8", "video", "vp8"}, {"V_VP9", "video", "vp9"}, {"V_AV1", "video", "av1"},
+	{"V_MPEG4/ISO/AVC", "video", "h264"}, {"V_MPEGH/ISO/HEVC", "video", "hevc"},
+	{"A_OPUS", "audio", "opus"}, {"A_VORBIS", "audio", "vorbis"}, {"A_AAC", "audio", "aac"},
+	{"A_FLAC", "audio", "flac"},
+}
+
+// sniffContainer identifies a file from its first bytes: a key of
+// containerExts, "zip", or "" when unrecognized.
+func sniffContainer(head []byte) string {
+	switch {
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		if string(head[8:12]) == "qt  " {
+			return "quicktime"
+		}
+		return "mp4"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		if bytes.Contains(head[:min(len(head), 64)], []byte("webm")) {
+			return "webm"
+		}
+		return "matroska"
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return "zip"
+	case len(head) > 188 && head[0] == 0x47 && head[188] == 0x47:
+		return "mpegts"
+	}
+	return ""
+}
+
+// probeMedia reads the container and codecs of the file at path.
+func probeMedia(path string) (*MediaInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, min(info.Size(), mediaSniffBytes))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return nil, err
+	}
+	m := &MediaInfo{Container: sniffContainer(head)}
+	m.MIME = containerMIME[m.Container]
+
+	data := head
+	if info.Size() > 2*mediaSniffBytes {
+		tail := make([]byte, mediaSniffBytes)
+		if _, err := f.ReadAt(tail, info.Size()-mediaSniffBytes); err == nil {
+			data = append(append([]byte(nil), head...), tail...)
+		}
+	} else if info.Size() > mediaSniffBytes {
+		rest, _ := io.ReadAll(f)
+		data = append(head, rest...)
+	}
+	switch m.Container {
+	case "mp4", "quicktime":
+		m.VideoCodec, m.AudioCodec = mp4CodecsIn(data)
+	case "webm", "matroska":
+		m.VideoCodec, m.AudioCodec = matroskaCodecsIn(data)
+	}
+	return m, nil
+}
+
+// mp4CodecsIn reads the first video and audio sample entry type from each
+// stsd box in data.
+func mp4CodecsIn(data []byte) (video, audio string) {
+	for i := 0; ; {
+		j := bytes.Index(data[i:], []byte("stsd"))
+		if j < 0 {
+			return video, audio
+		}
+		// 'stsd' version/flags(4) entry_count(4), then entry size(4) type(4)
+		at := i + j + 4 + 4 + 4 + 4
+		if at+4 <= len(data) {
+			if c, ok := mp4Codecs[string(data[at:at+4])]; ok {
+				if c.kind == "video" && video == "" {
+					video = c.name
+				} else if c.kind == "audio" && audio == "" {
+					audio = c.name
+				}
+			}
+		}
+		i += j + 4
+	}
+}
+
+// matroskaCodecsIn reads CodecID elements (ID 0x86, one-byte size) in data.
+func matroskaCodecsIn(data []byte) (video, audio string) {
+	for i := 0; i+2 < len(data); i++ {
+		if data[i] != 0x86 || data[i+1]&0x80 == 0 {
+			continue
+		}
+		n := int(data[i+1] & 0x7F)
+		if i+2+n > len(data) {
+			continue
+		}
+		id := string(data[i+2 : i+2+n])
+		for _, c := range matroskaCodecs {
+			if !strings.HasPrefix(id, c.prefix) {
+				continue
+			}
+			if c.kind == "video" && video == "" {
+				video = c.name
+			} else if c.kind == "audio" && audio == "" {
+				audio = c.name
+			}
+		}
+	}
+	return video, audio
+}
+
+// remuxFunc rewrites the video at in into an MP4 at out.
+type remuxFunc func(ctx context.Context, in, out string) error
+
+// ffmpegRemuxer returns a stream-copy remux into MP4, or nil when ffmpeg is
+// not on PATH.
+func ffmpegRemuxer(verbose bool) remuxFunc {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil
+	}
+	return func(ctx context.Context, in, out string) error {
+		return runFFmpeg(ctx, verbose, "-i", in, "-c", "copy", "-movflags", "+faststart", "-f", "mp4", "-y", out)
+	}
+}
+
+// normalizeVideo checks the downloaded file at path (<base>.mp4) and
+// converts, renames or unpacks it as described above. It returns the
+// final path, the path of a kept assets zip (or ""), and the media info.
+func (e *Exporter) normalizeVideo(ctx context.Context, path string) (string, string, *MediaInfo, error) {
+	m, err := probeMedia(path)
+	if err != nil {
+		return "", "", nil, err
+	}
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	assets, from := "", ""
+
+	if m.Container == "zip" {
+		extracted, kept, err := unpackVideoZip(path, base)
+		if err != nil {
+			// Keep what was downloaded; it may still be useful by hand.
+			if os.Rename(path, base+assetsSuffix) == nil {
+				assets = base + assetsSuffix
+			}
+			return "", assets, nil, err
+		}
+		if kept {
+			assets = base + assetsSuffix
+		}
+		if m, err = probeMedia(extracted); err != nil {
+			return "", assets, nil, err
+		}
+		path, from = extracted, "zip"
+	}
+
+	switch m.Container {
+	case "matroska", "quicktime", "mpegts":
+		if e.remux == nil {
+			break
+		}
+		out, err := e.remuxToMP4(ctx, path, base)
+		if err != nil {
+			slog.Warn("Remux to MP4 failed, keeping original container", "container", m.Container, "error", err)
+			break
+		}
+		remuxed, err := probeMedia(out)
+		if err != nil {
+			return "", assets, nil, err
+		}
+		remuxed.ConvertedFrom = coalesce(from, m.Container)
+		return out, assets, remuxed, nil
+	case "mp4", "webm":
+	default:
+		_ = os.Remove(path)
+		return "", assets, nil, errNotVideo
+	}
+
+	want := base + containerExts[m.Container]
+	if path != want {
+		if err := os.Rename(path, want); err != nil {
+			return "", assets, nil, err
+		}
+	}
+	m.ConvertedFrom = from
+	return want, assets, m, nil
+}
+
+// remuxToMP4 stream-copies the video at path into <base>.mp4 and removes
+// the original.
+func (e *Exporter) remuxToMP4(ctx context.Context, path, base string) (string, error) {
+	out := base + ".mp4"
+	tmp := out + ".part"
+	if err := e.remux(ctx, path, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := fixPerms(tmp); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, out); err != nil {
+		return "", err
+	}
+	if path != out {
+		_ = os.Remove(path)
+	}
+	return out, nil
+}
+
+// unpackVideoZip extracts the largest video in the zip at path to
+// <base>.unpacked. The zip is kept as <base>.assets.zip when it holds
+// anything else, and removed otherwise.
+func unpackVideoZip(path, base string) (string, bool, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", false, fmt.Errorf("open zip: %w", err)
+	}
+	var video *zip.File
+	others := 0
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if zipEntryIsVideo(f) && (video == nil || f.UncompressedSize64 > video.UncompressedSize64) {
+			if video != nil {
+				others++
+			}
+			video = f
+			continue
+		}
+		others++
+	}
+	if video == nil {
+		zr.Close()
+		return "", false, fmt.Errorf("%w: zip without a video", errNotVideo)
+	}
+	if video.UncompressedSize64 > maxUnpackedVideoBytes {
+		zr.Close()
+		return "", false, fmt.Errorf("zipped video too large (%s)", formatBytes(int64(video.UncompressedSize64)))
+	}
+
+	out := base + ".unpacked"
+	err = extractZipEntry(video, out)
+	zr.Close()
+	if err != nil {
+		_ = os.Remove(out)
+		return "", false, err
+	}
+	if others == 0 {
+		_ = os.Remove(path)
+		return out, false, nil
+	}
+	if err := os.Rename(path, base+assetsSuffix); err != nil {
+		return "", false, err
+	}
+	return out, true, nil
+}
+
+// zipEntryIsVideo reports whether a zip entry looks like a video by its
+// magic bytes.
+func zipEntryIsVideo(f *zip.File) bool {
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(rc, head)
+	c := sniffContainer(head[:n])
+	return c != "" && c != "zip"
+}
+
+// extractZipEntry writes f to out (0o600), reading no more than its
+// declared size.
+func extractZipEntry(f *zip.File, out string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	w, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, io.LimitReader(rc, int64(f.UncompressedSize64))); err != nil {
+		w.Close()
+		return fmt.Errorf("unzip %s: %w", f.Name, err)
+	}
+	return w.Close()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Minimal container headers: enough for sniffing and codec detection.
+func testMP4(videoFourCC, audioFourCC string) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0, 0, 0, 0x18})
+	b.WriteString("ftypisom\x00\x00\x02\x00isomiso2")
+	for _, cc := range []string{videoFourCC, audioFourCC} {
+		b.Write([]byte{0, 0, 0, 0x20})
+		b.WriteString("stsd\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x10")
+		b.WriteString(cc)
+	}
+	b.Write(make([]byte, 2048))
+	return b.Bytes()
+}
+
+func testMatroska(docType string, codecs ...string) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x82, 0x80 | byte(len(docType))})
+	b.WriteString(docType)
+	for _, c := range codecs {
+		b.Write([]byte{0x86, 0x80 | byte(len(c))})
+		b.WriteString(c)
+	}
+	b.Write(make([]byte, 2048))
+	return b.Bytes()
+}
+
+func TestSniffContainer(t *testing.T) {
+	ts := make([]byte, 400)
+	ts[0], ts[188] = 0x47, 0x47
+	for name, tc := range map[string]struct {
+		head []byte
+		want string
+	}{
+		"mp4":  {testMP4("avc1", "mp4a"), "mp4"},
+		"mov":  {[]byte("\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00"), "quicktime"},
+		"webm": {testMatroska("webm"), "webm"},
+		"mkv":  {testMatroska("matroska"), "matroska"},
+		"zip":  {[]byte("PK\x03\x04rest"), "zip"},
+		"ts":   {ts, "mpegts"},
+		"html": {[]byte("<!DOCTYPE html><html>"), ""},
+	} {
+		if got := sniffContainer(tc.head); got != tc.want {
+			t.Errorf("%s: sniffContainer = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestProbeMediaCodecs(t *testing.T) {
+	dir := t.TempDir()
+	for name, tc := range map[string]struct {
+		data         []byte
+		video, audio string
+	}{
+		"a.mp4":  {testMP4("avc1", "mp4a"), "h264", "aac"},
+		"b.mp4":  {testMP4("hvc1", "Opus"), "hevc", "opus"},
+		"c.webm": {testMatroska("webm", "V_VP9", "A_OPUS"), "vp9", "opus"},
+		"d.mkv":  {testMatroska("matroska", "V_MPEG4/ISO/AVC", "A_AAC"), "h264", "aac"},
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, tc.data, 0o600)
+		m, err := probeMedia(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.VideoCodec != tc.video || m.AudioCodec != tc.audio || m.MIME == "" {
+			t.Errorf("%s: %+v, want %s/%s", name, m, tc.video, tc.audio)
+		}
+	}
+}
+
+func TestNormalizeVideo(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T, data []byte) (*Exporter, string) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "m1.mp4")
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}, path
+	}
+
+	t.Run("mp4 kept", func(t *testing.T) {
+		e, path := setup(t, testMP4("avc1", "mp4a"))
+		got, assets, m, err := e.normalizeVideo(ctx, path)
+		if err != nil || got != path || assets != "" || m.Container != "mp4" || m.ConvertedFrom != "" {
+			t.Errorf("got %q %q %+v %v", got, assets, m, err)
+		}
+	})
+
+	t.Run("webm renamed", func(t *testing.T) {
+		e, path := setup(t, testMatroska("webm", "V_VP8", "A_VORBIS"))
+		got, _, m, err := e.normalizeVideo(ctx, path)
+		if err != nil || filepath.Base(got) != "m1.webm" || m.VideoCodec != "vp8" || fileExists(path) {
+			t.Errorf("got %q %+v %v", got, m, err)
+		}
+	})
+
+	t.Run("matroska remuxed", func(t *testing.T) {
+		e, path := setup(t, testMatroska("matroska", "V_MPEG4/ISO/AVC"))
+		mkv := ""
+		e.remux = func(_ context.Context, in, out string) error {
+			mkv = in
+			return os.WriteFile(out, testMP4("avc1", "mp4a"), 0o644)
+		}
+		got, _, m, err := e.normalizeVideo(ctx, path)
+		if err != nil || got != path || m.Container != "mp4" || m.ConvertedFrom != "matroska" {
+			t.Fatalf("got %q %+v %v", got, m, err)
+		}
+		if mkv != path || fileExists(path+".part") {
+			t.Errorf("remux input %q, leftover part: %v", mkv, fileExists(path+".part"))
+		}
+		if info, _ := os.Stat(got); info.Mode().Perm() != 0o600 {
+			t.Errorf("perms = %04o", info.Mode().Perm())
+		}
+	})
+
+	t.Run("remux failure keeps container", func(t *testing.T) {
+		e, path := setup(t, testMatroska("matroska"))
+		e.remux = func(context.Context, string, string) error { return errors.New("ffmpeg failed") }
+		got, _, m, err := e.normalizeVideo(ctx, path)
+		if err != nil || filepath.Base(got) != "m1.mkv" || m.Container != "matroska" {
+			t.Errorf("got %q %+v %v", got, m, err)
+		}
+	})
+
+	t.Run("zip unpacked", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for name, data := range map[string][]byte{
+			"recording/clip.webm": testMatroska("webm", "V_VP9"),
+			"recording/thumb.jpg": []byte("\xff\xd8\xff not a video"),
+		} {
+			w, _ := zw.Create(name)
+			w.Write(data)
+		}
+		zw.Close()
+		e, path := setup(t, buf.Bytes())
+
+		got, assets, m, err := e.normalizeVideo(ctx, path)
+		if err != nil || filepath.Base(got) != "m1.webm" || m.ConvertedFrom != "zip" || m.VideoCodec != "vp9" {
+			t.Fatalf("got %q %+v %v", got, m, err)
+		}
+		if filepath.Base(assets) != "m1.assets.zip" || !fileExists(assets) || fileExists(path) {
+			t.Errorf("assets = %q", assets)
+		}
+	})
+
+	t.Run("html rejected", func(t *testing.T) {
+		e, path := setup(t, append([]byte("<!DOCTYPE html><title>Sign in</title>"), make([]byte, 2000)...))
+		if _, _, _, err := e.normalizeVideo(ctx, path); !errors.Is(err, errNotVideo) {
+			t.Errorf("err = %v", err)
+		}
+		if fileExists(path) {
+			t.Error("non-video download left in place")
+		}
+	})
+}
+
+func TestCheckVideoRecordsMedia(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
+	os.MkdirAll(filepath.Join(dir, "2025-06-01"), 0o755)
+
+	os.WriteFile(filepath.Join(dir, "2025-06-01", "a.mp4"), testMatroska("webm", "V_VP9", "A_OPUS"), 0o600)
+	r := &ExportResult{VideoPath: filepath.Join("2025-06-01", "a.mp4"), VideoMethod: "button"}
+	e.checkVideo(context.Background(), "a", nil, r)
+	if r.VideoPath != filepath.Join("2025-06-01", "a.webm") || r.Media == nil || r.Media.Container != "webm" || r.Media.AudioCodec != "opus" {
+		t.Errorf("result = %q %+v", r.VideoPath, r.Media)
+	}
+
+	os.WriteFile(filepath.Join(dir, "2025-06-01", "b.mp4"), []byte("<html>error</html>"), 0o600)
+	r = &ExportResult{VideoPath: filepath.Join("2025-06-01", "b.mp4"), VideoMethod: "direct"}
+	e.checkVideo(context.Background(), "b", nil, r)
+	if r.VideoPath != "" || r.VideoMethod != "failed" || r.Media != nil {
+		t.Errorf("non-video result = %q %q %+v", r.VideoPath, r.VideoMethod, r.Media)
+	}
+}
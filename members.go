@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// ── Workspace Members ───────────────────────────────────────────────────────
+//
+// `graindl members` exports the Grain workspace's member directory (name,
+// email, role) to <output>/members.json. The list is read from the members
+// endpoint of the workspace API through the logged-in browser session, so no
+// API token is needed; when that fails (the account is not an admin, or the
+// endpoint moved) the admin members page is scraped instead. The file
+// records which source it came from.
+//
+// Exports load members.json when it exists and use it twice:
+//
+//   - a participant whose name or email matches a member (case and spacing
+//     ignored) is written under the member's name, so the same person is
+//     spelled the same way in every meeting, and stats and search group
+//     them together;
+//   - a participant matching no member, and not at one of the members' email
+//     domains, is an external attendee: the metadata lists them under
+//     external_participants and the export logs it.
+//
+// Re-run `graindl members` after people join or leave; without the file
+// participants are exported as scraped.
+
+// membersFile is the member directory in the output dir.
+const membersFile = "members.json"
+
+// Grain locations the directory is read from.
+const (
+	membersAPIPath  = "/workspace/members"
+	membersPagePath = "/app/settings/workspace/members"
+)
+
+// Member directory sources.
+const (
+	membersSourceAPI    = "api"
+	membersSourceScrape = "scrape"
+)
+
+// MemberDirectory is the content of members.json.
+type MemberDirectory struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Source     string    `json:"source"` // "api" or "scrape"
+	Members    []Member  `json:"members"`
+
+	byKey   map[string]*Member // folded name or email →
+	domains map[string]bool    // members' email domains
+}
+
+// Member is one person in the workspace.
+type Member struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Role  string `json:"role,omitempty"`
+}
+
+// memberKey folds a name or email for matching.
+func memberKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// emailDomain returns the lowercased domain of an email address, or "".
+func emailDomain(email string) string {
+	if _, domain, ok := strings.Cut(email, "@"); ok {
+		return strings.ToLower(domain)
+	}
+	return ""
+}
+
+// newMemberDirectory builds a directory from members, dropping entries with
+// neither name nor email and merging duplicates by email. Members are
+// sorted by name.
+func newMemberDirectory(source string, members []Member) *MemberDirectory {
+	d := &MemberDirectory{ExportedAt: time.Now().UTC().Truncate(time.Second), Source: source}
+	seen := map[string]int{}
+	for _, m := range members {
+		m.Name = strings.Join(strings.Fields(m.Name), " ")
+		m.Email = strings.ToLower(strings.TrimSpace(m.Email))
+		m.Role = strings.ToLower(strings.TrimSpace(m.Role))
+		if m.Name == "" && m.Email == "" {
+			continue
+		}
+		if i, ok := seen[m.Email]; ok && m.Email != "" {
+			d.Members[i].Name = coalesce(d.Members[i].Name, m.Name)
+			d.Members[i].Role = coalesce(d.Members[i].Role, m.Role)
+			continue
+		}
+		seen[m.Email] = len(d.Members)
+		d.Members = append(d.Members, m)
+	}
+	sort.SliceStable(d.Members, func(i, j int) bool {
+		return memberKey(coalesce(d.Members[i].Name, d.Members[i].Email)) < memberKey(coalesce(d.Members[j].Name, d.Members[j].Email))
+	})
+	d.index()
+	return d
+}
+
+// index builds the lookup maps.
+func (d *MemberDirectory) index() {
+	d.byKey = map[string]*Member{}
+	d.domains = map[string]bool{}
+	for i := range d.Members {
+		m := &d.Members[i]
+		for _, k := range []string{m.Name, m.Email} {
+			if k := memberKey(k); k != "" && d.byKey[k] == nil {
+				d.byKey[k] = m
+			}
+		}
+		if domain := emailDomain(m.Email); domain != "" {
+			d.domains[domain] = true
+		}
+	}
+}
+
+// parseMembersAPI decodes a members API response. The list may be the body
+// itself or sit under "members", "users", or "data"; each entry's name may
+// be "name", "full_name", or "display_name".
+func parseMembersAPI(body []byte) ([]Member, error) {
+	var raw any
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("members response: %w", err)
+	}
+	if obj, ok := raw.(map[string]any); ok {
+		for _, key := range []string{"members", "users", "data"} {
+			if list, ok := obj[key].([]any); ok {
+				raw = list
+				break
+			}
+		}
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, errors.New("members response: no member list")
+	}
+	str := func(obj map[string]any, keys ...string) string {
+		for _, k := range keys {
+			if s, ok := obj[k].(string); ok && strings.TrimSpace(s) != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	var members []Member
+	for _, item := range list {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if user, ok := obj["user"].(map[string]any); ok {
+			for k, v := range user {
+				if _, set := obj[k]; !set {
+					obj[k] = v
+				}
+			}
+		}
+		members = append(members, Member{
+			Name:  str(obj, "name", "full_name", "display_name"),
+			Email: str(obj, "email", "email_address"),
+			Role:  str(obj, "role", "workspace_role"),
+		})
+	}
+	return members, nil
+}
+
+// loadMemberDirectory reads members.json from outputDir. A missing file
+// yields nil, which leaves participants as scraped.
+func loadMemberDirectory(outputDir string) (*MemberDirectory, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, membersFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	d := &MemberDirectory{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("%s: %w", membersFile, err)
+	}
+	d.index()
+	return d, nil
+}
+
+// save writes the directory to outputDir.
+func (d *MemberDirectory) save(outputDir string) error {
+	return writeJSON(filepath.Join(outputDir, membersFile), d)
+}
+
+// apply normalizes meta's participants to member names and records the
+// ones that are not members.
+func (d *MemberDirectory) apply(meta *Metadata) {
+	if d == nil || meta == nil {
+		return
+	}
+	participants := flattenStringSlice(meta.Participants)
+	if len(participants) == 0 {
+		return
+	}
+	var names, external []string
+	for _, p := range participants {
+		if m := d.byKey[memberKey(p)]; m != nil {
+			names = append(names, coalesce(m.Name, p))
+			continue
+		}
+		names = append(names, p)
+		if email := emailRe.FindString(p); email != "" && d.domains[emailDomain(email)] {
+			continue // a colleague not in the directory yet
+		}
+		external = append(external, p)
+	}
+	meta.Participants = dedupeFold(names)
+	meta.ExternalParticipants = external
+}
+
+// ── Export ──────────────────────────────────────────────────────────────────
+
+// membersFetchJS fetches the members endpoint with the page's session and
+// returns the body, or "" on any failure.
+const membersFetchJS = `async (url) => {
+	try {
+		const r = await fetch(url, { credentials: 'include', headers: { Accept: 'application/json' } });
+		return r.ok ? await r.text() : '';
+	} catch { return ''; }
+}`
+
+// membersScrapeJS reads the admin members table: one row per member, with
+// the email found anywhere in the row and the role from a role cell or
+// select.
+const membersScrapeJS = `() => {
+	const emailRe = /[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}/;
+	const roleRe = /^(owner|admin|member|viewer|guest|recorder)$/i;
+	const rows = document.querySelectorAll('[data-testid="member-row"], table tbody tr, [role="row"]');
+	const out = [];
+	rows.forEach(row => {
+		const text = row.innerText || '';
+		const email = (text.match(emailRe) || [''])[0];
+		const nameEl = row.querySelector('[data-testid="member-name"], .member-name');
+		let name = nameEl ? nameEl.textContent.trim() : '';
+		let role = '';
+		const roleEl = row.querySelector('[data-testid="member-role"], .member-role, select');
+		if (roleEl) role = (roleEl.value || roleEl.textContent || '').trim();
+		for (const cell of text.split('\n').map(s => s.trim()).filter(Boolean)) {
+			if (!name && cell !== email && !roleRe.test(cell)) name = cell;
+			if (!role && roleRe.test(cell)) role = cell;
+		}
+		if (name || email) out.push({ name, email, role });
+	});
+	return JSON.stringify(out);
+}`
+
+// FetchMembers reads the workspace member directory, from the API when it
+// answers and from the admin members page otherwise.
+func (b *Browser) FetchMembers(ctx context.Context) ([]Member, string, error) {
+	res, err := b.page.Context(ctx).Eval(membersFetchJS, grainSite.api+membersAPIPath)
+	if err == nil && res.Value.Str() != "" {
+		members, perr := parseMembersAPI([]byte(res.Value.Str()))
+		if perr == nil && len(members) > 0 {
+			return members, membersSourceAPI, nil
+		}
+		err = perr
+	}
+	slog.Debug("Members API unavailable, scraping the admin page", "error", err)
+
+	if err := rod.Try(func() {
+		b.page.Context(ctx).Timeout(30 * time.Second).MustNavigate(grainPageURL(membersPagePath)).MustWaitStable()
+	}); err != nil {
+		return nil, "", fmt.Errorf("navigate to members page: %w", err)
+	}
+	if err := b.checkAuth(); err != nil {
+		return nil, "", err
+	}
+	res, err = b.page.Eval(membersScrapeJS)
+	if err != nil {
+		return nil, "", fmt.Errorf("scrape members page: %w", err)
+	}
+	var members []Member
+	if err := json.Unmarshal([]byte(res.Value.Str()), &members); err != nil {
+		return nil, "", fmt.Errorf("scrape members page: %w", err)
+	}
+	if len(members) == 0 {
+		return nil, "", errors.New("no members found (the members page needs workspace admin access)")
+	}
+	return members, membersSourceScrape, nil
+}
+
+// runMembers exports the member directory (graindl members). With
+// --dry-run it is printed instead of saved.
+func (e *Exporter) runMembers(ctx context.Context) error {
+	b, err := e.lazyBrowser()
+	if err != nil {
+		return err
+	}
+	if _, err := b.Login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	members, source, err := b.FetchMembers(ctx)
+	if err != nil {
+		return fmt.Errorf("members: %w", err)
+	}
+	d := newMemberDirectory(source, members)
+	if e.cfg.DryRun {
+		printMembers(os.Stdout, d)
+		return nil
+	}
+	if err := d.save(e.cfg.OutputDir); err != nil {
+		return fmt.Errorf("save members: %w", err)
+	}
+	slog.Info("Workspace members exported", "members", len(d.Members), "source", source, "path", filepath.Join(e.cfg.OutputDir, membersFile))
+	return nil
+}
+
+// printMembers writes the directory as a table.
+func printMembers(w io.Writer, d *MemberDirectory) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tEMAIL\tROLE")
+	for _, m := range d.Members {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", m.Name, m.Email, m.Role)
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%d member(s), from the %s.\n", len(d.Members), map[string]string{membersSourceAPI: "workspace API", membersSourceScrape: "admin members page"}[d.Source])
+}
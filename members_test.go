@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseMembersAPI(t *testing.T) {
+	for name, body := range map[string]string{
+		"bare list": `[{"name":"Dana Lee","email":"Dana@acme.com","role":"admin"},{"full_name":"Sam Ortiz","email":"sam@acme.com"}]`,
+		"wrapped":   `{"members":[{"user":{"display_name":"Dana Lee","email":"dana@acme.com"},"role":"admin"},{"name":"Sam Ortiz","email_address":"sam@acme.com"}]}`,
+	} {
+		members, err := parseMembersAPI([]byte(body))
+		if err != nil || len(members) != 2 {
+			t.Fatalf("%s: %v, %v", name, members, err)
+		}
+		if members[0].Name != "Dana Lee" || !strings.EqualFold(members[0].Email, "dana@acme.com") || members[0].Role != "admin" {
+			t.Errorf("%s: first = %+v", name, members[0])
+		}
+		if members[1].Name != "Sam Ortiz" || members[1].Email != "sam@acme.com" {
+			t.Errorf("%s: second = %+v", name, members[1])
+		}
+	}
+	for _, body := range []string{`{"error":"forbidden"}`, `<html>`, `"x"`} {
+		if _, err := parseMembersAPI([]byte(body)); err == nil {
+			t.Errorf("parseMembersAPI(%s): want error", body)
+		}
+	}
+}
+
+func TestMemberDirectoryApply(t *testing.T) {
+	d := newMemberDirectory(membersSourceAPI, []Member{
+		{Name: "Sam  Ortiz", Email: "sam@acme.com", Role: "Member"},
+		{Name: "Dana Lee", Email: "DANA@acme.com", Role: "admin"},
+		{Email: "dana@acme.com", Role: "owner"}, // duplicate
+		{},
+	})
+	if len(d.Members) != 2 || d.Members[0].Name != "Dana Lee" || d.Members[0].Role != "admin" || d.Members[1].Name != "Sam Ortiz" {
+		t.Fatalf("members = %+v", d.Members)
+	}
+
+	meta := &Metadata{Participants: []string{"dana lee", "sam@acme.com", "Sam Ortiz", "newhire@acme.com", "Pat Kim", "pat@customer.io"}}
+	d.apply(meta)
+	if got := strings.Join(flattenStringSlice(meta.Participants), "|"); got != "Dana Lee|Sam Ortiz|newhire@acme.com|Pat Kim|pat@customer.io" {
+		t.Errorf("participants = %q", got)
+	}
+	if got := strings.Join(meta.ExternalParticipants, "|"); got != "Pat Kim|pat@customer.io" {
+		t.Errorf("external = %q", got)
+	}
+
+	// No directory: participants stay as scraped.
+	var none *MemberDirectory
+	meta = &Metadata{Participants: []string{"dana lee"}}
+	none.apply(meta)
+	if meta.ExternalParticipants != nil || flattenStringSlice(meta.Participants)[0] != "dana lee" {
+		t.Errorf("nil directory changed metadata: %+v", meta)
+	}
+}
+
+func TestMemberDirectorySaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	if d, err := loadMemberDirectory(dir); d != nil || err != nil {
+		t.Fatalf("missing file: %v, %v", d, err)
+	}
+	if err := newMemberDirectory(membersSourceScrape, []Member{{Name: "Dana Lee", Email: "dana@acme.com"}}).save(dir); err != nil {
+		t.Fatal(err)
+	}
+	d, err := loadMemberDirectory(dir)
+	if err != nil || d.Source != membersSourceScrape || len(d.Members) != 1 || d.ExportedAt.IsZero() {
+		t.Fatalf("loaded %+v, %v", d, err)
+	}
+	meta := &Metadata{Participants: []string{"DANA@ACME.COM"}}
+	d.apply(meta)
+	if got := flattenStringSlice(meta.Participants); got[0] != "Dana Lee" {
+		t.Errorf("loaded directory not indexed: %v", got)
+	}
+
+	var buf bytes.Buffer
+	printMembers(&buf, d)
+	if !strings.Contains(buf.String(), "dana@acme.com") || !strings.Contains(buf.String(), "admin members page") {
+		t.Errorf("printMembers:\n%s", buf.String())
+	}
+
+	writeArchiveFile(t, dir, membersFile, "{not json", 0)
+	if _, err := loadMemberDirectory(dir); err == nil {
+		t.Error("corrupt members.json: want error")
+	}
+}
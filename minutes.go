@@ -0,0 +1,423 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ── Meeting Minutes ─────────────────────────────────────────────────────────
+//
+// --output-format minutes renders a formal minutes document instead of a
+// knowledge-base note: attendees, an agenda inferred from the AI notes'
+// headings with the discussion under each item, decisions, action items
+// with owners and due dates, next steps, and open questions. The full
+// transcript is left out; it stays in <id>.transcript.txt.
+//
+// Decisions come from AI notes sections headed like "Decisions" and from
+// highlights marked "Decision: ...". Only when neither has any are decision
+// phrases in the transcript ("we agreed to ...") used, attributed to their
+// speaker. Attendees are the meeting's participants, or the transcript's
+// speakers when Grain listed none.
+
+// minutesDecisionMarker matches highlight titles/texts recording a decision.
+var minutesDecisionMarker = regexp.MustCompile(`(?i)^\s*(?:decision|decided|agreed|resolved)\s*[:\-–—]\s*(.+)$`)
+
+// minutesDecisionPhrase matches transcript sentences announcing a decision.
+var minutesDecisionPhrase = regexp.MustCompile(`(?i)\b(?:we(?:'ve| have)? (?:decided|agreed)|(?:it's|it is) (?:decided|agreed)|the decision is|let's go with|we're going (?:to go )?with)\b`)
+
+// Owner patterns: "(owner: Dana)", "@dana", and "Dana: ..." or "Dana to ..."
+// at the start of the task.
+var (
+	minutesOwnerParen   = regexp.MustCompile(`(?i)\s*\((?:owner|assignee|assigned to)\s*[:\-]?\s*([^)]+)\)`)
+	minutesOwnerMention = regexp.MustCompile(`(?:^|\s)@([\p{L}][\p{L}\p{N}._-]*)`)
+	minutesOwnerLabel   = regexp.MustCompile(`^([\p{L}][\p{L}.'’-]*(?:\s[\p{L}][\p{L}.'’-]*){0,2})\s*:\s+(.+)$`)
+	minutesOwnerVerb    = regexp.MustCompile(`^([\p{L}][\p{L}.'’-]*(?:\s[\p{L}][\p{L}.'’-]*)?)\s+(?:to|will|should|is going to)\s+(.+)$`)
+)
+
+// minutesAction is one action item row.
+type minutesAction struct {
+	Text  string
+	Owner string
+	Due   string
+}
+
+// writeMinutes renders meta as a minutes document.
+func writeMinutes(b noteWriter, meta *Metadata, transcriptText string) {
+	turns := parseTranscript(transcriptText)
+	attendees := minutesAttendees(meta, turns)
+	sections := noteSections(meta.AINotes)
+
+	b.WriteString("---\n")
+	writeYAMLField(b, "title", meta.Title)
+	writeYAMLField(b, "type", "minutes")
+	if meta.Date != "" {
+		writeYAMLField(b, "date", dateFromISO(meta.Date))
+	}
+	writeYAMLField(b, "grain_id", meta.ID)
+	if meta.Ownership != "" {
+		writeYAMLField(b, "ownership", meta.Ownership)
+	}
+	if meta.Classification != "" {
+		writeYAMLField(b, "classification", meta.Classification)
+	}
+	if meta.Sharing != nil {
+		writeYAMLField(b, "sharing", meta.Sharing.Visibility)
+	}
+	writePlatformFields(b, meta)
+	tags := append([]string{"grain", "minutes"}, flattenStringSlice(meta.Tags)...)
+	writeYAMLList(b, "tags", topicTags(tags, meta.Topics))
+	if len(attendees) > 0 {
+		writeYAMLList(b, "attendees", attendees)
+	}
+	if meta.Links.Grain != "" {
+		writeYAMLField(b, "grain_url", meta.Links.Grain)
+	}
+	b.WriteString("---\n\n")
+
+	b.WriteString("# Minutes: " + coalesce(meta.Title, meta.ID) + "\n\n")
+	var details []string
+	if meta.Date != "" {
+		details = append(details, "**Date:** "+dateFromISO(meta.Date))
+		if t, err := time.Parse(time.RFC3339, meta.Date); err == nil {
+			details = append(details, "**Time:** "+t.Format("15:04 MST"))
+		}
+	}
+	if dur := formatDuration(meta.DurationSeconds); dur != "" {
+		details = append(details, "**Duration:** "+dur)
+	}
+	if u := coalesce(meta.Links.Grain, meta.Links.Share); u != "" {
+		details = append(details, "**Recording:** [Grain]("+u+")")
+	}
+	if len(details) > 0 {
+		b.WriteString(strings.Join(details, " · ") + "\n")
+	}
+
+	b.WriteString("\n## Attendees\n\n")
+	if len(attendees) == 0 {
+		b.WriteString("_Not recorded._\n")
+	}
+	for _, a := range attendees {
+		b.WriteString("- " + a + "\n")
+	}
+
+	if meta.Summary != "" {
+		b.WriteString("\n## Summary\n\n" + meta.Summary + "\n")
+	}
+
+	var agenda []AINoteSection
+	var decisions, nextSteps []string
+	for _, s := range sections {
+		switch minutesSectionKind(s.Title) {
+		case "decisions":
+			decisions = append(decisions, s.lines()...)
+		case "next_steps":
+			nextSteps = append(nextSteps, s.lines()...)
+		case "":
+			if strings.TrimSpace(s.Title) != "" {
+				agenda = append(agenda, s)
+			}
+		}
+	}
+	if len(agenda) > 0 {
+		b.WriteString("\n## Agenda\n\n")
+		for i, s := range agenda {
+			fmt.Fprintf(b, "%d. %s\n", i+1, s.Title)
+		}
+		b.WriteString("\n## Discussion\n")
+		for i, s := range agenda {
+			fmt.Fprintf(b, "\n### %d. %s\n\n", i+1, s.Title)
+			if s.Text != "" {
+				b.WriteString(s.Text + "\n")
+			}
+			if s.Text != "" && len(s.Items) > 0 {
+				b.WriteString("\n")
+			}
+			for _, item := range s.Items {
+				b.WriteString("- " + item + "\n")
+			}
+		}
+	}
+
+	clips := normalizeHighlights(parseHighlights(meta.Highlights))
+	decisions = minutesDecisions(decisions, clips, turns)
+	if len(decisions) > 0 {
+		b.WriteString("\n## Decisions\n\n")
+		for _, d := range decisions {
+			b.WriteString("- " + d + "\n")
+		}
+	}
+
+	actions := minutesActions(meta, clips, attendees)
+	if len(actions) > 0 {
+		b.WriteString("\n## Action Items\n\n")
+		b.WriteString("| # | Action | Owner | Due |\n|---|---|---|---|\n")
+		for i, a := range actions {
+			fmt.Fprintf(b, "| %d | %s | %s | %s |\n", i+1, minutesCell(a.Text), minutesCell(coalesce(a.Owner, "—")), coalesce(a.Due, "—"))
+		}
+	}
+
+	if nextSteps = minutesNextSteps(nextSteps, actions, attendees); len(nextSteps) > 0 {
+		b.WriteString("\n## Next Steps\n\n")
+		for _, s := range nextSteps {
+			b.WriteString("- " + s + "\n")
+		}
+	}
+
+	if len(meta.Questions) > 0 {
+		b.WriteString("\n## Open Questions\n\n")
+		for _, q := range meta.Questions {
+			b.WriteString("- " + q + "\n")
+		}
+	}
+
+	b.WriteString("\n---\n\n_Prepared by graindl from Grain's AI notes, highlights, and transcript. Review before approval._\n")
+}
+
+// noteSections returns AI notes as sections, whatever --notes-format they
+// were stored in: sections (fresh or decoded from JSON) or markdown.
+// Plain text has no headings and yields a single untitled section.
+func noteSections(v any) []AINoteSection {
+	switch n := v.(type) {
+	case []AINoteSection:
+		return n
+	case []any:
+		var out []AINoteSection
+		for _, item := range n {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			s := AINoteSection{Items: flattenStringSlice(m["items"])}
+			s.Title, _ = m["title"].(string)
+			s.Text, _ = m["text"].(string)
+			out = append(out, s)
+		}
+		return out
+	case string:
+		var out []AINoteSection
+		cur := -1
+		for _, line := range strings.Split(n, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			if strings.HasPrefix(line, "#") {
+				out = append(out, AINoteSection{Title: strings.TrimSpace(strings.TrimLeft(line, "#"))})
+				cur = len(out) - 1
+				continue
+			}
+			if cur < 0 {
+				out = append(out, AINoteSection{})
+				cur = 0
+			}
+			if item, ok := cutBullet(line); ok {
+				out[cur].Items = append(out[cur].Items, item)
+			} else if out[cur].Text == "" {
+				out[cur].Text = line
+			} else {
+				out[cur].Text += "\n" + line
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// cutBullet strips a markdown list marker from line.
+func cutBullet(line string) (string, bool) {
+	for _, p := range []string{"- ", "* ", "• "} {
+		if rest, ok := strings.CutPrefix(line, p); ok {
+			return strings.TrimSpace(rest), true
+		}
+	}
+	return line, false
+}
+
+// minutesSectionKind classifies an AI notes heading for the minutes:
+// "decisions", "next_steps", the classifyNoteSection kinds, or "" for an
+// agenda topic.
+func minutesSectionKind(title string) string {
+	t := strings.ToLower(strings.TrimSpace(title))
+	switch {
+	case strings.Contains(t, "decision"), strings.Contains(t, "decided"), strings.Contains(t, "agreement"),
+		strings.Contains(t, "agreed"), strings.Contains(t, "outcome"), strings.Contains(t, "resolution"):
+		return "decisions"
+	case strings.Contains(t, "next step"):
+		return "next_steps"
+	}
+	return classifyNoteSection(title)
+}
+
+// minutesAttendees returns the participants, or the transcript's speakers
+// in order of first appearance.
+func minutesAttendees(meta *Metadata, turns []transcriptTurn) []string {
+	if p := flattenStringSlice(meta.Participants); len(p) > 0 {
+		return p
+	}
+	seen := map[string]bool{}
+	var speakers []string
+	for _, t := range turns {
+		if t.Speaker != "" && !seen[t.Speaker] {
+			seen[t.Speaker] = true
+			speakers = append(speakers, t.Speaker)
+		}
+	}
+	return speakers
+}
+
+// minutesDecisions merges decisions from the AI notes and highlights,
+// falling back to decision phrases in the transcript.
+func minutesDecisions(fromNotes []string, clips []HighlightClip, turns []transcriptTurn) []string {
+	var out []string
+	seen := map[string]bool{}
+	add := func(s string) {
+		s = strings.Join(strings.Fields(s), " ")
+		if s != "" && !seen[strings.ToLower(s)] {
+			seen[strings.ToLower(s)] = true
+			out = append(out, s)
+		}
+	}
+	for _, d := range fromNotes {
+		add(d)
+	}
+	for _, c := range clips {
+		for _, s := range []string{c.Title, c.Text} {
+			if m := minutesDecisionMarker.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+				add(m[1])
+				break
+			}
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	for _, t := range turns {
+		for _, line := range t.Lines {
+			for _, s := range sentences(line) {
+				if !minutesDecisionPhrase.MatchString(s) {
+					continue
+				}
+				if t.Speaker != "" {
+					s += " (" + t.Speaker + ")"
+				}
+				add(s)
+			}
+		}
+	}
+	return out
+}
+
+// sentences splits text after ".", "!" or "?" followed by a space.
+func sentences(text string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(text)-1; i++ {
+		if strings.ContainsRune(".!?", rune(text[i])) && text[i+1] == ' ' {
+			if s := strings.TrimSpace(text[start : i+1]); s != "" {
+				out = append(out, s)
+			}
+			start = i + 2
+		}
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+// minutesActions returns the meeting's action items (see meetingTasks)
+// with owners.
+func minutesActions(meta *Metadata, clips []HighlightClip, attendees []string) []minutesAction {
+	var out []minutesAction
+	for _, t := range meetingTasks(meta, clips) {
+		text, owner := taskOwner(t.Text, attendees)
+		out = append(out, minutesAction{Text: text, Owner: owner, Due: t.Due})
+	}
+	return out
+}
+
+// taskOwner finds who an action item is assigned to and returns the task
+// without the assignment. A leading "Name to ..." only counts when Name is
+// an attendee, and "Name: ..." when Name is an attendee or capitalized;
+// "@name" and "(owner: Name)" always do.
+func taskOwner(text string, attendees []string) (string, string) {
+	if m := minutesOwnerParen.FindStringSubmatchIndex(text); m != nil {
+		owner := strings.TrimSpace(text[m[2]:m[3]])
+		return strings.TrimSpace(text[:m[0]] + text[m[1]:]), owner
+	}
+	if m := minutesOwnerLabel.FindStringSubmatch(text); m != nil && !taskMarker.MatchString(text) {
+		if name := matchAttendee(m[1], attendees); name != "" || capitalizedWords(m[1]) {
+			return upperFirst(m[2]), coalesce(name, m[1])
+		}
+	}
+	if m := minutesOwnerMention.FindStringSubmatch(text); m != nil {
+		return text, coalesce(matchAttendee(m[1], attendees), m[1])
+	}
+	if m := minutesOwnerVerb.FindStringSubmatch(text); m != nil {
+		if name := matchAttendee(m[1], attendees); name != "" {
+			return upperFirst(m[2]), name
+		}
+	}
+	return text, ""
+}
+
+// matchAttendee returns the attendee whose name, first name, or email
+// local part is name (case-insensitively), or "".
+func matchAttendee(name string, attendees []string) string {
+	name = strings.ToLower(name)
+	for _, a := range attendees {
+		l := strings.ToLower(a)
+		local, _, _ := strings.Cut(l, "@")
+		first, _, _ := strings.Cut(l, " ")
+		if name == l || name == first || name == local {
+			return a
+		}
+	}
+	return ""
+}
+
+// minutesNextSteps drops next steps already listed as action items.
+func minutesNextSteps(steps []string, actions []minutesAction, attendees []string) []string {
+	listed := map[string]bool{}
+	for _, a := range actions {
+		listed[strings.ToLower(a.Text)] = true
+	}
+	var out []string
+	for _, s := range steps {
+		s = strings.Join(strings.Fields(s), " ")
+		text, _ := taskOwner(s, attendees)
+		if s != "" && !listed[strings.ToLower(s)] && !listed[strings.ToLower(text)] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// minutesCell escapes a value for a markdown table cell.
+func minutesCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+// capitalizedWords reports whether every word of s starts with an
+// upper-case letter, as a name does.
+func capitalizedWords(s string) bool {
+	for _, w := range strings.Fields(s) {
+		if r, _ := utf8.DecodeRuneInString(w); !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// upperFirst capitalizes the first letter of s.
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if n == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -14,78 +15,208 @@ import (
 // ── Config ──────────────────────────────────────────────────────────────────
 
 type Config struct {
-	OutputDir     string
-	SessionDir    string
-	MaxMeetings   int
-	MeetingID     string
-	Parallel      int
-	DryRun        bool
-	SkipVideo     bool
-	AudioOnly     bool
-	Overwrite     bool
-	Headless      bool
-	CleanSession  bool
-	Verbose       bool
-	MinDelaySec   float64
-	MaxDelaySec   float64
-	SearchQuery   string
-	OutputFormat  string // "", "obsidian", "notion"
-	Watch           bool
-	WatchInterval   time.Duration
-	HealthcheckFile string
-	LogFormat       string // "", "json"
-	TUI             bool   // --tui: enable Bubble Tea TUI
-	ICloud          bool   // --icloud: copy exports to iCloud Drive
-	ICloudPath      string // --icloud-path: custom iCloud Drive directory (auto-detected on macOS)
+	OutputDir          string
+	MarkdownOutput     string // --markdown-output: root for notes and highlight previews ("" = OutputDir)
+	VideoOutput        string // --video-output: root for videos, audio, and HLS URL files
+	DataOutput         string // --data-output: root for metadata, transcripts, and highlights
+	SessionDir         string
+	MaxMeetings        int
+	MaxErrors          int  // --max-errors: abort the run after this many failed meetings (0 = never)
+	Strict             bool // --strict: exit non-zero on any failed meeting, pending HLS stream, or duration mismatch
+	DeadLetterAfter    int  // --dead-letter-after: dead-letter a meeting after this many failed runs (0 = off, the default)
+	MeetingID          string
+	Parallel           int
+	AutoParallel       bool // --auto-parallel: size and adapt Parallel from CPU, memory, and latency
+	DryRun             bool
+	SkipVideo          bool
+	NoVideoTags        bool // --no-video-tags: leave downloaded MP4s untagged
+	AudioOnly          bool
+	DeferVideos        bool // --defer-videos: queue video downloads for graindl download-videos
+	Overwrite          bool
+	Headless           bool
+	BrowserBin         string // --browser-bin: Chromium/Chrome/Edge binary to launch instead of Rod's download
+	BrowserRemote      string // --browser-remote: DevTools URL of a running browser to attach to instead of launching one
+	NoDownloadBrowser  bool   // --no-download-browser: use an installed browser rather than downloading Chromium
+	CleanSession       bool
+	Verbose            bool
+	MinDelaySec        float64
+	MaxDelaySec        float64
+	HostDelays         []HostDelay // --host-delay: per-host pacing for requests made outside the browser
+	APIUserAgent       string      // --api-user-agent: User-Agent for browser and download requests
+	APIHeaders         http.Header // --api-header: extra headers for browser and download requests
+	SearchQuery        string
+	IgnoreTitles       []titleRule       // --ignore-title-regex, --ignore-file: titles dropped after discovery
+	IncludeShared      bool              // --include-shared: also export meetings from "Shared with me"
+	SharedSubdir       bool              // --shared-subdir: put shared meetings under shared/<date>/
+	ClassifyRules      []classifyRule    // --classify: participant email patterns → access label
+	ClassifyRoutes     map[string]string // --classify-route: access label → Drive subfolder
+	OutputFormat       string            // "", "obsidian", "notion", "minutes"
+	HighlightPreviews  string            // --highlight-previews: "", "gif", "webp"
+	NotionMaxSize      int               // --notion-max-size: split notion notes past this many bytes (0 = never)
+	Compress           string            // --compress: "", "zstd", "gzip" for metadata, transcripts, and highlights
+	Topics             int               // --topics: TF-IDF keywords per meeting from the transcript (0 = off)
+	NotesFormat        string            // --notes-format: "json" (default), "md", "text" shape of metadata ai_notes
+	SlugStyle          string            // "", "ascii", "unicode": title-based markdown note names
+	AnkiDeck           string            // --anki-deck: Anki import file of highlight/action-item cards
+	HighlightPages     bool              // --highlight-pages: highlights/<tag>.md pages rebuilt after each run
+	Watch              bool
+	WatchInterval      time.Duration
+	WatchSchedule      *CronSchedule // --schedule: cron timing for watch cycles (overrides WatchInterval)
+	HealthcheckFile    string
+	HealthcheckFormat  string        // --healthcheck-format: "text" (default), "json"
+	EventsSock         string        // --events-sock: NDJSON export events on this unix socket or named pipe
+	ProgressInterval   time.Duration // --progress-interval: periodic progress/ETA summaries (0 = off)
+	LogFormat          string        // "", "json"
+	LogGroupByMeeting  bool          // --log-group-by-meeting: print each meeting's console lines as one block
+	LogFile            string        // --log-file: also write logs here, with rotation
+	LogMaxSize         int64         // --log-max-size: rotate past this many bytes (0 = no limit)
+	LogRotate          time.Duration // --log-rotate: rotate on period boundaries (0 = off)
+	LogKeep            int           // --log-keep: rotated files retained (0 = all)
+	Chaos              *Chaos        // hidden --chaos: injected failures; nil when off
+	TUI                bool          // --tui: enable Bubble Tea TUI
+	Pick               bool          // graindl pick: choose the meetings to export in a picker after discovery
+	DownloadVideos     bool          // graindl download-videos: work through the --defer-videos queue instead of exporting
+	Plan               bool          // graindl plan: schedule the unexported backlog at --max-rate instead of exporting
+	Members            bool          // graindl members: export the workspace member directory instead of exporting
+	Stdout             string        // --stdout: stream this artifact of the --id meeting to stdout ("video", "transcript")
+	Retry              bool          // graindl retry: export only the meetings chosen by a retry source flag
+	DeadLetter         bool          // --dead-letter: graindl retry exports the dead-lettered meetings
+	MaxRate            string        // --max-rate: backfill rate for graindl plan, e.g. "100meetings/day"
+	PlanRate           int           // meetings per PlanWindow, parsed from MaxRate
+	PlanWindow         time.Duration // the MaxRate window: an hour, day, or week
+	ICloud             bool          // --icloud: copy exports to iCloud Drive
+	ICloudPath         string        // --icloud-path: custom iCloud Drive directory (auto-detected on macOS)
+	AppleNotes         bool          // --apple-notes: push each markdown note into Apple Notes (macOS)
+	AppleNotesFolder   string        // --apple-notes-folder: Notes folder to create/update notes in
+	AppleNotesShortcut string        // --apple-notes-shortcut: run this Shortcut with the note instead of osascript
+	Spotlight          bool          // --spotlight: Spotlight/Finder metadata attributes on exported files (macOS)
+	NotifyDesktop      bool          // --notify-desktop: native notification when a run ends or a watch cycle exports meetings
+	ClaimTTL           time.Duration // --claim-ttl: per-meeting claim files for shared archives (0 = off)
+	ExtractScript      string        // --extract-script: JS file evaluated on each meeting page
+	SnapshotHTML       bool          // --snapshot-html: save an MHTML capture of each meeting page
+	RefreshAnalytics   bool          // --refresh-analytics: update view counts of already-exported meetings
+	MinQuality         float64       // --min-quality: re-export meetings whose scrape quality is below this
+	DurationTolerance  float64       // --duration-tolerance: seconds a video may differ from the meeting length (0 = off)
+	Immutable          bool          // --immutable: seal artifacts read-only and refuse overwrites (legal hold)
+	RetainUntil        time.Time     // --retention: end of the hold recorded in metadata (zero = indefinite)
+	RecordHTTP         string        // --record-http: directory for sanitized request/response fixtures
+	ReplayHTTP         string        // --replay-http: serve Grain requests from recorded fixtures
+	GrainBaseURL       string        // --grain-base-url: Grain web app base (default https://grain.com)
+	GrainAPIURL        string        // --grain-api-url: Grain public API base
+	IsolateWorkers     bool          // --isolate-workers: one incognito browser context per parallel worker
+	HLSDownload        bool          // --hls-download: fetch HLS segments natively instead of saving the URL
+	HLSConcurrency     int           // --hls-concurrency: parallel segment downloads
+	EncryptSession     bool          // --encrypt-session: session dir kept as <session-dir>.enc, unpacked to tmpfs per run
+	WebDAVURL          string        // --webdav-url: mirror exports to this WebDAV collection
+	WebDAVUser         string        // GRAIN_WEBDAV_USER (env/.env only)
+	WebDAVPassword     string        // GRAIN_WEBDAV_PASSWORD (env/.env only)
+	S3                 s3Config      // --s3-bucket/--s3-prefix/--s3-endpoint/--s3-region: mirror exports to a bucket (credentials from env/.env)
 
 	// Google Drive upload
 	GDrive            bool
 	GDriveFolderID    string
+	GDriveFolderPath  string // --gdrive-folder-path: resolved to a folder ID at startup
+	GDriveCreateDir   bool   // --gdrive-folder-create: create missing folders on the path
 	GDriveCredentials string
 	GDriveTokenFile   string
 	GDriveCleanLocal  bool
+	GDriveKeepVideos  time.Duration // --gdrive-keep-videos: remove local videos this long after a verified upload (0 = keep)
 	GDriveServiceAcct bool
 	GDriveConflict    string // "local-wins" (default), "skip", "newer-wins"
 	GDriveVerify      bool
+	GDriveQuotaGuard  bool         // abort uploads that would exceed remaining Drive quota
+	GDrivePreserve    string       // "", "keep-forever", "copy"
+	GDriveRoutes      []driveRoute // --gdrive-route: per-meeting subfolder rules
+
+	// Keyword alerts
+	AlertKeywords []string // --alert-keywords: lowercased, deduplicated
+	AlertWebhook  string   // --alert-webhook: Slack-compatible JSON endpoint
+
+	// Readwise
+	ReadwiseToken string // --readwise-token: push highlights to Readwise after each run
+
+	// Transcript embeddings
+	Embeddings        string  // --embeddings: "", "openai", "local"
+	EmbeddingsOut     string  // --embeddings-out: .parquet or .jsonl vector file
+	EmbeddingsModel   string  // --embeddings-model: backend default when empty
+	EmbeddingsURL     string  // --embeddings-url: OpenAI-compatible API base
+	EmbeddingsMaxCost float64 // --embeddings-max-cost: USD per run for openai (0 = no limit)
+	OpenAIKey         string  // OPENAI_API_KEY (env/.env only)
 }
 
 // ── Export Types ─────────────────────────────────────────────────────────────
 
 type MeetingRef struct {
-	ID    string
-	Title string
-	Date  string
-	URL   string
+	ID     string
+	Title  string
+	Date   string
+	URL    string
+	Shared bool // found in "Shared with me" rather than the account's own list
 }
 
 type ExportResult struct {
-	ID              string            `json:"id"`
-	Title           string            `json:"title"`
-	DateDir         string            `json:"date_dir"`
-	Status          string            `json:"status"`
-	MetadataPath    string            `json:"metadata_path,omitempty"`
-	MarkdownPath    string            `json:"markdown_path,omitempty"`
-	TranscriptPaths map[string]string `json:"transcript_paths,omitempty"`
-	HighlightsPath  string            `json:"highlights_path,omitempty"`
-	VideoPath       string            `json:"video_path,omitempty"`
-	VideoMethod     string            `json:"video_method,omitempty"`
-	AudioPath       string            `json:"audio_path,omitempty"`
-	AudioMethod     string            `json:"audio_method,omitempty"`
-	ErrorMsg        string            `json:"error_msg,omitempty"`
-	DriveUploaded   bool              `json:"drive_uploaded,omitempty"`
-	DriveSkipped    int               `json:"drive_skipped,omitempty"`
-	DriveUpdated    int               `json:"drive_updated,omitempty"`
-	DriveError      string            `json:"drive_error,omitempty"`
+	ID                   string            `json:"id"`
+	Title                string            `json:"title"`
+	DateDir              string            `json:"date_dir"`
+	Status               string            `json:"status"`
+	MetadataPath         string            `json:"metadata_path,omitempty"`
+	MarkdownPath         string            `json:"markdown_path,omitempty"`
+	MarkdownParts        []string          `json:"markdown_parts,omitempty"` // notion continuation files (<base>.part2.md, ...)
+	TranscriptPaths      map[string]string `json:"transcript_paths,omitempty"`
+	HighlightsPath       string            `json:"highlights_path,omitempty"`
+	AINotesPath          string            `json:"ai_notes_path,omitempty"`
+	VideoPath            string            `json:"video_path,omitempty"`
+	VideoMethod          string            `json:"video_method,omitempty"`
+	VideoStatus          string            `json:"video_status,omitempty"`   // "video_unavailable" (see videostate.go), "deferred", or "duration_mismatch" (see duration.go)
+	VideoRetryAt         string            `json:"video_retry_at,omitempty"` // RFC 3339; next download attempt for an unavailable video
+	VideoDuration        float64           `json:"video_duration,omitempty"` // seconds, probed with ffprobe (see duration.go)
+	Media                *MediaInfo        `json:"media,omitempty"`          // sniffed container/codecs of VideoPath
+	AssetsPath           string            `json:"assets_path,omitempty"`    // non-video assets from a zipped download
+	AudioPath            string            `json:"audio_path,omitempty"`
+	AudioMethod          string            `json:"audio_method,omitempty"`
+	SnapshotPath         string            `json:"snapshot_path,omitempty"`
+	PreviewPaths         []string          `json:"highlight_previews,omitempty"` // --highlight-previews animations
+	ErrorMsg             string            `json:"error_msg,omitempty"`
+	DriveUploaded        bool              `json:"drive_uploaded,omitempty"`
+	DriveSkipped         int               `json:"drive_skipped,omitempty"`
+	DriveUpdated         int               `json:"drive_updated,omitempty"`
+	DriveError           string            `json:"drive_error,omitempty"`
+	DriveRoute           string            `json:"drive_route,omitempty"`
+	DriveTxn             string            `json:"drive_txn,omitempty"` // committed, or pending after a failed upload (see drivetxn.go)
+	Backends             map[string]string `json:"backends,omitempty"`  // mirror/Drive name → "ok" or "error: ..."
+	AppleNotes           bool              `json:"apple_notes,omitempty"`
+	Classification       string            `json:"classification,omitempty"`
+	ExternalParticipants int               `json:"external_participants,omitempty"` // participants not in members.json
+	AlertMatches         int               `json:"alert_matches,omitempty"`
+	ScrapeQuality        *float64          `json:"scrape_quality,omitempty"`
+	ScrapeFallbacks      []string          `json:"scrape_fallbacks,omitempty"` // metadata fields the scrape did not find
+	Compressed           CompressedFiles   `json:"compressed,omitempty"`       // stored path → compressed and original size
+	Checksums            map[string]string `json:"sha256,omitempty"`           // stored path → hex SHA-256 (see integrity.go)
+
+	authFailed     bool   // meeting page redirected to login (see authGuard)
+	existed        bool   // metadata was already on disk before this export
+	browserTimeout bool   // meeting page scrape timed out (see workerGate)
+	grainErr       string // Grain-side failure: page scrape or download (see deadletter.go)
 }
 
 type ExportManifest struct {
-	ExportedAt string          `json:"exported_at"`
-	Total      int             `json:"total"`
-	OK         int             `json:"ok"`
-	Skipped    int             `json:"skipped"`
-	Errors     int             `json:"errors"`
-	HLSPending int             `json:"hls_pending"`
-	Meetings   []*ExportResult `json:"meetings"`
+	ExportedAt        string                `json:"exported_at"`
+	Total             int                   `json:"total"`
+	OK                int                   `json:"ok"`
+	Skipped           int                   `json:"skipped"`
+	Ignored           int                   `json:"ignored,omitempty"`    // discovered meetings dropped by title rules
+	IgnoredBy         map[string]int        `json:"ignored_by,omitempty"` // title rule → meetings it dropped
+	Errors            int                   `json:"errors"`
+	HLSPending        int                   `json:"hls_pending"`
+	AuthBlocked       int                   `json:"auth_blocked,omitempty"`
+	LowQuality        int                   `json:"low_quality,omitempty"`        // exported with scrape quality below 0.5
+	DurationMismatch  int                   `json:"duration_mismatch,omitempty"`  // videos whose length differs from the meeting's
+	DriveTransactions []DriveTxnReport      `json:"drive_transactions,omitempty"` // interrupted Drive uploads resumed this run
+	OutputRoots       OutputRoots           `json:"output_roots,omitempty"`       // artifact classes written outside --output
+	VideoRetention    *VideoRetentionReport `json:"video_retention,omitempty"`    // --gdrive-keep-videos cleanup pass
+	Chaos             map[string]int        `json:"chaos,omitempty"`              // --chaos faults injected this run
+	DeadLettered      int                   `json:"dead_lettered,omitempty"`      // discovered meetings skipped as dead-lettered
+	Meetings          []*ExportResult       `json:"meetings"`
 }
 
 // ── Highlight Types ─────────────────────────────────────────────────────────
@@ -204,6 +335,15 @@ func normalizeHighlight(h Highlight, index int) HighlightClip {
 	}
 }
 
+// normalizeHighlights converts a slice of raw highlights into clips.
+func normalizeHighlights(hs []Highlight) []HighlightClip {
+	clips := make([]HighlightClip, len(hs))
+	for i, h := range hs {
+		clips[i] = normalizeHighlight(h, i)
+	}
+	return clips
+}
+
 // toFloat64 attempts to convert a numeric any value to float64.
 func toFloat64(v any) float64 {
 	switch n := v.(type) {
@@ -229,15 +369,33 @@ func toFloat64(v any) float64 {
 // ── Output Metadata ─────────────────────────────────────────────────────────
 
 type Metadata struct {
-	ID              string `json:"id"`
-	Title           string `json:"title"`
-	Date            string `json:"date,omitempty"`
-	DurationSeconds any    `json:"duration_seconds,omitempty"`
-	Participants    any    `json:"participants,omitempty"`
-	Tags            any    `json:"tags,omitempty"`
-	Links           Links  `json:"links"`
-	AINotes         any    `json:"ai_notes,omitempty"`
-	Highlights      any    `json:"highlights,omitempty"`
+	ID                   string                     `json:"id"`
+	Title                string                     `json:"title"`
+	Date                 string                     `json:"date,omitempty"`
+	DurationSeconds      any                        `json:"duration_seconds,omitempty"`
+	Participants         any                        `json:"participants,omitempty"`
+	Tags                 any                        `json:"tags,omitempty"`
+	Topics               []string                   `json:"topics,omitempty"`                // --topics TF-IDF keywords
+	Ownership            string                     `json:"ownership,omitempty"`             // "owned" or "shared" with --include-shared
+	Classification       string                     `json:"classification,omitempty"`        // --classify label (see classify.go)
+	ExternalParticipants []string                   `json:"external_participants,omitempty"` // participants not in members.json (see members.go)
+	Links                Links                      `json:"links"`
+	AINotes              any                        `json:"ai_notes,omitempty"`
+	Summary              string                     `json:"summary,omitempty"`
+	ActionItems          []string                   `json:"action_items,omitempty"`
+	Questions            []string                   `json:"questions,omitempty"`
+	Highlights           any                        `json:"highlights,omitempty"`
+	Views                *int                       `json:"views,omitempty"`
+	UniqueViewers        *int                       `json:"unique_viewers,omitempty"`
+	LastViewedAt         string                     `json:"last_viewed_at,omitempty"`
+	Sharing              *Sharing                   `json:"sharing,omitempty"`            // share dialog state (see sharing.go)
+	Platform             *MeetingPlatform           `json:"platform,omitempty"`           // Zoom/Meet/Teams source (see platform.go)
+	Extra                map[string]any             `json:"extra,omitempty"`              // --extract-script fields
+	Provenance           map[string]FieldProvenance `json:"provenance,omitempty"`         // field → source/confidence
+	ScrapeQuality        *float64                   `json:"scrape_quality,omitempty"`     // 0–1, see provenance.go
+	Retention            *Retention                 `json:"retention,omitempty"`          // legal hold with --immutable
+	Media                *MediaInfo                 `json:"media,omitempty"`              // downloaded video's container/codecs
+	HighlightPreviews    []HighlightPreview         `json:"highlight_previews,omitempty"` // --highlight-previews animations
 }
 
 type Links struct {
@@ -308,7 +466,7 @@ func sanitize(s string) string {
 func ensureDir(dir string) error        { return os.MkdirAll(dir, 0o755) }
 func ensureDirPrivate(dir string) error { return os.MkdirAll(dir, 0o700) }
 func fileExists(path string) bool       { _, err := os.Stat(path); return err == nil }
-func meetingURL(id string) string       { return "https://grain.com/app/meetings/" + id }
+func meetingURL(id string) string       { return grainPageURL("/app/meetings/" + id) }
 
 func absPath(rel string) string {
 	a, err := filepath.Abs(rel)
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ── MP4 Tags ────────────────────────────────────────────────────────────────
+//
+// Downloaded MP4s get the meeting's title, date, participants, and Grain
+// URL written into their metadata atoms, plus a poster frame as cover art,
+// so a video copied out of the archive still says what it is. Tagging is a
+// stream copy through ffmpeg into a temp file that replaces the original;
+// without ffmpeg, or with --no-video-tags, videos are left as downloaded.
+// A failed tag never fails the export.
+
+// posterOffsets are the seek positions (seconds) tried for the poster
+// frame: a few seconds in skips a black first frame; 0 covers clips
+// shorter than that.
+var posterOffsets = []string{"5", "0"}
+
+// tagFunc writes meta's tags, and the poster at poster when it is not "",
+// into the MP4 at in, producing out.
+type tagFunc func(ctx context.Context, in, poster, out string, meta *Metadata) error
+
+// posterFunc extracts a poster frame from the video at in into out.
+type posterFunc func(ctx context.Context, in, out string) error
+
+// ffmpegTagger returns ffmpeg-backed tag and poster functions, or nils when
+// ffmpeg is not on PATH.
+func ffmpegTagger(verbose bool) (tagFunc, posterFunc) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, nil
+	}
+	tag := func(ctx context.Context, in, poster, out string, meta *Metadata) error {
+		return runFFmpeg(ctx, verbose, mp4TagArgs(in, poster, out, meta)...)
+	}
+	extract := func(ctx context.Context, in, out string) error {
+		var err error
+		for _, at := range posterOffsets {
+			err = runFFmpeg(ctx, verbose, "-ss", at, "-i", in, "-frames:v", "1", "-q:v", "2", "-f", "image2", "-y", out)
+			if fi, statErr := os.Stat(out); err == nil && statErr == nil && fi.Size() > 0 {
+				return nil
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("no frame extracted")
+		}
+		return err
+	}
+	return tag, extract
+}
+
+// mp4TagArgs builds the ffmpeg arguments that copy in to out with meta's
+// tags, attaching poster as cover art when it is not "".
+func mp4TagArgs(in, poster, out string, meta *Metadata) []string {
+	args := []string{"-i", in}
+	if poster != "" {
+		args = append(args, "-i", poster)
+	}
+	args = append(args, "-map", "0:v:0", "-map", "0:a?")
+	if poster != "" {
+		args = append(args, "-map", "1:v", "-disposition:v:1", "attached_pic")
+	}
+	args = append(args, "-c", "copy")
+	for _, kv := range mp4Tags(meta) {
+		args = append(args, "-metadata", kv[0]+"="+kv[1])
+	}
+	return append(args, "-movflags", "+faststart", "-f", "mp4", "-y", out)
+}
+
+// mp4Tags returns the metadata atoms written for meta, skipping empty ones.
+func mp4Tags(meta *Metadata) [][2]string {
+	var tags [][2]string
+	add := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			tags = append(tags, [2]string{key, value})
+		}
+	}
+	add("title", meta.Title)
+	if meta.Date != "" {
+		add("date", dateFromISO(meta.Date))
+	}
+	add("artist", strings.Join(flattenStringSlice(meta.Participants), ", "))
+	add("comment", meta.Links.Grain)
+	return tags
+}
+
+// tagVideo writes meta into the MP4 at relPath. Non-MP4 videos (webm kept
+// without ffmpeg remux, HLS URL files) are left alone.
+func (e *Exporter) tagVideo(ctx context.Context, id string, meta *Metadata, relPath string) {
+	if e.tagMP4 == nil || meta == nil || !strings.HasSuffix(relPath, ".mp4") {
+		return
+	}
+	path := e.storage.AbsPath(relPath)
+	tmp := path + ".tag.part"
+	poster := ""
+	if e.poster != nil {
+		poster = path + ".poster.jpg"
+		defer os.Remove(poster)
+		if err := e.poster(ctx, path, poster); err != nil {
+			slog.Debug("Poster frame extraction failed", "id", id, "error", err)
+			poster = ""
+		}
+	}
+	err := e.tagMP4(ctx, path, poster, tmp, meta)
+	if err != nil && poster != "" {
+		// Older ffmpeg builds can't mux cover art into MP4; tag without it.
+		slog.Debug("Tagging with poster failed, retrying without", "id", id, "error", err)
+		err = e.tagMP4(ctx, path, "", tmp, meta)
+	}
+	if err == nil {
+		err = fixPerms(tmp)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		slog.Warn("Video tagging failed, keeping untagged video", "id", id, "error", err)
+		return
+	}
+	slog.Debug("Video tagged", "id", id, "poster", poster != "")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMP4TagArgs(t *testing.T) {
+	meta := &Metadata{
+		Title:        "Q4 Planning",
+		Date:         "2024-11-15T14:00:00Z",
+		Participants: []any{"Alice", "Bob"},
+		Links:        Links{Grain: "https://grain.com/app/meetings/abc"},
+	}
+	got := strings.Join(mp4TagArgs("in.mp4", "poster.jpg", "out.mp4", meta), " ")
+	want := "-i in.mp4 -i poster.jpg -map 0:v:0 -map 0:a? -map 1:v -disposition:v:1 attached_pic -c copy " +
+		"-metadata title=Q4 Planning -metadata date=2024-11-15 -metadata artist=Alice, Bob " +
+		"-metadata comment=https://grain.com/app/meetings/abc -movflags +faststart -f mp4 -y out.mp4"
+	if got != want {
+		t.Errorf("args =\n%s\nwant\n%s", got, want)
+	}
+
+	got = strings.Join(mp4TagArgs("in.mp4", "", "out.mp4", &Metadata{Title: "Untitled"}), " ")
+	if strings.Contains(got, "attached_pic") || strings.Contains(got, "date=") || !strings.Contains(got, "title=Untitled") {
+		t.Errorf("args without poster = %s", got)
+	}
+}
+
+func TestTagVideo(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
+	video := filepath.Join(dir, "m1.mp4")
+	meta := &Metadata{Title: "Standup"}
+
+	var calls []string
+	e.poster = func(_ context.Context, in, out string) error {
+		return os.WriteFile(out, []byte("jpeg"), 0o600)
+	}
+	e.tagMP4 = func(_ context.Context, in, poster, out string, _ *Metadata) error {
+		if poster == "" {
+			calls = append(calls, "none")
+		} else {
+			calls = append(calls, filepath.Base(poster))
+		}
+		if poster != "" {
+			return errors.New("cover art unsupported")
+		}
+		return os.WriteFile(out, []byte("tagged"), 0o644)
+	}
+
+	os.WriteFile(video, []byte("video"), 0o600)
+	e.tagVideo(context.Background(), "m1", meta, "m1.mp4")
+	if strings.Join(calls, ",") != "m1.mp4.poster.jpg,none" {
+		t.Errorf("tag calls = %q, want poster then retry without", calls)
+	}
+	if data, _ := os.ReadFile(video); string(data) != "tagged" {
+		t.Errorf("video = %q", data)
+	}
+	if fi, _ := os.Stat(video); fi.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v", fi.Mode().Perm())
+	}
+	for _, leftover := range []string{video + ".poster.jpg", video + ".tag.part"} {
+		if fileExists(leftover) {
+			t.Errorf("%s left behind", filepath.Base(leftover))
+		}
+	}
+
+	// Failures keep the original; non-MP4s and missing metadata are skipped.
+	e.tagMP4 = func(context.Context, string, string, string, *Metadata) error { return errors.New("boom") }
+	e.tagVideo(context.Background(), "m1", meta, "m1.mp4")
+	if data, _ := os.ReadFile(video); string(data) != "tagged" {
+		t.Errorf("failed tag replaced video: %q", data)
+	}
+	calls = nil
+	e.tagMP4 = func(context.Context, string, string, string, *Metadata) error { calls = append(calls, "x"); return nil }
+	e.tagVideo(context.Background(), "m1", meta, "m1.webm")
+	e.tagVideo(context.Background(), "m1", nil, "m1.mp4")
+	if len(calls) != 0 {
+		t.Errorf("tagged %d skipped videos", len(calls))
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// ── Storage Multiplexer ─────────────────────────────────────────────────────
+//
+// MultiStorage fans every write out to any number of mirror backends (iCloud
+// Drive, WebDAV, S3, ...) behind the Storage interface. The local output
+// directory stays the primary copy: it is written first, reads and existence
+// checks only consult it, and a local failure fails the write. Mirror
+// failures are logged and remembered per path so exportOne can report each
+// backend's outcome in ExportResult.Backends.
+//
+// Google Drive is not a mirror: its uploads need the finished meeting's
+// metadata for --gdrive-route and run after the export, so exportOne records
+// its outcome under the "gdrive" key itself.
+
+// Mirror is a secondary storage target fed by MultiStorage. Paths are
+// relative to the output root.
+type Mirror interface {
+	// Name identifies the backend in logs and ExportResult.Backends.
+	Name() string
+	// Put writes data to relPath.
+	Put(relPath string, data []byte) error
+	// PutFile copies the already-written local file absPath to relPath.
+	PutFile(relPath, absPath string) error
+	// Mkdir creates the directory relPath.
+	Mkdir(relPath string) error
+	// Close persists any mirror state. Called at shutdown.
+	Close() error
+}
+
+// Backend status values in ExportResult.Backends.
+const (
+	backendOK     = "ok"
+	backendFailed = "error"
+)
+
+// backendReporter is implemented by storage that tracks per-backend results.
+type backendReporter interface {
+	BackendStatus(paths []string) map[string]string
+}
+
+// MultiStorage implements Storage over a local primary and mirrors.
+type MultiStorage struct {
+	local   *LocalStorage
+	mirrors []Mirror
+
+	mu     sync.Mutex
+	failed map[string]map[string]error // relPath → mirror name → first error
+}
+
+// NewMultiStorage returns a MultiStorage writing to local and then to each
+// mirror in order.
+func NewMultiStorage(local *LocalStorage, mirrors ...Mirror) *MultiStorage {
+	return &MultiStorage{local: local, mirrors: mirrors, failed: make(map[string]map[string]error)}
+}
+
+func (s *MultiStorage) WriteFile(relPath string, data []byte) error {
+	if err := s.local.WriteFile(relPath, data); err != nil {
+		return err
+	}
+	for _, m := range s.mirrors {
+		s.record(m, relPath, m.Put(relPath, data))
+	}
+	return nil
+}
+
+func (s *MultiStorage) WriteJSON(relPath string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	return s.WriteFile(relPath, data)
+}
+
+// WriteStream streams relPath to the local primary, then copies the file
+// to every mirror.
+func (s *MultiStorage) WriteStream(relPath string, render func(w io.Writer) error) error {
+	if err := s.local.WriteStream(relPath, render); err != nil {
+		return err
+	}
+	s.SyncExternalFile(relPath)
+	return nil
+}
+
+func (s *MultiStorage) FileExists(relPath string) bool {
+	return s.local.FileExists(relPath)
+}
+
+func (s *MultiStorage) EnsureDir(relPath string) error {
+	if err := s.local.EnsureDir(relPath); err != nil {
+		return err
+	}
+	for _, m := range s.mirrors {
+		if err := m.Mkdir(relPath); err != nil {
+			slog.Warn("Mirror dir creation failed", "backend", m.Name(), "path", relPath, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *MultiStorage) AbsPath(relPath string) string {
+	return s.local.AbsPath(relPath)
+}
+
+// SyncExternalFile copies a file written outside Storage (browser video
+// download, ffmpeg output) to every mirror.
+func (s *MultiStorage) SyncExternalFile(relPath string) {
+	abs := s.local.AbsPath(relPath)
+	for _, m := range s.mirrors {
+		s.record(m, relPath, m.PutFile(relPath, abs))
+	}
+}
+
+// Close closes every mirror, returning all errors.
+func (s *MultiStorage) Close() error {
+	var errs []error
+	for _, m := range s.mirrors {
+		if err := m.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BackendStatus reports each mirror's outcome for paths: "ok", or "error: "
+// and the first failure. Reported paths are forgotten, so watch mode does
+// not accumulate state.
+func (s *MultiStorage) BackendStatus(paths []string) map[string]string {
+	if len(s.mirrors) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := make(map[string]string, len(s.mirrors))
+	for _, m := range s.mirrors {
+		status[m.Name()] = backendOK
+	}
+	for _, p := range paths {
+		for name, err := range s.failed[p] {
+			if status[name] == backendOK {
+				status[name] = backendFailed + ": " + err.Error()
+			}
+		}
+		delete(s.failed, p)
+	}
+	return status
+}
+
+// newStorage builds the exporter's storage: plain local output, or a
+// MultiStorage when mirrors (--icloud, --webdav-url, --s3-bucket) are configured.
+func newStorage(cfg *Config) (Storage, error) {
+	local := NewLocalStorage(cfg.OutputDir)
+	local.roots = outputRoots(cfg)
+	var mirrors []Mirror
+	if cfg.ICloud && cfg.ICloudPath != "" {
+		s, err := NewICloudStorage(cfg.OutputDir, cfg.ICloudPath)
+		if err != nil {
+			return nil, fmt.Errorf("icloud storage: %w", err)
+		}
+		s.local.roots = local.roots // it copies routed files from where they are
+		mirrors = append(mirrors, s)
+	}
+	if cfg.WebDAVURL != "" {
+		m, err := NewWebDAVMirror(cfg.WebDAVURL, cfg.WebDAVUser, cfg.WebDAVPassword)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, m)
+	}
+	if cfg.S3.Bucket != "" {
+		s := NewS3Mirror(cfg.OutputDir, &cfg.S3)
+		s.roots = local.roots
+		s.immutable, s.retainUntil = cfg.Immutable, cfg.RetainUntil
+		mirrors = append(mirrors, s)
+	}
+	var s Storage = local
+	if len(mirrors) > 0 {
+		s = NewMultiStorage(local, mirrors...)
+	}
+	if cfg.Immutable {
+		s = NewImmutableStorage(s)
+	}
+	if cfg.Compress != "" {
+		s = NewCompressedStorage(s, cfg.Compress)
+	}
+	return s, nil
+}
+
+// setBackend records a backend outcome on the result.
+func (r *ExportResult) setBackend(name, status string) {
+	if r.Backends == nil {
+		r.Backends = make(map[string]string)
+	}
+	r.Backends[name] = status
+}
+
+func (s *MultiStorage) record(m Mirror, relPath string, err error) {
+	if err == nil {
+		return
+	}
+	slog.Warn("Mirror write failed, local copy preserved", "backend", m.Name(), "path", relPath, "error", err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failed[relPath] == nil {
+		s.failed[relPath] = make(map[string]error)
+	}
+	if _, ok := s.failed[relPath][m.Name()]; !ok {
+		s.failed[relPath][m.Name()] = err
+	}
+}
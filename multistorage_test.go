@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeMirror records writes and fails for paths containing failOn.
+type fakeMirror struct {
+	name   string
+	failOn string
+	mu     sync.Mutex
+	files  map[string]string
+	closed bool
+}
+
+func newFakeMirror(name, failOn string) *fakeMirror {
+	return &fakeMirror{name: name, failOn: failOn, files: map[string]string{}}
+}
+
+func (m *fakeMirror) Name() string { return m.name }
+
+func (m *fakeMirror) Put(relPath string, data []byte) error {
+	if m.failOn != "" && strings.Contains(relPath, m.failOn) {
+		return errors.New("quota exceeded")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[relPath] = string(data)
+	return nil
+}
+
+func (m *fakeMirror) PutFile(relPath, absPath string) error {
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return err
+	}
+	return m.Put(relPath, data)
+}
+
+func (m *fakeMirror) Mkdir(string) error { return nil }
+func (m *fakeMirror) Close() error       { m.closed = true; return nil }
+
+func TestMultiStorageFansOutAndReportsPerBackend(t *testing.T) {
+	dir := t.TempDir()
+	good, bad := newFakeMirror("a", ""), newFakeMirror("b", ".mp4")
+	s := NewMultiStorage(NewLocalStorage(dir), good, bad)
+
+	if err := s.WriteJSON("2025-01-02/m1.json", map[string]string{"id": "m1"}); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "2025-01-02", "m1.mp4"), []byte("video"), 0o600)
+	s.SyncExternalFile("2025-01-02/m1.mp4")
+
+	if !s.FileExists("2025-01-02/m1.json") {
+		t.Error("local copy missing")
+	}
+	if good.files["2025-01-02/m1.mp4"] != "video" || !strings.Contains(good.files["2025-01-02/m1.json"], `"m1"`) {
+		t.Errorf("mirror a files = %v", good.files)
+	}
+
+	status := s.BackendStatus([]string{"2025-01-02/m1.json", "2025-01-02/m1.mp4", ""})
+	if status["a"] != backendOK {
+		t.Errorf("a = %q, want ok", status["a"])
+	}
+	if !strings.HasPrefix(status["b"], "error: ") || !strings.Contains(status["b"], "quota exceeded") {
+		t.Errorf("b = %q, want error", status["b"])
+	}
+	// Reported failures are forgotten.
+	if again := s.BackendStatus([]string{"2025-01-02/m1.mp4"}); again["b"] != backendOK {
+		t.Errorf("b after report = %q, want ok", again["b"])
+	}
+
+	if err := s.Close(); err != nil || !good.closed || !bad.closed {
+		t.Errorf("Close: err=%v closed=%v/%v", err, good.closed, bad.closed)
+	}
+}
+
+func TestNewStorageLocalOnly(t *testing.T) {
+	s, err := newStorage(&Config{OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.(*LocalStorage); !ok {
+		t.Errorf("storage = %T, want *LocalStorage without mirrors", s)
+	}
+}
+
+// ── WebDAV ──────────────────────────────────────────────────────────────────
+
+// davServer is a minimal WebDAV server: MKCOL, PUT, Basic auth.
+func davServer(t *testing.T) (*httptest.Server, map[string]string) {
+	t.Helper()
+	var mu sync.Mutex
+	files, cols := map[string]string{}, map[string]bool{"/dav": true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "me" || p != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		p := strings.TrimSuffix(r.URL.Path, "/")
+		switch r.Method {
+		case "MKCOL":
+			if cols[p] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if !cols[filepath.Dir(p)] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			cols[p] = true
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPut:
+			if !cols[filepath.Dir(p)] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			files[p] = string(body)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, files
+}
+
+func TestWebDAVMirrorExportOne(t *testing.T) {
+	srv, files := davServer(t)
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, SkipVideo: true, MaxDelaySec: 0.01,
+		WebDAVURL: srv.URL + "/dav/", WebDAVUser: "me", WebDAVPassword: "s3cret"}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+
+	r := e.exportOne(context.Background(), MeetingRef{ID: "m 1", Date: "2025-01-15"})
+	if r.Status != "ok" {
+		t.Fatalf("status = %q (%s)", r.Status, r.ErrorMsg)
+	}
+	if r.Backends["webdav"] != backendOK {
+		t.Errorf("Backends = %v, want webdav ok", r.Backends)
+	}
+	if !strings.Contains(files["/dav/2025-01-15/m 1.json"], `"id": "m 1"`) {
+		t.Errorf("metadata not mirrored; files = %v", files)
+	}
+
+	cfg.WebDAVPassword = "wrong"
+	bad, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(bad.Close)
+	r = bad.exportOne(context.Background(), MeetingRef{ID: "m2", Date: "2025-01-15"})
+	if r.Status != "ok" {
+		t.Errorf("mirror failure must not fail the export: status = %q", r.Status)
+	}
+	if !strings.HasPrefix(r.Backends["webdav"], "error: ") {
+		t.Errorf("Backends = %v, want webdav error", r.Backends)
+	}
+}
+
+func TestNewWebDAVMirrorValidation(t *testing.T) {
+	for _, raw := range []string{
+		"ftp://example.com/dav",
+		"http://example.com/dav",
+		"https://user:pw@example.com/dav",
+		"not a url",
+	} {
+		if _, err := NewWebDAVMirror(raw, "", ""); err == nil {
+			t.Errorf("%q: expected error", raw)
+		}
+	}
+	for _, raw := range []string{"https://example.com/dav", "http://localhost:8080/dav", "http://127.0.0.1/dav"} {
+		if _, err := NewWebDAVMirror(raw, "", ""); err != nil {
+			t.Errorf("%q: %v", raw, err)
+		}
+	}
+}
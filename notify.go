@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// ── Desktop Notifications ───────────────────────────────────────────────────
+//
+// --notify-desktop pops up a native notification when an export run ends,
+// so a long backfill can be left running in another window. In watch mode
+// it only fires for cycles that exported new meetings or failed; quiet
+// cycles stay quiet. Each platform's own tool is used, so there is nothing
+// extra to install:
+//
+//	macOS    osascript (display notification)
+//	Linux    notify-send (libnotify)
+//	Windows  PowerShell toast (Windows.UI.Notifications)
+//
+// Title and text never become script source on macOS (they are passed as
+// arguments) and are single-quote escaped on Windows. A notification that
+// can't be shown is logged at debug level; it never fails the run.
+
+// notifyFunc shows a desktop notification.
+type notifyFunc func(ctx context.Context, title, body string) error
+
+// notifyTimeout bounds the notification tool; the run is already over.
+const notifyTimeout = 10 * time.Second
+
+// windowsToastAppID is PowerShell's AppUserModelID. Windows shows toasts
+// only for registered app IDs, and this one always is.
+const windowsToastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+// notifyTool returns the command that shows notifications on goos.
+func notifyTool(goos string) string {
+	switch goos {
+	case "darwin":
+		return "osascript"
+	case "windows":
+		return "powershell"
+	}
+	return "notify-send"
+}
+
+// desktopNotifier returns a notifyFunc for this platform, or nil when its
+// notification tool is not on PATH.
+func desktopNotifier() notifyFunc {
+	if _, err := exec.LookPath(notifyTool(runtime.GOOS)); err != nil {
+		return nil
+	}
+	return func(ctx context.Context, title, body string) error {
+		name, args := notifyCommand(runtime.GOOS, title, body)
+		return runQuiet(ctx, name, args...)
+	}
+}
+
+// notifyCommand builds the command line showing title and body on goos.
+func notifyCommand(goos, title, body string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "osascript", []string{
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, body,
+		}
+	case "windows":
+		quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+		script := strings.Join([]string{
+			"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null",
+			"$x = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+			"$t = $x.GetElementsByTagName('text')",
+			"$t.Item(0).AppendChild($x.CreateTextNode(" + quote(title) + ")) | Out-Null",
+			"$t.Item(1).AppendChild($x.CreateTextNode(" + quote(body) + ")) | Out-Null",
+			"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(" + quote(windowsToastAppID) + ").Show([Windows.UI.Notifications.ToastNotification]::new($x))",
+		}, "; ")
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	}
+	return "notify-send", []string{"--app-name=graindl", title, body}
+}
+
+// runNotification returns the title and text for a finished run, and
+// whether it is worth a notification.
+func runNotification(m *ExportManifest, err error, elapsed time.Duration, watch bool) (string, string, bool) {
+	counts := []string{fmt.Sprintf("%d exported", m.OK)}
+	if m.Skipped > 0 {
+		counts = append(counts, fmt.Sprintf("%d skipped", m.Skipped))
+	}
+	if m.Errors > 0 {
+		counts = append(counts, fmt.Sprintf("%d failed", m.Errors))
+	}
+	if m.HLSPending > 0 {
+		counts = append(counts, fmt.Sprintf("%d HLS pending", m.HLSPending))
+	}
+	body := strings.Join(counts, ", ") + " in " + elapsed.Round(time.Second).String()
+
+	switch {
+	case err != nil:
+		return "graindl: export failed", truncateRunes(redactSecrets(err.Error()), 200) + " (" + body + ")", true
+	case watch && m.OK == 0:
+		return "", "", false
+	case watch:
+		return fmt.Sprintf("graindl: %d new meeting(s) exported", m.OK), body, true
+	}
+	return "graindl: export finished", body, true
+}
+
+// notifyRun shows the desktop notification for a finished run.
+func (e *Exporter) notifyRun(ctx context.Context, err error, elapsed time.Duration) {
+	if e.notify == nil || ctx.Err() != nil || e.cfg.Plan || e.cfg.Members || e.cfg.Stdout != "" {
+		return
+	}
+	title, body, ok := runNotification(e.manifest, err, elapsed, e.cfg.Watch)
+	if !ok {
+		return
+	}
+	nctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+	defer cancel()
+	if err := e.notify(nctx, title, body); err != nil {
+		slog.Debug("Desktop notification failed", "error", err)
+	}
+}
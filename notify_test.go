@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifyCommand(t *testing.T) {
+	title, body := `graindl: "done"`, "It's 3 exported; $(rm -rf /)"
+
+	name, args := notifyCommand("darwin", title, body)
+	if name != "osascript" || args[len(args)-2] != title || args[len(args)-1] != body {
+		t.Errorf("darwin: %s %q", name, args)
+	}
+	for _, a := range args[:len(args)-2] {
+		if strings.Contains(a, "rm -rf") {
+			t.Errorf("darwin: body spliced into script: %q", a)
+		}
+	}
+
+	name, args = notifyCommand("linux", title, body)
+	if name != "notify-send" || args[len(args)-2] != title || args[len(args)-1] != body {
+		t.Errorf("linux: %s %q", name, args)
+	}
+
+	name, args = notifyCommand("windows", title, body)
+	script := args[len(args)-1]
+	if name != "powershell" || !strings.Contains(script, `'It''s 3 exported; $(rm -rf /)'`) || !strings.Contains(script, `'graindl: "done"'`) {
+		t.Errorf("windows: %s %q", name, args)
+	}
+}
+
+func TestRunNotification(t *testing.T) {
+	m := &ExportManifest{OK: 3, Skipped: 40, Errors: 1}
+	title, body, ok := runNotification(m, nil, 95*time.Second, false)
+	if !ok || title != "graindl: export finished" || body != "3 exported, 40 skipped, 1 failed in 1m35s" {
+		t.Errorf("run: %v %q %q", ok, title, body)
+	}
+	if _, _, ok := runNotification(&ExportManifest{Skipped: 12}, nil, time.Minute, true); ok {
+		t.Error("quiet watch cycle notified")
+	}
+	if title, _, ok := runNotification(m, nil, time.Minute, true); !ok || !strings.Contains(title, "3 new meeting") {
+		t.Errorf("watch cycle: %v %q", ok, title)
+	}
+	title, body, ok = runNotification(&ExportManifest{}, errAuthBlocked, time.Minute, true)
+	if !ok || title != "graindl: export failed" || !strings.HasPrefix(body, errAuthBlocked.Error()) {
+		t.Errorf("failure: %v %q %q", ok, title, body)
+	}
+}
+
+func TestNotifyRun(t *testing.T) {
+	var shown []string
+	e := &Exporter{
+		cfg:      &Config{},
+		manifest: &ExportManifest{OK: 1},
+		notify: func(_ context.Context, title, body string) error {
+			shown = append(shown, title)
+			return errors.New("no notification daemon") // logged, not fatal
+		},
+	}
+	e.notifyRun(context.Background(), nil, time.Second)
+
+	e.cfg.Plan = true // graindl plan reports on the terminal
+	e.notifyRun(context.Background(), nil, time.Second)
+	e.cfg.Plan = false
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // interrupted runs are not announced
+	e.notifyRun(ctx, nil, time.Second)
+
+	if len(shown) != 1 {
+		t.Errorf("notifications = %q", shown)
+	}
+}
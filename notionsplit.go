@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// ── Notion Split ────────────────────────────────────────────────────────────
+//
+// Notion's markdown import fails on files much above 1MB, which a long
+// meeting's transcript table easily passes. With --output-format notion, a
+// note larger than --notion-max-size keeps the summary, notes, and start of
+// the transcript in <base>.md and continues the transcript in
+// <base>.part2.md, <base>.part3.md, ..., each linked to its neighbours.
+// Splits fall between transcript rows, never inside one.
+
+const (
+	defaultNotionMaxSize = "1MB"
+	minNotionMaxSize     = 64 << 10
+
+	notionTranscriptHeading = "\n## Transcript\n\n"
+	notionNavReserve        = 512 // bytes kept free per part for navigation links
+)
+
+// notionPartPath returns the path of continuation part n (n >= 2) of the
+// note at relPath.
+func notionPartPath(relPath string, n int) string {
+	return fmt.Sprintf("%s.part%d.md", strings.TrimSuffix(relPath, ".md"), n)
+}
+
+// splitNotionMarkdown splits a rendered Notion note into parts of at most
+// limit bytes, where the note's base file is named base. It returns nil
+// when md fits, or has no transcript to move into continuation parts. A
+// single transcript row larger than a part still gets a part to itself.
+func splitNotionMarkdown(md string, limit int, meta *Metadata, base string) []string {
+	if len(md) <= limit {
+		return nil
+	}
+	i := strings.LastIndex(md, notionTranscriptHeading)
+	if i < 0 {
+		return nil
+	}
+	head := md[:i+len(notionTranscriptHeading)]
+	rows := strings.SplitAfter(md[len(head):], "\n")
+	if rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	tableHeader := ""
+	if len(rows) >= 2 && strings.HasPrefix(rows[0], "| Time |") {
+		tableHeader = rows[0] + rows[1]
+		rows = rows[2:]
+	}
+
+	title := coalesce(meta.Title, meta.ID)
+	budget := limit - len(head) - len(tableHeader) - notionNavReserve
+	contBudget := limit - len(notionPartHead(title, meta.ID, 99, 99, "")) - len(tableHeader) - 2*notionNavReserve
+
+	var chunks [][]string
+	var cur []string
+	size := 0
+	for _, row := range rows {
+		if size+len(row) > budget && (len(cur) > 0 || len(chunks) == 0) {
+			chunks = append(chunks, cur)
+			cur, size, budget = nil, 0, contBudget
+		}
+		cur = append(cur, row)
+		size += len(row)
+	}
+	chunks = append(chunks, cur)
+	if len(chunks) < 2 {
+		return nil
+	}
+
+	n := len(chunks)
+	parts := make([]string, n)
+	parts[0] = head + tableHeader + strings.Join(chunks[0], "") + "\n" + notionNav(base, 1, n) + "\n"
+	for p := 2; p <= n; p++ {
+		nav := notionNav(base, p, n)
+		parts[p-1] = notionPartHead(title, meta.ID, p, n, nav) + tableHeader +
+			strings.Join(chunks[p-1], "") + "\n" + nav + "\n"
+	}
+	return parts
+}
+
+// notionPartHead renders the frontmatter, heading, and top navigation of
+// continuation part p of n.
+func notionPartHead(title, id string, p, n int, nav string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeYAMLField(&b, "title", fmt.Sprintf("%s (part %d)", title, p))
+	writeYAMLField(&b, "type", "Meeting Transcript")
+	writeYAMLField(&b, "grain_id", id)
+	writeYAMLField(&b, "part", fmt.Sprint(p))
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s — Transcript (part %d of %d)\n\n", title, p, n)
+	b.WriteString(nav)
+	b.WriteString("\n\n")
+	return b.String()
+}
+
+// notionNav renders the links between part p of n and its neighbours.
+func notionNav(base string, p, n int) string {
+	link := func(label string, q int) string {
+		name := base + ".md"
+		if q > 1 {
+			name = path.Base(notionPartPath(name, q))
+		}
+		return fmt.Sprintf("[%s](%s)", label, url.PathEscape(name))
+	}
+	items := []string{fmt.Sprintf("*Transcript part %d of %d*", p, n)}
+	if p > 1 {
+		items = append(items, link(fmt.Sprintf("← Part %d", p-1), p-1))
+	}
+	if p < n {
+		items = append(items, link(fmt.Sprintf("Part %d →", p+1), p+1))
+	}
+	return strings.Join(items, " · ")
+}
+
+// writeNotionParts writes continuation parts 2..n of the note at relPath,
+// records them on r, and removes parts left over from an earlier, longer
+// split.
+func (e *Exporter) writeNotionParts(relPath string, parts []string, r *ExportResult) {
+	r.MarkdownParts = nil
+	for i, part := range parts[1:] {
+		partPath := notionPartPath(relPath, i+2)
+		if err := e.storage.WriteFile(partPath, []byte(part)); err != nil {
+			slog.Error("Markdown part write failed", "error", err, "path", partPath)
+			continue
+		}
+		r.MarkdownParts = append(r.MarkdownParts, partPath)
+	}
+	for n := len(parts) + 1; ; n++ {
+		abs := e.storage.AbsPath(notionPartPath(relPath, n))
+		if sealed(abs) || os.Remove(abs) != nil {
+			break
+		}
+	}
+}
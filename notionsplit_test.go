@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// longTranscript returns n speaker turns of roughly 100 bytes each.
+func longTranscript(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%02d:%02d:%02d Speaker %d: Line %04d %s\n\n", i/3600, i/60%60, i%60, i%3, i, strings.Repeat("word ", 16))
+	}
+	return b.String()
+}
+
+func TestSplitNotionMarkdownFits(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Short"}
+	md := renderFormattedMarkdown("notion", meta, longTranscript(3))
+	if parts := splitNotionMarkdown(md, len(md), meta, "m1"); parts != nil {
+		t.Errorf("note within the limit was split into %d parts", len(parts))
+	}
+	noTranscript := renderFormattedMarkdown("notion", &Metadata{ID: "m2", AINotes: strings.Repeat("note ", 1000)}, "")
+	if parts := splitNotionMarkdown(noTranscript, 100, meta, "m2"); parts != nil {
+		t.Errorf("note without a transcript was split into %d parts", len(parts))
+	}
+}
+
+func TestSplitNotionMarkdown(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Weekly Sync", Links: Links{Grain: "https://grain.com/app/meetings/m1"}}
+	md := renderFormattedMarkdown("notion", meta, longTranscript(400))
+	const limit = 16 << 10
+	parts := splitNotionMarkdown(md, limit, meta, "Weekly Sync")
+	if len(parts) < 3 {
+		t.Fatalf("parts = %d, want at least 3 for a %d byte note", len(parts), len(md))
+	}
+
+	var rows []string
+	for i, part := range parts {
+		if len(part) > limit {
+			t.Errorf("part %d is %d bytes, limit %d", i+1, len(part), limit)
+		}
+		if !strings.Contains(part, "| Time | Speaker | Text |\n| --- | --- | --- |\n") {
+			t.Errorf("part %d has no table header", i+1)
+		}
+		nav := fmt.Sprintf("*Transcript part %d of %d*", i+1, len(parts))
+		if !strings.Contains(part, nav) {
+			t.Errorf("part %d has no navigation %q", i+1, nav)
+		}
+		for _, line := range strings.Split(part, "\n") {
+			if strings.HasPrefix(line, "| [") {
+				rows = append(rows, line)
+			}
+		}
+	}
+	if len(rows) != 400 {
+		t.Errorf("rows across parts = %d, want 400", len(rows))
+	}
+
+	if !strings.HasPrefix(parts[0], "---\ntitle: Weekly Sync\n") || !strings.Contains(parts[0], "[Part 2 →](Weekly%20Sync.part2.md)") {
+		t.Errorf("part 1 should keep the note head and link to part 2:\n%s", parts[0][:200])
+	}
+	second := parts[1]
+	for _, want := range []string{
+		`title: Weekly Sync (part 2)`,
+		"grain_id: m1\n",
+		"# Weekly Sync — Transcript (part 2 of",
+		"[← Part 1](Weekly%20Sync.md)",
+		"[Part 3 →](Weekly%20Sync.part3.md)",
+	} {
+		if !strings.Contains(second, want) {
+			t.Errorf("part 2 missing %q", want)
+		}
+	}
+	if strings.Contains(parts[len(parts)-1], "→]") {
+		t.Error("last part should not link forward")
+	}
+}
+
+func TestWriteFormattedMarkdownSplitsNotion(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{OutputDir: dir, OutputFormat: "notion", NotionMaxSize: 16 << 10}
+	e := &Exporter{cfg: cfg, storage: NewLocalStorage(dir)}
+	meta := &Metadata{ID: "m1", Title: "Long"}
+	r := &ExportResult{}
+
+	e.writeFormattedMarkdown(meta, longTranscript(400), "2025-01-15/m1", r)
+	if len(r.MarkdownParts) < 2 {
+		t.Fatalf("markdown parts = %v", r.MarkdownParts)
+	}
+	if r.MarkdownParts[0] != "2025-01-15/m1.part2.md" {
+		t.Errorf("first continuation = %q", r.MarkdownParts[0])
+	}
+	for _, p := range append([]string{r.MarkdownPath}, r.MarkdownParts...) {
+		info, err := os.Stat(filepath.Join(dir, p))
+		if err != nil {
+			t.Fatalf("part not written: %v", err)
+		}
+		if info.Size() > int64(cfg.NotionMaxSize) {
+			t.Errorf("%s is %d bytes", p, info.Size())
+		}
+	}
+	if paths := strings.Join(collectResultPaths(r), ","); !strings.Contains(paths, "m1.part2.md") {
+		t.Errorf("result paths miss the parts: %s", paths)
+	}
+
+	// A shorter re-export removes the parts it no longer needs.
+	stale := r.MarkdownParts
+	r = &ExportResult{}
+	e.writeFormattedMarkdown(meta, longTranscript(3), "2025-01-15/m1", r)
+	if len(r.MarkdownParts) != 0 {
+		t.Errorf("parts after shrinking = %v", r.MarkdownParts)
+	}
+	for _, p := range stale {
+		if fileExists(filepath.Join(dir, p)) {
+			t.Errorf("stale part %s left behind", p)
+		}
+	}
+
+	// Splitting is off with a zero limit.
+	cfg.NotionMaxSize = 0
+	e.writeFormattedMarkdown(meta, longTranscript(400), "2025-01-15/m1", r)
+	if len(r.MarkdownParts) != 0 {
+		t.Errorf("parts with --notion-max-size 0 = %v", r.MarkdownParts)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ── Output Roots ────────────────────────────────────────────────────────────
+//
+// --markdown-output, --video-output, and --data-output root one class of
+// per-meeting artifact somewhere other than --output, e.g. notes inside an
+// Obsidian vault and everything else on a NAS. Each class keeps the
+// archive's layout (<date>/<id>.<ext>) under its own root, so paths in the
+// manifest and metadata stay relative and mean the same thing; the manifest
+// records the roots in "output_roots". LocalStorage does the routing, so
+// every writer that goes through Storage follows it. --output keeps the
+// manifest, the delta, and every hidden state file; each routed root gets a
+// rootMarkerFile naming it.
+//
+// The offline commands that read a single archive directory (gc, stats,
+// digest, share, verify-local, relink, hls-convert, diff) refuse routed
+// archives through checkUnrouted: they would judge a note or video without
+// metadata beside it orphaned, and gc would delete it.
+//
+//	markdown  notes, Notion parts, rollups, highlight pages, and the
+//	          highlight previews they embed
+//	video     videos, extracted audio, HLS URL files, and assets zips
+//	data      metadata, transcripts, highlights, AI notes, and snapshots
+
+// Artifact classes that can be routed to their own root.
+const (
+	outputMarkdown = "markdown"
+	outputVideo    = "video"
+	outputData     = "data"
+)
+
+// highlightPreviewRe matches the <id>.highlight-<n>.<gif|webp> previews.
+var highlightPreviewRe = regexp.MustCompile(`\.highlight-\d+\.(gif|webp)$`)
+
+// OutputRoots maps an artifact class to the directory its files go under.
+// Classes without an entry stay under --output.
+type OutputRoots map[string]string
+
+// outputRoots returns the routed classes from cfg, leaving out any that
+// point at --output itself.
+func outputRoots(cfg *Config) OutputRoots {
+	roots := OutputRoots{}
+	for class, dir := range map[string]string{
+		outputMarkdown: cfg.MarkdownOutput,
+		outputVideo:    cfg.VideoOutput,
+		outputData:     cfg.DataOutput,
+	} {
+		if dir != "" && filepath.Clean(dir) != filepath.Clean(cfg.OutputDir) {
+			roots[class] = dir
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+	return roots
+}
+
+// dataDir is where the archive's metadata lives: --data-output when set,
+// else --output.
+func (c *Config) dataDir() string {
+	return coalesce(c.DataOutput, c.OutputDir)
+}
+
+// outputClass returns the artifact class of relPath, or "" for files that
+// always stay under --output: anything whose name starts with "_" or ".".
+func outputClass(relPath string) string {
+	name := filepath.Base(trimCompressed(relPath))
+	if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+		return ""
+	}
+	switch {
+	case strings.HasSuffix(name, assetsSuffix), strings.HasSuffix(name, ".url"):
+		return outputVideo
+	case highlightPreviewRe.MatchString(name):
+		return outputMarkdown
+	}
+	switch classifyContent(name) {
+	case "markdown":
+		return outputMarkdown
+	case "video", "audio":
+		return outputVideo
+	}
+	return outputData
+}
+
+// root returns the directory relPath goes under, base when its class is
+// not routed.
+func (o OutputRoots) root(base, relPath string) string {
+	if dir := o[outputClass(relPath)]; dir != "" {
+		return dir
+	}
+	return base
+}
+
+// rel turns abs back into a path relative to whichever root holds it, the
+// deepest when roots nest. abs is returned as is when no root holds it.
+func (o OutputRoots) rel(base, abs string) string {
+	best, bestRoot := abs, ""
+	for _, root := range append([]string{base}, o.dirs()...) {
+		r, err := filepath.Rel(root, abs)
+		if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if bestRoot == "" || len(filepath.Clean(root)) > len(filepath.Clean(bestRoot)) {
+			best, bestRoot = r, root
+		}
+	}
+	return best
+}
+
+// dirs returns the routed directories.
+func (o OutputRoots) dirs() []string {
+	var dirs []string
+	for _, dir := range o {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// manifestRoots returns the roots as absolute paths for the manifest.
+func (o OutputRoots) manifestRoots() OutputRoots {
+	if len(o) == 0 {
+		return nil
+	}
+	abs := make(OutputRoots, len(o))
+	for class, dir := range o {
+		abs[class] = absPath(dir)
+	}
+	return abs
+}
+
+// checkOutputRoots rejects routing that other settings can't follow.
+func checkOutputRoots(cfg *Config) error {
+	if len(outputRoots(cfg)) == 0 {
+		return nil
+	}
+	if cfg.GDrive {
+		return fmt.Errorf("--markdown-output, --video-output, and --data-output can't be combined with --gdrive: Drive uploads read every file from --output")
+	}
+	return nil
+}
+
+// rootMarkerFile marks a routed root as part of the archive in --output.
+const rootMarkerFile = ".graindl-root.json"
+
+// rootMarker is the content of rootMarkerFile.
+type rootMarker struct {
+	Class  string `json:"class"`
+	Output string `json:"output"` // the archive's --output, absolute
+}
+
+// writeMarkers writes rootMarkerFile to every routed root.
+func (o OutputRoots) writeMarkers(outputDir string) error {
+	for class, dir := range o {
+		data, err := json.MarshalIndent(rootMarker{Class: class, Output: absPath(outputDir)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(dir, rootMarkerFile), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkUnrouted returns an error when dir is part of a routed archive: an
+// --output whose manifest records output_roots, or a routed root itself.
+// cmd names the offline command for the message.
+func checkUnrouted(cmd, dir string) error {
+	var m struct {
+		OutputRoots OutputRoots `json:"output_roots"`
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "_export-manifest.json")); err == nil && json.Unmarshal(data, &m) == nil && len(m.OutputRoots) > 0 {
+		return fmt.Errorf("graindl %s doesn't support archives split with --markdown-output, --video-output, or --data-output: %s keeps only part of it (the rest is under %s)", cmd, dir, strings.Join(m.OutputRoots.sortedDirs(), ", "))
+	}
+	var marker rootMarker
+	if data, err := os.ReadFile(filepath.Join(dir, rootMarkerFile)); err == nil && json.Unmarshal(data, &marker) == nil {
+		return fmt.Errorf("graindl %s doesn't support archives split with --markdown-output, --video-output, or --data-output: %s is the %s root of the archive in %s", cmd, dir, marker.Class, marker.Output)
+	}
+	return nil
+}
+
+// sortedDirs returns the routed directories in class order.
+func (o OutputRoots) sortedDirs() []string {
+	var dirs []string
+	for _, class := range []string{outputData, outputMarkdown, outputVideo} {
+		if dir := o[class]; dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
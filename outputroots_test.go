@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputClass(t *testing.T) {
+	for path, want := range map[string]string{
+		"2025-01-15/abc.md":                  outputMarkdown,
+		"2025-01-15/abc.part-2.md":           outputMarkdown,
+		"highlights/customer.md":             outputMarkdown,
+		"tasks.md":                           outputMarkdown,
+		"2025-01-15/abc.highlight-3.gif":     outputMarkdown,
+		"2025-01-15/abc.mp4":                 outputVideo,
+		"2025-01-15/abc.webm":                outputVideo,
+		"2025-01-15/abc.m4a":                 outputVideo,
+		"2025-01-15/abc.m3u8.url":            outputVideo,
+		"2025-01-15/abc.assets.zip":          outputVideo,
+		"2025-01-15/abc.json":                outputData,
+		"2025-01-15/abc.json.gz":             outputData,
+		"2025-01-15/abc.transcript.txt":      outputData,
+		"2025-01-15/abc.highlights.json":     outputData,
+		"2025-01-15/abc.mhtml":               outputData,
+		"_export-manifest.json":              "",
+		"_delta.json":                        "",
+		".graindl-checksums.json":            "",
+		"2025-01-15/.graindl-claim-abc.json": "",
+	} {
+		if got := outputClass(path); got != want {
+			t.Errorf("outputClass(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestLocalStorageOutputRoots(t *testing.T) {
+	base, vault, nas := t.TempDir(), t.TempDir(), t.TempDir()
+	cfg := &Config{OutputDir: base, MarkdownOutput: vault, VideoOutput: nas, DataOutput: base}
+	roots := outputRoots(cfg)
+	if len(roots) != 2 || roots[outputData] != "" {
+		t.Fatalf("roots = %v; --data-output equal to --output should not be routed", roots)
+	}
+	s := NewLocalStorage(base)
+	s.roots = roots
+
+	if err := s.EnsureDir("2025-01-15"); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{base, vault, nas} {
+		if _, err := os.Stat(filepath.Join(dir, "2025-01-15")); err != nil {
+			t.Errorf("date dir missing under %s", dir)
+		}
+	}
+	for rel, dir := range map[string]string{
+		"2025-01-15/abc.md":     vault,
+		"2025-01-15/abc.mp4":    nas,
+		"2025-01-15/abc.json":   base,
+		"_export-manifest.json": base,
+	} {
+		if err := s.WriteFile(rel, []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("%s not under %s: %v", rel, dir, err)
+		}
+		if !s.FileExists(rel) {
+			t.Errorf("FileExists(%q) = false", rel)
+		}
+		if got := roots.rel(base, s.AbsPath(rel)); got != filepath.FromSlash(rel) {
+			t.Errorf("rel(AbsPath(%q)) = %q", rel, got)
+		}
+	}
+
+	// A root nested inside --output maps back relative to itself.
+	nested := OutputRoots{outputVideo: filepath.Join(base, "videos")}
+	if got := nested.rel(base, filepath.Join(base, "videos", "2025-01-15", "abc.mp4")); got != filepath.Join("2025-01-15", "abc.mp4") {
+		t.Errorf("nested rel = %q", got)
+	}
+
+	if m := roots.manifestRoots(); m[outputMarkdown] != absPath(vault) || m[outputVideo] != absPath(nas) {
+		t.Errorf("manifest roots = %v", m)
+	}
+	cfg.GDrive = true
+	if checkOutputRoots(cfg) == nil {
+		t.Error("--gdrive with routed output accepted")
+	}
+}
+
+func TestCheckUnrouted(t *testing.T) {
+	out, data := t.TempDir(), t.TempDir()
+	writeArchiveMeta(t, out, "2025-01-15", &Metadata{ID: "m1"})
+	if err := checkUnrouted("gc", out); err != nil {
+		t.Fatalf("plain archive: %v", err)
+	}
+
+	roots := OutputRoots{outputData: data}
+	if err := writeFile(filepath.Join(out, "_export-manifest.json"), []byte(`{"output_roots":{"data":"`+data+`"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := roots.writeMarkers(out); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{out, data} {
+		if err := checkUnrouted("gc", dir); err == nil {
+			t.Errorf("%s: routed archive accepted", dir)
+		}
+	}
+
+	// gc refuses instead of listing the routed notes and videos.
+	writeArchiveFile(t, out, "2025-01-15/m2.md", "---\ngrain_id: m2\n---\n", 0)
+	if code := runGC([]string{"--output", out, "--apply"}); code != 1 {
+		t.Errorf("gc on routed archive exit = %d, want 1", code)
+	}
+	if !fileExists(filepath.Join(out, "2025-01-15", "m2.md")) {
+		t.Error("gc removed a note of a routed archive")
+	}
+}
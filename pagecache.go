@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// ── Meeting Page Reuse ──────────────────────────────────────────────────────
+//
+// Exporting a meeting used to load its page up to three times: to scrape it,
+// to download the video, and (with --audio-only) to find the stream ffmpeg
+// reads. Browser now remembers the meeting page its tab has open and the
+// video source found there. While the tab still shows that meeting, later
+// steps work on the loaded page instead of navigating again, and a source
+// found once is not looked up again. Any other navigation (a different
+// meeting on a --parallel shared page, a login redirect) changes the tab's
+// URL, so the next step loads the page afresh.
+
+// meetingPage is the meeting a Browser's tab has loaded.
+type meetingPage struct {
+	pageURL  string // meeting page requested
+	at       string // tab URL once loaded (Grain may redirect or rewrite it)
+	source   string // video source found on the page
+	searched bool   // source was looked up, even if none was found
+}
+
+// loaded reports whether a tab now at current still shows pageURL.
+func (p *meetingPage) loaded(pageURL, current string) bool {
+	return p.pageURL == pageURL && p.at != "" && p.at == current
+}
+
+// open records pageURL as loaded at current, dropping what was known about
+// another meeting.
+func (p *meetingPage) open(pageURL, current string) {
+	if p.pageURL != pageURL {
+		*p = meetingPage{pageURL: pageURL}
+	}
+	p.at = current
+}
+
+// videoSource returns the source found for pageURL, and whether it was
+// looked up at all.
+func (p *meetingPage) videoSource(pageURL string) (string, bool) {
+	if p.pageURL != pageURL {
+		return "", false
+	}
+	return p.source, p.searched || p.source != ""
+}
+
+// setSource records the result of a full source lookup on pageURL.
+func (p *meetingPage) setSource(pageURL, source string) {
+	if p.pageURL != pageURL {
+		*p = meetingPage{pageURL: pageURL}
+	}
+	p.source, p.searched = source, true
+}
+
+// openMeetingPage loads pageURL in the tab unless it is still showing it.
+func (b *Browser) openMeetingPage(pageURL string) error {
+	if b.meeting.loaded(pageURL, b.currentURL()) {
+		slog.Debug("Reusing loaded meeting page", "url", pageURL)
+		return nil
+	}
+	if err := rod.Try(func() {
+		b.page.Timeout(20 * time.Second).MustNavigate(pageURL).MustWaitStable()
+	}); err != nil {
+		b.meeting = meetingPage{}
+		return err
+	}
+	time.Sleep(2 * time.Second)
+	b.markMeetingPage(pageURL)
+	return nil
+}
+
+// markMeetingPage records that the tab shows pageURL at its current URL.
+// Login pages are never recorded, so a step after a redirect navigates
+// again.
+func (b *Browser) markMeetingPage(pageURL string) {
+	current := b.currentURL()
+	if current == "" || isLoginURL(current) {
+		b.meeting = meetingPage{}
+		return
+	}
+	b.meeting.open(pageURL, current)
+}
+
+// currentURL returns the tab's URL, or "" when it can't be read.
+func (b *Browser) currentURL() string {
+	info, err := b.page.Info()
+	if err != nil {
+		return ""
+	}
+	return info.URL
+}
+
+// pageVideoSource returns the video source of the loaded meeting page
+// pageURL, looking it up once: in the DOM, then in network requests.
+func (b *Browser) pageVideoSource(pageURL string) string {
+	if src, ok := b.meeting.videoSource(pageURL); ok {
+		return src
+	}
+	src := b.extractVideoURL()
+	if src == "" {
+		src = b.interceptNetwork(pageURL)
+	}
+	b.meeting.setSource(pageURL, src)
+	return src
+}
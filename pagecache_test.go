@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestMeetingPageState(t *testing.T) {
+	const a, b = "https://grain.com/app/meetings/a", "https://grain.com/app/meetings/b"
+	var p meetingPage
+	if p.loaded(a, "") || p.loaded(a, a) {
+		t.Error("nothing loaded yet")
+	}
+	if _, ok := p.videoSource(a); ok {
+		t.Error("source known before any lookup")
+	}
+
+	// Grain rewrote the URL; the tab counts as loaded while it stays there.
+	p.open(a, a+"/transcript")
+	if !p.loaded(a, a+"/transcript") {
+		t.Error("loaded page not recognized")
+	}
+	if p.loaded(a, b) || p.loaded(b, a+"/transcript") {
+		t.Error("tab moved on, or another meeting requested")
+	}
+
+	// A lookup that found nothing is remembered too.
+	p.setSource(a, "")
+	if src, ok := p.videoSource(a); !ok || src != "" {
+		t.Errorf("empty lookup = %q, %v", src, ok)
+	}
+	p.setSource(a, "https://cdn.grain.com/a.mp4")
+	p.open(a, a) // reloaded: same meeting, source kept
+	if src, ok := p.videoSource(a); !ok || src != "https://cdn.grain.com/a.mp4" {
+		t.Errorf("source after reload = %q, %v", src, ok)
+	}
+
+	// Opening another meeting forgets the first one.
+	p.open(b, b)
+	if _, ok := p.videoSource(a); ok || p.loaded(a, a) {
+		t.Error("state of the previous meeting kept")
+	}
+	if _, ok := p.videoSource(b); ok {
+		t.Error("source carried over to another meeting")
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ── Parquet Writer ──────────────────────────────────────────────────────────
+//
+// A minimal Parquet writer for --embeddings-out, so the vector file loads
+// straight into pandas, DuckDB, LanceDB, and the like without a new
+// dependency. It covers what the embeddings file needs and nothing more:
+// one row group, one uncompressed data page per column, PLAIN values, and
+// three column kinds: required UTF-8 strings, required doubles, and a
+// required list of floats (the standard three-level LIST layout). The file
+// footer is Thrift compact protocol, hand-encoded below.
+
+// parquetColumn is one column of a table. Exactly one of the value slices
+// is set, with one entry per row.
+type parquetColumn struct {
+	Name    string
+	Strings []string
+	Doubles []float64
+	Floats  [][]float32 // list<float>; empty lists are not supported
+}
+
+// Parquet physical types, encodings, and Thrift compact type IDs.
+const (
+	parquetDouble    = 5
+	parquetFloat     = 4
+	parquetByteArray = 6
+
+	parquetRequired = 0
+	parquetRepeated = 2
+
+	parquetUTF8 = 0 // ConvertedType
+	parquetList = 3
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// rows returns the column's row count.
+func (c *parquetColumn) rows() int {
+	switch {
+	case c.Strings != nil:
+		return len(c.Strings)
+	case c.Doubles != nil:
+		return len(c.Doubles)
+	}
+	return len(c.Floats)
+}
+
+// physicalType returns the Parquet type of the column's leaf values.
+func (c *parquetColumn) physicalType() int32 {
+	switch {
+	case c.Strings != nil:
+		return parquetByteArray
+	case c.Doubles != nil:
+		return parquetDouble
+	}
+	return parquetFloat
+}
+
+// path returns the column's leaf path in the schema.
+func (c *parquetColumn) path() []string {
+	if c.Floats != nil {
+		return []string{c.Name, "list", "element"}
+	}
+	return []string{c.Name}
+}
+
+// page encodes the column's data page body and returns it with the number
+// of values in it (list elements count individually).
+func (c *parquetColumn) page() ([]byte, int) {
+	var buf bytes.Buffer
+	switch {
+	case c.Strings != nil:
+		for _, s := range c.Strings {
+			binary.Write(&buf, binary.LittleEndian, uint32(len(s)))
+			buf.WriteString(s)
+		}
+		return buf.Bytes(), len(c.Strings)
+	case c.Doubles != nil:
+		for _, d := range c.Doubles {
+			binary.Write(&buf, binary.LittleEndian, math.Float64bits(d))
+		}
+		return buf.Bytes(), len(c.Doubles)
+	}
+
+	// Each row's first element starts a new list (repetition level 0), the
+	// rest continue it (1). Every element is present (definition level 1).
+	var rep, vals bytes.Buffer
+	n := 0
+	for _, list := range c.Floats {
+		rleRun(&rep, 1, 0)
+		if len(list) > 1 {
+			rleRun(&rep, len(list)-1, 1)
+		}
+		for _, f := range list {
+			binary.Write(&vals, binary.LittleEndian, math.Float32bits(f))
+		}
+		n += len(list)
+	}
+	var def bytes.Buffer
+	rleRun(&def, n, 1)
+	for _, levels := range []*bytes.Buffer{&rep, &def} {
+		binary.Write(&buf, binary.LittleEndian, uint32(levels.Len()))
+		buf.Write(levels.Bytes())
+	}
+	buf.Write(vals.Bytes())
+	return buf.Bytes(), n
+}
+
+// rleRun appends a run of count copies of a one-bit level in the
+// RLE/bit-packing hybrid encoding.
+func rleRun(buf *bytes.Buffer, count, value int) {
+	buf.Write(binary.AppendUvarint(nil, uint64(count)<<1))
+	buf.WriteByte(byte(value))
+}
+
+// encodeParquet returns a Parquet file holding cols, which must all have
+// the same number of rows.
+func encodeParquet(cols []parquetColumn) ([]byte, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("parquet: no columns")
+	}
+	rows := cols[0].rows()
+	for i := range cols {
+		if n := cols[i].rows(); n != rows {
+			return nil, fmt.Errorf("parquet: column %s has %d rows, want %d", cols[i].Name, n, rows)
+		}
+		for _, list := range cols[i].Floats {
+			if len(list) == 0 {
+				return nil, fmt.Errorf("parquet: column %s has an empty list", cols[i].Name)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("PAR1")
+	type chunk struct {
+		offset, size int64
+		values       int
+	}
+	chunks := make([]chunk, len(cols))
+	for i := range cols {
+		body, values := cols[i].page()
+		var h thriftWriter
+		h.begin()
+		h.i32(1, 0) // DATA_PAGE
+		h.i32(2, int32(len(body)))
+		h.i32(3, int32(len(body)))
+		h.structField(5) // DataPageHeader
+		h.i32(1, int32(values))
+		h.i32(2, parquetPlain)
+		h.i32(3, parquetRLE)
+		h.i32(4, parquetRLE)
+		h.end()
+		h.end()
+		chunks[i] = chunk{offset: int64(out.Len()), size: int64(len(h.b) + len(body)), values: values}
+		out.Write(h.b)
+		out.Write(body)
+	}
+
+	var m thriftWriter
+	m.begin()
+	m.i32(1, 1) // version
+	schema := 1
+	for i := range cols {
+		schema += len(cols[i].path())
+	}
+	m.list(2, thriftStruct, schema)
+	m.begin()
+	m.str(4, "schema")
+	m.i32(5, int32(len(cols)))
+	m.end()
+	for i := range cols {
+		c := &cols[i]
+		if c.Floats != nil {
+			m.begin()
+			m.i32(3, parquetRequired)
+			m.str(4, c.Name)
+			m.i32(5, 1)
+			m.i32(6, parquetList)
+			m.end()
+			m.begin()
+			m.i32(3, parquetRepeated)
+			m.str(4, "list")
+			m.i32(5, 1)
+			m.end()
+			m.begin()
+			m.i32(1, parquetFloat)
+			m.i32(3, parquetRequired)
+			m.str(4, "element")
+			m.end()
+			continue
+		}
+		m.begin()
+		m.i32(1, c.physicalType())
+		m.i32(3, parquetRequired)
+		m.str(4, c.Name)
+		if c.Strings != nil {
+			m.i32(6, parquetUTF8)
+		}
+		m.end()
+	}
+	m.i64(3, int64(rows))
+	m.list(4, thriftStruct, 1)
+	m.begin() // RowGroup
+	m.list(1, thriftStruct, len(cols))
+	var total int64
+	for i := range cols {
+		c, ch := &cols[i], chunks[i]
+		total += ch.size
+		m.begin() // ColumnChunk
+		m.i64(2, ch.offset)
+		m.structField(3) // ColumnMetaData
+		m.i32(1, c.physicalType())
+		if c.Floats != nil {
+			m.list(2, thriftI32, 2)
+			m.varint(parquetPlain)
+			m.varint(parquetRLE)
+		} else {
+			m.list(2, thriftI32, 1)
+			m.varint(parquetPlain)
+		}
+		path := c.path()
+		m.list(3, thriftBinary, len(path))
+		for _, p := range path {
+			m.bytes(p)
+		}
+		m.i32(4, 0) // UNCOMPRESSED
+		m.i64(5, int64(ch.values))
+		m.i64(6, ch.size)
+		m.i64(7, ch.size)
+		m.i64(9, ch.offset)
+		m.end()
+		m.end()
+	}
+	m.i64(2, total)
+	m.i64(3, int64(rows))
+	m.end()
+	m.str(6, "graindl "+version)
+	m.end()
+
+	out.Write(m.b)
+	binary.Write(&out, binary.LittleEndian, uint32(len(m.b)))
+	out.WriteString("PAR1")
+	return out.Bytes(), nil
+}
+
+// thriftWriter encodes Thrift compact protocol structs.
+type thriftWriter struct {
+	b    []byte
+	last []int16 // last field ID written, per open struct
+}
+
+// begin opens a struct: the top-level one, or a list element.
+func (t *thriftWriter) begin() { t.last = append(t.last, 0) }
+
+// end closes the innermost struct.
+func (t *thriftWriter) end() {
+	t.b = append(t.b, 0)
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	top := &t.last[len(t.last)-1]
+	if d := id - *top; d > 0 && d <= 15 {
+		t.b = append(t.b, byte(d)<<4|typ)
+	} else {
+		t.b = append(t.b, typ)
+		t.varint(int64(id))
+	}
+	*top = id
+}
+
+// varint appends a zigzag varint, the encoding of every Thrift integer.
+func (t *thriftWriter) varint(v int64) { t.b = binary.AppendVarint(t.b, v) }
+
+// bytes appends a length-prefixed string, as in a list element.
+func (t *thriftWriter) bytes(s string) {
+	t.b = binary.AppendUvarint(t.b, uint64(len(s)))
+	t.b = append(t.b, s...)
+}
+
+func (t *thriftWriter) i32(id int16, v int32)  { t.field(id, thriftI32); t.varint(int64(v)) }
+func (t *thriftWriter) i64(id int16, v int64)  { t.field(id, thriftI64); t.varint(v) }
+func (t *thriftWriter) str(id int16, s string) { t.field(id, thriftBinary); t.bytes(s) }
+
+// structField opens a struct-valued field; close it with end.
+func (t *thriftWriter) structField(id int16) {
+	t.field(id, thriftStruct)
+	t.begin()
+}
+
+// list writes a list field header; the n elements follow.
+func (t *thriftWriter) list(id int16, elem byte, n int) {
+	t.field(id, thriftList)
+	if n < 15 {
+		t.b = append(t.b, byte(n)<<4|elem)
+		return
+	}
+	t.b = append(t.b, 0xf0|elem)
+	t.b = binary.AppendUvarint(t.b, uint64(n))
+}
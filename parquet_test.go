@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes Thrift compact protocol structs into field ID → value
+// maps, enough to check the files encodeParquet writes.
+type thriftReader struct {
+	t *testing.T
+	b []byte
+}
+
+func (r *thriftReader) byte() byte {
+	if len(r.b) == 0 {
+		r.t.Fatal("thrift: unexpected end of data")
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.t.Fatal("thrift: bad varint")
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.t.Fatal("thrift: bad uvarint")
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := r.uvarint()
+		s := string(r.b[:n])
+		r.b = r.b[n:]
+		return s
+	case thriftList:
+		h := r.byte()
+		n, elem := uint64(h>>4), h&0x0f
+		if n == 15 {
+			n = r.uvarint()
+		}
+		list := make([]any, n)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case thriftStruct:
+		return r.fields()
+	}
+	r.t.Fatalf("thrift: unexpected type %d", typ)
+	return nil
+}
+
+func (r *thriftReader) fields() map[int16]any {
+	m := map[int16]any{}
+	var id int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return m
+		}
+		if d := int16(h >> 4); d != 0 {
+			id += d
+		} else {
+			id = int16(r.varint())
+		}
+		m[id] = r.value(h & 0x0f)
+	}
+}
+
+func TestEncodeParquet(t *testing.T) {
+	cols := []parquetColumn{
+		{Name: "id", Strings: []string{"a", "bé", ""}},
+		{Name: "start", Doubles: []float64{1.5, -1, 3}},
+		{Name: "vec", Floats: [][]float32{{1, 2}, {3}, {4, 5, 6}}},
+	}
+	data, err := encodeParquet(cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatal("missing PAR1 magic")
+	}
+	n := binary.LittleEndian.Uint32(data[len(data)-8:])
+	footer := &thriftReader{t: t, b: data[len(data)-8-int(n) : len(data)-8]}
+	meta := footer.fields()
+	if len(footer.b) != 0 {
+		t.Errorf("%d byte(s) after the footer struct", len(footer.b))
+	}
+	if meta[3] != int64(3) {
+		t.Errorf("num_rows = %v", meta[3])
+	}
+
+	var names []string
+	for _, e := range meta[2].([]any) {
+		names = append(names, e.(map[int16]any)[4].(string))
+	}
+	if want := []string{"schema", "id", "start", "vec", "list", "element"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("schema = %v, want %v", names, want)
+	}
+
+	rg := meta[4].([]any)[0].(map[int16]any)
+	chunks := rg[1].([]any)
+	if len(chunks) != 3 {
+		t.Fatalf("%d column chunks", len(chunks))
+	}
+	// page returns column i's data page body after checking its header.
+	page := func(i int, wantValues int64) []byte {
+		cm := chunks[i].(map[int16]any)[3].(map[int16]any)
+		if cm[5] != wantValues {
+			t.Errorf("column %d: num_values = %v, want %d", i, cm[5], wantValues)
+		}
+		r := &thriftReader{t: t, b: data[cm[9].(int64):]}
+		h := r.fields()
+		dp := h[5].(map[int16]any)
+		if dp[1] != wantValues {
+			t.Errorf("column %d: page num_values = %v", i, dp[1])
+		}
+		size := int(h[3].(int64))
+		if int64(len(data)-len(r.b)+size)-cm[9].(int64) != cm[7] {
+			t.Errorf("column %d: chunk size %v doesn't match the page", i, cm[7])
+		}
+		return r.b[:size]
+	}
+
+	body := page(0, 3)
+	var strs []string
+	for len(body) > 0 {
+		l := binary.LittleEndian.Uint32(body)
+		strs = append(strs, string(body[4:4+l]))
+		body = body[4+l:]
+	}
+	if !reflect.DeepEqual(strs, cols[0].Strings) {
+		t.Errorf("strings = %q", strs)
+	}
+
+	body = page(1, 3)
+	for i, want := range cols[1].Doubles {
+		if got := math.Float64frombits(binary.LittleEndian.Uint64(body[8*i:])); got != want {
+			t.Errorf("double %d = %v, want %v", i, got, want)
+		}
+	}
+
+	body = page(2, 6)
+	var levels [][]byte
+	for range 2 {
+		l := binary.LittleEndian.Uint32(body)
+		levels = append(levels, body[4:4+l])
+		body = body[4+l:]
+	}
+	// Runs of (count<<1, value): rows of 2, 1, and 3 elements.
+	if want := []byte{2, 0, 2, 1, 2, 0, 2, 0, 4, 1}; !bytes.Equal(levels[0], want) {
+		t.Errorf("repetition levels = %v, want %v", levels[0], want)
+	}
+	if want := []byte{12, 1}; !bytes.Equal(levels[1], want) {
+		t.Errorf("definition levels = %v, want %v", levels[1], want)
+	}
+	for i := range 6 {
+		if got := math.Float32frombits(binary.LittleEndian.Uint32(body[4*i:])); got != float32(i+1) {
+			t.Errorf("float %d = %v", i, got)
+		}
+	}
+
+	if _, err := encodeParquet([]parquetColumn{{Name: "a", Strings: []string{"x"}}, {Name: "b", Doubles: []float64{}}}); err == nil {
+		t.Error("mismatched row counts accepted")
+	}
+}
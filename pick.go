@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	xterm "github.com/charmbracelet/x/term"
+)
+
+// ── Meeting Picker ──────────────────────────────────────────────────────────
+//
+// `graindl pick [flags]` discovers meetings as usual, then lists them in a
+// full-screen picker: type to fuzzy-filter by title, date or ID, tab to
+// select, enter to export the selection through the normal pipeline with
+// the given flags. Meetings already in the archive are marked.
+
+// errPickCancelled is returned when the picker is closed without exporting.
+var errPickCancelled = errors.New("pick cancelled")
+
+type pickKeyMap struct {
+	Up        key.Binding
+	Down      key.Binding
+	Toggle    key.Binding
+	SelectAll key.Binding
+	Confirm   key.Binding
+	Quit      key.Binding
+}
+
+func (k pickKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Toggle, k.SelectAll, k.Confirm, k.Quit}
+}
+
+func (k pickKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// Letters go to the filter, so navigation uses arrows and ctrl keys only.
+var defaultPickKeys = pickKeyMap{
+	Up:        key.NewBinding(key.WithKeys("up", "ctrl+p"), key.WithHelp("↑", "up")),
+	Down:      key.NewBinding(key.WithKeys("down", "ctrl+n"), key.WithHelp("↓", "down")),
+	Toggle:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "select")),
+	SelectAll: key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "select shown")),
+	Confirm:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "export")),
+	Quit:      key.NewBinding(key.WithKeys("esc", "ctrl+c"), key.WithHelp("esc", "cancel")),
+}
+
+// pickItem is one meeting in the picker.
+type pickItem struct {
+	ref      MeetingRef
+	exported bool   // already in the archive
+	haystack string // what the filter matches against
+}
+
+type pickModel struct {
+	items    []pickItem
+	query    string
+	shown    []int // indices into items matching query, best first
+	cursor   int   // position in shown
+	offset   int   // first visible row of shown
+	selected map[int]bool
+
+	keys   pickKeyMap
+	width  int
+	height int
+
+	confirmed bool
+}
+
+func newPickModel(refs []MeetingRef, exported map[string]bool) pickModel {
+	m := pickModel{selected: make(map[int]bool), keys: defaultPickKeys}
+	for _, r := range refs {
+		m.items = append(m.items, pickItem{
+			ref:      r,
+			exported: exported[r.ID],
+			haystack: dateFromISO(r.Date) + " " + r.Title + " " + r.ID,
+		})
+	}
+	m.filter()
+	return m
+}
+
+func (m pickModel) Init() tea.Cmd { return nil }
+
+func (m pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Confirm):
+			if len(m.selected) == 0 && len(m.shown) > 0 {
+				m.selected[m.shown[m.cursor]] = true
+			}
+			if len(m.selected) > 0 {
+				m.confirmed = true
+				return m, tea.Quit
+			}
+		case key.Matches(msg, m.keys.Up):
+			m.move(-1)
+		case key.Matches(msg, m.keys.Down):
+			m.move(1)
+		case key.Matches(msg, m.keys.Toggle):
+			if len(m.shown) > 0 {
+				i := m.shown[m.cursor]
+				if m.selected[i] {
+					delete(m.selected, i)
+				} else {
+					m.selected[i] = true
+				}
+				m.move(1)
+			}
+		case key.Matches(msg, m.keys.SelectAll):
+			m.toggleShown()
+		case msg.Type == tea.KeyBackspace:
+			if r := []rune(m.query); len(r) > 0 {
+				m.query = string(r[:len(r)-1])
+				m.filter()
+			}
+		case msg.Type == tea.KeyCtrlU:
+			m.query = ""
+			m.filter()
+		case msg.Type == tea.KeySpace:
+			m.query += " "
+			m.filter()
+		case msg.Type == tea.KeyRunes:
+			m.query += string(msg.Runes)
+			m.filter()
+		}
+	}
+	return m, nil
+}
+
+// move shifts the cursor by d rows, clamped to the shown list.
+func (m *pickModel) move(d int) {
+	m.cursor = max(0, min(m.cursor+d, len(m.shown)-1))
+	m.scroll()
+}
+
+// toggleShown selects every shown meeting, or clears them if all are
+// already selected.
+func (m *pickModel) toggleShown() {
+	all := true
+	for _, i := range m.shown {
+		all = all && m.selected[i]
+	}
+	for _, i := range m.shown {
+		if all {
+			delete(m.selected, i)
+		} else {
+			m.selected[i] = true
+		}
+	}
+}
+
+// filter recomputes the shown list for the query. Space-separated terms
+// must all match; results are ordered by score, then discovery order.
+func (m *pickModel) filter() {
+	terms := strings.Fields(m.query)
+	type scored struct{ index, score int }
+	var hits []scored
+	for i, it := range m.items {
+		total, ok := 0, true
+		for _, t := range terms {
+			s, match := fuzzyScore(t, it.haystack)
+			if !match {
+				ok = false
+				break
+			}
+			total += s
+		}
+		if ok {
+			hits = append(hits, scored{i, total})
+		}
+	}
+	sort.SliceStable(hits, func(a, b int) bool { return hits[a].score > hits[b].score })
+	m.shown = m.shown[:0]
+	for _, h := range hits {
+		m.shown = append(m.shown, h.index)
+	}
+	m.cursor, m.offset = 0, 0
+}
+
+// fuzzyScore reports whether pattern's characters appear in order in s
+// (case-insensitively) and scores the match: consecutive characters and
+// characters at word starts score higher.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return 0, true
+	}
+	score, pi, prevMatch := 0, 0, -2
+	runes := []rune(strings.ToLower(s))
+	for i, r := range runes {
+		if pi == len(p) {
+			break
+		}
+		if r != p[pi] {
+			continue
+		}
+		score++
+		if i == prevMatch+1 {
+			score += 3
+		}
+		if i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1]) {
+			score += 2
+		}
+		prevMatch = i
+		pi++
+	}
+	return score, pi == len(p)
+}
+
+// listHeight is the number of meeting rows that fit on screen.
+func (m *pickModel) listHeight() int {
+	return max(1, m.height-4) // title, query, blank, help
+}
+
+// scroll keeps the cursor row visible.
+func (m *pickModel) scroll() {
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+func (m pickModel) View() string {
+	if m.width == 0 {
+		return "Loading…"
+	}
+	var b strings.Builder
+	b.WriteString(tuiTitleStyle.Render("🌾 graindl pick"))
+	b.WriteString(tuiDim.Render(fmt.Sprintf("  %d of %d shown · %d selected", len(m.shown), len(m.items), len(m.selected))))
+	b.WriteByte('\n')
+	b.WriteString(tuiActive.Render("> ") + m.query + tuiDim.Render("█"))
+	b.WriteByte('\n')
+
+	h := m.listHeight()
+	end := min(m.offset+h, len(m.shown))
+	rows := 0
+	for pos := m.offset; pos < end; pos++ {
+		b.WriteString(m.renderRow(pos))
+		b.WriteByte('\n')
+		rows++
+	}
+	if len(m.shown) == 0 {
+		b.WriteString(tuiDim.Render("  No meetings match"))
+		b.WriteByte('\n')
+		rows++
+	}
+	b.WriteString(strings.Repeat("\n", max(0, h-rows)+1))
+
+	help := m.keys.ShortHelp()
+	parts := make([]string, len(help))
+	for i, k := range help {
+		parts[i] = k.Help().Key + " " + k.Help().Desc
+	}
+	b.WriteString(tuiHelpStyle.Render(strings.Join(parts, " • ")))
+	return b.String()
+}
+
+// renderRow renders shown[pos]: cursor, checkbox, date, title, and an
+// "exported" marker for meetings already in the archive.
+func (m pickModel) renderRow(pos int) string {
+	i := m.shown[pos]
+	it := m.items[i]
+	cursor, box := "  ", "[ ]"
+	if pos == m.cursor {
+		cursor = tuiActive.Render("› ")
+	}
+	if m.selected[i] {
+		box = tuiOK.Render("[x]")
+	}
+	suffix := ""
+	if it.exported {
+		suffix = " · exported"
+	}
+	date := dateFromISO(it.ref.Date)
+	maxTitle := max(1, m.width-lipgloss.Width(cursor+box+date+suffix)-3)
+	title := coalesce(it.ref.Title, it.ref.ID)
+	if runes := []rune(title); len(runes) > maxTitle {
+		title = string(runes[:max(0, maxTitle-1)]) + "…"
+	}
+	row := fmt.Sprintf("%s%s %s %s", cursor, box, tuiDim.Render(date), title)
+	if pos == m.cursor {
+		row = fmt.Sprintf("%s%s %s %s", cursor, box, tuiDim.Render(date), tuiActive.Render(title))
+	}
+	return row + tuiDim.Render(suffix)
+}
+
+// picked returns the selected meetings in discovery order.
+func (m pickModel) picked() []MeetingRef {
+	var refs []MeetingRef
+	for i, it := range m.items {
+		if m.selected[i] {
+			refs = append(refs, it.ref)
+		}
+	}
+	return refs
+}
+
+// pickMeetings shows the picker for refs and returns the selection.
+func (e *Exporter) pickMeetings(ctx context.Context, refs []MeetingRef) ([]MeetingRef, error) {
+	if !xterm.IsTerminal(os.Stdin.Fd()) {
+		return nil, errors.New("graindl pick needs an interactive terminal")
+	}
+	exported := map[string]bool{}
+	if entries, err := scanArchive(e.cfg.dataDir()); err == nil {
+		for _, a := range entries {
+			exported[a.Meta.ID] = true
+		}
+	}
+	final, err := tea.NewProgram(newPickModel(refs, exported), tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if err != nil {
+		return nil, fmt.Errorf("picker: %w", err)
+	}
+	m := final.(pickModel)
+	if !m.confirmed {
+		return nil, errPickCancelled
+	}
+	return m.picked(), nil
+}
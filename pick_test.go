@@ -0,0 +1,125 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pickKeys(m pickModel, keys ...tea.KeyMsg) pickModel {
+	for _, k := range keys {
+		next, _ := m.Update(k)
+		m = next.(pickModel)
+	}
+	return m
+}
+
+func typed(s string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range s {
+		if r == ' ' {
+			keys = append(keys, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+			continue
+		}
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return keys
+}
+
+var pickRefs = []MeetingRef{
+	{ID: "a1", Title: "Weekly Sync", Date: "2025-06-02T10:00:00Z"},
+	{ID: "b2", Title: "Customer Call: Acme", Date: "2025-06-01T15:00:00Z"},
+	{ID: "c3", Title: "Sprint Planning", Date: "2025-05-30T09:00:00Z"},
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("wsy", "Weekly Sync"); !ok {
+		t.Error("subsequence should match")
+	}
+	if _, ok := fuzzyScore("syw", "Weekly Sync"); ok {
+		t.Error("out-of-order characters should not match")
+	}
+	prefix, _ := fuzzyScore("sync", "Weekly Sync")
+	scattered, _ := fuzzyScore("sync", "Sprint Planning y n c")
+	if prefix <= scattered {
+		t.Errorf("contiguous word match %d should outscore scattered %d", prefix, scattered)
+	}
+}
+
+func TestPickModelFilterAndSelect(t *testing.T) {
+	m := newPickModel(pickRefs, map[string]bool{"a1": true})
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}, tea.KeyMsg{Type: tea.KeyBackspace})
+	if len(m.shown) != 3 {
+		t.Fatalf("empty query shows %d, want 3", len(m.shown))
+	}
+	if !m.items[0].exported || m.items[1].exported {
+		t.Error("exported marker not applied")
+	}
+
+	// Multi-term query: both terms must match.
+	m = pickKeys(m, typed("call acme")...)
+	if len(m.shown) != 1 || m.items[m.shown[0]].ref.ID != "b2" {
+		t.Fatalf("shown = %v", m.shown)
+	}
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyCtrlU})
+
+	// Dates and IDs are searchable too.
+	m = pickKeys(m, typed("2025-05")...)
+	if len(m.shown) != 1 || m.items[m.shown[0]].ref.ID != "c3" {
+		t.Fatalf("date query shown = %v", m.shown)
+	}
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.confirmed {
+		t.Fatal("enter should confirm")
+	}
+	got := m.picked()
+	if len(got) != 2 || got[0].ID != "b2" || got[1].ID != "c3" {
+		t.Errorf("picked = %+v, want b2, c3 in discovery order", got)
+	}
+}
+
+func TestPickModelEnterWithoutSelection(t *testing.T) {
+	m := newPickModel(pickRefs, nil)
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.picked(); !m.confirmed || len(got) != 1 || got[0].ID != "b2" {
+		t.Errorf("enter should pick the meeting under the cursor, got %+v", got)
+	}
+
+	m = newPickModel(pickRefs, nil)
+	m = pickKeys(m, typed("zzz")...)
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.confirmed {
+		t.Error("enter with nothing shown or selected should not confirm")
+	}
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.confirmed || len(m.picked()) != 0 {
+		t.Error("esc should cancel")
+	}
+}
+
+func TestPickModelSelectAllShown(t *testing.T) {
+	m := newPickModel(pickRefs, nil)
+	m = pickKeys(m, typed("sync")...)
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyCtrlA})
+	if len(m.selected) != len(m.shown) {
+		t.Fatalf("selected %d of %d shown", len(m.selected), len(m.shown))
+	}
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyCtrlA})
+	if len(m.selected) != 0 {
+		t.Error("ctrl+a again should clear the shown selection")
+	}
+}
+
+func TestPickModelScroll(t *testing.T) {
+	m := newPickModel(pickRefs, nil)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 6}) // two rows
+	m = next.(pickModel)
+	m = pickKeys(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown})
+	if m.cursor != 2 || m.offset != 1 {
+		t.Errorf("cursor/offset = %d/%d, want 2/1", m.cursor, m.offset)
+	}
+	if lines := strings.Count(m.View(), "\n") + 1; lines != 6 {
+		t.Errorf("view is %d lines, want the window height 6", lines)
+	}
+}
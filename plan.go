@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	xterm "github.com/charmbracelet/x/term"
+)
+
+// ── Backfill Plan ───────────────────────────────────────────────────────────
+//
+// A first export of a very large archive can mean tens of thousands of page
+// loads. `graindl plan --max-rate 100meetings/day` discovers the archive,
+// takes the meetings not exported yet, and spreads them over as many days
+// (or hours, or weeks) as the rate needs. The schedule is printed and, after
+// confirmation on a terminal, saved to planFile. Every later export run —
+// usually `graindl --watch` — then works through it: a planned meeting is
+// exported only once its slot has come, and no more than the rate are tried
+// in any one window, so a machine that was off for a week does not catch up
+// in a burst. Meetings recorded after the plan was made are not part of it
+// and export as usual. Once every planned meeting is exported the plan file
+// is removed. Running `graindl plan` again replaces the plan.
+
+// planFile holds the backfill schedule. Hidden, like the other state files.
+const planFile = ".graindl-plan.json"
+
+var maxRateRe = regexp.MustCompile(`^(\d+)\s*(?:meetings?)?\s*/\s*(hour|day|week|h|d|w)$`)
+
+// BackfillPlan is the persisted schedule.
+type BackfillPlan struct {
+	CreatedAt time.Time    `json:"created_at"`
+	MaxRate   string       `json:"max_rate"` // as given, e.g. "100meetings/day"
+	Rate      int          `json:"rate"`     // meetings per window
+	Window    string       `json:"window"`   // Go duration, e.g. "24h0m0s"
+	Meetings  []*PlanEntry `json:"meetings"` // in export order
+}
+
+// PlanEntry is one planned meeting.
+type PlanEntry struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title,omitempty"`
+	Date      string     `json:"date,omitempty"`
+	NotBefore time.Time  `json:"not_before"`
+	Attempts  int        `json:"attempts,omitempty"`
+	LastTried *time.Time `json:"last_tried,omitempty"`
+	DoneAt    *time.Time `json:"done_at,omitempty"`
+}
+
+// parseMaxRate parses "<n>[meetings]/<hour|day|week>" into a count per
+// window.
+func parseMaxRate(s string) (int, time.Duration, error) {
+	m := maxRateRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid --max-rate %q (use e.g. 100meetings/day, 10/hour, or 500/week)", s)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return 0, 0, fmt.Errorf("invalid --max-rate %q: the count must be positive", s)
+	}
+	window := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[m[2][0]]
+	return n, window, nil
+}
+
+// newBackfillPlan schedules refs, in order, rate per window from now.
+func newBackfillPlan(refs []MeetingRef, rate int, window time.Duration, maxRate string, now time.Time) *BackfillPlan {
+	now = now.UTC().Truncate(time.Second)
+	p := &BackfillPlan{CreatedAt: now, MaxRate: maxRate, Rate: rate, Window: window.String()}
+	for i, ref := range refs {
+		p.Meetings = append(p.Meetings, &PlanEntry{
+			ID:        ref.ID,
+			Title:     ref.Title,
+			Date:      dateFromISO(ref.Date),
+			NotBefore: now.Add(time.Duration(i/rate) * window),
+		})
+	}
+	return p
+}
+
+// window returns the plan's rate window, a day if it is unreadable.
+func (p *BackfillPlan) window() time.Duration {
+	if d, err := time.ParseDuration(p.Window); err == nil && d > 0 {
+		return d
+	}
+	return 24 * time.Hour
+}
+
+// admit filters refs for this run: planned meetings whose slot has come,
+// up to what the rate leaves in the current window, and every meeting the
+// plan doesn't cover. Planned meetings already exported are marked done.
+func (p *BackfillPlan) admit(refs []MeetingRef, exported func(MeetingRef) bool, now time.Time) []MeetingRef {
+	byID := make(map[string]*PlanEntry, len(p.Meetings))
+	budget := p.Rate
+	since := now.Add(-p.window())
+	for _, m := range p.Meetings {
+		byID[m.ID] = m
+		if m.LastTried != nil && m.LastTried.After(since) {
+			budget--
+		}
+	}
+	var out []MeetingRef
+	deferred := 0
+	for _, ref := range refs {
+		m := byID[ref.ID]
+		switch {
+		case m == nil:
+			out = append(out, ref)
+		case m.DoneAt != nil:
+		case exported(ref):
+			t := now.UTC()
+			m.DoneAt = &t
+		case now.Before(m.NotBefore) || budget <= 0:
+			deferred++
+		default:
+			budget--
+			t := now.UTC()
+			m.LastTried = &t
+			m.Attempts++
+			out = append(out, ref)
+		}
+	}
+	if deferred > 0 {
+		slog.Info("Backfill plan: deferring meetings to later slots", "this_run", len(out), "deferred", deferred, "rate", p.MaxRate)
+	}
+	return out
+}
+
+// settle marks planned meetings among results that finished as done.
+func (p *BackfillPlan) settle(results []*ExportResult, now time.Time) {
+	byID := make(map[string]*PlanEntry, len(p.Meetings))
+	for _, m := range p.Meetings {
+		byID[m.ID] = m
+	}
+	for _, r := range results {
+		if r == nil || byID[r.ID] == nil || r.Status != "ok" && r.Status != "hls_pending" {
+			continue
+		}
+		t := now.UTC()
+		byID[r.ID].DoneAt = &t
+	}
+}
+
+// remaining counts planned meetings not yet exported.
+func (p *BackfillPlan) remaining() int {
+	n := 0
+	for _, m := range p.Meetings {
+		if m.DoneAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// loadBackfillPlan reads the plan in outputDir, or returns nil when there
+// is none.
+func loadBackfillPlan(outputDir string) (*BackfillPlan, error) {
+	var p *BackfillPlan
+	err := readStateFile(filepath.Join(outputDir, planFile), func(data []byte) error {
+		p = &BackfillPlan{}
+		return json.Unmarshal(data, p)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", planFile, err)
+	}
+	if p.Rate <= 0 {
+		return nil, fmt.Errorf("read %s: rate must be positive", planFile)
+	}
+	return p, nil
+}
+
+// saveBackfillPlan writes the plan, or removes it once every meeting in it
+// is exported.
+func saveBackfillPlan(outputDir string, p *BackfillPlan) error {
+	path := filepath.Join(outputDir, planFile)
+	if p.remaining() == 0 {
+		slog.Info("Backfill plan complete", "meetings", len(p.Meetings))
+		_ = os.Remove(path + stateBackupSuffix)
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStateFile(path, data)
+}
+
+// refExported reports whether ref's metadata is already in the archive.
+func (e *Exporter) refExported(ref MeetingRef) bool {
+	dateDir := e.meetingDir(ref, dateFromISO(coalesce(ref.Date, time.Now().Format("2006-01-02"))))
+	return artifactExists(e.storage, filepath.Join(dateDir, sanitize(ref.ID))+".json")
+}
+
+// runPlan is `graindl plan`: it schedules the discovered meetings not yet
+// exported and saves the plan.
+func (e *Exporter) runPlan(ctx context.Context) error {
+	meetings, err := e.discover(ctx)
+	if err != nil {
+		return fmt.Errorf("discover: %w", err)
+	}
+	meetings = e.ignoreMeetings(meetings)
+	if e.cfg.MaxMeetings > 0 && len(meetings) > e.cfg.MaxMeetings {
+		meetings = meetings[:e.cfg.MaxMeetings]
+	}
+	var backlog []MeetingRef
+	for _, ref := range meetings {
+		if !e.refExported(ref) {
+			backlog = append(backlog, ref)
+		}
+	}
+	if len(backlog) == 0 {
+		slog.Info("Nothing to plan: every discovered meeting is exported", "discovered", len(meetings))
+		return nil
+	}
+
+	plan := newBackfillPlan(backlog, e.cfg.PlanRate, e.cfg.PlanWindow, e.cfg.MaxRate, time.Now())
+	printBackfillPlan(os.Stdout, plan, len(meetings))
+	if e.cfg.DryRun {
+		return nil
+	}
+	if old, err := loadBackfillPlan(e.cfg.OutputDir); err == nil && old != nil {
+		fmt.Printf("This replaces the current plan (%d of %d meetings left).\n", old.remaining(), len(old.Meetings))
+	}
+	if xterm.IsTerminal(os.Stdin.Fd()) && !confirm(os.Stdin, os.Stdout, "Save this plan?") {
+		slog.Info("Plan not saved")
+		return nil
+	}
+	if err := saveBackfillPlan(e.cfg.OutputDir, plan); err != nil {
+		return fmt.Errorf("save plan: %w", err)
+	}
+	slog.Info("Backfill plan saved; graindl --watch works through it", "path", filepath.Join(e.cfg.OutputDir, planFile))
+	return nil
+}
+
+// printBackfillPlan writes the schedule, one row per rate window.
+func printBackfillPlan(w io.Writer, p *BackfillPlan, discovered int) {
+	slots := (len(p.Meetings) + p.Rate - 1) / p.Rate
+	last := p.Meetings[len(p.Meetings)-1].NotBefore
+	fmt.Fprintf(w, "Backfill plan: %d of %d discovered meetings to export at %s, in %d window(s) of %s; the last starts %s.\n\n",
+		len(p.Meetings), discovered, p.MaxRate, slots, p.window(), last.Local().Format("2006-01-02 15:04"))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tNOT BEFORE\tMEETINGS\tRECORDED")
+	for i := 0; i < len(p.Meetings); i += p.Rate {
+		batch := p.Meetings[i:min(i+p.Rate, len(p.Meetings))]
+		oldest, newest := batch[0].Date, batch[0].Date
+		for _, m := range batch {
+			if m.Date != "" && (oldest == "" || m.Date < oldest) {
+				oldest = m.Date
+			}
+			if m.Date > newest {
+				newest = m.Date
+			}
+		}
+		recorded := oldest
+		if newest != oldest {
+			recorded = oldest + " – " + newest
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", i/p.Rate+1, batch[0].NotBefore.Local().Format("2006-01-02 15:04"), len(batch), recorded)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
+
+// confirm asks a yes/no question, defaulting to yes.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [Y/n] ", question)
+	line, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "", "y", "yes":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMaxRate(t *testing.T) {
+	for in, want := range map[string]struct {
+		n      int
+		window time.Duration
+	}{
+		"100meetings/day":  {100, 24 * time.Hour},
+		"1 meeting / hour": {1, time.Hour},
+		"500/week":         {500, 7 * 24 * time.Hour},
+		"20/H":             {20, time.Hour},
+	} {
+		n, window, err := parseMaxRate(in)
+		if err != nil || n != want.n || window != want.window {
+			t.Errorf("parseMaxRate(%q) = %d, %v, %v", in, n, window, err)
+		}
+	}
+	for _, bad := range []string{"", "0/day", "100", "100/month", "-5/day", "fast"} {
+		if _, _, err := parseMaxRate(bad); err == nil {
+			t.Errorf("parseMaxRate(%q) accepted", bad)
+		}
+	}
+}
+
+func TestBackfillPlan(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	var refs []MeetingRef
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		refs = append(refs, MeetingRef{ID: id, Title: strings.ToUpper(id), Date: "2024-01-0" + string(rune('1'+len(refs)))})
+	}
+	p := newBackfillPlan(refs, 2, 24*time.Hour, "2/day", start)
+	if got := p.Meetings[4].NotBefore; !got.Equal(start.Add(48 * time.Hour)) {
+		t.Fatalf("fifth meeting not before %v", got)
+	}
+	var out bytes.Buffer
+	printBackfillPlan(&out, p, 7)
+	for _, want := range []string{"5 of 7 discovered meetings", "3 window(s)", "2024-01-01 – 2024-01-02", "2024-01-05\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printed plan lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	exported := map[string]bool{"b": true}
+	isExported := func(r MeetingRef) bool { return exported[r.ID] }
+	ids := func(refs []MeetingRef) string {
+		var s []string
+		for _, r := range refs {
+			s = append(s, r.ID)
+		}
+		return strings.Join(s, ",")
+	}
+
+	// Day one: a is due, b is already exported; c and d wait; "new" is
+	// not in the plan and always passes.
+	discovered := append([]MeetingRef{{ID: "new"}}, refs...)
+	if got := ids(p.admit(discovered, isExported, start)); got != "new,a" {
+		t.Fatalf("day one admitted %s", got)
+	}
+	if p.Meetings[1].DoneAt == nil {
+		t.Error("exported meeting not marked done")
+	}
+	p.settle([]*ExportResult{{ID: "new", Status: "ok"}, {ID: "a", Status: "error"}, nil}, start)
+	if p.remaining() != 4 {
+		t.Errorf("%d remaining after a failed export", p.remaining())
+	}
+
+	// A week later every slot has come, but only two meetings fit in the
+	// window: the retried a still counts until a day has passed.
+	later := start.Add(7 * 24 * time.Hour)
+	if got := ids(p.admit(discovered, isExported, later)); got != "new,a,c" {
+		t.Fatalf("catch-up admitted %s", got)
+	}
+	if got := ids(p.admit(discovered, isExported, later.Add(time.Hour))); got != "new" {
+		t.Fatalf("same window admitted %s", got)
+	}
+	if p.Meetings[0].Attempts != 2 {
+		t.Errorf("a tried %d times", p.Meetings[0].Attempts)
+	}
+
+	dir := t.TempDir()
+	p.settle([]*ExportResult{{ID: "a", Status: "ok"}, {ID: "c", Status: "hls_pending"}}, later)
+	if err := saveBackfillPlan(dir, p); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadBackfillPlan(dir)
+	if err != nil || loaded == nil || loaded.remaining() != 2 || loaded.window() != 24*time.Hour {
+		t.Fatalf("loaded plan %+v, %v", loaded, err)
+	}
+	loaded.settle([]*ExportResult{{ID: "d", Status: "ok"}, {ID: "e", Status: "ok"}}, later)
+	if err := saveBackfillPlan(dir, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, planFile)); !os.IsNotExist(err) {
+		t.Errorf("finished plan not removed: %v", err)
+	}
+	if p, err := loadBackfillPlan(dir); p != nil || err != nil {
+		t.Errorf("no plan: %+v, %v", p, err)
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	for in, want := range map[string]bool{"\n": true, "y\n": true, "YES\n": true, "n\n": false, "nope\n": false} {
+		var out bytes.Buffer
+		if got := confirm(strings.NewReader(in), &out, "Save?"); got != want || out.String() != "Save? [Y/n] " {
+			t.Errorf("confirm(%q) = %v, prompt %q", in, got, out.String())
+		}
+	}
+}
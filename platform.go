@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ── Recording Platform ──────────────────────────────────────────────────────
+//
+// Grain records calls on Zoom, Google Meet, Microsoft Teams, and Webex, and
+// takes uploads. The meeting page shows where a recording came from, and
+// its embedded app state usually carries the original meeting link and the
+// calendar event the call was scheduled from. Metadata "platform" records
+// all three, and formatted notes get platform, meeting_url, and
+// calendar_event_id frontmatter, so notes can be filtered by platform.
+//
+// Sources, most explicit first: the page's platform badge, platform-like
+// fields in the app state JSON, then the host of the meeting link. Join
+// links are stored without passcodes.
+
+// Platform names recorded in metadata.
+const (
+	platformZoom   = "zoom"
+	platformMeet   = "google_meet"
+	platformTeams  = "teams"
+	platformWebex  = "webex"
+	platformSlack  = "slack"
+	platformUpload = "upload"
+)
+
+// MeetingPlatform is where a recording was made.
+type MeetingPlatform struct {
+	Name            string `json:"name,omitempty"`              // zoom, google_meet, teams, webex, slack, upload
+	MeetingURL      string `json:"meeting_url,omitempty"`       // original join link, passcode removed
+	CalendarEventID string `json:"calendar_event_id,omitempty"` // the scheduling calendar event
+}
+
+// Normalized (lowercase, no '_' or '-') app state keys, by what they hold.
+var (
+	platformNameKeys = map[string]bool{
+		"platform": true, "meetingplatform": true, "conferenceplatform": true, "conferencetype": true,
+		"conferencingprovider": true, "videoprovider": true, "provider": true, "recordingsource": true,
+		"source": true, "integration": true,
+	}
+	platformURLKeys = map[string]bool{
+		"meetingurl": true, "meetinglink": true, "joinurl": true, "joinweburl": true, "joinlink": true,
+		"conferenceurl": true, "conferencelink": true, "onlinemeetingurl": true, "hangoutlink": true, "zoomurl": true,
+	}
+	platformCalendarKeys = map[string]bool{
+		"calendareventid": true, "calendarevent": true, "icaluid": true, "googleeventid": true, "outlookeventid": true,
+	}
+)
+
+// platformFromLabel maps a platform label or name ("Zoom", "Google Meet",
+// "microsoft_teams") to its metadata name, or "" when unrecognized.
+func platformFromLabel(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "":
+		return ""
+	case strings.Contains(s, "zoom"):
+		return platformZoom
+	case strings.Contains(s, "meet.google") || strings.Contains(s, "google") && strings.Contains(s, "meet") ||
+		s == "meet" || strings.Contains(s, "hangout"):
+		return platformMeet
+	case strings.Contains(s, "teams"):
+		return platformTeams
+	case strings.Contains(s, "webex"):
+		return platformWebex
+	case strings.Contains(s, "huddle") || s == "slack":
+		return platformSlack
+	case strings.Contains(s, "upload"):
+		return platformUpload
+	}
+	return ""
+}
+
+// platformFromURL returns the platform hosting a meeting link, or "".
+func platformFromURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	has := func(domain string) bool { return host == domain || strings.HasSuffix(host, "."+domain) }
+	switch {
+	case has("zoom.us") || has("zoomgov.com"):
+		return platformZoom
+	case host == "meet.google.com":
+		return platformMeet
+	case has("teams.microsoft.com") || has("teams.live.com"):
+		return platformTeams
+	case has("webex.com"):
+		return platformWebex
+	}
+	return ""
+}
+
+// stripJoinSecrets removes passcodes and tokens from a join link's query.
+func stripJoinSecrets(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return raw
+	}
+	q := u.Query()
+	for k := range q {
+		switch strings.ToLower(k) {
+		case "pwd", "passcode", "tk", "uname":
+			q.Del(k)
+		default:
+			if isSecretLogKey(k) {
+				q.Del(k)
+			}
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// newMeetingPlatform builds a MeetingPlatform from the page's platform
+// label, the meeting links on the page, and its app state JSON blobs. It
+// returns nil when none of them say anything.
+func newMeetingPlatform(label string, links, blobs []string) *MeetingPlatform {
+	p := &MeetingPlatform{Name: platformFromLabel(label)}
+	var stateName string
+	for _, blob := range blobs {
+		var v any
+		if json.Unmarshal([]byte(blob), &v) != nil {
+			continue
+		}
+		walkPlatformState(v, 0, p, &stateName)
+	}
+	if p.MeetingURL == "" {
+		for _, l := range links {
+			if platformFromURL(l) != "" {
+				p.MeetingURL = l
+				break
+			}
+		}
+	}
+	if p.MeetingURL != "" {
+		p.MeetingURL = stripJoinSecrets(p.MeetingURL)
+	}
+	p.Name = coalesce(p.Name, stateName, platformFromURL(p.MeetingURL))
+	if *p == (MeetingPlatform{}) {
+		return nil
+	}
+	return p
+}
+
+// walkPlatformState fills p's link and calendar event, and name, from the
+// first matching fields in v. Recursion is bounded; app state can be deep.
+func walkPlatformState(v any, depth int, p *MeetingPlatform, name *string) {
+	if depth > 12 {
+		return
+	}
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			walkPlatformState(e, depth+1, p, name)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			val := v[k]
+			key := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(k))
+			s, _ := val.(string)
+			switch {
+			case platformNameKeys[key] && *name == "":
+				*name = platformFromLabel(s)
+			case platformURLKeys[key] && p.MeetingURL == "" && platformFromURL(s) != "":
+				p.MeetingURL = s
+			case platformCalendarKeys[key] && p.CalendarEventID == "":
+				if obj, ok := val.(map[string]any); ok {
+					s, _ = obj["id"].(string)
+				}
+				p.CalendarEventID = strings.TrimSpace(s)
+			}
+			walkPlatformState(val, depth+1, p, name)
+		}
+	}
+}
+
+// platformJS collects the page's platform badge, meeting links, and app
+// state JSON (Next.js data, JSON script tags, and known state globals).
+const platformJS = `() => {
+	const blobs = [];
+	for (const s of document.querySelectorAll('script#__NEXT_DATA__, script[type="application/json"]')) {
+		const t = s.textContent;
+		if (t && t.length < 2000000) blobs.push(t);
+	}
+	for (const k of ['__APOLLO_STATE__', '__INITIAL_STATE__', '__NEXT_DATA__']) {
+		try {
+			const t = window[k] ? JSON.stringify(window[k]) : '';
+			if (t && t.length < 2000000) blobs.push(t);
+		} catch (e) {}
+	}
+	const links = [...document.querySelectorAll('a[href]')].map((a) => a.href)
+		.filter((h) => /zoom\.us|zoomgov\.com|meet\.google\.com|teams\.microsoft\.com|teams\.live\.com|webex\.com/i.test(h));
+	const el = document.querySelector('[data-testid="meeting-platform"], [data-testid="recording-source"], [data-testid="meeting-source"]');
+	let label = el ? (el.getAttribute('aria-label') || el.getAttribute('title') || el.textContent || '').trim() : '';
+	if (!label) {
+		const img = document.querySelector('img[alt*="Zoom" i], img[alt*="Google Meet" i], img[alt*="Teams" i], img[alt*="Webex" i]');
+		if (img) label = img.alt;
+	}
+	return {label: label, links: links, blobs: blobs};
+}`
+
+// scrapePlatform reads the current meeting page's recording platform.
+func (b *Browser) scrapePlatform() *MeetingPlatform {
+	res, err := b.page.Eval(platformJS)
+	if err != nil {
+		slog.Debug("Platform scrape failed", "error", err)
+		return nil
+	}
+	var links, blobs []string
+	for _, l := range res.Value.Get("links").Arr() {
+		links = append(links, l.Str())
+	}
+	for _, s := range res.Value.Get("blobs").Arr() {
+		blobs = append(blobs, s.Str())
+	}
+	return newMeetingPlatform(res.Value.Get("label").Str(), links, blobs)
+}
+
+// writePlatformFields adds the recording platform to note frontmatter.
+func writePlatformFields(b noteWriter, meta *Metadata) {
+	p := meta.Platform
+	if p == nil {
+		return
+	}
+	if p.Name != "" {
+		writeYAMLField(b, "platform", p.Name)
+	}
+	if p.MeetingURL != "" {
+		writeYAMLField(b, "meeting_url", p.MeetingURL)
+	}
+	if p.CalendarEventID != "" {
+		writeYAMLField(b, "calendar_event_id", p.CalendarEventID)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMeetingPlatform(t *testing.T) {
+	nextData := `{"props":{"pageProps":{"recording":{"id":"r1","source":"web",
+		"calendar_event":{"id":"evt_123","title":"Acme sync"},
+		"meeting":{"conference_platform":"ZOOM","join_url":"https://acme.zoom.us/j/8123?pwd=s3cret&from=addon"}}}}}`
+	for _, tc := range []struct {
+		name         string
+		label        string
+		links, blobs []string
+		want         *MeetingPlatform
+	}{
+		{name: "app state", blobs: []string{nextData},
+			want: &MeetingPlatform{Name: platformZoom, MeetingURL: "https://acme.zoom.us/j/8123?from=addon", CalendarEventID: "evt_123"}},
+		{name: "badge wins", label: "Recorded on Microsoft Teams", blobs: []string{nextData},
+			want: &MeetingPlatform{Name: platformTeams, MeetingURL: "https://acme.zoom.us/j/8123?from=addon", CalendarEventID: "evt_123"}},
+		{name: "link only", links: []string{"https://grain.com/app", "https://meet.google.com/abc-defg-hij"},
+			want: &MeetingPlatform{Name: platformMeet, MeetingURL: "https://meet.google.com/abc-defg-hij"}},
+		{name: "upload badge", label: "Uploaded", want: &MeetingPlatform{Name: platformUpload}},
+		{name: "calendar ID only", blobs: []string{`[{"iCalUID":"abc@google.com"}]`}, want: &MeetingPlatform{CalendarEventID: "abc@google.com"}},
+		{name: "nothing", label: "Share", links: []string{"https://example.com"}, blobs: []string{`{"source":"web"}`, `not json`}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newMeetingPlatform(tc.label, tc.links, tc.blobs)
+			if (got == nil) != (tc.want == nil) || got != nil && *got != *tc.want {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlatformFromURL(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://us02web.zoom.us/j/123":                          platformZoom,
+		"https://teams.microsoft.com/l/meetup-join/19%3ameeting": platformTeams,
+		"https://acme.webex.com/meet/dana":                       platformWebex,
+		"https://meet.google.com.evil.example/abc":               "",
+		"https://notzoom.us/j/1":                                 "",
+		"zoommtg://zoom.us/join?confno=1":                        "",
+	} {
+		if got := platformFromURL(raw); got != want {
+			t.Errorf("platformFromURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestPlatformFrontmatter(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Acme", Platform: &MeetingPlatform{Name: platformMeet, MeetingURL: "https://meet.google.com/abc-defg-hij", CalendarEventID: "evt_1"}}
+	for _, format := range []string{"obsidian", "notion", "minutes"} {
+		md := renderFormattedMarkdown(format, meta, "")
+		for _, want := range []string{"platform: google_meet\n", "meeting_url: ", "calendar_event_id: evt_1\n"} {
+			if !strings.Contains(md, want) {
+				t.Errorf("%s frontmatter missing %q:\n%s", format, want, md)
+			}
+		}
+	}
+	if md := renderFormattedMarkdown("obsidian", &Metadata{ID: "m2"}, ""); strings.Contains(md, "platform:") {
+		t.Error("platform written without one")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ── Progress / ETA ──────────────────────────────────────────────────────────
+//
+// Multi-hundred meeting backfills run for hours. Every --progress-interval
+// the exporter logs completed/total, the average time per meeting, and a
+// projected finish time, and mirrors the figures into the healthcheck file
+// so monitors can see a long run is alive and how far along it is.
+//
+// The average is an exponential moving average of the wall-clock time
+// between completed exports, so it tracks the current pace (throttling,
+// parallel workers, slow recordings) rather than the whole-run mean.
+// Skipped meetings count toward progress but not the average: they finish
+// instantly and would make the ETA for the remaining real work optimistic.
+
+// progressAlpha weights the newest sample in the moving average.
+const progressAlpha = 0.15
+
+// progressTracker accumulates completions for one run. It is safe for
+// concurrent use; a nil tracker ignores everything.
+type progressTracker struct {
+	mu         sync.Mutex
+	total      int
+	done       int
+	avg        float64 // EMA of seconds per exported meeting; 0 = no sample yet
+	last       time.Time
+	lastReport time.Time
+	every      time.Duration
+	now        func() time.Time
+}
+
+// progressSnapshot is a point-in-time view of a run's progress.
+type progressSnapshot struct {
+	Done, Total int
+	AvgSeconds  float64       // 0 until the first meeting is exported
+	ETA         time.Duration // 0 when unknown
+	Finish      time.Time     // zero when unknown
+}
+
+func newProgressTracker(total int, every time.Duration) *progressTracker {
+	t := &progressTracker{total: total, every: every, now: time.Now}
+	t.last = t.now()
+	t.lastReport = t.last
+	return t
+}
+
+// Complete records one finished meeting and reports whether a periodic
+// summary is due.
+func (p *progressTracker) Complete(skipped bool) bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.done++
+	if !skipped {
+		sample := now.Sub(p.last).Seconds()
+		if p.avg == 0 {
+			p.avg = sample
+		} else {
+			p.avg = progressAlpha*sample + (1-progressAlpha)*p.avg
+		}
+		p.last = now
+	}
+
+	if p.every <= 0 || p.done >= p.total || now.Sub(p.lastReport) < p.every {
+		return false
+	}
+	p.lastReport = now
+	return true
+}
+
+// Grow raises the total by n, for runs whose meetings are still being
+// found (a streamed --search).
+func (p *progressTracker) Grow(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.total += n
+	p.mu.Unlock()
+}
+
+// Snapshot returns the current progress and estimate.
+func (p *progressTracker) Snapshot() progressSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := progressSnapshot{Done: p.done, Total: p.total, AvgSeconds: p.avg}
+	if p.avg > 0 && p.done < p.total {
+		s.ETA = time.Duration(float64(p.total-p.done) * p.avg * float64(time.Second)).Round(time.Second)
+		s.Finish = p.now().Add(s.ETA)
+	}
+	return s
+}
+
+// String formats the summary log line.
+func (s progressSnapshot) String() string {
+	pct := 0.0
+	if s.Total > 0 {
+		pct = 100 * float64(s.Done) / float64(s.Total)
+	}
+	line := fmt.Sprintf("Progress: %d/%d (%.0f%%)", s.Done, s.Total, pct)
+	if s.Finish.IsZero() {
+		return line + ", ETA pending first export"
+	}
+	return line + fmt.Sprintf(", %.1fs/meeting, ETA %s (finishes ~%s)", s.AvgSeconds, s.ETA, s.Finish.Format("Mon 15:04"))
+}
+
+// healthLines renders the snapshot as key=value healthcheck lines.
+func (s progressSnapshot) healthLines() []string {
+	lines := []string{fmt.Sprintf("progress=%d/%d", s.Done, s.Total)}
+	if !s.Finish.IsZero() {
+		lines = append(lines,
+			"avg_seconds="+strconv.FormatFloat(s.AvgSeconds, 'f', 1, 64),
+			"eta="+s.Finish.UTC().Format(time.RFC3339))
+	}
+	return lines
+}
+
+// healthProgress renders the snapshot for the JSON healthcheck status.
+func (s progressSnapshot) healthProgress() *HealthProgress {
+	p := &HealthProgress{Done: s.Done, Total: s.Total}
+	if !s.Finish.IsZero() {
+		p.AvgSeconds = math.Round(s.AvgSeconds*10) / 10
+		p.ETA = s.Finish.UTC().Format(time.RFC3339)
+	}
+	return p
+}
+
+// recordProgress counts a finished meeting and, when a summary is due,
+// logs it and refreshes the healthcheck file.
+func (e *Exporter) recordProgress(r *ExportResult) {
+	if !e.progress.Complete(r.Status == "skipped") {
+		return
+	}
+	s := e.progress.Snapshot()
+	if !e.cfg.TUI {
+		slog.Info(s.String())
+	}
+	e.health.update(func(st *HealthStatus) { st.Progress = s.healthProgress() })
+	e.writeHealthcheck(s.healthLines()...)
+}
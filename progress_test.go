@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeClockTracker returns a tracker over total meetings whose clock is
+// advanced by the returned function.
+func newFakeClockTracker(total int, every time.Duration) (*progressTracker, func(time.Duration)) {
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	p := &progressTracker{total: total, every: every, now: func() time.Time { return now }}
+	p.last, p.lastReport = now, now
+	return p, func(d time.Duration) { now = now.Add(d) }
+}
+
+// ── progressTracker ─────────────────────────────────────────────────────────
+
+func TestProgressTrackerEMA(t *testing.T) {
+	p, advance := newFakeClockTracker(10, time.Hour)
+
+	advance(10 * time.Second)
+	p.Complete(false)
+	if s := p.Snapshot(); s.AvgSeconds != 10 || s.ETA != 90*time.Second {
+		t.Errorf("after first sample: avg=%v eta=%v, want 10s/90s", s.AvgSeconds, s.ETA)
+	}
+
+	advance(20 * time.Second)
+	p.Complete(false)
+	want := progressAlpha*20 + (1-progressAlpha)*10
+	s := p.Snapshot()
+	if math.Abs(s.AvgSeconds-want) > 1e-9 {
+		t.Errorf("avg = %v, want %v", s.AvgSeconds, want)
+	}
+	if s.Done != 2 || s.Total != 10 {
+		t.Errorf("done/total = %d/%d", s.Done, s.Total)
+	}
+	wantETA := time.Duration(8 * want * float64(time.Second)).Round(time.Second)
+	if s.ETA != wantETA {
+		t.Errorf("eta = %v, want %v", s.ETA, wantETA)
+	}
+	if !s.Finish.Equal(time.Date(2025, 3, 1, 9, 0, 30, 0, time.UTC).Add(wantETA)) {
+		t.Errorf("finish = %v", s.Finish)
+	}
+}
+
+func TestProgressTrackerSkippedDoesNotSkewAverage(t *testing.T) {
+	p, advance := newFakeClockTracker(5, time.Hour)
+	p.Complete(true) // instant skip
+	if s := p.Snapshot(); s.AvgSeconds != 0 || !s.Finish.IsZero() {
+		t.Errorf("skip-only snapshot has estimate: %+v", s)
+	}
+
+	advance(30 * time.Second)
+	p.Complete(false)
+	if s := p.Snapshot(); s.AvgSeconds != 30 || s.Done != 2 {
+		t.Errorf("avg=%v done=%d, want 30/2", s.AvgSeconds, s.Done)
+	}
+}
+
+func TestProgressTrackerReportCadence(t *testing.T) {
+	p, advance := newFakeClockTracker(100, time.Minute)
+
+	advance(20 * time.Second)
+	if p.Complete(false) {
+		t.Error("report before interval elapsed")
+	}
+	advance(45 * time.Second)
+	if !p.Complete(false) {
+		t.Error("no report after interval elapsed")
+	}
+	advance(10 * time.Second)
+	if p.Complete(false) {
+		t.Error("report again right after a report")
+	}
+
+	var off *progressTracker
+	if off.Complete(false) {
+		t.Error("nil tracker reported")
+	}
+	disabled, adv := newFakeClockTracker(100, 0)
+	adv(time.Hour)
+	if disabled.Complete(false) {
+		t.Error("interval 0 reported")
+	}
+}
+
+func TestProgressTrackerNoReportOnLast(t *testing.T) {
+	p, advance := newFakeClockTracker(1, time.Second)
+	advance(time.Minute)
+	if p.Complete(false) {
+		t.Error("summary for the final meeting (the Done line covers it)")
+	}
+}
+
+func TestProgressSnapshotFormat(t *testing.T) {
+	s := progressSnapshot{Done: 40, Total: 400, AvgSeconds: 12.34, ETA: 74 * time.Minute,
+		Finish: time.Date(2025, 3, 1, 14, 5, 0, 0, time.UTC)}
+	got := s.String()
+	for _, want := range []string{"40/400 (10%)", "12.3s/meeting", "ETA 1h14m0s", "Sat 14:05"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, missing %q", got, want)
+		}
+	}
+	if got := (progressSnapshot{Done: 3, Total: 400}).String(); !strings.Contains(got, "pending") {
+		t.Errorf("String() without estimate = %q", got)
+	}
+
+	lines := s.healthLines()
+	want := []string{"progress=40/400", "avg_seconds=12.3", "eta=2025-03-01T14:05:00Z"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("healthLines = %v, want %v", lines, want)
+	}
+}
+
+// ── Exporter integration ────────────────────────────────────────────────────
+
+func TestExporterProgressWritesHealthcheck(t *testing.T) {
+	dir := t.TempDir()
+	hc := filepath.Join(dir, "health")
+	cfg := &Config{OutputDir: dir, SkipVideo: true, HealthcheckFile: hc, TUI: true}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+
+	p, advance := newFakeClockTracker(3, time.Second)
+	e.progress = p
+	advance(5 * time.Second)
+	e.tally(&ExportResult{ID: "a", Status: "ok"})
+
+	data, err := os.ReadFile(hc)
+	if err != nil {
+		t.Fatalf("healthcheck not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || lines[1] != "progress=1/3" || lines[2] != "avg_seconds=5.0" || !strings.HasPrefix(lines[3], "eta=") {
+		t.Errorf("healthcheck = %q", data)
+	}
+	if _, err := time.Parse(time.RFC3339, lines[0]); err != nil {
+		t.Errorf("first line not a timestamp: %q", lines[0])
+	}
+	if e.manifest.OK != 1 {
+		t.Errorf("manifest OK = %d, want 1", e.manifest.OK)
+	}
+}
+
+func TestProgressTrackerGrow(t *testing.T) {
+	p, _ := newFakeClockTracker(0, time.Minute)
+	p.Grow(1)
+	p.Grow(2)
+	if s := p.Snapshot(); s.Total != 3 {
+		t.Errorf("total = %d, want 3", s.Total)
+	}
+	var nilTracker *progressTracker
+	nilTracker.Grow(1) // must not panic
+}
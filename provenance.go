@@ -0,0 +1,134 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ── Scrape Provenance ───────────────────────────────────────────────────────
+//
+// When a page selector stops matching, the scraped field silently falls
+// back to empty (or "Untitled", or today's date directory). Metadata
+// records, per field, where the value came from and how far it can be
+// trusted, and a scrape quality score (0–1) summarizes the core fields.
+// The score is also written to the manifest, so low-quality exports can be
+// found and, after a selector fix, re-exported with --min-quality.
+
+const (
+	sourceAPI      = "api"      // machine-readable data (e.g. a Grain export's JSON)
+	sourceScrape   = "scrape"   // read from the meeting page
+	sourceFallback = "fallback" // not found; a default or inferred value
+)
+
+const (
+	confidenceAPI      = 1.0
+	confidenceScrape   = 0.9 // scraped and parsed as expected
+	confidenceUnparsed = 0.5 // present but not in the expected shape (free-text date, unstructured transcript)
+)
+
+// lowQualityThreshold is the score below which an export is reported as
+// low quality.
+const lowQualityThreshold = 0.5
+
+// qualityWeights are the fields the scrape quality score averages over.
+// Tags, highlights and AI notes are recorded too, but many meetings
+// legitimately have none.
+var qualityWeights = map[string]float64{
+	"title":            2,
+	"date":             2,
+	"transcript":       3,
+	"duration_seconds": 1,
+	"participants":     1,
+}
+
+// FieldProvenance is where one metadata field's value came from.
+type FieldProvenance struct {
+	Source     string  `json:"source"`     // api, scrape, fallback
+	Confidence float64 `json:"confidence"` // 0–1
+}
+
+// assess records the provenance of meta's fields, assuming every value
+// found came from source, and computes the scrape quality. transcript is
+// the meeting's transcript text, which is not part of the metadata.
+func (m *Metadata) assess(source, transcript string) {
+	base := confidenceScrape
+	if source == sourceAPI {
+		base = confidenceAPI
+	}
+	m.Provenance = map[string]FieldProvenance{}
+	found := func(field string, ok, parsed bool) {
+		switch {
+		case !ok:
+			m.Provenance[field] = FieldProvenance{Source: sourceFallback}
+		case parsed:
+			m.Provenance[field] = FieldProvenance{Source: source, Confidence: base}
+		default:
+			m.Provenance[field] = FieldProvenance{Source: source, Confidence: confidenceUnparsed}
+		}
+	}
+	found("title", m.Title != "" && m.Title != "Untitled", true)
+	found("date", m.Date != "", validDate(dateFromISO(m.Date)))
+	found("duration_seconds", m.DurationSeconds != nil && m.DurationSeconds != "", durationSeconds(m.DurationSeconds) > 0)
+	found("participants", len(flattenStringSlice(m.Participants)) > 0, true)
+	found("tags", len(flattenStringSlice(m.Tags)) > 0, true)
+	found("transcript", strings.TrimSpace(transcript) != "", parseTranscript(transcript) != nil)
+	found("highlights", m.Highlights != nil, true)
+	found("ai_notes", m.AINotes != nil || m.Summary != "", true)
+	m.scoreQuality()
+}
+
+// setProvenance overrides one field's provenance and rescores.
+func (m *Metadata) setProvenance(field, source string, confidence float64) {
+	if m.Provenance == nil {
+		m.Provenance = map[string]FieldProvenance{}
+	}
+	m.Provenance[field] = FieldProvenance{Source: source, Confidence: confidence}
+	m.scoreQuality()
+}
+
+// scoreQuality sets ScrapeQuality to the weighted mean confidence of the
+// core fields, rounded to two decimals. Missing entries count as 0.
+func (m *Metadata) scoreQuality() {
+	var sum, total float64
+	for field, w := range qualityWeights {
+		sum += w * m.Provenance[field].Confidence
+		total += w
+	}
+	q := math.Round(sum/total*100) / 100
+	m.ScrapeQuality = &q
+}
+
+// fallbackFields lists the fields that were not found, sorted.
+func (m *Metadata) fallbackFields() []string {
+	var fields []string
+	for field, p := range m.Provenance {
+		if p.Source == sourceFallback {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// validDate reports whether s is a YYYY-MM-DD date.
+func validDate(s string) bool {
+	return len(s) == 10 && s[4] == '-' && s[7] == '-' && strings.Trim(s[:4]+s[5:7]+s[8:], "0123456789") == ""
+}
+
+// storedQuality returns the scrape quality of an exported meeting. Metadata
+// written before scores were recorded is assessed from its fields and
+// transcript file.
+func storedQuality(outputDir, metaRelPath string) (float64, error) {
+	meta, err := readArchiveMetadata(filepath.Join(outputDir, metaRelPath))
+	if err != nil {
+		return 0, err
+	}
+	if meta.ScrapeQuality != nil {
+		return *meta.ScrapeQuality, nil
+	}
+	transcript, _ := readArtifact(filepath.Join(outputDir, strings.TrimSuffix(metaRelPath, ".json")+".transcript.txt"))
+	meta.assess(sourceScrape, string(transcript))
+	return *meta.ScrapeQuality, nil
+}
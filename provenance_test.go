@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAssessComplete(t *testing.T) {
+	m := &Metadata{
+		Title:           "Weekly Sync",
+		Date:            "2025-03-04T15:00:00Z",
+		DurationSeconds: 1800.0,
+		Participants:    []any{"Alice"},
+	}
+	m.assess(sourceScrape, "[00:00:01] Alice: hello")
+
+	if m.ScrapeQuality == nil || *m.ScrapeQuality != 0.9 {
+		t.Fatalf("quality = %v, want 0.9", m.ScrapeQuality)
+	}
+	if p := m.Provenance["title"]; p.Source != sourceScrape || p.Confidence != confidenceScrape {
+		t.Errorf("title provenance = %+v", p)
+	}
+	if got := m.fallbackFields(); !reflect.DeepEqual(got, []string{"ai_notes", "highlights", "tags"}) {
+		t.Errorf("fallbacks = %v", got)
+	}
+}
+
+func TestAssessMissingAndUnparsed(t *testing.T) {
+	m := &Metadata{Title: "Untitled", Date: "last Tuesday", DurationSeconds: "about an hour"}
+	m.assess(sourceScrape, "free-form notes without speaker lines")
+
+	if p := m.Provenance["title"]; p.Source != sourceFallback || p.Confidence != 0 {
+		t.Errorf("title provenance = %+v", p)
+	}
+	for _, field := range []string{"date", "duration_seconds", "transcript"} {
+		if p := m.Provenance[field]; p.Source != sourceScrape || p.Confidence != confidenceUnparsed {
+			t.Errorf("%s provenance = %+v", field, p)
+		}
+	}
+	// (2*0 + 2*0.5 + 3*0.5 + 1*0.5 + 1*0) / 9
+	if *m.ScrapeQuality != 0.33 {
+		t.Errorf("quality = %v", *m.ScrapeQuality)
+	}
+}
+
+func TestSetProvenanceRescores(t *testing.T) {
+	m := &Metadata{Title: "T", Date: "2025-03-04", DurationSeconds: 60.0, Participants: []any{"A"}}
+	m.assess(sourceAPI, "[00:00:01] A: hi")
+	if *m.ScrapeQuality != 1 {
+		t.Fatalf("quality = %v, want 1", *m.ScrapeQuality)
+	}
+	m.setProvenance("title", sourceFallback, confidenceUnparsed)
+	if *m.ScrapeQuality != 0.89 {
+		t.Errorf("quality = %v, want 0.89", *m.ScrapeQuality)
+	}
+}
+
+func TestStoredQuality(t *testing.T) {
+	dir := t.TempDir()
+	day := filepath.Join(dir, "2025-03-04")
+	os.MkdirAll(day, 0o755)
+
+	q := 0.4
+	writeJSON(filepath.Join(day, "scored.json"), &Metadata{ID: "scored", ScrapeQuality: &q})
+	got, err := storedQuality(dir, "2025-03-04/scored.json")
+	if err != nil || got != 0.4 {
+		t.Errorf("scored = %v, %v", got, err)
+	}
+
+	// Legacy metadata has no score; it is assessed from the fields and transcript.
+	writeJSON(filepath.Join(day, "legacy.json"), &Metadata{
+		ID: "legacy", Title: "Legacy", Date: "2025-03-04", DurationSeconds: 60.0, Participants: []any{"A"},
+	})
+	os.WriteFile(filepath.Join(day, "legacy.transcript.txt"), []byte("[00:00:01] A: hi"), 0o600)
+	got, err = storedQuality(dir, "2025-03-04/legacy.json")
+	if err != nil || got != 0.9 {
+		t.Errorf("legacy = %v, %v", got, err)
+	}
+
+	if _, err := storedQuality(dir, "2025-03-04/missing.json"); err == nil {
+		t.Error("expected error for missing metadata")
+	}
+}
+
+func TestBelowMinQuality(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "2025-03-04"), 0o755)
+	q := 0.4
+	writeJSON(filepath.Join(dir, "2025-03-04", "m1.json"), &Metadata{ID: "m1", ScrapeQuality: &q})
+
+	e := &Exporter{cfg: &Config{OutputDir: dir}}
+	if e.belowMinQuality("m1", "2025-03-04/m1.json") {
+		t.Error("--min-quality unset should never re-export")
+	}
+	e.cfg.MinQuality = 0.7
+	if !e.belowMinQuality("m1", "2025-03-04/m1.json") {
+		t.Error("0.4 < 0.7 should re-export")
+	}
+	e.cfg.MinQuality = 0.3
+	if e.belowMinQuality("m1", "2025-03-04/m1.json") {
+		t.Error("0.4 >= 0.3 should not re-export")
+	}
+	if e.belowMinQuality("m2", "2025-03-04/m2.json") {
+		t.Error("unreadable metadata should be left alone")
+	}
+}
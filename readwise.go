@@ -0,0 +1,369 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Readwise ────────────────────────────────────────────────────────────────
+//
+// --readwise-token pushes the archive's highlights to Readwise, so they come
+// up in the same daily review as book and article highlights. After each
+// run, every highlight on disk that Readwise hasn't seen is sent: its text,
+// the speaker, the meeting title, and a link to the moment in the recording.
+// Each meeting becomes one Readwise "book" (category podcasts, author
+// Grain). readwiseStateFile maps Grain highlight IDs to the Readwise IDs they
+// were created under, so a highlight is pushed once; one whose text changed
+// in Grain is updated in place. Highlights deleted in Readwise are not
+// recreated. Failed pushes are not recorded and are retried after the next
+// run.
+
+// readwiseAPI is the Readwise API base URL.
+const readwiseAPI = "https://readwise.io/api/v2"
+
+// readwiseStateFile records the highlights pushed to Readwise. Hidden, like
+// the other state files, so mirrors and Drive sync leave it alone.
+const readwiseStateFile = ".graindl-readwise.json"
+
+// readwiseMaxText is Readwise's limit on highlight text and note length.
+const readwiseMaxText = 8191
+
+// readwiseMaxRetries bounds the retries of a rate-limited request.
+const readwiseMaxRetries = 3
+
+// errReadwiseAuth is returned when Readwise rejects the token.
+var errReadwiseAuth = errors.New("readwise rejected the access token")
+
+// Readwise pushes highlights to the Readwise API.
+type Readwise struct {
+	client *http.Client
+	token  string
+	base   string
+	wait   func(ctx context.Context, d time.Duration) error // rate-limit backoff; replaced in tests
+}
+
+// NewReadwise returns a client for token after checking that Readwise
+// accepts it.
+func NewReadwise(ctx context.Context, token string) (*Readwise, error) {
+	rw := newReadwise(token, readwiseAPI)
+	if err := rw.do(ctx, http.MethodGet, "/auth/", nil, nil); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func newReadwise(token, base string) *Readwise {
+	return &Readwise{
+		client: &http.Client{Timeout: 30 * time.Second},
+		token:  token,
+		base:   strings.TrimSuffix(base, "/"),
+		wait:   readwiseWait,
+	}
+}
+
+// readwiseWait sleeps for d, or until ctx is done.
+func readwiseWait(ctx context.Context, d time.Duration) error {
+	if !waitUntil(ctx, time.Now().Add(d)) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// readwiseHighlight is one highlight in a create request.
+type readwiseHighlight struct {
+	Text          string `json:"text"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	Category      string `json:"category"`
+	SourceType    string `json:"source_type"`
+	SourceURL     string `json:"source_url,omitempty"`
+	Note          string `json:"note,omitempty"`
+	Location      int    `json:"location"`
+	LocationType  string `json:"location_type"`
+	HighlightedAt string `json:"highlighted_at,omitempty"`
+	HighlightURL  string `json:"highlight_url,omitempty"`
+}
+
+// readwiseBook is one entry of a create response.
+type readwiseBook struct {
+	ID                 int64   `json:"id"`
+	ModifiedHighlights []int64 `json:"modified_highlights"`
+}
+
+// ReadwiseState is the persisted record of pushed highlights.
+type ReadwiseState struct {
+	Highlights map[string]*ReadwisePush `json:"highlights"` // Grain highlight key →
+}
+
+// ReadwisePush is one highlight sent to Readwise.
+type ReadwisePush struct {
+	ReadwiseID int64     `json:"readwise_id,omitempty"` // 0 when the response didn't say
+	Hash       string    `json:"hash"`                  // of the fields sent
+	PushedAt   time.Time `json:"pushed_at"`
+}
+
+// loadReadwiseState reads the push state, falling back to its backup. A
+// missing or unreadable file yields an empty state; Readwise itself drops
+// exact duplicates, so starting over re-sends but doesn't duplicate.
+func loadReadwiseState(outputDir string) *ReadwiseState {
+	st := &ReadwiseState{}
+	path := filepath.Join(outputDir, readwiseStateFile)
+	err := readStateFile(path, func(data []byte) error {
+		st = &ReadwiseState{}
+		return json.Unmarshal(data, st)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Readwise state unreadable, re-sending highlights", "path", path, "error", err)
+	}
+	if st.Highlights == nil {
+		st.Highlights = map[string]*ReadwisePush{}
+	}
+	return st
+}
+
+func saveReadwiseState(outputDir string, st *ReadwiseState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeStateFile(filepath.Join(outputDir, readwiseStateFile), data)
+}
+
+// readwiseKey identifies a Grain highlight across runs: its ID, or the
+// meeting and start second for highlights scraped without one.
+func readwiseKey(meetingID string, c HighlightClip) string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return meetingID + "@" + strconv.Itoa(int(c.StartSec))
+}
+
+// readwiseHighlightFor builds the Readwise highlight for clip c of meeting
+// m, or false when the clip has no text.
+func readwiseHighlightFor(m *Metadata, c HighlightClip) (readwiseHighlight, bool) {
+	text := strings.TrimSpace(coalesce(c.Text, c.Title))
+	if text == "" {
+		return readwiseHighlight{}, false
+	}
+	var note []string
+	if c.Speaker != "" {
+		note = append(note, c.Speaker)
+	}
+	if c.Title != "" && c.Title != text {
+		note = append(note, c.Title)
+	}
+	h := readwiseHighlight{
+		Text:         truncateRunes(text, readwiseMaxText-1),
+		Title:        coalesce(m.Title, m.ID),
+		Author:       "Grain",
+		Category:     "podcasts",
+		SourceType:   "graindl",
+		SourceURL:    m.Links.Grain,
+		Note:         truncateRunes(strings.Join(note, " · "), readwiseMaxText-1),
+		Location:     int(c.StartSec),
+		LocationType: "time_offset",
+		HighlightURL: coalesce(c.URL, timestampURL(m.Links.Grain, c.StartSec)),
+	}
+	if t, err := time.Parse(time.RFC3339, c.CreatedAt); err == nil {
+		h.HighlightedAt = t.UTC().Format(time.RFC3339)
+	}
+	return h, true
+}
+
+// hash fingerprints the fields sent, so edits in Grain are noticed.
+func (h readwiseHighlight) hash() string {
+	data, _ := json.Marshal(h)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readwiseStats counts the outcome of a sync.
+type readwiseStats struct {
+	Created, Updated, Failed int
+}
+
+// Push sends one meeting's highlights that st has not seen, or that changed
+// since, and records them in st.
+func (rw *Readwise) Push(ctx context.Context, m *Metadata, clips []HighlightClip, st *ReadwiseState) (readwiseStats, error) {
+	var stats readwiseStats
+	var create []readwiseHighlight
+	var createKeys []string
+	now := time.Now().UTC()
+	for _, c := range clips {
+		h, ok := readwiseHighlightFor(m, c)
+		if !ok {
+			continue
+		}
+		key, sum := readwiseKey(m.ID, c), h.hash()
+		prev := st.Highlights[key]
+		switch {
+		case prev == nil || prev.ReadwiseID == 0 && prev.Hash != sum:
+			create = append(create, h)
+			createKeys = append(createKeys, key)
+		case prev.Hash != sum:
+			err := rw.update(ctx, prev.ReadwiseID, h)
+			if errors.Is(err, errReadwiseAuth) {
+				return stats, err
+			}
+			var se *readwiseStatusError
+			if errors.As(err, &se) && se.Code == http.StatusNotFound {
+				slog.Debug("Highlight deleted in Readwise, not recreating", "id", m.ID, "highlight", key)
+				err = nil
+			} else if err == nil {
+				stats.Updated++
+			}
+			if err != nil {
+				slog.Warn("Readwise update failed", "id", m.ID, "highlight", key, "error", err)
+				stats.Failed++
+				continue
+			}
+			st.Highlights[key] = &ReadwisePush{ReadwiseID: prev.ReadwiseID, Hash: sum, PushedAt: now}
+		}
+	}
+	if len(create) == 0 {
+		return stats, nil
+	}
+
+	var books []readwiseBook
+	if err := rw.do(ctx, http.MethodPost, "/highlights/", map[string]any{"highlights": create}, &books); err != nil {
+		stats.Failed += len(create)
+		return stats, err
+	}
+	// One meeting is one book, so the IDs come back in request order.
+	var ids []int64
+	if len(books) == 1 && len(books[0].ModifiedHighlights) == len(create) {
+		ids = books[0].ModifiedHighlights
+	}
+	for i, key := range createKeys {
+		p := &ReadwisePush{Hash: create[i].hash(), PushedAt: now}
+		if ids != nil {
+			p.ReadwiseID = ids[i]
+		}
+		st.Highlights[key] = p
+	}
+	stats.Created += len(create)
+	return stats, nil
+}
+
+// update replaces the text and link of an existing highlight. The note is
+// left alone: it may hold the user's own notes and tags by now.
+func (rw *Readwise) update(ctx context.Context, id int64, h readwiseHighlight) error {
+	body := map[string]any{"text": h.Text, "location": h.Location, "url": h.HighlightURL}
+	return rw.do(ctx, http.MethodPatch, fmt.Sprintf("/highlights/%d/", id), body, nil)
+}
+
+// readwiseStatusError is a non-2xx Readwise response.
+type readwiseStatusError struct {
+	Code int
+	Body string
+}
+
+func (e *readwiseStatusError) Error() string {
+	return fmt.Sprintf("readwise: HTTP %d: %s", e.Code, e.Body)
+}
+
+// do sends a JSON request and decodes the response into out (when non-nil).
+// Rate-limited requests are retried after the Retry-After delay.
+func (rw *Readwise) do(ctx context.Context, method, path string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, rw.base+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Token "+rw.token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := rw.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("readwise: %w", err)
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < readwiseMaxRetries:
+			delay := time.Minute
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s >= 0 {
+				delay = time.Duration(min(s, 300)) * time.Second
+			}
+			slog.Info(fmt.Sprintf("Readwise rate limit; retrying in %s", delay))
+			if err := rw.wait(ctx, delay); err != nil {
+				return err
+			}
+			continue
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return errReadwiseAuth
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return &readwiseStatusError{Code: resp.StatusCode, Body: truncateRunes(strings.TrimSpace(string(data)), 200)}
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("readwise: decode response: %w", err)
+		}
+		return nil
+	}
+}
+
+// syncReadwise pushes new and changed highlights from the whole archive.
+// Failures are logged; they never fail the run.
+func (e *Exporter) syncReadwise(ctx context.Context) {
+	if e.readwise == nil {
+		return
+	}
+	entries, err := scanArchive(e.cfg.dataDir())
+	if err != nil {
+		slog.Warn("Readwise sync skipped", "error", err)
+		return
+	}
+	st := loadReadwiseState(e.cfg.OutputDir)
+	var total readwiseStats
+	for _, a := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		clips := a.Highlights(e.cfg.dataDir())
+		if len(clips) == 0 {
+			continue
+		}
+		stats, err := e.readwise.Push(ctx, a.Meta, clips, st)
+		total.Created += stats.Created
+		total.Updated += stats.Updated
+		total.Failed += stats.Failed
+		if errors.Is(err, errReadwiseAuth) {
+			slog.Warn("Readwise sync stopped: the token was rejected; check --readwise-token")
+			break
+		}
+		if err != nil {
+			slog.Warn("Readwise push failed", "id", a.Meta.ID, "error", err)
+		}
+	}
+	if err := saveReadwiseState(e.cfg.OutputDir, st); err != nil {
+		slog.Warn("Readwise state write failed", "error", err)
+	}
+	if total.Created+total.Updated+total.Failed > 0 {
+		slog.Info(fmt.Sprintf("Readwise: %d highlight(s) added, %d updated, %d failed", total.Created, total.Updated, total.Failed))
+	}
+}
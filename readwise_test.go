@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeReadwise records requests and answers creates with sequential IDs.
+type fakeReadwise struct {
+	t       *testing.T
+	creates [][]readwiseHighlight
+	patches map[string]map[string]any
+	status  int // forced response status, when non-zero
+	limited int // 429 responses to send first
+	nextID  int64
+}
+
+func (f *fakeReadwise) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "Token tok" {
+		f.t.Errorf("Authorization = %q", got)
+	}
+	if f.limited > 0 {
+		f.limited--
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/highlights/":
+		var req struct {
+			Highlights []readwiseHighlight `json:"highlights"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			f.t.Fatalf("bad create payload: %v", err)
+		}
+		f.creates = append(f.creates, req.Highlights)
+		ids := make([]int64, len(req.Highlights))
+		for i := range ids {
+			f.nextID++
+			ids[i] = f.nextID
+		}
+		json.NewEncoder(w).Encode([]readwiseBook{{ID: 1, ModifiedHighlights: ids}})
+	case r.Method == http.MethodPatch:
+		var req map[string]any
+		json.Unmarshal(body, &req)
+		f.patches[r.URL.Path] = req
+		w.Write([]byte("{}"))
+	default:
+		f.t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+	}
+}
+
+func newFakeReadwise(t *testing.T) (*fakeReadwise, *Readwise) {
+	t.Helper()
+	f := &fakeReadwise{t: t, patches: map[string]map[string]any{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, newReadwise("tok", srv.URL)
+}
+
+func TestReadwiseHighlightFor(t *testing.T) {
+	m := &Metadata{ID: "m1", Title: "Acme renewal", Links: Links{Grain: "https://grain.com/app/meetings/m1"}}
+	h, ok := readwiseHighlightFor(m, HighlightClip{Text: "We need SSO.", Title: "SSO ask", Speaker: "Dana", StartSec: 754.6, CreatedAt: "2025-03-05T10:00:00+01:00"})
+	if !ok {
+		t.Fatal("highlight with text skipped")
+	}
+	want := readwiseHighlight{
+		Text: "We need SSO.", Title: "Acme renewal", Author: "Grain", Category: "podcasts", SourceType: "graindl",
+		SourceURL: "https://grain.com/app/meetings/m1", Note: "Dana · SSO ask", Location: 754, LocationType: "time_offset",
+		HighlightedAt: "2025-03-05T09:00:00Z", HighlightURL: "https://grain.com/app/meetings/m1?t=754",
+	}
+	if h != want {
+		t.Errorf("highlight =\n%+v\nwant\n%+v", h, want)
+	}
+	if h, _ := readwiseHighlightFor(m, HighlightClip{Text: "x", URL: "https://grain.com/share/highlight/c1"}); h.HighlightURL != "https://grain.com/share/highlight/c1" {
+		t.Errorf("clip URL not preferred: %q", h.HighlightURL)
+	}
+	if _, ok := readwiseHighlightFor(m, HighlightClip{Speaker: "Dana"}); ok {
+		t.Error("highlight without text pushed")
+	}
+}
+
+func TestReadwiseSync(t *testing.T) {
+	f, rw := newFakeReadwise(t)
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-03-05", &Metadata{ID: "m1", Title: "Acme renewal", Links: Links{Grain: "https://grain.com/app/meetings/m1"}})
+	clips := []HighlightClip{{ID: "c1", Text: "We need SSO.", Speaker: "Dana", StartSec: 60}, {Text: "Budget is approved.", StartSec: 120}}
+	writeClips := func() {
+		data, _ := json.Marshal(clips)
+		writeArchiveFile(t, dir, "2025-03-05/m1.highlights.json", string(data), 0)
+	}
+	writeClips()
+	writeArchiveMeta(t, dir, "2025-03-06", &Metadata{ID: "m2", Title: "No highlights"})
+	e := &Exporter{cfg: &Config{OutputDir: dir}, readwise: rw}
+
+	e.syncReadwise(context.Background())
+	if len(f.creates) != 1 || len(f.creates[0]) != 2 {
+		t.Fatalf("creates = %+v, want one request with 2 highlights", f.creates)
+	}
+	st := loadReadwiseState(dir)
+	if p := st.Highlights["c1"]; p == nil || p.ReadwiseID != 1 {
+		t.Errorf("c1 state = %+v", p)
+	}
+	if p := st.Highlights["m1@120"]; p == nil || p.ReadwiseID != 2 {
+		t.Errorf("ID-less highlight state = %+v", p)
+	}
+
+	// Nothing new: nothing sent.
+	e.syncReadwise(context.Background())
+	if len(f.creates) != 1 || len(f.patches) != 0 {
+		t.Fatalf("second sync sent %d creates, %d patches", len(f.creates)-1, len(f.patches))
+	}
+
+	// An edited highlight is updated in place; a new one is created.
+	clips[0].Text = "We need SSO and SCIM."
+	clips = append(clips, HighlightClip{ID: "c3", Text: "Ship by May.", StartSec: 300})
+	writeClips()
+	e.syncReadwise(context.Background())
+	if len(f.creates) != 2 || len(f.creates[1]) != 1 || f.creates[1][0].Text != "Ship by May." {
+		t.Errorf("creates after edit = %+v", f.creates)
+	}
+	if p := f.patches["/highlights/1/"]; p == nil || p["text"] != "We need SSO and SCIM." || p["note"] != nil {
+		t.Errorf("patches = %+v", f.patches)
+	}
+}
+
+func TestReadwisePushFailures(t *testing.T) {
+	m := &Metadata{ID: "m1", Title: "Acme"}
+	clips := []HighlightClip{{ID: "c1", Text: "hello"}}
+
+	// Rate limiting waits for Retry-After, then goes through.
+	f, rw := newFakeReadwise(t)
+	var waited []time.Duration
+	rw.wait = func(_ context.Context, d time.Duration) error { waited = append(waited, d); return nil }
+	f.limited = 2
+	st := &ReadwiseState{Highlights: map[string]*ReadwisePush{}}
+	if stats, err := rw.Push(context.Background(), m, clips, st); err != nil || stats.Created != 1 {
+		t.Fatalf("rate-limited push: %+v, %v", stats, err)
+	}
+	if len(waited) != 2 || waited[0] != 7*time.Second {
+		t.Errorf("waited %v", waited)
+	}
+
+	// A failed create is not recorded, so the next sync retries it.
+	f.status = http.StatusInternalServerError
+	st = &ReadwiseState{Highlights: map[string]*ReadwisePush{}}
+	stats, err := rw.Push(context.Background(), m, clips, st)
+	if err == nil || stats.Failed != 1 || len(st.Highlights) != 0 {
+		t.Errorf("failed push: %+v, %v, state %+v", stats, err, st.Highlights)
+	}
+
+	// A rejected token is reported as such.
+	f.status = http.StatusUnauthorized
+	if _, err := rw.Push(context.Background(), m, clips, st); !errors.Is(err, errReadwiseAuth) {
+		t.Errorf("401: err = %v", err)
+	}
+
+	// A highlight deleted in Readwise is not recreated.
+	f.status = http.StatusNotFound
+	st = &ReadwiseState{Highlights: map[string]*ReadwisePush{"c1": {ReadwiseID: 9, Hash: "old"}}}
+	if stats, err := rw.Push(context.Background(), m, clips, st); err != nil || stats.Updated+stats.Created != 0 {
+		t.Errorf("deleted highlight: %+v, %v", stats, err)
+	}
+	if p := st.Highlights["c1"]; p.Hash == "old" || p.ReadwiseID != 9 {
+		t.Errorf("deleted highlight state = %+v", p)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// ── Export Receipts ─────────────────────────────────────────────────────────
+//
+// Every exported meeting gets a <date>/<id>.receipt.json next to its
+// metadata: the SHA-256 of each artifact, the graindl version, when it was
+// exported, and per downstream target (Drive, Apple Notes, the keyword alert
+// webhook) which artifact hashes were last delivered there. Integrations push only the artifacts
+// whose current hash differs from the delivered one, so "exported locally"
+// and "delivered downstream" are tracked apart: a meeting whose Drive upload
+// failed is skipped as exported on the next run, yet its receipt still shows
+// the upload as owed and the run retries it. Only targets already attempted
+// for a meeting are retried that way; `graindl gdrive` backfills an archive
+// exported before --gdrive was turned on. Keyword alerts scan the scraped
+// transcript, so a failed alert webhook is retried only when the meeting is
+// exported again.
+//
+// Receipts are local state: they are written in place rather than through
+// Storage, so they are never compressed, sealed, or mirrored, and Drive
+// sync leaves them out.
+
+// receiptSuffix is appended to a meeting's <date>/<id> base.
+const receiptSuffix = ".receipt.json"
+
+// receiptVersion is the current receipt format.
+const receiptVersion = 1
+
+// notionPartRe matches a split Notion note's continuation files, which
+// Apple Notes never receives.
+var notionPartRe = regexp.MustCompile(`\.part\d+\.md$`)
+
+// Delivery targets recorded in receipts.
+const (
+	deliveryDrive      = "gdrive"
+	deliveryAppleNotes = "apple_notes"
+	deliveryAlert      = "alert_webhook" // transcript and highlights scanned by --alert-keywords
+)
+
+// ExportReceipt is one meeting's record of what was exported and delivered.
+type ExportReceipt struct {
+	Version        int                  `json:"version"`
+	ID             string               `json:"id"`
+	GraindlVersion string               `json:"graindl_version"`
+	ExportedAt     time.Time            `json:"exported_at"`
+	Artifacts      map[string]string    `json:"artifacts"`            // stored path → SHA-256
+	Deliveries     map[string]*Delivery `json:"deliveries,omitempty"` // target →
+
+	path string // absolute path of the receipt file
+}
+
+// Delivery is what one downstream target last received.
+type Delivery struct {
+	Artifacts   map[string]string `json:"artifacts"`              // stored path → SHA-256 delivered
+	DeliveredAt *time.Time        `json:"delivered_at,omitempty"` // last successful push
+	Error       string            `json:"error,omitempty"`        // last failed push, cleared by a success
+	FailedAt    *time.Time        `json:"failed_at,omitempty"`
+}
+
+// receiptRelPath returns the receipt path for r's meeting.
+func receiptRelPath(r *ExportResult) string {
+	return filepath.Join(r.DateDir, sanitize(r.ID)) + receiptSuffix
+}
+
+// loadReceipt reads the receipt at path. A missing or unreadable receipt
+// yields an empty one, so every artifact counts as undelivered.
+func loadReceipt(path, id string) *ExportReceipt {
+	rc := &ExportReceipt{}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, rc)
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Receipt unreadable, starting a new one", "path", path, "error", err)
+		rc = &ExportReceipt{}
+	}
+	rc.Version, rc.ID, rc.path = receiptVersion, id, path
+	if rc.Artifacts == nil {
+		rc.Artifacts = map[string]string{}
+	}
+	if rc.Deliveries == nil {
+		rc.Deliveries = map[string]*Delivery{}
+	}
+	return rc
+}
+
+// record merges freshly hashed artifacts into the receipt. Artifacts not
+// rewritten (a video added later by download-videos, say) keep their hash.
+func (rc *ExportReceipt) record(checksums map[string]string) {
+	if rc == nil || len(checksums) == 0 {
+		return
+	}
+	for p, sum := range checksums {
+		rc.Artifacts[filepath.Clean(p)] = sum
+	}
+	rc.GraindlVersion = version
+	rc.ExportedAt = time.Now().UTC()
+}
+
+// pending returns the paths among paths that target has not received in
+// their current version. Paths the receipt has no hash for are always
+// pending; with no receipt, every path is.
+func (rc *ExportReceipt) pending(target string, paths []string) []string {
+	var out []string
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		if rc != nil {
+			sum := rc.Artifacts[filepath.Clean(p)]
+			if d := rc.Deliveries[target]; sum != "" && d != nil && d.Artifacts[filepath.Clean(p)] == sum {
+				continue
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// delivered records that target now has paths in their current version.
+func (rc *ExportReceipt) delivered(target string, paths []string) {
+	if rc == nil {
+		return
+	}
+	d := rc.delivery(target)
+	for _, p := range paths {
+		if sum := rc.Artifacts[filepath.Clean(p)]; p != "" && sum != "" {
+			d.Artifacts[filepath.Clean(p)] = sum
+		}
+	}
+	now := time.Now().UTC()
+	d.DeliveredAt = &now
+	d.Error, d.FailedAt = "", nil
+}
+
+// failed records a failed push to target; what it had before is kept.
+func (rc *ExportReceipt) failed(target string, err error) {
+	if rc == nil {
+		return
+	}
+	d := rc.delivery(target)
+	now := time.Now().UTC()
+	d.Error, d.FailedAt = err.Error(), &now
+}
+
+func (rc *ExportReceipt) delivery(target string) *Delivery {
+	d := rc.Deliveries[target]
+	if d == nil {
+		d = &Delivery{Artifacts: map[string]string{}}
+		rc.Deliveries[target] = d
+	}
+	if d.Artifacts == nil {
+		d.Artifacts = map[string]string{}
+	}
+	return d
+}
+
+// save atomically replaces the receipt file.
+func (rc *ExportReceipt) save() error {
+	if rc == nil || len(rc.Artifacts) == 0 && len(rc.Deliveries) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(rc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(rc.path), 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	tmp := rc.path + ".tmp"
+	if err := writeFile(tmp, data); err != nil {
+		return err
+	}
+	return os.Rename(tmp, rc.path)
+}
+
+// ── Exporter Integration ────────────────────────────────────────────────────
+
+// openReceipt loads r's receipt and records r's checksums in it.
+func (e *Exporter) openReceipt(r *ExportResult) *ExportReceipt {
+	rc := loadReceipt(e.storage.AbsPath(receiptRelPath(r)), r.ID)
+	rc.record(r.Checksums)
+	return rc
+}
+
+// saveReceipt writes rc, logging a failure; it never fails the export.
+func (e *Exporter) saveReceipt(rc *ExportReceipt) {
+	if err := rc.save(); err != nil {
+		slog.Warn("Receipt write failed", "id", rc.ID, "error", err)
+	}
+}
+
+// alertPaths returns the artifacts the keyword alerter scans.
+func alertPaths(r *ExportResult) []string {
+	var paths []string
+	for _, p := range r.TranscriptPaths {
+		paths = append(paths, p)
+	}
+	return append(paths, r.HighlightsPath)
+}
+
+// alertMeeting runs the keyword alerter on a meeting's transcript and
+// highlights and records the outcome in rc under alert_webhook. A meeting
+// alerts on its first export and again only when its transcript or
+// highlights change, so --overwrite, --min-quality, and retry re-exports
+// don't repeat the alert.
+func (e *Exporter) alertMeeting(ctx context.Context, meta *Metadata, r *ExportResult, rc *ExportReceipt, transcript string, highlights []HighlightClip) {
+	if rc.Deliveries[deliveryAlert] != nil && len(rc.pending(deliveryAlert, alertPaths(r))) == 0 {
+		slog.Debug("Keyword alert skipped: transcript unchanged since the last alert", "id", r.ID)
+		return
+	}
+	defer e.saveReceipt(rc)
+	n, err := e.alerter.Check(ctx, meta, transcript, highlights)
+	r.AlertMatches = n
+	if err != nil {
+		rc.failed(deliveryAlert, err)
+		return
+	}
+	rc.delivered(deliveryAlert, alertPaths(r))
+}
+
+// redeliver retries, for a meeting skipped as already exported, the pushes
+// its receipt shows as owed: targets attempted before whose delivered
+// artifacts are missing or stale. Artifacts no longer on disk are ignored.
+func (e *Exporter) redeliver(ctx context.Context, relBase string, r *ExportResult) {
+	if e.drive == nil && e.notes == nil {
+		return
+	}
+	rc := loadReceipt(e.storage.AbsPath(relBase+receiptSuffix), r.ID)
+	var onDisk []string
+	for p := range rc.Artifacts {
+		if _, err := os.Stat(e.storage.AbsPath(p)); err == nil {
+			onDisk = append(onDisk, p)
+		}
+	}
+	owed := func(target string, enabled bool) []string {
+		if !enabled || rc.Deliveries[target] == nil {
+			return nil
+		}
+		return rc.pending(target, onDisk)
+	}
+	drive := owed(deliveryDrive, e.drive != nil)
+	var note string
+	for _, p := range owed(deliveryAppleNotes, e.notes != nil) {
+		if classifyContent(p) == "markdown" && !notionPartRe.MatchString(p) {
+			note = p
+		}
+	}
+	if len(drive) == 0 && note == "" {
+		return
+	}
+	meta, err := readArchiveMetadata(e.storage.AbsPath(relBase + ".json"))
+	if err != nil {
+		slog.Debug("Redelivery skipped: metadata unreadable", "id", r.ID, "error", err)
+		return
+	}
+
+	if note != "" {
+		r.MarkdownPath = note
+		e.pushAppleNote(ctx, meta, r, rc)
+	}
+	if len(drive) > 0 {
+		e.queueDrive(ctx, &driveJob{meta: meta, r: r, rc: rc, paths: drive, done: func(stats *UploadStats, err error) {
+			if err != nil {
+				slog.Warn("Drive redelivery failed", "id", r.ID, "error", err)
+				r.DriveError = redactSecrets(err.Error())
+			} else {
+				r.DriveUploaded = true
+				slog.Info("Redelivered to Google Drive", "id", r.ID, "created", stats.Created, "updated", stats.Updated)
+			}
+		}})
+	}
+	e.saveReceipt(rc)
+}
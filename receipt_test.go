@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReceiptPendingAndDelivered(t *testing.T) {
+	rc := loadReceipt(filepath.Join(t.TempDir(), "m1"+receiptSuffix), "m1")
+	rc.record(map[string]string{"d/m1.json": "aaa", "d/m1.md": "bbb"})
+	paths := []string{"d/m1.json", "", "d/m1.md", "d/m1.mp4"}
+
+	if got := rc.pending(deliveryDrive, paths); len(got) != 3 {
+		t.Fatalf("nothing delivered: pending = %v", got)
+	}
+	rc.delivered(deliveryDrive, paths)
+	// m1.mp4 has no recorded hash, so it is always pushed.
+	if got := rc.pending(deliveryDrive, paths); len(got) != 1 || got[0] != "d/m1.mp4" {
+		t.Errorf("after delivery: pending = %v, want m1.mp4", got)
+	}
+	if got := rc.pending(deliveryAppleNotes, paths); len(got) != 3 {
+		t.Errorf("other target: pending = %v", got)
+	}
+
+	// A re-export changes the note; a failed push keeps it owed.
+	rc.record(map[string]string{"d/m1.md": "ccc"})
+	rc.failed(deliveryDrive, errors.New("quota"))
+	if got := rc.pending(deliveryDrive, paths[:3]); len(got) != 1 || got[0] != "d/m1.md" {
+		t.Errorf("after change: pending = %v, want m1.md", got)
+	}
+	if d := rc.Deliveries[deliveryDrive]; d.Error != "quota" || d.FailedAt == nil || d.DeliveredAt == nil {
+		t.Errorf("delivery = %+v", d)
+	}
+
+	var none *ExportReceipt
+	if got := none.pending(deliveryDrive, paths); len(got) != 3 {
+		t.Errorf("nil receipt: pending = %v", got)
+	}
+}
+
+func TestReceiptSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2025-01-15", "m1"+receiptSuffix)
+	rc := loadReceipt(path, "m1")
+	if err := rc.save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("empty receipt was written")
+	}
+
+	rc.record(map[string]string{"2025-01-15/m1.json": "aaa"})
+	rc.delivered(deliveryDrive, []string{"2025-01-15/m1.json"})
+	if err := rc.save(); err != nil {
+		t.Fatal(err)
+	}
+	got := loadReceipt(path, "m1")
+	if got.Version != receiptVersion || got.GraindlVersion != version || got.ExportedAt.IsZero() || len(got.pending(deliveryDrive, []string{"2025-01-15/m1.json"})) != 0 {
+		t.Errorf("loaded = %+v", got)
+	}
+
+	_ = os.WriteFile(path, []byte("{not json"), 0o600)
+	if got := loadReceipt(path, "m1"); len(got.Artifacts) != 0 || got.ID != "m1" {
+		t.Errorf("corrupt receipt: %+v", got)
+	}
+	if c := classifyContent(path); c != "receipt" {
+		t.Errorf("classifyContent = %q", c)
+	}
+}
+
+func TestFinishResultReceipt(t *testing.T) {
+	dir := t.TempDir()
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, SkipVideo: true, OutputFormat: "obsidian"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	var calls [][]string
+	var body string
+	e.notes = newAppleNotes(&Config{})
+	e.notes.run = recordRun(&calls, &body)
+
+	meta := &Metadata{ID: "m1", Title: "Sync", Date: "2025-01-15"}
+	export := func() *ExportResult {
+		r := &ExportResult{ID: "m1", DateDir: "2025-01-15"}
+		e.writeMetadata(meta, "2025-01-15/m1.json", r)
+		e.writeFormattedMarkdown(meta, "", "2025-01-15/m1", r)
+		e.finishResult(context.Background(), meta, r)
+		return r
+	}
+
+	r := export()
+	rc := loadReceipt(filepath.Join(dir, "2025-01-15", "m1"+receiptSuffix), "m1")
+	if len(rc.Artifacts) != 2 || rc.Artifacts[r.MarkdownPath] == "" || rc.Deliveries[deliveryAppleNotes] == nil {
+		t.Fatalf("receipt = %+v", rc)
+	}
+	if len(calls) != 1 || !r.AppleNotes {
+		t.Fatalf("first export: %d pushes", len(calls))
+	}
+
+	// Re-exporting the same note doesn't push it again; a changed one does.
+	if r := export(); len(calls) != 1 || !r.AppleNotes {
+		t.Errorf("unchanged note: %d pushes, AppleNotes=%v", len(calls), r.AppleNotes)
+	}
+	meta.Title = "Sync v2"
+	export()
+	if len(calls) != 2 {
+		t.Errorf("changed note: %d pushes, want 2", len(calls))
+	}
+
+	// A failed push is retried when a later run skips the meeting.
+	ok := e.notes.run
+	e.notes.run = func(context.Context, string, ...string) error { return errors.New("Notes is not running") }
+	meta.Title = "Sync v3"
+	export()
+	e.notes.run = ok
+	e.redeliver(context.Background(), "2025-01-15/m1", &ExportResult{ID: "m1", DateDir: "2025-01-15"})
+	if len(calls) != 3 {
+		t.Errorf("redelivery: %d pushes, want 3", len(calls))
+	}
+	e.redeliver(context.Background(), "2025-01-15/m1", &ExportResult{ID: "m1", DateDir: "2025-01-15"})
+	if len(calls) != 3 {
+		t.Errorf("second redelivery pushed again: %d pushes", len(calls))
+	}
+
+	// Receipts are not meetings.
+	entries, err := scanArchive(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("scanArchive = %d entries, %v", len(entries), err)
+	}
+}
+
+func TestAlertMeetingReceipt(t *testing.T) {
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, SkipVideo: true, AlertKeywords: []string{"refund"}, AlertWebhook: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	writeArchiveFile(t, dir, "2025-01-15/m1.transcript.txt", "Alice: a refund", 0)
+	meta := &Metadata{ID: "m1", Title: "Sync"}
+	alert := func() *ExportReceipt {
+		r := &ExportResult{ID: "m1", DateDir: "2025-01-15", TranscriptPaths: map[string]string{"text": "2025-01-15/m1.transcript.txt"}}
+		rc := e.finishResult(context.Background(), meta, r)
+		e.alertMeeting(context.Background(), meta, r, rc, "Alice: a refund", nil)
+		if r.AlertMatches != 1 {
+			t.Errorf("AlertMatches = %d", r.AlertMatches)
+		}
+		return loadReceipt(filepath.Join(dir, "2025-01-15", "m1"+receiptSuffix), "m1")
+	}
+
+	if d := alert().Deliveries[deliveryAlert]; d == nil || d.Error == "" || len(d.Artifacts) != 0 {
+		t.Fatalf("failed webhook: delivery = %+v", d)
+	}
+	status = http.StatusOK
+	if d := alert().Deliveries[deliveryAlert]; d == nil || d.Error != "" || d.Artifacts["2025-01-15/m1.transcript.txt"] == "" {
+		t.Errorf("delivered webhook: delivery = %+v", d)
+	}
+}
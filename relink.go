@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ── Relink ──────────────────────────────────────────────────────────────────
+//
+// `graindl relink --from /old/path --to /new/path` repairs an archive that
+// was moved, for example during a NAS migration. graindl itself only writes
+// relative paths, but notes edited by hand, --extract-script results, and
+// sync states can hold absolute ones that point at the old location. relink
+// rewrites every path under --from to the same path under --to in markdown
+// notes, metadata and the other JSON artifacts (compressed ones included),
+// the manifest, the state files in the archive, and the Drive sync state in
+// the session dir. A path only matches on whole components, so /old/path
+// never rewrites /old/pathology or /srv/old/path. Sealed (--immutable) files
+// are reported and left alone.
+
+// relinkDriveState is the Drive sync state relink updates in --session-dir.
+const relinkDriveState = "gdrive-sync.json"
+
+// relinkCodecs maps compression suffixes back to the codec that wrote them.
+var relinkCodecs = map[string]string{".zst": "zstd", ".gz": "gzip"}
+
+// relinker rewrites one path prefix in the spellings it can appear in.
+type relinker struct {
+	forms [][2]string // old → new: plain, URL-escaped, JSON-escaped
+}
+
+func newRelinker(from, to string) *relinker {
+	r := &relinker{}
+	seen := map[string]bool{}
+	add := func(old, new string) {
+		if !seen[old] {
+			seen[old] = true
+			r.forms = append(r.forms, [2]string{old, new})
+		}
+	}
+	add(from, to)
+	add((&url.URL{Path: from}).EscapedPath(), (&url.URL{Path: to}).EscapedPath())
+	for _, escapeHTML := range []bool{true, false} {
+		add(jsonStringBody(from, escapeHTML), jsonStringBody(to, escapeHTML))
+	}
+	return r
+}
+
+// jsonStringBody returns s as it appears between the quotes of a JSON string.
+func jsonStringBody(s string, escapeHTML bool) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(escapeHTML)
+	_ = enc.Encode(s)
+	out := strings.TrimSuffix(b.String(), "\n")
+	return out[1 : len(out)-1]
+}
+
+// rewrite replaces every boundary-delimited occurrence of the old prefix in
+// data and returns the result with the number of replacements.
+func (r *relinker) rewrite(data []byte) ([]byte, int) {
+	total := 0
+	for _, f := range r.forms {
+		old := []byte(f[0])
+		var out bytes.Buffer
+		last, n := 0, 0
+		for i := 0; i < len(data); {
+			j := bytes.Index(data[i:], old)
+			if j < 0 {
+				break
+			}
+			start, end := i+j, i+j+len(old)
+			if (start == 0 || !pathByte(data[start-1])) && (end == len(data) || !pathByte(data[end])) {
+				out.Write(data[last:start])
+				out.WriteString(f[1])
+				last, i = end, end
+				n++
+				continue
+			}
+			i = start + 1
+		}
+		if n > 0 {
+			out.Write(data[last:])
+			data = out.Bytes()
+			total += n
+		}
+	}
+	return data, total
+}
+
+// pathByte reports whether c can continue a path component.
+func pathByte(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c >= 0x80:
+		return true
+	}
+	return strings.IndexByte("._-~%", c) >= 0
+}
+
+// relinkable reports whether relink rewrites the file at relPath.
+func relinkable(relPath string) bool {
+	name := trimCompressed(filepath.Base(relPath))
+	return strings.HasSuffix(name, ".md") || strings.HasSuffix(name, ".json")
+}
+
+// relinkResult is what relinkFile did to one file.
+type relinkResult struct {
+	Links  int
+	Sealed bool
+}
+
+// relinkFile rewrites the file at path, decompressing and recompressing it
+// when it is stored compressed. Nothing is written when dryRun is set, no
+// link matched, or the file is sealed.
+func relinkFile(path string, r *relinker, dryRun bool) (relinkResult, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return relinkResult{}, err
+	}
+	ext := compressionExt(path)
+	data := raw
+	if ext != "" {
+		if data, err = decompressArtifact(ext, raw); err != nil {
+			return relinkResult{}, fmt.Errorf("decompress: %w", err)
+		}
+	}
+	out, n := r.rewrite(data)
+	if n == 0 {
+		return relinkResult{}, nil
+	}
+	if sealed(path) {
+		return relinkResult{Links: n, Sealed: true}, nil
+	}
+	if dryRun {
+		return relinkResult{Links: n}, nil
+	}
+	if ext != "" {
+		if out, err = compressArtifact(relinkCodecs[ext], out); err != nil {
+			return relinkResult{}, fmt.Errorf("compress: %w", err)
+		}
+	}
+	if strings.HasPrefix(filepath.Base(path), ".graindl-") || filepath.Base(path) == relinkDriveState {
+		err = writeStateFile(path, out)
+	} else {
+		err = writeFile(path, out)
+	}
+	return relinkResult{Links: n}, err
+}
+
+// relinkPaths lists the files relink visits: the relinkable files under
+// outputDir and, when it exists, the Drive sync state in sessionDir.
+func relinkPaths(outputDir, sessionDir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && relinkable(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", outputDir, err)
+	}
+	if sessionDir != "" {
+		if state := filepath.Join(sessionDir, relinkDriveState); fileExists(state) {
+			paths = append(paths, state)
+		}
+	}
+	return paths, nil
+}
+
+func runRelink(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fset := flag.NewFlagSet("relink", flag.ContinueOnError)
+	from := fset.String("from", "", "Absolute path the archive used to live at (required)")
+	to := fset.String("to", "", "Absolute path the archive lives at now (required)")
+	outputDir := fset.String("output", "", "Archive directory to rewrite (default: --to)")
+	sessionDir := fset.String("session-dir", coalesce(envGet(dotenv, "GRAIN_SESSION_DIR"), "./.grain-session"), "Session dir holding the Drive sync state")
+	dryRun := fset.Bool("dry-run", false, "List files that would change without writing them")
+	verbose := fset.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	logFormat := fset.String("log-format", envGet(dotenv, "GRAIN_LOG_FORMAT"), "Log format: color (default), json")
+	if err := fset.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(*logFormat, *verbose)
+
+	if *from == "" || *to == "" {
+		slog.Error("--from and --to are required")
+		return 2
+	}
+	if !filepath.IsAbs(*from) || !filepath.IsAbs(*to) {
+		slog.Error("--from and --to must be absolute paths")
+		return 2
+	}
+	oldPath, newPath := filepath.Clean(*from), filepath.Clean(*to)
+	if oldPath == newPath || oldPath == string(filepath.Separator) {
+		slog.Error("--from must be a directory other than --to and /")
+		return 2
+	}
+	dir := coalesce(*outputDir, newPath)
+	if _, err := os.Stat(dir); err != nil {
+		slog.Error("Archive directory not found", "path", dir)
+		return 1
+	}
+	if err := checkUnrouted("relink", dir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+
+	paths, err := relinkPaths(dir, *sessionDir)
+	if err != nil {
+		slog.Error("relink failed", "error", err)
+		return 1
+	}
+	r := newRelinker(oldPath, newPath)
+	verb := "Relinked"
+	if *dryRun {
+		verb = "Would relink"
+	}
+	files, links, failed := 0, 0, 0
+	var rewritten []string
+	for _, path := range paths {
+		res, err := relinkFile(path, r, *dryRun)
+		switch {
+		case err != nil:
+			slog.Warn("Relink failed", "path", path, "error", err)
+			failed++
+		case res.Sealed:
+			slog.Warn("Sealed by --immutable, left unchanged", "path", path, "links", res.Links)
+			failed++
+		case res.Links > 0:
+			slog.Info(fmt.Sprintf("%s %s", verb, path), "links", res.Links)
+			files++
+			links += res.Links
+			if rel, err := filepath.Rel(dir, path); err == nil && !*dryRun && !strings.HasPrefix(rel, "..") {
+				rewritten = append(rewritten, rel)
+			}
+		}
+	}
+	if len(rewritten) > 0 {
+		// Rewritten artifacts get new hashes so verify-local doesn't flag them.
+		if err := rehashTracked(dir, rewritten); err != nil {
+			slog.Warn("Checksum state update failed", "error", err)
+		}
+	}
+	slog.Info(fmt.Sprintf("%s %d links in %d files", verb, links, files), "scanned", len(paths), "failed", failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRelinkerRewrite(t *testing.T) {
+	r := newRelinker("/mnt/old nas/grain", "/volume1/grain")
+	for in, want := range map[string]string{
+		"![clip](/mnt/old nas/grain/2025-01-15/m1.mp4)":          "![clip](/volume1/grain/2025-01-15/m1.mp4)",
+		"[video](file:///mnt/old%20nas/grain/2025-01-15/m1.mp4)": "[video](file:///volume1/grain/2025-01-15/m1.mp4)",
+		`{"path": "/mnt/old nas/grain"}`:                         `{"path": "/volume1/grain"}`,
+		"/mnt/old nas/grainy/x.md":                               "/mnt/old nas/grainy/x.md",
+		"/srv/mnt/old nas/grain/x.md":                            "/srv/mnt/old nas/grain/x.md",
+		"no links here":                                          "no links here",
+	} {
+		if got, _ := r.rewrite([]byte(in)); string(got) != want {
+			t.Errorf("rewrite(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	// encoding/json writes & as \u0026 unless told otherwise.
+	r = newRelinker("/data/R&D", "/archive/R&D")
+	got, n := r.rewrite([]byte(`{"a": "/data/R\u0026D/m1.mp4", "b": "/data/R&D/m2.mp4"}`))
+	if n != 2 || string(got) != `{"a": "/archive/R\u0026D/m1.mp4", "b": "/archive/R&D/m2.mp4"}` {
+		t.Errorf("JSON rewrite = %s (%d)", got, n)
+	}
+}
+
+func TestRunRelink(t *testing.T) {
+	dir := t.TempDir()
+	session := t.TempDir()
+	old := "/mnt/nas/grain"
+	note := "See [recording](" + old + "/2025-01-15/m1.mp4)\n"
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "m1", Extra: map[string]any{"clip": old + "/2025-01-15/m1.mp4"}})
+	os.WriteFile(filepath.Join(dir, "2025-01-15", "m1.md"), []byte(note), 0o600)
+	os.WriteFile(filepath.Join(dir, "2025-01-15", "m1.transcript.txt"), []byte(old), 0o600)
+	zs, _ := compressArtifact("zstd", []byte(`{"src": "`+old+`/2025-01-15/m1.mp4"}`))
+	os.WriteFile(filepath.Join(dir, "2025-01-15", "m1.highlights.json.zst"), zs, 0o600)
+	os.WriteFile(filepath.Join(dir, ".graindl-sync-state.json"), []byte(`{"files": {"`+old+`/2025-01-15/m1.md": {}}}`), 0o600)
+	os.WriteFile(filepath.Join(session, relinkDriveState), []byte(`{"files": {"`+old+`/2025-01-15/m1.json": {}}}`), 0o600)
+	sealedNote := filepath.Join(dir, "2025-01-16", "m2.md")
+	os.MkdirAll(filepath.Dir(sealedNote), 0o755)
+	os.WriteFile(sealedNote, []byte(note), sealedPerm)
+
+	args := []string{"--from", old + "/", "--to", dir, "--session-dir", session}
+	if code := runRelink(append(args, "--dry-run")); code != 1 {
+		t.Errorf("dry run with a sealed file: exit %d, want 1", code)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "2025-01-15", "m1.md")); string(data) != note {
+		t.Errorf("dry run wrote the note: %s", data)
+	}
+
+	runRelink(args)
+	for _, rel := range []string{"2025-01-15/m1.md", "2025-01-15/m1.json", "2025-01-15/m1.highlights.json.zst", ".graindl-sync-state.json"} {
+		data, err := readArtifact(filepath.Join(dir, rel))
+		if err != nil || strings.Contains(string(data), old) || !strings.Contains(string(data), dir+"/2025-01-15/") {
+			t.Errorf("%s = %s, %v", rel, data, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(session, relinkDriveState)); !strings.Contains(string(data), dir+"/2025-01-15/m1.json") {
+		t.Errorf("drive state = %s", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "2025-01-15", "m1.transcript.txt")); string(data) != old {
+		t.Errorf("transcript rewritten: %s", data)
+	}
+	if data, _ := os.ReadFile(sealedNote); string(data) != note {
+		t.Errorf("sealed note rewritten: %s", data)
+	}
+
+	if code := runRelink([]string{"--from", "relative", "--to", dir}); code != 2 {
+		t.Errorf("relative --from: exit %d, want 2", code)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// ── Alternate and Remote Browsers ───────────────────────────────────────────
+//
+// --browser-bin launches a specific Chromium, Chrome, or Edge binary instead
+// of the Chromium Rod downloads. --browser-remote attaches to a browser that
+// is already running (a browserless or chromedp container, a shared browser
+// farm) over the DevTools protocol, so the graindl container needs no browser
+// of its own. A remote browser keeps its own profile: the Grain login lives
+// there, not in --session-dir, and --headless and --clean-session don't
+// apply. graindl never closes a remote browser; it closes its page and drops
+// the connection.
+
+// validateBrowserRemote checks a --browser-remote value: a ws(s) DevTools
+// URL, or the http(s) address of a browser's debugging port.
+func validateBrowserRemote(remote string) error {
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid --browser-remote %q: want ws://host:port or http://host:port", remote)
+	}
+	switch u.Scheme {
+	case "ws", "wss", "http", "https":
+		return nil
+	}
+	return fmt.Errorf("invalid --browser-remote %q: scheme must be ws, wss, http, or https", remote)
+}
+
+// validateBrowserBin checks that --browser-bin names an executable file.
+func validateBrowserBin(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("--browser-bin: %w", err)
+	}
+	if info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("--browser-bin: %s is not an executable file", path)
+	}
+	return nil
+}
+
+// browserControlURL turns a --browser-remote value into a DevTools
+// WebSocket URL. A bare host:port is resolved through its /json/version
+// endpoint; a URL with a path or query (a /devtools/browser/<id> URL, a
+// browserless ?token=) is used as given.
+func browserControlURL(remote string) (string, error) {
+	u, err := url.Parse(remote)
+	if err != nil {
+		return "", err
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		if u.Scheme == "http" || u.Scheme == "https" {
+			return "", fmt.Errorf("%s: a DevTools URL with a path must use ws:// or wss://", remote)
+		}
+		return remote, nil
+	}
+	// ResolveURL panics on a malformed /json/version answer.
+	var resolved string
+	if tryErr := rod.Try(func() { resolved, err = launcher.ResolveURL(remote) }); tryErr != nil {
+		err = tryErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", remote, err)
+	}
+	r, err := url.Parse(resolved)
+	if err != nil || (r.Scheme != "ws" && r.Scheme != "wss") {
+		return "", fmt.Errorf("resolve %s: no DevTools WebSocket URL in /json/version", remote)
+	}
+	// Chrome reports a ws:// URL; behind a TLS proxy, keep the TLS.
+	if u.Scheme == "wss" || u.Scheme == "https" {
+		r.Scheme = "wss"
+	}
+	return r.String(), nil
+}
+
+// connectRemote connects to the browser at remote and returns it with the
+// DevTools connection, which the caller closes instead of the browser.
+func connectRemote(ctx context.Context, remote string) (*rod.Browser, *cdp.WebSocket, error) {
+	u, err := browserControlURL(remote)
+	if err != nil {
+		return nil, nil, err
+	}
+	ws := &cdp.WebSocket{}
+	if err := ws.Connect(ctx, u, nil); err != nil {
+		return nil, nil, fmt.Errorf("connect %s: %w", remote, err)
+	}
+	b := rod.New().Context(ctx).Client(cdp.New().Start(ws))
+	if err := b.Connect(); err != nil {
+		_ = ws.Close()
+		return nil, nil, fmt.Errorf("connect %s: %w", remote, err)
+	}
+	return b, ws, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateBrowserRemote(t *testing.T) {
+	for remote, ok := range map[string]bool{
+		"ws://chrome:9222":                     true,
+		"wss://browserless.internal?token=abc": true,
+		"http://127.0.0.1:9222":                true,
+		"chrome:9222":                          false,
+		"ftp://chrome:9222":                    false,
+		"ws://":                                false,
+	} {
+		if err := validateBrowserRemote(remote); (err == nil) != ok {
+			t.Errorf("%q: err = %v, want ok=%v", remote, err, ok)
+		}
+	}
+}
+
+func TestValidateBrowserBin(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "chrome")
+	plain := filepath.Join(dir, "notes.txt")
+	os.WriteFile(exe, []byte("#!/bin/sh\n"), 0o755)
+	os.WriteFile(plain, nil, 0o644)
+
+	if err := validateBrowserBin(exe); err != nil {
+		t.Errorf("executable: %v", err)
+	}
+	for _, path := range []string{plain, dir, filepath.Join(dir, "missing")} {
+		if err := validateBrowserBin(path); err == nil {
+			t.Errorf("%s accepted", path)
+		}
+	}
+}
+
+func TestBrowserControlURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/json/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"Browser":"HeadlessChrome/120","webSocketDebuggerUrl":"ws://127.0.0.1:9222/devtools/browser/abc"}`))
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	for _, remote := range []string{srv.URL, "ws://" + host, "ws://" + host + "/"} {
+		got, err := browserControlURL(remote)
+		if want := "ws://" + host + "/devtools/browser/abc"; err != nil || got != want {
+			t.Errorf("%s: got %q, %v; want %q", remote, got, err, want)
+		}
+	}
+
+	direct := "ws://" + host + "/devtools/browser/xyz"
+	if got, err := browserControlURL(direct); err != nil || got != direct {
+		t.Errorf("DevTools URL: got %q, %v", got, err)
+	}
+	token := "wss://browserless.internal?token=abc"
+	if got, err := browserControlURL(token); err != nil || got != token {
+		t.Errorf("token URL: got %q, %v", got, err)
+	}
+	if _, err := browserControlURL(srv.URL + "/devtools/browser/xyz"); err == nil {
+		t.Error("http URL with a path accepted")
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer empty.Close()
+	if _, err := browserControlURL(empty.URL); err == nil {
+		t.Error("missing webSocketDebuggerUrl accepted")
+	}
+}
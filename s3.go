@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── S3-compatible Object URLs ───────────────────────────────────────────────
+//
+// Stdlib-only AWS Signature Version 4 for S3 and compatible stores (MinIO,
+// Cloudflare R2, Backblaze B2, Wasabi). Presigned GET URLs are computed
+// locally from the credentials, with no request to the bucket, so anyone
+// holding the URL can download that one object until it expires.
+
+// s3MaxPresignExpiry is the longest lifetime SigV4 allows a presigned URL.
+const s3MaxPresignExpiry = 7 * 24 * time.Hour
+
+// s3Config locates a bucket and holds its credentials.
+type s3Config struct {
+	Bucket    string
+	Prefix    string // key prefix the archive is stored under
+	Endpoint  string // empty = AWS (https://s3.<region>.amazonaws.com)
+	Region    string
+	PathStyle bool // https://endpoint/bucket/key instead of https://bucket.endpoint/key
+
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // temporary (STS) credentials only
+}
+
+// loadS3Credentials reads credentials and region from env/.env, preferring
+// the GRAIN_S3_* names over the standard AWS_* ones. Secrets are never
+// taken from flags.
+func loadS3Credentials(c *s3Config, dotenv map[string]string) {
+	c.AccessKeyID = coalesce(envGet(dotenv, "GRAIN_S3_ACCESS_KEY_ID"), envGet(dotenv, "AWS_ACCESS_KEY_ID"))
+	c.SecretAccessKey = coalesce(envGet(dotenv, "GRAIN_S3_SECRET_ACCESS_KEY"), envGet(dotenv, "AWS_SECRET_ACCESS_KEY"))
+	c.SessionToken = coalesce(envGet(dotenv, "GRAIN_S3_SESSION_TOKEN"), envGet(dotenv, "AWS_SESSION_TOKEN"))
+}
+
+// validate fills defaults and checks that c can sign requests. A custom
+// endpoint uses path-style URLs, which every S3-compatible store accepts;
+// AWS uses virtual-hosted URLs unless the bucket name has dots (which break
+// TLS for bucket.s3 hostnames).
+func (c *s3Config) validate() error {
+	if c.Bucket == "" {
+		return fmt.Errorf("--s3-bucket is required")
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return fmt.Errorf("S3 credentials missing: set GRAIN_S3_ACCESS_KEY_ID and GRAIN_S3_SECRET_ACCESS_KEY (or AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY)")
+	}
+	c.Region = coalesce(c.Region, "us-east-1")
+	if c.Endpoint == "" {
+		c.Endpoint = "https://s3." + c.Region + ".amazonaws.com"
+		c.PathStyle = strings.Contains(c.Bucket, ".")
+	} else {
+		c.PathStyle = true
+	}
+	u, err := url.Parse(c.Endpoint)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("--s3-endpoint must be an http(s) URL, got %q", c.Endpoint)
+	}
+	c.Prefix = strings.Trim(c.Prefix, "/")
+	return nil
+}
+
+// objectKey returns the bucket key for a path relative to the archive root.
+func (c *s3Config) objectKey(relPath string) string {
+	key := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(relPath, `\`, "/")), "/")
+	if c.Prefix == "" {
+		return key
+	}
+	return c.Prefix + "/" + key
+}
+
+// objectURL returns the unsigned URL of key.
+func (c *s3Config) objectURL(key string) *url.URL {
+	u, _ := url.Parse(c.Endpoint) // checked by validate
+	base := strings.TrimSuffix(u.Path, "/")
+	if c.PathStyle {
+		u.Path = base + "/" + c.Bucket + "/" + key
+	} else {
+		u.Host = c.Bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	u.RawQuery = ""
+	return u
+}
+
+// presignGet returns a URL that downloads key until now+expires.
+func (c *s3Config) presignGet(key string, expires time.Duration, now time.Time) (string, error) {
+	if expires <= 0 || expires > s3MaxPresignExpiry {
+		return "", fmt.Errorf("expiry must be between 1s and %s, got %s", s3MaxPresignExpiry, expires)
+	}
+	u := c.objectURL(key)
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + c.Region + "/s3/aws4_request"
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":    c.AccessKeyID + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(expires.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	if c.SessionToken != "" {
+		query["X-Amz-Security-Token"] = c.SessionToken
+	}
+	canonicalQuery := s3CanonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		"GET",
+		u.RawPath,
+		canonicalQuery,
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+
+	signature := hex.EncodeToString(hmacSHA256(c.signingKey(now), stringToSign))
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + signature
+	return u.String(), nil
+}
+
+// signRequest adds SigV4 Authorization headers to req, a request for one
+// object with no query string. payloadHash is the hex SHA-256 of the body.
+// Host, Content-MD5, Content-Type, and every X-Amz-* header already on req
+// are signed.
+func (c *s3Config) signRequest(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + c.Region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+	}
+	values := map[string]string{"host": req.URL.Host}
+	for name, vals := range req.Header {
+		if name = strings.ToLower(name); name == "content-md5" || name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			values[name] = strings.TrimSpace(strings.Join(vals, ","))
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := make([]string, len(names))
+	for i, name := range names {
+		headers[i] = name + ":" + values[name]
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		strings.Join(headers, "\n") + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+	signature := hex.EncodeToString(hmacSHA256(c.signingKey(now), stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// signingKey derives the SigV4 key for the UTC day of now.
+func (c *s3Config) signingKey(now time.Time) []byte {
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), now.Format("20060102"))
+	for _, part := range []string{c.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	return key
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3CanonicalQuery encodes params sorted by name, as SigV4 requires.
+func s3CanonicalQuery(params map[string]string) string {
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, k := range names {
+		parts[i] = s3Escape(k, true) + "=" + s3Escape(params[k], true)
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath URI-encodes each segment of an object path.
+func s3EscapePath(p string) string {
+	return s3Escape(p, false)
+}
+
+// s3Escape percent-encodes everything but RFC 3986 unreserved characters
+// (and '/', unless encodeSlash).
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
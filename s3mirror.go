@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── S3 Mirror ───────────────────────────────────────────────────────────────
+//
+// --s3-bucket mirrors the archive to an S3-compatible bucket (AWS, MinIO,
+// Cloudflare R2, ...) with SigV4-signed PUT requests under --s3-prefix.
+// Credentials come from GRAIN_S3_* or AWS_* (env or .env only). Like iCloud,
+// uploads are incremental: a sync state in the output directory records the
+// SHA-256 of every object written, and unchanged files are not sent again.
+//
+// The bucket is a mirror, not a replacement for local disk: the local
+// output directory stays the primary copy, since browser downloads and
+// ffmpeg need a filesystem to write to, and skip checks read it.
+//
+// With --immutable, each meeting's artifacts (the files in its date
+// directory) are uploaded under S3 Object Lock: COMPLIANCE retention until
+// --retention, or a legal hold when the hold is indefinite. The bucket must
+// have Object Lock enabled. Aggregate files (manifest, delta, tasks.md,
+// highlight pages) are rewritten every run and are not locked, as they are
+// not sealed locally.
+
+// s3SyncStateFile is the S3 sync state, kept in the output directory.
+const s3SyncStateFile = ".graindl-s3-sync-state.json"
+
+// s3MaxPutSize is the largest object a single PUT may upload.
+const s3MaxPutSize = 5 << 30
+
+// S3Mirror implements Mirror for an S3-compatible bucket.
+type S3Mirror struct {
+	cfg       *s3Config
+	localRoot string
+	roots     OutputRoots // routed artifact classes, for compaction
+	client    *http.Client
+	now       func() time.Time // signing clock; replaced in tests
+
+	immutable   bool      // --immutable: Object Lock on meeting artifacts
+	retainUntil time.Time // --retention; zero = legal hold
+
+	mu    sync.Mutex // protects state
+	state *SyncState
+}
+
+// NewS3Mirror returns a mirror of localRoot in the bucket c, which must
+// have been validated. It loads any existing sync state from localRoot.
+func NewS3Mirror(localRoot string, c *s3Config) *S3Mirror {
+	statePath := filepath.Join(localRoot, s3SyncStateFile)
+	state := loadSyncState(statePath)
+	slog.Debug("S3 sync state loaded", "files", len(state.Files), "path", statePath)
+	return &S3Mirror{
+		cfg:       c,
+		localRoot: localRoot,
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 60 * time.Second,
+		}},
+		now:   time.Now,
+		state: state,
+	}
+}
+
+func (s *S3Mirror) Name() string { return "s3" }
+
+// Put uploads data to relPath unless the sync state already has the same
+// content there.
+func (s *S3Mirror) Put(relPath string, data []byte) error {
+	hash := computeSHA256(data)
+	contentType := classifyContent(relPath)
+
+	s.mu.Lock()
+	existing := s.state.Files[relPath]
+	s.mu.Unlock()
+	if existing != nil && existing.SHA256 == hash {
+		slog.Debug("S3 skip (unchanged)", "path", relPath)
+		return nil
+	}
+	if existing != nil && resolveConflict(contentType, existing, data) == conflictSkip {
+		slog.Debug("S3 skip (conflict: keep existing)", "path", relPath, "type", contentType)
+		return nil
+	}
+
+	var sum []byte
+	if s.locked(relPath) {
+		d := md5.Sum(data)
+		sum = d[:]
+	}
+	if err := s.put(relPath, bytes.NewReader(data), int64(len(data)), hash, sum); err != nil {
+		return err
+	}
+	s.track(relPath, hash, int64(len(data)), contentType)
+	return nil
+}
+
+// PutFile uploads the local file absPath to relPath unless the sync state
+// already has the same content there. The file is hashed before the upload
+// (SigV4 signs the payload hash) and then streamed.
+func (s *S3Mirror) PutFile(relPath, absPath string) error {
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return fmt.Errorf("stat source: %w", err)
+	}
+	if info.Size() > s3MaxPutSize {
+		return fmt.Errorf("s3 put %s: %s is over the %s single-upload limit", relPath, formatBytes(info.Size()), formatBytes(s3MaxPutSize))
+	}
+	hash, err := hashFileOnDisk(absPath)
+	if err != nil {
+		return fmt.Errorf("hash source: %w", err)
+	}
+
+	s.mu.Lock()
+	existing := s.state.Files[relPath]
+	s.mu.Unlock()
+	if existing != nil && existing.SHA256 == hash {
+		slog.Debug("S3 skip (unchanged)", "path", relPath)
+		return nil
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var sum []byte
+	if s.locked(relPath) {
+		h := md5.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return fmt.Errorf("hash source: %w", err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		sum = h.Sum(nil)
+	}
+	if err := s.put(relPath, f, info.Size(), hash, sum); err != nil {
+		return err
+	}
+	s.track(relPath, hash, info.Size(), classifyContent(relPath))
+	return nil
+}
+
+// Mkdir is a no-op: buckets have no directories.
+func (s *S3Mirror) Mkdir(string) error { return nil }
+
+// Close persists the sync state to the output directory.
+func (s *S3Mirror) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if compactDue(s.state.CompactedAt) {
+		if n := compactSyncFiles(s.state.Files, s.localRoot, s.roots); n > 0 {
+			slog.Info("S3 sync state compacted", "dropped", n)
+		}
+		s.state.CompactedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if err := saveSyncState(filepath.Join(s.localRoot, s3SyncStateFile), s.state); err != nil {
+		return fmt.Errorf("save s3 sync state: %w", err)
+	}
+	slog.Debug("S3 sync state saved", "files", len(s.state.Files))
+	return nil
+}
+
+func (s *S3Mirror) track(relPath, hash string, size int64, contentType string) {
+	s.mu.Lock()
+	s.state.Files[relPath] = &SyncFileEntry{
+		SHA256:      hash,
+		Size:        size,
+		ModifiedAt:  time.Now().UTC().Format(time.RFC3339),
+		ContentType: contentType,
+	}
+	s.mu.Unlock()
+	slog.Debug("S3 written", "path", relPath, "size", size)
+}
+
+// locked reports whether relPath is uploaded under Object Lock: a meeting
+// artifact in a date directory, with --immutable.
+func (s *S3Mirror) locked(relPath string) bool {
+	if !s.immutable {
+		return false
+	}
+	dir := filepath.ToSlash(filepath.Dir(relPath))
+	dir = strings.TrimPrefix(dir, sharedDir+"/")
+	_, err := time.Parse(time.DateOnly, dir)
+	return err == nil
+}
+
+// put uploads body to relPath with the file's MIME type, so browsers and
+// presigned share links serve it as what it is. md5sum, when set, is sent
+// as Content-MD5 (which S3 requires for Object Lock) along with the lock
+// headers.
+func (s *S3Mirror) put(relPath string, body io.Reader, size int64, hash string, md5sum []byte) error {
+	key := s.cfg.objectKey(relPath)
+	req, err := http.NewRequest(http.MethodPut, s.cfg.objectURL(key).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", detectMIME(relPath))
+	if md5sum != nil {
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5sum))
+		if s.retainUntil.IsZero() {
+			req.Header.Set("X-Amz-Object-Lock-Legal-Hold", "ON")
+		} else {
+			req.Header.Set("X-Amz-Object-Lock-Mode", "COMPLIANCE")
+			req.Header.Set("X-Amz-Object-Lock-Retain-Until-Date", s.retainUntil.UTC().Format(time.RFC3339))
+		}
+	}
+	s.cfg.signRequest(req, hash, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3 put: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 put %s: %s %s", key, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 records the PUTs it receives.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	headers map[string]http.Header // object path → request headers
+	puts    int
+	status  int
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{objects: map[string]string{}, headers: map[string]http.Header{}, status: http.StatusOK}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Method != http.MethodPut || r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/20240301/us-east-1/s3/aws4_request, SignedHeaders=") {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.headers[r.URL.Path] = r.Header.Clone()
+		f.puts++
+		if f.status != http.StatusOK {
+			http.Error(w, "<Error><Code>SlowDown</Code></Error>", f.status)
+			return
+		}
+		f.objects[r.URL.Path] = string(body)
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func newTestS3Mirror(t *testing.T, endpoint, root string) *S3Mirror {
+	c := &s3Config{Bucket: "grain", Prefix: "/archive/", Endpoint: endpoint, AccessKeyID: "minio", SecretAccessKey: "secret"}
+	if err := c.validate(); err != nil {
+		t.Fatal(err)
+	}
+	s := NewS3Mirror(root, c)
+	s.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func TestS3MirrorPutIncremental(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	root := t.TempDir()
+	s := newTestS3Mirror(t, srv.URL, root)
+
+	rel := filepath.Join("2024-03-01", "Weekly Sync.json")
+	if err := s.Put(rel, []byte(`{"id":"m1"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.objects["/grain/archive/2024-03-01/Weekly Sync.json"]; got != `{"id":"m1"}` {
+		t.Errorf("objects = %v", fake.objects)
+	}
+	h := fake.headers["/grain/archive/2024-03-01/Weekly Sync.json"]
+	if got := signedHeaders(h); got != "content-type;host;x-amz-content-sha256;x-amz-date" {
+		t.Errorf("signed headers = %s", got)
+	}
+	if ct := h.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if err := s.Put(rel, []byte(`{"id":"m1"}`)); err != nil || fake.puts != 1 {
+		t.Errorf("unchanged content re-uploaded: puts = %d, err = %v", fake.puts, err)
+	}
+	if err := s.Put(rel, []byte(`{"id":"m1","title":"x"}`)); err != nil || fake.puts != 2 {
+		t.Errorf("changed content not uploaded: puts = %d, err = %v", fake.puts, err)
+	}
+
+	// A failed upload is not tracked, so the next write tries again.
+	fake.status = http.StatusServiceUnavailable
+	if err := s.Put("2024-03-01/b.json", []byte("{}")); err == nil || !strings.Contains(err.Error(), "SlowDown") {
+		t.Errorf("err = %v", err)
+	}
+	fake.status = http.StatusOK
+	if err := s.Put("2024-03-01/b.json", []byte("{}")); err != nil || fake.puts != 4 {
+		t.Errorf("retry after failure: puts = %d, err = %v", fake.puts, err)
+	}
+}
+
+func TestS3MirrorPutFileAndState(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	root := t.TempDir()
+	writeArchiveFile(t, root, "2024-03-01/call.mp4", "video bytes", 0)
+	writeArchiveFile(t, root, "2024-03-01/call.json", "{}", 0)
+
+	s := newTestS3Mirror(t, srv.URL, root)
+	for _, rel := range []string{"2024-03-01/call.mp4", "2024-03-01/call.json"} {
+		if err := s.PutFile(rel, filepath.Join(root, rel)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fake.objects["/grain/archive/2024-03-01/call.mp4"] != "video bytes" {
+		t.Errorf("objects = %v", fake.objects)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(root, s3SyncStateFile)); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("sync state: %v, %v", info, err)
+	}
+
+	// A new run loads the state and skips what was already uploaded.
+	s = newTestS3Mirror(t, srv.URL, root)
+	if err := s.PutFile("2024-03-01/call.mp4", filepath.Join(root, "2024-03-01/call.mp4")); err != nil || fake.puts != 2 {
+		t.Errorf("reloaded state: puts = %d, err = %v", fake.puts, err)
+	}
+	if e := s.state.Files["2024-03-01/call.mp4"]; e == nil || e.ContentType != "video" || e.Size != int64(len("video bytes")) {
+		t.Errorf("state entry = %+v", e)
+	}
+}
+
+func TestNewStorageS3Mirror(t *testing.T) {
+	cfg := &Config{OutputDir: t.TempDir(), S3: s3Config{Bucket: "grain", AccessKeyID: "a", SecretAccessKey: "s"}}
+	if err := cfg.S3.validate(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newStorage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, ok := s.(*MultiStorage)
+	if !ok || len(ms.mirrors) != 1 || ms.mirrors[0].Name() != "s3" {
+		t.Errorf("storage = %#v", s)
+	}
+}
+
+// signedHeaders returns the SignedHeaders list of a SigV4 Authorization.
+func signedHeaders(h http.Header) string {
+	_, rest, _ := strings.Cut(h.Get("Authorization"), "SignedHeaders=")
+	list, _, _ := strings.Cut(rest, ",")
+	return list
+}
+
+func TestS3MirrorObjectLock(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	root := t.TempDir()
+	writeArchiveFile(t, root, "2024-03-01/call.mp4", "video bytes", 0)
+
+	s := newTestS3Mirror(t, srv.URL, root)
+	s.immutable, s.retainUntil = true, time.Date(2031, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := s.PutFile("2024-03-01/call.mp4", filepath.Join(root, "2024-03-01/call.mp4")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put("_export-manifest.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	h := fake.headers["/grain/archive/2024-03-01/call.mp4"]
+	sum := md5.Sum([]byte("video bytes"))
+	if h.Get("X-Amz-Object-Lock-Mode") != "COMPLIANCE" || h.Get("X-Amz-Object-Lock-Retain-Until-Date") != "2031-03-01T00:00:00Z" ||
+		h.Get("Content-MD5") != base64.StdEncoding.EncodeToString(sum[:]) || h.Get("Content-Type") != "video/mp4" {
+		t.Errorf("lock headers = %v", h)
+	}
+	if got := signedHeaders(h); got != "content-md5;content-type;host;x-amz-content-sha256;x-amz-date;x-amz-object-lock-mode;x-amz-object-lock-retain-until-date" {
+		t.Errorf("signed headers = %s", got)
+	}
+	if h := fake.headers["/grain/archive/_export-manifest.json"]; h.Get("X-Amz-Object-Lock-Mode") != "" || h.Get("Content-MD5") != "" {
+		t.Errorf("aggregate file locked: %v", h)
+	}
+
+	// An indefinite hold is a legal hold.
+	s.retainUntil = time.Time{}
+	if err := s.Put("shared/2024-03-02/m2.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if h := fake.headers["/grain/archive/shared/2024-03-02/m2.json"]; h.Get("X-Amz-Object-Lock-Legal-Hold") != "ON" || h.Get("X-Amz-Object-Lock-Mode") != "" {
+		t.Errorf("legal hold headers = %v", h)
+	}
+}
@@ -13,7 +13,7 @@ import (
 )
 
 const (
-	grainSearchURL    = "https://grain.com/app/search?q="
+	grainSearchPath   = "/app/search?q="
 	searchResultSel   = `div[role="link"]`  // broad — UUID filter is the real gate
 	titleWithinSel    = `[dir="auto"]`      // used within a result element
 	noResultsSel      = `text="No results"` // early exit when search has no matches
@@ -32,37 +32,70 @@ type SearchResult struct {
 // Returns a slice of SearchResults containing meeting IDs that can be
 // fed into the export pipeline.
 func (b *Browser) Search(ctx context.Context, query string) ([]SearchResult, error) {
+	out := make(chan SearchResult)
+	errc := make(chan error, 1)
+	go func() { errc <- b.SearchStream(ctx, query, out) }()
+	var results []SearchResult
+	for r := range out {
+		results = append(results, r)
+	}
+	return results, <-errc
+}
+
+// SearchStream is Search, but sends each match on out as soon as it is
+// rendered instead of waiting for the page to finish scrolling. out is
+// closed when the search ends.
+func (b *Browser) SearchStream(ctx context.Context, query string, out chan<- SearchResult) error {
+	defer close(out)
 	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+		return fmt.Errorf("search query cannot be empty")
 	}
 
-	searchURL := grainSearchURL + url.QueryEscape(query)
+	searchURL := grainPageURL(grainSearchPath) + url.QueryEscape(query)
 	slog.Info("searching grain", "query", query, "url", searchURL)
 
 	page, err := b.newPage(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("creating search page: %w", err)
+		return fmt.Errorf("creating search page: %w", err)
 	}
 	defer page.Close()
 
 	// Navigate with context-aware timeout.
 	if err := b.navigate(ctx, page, searchURL, searchTimeout); err != nil {
-		return nil, fmt.Errorf("navigating to search: %w", err)
+		return fmt.Errorf("navigating to search: %w", err)
 	}
 
 	// Wait for results to render — or timeout if no results.
 	if err := b.waitForResults(ctx, page); err != nil {
 		slog.Warn("no search results found", "query", query, "err", err)
-		return nil, nil // no results is not an error
+		return nil // no results is not an error
 	}
 
-	// Scroll to load all results (Grain likely uses infinite scroll).
-	if err := b.scrollToEnd(ctx, page); err != nil {
+	seen := make(map[string]bool)
+	emit := func() error {
+		results, err := b.extractResults(ctx, page, seen)
+		for _, r := range results {
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return err
+	}
+	if err := emit(); err != nil {
+		return err
+	}
+
+	// Scroll to load all results (Grain likely uses infinite scroll),
+	// emitting new matches after every scroll.
+	if err := b.scrollToEnd(ctx, page, emit); err != nil {
 		slog.Warn("scroll incomplete", "err", err)
 		// Continue with what we have.
 	}
 
-	return b.extractResults(ctx, page)
+	slog.Info("search complete", "query_results", len(seen))
+	return ctx.Err()
 }
 
 // waitForResults waits for at least one search result to appear,
@@ -96,14 +129,13 @@ func (b *Browser) waitForResults(ctx context.Context, page *rod.Page) error {
 }
 
 // scrollToEnd scrolls the page until no new results appear, handling
-// infinite scroll / lazy loading.
-func (b *Browser) scrollToEnd(ctx context.Context, page *rod.Page) error {
-	const maxScrolls = 50
-
+// infinite scroll / lazy loading. onScroll runs after each scroll that
+// rendered more results.
+func (b *Browser) scrollToEnd(ctx context.Context, page *rod.Page, onScroll func() error) error {
 	prevCount := 0
 	stableRounds := 0
 
-	for i := range maxScrolls {
+	for i := range b.depth.maxScrolls {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -126,30 +158,32 @@ func (b *Browser) scrollToEnd(ctx context.Context, page *rod.Page) error {
 		count := len(elements)
 		if count == prevCount {
 			stableRounds++
-			if stableRounds >= 3 {
+			if stableRounds >= b.depth.stableRounds {
 				slog.Debug("scroll complete", "total_results", count, "scrolls", i+1)
 				return nil
 			}
 		} else {
 			stableRounds = 0
 			prevCount = count
+			if err := onScroll(); err != nil {
+				return err
+			}
 		}
 	}
 
-	slog.Warn("hit max scroll limit", "max", maxScrolls, "results", prevCount)
+	slog.Warn("hit max scroll limit", "max", b.depth.maxScrolls, "results", prevCount)
 	return nil
 }
 
-// extractResults pulls meeting IDs and titles from the rendered search page.
-func (b *Browser) extractResults(ctx context.Context, page *rod.Page) ([]SearchResult, error) {
+// extractResults pulls meeting IDs and titles from the rendered search page,
+// skipping IDs already in seen and adding the new ones to it.
+func (b *Browser) extractResults(ctx context.Context, page *rod.Page, seen map[string]bool) ([]SearchResult, error) {
 	links, err := page.Elements(searchResultSel)
 	if err != nil {
 		return nil, fmt.Errorf("querying result elements: %w", err)
 	}
 
 	var results []SearchResult
-	seen := make(map[string]bool)
-
 	for _, link := range links {
 		select {
 		case <-ctx.Done():
@@ -181,7 +215,6 @@ func (b *Browser) extractResults(ctx context.Context, page *rod.Page) ([]SearchR
 		})
 	}
 
-	slog.Info("search complete", "query_results", len(results))
 	return results, nil
 }
 
@@ -252,6 +285,11 @@ func (b *Browser) newPage(ctx context.Context) (*rod.Page, error) {
 		page.Close()
 		return nil, fmt.Errorf("applying stealth settings: %w", err)
 	}
+	if err := identifyPage(b.cfg, page); err != nil {
+		page.Close()
+		return nil, err
+	}
+	b.attachHTTPTap(page)
 
 	return page, nil
 }
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ── Archive Server ──────────────────────────────────────────────────────────
+//
+// `graindl serve` exposes the local archive over a read-only REST API, so
+// internal tools can use it without access to the disk it lives on:
+//
+//	GET /api/meetings                      list (?q=, since=, until=, limit=, offset=)
+//	GET /api/meetings/{id}                 metadata JSON
+//	GET /api/meetings/{id}/transcript      transcript, text/plain
+//	GET /api/meetings/{id}/highlights      highlights JSON
+//	GET /api/meetings/{id}/video           video or audio, with Range support
+//	GET /healthz                           liveness
+//
+// Nothing is ever written. The meeting index is a scan of the metadata files
+// (see archive.go), refreshed when it is older than serveIndexTTL or a
+// requested ID isn't in it, so meetings exported while the server runs show
+// up without a restart. Compressed artifacts (--compress) are served
+// decompressed. With --data-output or --video-output roots (outputroots.go),
+// pass the same flags here. It listens on loopback by default; with
+// GRAIN_SERVE_TOKEN set, every request except /healthz needs
+// "Authorization: Bearer <token>".
+
+const (
+	serveDefaultAddr  = "127.0.0.1:8089"
+	serveIndexTTL     = 30 * time.Second
+	serveDefaultLimit = 100
+	serveMaxLimit     = 1000
+)
+
+// serveVideoExts are the media files served for a meeting, best first.
+var serveVideoExts = []string{".mp4", ".webm", ".mkv", ".mov", ".m4a"}
+
+// serveMediaTypes names media types Go's mime table may not know.
+var serveMediaTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".mov":  "video/quicktime",
+	".m4a":  "audio/mp4",
+}
+
+// ServeMeeting is one meeting in the /api/meetings list.
+type ServeMeeting struct {
+	ID              string   `json:"id"`
+	Title           string   `json:"title"`
+	Date            string   `json:"date,omitempty"`
+	DurationSeconds float64  `json:"duration_seconds,omitempty"`
+	Participants    []string `json:"participants,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	HasTranscript   bool     `json:"has_transcript"`
+	HasVideo        bool     `json:"has_video"`
+	URL             string   `json:"url"` // this meeting's API path
+}
+
+// ServeMeetingList is the /api/meetings response.
+type ServeMeetingList struct {
+	Total    int            `json:"total"` // matches before limit/offset
+	Meetings []ServeMeeting `json:"meetings"`
+}
+
+// archiveServer answers API requests from an archive on disk.
+type archiveServer struct {
+	dataDir  string // metadata, transcripts, highlights
+	videoDir string // videos and audio
+	token    string
+
+	mu      sync.Mutex
+	entries []*ArchiveEntry
+	byID    map[string]*ArchiveEntry
+	scanned time.Time
+	now     func() time.Time
+}
+
+func runServe(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", coalesce(envGet(dotenv, "GRAIN_SERVE_ADDR"), serveDefaultAddr), "Address to listen on")
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to serve")
+	dataOutput := fs.String("data-output", envGet(dotenv, "GRAIN_DATA_OUTPUT"), "Metadata, transcripts, and highlights root, when exported with --data-output")
+	videoOutput := fs.String("video-output", envGet(dotenv, "GRAIN_VIDEO_OUTPUT"), "Video root, when exported with --video-output")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output (logs every request)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	s := newArchiveServer(coalesce(*dataOutput, *outputDir), coalesce(*videoOutput, *outputDir), envGet(dotenv, "GRAIN_SERVE_TOKEN"))
+	if _, err := os.Stat(s.dataDir); err != nil {
+		slog.Error("Archive directory not found", "path", s.dataDir)
+		return 1
+	}
+	host, _, err := net.SplitHostPort(*addr)
+	if err != nil {
+		slog.Error(fmt.Sprintf("invalid --addr %q: %v", *addr, err))
+		return 2
+	}
+	if s.token == "" && !isLoopbackHost(host) {
+		slog.Warn("Serving the archive beyond this machine without GRAIN_SERVE_TOKEN; anyone who can reach it can read every meeting", "addr", *addr)
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		slog.Error("Listen failed", "addr", *addr, "error", err)
+		return 1
+	}
+	srv := &http.Server{Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if n, err := s.refresh(); err == nil {
+		slog.Info("Serving archive (read-only)", "url", "http://"+ln.Addr().String()+"/api/meetings", "meetings", n, "auth", s.token != "")
+	}
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Server failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+func newArchiveServer(dataDir, videoDir, token string) *archiveServer {
+	return &archiveServer{dataDir: dataDir, videoDir: videoDir, token: token, now: time.Now}
+}
+
+// handler routes the API.
+func (s *archiveServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("GET /api/meetings", s.auth(s.listMeetings))
+	mux.HandleFunc("GET /api/meetings/{id}", s.auth(s.getMetadata))
+	mux.HandleFunc("GET /api/meetings/{id}/transcript", s.auth(s.getTranscript))
+	mux.HandleFunc("GET /api/meetings/{id}/highlights", s.auth(s.getHighlights))
+	mux.HandleFunc("GET /api/meetings/{id}/video", s.auth(s.getVideo))
+	return logRequests(mux)
+}
+
+// auth requires the bearer token when one is configured.
+func (s *archiveServer) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="graindl"`)
+				serveError(w, http.StatusUnauthorized, "missing or wrong bearer token")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// logRequests logs each request at debug level.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		slog.Debug("Request", "method", r.Method, "path", r.URL.Path, "range", r.Header.Get("Range"), "elapsed", time.Since(start).Round(time.Millisecond))
+	})
+}
+
+// refresh rescans the archive, returning the number of meetings.
+func (s *archiveServer) refresh() (int, error) {
+	entries, err := scanArchive(s.dataDir)
+	if err != nil {
+		return 0, err
+	}
+	byID := make(map[string]*ArchiveEntry, len(entries))
+	for _, a := range entries {
+		byID[a.Meta.ID] = a
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries, s.byID, s.scanned = entries, byID, s.now()
+	return len(entries), nil
+}
+
+// index returns the meetings, rescanning when the index is stale.
+func (s *archiveServer) index() ([]*ArchiveEntry, error) {
+	s.mu.Lock()
+	fresh := s.entries != nil && s.now().Sub(s.scanned) < serveIndexTTL
+	entries := s.entries
+	s.mu.Unlock()
+	if fresh {
+		return entries, nil
+	}
+	if _, err := s.refresh(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.entries, nil
+}
+
+// lookup finds a meeting by ID, rescanning once when it is not indexed.
+func (s *archiveServer) lookup(w http.ResponseWriter, r *http.Request) *ArchiveEntry {
+	id := r.PathValue("id")
+	if _, err := s.index(); err != nil {
+		serveError(w, http.StatusInternalServerError, "archive unreadable")
+		return nil
+	}
+	s.mu.Lock()
+	a := s.byID[id]
+	stale := a == nil && s.now().Sub(s.scanned) > time.Second
+	s.mu.Unlock()
+	if stale {
+		if _, err := s.refresh(); err == nil {
+			s.mu.Lock()
+			a = s.byID[id]
+			s.mu.Unlock()
+		}
+	}
+	if a == nil {
+		serveError(w, http.StatusNotFound, "no meeting "+strconv.Quote(id))
+	}
+	return a
+}
+
+func (s *archiveServer) listMeetings(w http.ResponseWriter, r *http.Request) {
+	entries, err := s.index()
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, "archive unreadable")
+		return
+	}
+	q := r.URL.Query()
+	var since, until time.Time
+	for name, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := q.Get(name); v != "" {
+			if *t, err = time.Parse("2006-01-02", v); err != nil {
+				serveError(w, http.StatusBadRequest, name+" must be YYYY-MM-DD")
+				return
+			}
+		}
+	}
+	limit, offset := serveDefaultLimit, 0
+	if v := q.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			serveError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+		limit = min(limit, serveMaxLimit)
+	}
+	if v := q.Get("offset"); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			serveError(w, http.StatusBadRequest, "offset must be zero or more")
+			return
+		}
+	}
+	text := strings.ToLower(strings.TrimSpace(q.Get("q")))
+
+	list := ServeMeetingList{Meetings: []ServeMeeting{}}
+	// Newest first, like the Grain library.
+	for i := len(entries) - 1; i >= 0; i-- {
+		a := entries[i]
+		d := a.Date()
+		if !since.IsZero() && d.Before(since) || !until.IsZero() && d.After(until) {
+			continue
+		}
+		m := s.summary(a)
+		if text != "" && !strings.Contains(strings.ToLower(m.Title+" "+strings.Join(m.Participants, " ")+" "+strings.Join(m.Tags, " ")), text) {
+			continue
+		}
+		list.Total++
+		if list.Total > offset && len(list.Meetings) < limit {
+			list.Meetings = append(list.Meetings, m)
+		}
+	}
+	serveJSON(w, list)
+}
+
+// summary builds a meeting's list entry.
+func (s *archiveServer) summary(a *ArchiveEntry) ServeMeeting {
+	_, hasVideo := s.videoPath(a)
+	return ServeMeeting{
+		ID:              a.Meta.ID,
+		Title:           a.Meta.Title,
+		Date:            a.Date().Format("2006-01-02"),
+		DurationSeconds: durationSeconds(a.Meta.DurationSeconds),
+		Participants:    flattenStringSlice(a.Meta.Participants),
+		Tags:            flattenStringSlice(a.Meta.Tags),
+		HasTranscript:   artifactOnDisk(filepath.Join(s.dataDir, a.RelBase+".transcript.txt")),
+		HasVideo:        hasVideo,
+		URL:             "/api/meetings/" + a.Meta.ID,
+	}
+}
+
+func (s *archiveServer) getMetadata(w http.ResponseWriter, r *http.Request) {
+	if a := s.lookup(w, r); a != nil {
+		s.serveArtifact(w, r, a.RelBase+".json", "application/json")
+	}
+}
+
+func (s *archiveServer) getTranscript(w http.ResponseWriter, r *http.Request) {
+	if a := s.lookup(w, r); a != nil {
+		s.serveArtifact(w, r, a.RelBase+".transcript.txt", "text/plain; charset=utf-8")
+	}
+}
+
+func (s *archiveServer) getHighlights(w http.ResponseWriter, r *http.Request) {
+	if a := s.lookup(w, r); a != nil {
+		s.serveArtifact(w, r, a.RelBase+".highlights.json", "application/json")
+	}
+}
+
+// serveArtifact streams a data file, decompressing a --compress artifact on
+// the way out rather than reading it into memory.
+func (s *archiveServer) serveArtifact(w http.ResponseWriter, r *http.Request, relPath, contentType string) {
+	rc, fi, err := openArtifact(filepath.Join(s.dataDir, relPath))
+	if errors.Is(err, os.ErrNotExist) {
+		serveError(w, http.StatusNotFound, filepath.Base(relPath)+" not exported")
+		return
+	}
+	if err != nil {
+		slog.Warn("Artifact unreadable", "path", relPath, "error", err)
+		serveError(w, http.StatusInternalServerError, "artifact unreadable")
+		return
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", contentType)
+	if f, ok := rc.(*os.File); ok {
+		http.ServeContent(w, r, filepath.Base(relPath), fi.ModTime(), f)
+		return
+	}
+	// A decompressing reader can't seek, so compressed artifacts are sent
+	// whole, without Range support.
+	w.Header().Set("Last-Modified", fi.ModTime().UTC().Format(http.TimeFormat))
+	if _, err := io.Copy(w, rc); err != nil {
+		slog.Warn("Artifact stream failed", "path", relPath, "error", err)
+	}
+}
+
+// videoPath returns the meeting's video (or audio) file.
+func (s *archiveServer) videoPath(a *ArchiveEntry) (string, bool) {
+	for _, ext := range serveVideoExts {
+		p := filepath.Join(s.videoDir, a.RelBase+ext)
+		if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// getVideo streams the video; http.ServeContent answers Range and
+// If-Range requests, so players can seek.
+func (s *archiveServer) getVideo(w http.ResponseWriter, r *http.Request) {
+	a := s.lookup(w, r)
+	if a == nil {
+		return
+	}
+	path, ok := s.videoPath(a)
+	if !ok {
+		serveError(w, http.StatusNotFound, "no video exported for this meeting")
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, "video unreadable")
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		serveError(w, http.StatusInternalServerError, "video unreadable")
+		return
+	}
+	if ct := serveMediaTypes[filepath.Ext(path)]; ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filepath.Base(path)))
+	http.ServeContent(w, r, filepath.Base(path), fi.ModTime(), f)
+}
+
+// artifactOnDisk reports whether path or its compressed form exists.
+func artifactOnDisk(path string) bool {
+	for _, ext := range append([]string{""}, compressedExts...) {
+		if _, err := os.Stat(path + ext); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func serveJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func serveError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newServeArchive writes two meetings; "a" has a transcript and a video.
+func newServeArchive(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "a", Title: "Acme kickoff", Participants: []any{"Ana"}})
+	writeArchiveMeta(t, dir, "2025-02-01", &Metadata{ID: "b", Title: "Weekly sync", Tags: []any{"team"}})
+	base := filepath.Join(dir, "2025-01-15", "a")
+	_ = os.WriteFile(base+".transcript.txt", []byte("Ana: hello\n"), 0o600)
+	_ = os.WriteFile(base+".mp4", []byte("0123456789"), 0o600)
+	return dir
+}
+
+func serveGet(t *testing.T, h http.Handler, target string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServeListMeetings(t *testing.T) {
+	dir := newServeArchive(t)
+	h := newArchiveServer(dir, dir, "").handler()
+
+	var list ServeMeetingList
+	rec := serveGet(t, h, "/api/meetings")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 2 || len(list.Meetings) != 2 || list.Meetings[0].ID != "b" {
+		t.Fatalf("list = %+v, want b then a", list)
+	}
+	a := list.Meetings[1]
+	if !a.HasTranscript || !a.HasVideo || a.Date != "2025-01-15" || a.URL != "/api/meetings/a" {
+		t.Errorf("a = %+v", a)
+	}
+	if list.Meetings[0].HasTranscript || list.Meetings[0].HasVideo {
+		t.Errorf("b = %+v, want no transcript or video", list.Meetings[0])
+	}
+
+	for _, tc := range []struct {
+		query string
+		total int
+		ids   []string
+	}{
+		{"q=ana", 1, []string{"a"}},
+		{"q=TEAM", 1, []string{"b"}},
+		{"since=2025-01-20", 1, []string{"b"}},
+		{"until=2025-01-20", 1, []string{"a"}},
+		{"limit=1", 2, []string{"b"}},
+		{"limit=1&offset=1", 2, []string{"a"}},
+		{"offset=5", 2, nil},
+	} {
+		list = ServeMeetingList{}
+		rec := serveGet(t, h, "/api/meetings?"+tc.query)
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+		var ids []string
+		for _, m := range list.Meetings {
+			ids = append(ids, m.ID)
+		}
+		if list.Total != tc.total || len(ids) != len(tc.ids) || len(ids) > 0 && ids[0] != tc.ids[0] {
+			t.Errorf("%s: total %d, ids %v; want %d, %v", tc.query, list.Total, ids, tc.total, tc.ids)
+		}
+	}
+
+	for _, q := range []string{"since=yesterday", "limit=0", "offset=-1"} {
+		if rec := serveGet(t, h, "/api/meetings?"+q); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestServeArtifacts(t *testing.T) {
+	dir := newServeArchive(t)
+	// A --compress'ed highlights file is served decompressed.
+	f, _ := os.Create(filepath.Join(dir, "2025-01-15", "a.highlights.json.gz"))
+	zw := gzip.NewWriter(f)
+	_, _ = zw.Write([]byte(`[{"text":"ship it"}]`))
+	_ = zw.Close()
+	_ = f.Close()
+	h := newArchiveServer(dir, dir, "").handler()
+
+	rec := serveGet(t, h, "/api/meetings/a")
+	var meta Metadata
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &meta) != nil || meta.Title != "Acme kickoff" {
+		t.Errorf("metadata: %d %s", rec.Code, rec.Body)
+	}
+	rec = serveGet(t, h, "/api/meetings/a/transcript")
+	if rec.Body.String() != "Ana: hello\n" || rec.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Errorf("transcript: %q %q", rec.Body, rec.Header().Get("Content-Type"))
+	}
+	if rec := serveGet(t, h, "/api/meetings/a/highlights"); rec.Body.String() != `[{"text":"ship it"}]` || rec.Header().Get("Last-Modified") == "" {
+		t.Errorf("highlights = %q %v", rec.Body, rec.Header())
+	}
+	// Plain artifacts are served from the file, so ranges work.
+	if rec := serveGet(t, h, "/api/meetings/a/transcript", "Range", "bytes=0-2"); rec.Code != http.StatusPartialContent || rec.Body.String() != "Ana" {
+		t.Errorf("transcript range: %d %q", rec.Code, rec.Body)
+	}
+	base := filepath.Join(dir, "2025-01-15", "a.transcript.txt")
+	if err := os.WriteFile(base+".zst", zstdCompress([]byte("Ana: compressed\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.Remove(base)
+	if rec := serveGet(t, h, "/api/meetings/a/transcript"); rec.Body.String() != "Ana: compressed\n" {
+		t.Errorf("zstd transcript = %q", rec.Body)
+	}
+
+	for _, target := range []string{"/api/meetings/b/transcript", "/api/meetings/b/video", "/api/meetings/zzz"} {
+		if rec := serveGet(t, h, target); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", target, rec.Code)
+		}
+	}
+}
+
+func TestServeVideoRange(t *testing.T) {
+	dir := newServeArchive(t)
+	h := newArchiveServer(dir, dir, "").handler()
+
+	rec := serveGet(t, h, "/api/meetings/a/video", "Range", "bytes=2-5")
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", rec.Code)
+	}
+	if got := rec.Body.String(); got != "2345" {
+		t.Errorf("body = %q, want 2345", got)
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("Content-Range = %q", got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "video/mp4" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	rec = serveGet(t, h, "/api/meetings/a/video")
+	if rec.Code != http.StatusOK || rec.Body.Len() != 10 || rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("full video: %d, %d bytes, Accept-Ranges %q", rec.Code, rec.Body.Len(), rec.Header().Get("Accept-Ranges"))
+	}
+}
+
+func TestServeSeparateVideoRoot(t *testing.T) {
+	data := t.TempDir()
+	videos := t.TempDir()
+	writeArchiveMeta(t, data, "2025-01-15", &Metadata{ID: "a"})
+	_ = os.MkdirAll(filepath.Join(videos, "2025-01-15"), 0o700)
+	_ = os.WriteFile(filepath.Join(videos, "2025-01-15", "a.m4a"), []byte("audio"), 0o600)
+
+	rec := serveGet(t, newArchiveServer(data, videos, "").handler(), "/api/meetings/a/video")
+	if rec.Body.String() != "audio" || rec.Header().Get("Content-Type") != "audio/mp4" {
+		t.Errorf("video: %q %q", rec.Body, rec.Header().Get("Content-Type"))
+	}
+}
+
+func TestServeAuth(t *testing.T) {
+	dir := newServeArchive(t)
+	h := newArchiveServer(dir, dir, "s3cret").handler()
+
+	if rec := serveGet(t, h, "/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("healthz status = %d, want 200 without a token", rec.Code)
+	}
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		if rec := serveGet(t, h, "/api/meetings", "Authorization", auth); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", auth, rec.Code)
+		}
+	}
+	if rec := serveGet(t, h, "/api/meetings", "Authorization", "Bearer s3cret"); rec.Code != http.StatusOK {
+		t.Errorf("good token: status = %d", rec.Code)
+	}
+}
+
+func TestServeIndexRefresh(t *testing.T) {
+	dir := newServeArchive(t)
+	s := newArchiveServer(dir, dir, "")
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	h := s.handler()
+	serveGet(t, h, "/api/meetings")
+
+	// A meeting exported while serving is found by ID right away, and
+	// listed once the index is stale.
+	writeArchiveMeta(t, dir, "2025-03-01", &Metadata{ID: "c", Title: "New"})
+	now = now.Add(2 * time.Second)
+	if rec := serveGet(t, h, "/api/meetings/c"); rec.Code != http.StatusOK {
+		t.Errorf("new meeting: status = %d", rec.Code)
+	}
+
+	writeArchiveMeta(t, dir, "2025-03-01", &Metadata{ID: "d"})
+	var list ServeMeetingList
+	_ = json.NewDecoder(serveGet(t, h, "/api/meetings").Body).Decode(&list)
+	if list.Total != 3 {
+		t.Errorf("total within TTL = %d, want 3", list.Total)
+	}
+	now = now.Add(serveIndexTTL)
+	_ = json.NewDecoder(serveGet(t, h, "/api/meetings").Body).Decode(&list)
+	if list.Total != 4 {
+		t.Errorf("total after TTL = %d, want 4", list.Total)
+	}
+}
+
+func TestRunServeFlags(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if got := runServe([]string{"--output", filepath.Join(t.TempDir(), "nope")}); got != 1 {
+		t.Errorf("missing archive: exit %d, want 1", got)
+	}
+	if got := runServe([]string{"--output", t.TempDir(), "--addr", "8089"}); got != 2 {
+		t.Errorf("bad --addr: exit %d, want 2", got)
+	}
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// ── Background Service ──────────────────────────────────────────────────────
+//
+// `graindl service install --mode watch [exporter flags]` keeps watch mode
+// (or `graindl serve`, with --mode serve) running across reboots without a
+// hand-written unit. The flags after the service's own are passed to
+// graindl unchanged, and the unit runs in the current directory so .env and
+// relative paths resolve as they do now. Per platform:
+//
+//	Linux    systemd user unit  ~/.config/systemd/user/<name>.service
+//	macOS    launchd agent      ~/Library/LaunchAgents/com.github.droxey.<name>.plist
+//	Windows  WinSW service      %ProgramData%\graindl\<name>\<name>.xml
+//
+// Windows services must speak the service control protocol, which graindl
+// does not, so the unit there is a WinSW (github.com/winsw/winsw) wrapper:
+// winsw.exe must be on PATH and is copied next to the XML. `service status`
+// and `service uninstall` take the same --name. The unit file may hold
+// flag values such as tokens, so it is written 0600.
+
+// serviceModes maps --mode to the graindl arguments that start it.
+var serviceModes = map[string][]string{
+	"watch": {"--watch"},
+	"serve": {"serve"},
+}
+
+// serviceOwnFlags are the flags install keeps for itself; everything else
+// goes to graindl.
+var serviceOwnFlags = []string{"mode", "name", "print"}
+
+var serviceNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// launchdLabelPrefix namespaces launchd labels.
+const launchdLabelPrefix = "com.github.droxey."
+
+// serviceSpec describes one installed graindl service.
+type serviceSpec struct {
+	Name    string
+	Mode    string
+	Exe     string   // absolute path of the graindl binary
+	WorkDir string   // directory the service runs in
+	Args    []string // graindl arguments, mode first
+}
+
+// serviceStep is one service manager command. A step that may fail (e.g.
+// stopping a service that is not running) does not stop the sequence.
+type serviceStep struct {
+	argv    []string
+	mayFail bool
+}
+
+// serviceRun runs a service manager command; tests replace it.
+var serviceRun = func(ctx context.Context, out io.Writer, name string, args ...string) error {
+	if out == nil {
+		return runQuiet(ctx, name, args...)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout, cmd.Stderr = out, out
+	return cmd.Run()
+}
+
+// splitServiceArgs separates install's own flags from the graindl flags.
+// Both "-name value" and "-name=value" forms are recognized.
+func splitServiceArgs(args []string) (own, rest []string) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		name, _, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "-") || !slices.Contains(serviceOwnFlags, name) {
+			rest = append(rest, a)
+			continue
+		}
+		own = append(own, a)
+		if !hasValue && name != "print" && i+1 < len(args) {
+			i++
+			own = append(own, args[i])
+		}
+	}
+	return own, rest
+}
+
+// unitPath returns where the unit file for spec lives on goos.
+func (s *serviceSpec) unitPath(goos, home string) string {
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabelPrefix+s.Name+".plist")
+	case "windows":
+		return filepath.Join(coalesce(os.Getenv("ProgramData"), `C:\ProgramData`), "graindl", s.Name, s.Name+".xml")
+	}
+	return filepath.Join(coalesce(os.Getenv("XDG_CONFIG_HOME"), filepath.Join(home, ".config")), "systemd", "user", s.Name+".service")
+}
+
+// unitFile renders the unit for goos.
+func (s *serviceSpec) unitFile(goos, home string) string {
+	switch goos {
+	case "darwin":
+		return s.launchdPlist(home)
+	case "windows":
+		return s.winswXML()
+	}
+	return s.systemdUnit()
+}
+
+func (s *serviceSpec) systemdUnit() string {
+	argv := append([]string{s.Exe}, s.Args...)
+	for i, a := range argv {
+		argv[i] = systemdQuote(a)
+	}
+	return fmt.Sprintf(`[Unit]
+Description=graindl %s (%s)
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+WorkingDirectory=%s
+ExecStart=%s
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`, s.Mode, s.Name, systemdQuote(s.WorkDir), strings.Join(argv, " "))
+}
+
+func (s *serviceSpec) launchdPlist(home string) string {
+	var args strings.Builder
+	for _, a := range append([]string{s.Exe}, s.Args...) {
+		fmt.Fprintf(&args, "\t\t<string>%s</string>\n", xmlEscape(a))
+	}
+	logPath := xmlEscape(filepath.Join(home, "Library", "Logs", "graindl", s.Name+".log"))
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>30</integer>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, launchdLabelPrefix+s.Name, args.String(), xmlEscape(s.WorkDir), logPath, logPath)
+}
+
+func (s *serviceSpec) winswXML() string {
+	args := make([]string, len(s.Args))
+	for i, a := range s.Args {
+		args[i] = windowsQuote(a)
+	}
+	return fmt.Sprintf(`<service>
+  <id>%s</id>
+  <name>graindl %s (%s)</name>
+  <description>Grain recording export (graindl %s)</description>
+  <executable>%s</executable>
+  <arguments>%s</arguments>
+  <workingdirectory>%s</workingdirectory>
+  <startmode>Automatic</startmode>
+  <delayedAutoStart>true</delayedAutoStart>
+  <onfailure action="restart" delay="30 sec"/>
+  <log mode="roll-by-size"/>
+</service>
+`, xmlEscape(s.Name), xmlEscape(s.Mode), xmlEscape(s.Name), xmlEscape(s.Mode), xmlEscape(s.Exe), xmlEscape(strings.Join(args, " ")), xmlEscape(s.WorkDir))
+}
+
+// winswExe is the WinSW copy that manages the service on Windows.
+func (s *serviceSpec) winswExe(unitPath string) string {
+	return filepath.Join(filepath.Dir(unitPath), s.Name+".exe")
+}
+
+// serviceSteps returns the commands for action ("install", "status",
+// "uninstall") on goos, given the unit file's path.
+func (s *serviceSpec) serviceSteps(goos, action, unitPath string, uid int) []serviceStep {
+	switch goos {
+	case "darwin":
+		domain := fmt.Sprintf("gui/%d", uid)
+		target := domain + "/" + launchdLabelPrefix + s.Name
+		switch action {
+		case "install":
+			return []serviceStep{{argv: []string{"launchctl", "bootout", target}, mayFail: true}, {argv: []string{"launchctl", "bootstrap", domain, unitPath}}}
+		case "status":
+			return []serviceStep{{argv: []string{"launchctl", "print", target}}}
+		case "uninstall":
+			return []serviceStep{{argv: []string{"launchctl", "bootout", target}, mayFail: true}}
+		}
+	case "windows":
+		exe := s.winswExe(unitPath)
+		switch action {
+		case "install":
+			return []serviceStep{{argv: []string{exe, "install"}}, {argv: []string{exe, "start"}}}
+		case "status":
+			return []serviceStep{{argv: []string{exe, "status"}}}
+		case "uninstall":
+			return []serviceStep{{argv: []string{exe, "stop"}, mayFail: true}, {argv: []string{exe, "uninstall"}}}
+		}
+	default:
+		unit := s.Name + ".service"
+		switch action {
+		case "install":
+			return []serviceStep{{argv: []string{"systemctl", "--user", "daemon-reload"}}, {argv: []string{"systemctl", "--user", "enable", "--now", unit}}}
+		case "status":
+			return []serviceStep{{argv: []string{"systemctl", "--user", "status", "--no-pager", unit}}}
+		case "uninstall":
+			return []serviceStep{{argv: []string{"systemctl", "--user", "disable", "--now", unit}, mayFail: true}}
+		}
+	}
+	return nil
+}
+
+// runSteps runs steps in order, stopping at the first failure that is not
+// allowed.
+func runSteps(ctx context.Context, out io.Writer, steps []serviceStep) error {
+	for _, st := range steps {
+		err := serviceRun(ctx, out, st.argv[0], st.argv[1:]...)
+		if err != nil && !st.mayFail {
+			return fmt.Errorf("%s: %w", strings.Join(st.argv, " "), err)
+		}
+		if err != nil {
+			slog.Debug("Service step failed, continuing", "cmd", strings.Join(st.argv, " "), "error", err)
+		}
+	}
+	return nil
+}
+
+// ── Quoting ─────────────────────────────────────────────────────────────────
+
+// systemdQuote quotes s for ExecStart= and WorkingDirectory=, escaping
+// systemd's % specifiers and $ expansion.
+func systemdQuote(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// windowsQuote quotes s as one argument of a Windows command line
+// (CommandLineToArgvW rules).
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for _, c := range s {
+		if c == '\\' {
+			slashes++
+			continue
+		}
+		if c == '"' {
+			slashes = 2*slashes + 1 // escape the run and the quote
+		}
+		b.WriteString(strings.Repeat(`\`, slashes))
+		slashes = 0
+		b.WriteRune(c)
+	}
+	b.WriteString(strings.Repeat(`\`, 2*slashes)) // before the closing quote
+	b.WriteByte('"')
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;").Replace(s)
+}
+
+// ── Subcommand ──────────────────────────────────────────────────────────────
+
+const serviceUsage = "usage: graindl service install [--mode watch|serve] [--name graindl] [--print] [graindl flags...]\n       graindl service status|uninstall [--name graindl]"
+
+func runService(args []string) int {
+	if len(args) == 0 || !slices.Contains([]string{"install", "status", "uninstall"}, args[0]) {
+		fmt.Fprintln(os.Stderr, serviceUsage)
+		return 2
+	}
+	action := args[0]
+	own, rest := args[1:], []string(nil)
+	if action == "install" {
+		own, rest = splitServiceArgs(args[1:])
+	}
+
+	fs := flag.NewFlagSet("service "+action, flag.ContinueOnError)
+	mode := fs.String("mode", "watch", "What the service runs: watch (graindl --watch) or serve (graindl serve)")
+	name := fs.String("name", "graindl", "Service name, to run several side by side")
+	printOnly := fs.Bool("print", false, "Print the unit file and commands instead of installing")
+	if err := fs.Parse(own); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, serviceUsage)
+		return 2
+	}
+	setupLogger("", false)
+	if !serviceNameRe.MatchString(*name) {
+		slog.Error("Invalid --name: use letters, digits, '.', '_', or '-'", "name", *name)
+		return 2
+	}
+	modeArgs, ok := serviceModes[*mode]
+	if !ok {
+		slog.Error("Invalid --mode (use watch or serve)", "mode", *mode)
+		return 2
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		slog.Error("Home directory unknown", "error", err)
+		return 1
+	}
+	spec := &serviceSpec{Name: *name, Mode: *mode}
+	unitPath := spec.unitPath(runtime.GOOS, home)
+	ctx := context.Background()
+
+	switch action {
+	case "status":
+		if !fileExists(unitPath) {
+			slog.Error("Service not installed", "name", *name, "unit", unitPath)
+			return 1
+		}
+		fmt.Println("Unit:", unitPath)
+		if err := runSteps(ctx, os.Stdout, spec.serviceSteps(runtime.GOOS, action, unitPath, os.Getuid())); err != nil {
+			return 3 // not running, by the service manager's account
+		}
+		return 0
+
+	case "uninstall":
+		if !fileExists(unitPath) {
+			slog.Error("Service not installed", "name", *name, "unit", unitPath)
+			return 1
+		}
+		if err := spec.uninstall(ctx, runtime.GOOS, unitPath); err != nil {
+			slog.Error("Service uninstall failed", "error", err)
+			return 1
+		}
+		slog.Info("Service removed", "name", *name)
+		return 0
+	}
+
+	if spec.Exe, err = os.Executable(); err == nil {
+		spec.Exe, err = filepath.EvalSymlinks(spec.Exe)
+	}
+	if err == nil {
+		spec.WorkDir, err = os.Getwd()
+	}
+	if err != nil {
+		slog.Error("Cannot locate graindl", "error", err)
+		return 1
+	}
+	if strings.Contains(spec.Exe, "go-build") {
+		slog.Warn("graindl is running from `go run`; install a built binary so the service survives the build cache", "exe", spec.Exe)
+	}
+	spec.Args = append(append([]string{}, modeArgs...), rest...)
+	unit := spec.unitFile(runtime.GOOS, home)
+
+	if *printOnly {
+		fmt.Printf("# %s\n%s\n", unitPath, unit)
+		for _, st := range spec.serviceSteps(runtime.GOOS, "install", unitPath, os.Getuid()) {
+			fmt.Println("$", strings.Join(st.argv, " "))
+		}
+		return 0
+	}
+	if err := spec.install(ctx, runtime.GOOS, home, unitPath, unit); err != nil {
+		slog.Error("Service install failed", "error", err)
+		return 1
+	}
+	slog.Info("Service installed and started", "name", *name, "mode", *mode, "unit", unitPath)
+	switch runtime.GOOS {
+	case "linux":
+		slog.Info(fmt.Sprintf("To keep it running while you are logged out and start it at boot, run: loginctl enable-linger %s", os.Getenv("USER")))
+	case "darwin":
+		slog.Info("Logs go to " + filepath.Join(home, "Library", "Logs", "graindl", *name+".log"))
+	}
+	return 0
+}
+
+// install writes the unit (and on Windows the WinSW copy) and starts it.
+func (s *serviceSpec) install(ctx context.Context, goos, home, unitPath, unit string) error {
+	if err := os.MkdirAll(filepath.Dir(unitPath), 0o755); err != nil {
+		return err
+	}
+	switch goos {
+	case "darwin":
+		if err := os.MkdirAll(filepath.Join(home, "Library", "Logs", "graindl"), 0o755); err != nil {
+			return err
+		}
+	case "windows":
+		winsw, err := exec.LookPath("winsw")
+		if err != nil {
+			return errors.New("winsw.exe not found on PATH: install WinSW (github.com/winsw/winsw) to run graindl as a Windows service")
+		}
+		if fileExists(s.winswExe(unitPath)) {
+			_ = runSteps(ctx, nil, s.serviceSteps(goos, "uninstall", unitPath, 0))
+		}
+		if _, err := copyFileWithHash(s.winswExe(unitPath), winsw); err != nil {
+			return fmt.Errorf("copy winsw: %w", err)
+		}
+	}
+	if err := os.WriteFile(unitPath, []byte(unit), 0o600); err != nil {
+		return err
+	}
+	return runSteps(ctx, nil, s.serviceSteps(goos, "install", unitPath, os.Getuid()))
+}
+
+// uninstall stops the service and removes its files.
+func (s *serviceSpec) uninstall(ctx context.Context, goos, unitPath string) error {
+	if err := runSteps(ctx, nil, s.serviceSteps(goos, "uninstall", unitPath, os.Getuid())); err != nil {
+		return err
+	}
+	if goos == "windows" {
+		return os.RemoveAll(filepath.Dir(unitPath))
+	}
+	if err := os.Remove(unitPath); err != nil {
+		return err
+	}
+	if goos == "linux" {
+		return runSteps(ctx, nil, []serviceStep{{argv: []string{"systemctl", "--user", "daemon-reload"}, mayFail: true}})
+	}
+	return nil
+}
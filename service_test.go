@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitServiceArgs(t *testing.T) {
+	own, rest := splitServiceArgs([]string{"--mode", "serve", "--output", "/srv/rec", "-name=work", "--headless", "--print", "--gdrive"})
+	if got := strings.Join(own, " "); got != "--mode serve -name=work --print" {
+		t.Errorf("own = %q", got)
+	}
+	if got := strings.Join(rest, " "); got != "--output /srv/rec --headless --gdrive" {
+		t.Errorf("rest = %q", got)
+	}
+}
+
+func TestServiceQuoting(t *testing.T) {
+	for in, want := range map[string]string{
+		"--headless":    "--headless",
+		"/home/a b/rec": `"/home/a b/rec"`,
+		`say "hi"`:      `"say \"hi\""`,
+		"100%":          "100%%",
+		"$HOME":         "$$HOME",
+		"":              `""`,
+	} {
+		if got := systemdQuote(in); got != want {
+			t.Errorf("systemdQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+	for in, want := range map[string]string{
+		`C:\rec`:     `C:\rec`,
+		`C:\My Rec\`: `"C:\My Rec\\"`,
+		`a"b`:        `"a\"b"`,
+		`x\"y`:       `"x\\\"y"`,
+		"":           `""`,
+	} {
+		if got := windowsQuote(in); got != want {
+			t.Errorf("windowsQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestServiceUnitFiles(t *testing.T) {
+	spec := &serviceSpec{Name: "graindl", Mode: "watch", Exe: "/opt/graindl", WorkDir: "/srv/a b", Args: []string{"--watch", "--output", "rec & more"}}
+
+	unit := spec.unitFile("linux", "/home/u")
+	for _, want := range []string{`WorkingDirectory="/srv/a b"`, `ExecStart=/opt/graindl --watch --output "rec & more"`, "Restart=on-failure", "WantedBy=default.target"} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("systemd unit missing %q:\n%s", want, unit)
+		}
+	}
+
+	plist := spec.unitFile("darwin", "/Users/u")
+	for _, want := range []string{"<string>com.github.droxey.graindl</string>", "<string>rec &amp; more</string>", "<string>/Users/u/Library/Logs/graindl/graindl.log</string>", "<key>RunAtLoad</key>"} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist missing %q:\n%s", want, plist)
+		}
+	}
+
+	xml := spec.unitFile("windows", "")
+	for _, want := range []string{"<id>graindl</id>", "<arguments>--watch --output &quot;rec &amp; more&quot;</arguments>", "<startmode>Automatic</startmode>"} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("WinSW XML missing %q:\n%s", want, xml)
+		}
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	if got := spec.unitPath("linux", "/home/u"); got != filepath.Join("/home/u", ".config", "systemd", "user", "graindl.service") {
+		t.Errorf("linux unit path = %q", got)
+	}
+	if got := spec.unitPath("darwin", "/Users/u"); got != filepath.Join("/Users/u", "Library", "LaunchAgents", "com.github.droxey.graindl.plist") {
+		t.Errorf("darwin unit path = %q", got)
+	}
+}
+
+func TestServiceSteps(t *testing.T) {
+	spec := &serviceSpec{Name: "work"}
+	steps := func(goos, action string) string {
+		var out []string
+		for _, st := range spec.serviceSteps(goos, action, "/u/work.plist", 501) {
+			out = append(out, strings.Join(st.argv, " "))
+		}
+		return strings.Join(out, "; ")
+	}
+	if got := steps("linux", "install"); got != "systemctl --user daemon-reload; systemctl --user enable --now work.service" {
+		t.Errorf("linux install = %q", got)
+	}
+	if got := steps("darwin", "install"); got != "launchctl bootout gui/501/com.github.droxey.work; launchctl bootstrap gui/501 /u/work.plist" {
+		t.Errorf("darwin install = %q", got)
+	}
+	if got := steps("windows", "status"); !strings.HasSuffix(got, "work.exe status") {
+		t.Errorf("windows status = %q", got)
+	}
+}
+
+func TestServiceInstallUninstallLinux(t *testing.T) {
+	var calls []string
+	saved := serviceRun
+	t.Cleanup(func() { serviceRun = saved })
+	serviceRun = func(_ context.Context, _ io.Writer, name string, args ...string) error {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		if strings.Contains(strings.Join(args, " "), "disable") {
+			return errors.New("not running") // tolerated
+		}
+		return nil
+	}
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+	spec := &serviceSpec{Name: "graindl", Mode: "watch", Exe: "/opt/graindl", WorkDir: home, Args: []string{"--watch"}}
+	unitPath := spec.unitPath("linux", home)
+	if err := spec.install(context.Background(), "linux", home, unitPath, spec.unitFile("linux", home)); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(unitPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("unit file: %v, %v", info, err)
+	}
+	if len(calls) != 2 || !strings.Contains(calls[1], "enable --now graindl.service") {
+		t.Errorf("install calls = %q", calls)
+	}
+
+	calls = nil
+	if err := spec.uninstall(context.Background(), "linux", unitPath); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(unitPath) {
+		t.Error("unit file left behind")
+	}
+	if len(calls) != 2 || !strings.Contains(calls[1], "daemon-reload") {
+		t.Errorf("uninstall calls = %q", calls)
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+)
+
+// ── Encrypted Session ───────────────────────────────────────────────────────
+//
+// --encrypt-session keeps the session directory (Chromium profile with Grain
+// cookies, Drive token and sync state) at rest only as <session-dir>.enc, a
+// gzipped tarball in the age format (filippo.io/age), encrypted to an scrypt
+// recipient derived from GRAIN_SESSION_PASSPHRASE. The file is a standard
+// age file, so `age -d <session-dir>.enc | tar xz` recovers the session by
+// hand. At startup it is unpacked into a private directory on tmpfs
+// (/dev/shm where available) which stands in for --session-dir during the
+// run; at shutdown the directory is repacked and wiped. Backups of the
+// machine therefore only ever see ciphertext.
+//
+// age authenticates the header and seals the payload in chunks whose nonces
+// carry a counter and a final-chunk flag, so reordering, truncation, and
+// tampering all fail to decrypt.
+
+// sessionWorkFactor is the scrypt work factor (log2 N) for new containers.
+// age records it in the header, so raising it later keeps old containers
+// readable.
+var sessionWorkFactor = 18
+
+// sessionSkipDirs are Chromium cache directories left out of the container;
+// they are large, rebuilt on demand, and hold nothing worth protecting.
+var sessionSkipDirs = map[string]bool{
+	"Cache":             true,
+	"Code Cache":        true,
+	"GPUCache":          true,
+	"GrShaderCache":     true,
+	"ShaderCache":       true,
+	"DawnCache":         true,
+	"GraphiteDawnCache": true,
+	"CacheStorage":      true,
+	"Crashpad":          true,
+}
+
+// errSessionPassphrase reports a wrong passphrase or a damaged container.
+var errSessionPassphrase = errors.New("cannot decrypt session: wrong GRAIN_SESSION_PASSPHRASE or corrupted file")
+
+// encryptedSession is an unpacked encrypted session directory.
+type encryptedSession struct {
+	path       string // encrypted container on disk
+	dir        string // plaintext working copy, on tmpfs where possible
+	passphrase string
+}
+
+// openEncryptedSession unpacks sessionDir's encrypted container into a
+// private temporary directory. Without a container, an existing plaintext
+// session directory is copied in so the first encrypted run stays logged in.
+func openEncryptedSession(sessionDir, passphrase string) (*encryptedSession, error) {
+	if passphrase == "" {
+		return nil, errors.New("--encrypt-session requires GRAIN_SESSION_PASSPHRASE")
+	}
+	root, onTmpfs := sessionTmpRoot()
+	if !onTmpfs {
+		slog.Warn("No tmpfs available; the decrypted session lives in the system temp dir while graindl runs", "dir", root)
+	}
+	dir, err := os.MkdirTemp(root, "graindl-session-")
+	if err != nil {
+		return nil, fmt.Errorf("session temp dir: %w", err)
+	}
+	s := &encryptedSession{path: filepath.Clean(sessionDir) + ".enc", dir: dir, passphrase: passphrase}
+
+	switch {
+	case fileExists(s.path):
+		err = s.unpack()
+	case dirHasEntries(sessionDir):
+		slog.Warn("Importing plaintext session into the encrypted container; delete it once the encrypted session works", "path", sessionDir)
+		err = copySessionDir(sessionDir, dir)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return s, nil
+}
+
+// unlockSession opens the encrypted session for a command that uses
+// cfg.SessionDir: when --encrypt-session is set, and whenever
+// <session-dir>.enc exists, so no command reads or writes a plaintext session
+// beside the container. cfg.SessionDir, and the Drive token path when it is
+// the default, are pointed at the unpacked copy; Close on the returned
+// session repacks it. It returns nil when the session is not encrypted.
+func unlockSession(cfg *Config, passphrase string) (*encryptedSession, error) {
+	container := filepath.Clean(cfg.SessionDir) + ".enc"
+	if !cfg.EncryptSession && !fileExists(container) {
+		return nil, nil
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("the session is encrypted (%s); set GRAIN_SESSION_PASSPHRASE", container)
+	}
+	s, err := openEncryptedSession(cfg.SessionDir, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GDriveTokenFile == filepath.Join(cfg.SessionDir, stateDriveToken) {
+		cfg.GDriveTokenFile = filepath.Join(s.Dir(), stateDriveToken)
+	}
+	cfg.SessionDir = s.Dir()
+	cfg.EncryptSession = true
+	return s, nil
+}
+
+// closeSession repacks s, if any, logging a failure. It reports whether the
+// session was sealed (or there was nothing to seal).
+func closeSession(s *encryptedSession) bool {
+	if s == nil {
+		return true
+	}
+	if err := s.Close(); err != nil {
+		slog.Error("Session re-encryption failed; the previous encrypted session was kept", "error", err)
+		return false
+	}
+	return true
+}
+
+// Dir is the plaintext session directory to use for this run.
+func (s *encryptedSession) Dir() string { return s.dir }
+
+// Close repacks the session into its container and removes the plaintext
+// copy. The container is replaced atomically; on error the previous one is
+// kept and the plaintext copy is still removed.
+func (s *encryptedSession) Close() error {
+	defer os.RemoveAll(s.dir)
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("session container: %w", err)
+	}
+	err = s.pack(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Discard removes the plaintext copy without repacking it, leaving the
+// container as it was. For commands that only read the session.
+func (s *encryptedSession) Discard() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *encryptedSession) unpack() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return fmt.Errorf("open session container: %w", err)
+	}
+	defer f.Close()
+	dr, err := newSessionDecrypter(bufio.NewReader(f), s.passphrase)
+	if err != nil {
+		return err
+	}
+	zr, err := gzip.NewReader(dr)
+	if err != nil {
+		return sessionReadErr(err)
+	}
+	return untarSession(zr, s.dir)
+}
+
+func (s *encryptedSession) pack(w io.Writer) error {
+	ew, err := newSessionEncrypter(w, s.passphrase)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(ew)
+	if err := tarSession(zw, s.dir); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return ew.Close()
+}
+
+// sessionTmpRoot returns where to unpack the session and whether it is
+// memory-backed.
+func sessionTmpRoot() (string, bool) {
+	if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+		if f, err := os.CreateTemp("/dev/shm", ".graindl-probe-"); err == nil {
+			f.Close()
+			os.Remove(f.Name())
+			return "/dev/shm", true
+		}
+	}
+	return os.TempDir(), false
+}
+
+func dirHasEntries(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err == nil && len(entries) > 0
+}
+
+// ── Tar ─────────────────────────────────────────────────────────────────────
+
+// tarSession writes dir's regular files and directories to w. Symlinks
+// (Chromium's Singleton* locks) and cache directories are skipped.
+func tarSession(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	if err := tarSessionTree(tw, dir, ""); err != nil {
+		return fmt.Errorf("pack session: %w", err)
+	}
+	return tw.Close()
+}
+
+// tarSessionTree adds dir's files to tw under prefix, skipping what
+// tarSession skips.
+func tarSessionTree(tw *tar.Writer, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && sessionSkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{Name: prefix + filepath.ToSlash(rel), ModTime: info.ModTime(), Mode: 0o600, Typeflag: tar.TypeReg, Size: info.Size()}
+		if d.IsDir() {
+			hdr.Name += "/"
+			hdr.Mode, hdr.Typeflag, hdr.Size = 0o700, tar.TypeDir, 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		// A file still being written may change size; copy exactly what the
+		// header promised.
+		if _, err := io.CopyN(tw, f, hdr.Size); err != nil {
+			return fmt.Errorf("pack %s: %w", rel, err)
+		}
+		return nil
+	})
+}
+
+// untarSession extracts a session tarball into dir. Entries that would
+// escape dir are rejected.
+func untarSession(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return sessionReadErr(err)
+		}
+		rel := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if rel == "" || filepath.IsAbs(rel) || !filepath.IsLocal(rel) {
+			return fmt.Errorf("session container: unsafe path %q", hdr.Name)
+		}
+		dest := filepath.Join(dir, rel)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, 0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return sessionReadErr(err)
+			}
+			_ = os.Chtimes(dest, hdr.ModTime, hdr.ModTime)
+		}
+	}
+}
+
+// copySessionDir copies a plaintext session into dir via the tar writer, so
+// the same files are kept as in the container.
+func copySessionDir(src, dir string) error {
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(tarSession(pw, src)) }()
+	err := untarSession(pr, dir)
+	pr.CloseWithError(err)
+	return err
+}
+
+// sessionReadErr maps authentication failures surfacing through the gzip
+// and tar readers to errSessionPassphrase.
+func sessionReadErr(err error) error {
+	if errors.Is(err, errSessionPassphrase) {
+		return errSessionPassphrase
+	}
+	return fmt.Errorf("unpack session: %w", err)
+}
+
+// ── age ─────────────────────────────────────────────────────────────────────
+
+// newSessionEncrypter returns a writer that encrypts to passphrase. Close
+// finishes the age file; it does not close w.
+func newSessionEncrypter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	r, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	r.SetWorkFactor(sessionWorkFactor)
+	return age.Encrypt(w, r)
+}
+
+// newSessionDecrypter returns a reader over the decrypted container. A
+// wrong passphrase, a damaged header, and a damaged or truncated payload
+// all surface as errSessionPassphrase.
+func newSessionDecrypter(r io.Reader, passphrase string) (io.Reader, error) {
+	id, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := age.Decrypt(r, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%v)", errSessionPassphrase, err)
+	}
+	return sessionAuthReader{dr}, nil
+}
+
+// sessionAuthReader reports a payload that fails to authenticate as
+// errSessionPassphrase.
+type sessionAuthReader struct{ r io.Reader }
+
+func (a sessionAuthReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = errSessionPassphrase
+	}
+	return n, err
+}
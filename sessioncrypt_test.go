@@ -0,0 +1,226 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fastSessionKDF(t *testing.T) {
+	t.Helper()
+	old := sessionWorkFactor
+	sessionWorkFactor = 10
+	t.Cleanup(func() { sessionWorkFactor = old })
+}
+
+func writeSessionFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	p := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncryptedSessionRoundTrip(t *testing.T) {
+	fastSessionKDF(t)
+	sessionDir := filepath.Join(t.TempDir(), ".grain-session")
+
+	s, err := openEncryptedSession(sessionDir, "hunter2")
+	if err != nil {
+		t.Fatalf("open (new): %v", err)
+	}
+	big := strings.Repeat("cookie-data ", 20000) // spans several chunks
+	writeSessionFile(t, s.Dir(), "chromium-profile/Default/Cookies", big)
+	writeSessionFile(t, s.Dir(), "gdrive-token.json", `{"refresh_token":"r"}`)
+	writeSessionFile(t, s.Dir(), "chromium-profile/Default/Cache/blob", "cached")
+	os.Symlink("host-1234", filepath.Join(s.Dir(), "chromium-profile", "SingletonLock"))
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if fileExists(s.Dir()) {
+		t.Error("plaintext working copy not removed")
+	}
+	if fileExists(sessionDir) {
+		t.Error("plaintext session dir created")
+	}
+	raw, err := os.ReadFile(sessionDir + ".enc")
+	if err != nil {
+		t.Fatalf("container missing: %v", err)
+	}
+	if bytes.Contains(raw, []byte("cookie-data")) || bytes.Contains(raw, []byte("refresh_token")) {
+		t.Error("container holds plaintext")
+	}
+	if !bytes.HasPrefix(raw, []byte("age-encryption.org/v1\n-> scrypt ")) {
+		t.Errorf("container is not an age scrypt file: %q", raw[:min(len(raw), 40)])
+	}
+	if info, _ := os.Stat(sessionDir + ".enc"); info.Mode().Perm() != 0o600 {
+		t.Errorf("container perm = %o, want 600", info.Mode().Perm())
+	}
+
+	s2, err := openEncryptedSession(sessionDir, "hunter2")
+	if err != nil {
+		t.Fatalf("open (existing): %v", err)
+	}
+	defer os.RemoveAll(s2.Dir())
+	got, err := os.ReadFile(filepath.Join(s2.Dir(), "chromium-profile/Default/Cookies"))
+	if err != nil || string(got) != big {
+		t.Errorf("cookies not restored (err=%v, %d bytes)", err, len(got))
+	}
+	if !fileExists(filepath.Join(s2.Dir(), "gdrive-token.json")) {
+		t.Error("token not restored")
+	}
+	if fileExists(filepath.Join(s2.Dir(), "chromium-profile/Default/Cache")) {
+		t.Error("cache dir should not be packed")
+	}
+	if _, err := os.Lstat(filepath.Join(s2.Dir(), "chromium-profile", "SingletonLock")); err == nil {
+		t.Error("symlink should not be packed")
+	}
+	if info, _ := os.Stat(s2.Dir()); info.Mode().Perm() != 0o700 {
+		t.Errorf("working dir perm = %o, want 700", info.Mode().Perm())
+	}
+}
+
+func TestEncryptedSessionWrongPassphrase(t *testing.T) {
+	fastSessionKDF(t)
+	sessionDir := filepath.Join(t.TempDir(), "sess")
+	s, err := openEncryptedSession(sessionDir, "right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, s.Dir(), "a", "secret")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := openEncryptedSession(sessionDir, "wrong"); !errors.Is(err, errSessionPassphrase) {
+		t.Errorf("wrong passphrase: err = %v, want errSessionPassphrase", err)
+	}
+	if _, err := openEncryptedSession(sessionDir, ""); err == nil {
+		t.Error("empty passphrase should be rejected")
+	}
+}
+
+func TestEncryptedSessionDetectsTampering(t *testing.T) {
+	fastSessionKDF(t)
+	var buf bytes.Buffer
+	ew, err := newSessionEncrypter(&buf, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const chunk = 64 << 10 // age's payload chunk size
+	ew.Write(bytes.Repeat([]byte("x"), chunk*2+10))
+	if err := ew.Close(); err != nil {
+		t.Fatal(err)
+	}
+	good := buf.Bytes()
+
+	read := func(data []byte) error {
+		dr, err := newSessionDecrypter(bytes.NewReader(data), "pw")
+		if err != nil {
+			return err
+		}
+		_, err = bytes.NewBuffer(nil).ReadFrom(dr)
+		return err
+	}
+	if err := read(good); err != nil {
+		t.Fatalf("intact stream: %v", err)
+	}
+
+	flipped := bytes.Clone(good)
+	flipped[len(flipped)-5] ^= 1
+	if err := read(flipped); !errors.Is(err, errSessionPassphrase) {
+		t.Errorf("bit flip: err = %v", err)
+	}
+
+	// Dropping the final chunk must not look like a complete stream.
+	if err := read(good[:len(good)-(10+16)]); !errors.Is(err, errSessionPassphrase) {
+		t.Errorf("truncation: err = %v", err)
+	}
+
+	// A modified header (here the scrypt salt) fails before any payload.
+	salted := bytes.Clone(good)
+	salted[bytes.Index(salted, []byte("-> scrypt "))+10] ^= 1
+	if err := read(salted); !errors.Is(err, errSessionPassphrase) {
+		t.Errorf("header change: err = %v", err)
+	}
+}
+
+func TestEncryptedSessionImportsPlaintext(t *testing.T) {
+	fastSessionKDF(t)
+	sessionDir := filepath.Join(t.TempDir(), "sess")
+	writeSessionFile(t, sessionDir, "chromium-profile/Default/Cookies", "old-login")
+
+	s, err := openEncryptedSession(sessionDir, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(filepath.Join(s.Dir(), "chromium-profile/Default/Cookies"))
+	if string(got) != "old-login" {
+		t.Errorf("imported cookies = %q", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !fileExists(sessionDir + ".enc") {
+		t.Error("container not written after import")
+	}
+	if !fileExists(sessionDir) {
+		t.Error("plaintext session dir must be left for the user to delete")
+	}
+}
+
+func TestUntarSessionRejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../escape", "/abs", "a/../../b"} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: 1, Typeflag: tar.TypeReg})
+		tw.Write([]byte("x"))
+		tw.Close()
+		err := untarSession(&buf, t.TempDir())
+		if err == nil || !strings.Contains(err.Error(), "unsafe path") {
+			t.Errorf("%q: err = %v, want unsafe path error", name, err)
+		}
+	}
+}
+
+func TestUnlockSession(t *testing.T) {
+	fastSessionKDF(t)
+	sessionDir := filepath.Join(t.TempDir(), "sess")
+	cfg := Config{SessionDir: sessionDir, GDriveTokenFile: filepath.Join(sessionDir, stateDriveToken)}
+	if s, err := unlockSession(&cfg, ""); s != nil || err != nil || cfg.SessionDir != sessionDir {
+		t.Errorf("plaintext session: %v, %v, dir %s", s, err, cfg.SessionDir)
+	}
+
+	s, err := openEncryptedSession(sessionDir, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeSessionFile(t, s.Dir(), stateDriveToken, "{}")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A container is unlocked even without --encrypt-session.
+	if _, err := unlockSession(&cfg, ""); err == nil || !strings.Contains(err.Error(), "GRAIN_SESSION_PASSPHRASE") {
+		t.Errorf("no passphrase: err = %v", err)
+	}
+	s, err = unlockSession(&cfg, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Discard()
+	if cfg.SessionDir != s.Dir() || cfg.GDriveTokenFile != filepath.Join(s.Dir(), stateDriveToken) || !cfg.EncryptSession {
+		t.Errorf("cfg not pointed at the unlocked copy: %+v", cfg)
+	}
+	if !fileExists(cfg.GDriveTokenFile) {
+		t.Error("token missing from the unlocked copy")
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Share ───────────────────────────────────────────────────────────────────
+//
+// `graindl share --id <id> --expires 72h` prints time-limited links to a
+// meeting's recording and transcript in an S3-compatible bucket, so it can
+// be shared outside the team without granting bucket access. The bucket
+// holds a copy of the archive under --s3-prefix with the same layout (the
+// export --s3-bucket mirror, `aws s3 sync ./recordings s3://bucket/prefix`,
+// or `rclone sync`). Links are
+// signed locally; nothing is uploaded. --append also adds them to the
+// meeting's markdown note.
+
+// shareSection is the note heading the links are written under. Sharing
+// again replaces the section.
+const shareSection = "## Shared Links"
+
+// shareArtifacts are the shareable files of a meeting, as suffixes of its
+// <date>/<id> base, with their labels.
+var shareArtifacts = []struct{ suffix, label string }{
+	{".mp4", "Video"},
+	{".webm", "Video"},
+	{".mkv", "Video"},
+	{".mov", "Video"},
+	{".ts", "Video"},
+	{".m4a", "Audio"},
+	{".transcript.txt", "Transcript"},
+	{".transcript.txt.zst", "Transcript"},
+	{".transcript.txt.gz", "Transcript"},
+}
+
+// sharedLink is one presigned artifact URL.
+type sharedLink struct {
+	Label   string
+	RelPath string
+	URL     string
+}
+
+func runShare(args []string) int {
+	dotenv := loadDotEnv(".env")
+	var s3 s3Config
+	fs := flag.NewFlagSet("share", flag.ContinueOnError)
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory the meeting was exported to")
+	id := fs.String("id", "", "Meeting ID to share (required)")
+	expiresStr := fs.String("expires", "72h", "How long the links stay valid, up to 7d (e.g. 72h, 3d)")
+	appendNote := fs.Bool("append", false, "Also add the links to the meeting's markdown note")
+	registerS3Flags(fs, &s3, dotenv)
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	if *id == "" {
+		slog.Error("--id is required")
+		return 2
+	}
+	expires, err := parseExpiry(*expiresStr)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	loadS3Credentials(&s3, dotenv)
+	if err := s3.validate(); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+
+	if err := checkUnrouted("share", *outputDir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	entry, err := findArchiveEntry(*outputDir, *id)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	now := time.Now()
+	links, err := shareLinks(&s3, *outputDir, entry, expires, now)
+	if err != nil {
+		slog.Error("Signing failed", "error", err)
+		return 1
+	}
+	if len(links) == 0 {
+		slog.Error("Nothing to share: no video, audio, or transcript exported for this meeting", "id", *id)
+		return 1
+	}
+
+	until := now.Add(expires)
+	for _, l := range links {
+		fmt.Printf("%-10s %s\n", l.Label+":", l.URL)
+	}
+	slog.Info(fmt.Sprintf("Links expire %s (in %s)", until.Format("Mon Jan 2 15:04 MST"), expires))
+
+	if *appendNote {
+		note := findMeetingNote(*outputDir, entry)
+		if note == "" {
+			slog.Error("No markdown note for this meeting (export with --output-format first)", "id", *id)
+			return 1
+		}
+		if err := appendShareSection(filepath.Join(*outputDir, note), links, until); err != nil {
+			slog.Error("Note update failed", "error", err)
+			return 1
+		}
+		slog.Info("Links added to note", "path", note)
+	}
+	return 0
+}
+
+// registerS3Flags adds the bucket location flags, defaulting from env/.env.
+func registerS3Flags(fs *flag.FlagSet, c *s3Config, dotenv map[string]string) {
+	fs.StringVar(&c.Bucket, "s3-bucket", envGet(dotenv, "GRAIN_S3_BUCKET"), "S3 bucket holding a copy of the archive")
+	fs.StringVar(&c.Prefix, "s3-prefix", envGet(dotenv, "GRAIN_S3_PREFIX"), "Key prefix the archive is stored under")
+	fs.StringVar(&c.Endpoint, "s3-endpoint", envGet(dotenv, "GRAIN_S3_ENDPOINT"), "S3-compatible endpoint URL (default AWS; e.g. MinIO, R2)")
+	fs.StringVar(&c.Region, "s3-region", coalesce(envGet(dotenv, "GRAIN_S3_REGION"), envGet(dotenv, "AWS_REGION")), "Bucket region (default us-east-1; \"auto\" for R2)")
+}
+
+// parseExpiry parses --expires: a Go duration or whole days/weeks ("3d").
+func parseExpiry(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	d, err := time.ParseDuration(s)
+	if m := sinceRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		d, err = time.Duration(n)*24*time.Hour, nil
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --expires %q (use e.g. 72h or 3d)", s)
+	}
+	if d > s3MaxPresignExpiry {
+		return 0, fmt.Errorf("--expires %s is too long: signed URLs last at most 7d", s)
+	}
+	return d, nil
+}
+
+// findArchiveEntry returns the exported meeting with id.
+func findArchiveEntry(outputDir, id string) (*ArchiveEntry, error) {
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("invalid meeting ID %q", id)
+	}
+	entries, err := scanArchive(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("scan archive: %w", err)
+	}
+	for _, e := range entries {
+		if e.Meta.ID == id {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("meeting %s not found in %s", id, outputDir)
+}
+
+// shareLinks signs a URL for each of the entry's artifacts present locally.
+// The bucket itself is not checked.
+func shareLinks(c *s3Config, outputDir string, entry *ArchiveEntry, expires time.Duration, now time.Time) ([]sharedLink, error) {
+	var links []sharedLink
+	labels := map[string]bool{}
+	for _, a := range shareArtifacts {
+		rel := entry.RelBase + a.suffix
+		if labels[a.label] || !fileExists(filepath.Join(outputDir, rel)) {
+			continue
+		}
+		u, err := c.presignGet(c.objectKey(rel), expires, now)
+		if err != nil {
+			return nil, err
+		}
+		labels[a.label] = true
+		links = append(links, sharedLink{Label: a.label, RelPath: rel, URL: u})
+	}
+	return links, nil
+}
+
+// findMeetingNote returns the markdown note for entry: <date>/<id>.md, or a
+// --slug-style note in the same directory whose grain_id matches.
+func findMeetingNote(outputDir string, entry *ArchiveEntry) string {
+	if rel := entry.RelBase + ".md"; fileExists(filepath.Join(outputDir, rel)) {
+		return rel
+	}
+	files, err := os.ReadDir(filepath.Join(outputDir, entry.DateDir))
+	if err != nil {
+		return ""
+	}
+	for _, f := range files {
+		rel := filepath.Join(entry.DateDir, f.Name())
+		if !f.IsDir() && filepath.Ext(f.Name()) == ".md" && noteGrainID(filepath.Join(outputDir, rel)) == entry.Meta.ID {
+			return rel
+		}
+	}
+	return ""
+}
+
+// appendShareSection writes links into the note at path, replacing the
+// section from an earlier share.
+func appendShareSection(path string, links []sharedLink, until time.Time) error {
+	if sealed(path) {
+		return fmt.Errorf("%s: %w", filepath.Base(path), errImmutable)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	md := strings.TrimRight(removeMarkdownSection(string(data), shareSection), "\n")
+
+	var b strings.Builder
+	b.WriteString(md)
+	b.WriteString("\n\n" + shareSection + "\n\n")
+	fmt.Fprintf(&b, "_Expires %s._\n\n", until.Format("Mon Jan 2, 2006 15:04 MST"))
+	for _, l := range links {
+		fmt.Fprintf(&b, "- [%s](%s)\n", l.Label, l.URL)
+	}
+	return writeFile(path, []byte(b.String()))
+}
+
+// removeMarkdownSection cuts the "## " section with heading from md, up to
+// the next heading of the same or higher level.
+func removeMarkdownSection(md, heading string) string {
+	lines := strings.SplitAfter(md, "\n")
+	var out []string
+	skipping := false
+	for _, l := range lines {
+		trimmed := strings.TrimRight(l, "\r\n")
+		switch {
+		case trimmed == heading:
+			skipping = true
+			continue
+		case skipping && (strings.HasPrefix(trimmed, "## ") || strings.HasPrefix(trimmed, "# ")):
+			skipping = false
+		}
+		if !skipping {
+			out = append(out, l)
+		}
+	}
+	return strings.Join(out, "")
+}
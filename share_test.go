@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExpiry(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"72h": 72 * time.Hour,
+		"90m": 90 * time.Minute,
+		"3d":  72 * time.Hour,
+		"1w":  7 * 24 * time.Hour,
+	} {
+		got, err := parseExpiry(in)
+		if err != nil || got != want {
+			t.Errorf("parseExpiry(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0s", "-1h", "8d", "2w", "soon"} {
+		if _, err := parseExpiry(in); err == nil {
+			t.Errorf("parseExpiry(%q) should fail", in)
+		}
+	}
+}
+
+// shareArchive writes one exported meeting with a video and transcript.
+func shareArchive(t *testing.T) (string, *ArchiveEntry) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "2025-06-01"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	meta := &Metadata{ID: "abc123", Title: "Customer Call", Date: "2025-06-01T10:00:00Z"}
+	if err := writeJSON(filepath.Join(dir, "2025-06-01", "abc123.json"), meta); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"abc123.mp4", "abc123.transcript.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, "2025-06-01", name), []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entry, err := findArchiveEntry(dir, "abc123")
+	if err != nil {
+		t.Fatalf("findArchiveEntry: %v", err)
+	}
+	return dir, entry
+}
+
+func TestShareLinks(t *testing.T) {
+	dir, entry := shareArchive(t)
+	c := &s3Config{Bucket: "b", Prefix: "/grain/", AccessKeyID: "a", SecretAccessKey: "s"}
+	if err := c.validate(); err != nil {
+		t.Fatal(err)
+	}
+	links, err := shareLinks(c, dir, entry, time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].Label != "Video" || links[1].Label != "Transcript" {
+		t.Fatalf("links = %+v", links)
+	}
+	if !strings.HasPrefix(links[0].URL, "https://b.s3.us-east-1.amazonaws.com/grain/2025-06-01/abc123.mp4?") {
+		t.Errorf("video URL = %s", links[0].URL)
+	}
+
+	if _, err := findArchiveEntry(dir, "missing"); err == nil {
+		t.Error("unknown meeting should be an error")
+	}
+	if _, err := findArchiveEntry(dir, "../etc"); err == nil {
+		t.Error("invalid ID should be rejected")
+	}
+}
+
+func TestFindMeetingNote(t *testing.T) {
+	dir, entry := shareArchive(t)
+	if got := findMeetingNote(dir, entry); got != "" {
+		t.Errorf("no note yet, got %q", got)
+	}
+	slugNote := filepath.Join("2025-06-01", "customer-call.md")
+	if err := os.WriteFile(filepath.Join(dir, slugNote), []byte("---\ntitle: Customer Call\ngrain_id: abc123\n---\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := findMeetingNote(dir, entry); got != slugNote {
+		t.Errorf("slug note = %q, want %q", got, slugNote)
+	}
+}
+
+func TestAppendShareSectionReplacesPrevious(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+	note := "---\ngrain_id: abc123\n---\n\n# Call\n\n## Highlights\n\nSomething\n"
+	if err := os.WriteFile(path, []byte(note), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	until := time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC)
+
+	if err := appendShareSection(path, []sharedLink{{Label: "Video", URL: "https://x/old"}}, until); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendShareSection(path, []sharedLink{{Label: "Video", URL: "https://x/new"}, {Label: "Transcript", URL: "https://x/t"}}, until); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	want := note + "\n## Shared Links\n\n_Expires Wed Jun 4, 2025 10:00 UTC._\n\n- [Video](https://x/new)\n- [Transcript](https://x/t)\n"
+	if got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("note perms = %04o, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRemoveMarkdownSection(t *testing.T) {
+	md := "# T\n\n## Shared Links\n\n- a\n\n## Transcript\n\ntext\n"
+	if got := removeMarkdownSection(md, shareSection); got != "# T\n\n## Transcript\n\ntext\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRunShareRequiresCredentials(t *testing.T) {
+	dir, _ := shareArchive(t)
+	for _, k := range []string{"GRAIN_S3_ACCESS_KEY_ID", "GRAIN_S3_SECRET_ACCESS_KEY", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		t.Setenv(k, "")
+	}
+	t.Chdir(t.TempDir()) // no .env
+	if code := runShare([]string{"--output", dir, "--id", "abc123", "--s3-bucket", "b"}); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if code := runShare([]string{"--output", dir}); code != 2 {
+		t.Errorf("missing --id: exit code = %d, want 2", code)
+	}
+}
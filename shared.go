@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+)
+
+// ── Shared With Me ──────────────────────────────────────────────────────────
+//
+// --include-shared adds recordings other users have shared with the account
+// to discovery. They are exported like the account's own meetings, with
+// metadata `ownership: shared`; with --shared-subdir they land under
+// shared/<date>/ instead of <date>/ so the two sets stay apart. A meeting
+// that appears in both lists counts as owned.
+//
+// Grain's public API has no "shared with me" listing, so there is nothing to
+// correlate against beyond the meeting page itself; the browser view is the
+// only source.
+
+// sharedDir is the top-level directory holding shared meetings with
+// --shared-subdir. It mirrors the <date>/ layout.
+const sharedDir = "shared"
+
+const (
+	ownershipOwned  = "owned"
+	ownershipShared = "shared"
+)
+
+// mergeSharedMeetings appends shared meetings not already in owned.
+func mergeSharedMeetings(owned, shared []MeetingRef) []MeetingRef {
+	seen := make(map[string]bool, len(owned))
+	for _, m := range owned {
+		seen[m.ID] = true
+	}
+	added := 0
+	for _, m := range shared {
+		if seen[m.ID] {
+			continue
+		}
+		seen[m.ID] = true
+		m.Shared = true
+		owned = append(owned, m)
+		added++
+	}
+	slog.Debug("Merged shared meetings", "shared", len(shared), "added", added)
+	return owned
+}
+
+// meetingDir returns the directory, relative to the output dir, that a
+// meeting's artifacts go in.
+func (e *Exporter) meetingDir(ref MeetingRef, dateStr string) string {
+	if ref.Shared && e.cfg.SharedSubdir {
+		return filepath.Join(sharedDir, dateStr)
+	}
+	return dateStr
+}
+
+// ownership is the metadata ownership value for ref; empty unless
+// --include-shared is on, since without it nothing distinguishes the two.
+func (e *Exporter) ownership(ref MeetingRef) string {
+	switch {
+	case ref.Shared:
+		return ownershipShared
+	case e.cfg.IncludeShared:
+		return ownershipOwned
+	}
+	return ""
+}
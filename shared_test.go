@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeSharedMeetings(t *testing.T) {
+	owned := []MeetingRef{{ID: "a"}, {ID: "b"}}
+	shared := []MeetingRef{{ID: "b"}, {ID: "c"}, {ID: "c"}}
+	got := mergeSharedMeetings(owned, shared)
+
+	if len(got) != 3 {
+		t.Fatalf("merged = %+v, want a, b, c", got)
+	}
+	for _, m := range got {
+		if want := m.ID == "c"; m.Shared != want {
+			t.Errorf("%s: Shared = %v, want %v (owned wins)", m.ID, m.Shared, want)
+		}
+	}
+}
+
+func TestSharedMeetingPlacement(t *testing.T) {
+	owned, shared := MeetingRef{ID: "a"}, MeetingRef{ID: "b", Shared: true}
+	tests := []struct {
+		cfg                     Config
+		ownedDir, sharedDirWant string
+		ownedOwn, sharedOwn     string
+	}{
+		{Config{}, "2025-01-02", "2025-01-02", "", "shared"},
+		{Config{IncludeShared: true}, "2025-01-02", "2025-01-02", "owned", "shared"},
+		{Config{IncludeShared: true, SharedSubdir: true}, "2025-01-02", filepath.Join("shared", "2025-01-02"), "owned", "shared"},
+	}
+	for i, tt := range tests {
+		e := &Exporter{cfg: &tt.cfg}
+		if got := e.meetingDir(owned, "2025-01-02"); got != tt.ownedDir {
+			t.Errorf("%d: owned dir = %q, want %q", i, got, tt.ownedDir)
+		}
+		if got := e.meetingDir(shared, "2025-01-02"); got != tt.sharedDirWant {
+			t.Errorf("%d: shared dir = %q, want %q", i, got, tt.sharedDirWant)
+		}
+		if got := e.ownership(owned); got != tt.ownedOwn {
+			t.Errorf("%d: owned ownership = %q, want %q", i, got, tt.ownedOwn)
+		}
+		if got := e.ownership(shared); got != tt.sharedOwn {
+			t.Errorf("%d: shared ownership = %q, want %q", i, got, tt.sharedOwn)
+		}
+	}
+}
+
+func TestScanArchiveIncludesSharedSubdir(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-15", &Metadata{ID: "a"})
+	writeArchiveMeta(t, dir, filepath.Join(sharedDir, "2025-01-14"), &Metadata{ID: "s", Ownership: ownershipShared})
+
+	entries, err := scanArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Meta.ID != "s" {
+		t.Fatalf("entries = %d, want shared meeting first by date", len(entries))
+	}
+	if got := entries[0].RelBase; got != filepath.Join("shared", "2025-01-14", "s") {
+		t.Errorf("RelBase = %q", got)
+	}
+	if !entries[0].Date().Equal(time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want date from shared/<date>", entries[0].Date())
+	}
+}
+
+func TestOwnershipInFrontmatter(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Sync", Ownership: ownershipShared}
+	for _, format := range []string{"obsidian", "notion"} {
+		if md := renderFormattedMarkdown(format, meta, ""); !strings.Contains(md, "\nownership: shared\n") {
+			t.Errorf("%s: ownership missing:\n%s", format, md)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ── Sharing State ───────────────────────────────────────────────────────────
+//
+// Who a recording is shared with is scraped from the meeting page's share
+// dialog into metadata "sharing", so compliance reviews can find publicly
+// shared customer calls in the local archive without visiting Grain. The
+// dialog is read as it stood at export time (scraped_at); --overwrite
+// re-exports pick up later changes.
+
+// Sharing visibilities, most exposed first.
+const (
+	sharingPublic     = "public"     // anyone with the link
+	sharingWorkspace  = "workspace"  // everyone in the Grain workspace
+	sharingRestricted = "restricted" // only the listed people
+	sharingPrivate    = "private"    // owner only
+)
+
+// Sharing is a recording's access as shown in its share dialog.
+type Sharing struct {
+	Visibility string   `json:"visibility"`       // public, workspace, restricted, private
+	PublicLink bool     `json:"public_link"`      // anyone with the link can view
+	Workspace  bool     `json:"workspace"`        // shared with the whole workspace
+	Emails     []string `json:"emails,omitempty"` // individually invited people, lowercased
+	ScrapedAt  string   `json:"scraped_at"`       // RFC 3339
+}
+
+var sharingEmailRe = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// newSharing builds a Sharing from the raw dialog fields: the public and
+// workspace access labels or toggle states, and the text of the invited
+// people list. found reports whether any sharing control was on the page;
+// without one the state is unknown and nil is returned.
+func newSharing(found bool, public, workspace string, people []string, now time.Time) *Sharing {
+	if !found {
+		return nil
+	}
+	s := &Sharing{
+		PublicLink: accessEnabled(public, "anyone", "public"),
+		Workspace:  accessEnabled(workspace, "workspace", "organization", "team"),
+		ScrapedAt:  now.UTC().Format(time.RFC3339),
+	}
+	seen := map[string]bool{}
+	for _, p := range people {
+		for _, email := range sharingEmailRe.FindAllString(p, -1) {
+			email = strings.ToLower(email)
+			if !seen[email] {
+				seen[email] = true
+				s.Emails = append(s.Emails, email)
+			}
+		}
+	}
+	sort.Strings(s.Emails)
+	switch {
+	case s.PublicLink:
+		s.Visibility = sharingPublic
+	case s.Workspace:
+		s.Visibility = sharingWorkspace
+	case len(s.Emails) > 0:
+		s.Visibility = sharingRestricted
+	default:
+		s.Visibility = sharingPrivate
+	}
+	return s
+}
+
+// accessEnabled interprets a share dialog access value: a toggle state
+// ("true"/"false") or the selected option's label, which is on when it
+// mentions one of words and isn't a "restricted"/"only" option.
+func accessEnabled(v string, words ...string) bool {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch v {
+	case "", "false", "off":
+		return false
+	case "true", "on":
+		return true
+	}
+	if containsAny(v, "restricted", "only people", "no one", "disabled") {
+		return false
+	}
+	return containsAny(v, words...)
+}
+
+// sharingJS reads the share dialog: explicit test IDs first, then the
+// dialog's visible text. Returns found=false when no sharing UI exists.
+const sharingJS = `() => {
+	const dialog = document.querySelector('[data-testid="share-dialog"], [role="dialog"][aria-label*="hare"]');
+	const root = dialog || document;
+	const state = (sels) => {
+		for (const s of sels) {
+			const el = root.querySelector(s);
+			if (!el) continue;
+			const checked = el.getAttribute('aria-checked') ?? el.getAttribute('aria-pressed');
+			if (checked !== null) return checked;
+			if (el.type === 'checkbox') return String(el.checked);
+			if (el.textContent.trim()) return el.textContent.trim();
+		}
+		return '';
+	};
+	const text = dialog ? dialog.innerText : '';
+	const line = (re) => (text.split('\n').find((l) => re.test(l)) || '');
+	const people = [...root.querySelectorAll('[data-testid="share-member"], [data-testid="shared-with"] li')]
+		.map((el) => el.textContent.trim());
+	if (dialog && people.length === 0) people.push(text);
+	const pub = state(['[data-testid="share-public-toggle"]', '[data-testid="link-access"]']) || line(/anyone with the link|public/i);
+	const ws = state(['[data-testid="share-workspace-toggle"]', '[data-testid="workspace-access"]']) || line(/workspace|organization/i);
+	return {found: !!dialog || pub !== '' || ws !== '' || people.length > 0, public: pub, workspace: ws, people: people};
+}`
+
+// scrapeSharing reads the current page's sharing state, opening the share
+// dialog when it isn't already on the page and closing it again.
+func (b *Browser) scrapeSharing() *Sharing {
+	s := b.evalSharing()
+	if s != nil {
+		return s
+	}
+	res, err := b.page.Eval(`() => {
+		const btn = document.querySelector('[data-testid="share-button"], button[aria-label="Share"]');
+		if (btn) btn.click();
+		return !!btn;
+	}`)
+	if err != nil || !res.Value.Bool() {
+		return nil
+	}
+	time.Sleep(time.Second)
+	s = b.evalSharing()
+	b.pressEscape()
+	return s
+}
+
+func (b *Browser) evalSharing() *Sharing {
+	res, err := b.page.Eval(sharingJS)
+	if err != nil {
+		slog.Debug("Sharing scrape failed", "error", err)
+		return nil
+	}
+	var people []string
+	for _, p := range res.Value.Get("people").Arr() {
+		people = append(people, p.Str())
+	}
+	return newSharing(res.Value.Get("found").Bool(), res.Value.Get("public").Str(), res.Value.Get("workspace").Str(), people, time.Now())
+}
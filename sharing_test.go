@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSharing(t *testing.T) {
+	now := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name              string
+		public, workspace string
+		people            []string
+		want              string
+		emails            string
+	}{
+		{"public toggle", "true", "false", nil, sharingPublic, ""},
+		{"public label", "Anyone with the link can view", "", nil, sharingPublic, ""},
+		{"restricted link", "Restricted — only people invited", "Acme workspace", nil, sharingWorkspace, ""},
+		{"invited", "false", "off", []string{"Ana Diaz ana@Customer.com", "bo@acme.io Can view", "ana@customer.com"}, sharingRestricted, "ana@customer.com,bo@acme.io"},
+		{"owner only", "", "", nil, sharingPrivate, ""},
+	}
+	for _, tt := range tests {
+		s := newSharing(true, tt.public, tt.workspace, tt.people, now)
+		if s.Visibility != tt.want || strings.Join(s.Emails, ",") != tt.emails {
+			t.Errorf("%s: visibility %q, emails %v; want %q, %q", tt.name, s.Visibility, s.Emails, tt.want, tt.emails)
+		}
+		if s.ScrapedAt != "2025-01-15T09:00:00Z" {
+			t.Errorf("%s: scraped_at = %q", tt.name, s.ScrapedAt)
+		}
+	}
+	if s := newSharing(false, "true", "", nil, now); s != nil {
+		t.Errorf("no sharing UI: %+v, want nil", s)
+	}
+}
+
+func TestSharingFrontmatter(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Customer call", Sharing: &Sharing{Visibility: sharingPublic, PublicLink: true}}
+	for _, format := range []string{"obsidian", "notion", "minutes"} {
+		if md := renderFormattedMarkdown(format, meta, ""); !strings.Contains(md, "\nsharing: public\n") {
+			t.Errorf("%s frontmatter missing sharing:\n%s", format, md)
+		}
+	}
+	meta.Sharing = nil
+	if md := renderFormattedMarkdown("obsidian", meta, ""); strings.Contains(md, "sharing:") {
+		t.Errorf("sharing written without state:\n%s", md)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ── Title Slugs ─────────────────────────────────────────────────────────────
+//
+// --slug-style names markdown notes after the meeting title instead of its
+// ID. sanitize() only strips path-unsafe bytes, which leaves non-Latin
+// titles as-is (unportable on some filesystems and sync targets) and turns
+// punctuation-heavy titles into dash soup. slugify produces a lowercase,
+// dash-separated name in one of two styles:
+//
+//	ascii    transliterate to ASCII: Latin diacritics are stripped, Cyrillic,
+//	         Greek, Japanese kana and Hangul are romanized. Scripts with no
+//	         built-in romanization (e.g. kanji/hanzi) are dropped.
+//	unicode  keep letters and digits of every script; only normalize width,
+//	         case, and separators.
+//
+// Both styles fold fullwidth/halfwidth forms (NFKC-style) first so "ＡＢＣ"
+// and "ABC" slug identically.
+
+const (
+	slugStyleASCII   = "ascii"
+	slugStyleUnicode = "unicode"
+	maxSlugRunes     = 80
+)
+
+// slugify returns the slug of s in the given style, or "" if nothing
+// printable survives (callers fall back to the meeting ID).
+func slugify(s, style string) string {
+	var b strings.Builder
+	dash := false
+	emit := func(str string) {
+		for _, r := range str {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+				if dash && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				dash = false
+				b.WriteRune(r)
+			} else {
+				dash = true
+			}
+		}
+	}
+
+	runes := []rune(foldWidth(s))
+	for i := 0; i < len(runes); i++ {
+		r := unicode.ToLower(runes[i])
+		if style != slugStyleASCII {
+			emit(string(r))
+			continue
+		}
+		switch {
+		case r < unicode.MaxASCII:
+			emit(string(r))
+		case unicode.Is(unicode.Mn, r):
+			// Combining mark from decomposed (NFD) input: drop.
+		case isKana(r):
+			romaji, n := romanizeKana(runes[i:])
+			emit(romaji)
+			i += n - 1
+		case r >= 0xAC00 && r <= 0xD7A3:
+			emit(romanizeHangul(r))
+		default:
+			if t, ok := translit[r]; ok {
+				emit(t)
+			} else {
+				dash = true
+			}
+		}
+	}
+
+	out := b.String()
+	if rs := []rune(out); len(rs) > maxSlugRunes {
+		out = strings.TrimRight(string(rs[:maxSlugRunes]), "-")
+	}
+	return out
+}
+
+// foldWidth maps fullwidth ASCII variants and the ideographic space to
+// their ASCII equivalents, and halfwidth katakana to fullwidth.
+func foldWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			return r - 0xFEE0
+		case r == 0x3000:
+			return ' '
+		case r >= 0xFF66 && r <= 0xFF9D:
+			if k, ok := halfwidthKana[r]; ok {
+				return k
+			}
+		}
+		return r
+	}, s)
+}
+
+// ── Latin / Cyrillic / Greek ────────────────────────────────────────────────
+
+// translit maps single (lowercased) runes to ASCII.
+var translit = func() map[rune]string {
+	m := map[rune]string{
+		// Latin letters that don't decompose to a base letter.
+		'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d",
+		'ð': "d", 'þ': "th", 'ı': "i", 'ŋ': "ng", 'ħ': "h",
+
+		// Cyrillic (Russian, Ukrainian, Belarusian), BGN/PCGN-style.
+		'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e",
+		'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k",
+		'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+		'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+		'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+		'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi",
+		'ґ': "g", 'ў': "u",
+
+		// Greek, ELOT 743-style.
+		'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z",
+		'η': "i", 'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m",
+		'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s",
+		'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+		'ω': "o", 'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o",
+		'ύ': "y", 'ώ': "o", 'ϊ': "i", 'ϋ': "y", 'ΐ': "i", 'ΰ': "y",
+	}
+	// Latin letters with diacritics: base letter followed by its variants.
+	for _, group := range []string{
+		"aàáâãäåāăąǎ", "cçćĉċč", "dď", "eèéêëēĕėęěẽ", "gĝğġģ",
+		"hĥ", "iìíîïĩīĭįǐ", "jĵ", "kķ", "lĺļľŀ", "nñńņňŉ",
+		"oòóôõöōŏőǒ", "rŕŗř", "sśŝşšș", "tţťț", "uùúûüũūŭůűųǔ",
+		"wŵ", "yýÿŷ", "zźżž",
+	} {
+		rs := []rune(group)
+		for _, r := range rs[1:] {
+			m[r] = string(rs[0])
+		}
+	}
+	return m
+}()
+
+// ── Japanese Kana ───────────────────────────────────────────────────────────
+
+func isKana(r rune) bool {
+	return (r >= 0x3041 && r <= 0x3096) || (r >= 0x30A1 && r <= 0x30FA) || r == 0x30FC
+}
+
+// toHiragana maps katakana to the corresponding hiragana.
+func toHiragana(r rune) rune {
+	if r >= 0x30A1 && r <= 0x30F6 {
+		return r - 0x60
+	}
+	return r
+}
+
+// kanaRomaji is modified Hepburn for single hiragana.
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゎ': "wa",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゕ': "ka", 'ゖ': "ke",
+}
+
+// romanizeKana romanizes the kana at the start of rs, handling yōon
+// (きゃ → kya) and sokuon (っか → kka). It returns the romaji and the number
+// of runes consumed (at least 1).
+func romanizeKana(rs []rune) (string, int) {
+	r := toHiragana(rs[0])
+	switch r {
+	case 0x30FC: // ー long vowel mark: the preceding vowel already carries it
+		return "", 1
+	case 'っ':
+		if len(rs) > 1 && isKana(rs[1]) {
+			next, n := romanizeKana(rs[1:])
+			switch {
+			case strings.HasPrefix(next, "ch"):
+				return "t" + next, n + 1 // っち → tchi
+			case next != "" && !strings.ContainsRune("aiueon", rune(next[0])):
+				return next[:1] + next, n + 1
+			}
+			return next, n + 1
+		}
+		return "", 1
+	}
+
+	base, ok := kanaRomaji[r]
+	if !ok {
+		return "", 1
+	}
+	if len(rs) > 1 && strings.HasSuffix(base, "i") && len(base) > 1 {
+		var vowel string
+		switch toHiragana(rs[1]) {
+		case 'ゃ':
+			vowel = "a"
+		case 'ゅ':
+			vowel = "u"
+		case 'ょ':
+			vowel = "o"
+		}
+		if vowel != "" {
+			stem := strings.TrimSuffix(base, "i")
+			switch stem {
+			case "sh", "ch", "j":
+				return stem + vowel, 2
+			}
+			return stem + "y" + vowel, 2
+		}
+	}
+	return base, 1
+}
+
+// halfwidthKana maps halfwidth katakana (U+FF66–U+FF9D) to fullwidth.
+var halfwidthKana = func() map[rune]rune {
+	full := []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+	m := make(map[rune]rune, len(full))
+	for i, r := range full {
+		m[rune(0xFF66+i)] = r
+	}
+	return m
+}()
+
+// ── Korean Hangul ───────────────────────────────────────────────────────────
+
+// Revised Romanization jamo tables, per syllable (no cross-syllable
+// assimilation).
+var (
+	hangulInitial = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedial  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinal   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+)
+
+func romanizeHangul(r rune) string {
+	idx := int(r - 0xAC00)
+	return hangulInitial[idx/(21*28)] + hangulMedial[(idx%(21*28))/28] + hangulFinal[idx%28]
+}
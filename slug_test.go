@@ -0,0 +1,107 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ── slugify ─────────────────────────────────────────────────────────────────
+
+func TestSlugifyASCII(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Weekly Sync: Q3 / Planning", "weekly-sync-q3-planning"},
+		{"  --Hello__World!!  ", "hello-world"},
+		{"Café Crème Brûlée", "cafe-creme-brulee"},
+		{"Straße Ærø Łódź", "strasse-aero-lodz"},
+		{"Café", "cafe"}, // decomposed (NFD) accent
+		{"Встреча с клиентом", "vstrecha-s-klientom"},
+		{"Щука и ёж", "shchuka-i-yozh"},
+		{"Київ", "kiyiv"},
+		{"Συνάντηση ομάδας", "synantisi-omadas"},
+		{"ミーティング", "miteingu"},
+		{"きょうのかいぎ", "kyounokaigi"},
+		{"ちょっと", "chotto"},
+		{"マッチ", "matchi"},
+		{"회의록", "hoeuirok"},
+		{"ＡＢＣ　１２３", "abc-123"},
+		{"ｶﾀｶﾅ", "katakana"},
+		{"会議 2024", "2024"},
+		{"会議", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in, slugStyleASCII); got != tt.want {
+			t.Errorf("slugify(%q, ascii) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlugifyUnicode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Встреча: План / Q3", "встреча-план-q3"},
+		{"週次 ミーティング", "週次-ミーティング"},
+		{"Café?", "café"},
+		{"ＡＢＣ", "abc"},
+		{"a/b\\c..d", "a-b-c-d"},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.in, slugStyleUnicode); got != tt.want {
+			t.Errorf("slugify(%q, unicode) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlugifyTruncates(t *testing.T) {
+	got := slugify(strings.Repeat("word ", 40), slugStyleASCII)
+	if n := len([]rune(got)); n > maxSlugRunes {
+		t.Errorf("slug length = %d, want <= %d", n, maxSlugRunes)
+	}
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("truncated slug should not end with a dash: %q", got)
+	}
+}
+
+// ── noteRelPath ─────────────────────────────────────────────────────────────
+
+func TestNoteRelPath(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
+	meta := &Metadata{ID: "m1", Title: "Встреча"}
+	relBase := filepath.Join("2025-01-15", "m1")
+
+	if got, want := e.noteRelPath(meta, relBase), relBase+".md"; got != want {
+		t.Errorf("default = %q, want %q", got, want)
+	}
+
+	e.cfg.SlugStyle = slugStyleASCII
+	want := filepath.Join("2025-01-15", "vstrecha.md")
+	if got := e.noteRelPath(meta, relBase); got != want {
+		t.Errorf("ascii = %q, want %q", got, want)
+	}
+
+	// Same-title note from another meeting already exists: disambiguate.
+	if err := e.storage.WriteFile(want, []byte("grain_id: other\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.noteRelPath(meta, relBase), filepath.Join("2025-01-15", "vstrecha-m1.md"); got != want {
+		t.Errorf("collision = %q, want %q", got, want)
+	}
+
+	// Our own note (re-export with --overwrite) keeps its name.
+	if err := e.storage.WriteFile(want, []byte("grain_id: m1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.noteRelPath(&Metadata{ID: "m1", Title: "Встреча"}, relBase); got != want {
+		t.Errorf("own note = %q, want %q", got, want)
+	}
+
+	// Untitled (or unromanizable) meetings fall back to the ID.
+	if got := e.noteRelPath(&Metadata{ID: "m2", Title: "会議"}, relBase); got != relBase+".md" {
+		t.Errorf("fallback = %q, want %q", got, relBase+".md")
+	}
+}
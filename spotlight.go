@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// ── Spotlight and Finder Tags ───────────────────────────────────────────────
+//
+// On macOS, --spotlight writes each meeting's title, keywords (participants,
+// tags, topics), Grain URL, and Finder tags onto its exported files as
+// com.apple.metadata extended attributes, so a meeting turns up in Spotlight
+// and Finder searches without opening the notes. The values are binary
+// property lists written with the system `xattr` tool. Finder tags are the
+// meeting's Grain tags plus "Grain" (and its --classify label), which makes
+// the whole archive one tag click away.
+
+// spotlightTag is the Finder tag every exported file gets.
+const spotlightTag = "Grain"
+
+// Spotlight writes metadata attributes onto exported files.
+type Spotlight struct {
+	// run executes an external command; replaced in tests.
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// NewSpotlight returns a tagger, or an error off macOS or without xattr.
+func NewSpotlight() (*Spotlight, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("--spotlight is only supported on macOS")
+	}
+	if _, err := exec.LookPath("xattr"); err != nil {
+		return nil, fmt.Errorf("xattr not found in PATH (required for --spotlight): %w", err)
+	}
+	return &Spotlight{run: runQuiet}, nil
+}
+
+// spotlightAttrs returns the attributes written for meta, by name.
+func spotlightAttrs(meta *Metadata) [][2]string {
+	var attrs [][2]string
+	add := func(name string, plist []byte) {
+		attrs = append(attrs, [2]string{"com.apple.metadata:" + name, hex.EncodeToString(plist)})
+	}
+	if title := strings.TrimSpace(meta.Title); title != "" {
+		add("kMDItemTitle", bplistString(title))
+	}
+
+	tags := flattenStringSlice(meta.Tags)
+	keywords := dedupeFold(append(append(flattenStringSlice(meta.Participants), tags...), meta.Topics...))
+	if len(keywords) > 0 {
+		add("kMDItemKeywords", bplistStrings(keywords))
+	}
+	if meta.Links.Grain != "" {
+		add("kMDItemWhereFroms", bplistStrings([]string{meta.Links.Grain}))
+	}
+	finder := []string{spotlightTag}
+	if meta.Classification != "" {
+		finder = append(finder, meta.Classification)
+	}
+	add("_kMDItemUserTags", bplistStrings(dedupeFold(append(finder, tags...))))
+	return attrs
+}
+
+// Tag writes meta's attributes onto the files at paths.
+func (s *Spotlight) Tag(ctx context.Context, meta *Metadata, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	for _, attr := range spotlightAttrs(meta) {
+		args := append([]string{"-wx", attr[0], attr[1]}, paths...)
+		if err := s.run(ctx, "xattr", args...); err != nil {
+			return fmt.Errorf("xattr %s: %w", attr[0], err)
+		}
+	}
+	return nil
+}
+
+// tagSpotlight writes the meeting's Spotlight attributes onto r's files.
+// It runs before the files are sealed, since sealed files can't take new
+// attributes. Failures are logged and leave the export intact.
+func (e *Exporter) tagSpotlight(ctx context.Context, meta *Metadata, r *ExportResult) {
+	if e.spotlight == nil || meta == nil {
+		return
+	}
+	var paths []string
+	for _, p := range collectResultPaths(r) {
+		if p != "" {
+			paths = append(paths, e.storage.AbsPath(p))
+		}
+	}
+	if err := e.spotlight.Tag(ctx, meta, paths); err != nil {
+		slog.Warn("Spotlight tagging failed", "id", meta.ID, "error", err)
+		return
+	}
+	slog.Debug("Spotlight attributes written", "id", meta.ID, "files", len(paths))
+}
+
+// dedupeFold drops empty and case-insensitively repeated strings, keeping
+// the first spelling.
+func dedupeFold(items []string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, s := range items {
+		s = strings.TrimSpace(s)
+		if key := strings.ToLower(s); s != "" && !seen[key] {
+			seen[key] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ── Binary Property Lists ───────────────────────────────────────────────────
+//
+// Just enough of the bplist00 format for a string or an array of strings,
+// the shapes com.apple.metadata attributes hold.
+
+// bplistString encodes s as a binary property list.
+func bplistString(s string) []byte {
+	return encodeBplist([][]byte{bplistStringObject(s)}, 0)
+}
+
+// bplistStrings encodes items as a binary property list array.
+func bplistStrings(items []string) []byte {
+	objects := make([][]byte, 0, len(items)+1)
+	objects = append(objects, nil) // the array, filled in once refs are sized
+	for _, s := range items {
+		objects = append(objects, bplistStringObject(s))
+	}
+	refSize := bplistIntSize(uint64(len(objects)))
+	arr := bplistMarker(0xA0, len(items))
+	for i := range items {
+		arr = appendBplistUint(arr, uint64(i+1), refSize)
+	}
+	objects[0] = arr
+	return encodeBplist(objects, 0)
+}
+
+// bplistStringObject encodes one string object: ASCII, or UTF-16BE when s
+// has other characters.
+func bplistStringObject(s string) []byte {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return append(bplistMarker(0x50, len(s)), s...)
+	}
+	units := utf16.Encode([]rune(s))
+	b := bplistMarker(0x60, len(units))
+	for _, u := range units {
+		b = binary.BigEndian.AppendUint16(b, u)
+	}
+	return b
+}
+
+// bplistMarker returns an object marker with a length, spilling lengths of
+// 15 and over into a following integer object.
+func bplistMarker(kind byte, n int) []byte {
+	if n < 15 {
+		return []byte{kind | byte(n)}
+	}
+	size := bplistIntSize(uint64(n))
+	exp := map[int]byte{1: 0, 2: 1, 4: 2, 8: 3}[size]
+	return appendBplistUint([]byte{kind | 0x0F, 0x10 | exp}, uint64(n), size)
+}
+
+// bplistIntSize returns the byte width (1, 2, 4, or 8) that holds n.
+func bplistIntSize(n uint64) int {
+	switch {
+	case n < 1<<8:
+		return 1
+	case n < 1<<16:
+		return 2
+	case n < 1<<32:
+		return 4
+	}
+	return 8
+}
+
+func appendBplistUint(b []byte, n uint64, size int) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	return append(b, buf[8-size:]...)
+}
+
+// encodeBplist lays out objects (references between them already encoded)
+// with the offset table and trailer.
+func encodeBplist(objects [][]byte, top int) []byte {
+	var b bytes.Buffer
+	b.WriteString("bplist00")
+	offsets := make([]uint64, len(objects))
+	for i, obj := range objects {
+		offsets[i] = uint64(b.Len())
+		b.Write(obj)
+	}
+	tableOffset := uint64(b.Len())
+	offsetSize := bplistIntSize(tableOffset)
+	for _, off := range offsets {
+		b.Write(appendBplistUint(nil, off, offsetSize))
+	}
+	trailer := make([]byte, 6, 32)
+	trailer = append(trailer, byte(offsetSize), byte(bplistIntSize(uint64(len(objects)))))
+	trailer = binary.BigEndian.AppendUint64(trailer, uint64(len(objects)))
+	trailer = binary.BigEndian.AppendUint64(trailer, uint64(top))
+	trailer = binary.BigEndian.AppendUint64(trailer, tableOffset)
+	b.Write(trailer)
+	return b.Bytes()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBplistEncoding(t *testing.T) {
+	// Reference encodings from Python's plistlib (FMT_BINARY).
+	for name, tc := range map[string]struct {
+		got  []byte
+		want string
+	}{
+		"array":  {bplistStrings([]string{"Grain", "x"}), "62706c6973743030a2010255477261696e5178080b110000000000000101000000000000000300000000000000000000000000000013"},
+		"string": {bplistString("Sync"), "62706c69737430305453796e6308000000000000010100000000000000010000000000000000000000000000000d"},
+	} {
+		if got := hex.EncodeToString(tc.got); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", name, got, tc.want)
+		}
+	}
+
+	long := bplistString("Ana Müller and a title longer than fifteen")
+	if long[8] != 0x6F || long[9] != 0x10 || long[10] != 42 {
+		t.Errorf("UTF-16 long string header = % x", long[8:11])
+	}
+}
+
+func TestSpotlightAttrs(t *testing.T) {
+	meta := &Metadata{
+		Title:          "Renewal call",
+		Participants:   []string{"Ana", "Ben"},
+		Tags:           []string{"customer", "Ana"},
+		Topics:         []string{"pricing"},
+		Classification: "external",
+		Links:          Links{Grain: "https://grain.com/share/recording/m1"},
+	}
+	attrs := map[string]string{}
+	for _, a := range spotlightAttrs(meta) {
+		attrs[strings.TrimPrefix(a[0], "com.apple.metadata:")] = a[1]
+	}
+	for name, want := range map[string][]byte{
+		"kMDItemTitle":      bplistString("Renewal call"),
+		"kMDItemKeywords":   bplistStrings([]string{"Ana", "Ben", "customer", "pricing"}),
+		"kMDItemWhereFroms": bplistStrings([]string{meta.Links.Grain}),
+		"_kMDItemUserTags":  bplistStrings([]string{"Grain", "external", "customer", "Ana"}),
+	} {
+		if attrs[name] != hex.EncodeToString(want) {
+			t.Errorf("%s not written as expected", name)
+		}
+	}
+
+	bare := spotlightAttrs(&Metadata{ID: "m1"})
+	if len(bare) != 1 || !strings.HasSuffix(bare[0][0], "_kMDItemUserTags") {
+		t.Errorf("untitled meeting attrs = %v", bare)
+	}
+}
+
+func TestSpotlightTag(t *testing.T) {
+	var calls [][]string
+	s := &Spotlight{run: func(_ context.Context, name string, args ...string) error {
+		calls = append(calls, append([]string{name}, args...))
+		return nil
+	}}
+	paths := []string{"/out/2025-01-15/m1.json", "/out/2025-01-15/m1.mp4"}
+	if err := s.Tag(context.Background(), &Metadata{Title: "Sync"}, paths); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("calls = %v", calls)
+	}
+	for _, c := range calls {
+		if c[0] != "xattr" || c[1] != "-wx" || !strings.HasPrefix(c[2], "com.apple.metadata:") || strings.Join(c[4:], " ") != strings.Join(paths, " ") {
+			t.Errorf("call = %v", c)
+		}
+	}
+
+	s.run = func(context.Context, string, ...string) error { return errors.New("Operation not permitted") }
+	if err := s.Tag(context.Background(), &Metadata{Title: "Sync"}, paths); err == nil || !strings.Contains(err.Error(), "kMDItemTitle") {
+		t.Errorf("err = %v", err)
+	}
+	if err := s.Tag(context.Background(), &Metadata{Title: "Sync"}, nil); err != nil {
+		t.Errorf("no files: %v", err)
+	}
+}
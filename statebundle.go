@@ -0,0 +1,436 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ── State Bundles ───────────────────────────────────────────────────────────
+//
+// `graindl state export bundle.tar.gz` packs what a new machine needs to
+// carry on where this one stopped: the archive's state files (manifest,
+// checksums, video state and queue, watch state), the Drive sync state, and
+// .env. With --with-session the browser profile (Grain cookies) and the
+// Drive token come along too, so the new machine doesn't have to log in
+// again.
+// `graindl state import bundle.tar.gz` unpacks it into --output,
+// --session-dir, and --env. Copy the archive itself separately; with the
+// state in place, the next run skips what was already exported and
+// uploaded. When the archive lives somewhere else on the new machine,
+// absolute paths in the imported state are rewritten to the new location.
+// An encrypted session (--encrypt-session) is unlocked to read or write the
+// Drive sync state inside it; --with-session bundles the container itself.
+
+const (
+	stateBundleVersion = 1
+	stateBundleIndex   = "graindl-state.json" // always the first entry
+	stateProfileDir    = "chromium-profile"
+	stateDriveToken    = "gdrive-token.json"
+)
+
+// stateOutputFiles are the state files bundled from the output dir.
+var stateOutputFiles = []string{"_export-manifest.json", checksumFile, videoStateFile, videoQueueFile, watchStateFile, readwiseStateFile, planFile, membersFile, s3SyncStateFile, failureStateFile}
+
+// StateBundle is the index at the start of a state bundle.
+type StateBundle struct {
+	Version    int      `json:"version"`
+	CreatedAt  string   `json:"created_at"`
+	Host       string   `json:"host,omitempty"`
+	OutputDir  string   `json:"output_dir"`  // absolute, as on the exporting machine
+	SessionDir string   `json:"session_dir"` // absolute, as on the exporting machine
+	Session    bool     `json:"session"`     // browser profile and Drive token included
+	Files      []string `json:"files"`       // bundle entries; the profile is one "session/chromium-profile/"
+}
+
+// stateDirs locates a machine's state for export or import.
+type stateDirs struct {
+	OutputDir  string
+	SessionDir string
+	EnvFile    string
+	Unlocked   string // unpacked copy of an encrypted session, "" when plaintext
+}
+
+// sessionFiles is where the session's files are read and written: the
+// unlocked copy of an encrypted session, else the session dir.
+func (d stateDirs) sessionFiles() string {
+	if d.Unlocked != "" {
+		return d.Unlocked
+	}
+	return d.SessionDir
+}
+
+// stateSource is one bundle entry and the file or directory it comes from.
+type stateSource struct {
+	name string
+	path string
+}
+
+// stateSources lists what exportState bundles from dirs.
+func stateSources(dirs stateDirs, withSession bool) []stateSource {
+	var srcs []stateSource
+	for _, name := range stateOutputFiles {
+		srcs = append(srcs, stateSource{"output/" + name, filepath.Join(dirs.OutputDir, name)})
+	}
+	srcs = append(srcs, stateSource{"session/" + relinkDriveState, filepath.Join(dirs.sessionFiles(), relinkDriveState)})
+	if withSession {
+		// An encrypted session travels as its container, never unpacked.
+		if dirs.Unlocked == "" {
+			srcs = append(srcs,
+				stateSource{"session/" + stateDriveToken, filepath.Join(dirs.SessionDir, stateDriveToken)},
+				stateSource{"session/" + stateProfileDir + "/", filepath.Join(dirs.SessionDir, stateProfileDir)},
+			)
+		}
+		srcs = append(srcs, stateSource{"session.enc", filepath.Clean(dirs.SessionDir) + ".enc"})
+	}
+	srcs = append(srcs, stateSource{"config/.env", dirs.EnvFile})
+
+	var present []stateSource
+	for _, src := range srcs {
+		if src.path != "" && fileExists(src.path) {
+			present = append(present, src)
+		}
+	}
+	return present
+}
+
+// exportState writes a gzipped state bundle of dirs to w.
+func exportState(w io.Writer, dirs stateDirs, withSession bool) (*StateBundle, error) {
+	srcs := stateSources(dirs, withSession)
+	host, _ := os.Hostname()
+	bundle := &StateBundle{
+		Version:    stateBundleVersion,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		Host:       host,
+		OutputDir:  absPath(dirs.OutputDir),
+		SessionDir: absPath(dirs.SessionDir),
+		Session:    withSession,
+	}
+	for _, src := range srcs {
+		bundle.Files = append(bundle.Files, src.name)
+	}
+	index, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	if err := tw.WriteHeader(&tar.Header{Name: stateBundleIndex, Mode: 0o600, Size: int64(len(index)), ModTime: time.Now()}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(index); err != nil {
+		return nil, err
+	}
+	for _, src := range srcs {
+		if strings.HasSuffix(src.name, "/") {
+			err = tarSessionTree(tw, src.path, src.name)
+		} else {
+			err = tarStateFile(tw, src.name, src.path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bundle %s: %w", src.path, err)
+		}
+		slog.Debug("Bundled", "entry", src.name, "path", src.path)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return bundle, zw.Close()
+}
+
+// tarStateFile adds the file at path to tw as name.
+func tarStateFile(tw *tar.Writer, name, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: info.ModTime()}); err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+// stateDest maps a bundle entry to where it is imported in dirs, or "" to
+// skip it. Entries graindl doesn't write are rejected, so a bundle can't
+// place files anywhere else.
+func stateDest(name string, dirs stateDirs) (string, error) {
+	dir, rest, _ := strings.Cut(name, "/")
+	switch {
+	case dir == "output" && slices.Contains(stateOutputFiles, rest):
+		return filepath.Join(dirs.OutputDir, rest), nil
+	case dir == "session" && (rest == relinkDriveState || rest == stateDriveToken):
+		return filepath.Join(dirs.sessionFiles(), rest), nil
+	case dir == "session" && strings.HasPrefix(rest, stateProfileDir+"/"):
+		rel := filepath.FromSlash(strings.TrimSuffix(rest, "/"))
+		if !filepath.IsLocal(rel) {
+			break
+		}
+		return filepath.Join(dirs.sessionFiles(), rel), nil
+	case name == "session.enc":
+		return filepath.Clean(dirs.SessionDir) + ".enc", nil
+	case name == "config/.env":
+		return dirs.EnvFile, nil
+	}
+	return "", fmt.Errorf("unexpected bundle entry %q", name)
+}
+
+// readStateIndex opens the bundle at path and reads its index, leaving the
+// tar reader positioned after it.
+func readStateIndex(path string) (*StateBundle, *tar.Reader, io.Closer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("not a graindl state bundle: %w", err)
+	}
+	tr := tar.NewReader(zr)
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != stateBundleIndex {
+		f.Close()
+		return nil, nil, nil, errors.New("not a graindl state bundle: missing " + stateBundleIndex)
+	}
+	var bundle StateBundle
+	if err := json.NewDecoder(tr).Decode(&bundle); err != nil {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("read %s: %w", stateBundleIndex, err)
+	}
+	if bundle.Version < 1 || bundle.Version > stateBundleVersion {
+		f.Close()
+		return nil, nil, nil, fmt.Errorf("unsupported state bundle version %d", bundle.Version)
+	}
+	return &bundle, tr, f, nil
+}
+
+// importState unpacks the bundle at path into dirs, returning its index.
+// Nothing is written when an entry would replace an existing file, unless
+// force is set; a forced import replaces the browser profile as a whole.
+// State files and .env have the old output and session dirs rewritten to
+// the new ones. A bundle carrying an encrypted session container has the
+// session's files inside it, so its loose session entries are skipped.
+func importState(path string, dirs stateDirs, force bool) (*StateBundle, error) {
+	bundle, tr, closer, err := readStateIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	hasContainer := slices.Contains(bundle.Files, "session.enc")
+	skip := func(name string) bool { return hasContainer && strings.HasPrefix(name, "session/") }
+
+	var existing []string
+	for _, name := range bundle.Files {
+		dest, err := stateDest(name, dirs)
+		if err != nil {
+			return nil, err
+		}
+		if dest != "" && !skip(name) && fileExists(dest) {
+			existing = append(existing, dest)
+		}
+	}
+	if len(existing) > 0 && !force {
+		return nil, fmt.Errorf("would replace %s (use --force)", strings.Join(existing, ", "))
+	}
+
+	var relinkers []*relinker
+	for _, move := range [][2]string{{bundle.OutputDir, absPath(dirs.OutputDir)}, {bundle.SessionDir, absPath(dirs.SessionDir)}} {
+		if filepath.IsAbs(move[0]) && move[0] != move[1] && move[0] != string(filepath.Separator) {
+			relinkers = append(relinkers, newRelinker(move[0], move[1]))
+		}
+	}
+	if err := ensureDir(dirs.OutputDir); err != nil {
+		return nil, err
+	}
+	if err := ensureDirPrivate(dirs.sessionFiles()); err != nil {
+		return nil, err
+	}
+	if force && !hasContainer && slices.Contains(bundle.Files, "session/"+stateProfileDir+"/") {
+		if err := os.RemoveAll(filepath.Join(dirs.sessionFiles(), stateProfileDir)); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return bundle, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		dest, err := stateDest(hdr.Name, dirs)
+		if err != nil {
+			return nil, err
+		}
+		if dest == "" || skip(hdr.Name) {
+			continue // --env "", or held by the imported container
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			if err := ensureDirPrivate(dest); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+		if err := writeImportedState(hdr.Name, dest, data, relinkers); err != nil {
+			return nil, fmt.Errorf("import %s: %w", hdr.Name, err)
+		}
+		_ = os.Chtimes(dest, hdr.ModTime, hdr.ModTime)
+	}
+}
+
+// writeImportedState writes one bundle entry to dest, rewriting moved paths
+// in everything but the browser profile and the encrypted session.
+func writeImportedState(name, dest string, data []byte, relinkers []*relinker) error {
+	if strings.HasPrefix(name, "session/"+stateProfileDir+"/") || name == "session.enc" {
+		if err := ensureDirPrivate(filepath.Dir(dest)); err != nil {
+			return err
+		}
+		return writeFile(dest, data)
+	}
+	for _, r := range relinkers {
+		data, _ = r.rewrite(data)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	if strings.HasPrefix(filepath.Base(dest), ".graindl-") || filepath.Base(dest) == relinkDriveState {
+		return writeStateFile(dest, data)
+	}
+	return writeFile(dest, data)
+}
+
+func runState(args []string) int {
+	if len(args) == 0 || (args[0] != "export" && args[0] != "import") {
+		fmt.Fprintln(os.Stderr, "usage: graindl state export|import [flags] <bundle.tar.gz>")
+		return 2
+	}
+	op := args[0]
+
+	dotenv := loadDotEnv(".env")
+	var dirs stateDirs
+	fs := flag.NewFlagSet("state "+op, flag.ContinueOnError)
+	fs.StringVar(&dirs.OutputDir, "output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory holding the state files")
+	fs.StringVar(&dirs.SessionDir, "session-dir", coalesce(envGet(dotenv, "GRAIN_SESSION_DIR"), "./.grain-session"), "Session dir (Drive sync state, browser profile)")
+	fs.StringVar(&dirs.EnvFile, "env", ".env", "Config file to bundle or restore (empty to skip)")
+	withSession := fs.Bool("with-session", false, "Export: include the browser profile (Grain cookies) and Drive token")
+	force := fs.Bool("force", false, "Import: replace existing state files")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: graindl state %s [flags] <bundle.tar.gz>\n", op)
+		return 2
+	}
+	path := fs.Arg(0)
+	passphrase := envGet(dotenv, "GRAIN_SESSION_PASSPHRASE")
+
+	if op == "import" {
+		if *withSession {
+			slog.Warn("--with-session only applies to state export; ignoring")
+		}
+		// Session files go inside an existing encrypted session, unless the
+		// bundle brings its own container to replace it.
+		var sess *encryptedSession
+		if peek, _, closer, err := readStateIndex(path); err == nil {
+			closer.Close()
+			if !slices.Contains(peek.Files, "session.enc") {
+				if sess, err = unlockStateSession(&dirs, passphrase); err != nil {
+					slog.Error("Encrypted session", "error", err)
+					return 1
+				}
+			}
+		}
+		bundle, err := importState(path, dirs, *force)
+		if err != nil {
+			if sess != nil {
+				sess.Discard()
+			}
+			slog.Error("State import failed", "error", err)
+			return 1
+		}
+		if !closeSession(sess) {
+			return 1
+		}
+		slog.Info("State imported", "from", bundle.Host, "created", bundle.CreatedAt, "entries", len(bundle.Files), "session", bundle.Session)
+		if old := bundle.OutputDir; old != absPath(dirs.OutputDir) {
+			slog.Info(fmt.Sprintf("The archive used to live at %s; after copying it, run `graindl relink --from %s --to %s` to rewrite paths in notes", old, old, absPath(dirs.OutputDir)))
+		}
+		return 0
+	}
+
+	if *force {
+		slog.Warn("--force only applies to state import; ignoring")
+	}
+	if _, err := os.Stat(dirs.OutputDir); err != nil {
+		slog.Error("Archive directory not found", "path", dirs.OutputDir)
+		return 1
+	}
+	sess, err := unlockStateSession(&dirs, passphrase)
+	if err != nil {
+		slog.Error("Encrypted session", "error", err)
+		return 1
+	}
+	if sess != nil {
+		defer sess.Discard()
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		slog.Error("State export failed", "error", err)
+		return 1
+	}
+	bundle, err := exportState(f, dirs, *withSession)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		slog.Error("State export failed", "error", err)
+		return 1
+	}
+	slog.Info("State exported", "path", path, "entries", len(bundle.Files), "session", bundle.Session)
+	if *withSession {
+		slog.Warn("The bundle holds Grain cookies and the Drive token; keep it private and delete it after importing", "path", path)
+	}
+	return 0
+}
+
+// unlockStateSession unlocks an encrypted session dir for state export or
+// import, recording the unpacked copy in dirs.Unlocked.
+func unlockStateSession(dirs *stateDirs, passphrase string) (*encryptedSession, error) {
+	cfg := Config{SessionDir: dirs.SessionDir}
+	sess, err := unlockSession(&cfg, passphrase)
+	if sess != nil {
+		dirs.Unlocked = sess.Dir()
+	}
+	return sess, err
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeStateBundle exports dirs into a bundle file and returns its path.
+func writeStateBundle(t *testing.T, dirs stateDirs, withSession bool) (string, *StateBundle) {
+	t.Helper()
+	var buf bytes.Buffer
+	bundle, err := exportState(&buf, dirs, withSession)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "state.tar.gz")
+	os.WriteFile(path, buf.Bytes(), 0o600)
+	return path, bundle
+}
+
+func TestStateBundleRoundTrip(t *testing.T) {
+	old := t.TempDir()
+	src := stateDirs{OutputDir: filepath.Join(old, "recordings"), SessionDir: filepath.Join(old, "session"), EnvFile: filepath.Join(old, ".env")}
+	os.MkdirAll(filepath.Join(src.SessionDir, stateProfileDir, "Default", "Cache"), 0o700)
+	os.MkdirAll(src.OutputDir, 0o755)
+	os.WriteFile(filepath.Join(src.OutputDir, "_export-manifest.json"), []byte(`{"ok":1}`), 0o600)
+	os.WriteFile(filepath.Join(src.OutputDir, videoStateFile), []byte(`{"path":"`+src.OutputDir+`/2025-01-15/m1.mp4"}`), 0o600)
+	os.WriteFile(filepath.Join(src.OutputDir, "2025-01-15.json"), []byte(`{}`), 0o600)
+	os.WriteFile(filepath.Join(src.SessionDir, relinkDriveState), []byte(`{"files":{"`+src.OutputDir+`/a.json":{}}}`), 0o600)
+	os.WriteFile(filepath.Join(src.SessionDir, stateDriveToken), []byte(`{"access_token":"x"}`), 0o600)
+	os.WriteFile(filepath.Join(src.SessionDir, stateProfileDir, "Default", "Cookies"), []byte("cookies"), 0o600)
+	os.WriteFile(filepath.Join(src.SessionDir, stateProfileDir, "Default", "Cache", "blob"), []byte("cache"), 0o600)
+	os.WriteFile(src.EnvFile, []byte("GRAIN_OUTPUT_DIR="+src.OutputDir+"\n"), 0o600)
+
+	path, bundle := writeStateBundle(t, src, false)
+	want := []string{"output/_export-manifest.json", "output/" + videoStateFile, "session/" + relinkDriveState, "config/.env"}
+	if strings.Join(bundle.Files, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", bundle.Files, want)
+	}
+
+	dst := stateDirs{OutputDir: filepath.Join(t.TempDir(), "archive"), SessionDir: filepath.Join(t.TempDir(), "s"), EnvFile: filepath.Join(t.TempDir(), ".env")}
+	if _, err := importState(path, dst, false); err != nil {
+		t.Fatal(err)
+	}
+	video, _ := os.ReadFile(filepath.Join(dst.OutputDir, videoStateFile))
+	drive, _ := os.ReadFile(filepath.Join(dst.SessionDir, relinkDriveState))
+	env, _ := os.ReadFile(dst.EnvFile)
+	if !strings.Contains(string(video), dst.OutputDir+"/2025-01-15/m1.mp4") || !strings.Contains(string(drive), dst.OutputDir+"/a.json") {
+		t.Errorf("paths not rewritten:\n%s\n%s", video, drive)
+	}
+	if string(env) != "GRAIN_OUTPUT_DIR="+dst.OutputDir+"\n" {
+		t.Errorf(".env = %q", env)
+	}
+	if fileExists(filepath.Join(dst.OutputDir, "2025-01-15.json")) || fileExists(filepath.Join(dst.SessionDir, stateDriveToken)) {
+		t.Error("bundled more than the state files")
+	}
+
+	// The same bundle again: refused, then replaced with --force.
+	if _, err := importState(path, dst, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("second import: err = %v", err)
+	}
+	if _, err := importState(path, dst, true); err != nil {
+		t.Errorf("forced import: %v", err)
+	}
+
+	path, bundle = writeStateBundle(t, src, true)
+	if !bundle.Session {
+		t.Error("session not recorded in the index")
+	}
+	dst.SessionDir = filepath.Join(t.TempDir(), "s")
+	if _, err := importState(path, dst, true); err != nil {
+		t.Fatal(err)
+	}
+	if cookies, _ := os.ReadFile(filepath.Join(dst.SessionDir, stateProfileDir, "Default", "Cookies")); string(cookies) != "cookies" {
+		t.Errorf("cookies = %q", cookies)
+	}
+	if !fileExists(filepath.Join(dst.SessionDir, stateDriveToken)) {
+		t.Error("Drive token missing")
+	}
+	if fileExists(filepath.Join(dst.SessionDir, stateProfileDir, "Default", "Cache")) {
+		t.Error("browser cache bundled")
+	}
+}
+
+func TestStateImportRejectsForeignEntries(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	index := `{"version":1,"files":[]}`
+	tw.WriteHeader(&tar.Header{Name: stateBundleIndex, Mode: 0o600, Size: int64(len(index))})
+	tw.Write([]byte(index))
+	for _, name := range []string{"output/2025-01-15/m1.json", "session/chromium-profile/../../x"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: 1})
+		tw.Write([]byte("x"))
+	}
+	tw.Close()
+	zw.Close()
+	path := filepath.Join(t.TempDir(), "evil.tar.gz")
+	os.WriteFile(path, buf.Bytes(), 0o600)
+
+	dst := stateDirs{OutputDir: t.TempDir(), SessionDir: t.TempDir()}
+	if _, err := importState(path, dst, false); err == nil || !strings.Contains(err.Error(), "unexpected bundle entry") {
+		t.Errorf("err = %v", err)
+	}
+	if fileExists(filepath.Join(dst.OutputDir, "2025-01-15")) {
+		t.Error("foreign entry written")
+	}
+
+	notBundle := filepath.Join(t.TempDir(), "x.tar.gz")
+	os.WriteFile(notBundle, []byte("plain"), 0o600)
+	if _, err := importState(notBundle, dst, false); err == nil {
+		t.Error("non-bundle accepted")
+	}
+}
+
+func TestStateBundleEncryptedSession(t *testing.T) {
+	fastSessionKDF(t)
+	newSession := func(files map[string]string) string {
+		t.Helper()
+		dir := filepath.Join(t.TempDir(), "sess")
+		s, err := openEncryptedSession(dir, "pw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rel, body := range files {
+			writeSessionFile(t, s.Dir(), rel, body)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	out := t.TempDir()
+	os.WriteFile(filepath.Join(out, "_export-manifest.json"), []byte(`{}`), 0o600)
+	srcSession := newSession(map[string]string{relinkDriveState: `{"files":{"a.json":{}}}`, stateProfileDir + "/Default/Cookies": "cookies"})
+	bundlePath := filepath.Join(t.TempDir(), "state.tar.gz")
+
+	t.Setenv("GRAIN_SESSION_PASSPHRASE", "")
+	if code := runState([]string{"export", "--output", out, "--session-dir", srcSession, "--env", "", bundlePath}); code != 1 {
+		t.Errorf("export without passphrase: exit %d, want 1", code)
+	}
+	t.Setenv("GRAIN_SESSION_PASSPHRASE", "pw")
+
+	// The Drive state comes out of the container; the profile stays in it.
+	if code := runState([]string{"export", "--output", out, "--session-dir", srcSession, "--env", "", bundlePath}); code != 0 {
+		t.Fatalf("export: exit %d", code)
+	}
+	bundle, _, closer, err := readStateIndex(bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closer.Close()
+	if want := "output/_export-manifest.json,session/" + relinkDriveState; strings.Join(bundle.Files, ",") != want {
+		t.Errorf("files = %v, want %s", bundle.Files, want)
+	}
+
+	// Imported into another encrypted session, it lands inside the container.
+	dstSession := newSession(nil)
+	if code := runState([]string{"import", "--output", t.TempDir(), "--session-dir", dstSession, "--env", "", bundlePath}); code != 0 {
+		t.Fatalf("import: exit %d", code)
+	}
+	if fileExists(dstSession) {
+		t.Error("plaintext session dir created beside the container")
+	}
+	s, err := openEncryptedSession(dstSession, "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Discard()
+	if data, _ := os.ReadFile(filepath.Join(s.Dir(), relinkDriveState)); !strings.Contains(string(data), "a.json") {
+		t.Errorf("drive state in container = %q", data)
+	}
+
+	// --with-session carries the container, never the unpacked profile.
+	if code := runState([]string{"export", "--with-session", "--output", out, "--session-dir", srcSession, "--env", "", bundlePath}); code != 0 {
+		t.Fatalf("export --with-session: exit %d", code)
+	}
+	bundle, _, closer, _ = readStateIndex(bundlePath)
+	closer.Close()
+	if want := "output/_export-manifest.json,session/" + relinkDriveState + ",session.enc"; strings.Join(bundle.Files, ",") != want {
+		t.Errorf("files = %v, want %s", bundle.Files, want)
+	}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ── Stats ───────────────────────────────────────────────────────────────────
+//
+// `graindl stats` computes archive-wide aggregates from the metadata files:
+// meetings and hours per week, total and average duration, the most
+// frequent participants, and how many meetings have a transcript. It reads
+// only the local archive, like digest, and prints a terminal table, JSON,
+// or markdown.
+
+const (
+	statsDefaultWeeks = 12
+	statsDefaultTop   = 10
+)
+
+// ArchiveStats is the `graindl stats` report. It is also the --format json
+// output.
+type ArchiveStats struct {
+	GeneratedAt        string             `json:"generated_at"`
+	Since              string             `json:"since,omitempty"` // --since cutoff, YYYY-MM-DD
+	FirstMeeting       string             `json:"first_meeting,omitempty"`
+	LastMeeting        string             `json:"last_meeting,omitempty"`
+	Meetings           int                `json:"meetings"`
+	TotalHours         float64            `json:"total_hours"`
+	AvgMinutes         float64            `json:"avg_minutes"`   // over meetings with a known duration
+	WithDuration       int                `json:"with_duration"` // meetings with a known duration
+	WithTranscript     int                `json:"with_transcript"`
+	TranscriptCoverage float64            `json:"transcript_coverage_pct"`
+	PerWeek            []WeekStats        `json:"per_week"`
+	TopParticipants    []ParticipantStats `json:"top_participants"`
+}
+
+// WeekStats is one ISO week (Monday to Sunday) of meetings.
+type WeekStats struct {
+	Week     string  `json:"week"`  // "2025-W09"
+	Start    string  `json:"start"` // Monday, YYYY-MM-DD
+	Meetings int     `json:"meetings"`
+	Hours    float64 `json:"hours"`
+}
+
+// ParticipantStats is one participant's share of the archive.
+type ParticipantStats struct {
+	Name     string  `json:"name"`
+	Meetings int     `json:"meetings"`
+	Hours    float64 `json:"hours"`
+}
+
+// statsOptions controls computeStats.
+type statsOptions struct {
+	Since time.Time
+	Weeks int // most recent weeks in PerWeek (0 = all)
+	Top   int // participants in TopParticipants
+}
+
+func runStats(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	outputDir := fs.String("output", coalesce(envGet(dotenv, "GRAIN_OUTPUT_DIR"), "./recordings"), "Archive directory to analyze")
+	since := fs.String("since", "", "Only count meetings since (e.g. 90d, 12w, 2024-11-01; default all)")
+	format := fs.String("format", "text", "Output format: text (table), json, markdown")
+	out := fs.String("out", "-", "Write the report to this file (- for stdout)")
+	weeks := fs.Int("weeks", statsDefaultWeeks, "Most recent weeks in the per-week breakdown (0 = all)")
+	top := fs.Int("top", statsDefaultTop, "Number of top participants to list")
+	verbose := fs.Bool("verbose", envBool(dotenv, "GRAIN_VERBOSE"), "Verbose output")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	setupLogger(envGet(dotenv, "GRAIN_LOG_FORMAT"), *verbose)
+
+	render, ok := map[string]func(io.Writer, *ArchiveStats) error{
+		"text":     writeStatsText,
+		"json":     writeStatsJSON,
+		"markdown": writeStatsMarkdown,
+	}[strings.ToLower(*format)]
+	if !ok {
+		slog.Error("Invalid --format (must be text, json, or markdown)", "value", *format)
+		return 2
+	}
+
+	now := time.Now()
+	cutoff, err := parseSince(*since, now)
+	if err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	if err := checkUnrouted("stats", *outputDir); err != nil {
+		slog.Error(err.Error())
+		return 1
+	}
+	entries, err := scanArchive(*outputDir)
+	if err != nil {
+		slog.Error("Stats failed", "error", err)
+		return 1
+	}
+	st := computeStats(filterSince(entries, cutoff), *outputDir, now, statsOptions{
+		Since: cutoff,
+		Weeks: max(*weeks, 0),
+		Top:   max(*top, 0),
+	})
+
+	if *out == "-" {
+		if err := render(os.Stdout, st); err != nil {
+			slog.Error("Stats write failed", "error", err)
+			return 1
+		}
+		return 0
+	}
+	var b strings.Builder
+	_ = render(&b, st)
+	if err := os.WriteFile(*out, []byte(b.String()), 0o600); err != nil {
+		slog.Error("Stats write failed", "error", err)
+		return 1
+	}
+	slog.Info(fmt.Sprintf("Stats: %d meeting(s) → %s", st.Meetings, *out))
+	return 0
+}
+
+// computeStats aggregates entries (sorted by date, as scanArchive returns
+// them).
+func computeStats(entries []*ArchiveEntry, outputDir string, now time.Time, opts statsOptions) *ArchiveStats {
+	st := &ArchiveStats{
+		GeneratedAt:     now.UTC().Format(time.RFC3339),
+		Meetings:        len(entries),
+		PerWeek:         []WeekStats{},
+		TopParticipants: []ParticipantStats{},
+	}
+	if !opts.Since.IsZero() {
+		st.Since = opts.Since.Format(time.DateOnly)
+	}
+	if len(entries) == 0 {
+		return st
+	}
+	st.FirstMeeting = entries[0].Date().Format(time.DateOnly)
+	st.LastMeeting = entries[len(entries)-1].Date().Format(time.DateOnly)
+
+	local := NewLocalStorage(outputDir)
+	weeks := map[time.Time]*WeekStats{}
+	people := map[string]*ParticipantStats{} // lower-cased name →
+	var totalSecs float64
+	for _, e := range entries {
+		secs := durationSeconds(e.Meta.DurationSeconds)
+		if secs > 0 {
+			totalSecs += secs
+			st.WithDuration++
+		}
+		if artifactExists(local, e.RelBase+".transcript.txt") {
+			st.WithTranscript++
+		}
+
+		monday := weekStart(e.Date())
+		w := weeks[monday]
+		if w == nil {
+			y, n := monday.ISOWeek()
+			w = &WeekStats{Week: fmt.Sprintf("%d-W%02d", y, n), Start: monday.Format(time.DateOnly)}
+			weeks[monday] = w
+		}
+		w.Meetings++
+		w.Hours += secs / 3600
+
+		seen := map[string]bool{}
+		for _, name := range flattenStringSlice(e.Meta.Participants) {
+			name = strings.Join(strings.Fields(name), " ")
+			key := strings.ToLower(name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			p := people[key]
+			if p == nil {
+				p = &ParticipantStats{Name: name}
+				people[key] = p
+			}
+			p.Meetings++
+			p.Hours += secs / 3600
+		}
+	}
+
+	st.TotalHours = round2(totalSecs / 3600)
+	if st.WithDuration > 0 {
+		st.AvgMinutes = round2(totalSecs / float64(st.WithDuration) / 60)
+	}
+	st.TranscriptCoverage = round2(100 * float64(st.WithTranscript) / float64(st.Meetings))
+
+	// Every week from the first meeting to the last, empty ones included.
+	first, last := weekStart(entries[0].Date()), weekStart(entries[len(entries)-1].Date())
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 7) {
+		w := weeks[d]
+		if w == nil {
+			y, n := d.ISOWeek()
+			w = &WeekStats{Week: fmt.Sprintf("%d-W%02d", y, n), Start: d.Format(time.DateOnly)}
+		}
+		w.Hours = round2(w.Hours)
+		st.PerWeek = append(st.PerWeek, *w)
+	}
+	if opts.Weeks > 0 && len(st.PerWeek) > opts.Weeks {
+		st.PerWeek = st.PerWeek[len(st.PerWeek)-opts.Weeks:]
+	}
+
+	for _, p := range people {
+		p.Hours = round2(p.Hours)
+		st.TopParticipants = append(st.TopParticipants, *p)
+	}
+	sort.Slice(st.TopParticipants, func(i, j int) bool {
+		a, b := st.TopParticipants[i], st.TopParticipants[j]
+		if a.Meetings != b.Meetings {
+			return a.Meetings > b.Meetings
+		}
+		if a.Hours != b.Hours {
+			return a.Hours > b.Hours
+		}
+		return a.Name < b.Name
+	})
+	if len(st.TopParticipants) > opts.Top {
+		st.TopParticipants = st.TopParticipants[:opts.Top]
+	}
+	return st
+}
+
+// weekStart returns midnight on the Monday of t's ISO week.
+func weekStart(t time.Time) time.Time {
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+}
+
+func round2(f float64) float64 { return math.Round(f*100) / 100 }
+
+// statsHours formats hours for the text and markdown reports.
+func statsHours(h float64) string { return fmt.Sprintf("%.1f h", h) }
+
+func writeStatsJSON(w io.Writer, st *ArchiveStats) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+func writeStatsText(w io.Writer, st *ArchiveStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Meetings\t%d\n", st.Meetings)
+	if st.Meetings > 0 {
+		fmt.Fprintf(tw, "Range\t%s – %s\n", st.FirstMeeting, st.LastMeeting)
+	}
+	fmt.Fprintf(tw, "Total recorded\t%s\n", statsHours(st.TotalHours))
+	fmt.Fprintf(tw, "Average duration\t%.0f min (%d with a duration)\n", st.AvgMinutes, st.WithDuration)
+	fmt.Fprintf(tw, "Transcript coverage\t%.1f%% (%d of %d)\n", st.TranscriptCoverage, st.WithTranscript, st.Meetings)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(st.PerWeek) > 0 {
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "\nWEEK\tSTART\tMEETINGS\tHOURS\t")
+		for _, wk := range st.PerWeek {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", wk.Week, wk.Start, wk.Meetings, statsHours(wk.Hours), statsBar(wk.Meetings, st.PerWeek))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(st.TopParticipants) > 0 {
+		tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "\nPARTICIPANT\tMEETINGS\tHOURS")
+		for _, p := range st.TopParticipants {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", p.Name, p.Meetings, statsHours(p.Hours))
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// statsBar draws n as a bar scaled to the busiest week.
+func statsBar(n int, weeks []WeekStats) string {
+	peak := 0
+	for _, w := range weeks {
+		peak = max(peak, w.Meetings)
+	}
+	if peak == 0 || n == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, n*20/peak))
+}
+
+func writeStatsMarkdown(w io.Writer, st *ArchiveStats) error {
+	var b strings.Builder
+	b.WriteString("# Archive Stats\n\n")
+	if st.Meetings > 0 {
+		fmt.Fprintf(&b, "_%s – %s_\n\n", st.FirstMeeting, st.LastMeeting)
+	}
+	b.WriteString("| Metric | Value |\n| --- | --- |\n")
+	fmt.Fprintf(&b, "| Meetings | %d |\n", st.Meetings)
+	fmt.Fprintf(&b, "| Total recorded | %s |\n", statsHours(st.TotalHours))
+	fmt.Fprintf(&b, "| Average duration | %.0f min |\n", st.AvgMinutes)
+	fmt.Fprintf(&b, "| Transcript coverage | %.1f%% (%d of %d) |\n", st.TranscriptCoverage, st.WithTranscript, st.Meetings)
+
+	if len(st.PerWeek) > 0 {
+		b.WriteString("\n## Meetings per Week\n\n| Week | Start | Meetings | Hours |\n| --- | --- | ---: | ---: |\n")
+		for _, wk := range st.PerWeek {
+			fmt.Fprintf(&b, "| %s | %s | %d | %.1f |\n", wk.Week, wk.Start, wk.Meetings, wk.Hours)
+		}
+	}
+	if len(st.TopParticipants) > 0 {
+		b.WriteString("\n## Top Participants\n\n| Participant | Meetings | Hours |\n| --- | ---: | ---: |\n")
+		for _, p := range st.TopParticipants {
+			fmt.Fprintf(&b, "| %s | %d | %.1f |\n", minutesCell(p.Name), p.Meetings, p.Hours)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func statsArchive(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-01-06", &Metadata{ID: "m1", DurationSeconds: float64(3600), Participants: []string{"Alice", "Bob"}})
+	writeArchiveMeta(t, dir, "2025-01-08", &Metadata{ID: "m2", DurationSeconds: "30:00", Participants: []any{map[string]any{"name": "alice"}, "Carol"}})
+	writeArchiveMeta(t, dir, "2025-01-22", &Metadata{ID: "m3", Participants: []string{"Bob", "Bob"}})
+	writeArchiveMeta(t, dir, "2025-01-23", &Metadata{ID: "m4", DurationSeconds: float64(1800), Participants: []string{"Alice | Ops"}})
+	os.WriteFile(filepath.Join(dir, "2025-01-06", "m1.transcript.txt"), []byte("00:00:01 Alice: hi"), 0o600)
+	os.WriteFile(filepath.Join(dir, "2025-01-22", "m3.transcript.txt.zst"), zstdCompress([]byte("00:00:01 Bob: hi")), 0o600)
+	return dir
+}
+
+func TestComputeStats(t *testing.T) {
+	dir := statsArchive(t)
+	entries, err := scanArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := computeStats(entries, dir, time.Now(), statsOptions{Top: 2})
+
+	if st.Meetings != 4 || st.TotalHours != 2 || st.WithDuration != 3 || st.AvgMinutes != 40 {
+		t.Errorf("totals = %d meetings, %v h, %d with duration, %v min avg", st.Meetings, st.TotalHours, st.WithDuration, st.AvgMinutes)
+	}
+	if st.WithTranscript != 2 || st.TranscriptCoverage != 50 {
+		t.Errorf("transcripts = %d (%v%%)", st.WithTranscript, st.TranscriptCoverage)
+	}
+	if st.FirstMeeting != "2025-01-06" || st.LastMeeting != "2025-01-23" {
+		t.Errorf("range = %s – %s", st.FirstMeeting, st.LastMeeting)
+	}
+
+	// Three ISO weeks, the empty middle one included.
+	var weeks []string
+	for _, w := range st.PerWeek {
+		weeks = append(weeks, w.Week+"="+w.Start)
+	}
+	if got := strings.Join(weeks, ","); got != "2025-W02=2025-01-06,2025-W03=2025-01-13,2025-W04=2025-01-20" {
+		t.Errorf("weeks = %s", got)
+	}
+	if w := st.PerWeek[0]; w.Meetings != 2 || w.Hours != 1.5 {
+		t.Errorf("first week = %+v", w)
+	}
+
+	// Names match case-insensitively and count once per meeting.
+	if len(st.TopParticipants) != 2 {
+		t.Fatalf("top = %+v", st.TopParticipants)
+	}
+	if p := st.TopParticipants[0]; p.Name != "Alice" || p.Meetings != 2 || p.Hours != 1.5 {
+		t.Errorf("top participant = %+v", p)
+	}
+	if p := st.TopParticipants[1]; p.Name != "Bob" || p.Meetings != 2 || p.Hours != 1 {
+		t.Errorf("second participant = %+v", p)
+	}
+
+	if st := computeStats(entries, dir, time.Now(), statsOptions{Weeks: 1, Top: 10}); len(st.PerWeek) != 1 || st.PerWeek[0].Week != "2025-W04" {
+		t.Errorf("--weeks 1 = %+v", st.PerWeek)
+	}
+}
+
+func TestStatsEmptyArchive(t *testing.T) {
+	st := computeStats(nil, t.TempDir(), time.Now(), statsOptions{Top: 10})
+	var b strings.Builder
+	if err := writeStatsJSON(&b, st); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"per_week": []`) || !strings.Contains(b.String(), `"meetings": 0`) {
+		t.Errorf("empty JSON:\n%s", b.String())
+	}
+}
+
+func TestStatsRenderers(t *testing.T) {
+	dir := statsArchive(t)
+	entries, _ := scanArchive(dir)
+	st := computeStats(entries, dir, time.Now(), statsOptions{Top: 10})
+
+	var text strings.Builder
+	if err := writeStatsText(&text, st); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Meetings", "Total recorded       2.0 h", "Transcript coverage  50.0% (2 of 4)", "2025-W02  2025-01-06  2", "Alice"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var md strings.Builder
+	_ = writeStatsMarkdown(&md, st)
+	for _, want := range []string{"# Archive Stats", "| Meetings | 4 |", "## Meetings per Week", "| 2025-W03 | 2025-01-13 | 0 | 0.0 |", `| Alice \| Ops | 1 | 0.5 |`} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var js strings.Builder
+	_ = writeStatsJSON(&js, st)
+	var back ArchiveStats
+	if err := json.Unmarshal([]byte(js.String()), &back); err != nil || back.Meetings != 4 || len(back.TopParticipants) != 4 {
+		t.Errorf("JSON round trip: %+v, %v", back, err)
+	}
+}
+
+func TestRunStatsFlags(t *testing.T) {
+	dir := statsArchive(t)
+	out := filepath.Join(t.TempDir(), "stats.json")
+	if code := runStats([]string{"--output", dir, "--format", "json", "--out", out}); code != 0 {
+		t.Fatalf("exit = %d", code)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(data), `"meetings": 4`) {
+		t.Errorf("report = %s, %v", data, err)
+	}
+	if code := runStats([]string{"--output", dir, "--format", "csv"}); code != 2 {
+		t.Errorf("bad --format exit = %d, want 2", code)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ── Stdout Streaming ────────────────────────────────────────────────────────
+//
+// `graindl export --id X --stdout video|transcript` writes one artifact of
+// one meeting to stdout instead of the archive, so it can feed a pipeline
+// (`graindl export --id X --stdout video | ffmpeg -i - ...`) without a copy
+// landing on disk first. Nothing is written to the output directory: no
+// metadata, no manifest, no sync. Logs stay on stderr.
+
+// Artifacts --stdout can stream.
+const (
+	stdoutVideo      = "video"
+	stdoutTranscript = "transcript"
+)
+
+var stdoutArtifacts = []string{stdoutVideo, stdoutTranscript}
+
+// parseStdoutArtifact validates --stdout.
+func parseStdoutArtifact(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, a := range stdoutArtifacts {
+		if s == a {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("invalid --stdout %q (use %s)", s, strings.Join(stdoutArtifacts, " or "))
+}
+
+// runStdout streams the --stdout artifact of the --id meeting to stdout.
+func (e *Exporter) runStdout(ctx context.Context) error {
+	id := e.cfg.MeetingID
+	if !validID.MatchString(id) {
+		return fmt.Errorf("invalid meeting ID: %q", id)
+	}
+	b, err := e.lazyBrowser()
+	if err != nil {
+		return err
+	}
+	if _, err := b.Login(ctx); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+	pageURL := meetingURL(id)
+
+	if e.cfg.Stdout == stdoutTranscript {
+		if err := e.cfg.Chaos.fail(chaosScrape, id); err != nil {
+			return err
+		}
+		data, err := b.ScrapeMeetingPage(ctx, pageURL)
+		if err != nil {
+			return fmt.Errorf("scrape: %w", err)
+		}
+		if data == nil || data.Transcript == "" {
+			return fmt.Errorf("meeting %s has no transcript", id)
+		}
+		_, err = io.WriteString(os.Stdout, data.Transcript)
+		return err
+	}
+
+	videoURL := b.FindVideoSource(ctx, pageURL)
+	if videoURL == "" {
+		return fmt.Errorf("no video source found for meeting %s", id)
+	}
+	slog.Info("Streaming video to stdout", "id", id)
+	if strings.Contains(videoURL, ".m3u8") {
+		if err := checkFFmpeg(); err != nil {
+			return fmt.Errorf("HLS video: %w", err)
+		}
+		return streamFFmpeg(ctx, os.Stdout, e.cfg.Verbose, ffmpegIdentityArgs(e.cfg), videoURL)
+	}
+	n, err := b.streamViaHTTP(ctx, videoURL, os.Stdout)
+	if err != nil {
+		return err
+	}
+	slog.Info("Video streamed", "id", id, "size", formatBytes(n))
+	return nil
+}
+
+// streamViaHTTP writes a direct video URL to w with the browser session's
+// cookies.
+func (b *Browser) streamViaHTTP(ctx context.Context, videoURL string, w io.Writer) (int64, error) {
+	cookies, err := b.exportCookies()
+	if err != nil {
+		slog.Debug("Could not export cookies for video download", "error", err)
+	}
+	d := newVideoDownloader(cookies, b.throttle)
+	d.client.Transport = withChaos(b.cfg.Chaos, chaosDownload, true, withAPIIdentity(b.cfg, d.client.Transport))
+	return d.Stream(ctx, videoURL, w)
+}
+
+// Stream writes videoURL to w. Unlike Download it cannot resume, so it
+// retries only failures that happen before the first byte is written.
+func (d *videoDownloader) Stream(ctx context.Context, videoURL string, w io.Writer) (int64, error) {
+	var lastErr error
+	for attempt := range d.retries {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(d.backoff << (attempt - 1)):
+			}
+		}
+		pw := &progressWriter{w: w, total: -1, interval: d.interval, last: time.Now()}
+		err := d.stream(ctx, videoURL, pw)
+		if err == nil {
+			return pw.done, nil
+		}
+		if pw.done > 0 {
+			return pw.done, fmt.Errorf("stream interrupted at %s: %w", formatBytes(pw.done), err)
+		}
+		var perm *permanentDLError
+		if errors.As(err, &perm) || ctx.Err() != nil {
+			return 0, err
+		}
+		lastErr = err
+		slog.Debug("Video stream attempt failed", "attempt", attempt+1, "error", err)
+	}
+	return 0, lastErr
+}
+
+func (d *videoDownloader) stream(ctx context.Context, videoURL string, pw *progressWriter) error {
+	if d.pace != nil {
+		if err := d.pace.WaitHost(ctx, hostOf(videoURL)); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", videoURL, nil)
+	if err != nil {
+		return &permanentDLError{err}
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		return &permanentDLError{fmt.Errorf("HTTP %d", resp.StatusCode)}
+	}
+	if ct := resp.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/") {
+		return &permanentDLError{fmt.Errorf("unexpected content type %q", ct)}
+	}
+	pw.total = resp.ContentLength
+	if _, err := io.Copy(pw, resp.Body); err != nil {
+		return err
+	}
+	if pw.total >= 0 && pw.done != pw.total {
+		return fmt.Errorf("short stream: got %s of %s", formatBytes(pw.done), formatBytes(pw.total))
+	}
+	return nil
+}
+
+// streamFFmpeg remuxes an HLS stream into fragmented MP4 on w; a plain MP4
+// needs a seekable output for its index.
+func streamFFmpeg(ctx context.Context, w io.Writer, verbose bool, inputArgs []string, playlistURL string) error {
+	args := []string{"-loglevel", "error"}
+	if verbose {
+		args = nil
+	}
+	args = append(append(args, inputArgs...), "-i", playlistURL, "-c", "copy",
+		"-bsf:a", "aac_adtstoasc", "-movflags", "frag_keyframe+empty_moov", "-f", "mp4", "pipe:1")
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = w
+	if verbose {
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg hls stream: %w", err)
+	}
+	return nil
+}
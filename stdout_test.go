@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseStdoutArtifact(t *testing.T) {
+	for in, want := range map[string]string{"video": stdoutVideo, " Transcript ": stdoutTranscript} {
+		if got, err := parseStdoutArtifact(in); err != nil || got != want {
+			t.Errorf("parseStdoutArtifact(%q) = %q, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"audio", "", "metadata"} {
+		if _, err := parseStdoutArtifact(in); err == nil {
+			t.Errorf("parseStdoutArtifact(%q): want error", in)
+		}
+	}
+}
+
+func TestVideoDownloaderStream(t *testing.T) {
+	body := testVideoBody()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/login":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>sign in</html>"))
+		case r.URL.Path == "/flaky" && calls.Add(1) == 1:
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "video/mp4")
+			w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	d := newVideoDownloader(nil, nil)
+	d.backoff = time.Millisecond
+	var out bytes.Buffer
+	n, err := d.Stream(context.Background(), srv.URL+"/flaky", &out)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if n != int64(len(body)) || !bytes.Equal(out.Bytes(), body) {
+		t.Errorf("streamed %d bytes, content match = %v", n, bytes.Equal(out.Bytes(), body))
+	}
+	if calls.Load() != 2 {
+		t.Errorf("%d requests, want 2 (one retry before the first byte)", calls.Load())
+	}
+
+	out.Reset()
+	if _, err := d.Stream(context.Background(), srv.URL+"/login", &out); err == nil || out.Len() != 0 {
+		t.Errorf("login page: err = %v, wrote %d bytes", err, out.Len())
+	}
+}
+
+func TestVideoDownloaderStreamNoRetryAfterFirstByte(t *testing.T) {
+	body := testVideoBody()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Length", "65536")
+		w.Write(body[:len(body)/2])
+		panic(http.ErrAbortHandler)
+	}))
+	defer srv.Close()
+
+	d := newVideoDownloader(nil, nil)
+	d.backoff = time.Millisecond
+	var out bytes.Buffer
+	n, err := d.Stream(context.Background(), srv.URL, &out)
+	if err == nil {
+		t.Fatal("interrupted stream: want error")
+	}
+	if calls.Load() != 1 || n != int64(out.Len()) || n == 0 {
+		t.Errorf("%d requests, %d bytes reported, %d written", calls.Load(), n, out.Len())
+	}
+}
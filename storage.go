@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -35,12 +39,41 @@ type Storage interface {
 	Close() error
 }
 
+// ── Streamed Writes ─────────────────────────────────────────────────────────
+//
+// Transcripts of multi-hour meetings, and the notes embedding them, can run
+// to hundreds of megabytes. Storage that implements streamWriter writes them
+// as they are rendered; writeStreamed falls back to one buffer and WriteFile
+// for storage that doesn't.
+
+// streamBufferSize is the write buffer of a streamed file.
+const streamBufferSize = 64 << 10
+
+// streamWriter is implemented by storage that can write a file from a
+// renderer without buffering it whole.
+type streamWriter interface {
+	WriteStream(relPath string, render func(w io.Writer) error) error
+}
+
+// writeStreamed writes relPath with the output of render.
+func writeStreamed(s Storage, relPath string, render func(w io.Writer) error) error {
+	if sw, ok := s.(streamWriter); ok {
+		return sw.WriteStream(relPath, render)
+	}
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	return s.WriteFile(relPath, buf.Bytes())
+}
+
 // ── LocalStorage ────────────────────────────────────────────────────────────
 
 // LocalStorage implements Storage by writing directly to a root directory.
 // This preserves the existing graindl behavior with 0o600 file permissions.
 type LocalStorage struct {
-	root string
+	root  string
+	roots OutputRoots // artifact classes routed elsewhere (see outputroots.go)
 }
 
 // NewLocalStorage returns a LocalStorage rooted at dir.
@@ -49,7 +82,7 @@ func NewLocalStorage(dir string) *LocalStorage {
 }
 
 func (s *LocalStorage) WriteFile(relPath string, data []byte) error {
-	abs := filepath.Join(s.root, relPath)
+	abs := s.AbsPath(relPath)
 	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
 		return fmt.Errorf("mkdir: %w", err)
 	}
@@ -61,24 +94,53 @@ func (s *LocalStorage) WriteJSON(relPath string, v any) error {
 	if err != nil {
 		return fmt.Errorf("marshal: %w", err)
 	}
-	abs := filepath.Join(s.root, relPath)
+	abs := s.AbsPath(relPath)
 	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
 		return fmt.Errorf("mkdir: %w", err)
 	}
 	return os.WriteFile(abs, data, 0o600)
 }
 
+// WriteStream writes relPath through a buffer as render produces it, so a
+// large file is never held in memory whole.
+func (s *LocalStorage) WriteStream(relPath string, render func(w io.Writer) error) error {
+	abs := s.AbsPath(relPath)
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	f, err := os.OpenFile(abs, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriterSize(f, streamBufferSize)
+	err = render(bw)
+	if err == nil {
+		err = bw.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
 func (s *LocalStorage) FileExists(relPath string) bool {
-	_, err := os.Stat(filepath.Join(s.root, relPath))
+	_, err := os.Stat(s.AbsPath(relPath))
 	return err == nil
 }
 
+// EnsureDir creates relPath under the output root and under every routed
+// root, so date dirs exist wherever their files will go.
 func (s *LocalStorage) EnsureDir(relPath string) error {
-	return os.MkdirAll(filepath.Join(s.root, relPath), 0o755)
+	for _, root := range append([]string{s.root}, s.roots.dirs()...) {
+		if err := os.MkdirAll(filepath.Join(root, relPath), 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *LocalStorage) AbsPath(relPath string) string {
-	return filepath.Join(s.root, relPath)
+	return filepath.Join(s.roots.root(s.root, relPath), relPath)
 }
 
 func (s *LocalStorage) SyncExternalFile(_ string) {} // no secondary target
@@ -92,9 +154,10 @@ func (s *LocalStorage) Root() string { return s.root }
 // SyncState tracks files that have been written to a target directory,
 // enabling incremental updates and conflict resolution.
 type SyncState struct {
-	Version   int                       `json:"version"`
-	UpdatedAt string                    `json:"updated_at"`
-	Files     map[string]*SyncFileEntry `json:"files"`
+	Version     int                       `json:"version"`
+	UpdatedAt   string                    `json:"updated_at"`
+	CompactedAt string                    `json:"compacted_at,omitempty"`
+	Files       map[string]*SyncFileEntry `json:"files"`
 }
 
 // SyncFileEntry records the hash, size, and classification of a synced file.
@@ -115,42 +178,116 @@ func NewSyncState() *SyncState {
 }
 
 // loadSyncState reads a sync state file from disk.
-// Returns a fresh state if the file does not exist. Logs a warning if the
-// file exists but contains corrupt JSON, rather than silently resetting.
+// Returns a fresh state if neither the file nor its backup exists. A corrupt
+// file is recovered from its .bak copy when possible; otherwise a warning is
+// logged and the state is reset rather than silently discarded.
 func loadSyncState(path string) *SyncState {
-	data, err := os.ReadFile(path)
-	if err != nil {
+	var state *SyncState
+	err := readStateFile(path, func(data []byte) error {
+		var s SyncState
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		state = &s
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
 		return NewSyncState()
 	}
-	var state SyncState
-	if err := json.Unmarshal(data, &state); err != nil {
+	if err != nil {
 		slog.Warn("Corrupt sync state file, resetting", "path", path, "error", err)
 		return NewSyncState()
 	}
 	if state.Files == nil {
 		state.Files = make(map[string]*SyncFileEntry)
 	}
-	return &state
+	return state
 }
 
 // saveSyncState writes the sync state to disk with 0o600 permissions.
-// Uses atomic temp-file + rename to avoid corruption on crash.
+// Uses atomic temp-file + rename to avoid corruption on crash, keeping the
+// previous version as a .bak copy.
 func saveSyncState(path string, state *SyncState) error {
 	state.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal sync state: %w", err)
 	}
+	return writeStateFile(path, data)
+}
+
+// ── State File Durability ───────────────────────────────────────────────────
+//
+// Sync state files (iCloud, Drive) are written atomically and the previous
+// good version is kept next to them as <name>.bak, so a torn or corrupt
+// write loses at most one save. Entries for files that no longer exist
+// locally are dropped at most once per syncCompactInterval.
+
+const (
+	stateBackupSuffix   = ".bak"
+	syncCompactInterval = 24 * time.Hour
+)
+
+// readStateFile passes the contents of path to decode. When path is missing
+// or decode rejects it, the .bak copy is tried instead. The primary's error
+// is returned when neither can be used.
+func readStateFile(path string, decode func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if err = decode(data); err == nil {
+			return nil
+		}
+	}
+	bak, berr := os.ReadFile(path + stateBackupSuffix)
+	if berr != nil || decode(bak) != nil {
+		return err
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Sync state unreadable, recovered from backup", "path", path, "error", err)
+	}
+	return nil
+}
+
+// writeStateFile atomically replaces path with data (0o600). A valid
+// existing file is rotated to path.bak first; a corrupt one is not, so the
+// last good backup survives.
+func writeStateFile(path string, data []byte) error {
 	tmp := path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+	if err := writeFile(tmp, data); err != nil {
 		return fmt.Errorf("write temp sync state: %w", err)
 	}
+	if prev, err := os.ReadFile(path); err == nil && json.Valid(prev) {
+		if err := os.Rename(path, path+stateBackupSuffix); err != nil {
+			slog.Warn("Sync state backup failed", "path", path, "error", err)
+		}
+	}
 	if err := os.Rename(tmp, path); err != nil {
 		return fmt.Errorf("rename sync state: %w", err)
 	}
 	return nil
 }
 
+// compactDue reports whether a state last compacted at the RFC 3339 time
+// last should be compacted again.
+func compactDue(last string) bool {
+	t, err := time.Parse(time.RFC3339, last)
+	return err != nil || time.Since(t) >= syncCompactInterval
+}
+
+// compactSyncFiles deletes entries whose relative path no longer exists
+// under root, or under the root its class is routed to, and returns how
+// many were dropped.
+func compactSyncFiles[E any](files map[string]E, root string, roots OutputRoots) int {
+	dropped := 0
+	for rel := range files {
+		if !fileExists(filepath.Join(roots.root(root, rel), rel)) {
+			delete(files, rel)
+			dropped++
+		}
+	}
+	return dropped
+}
+
 // ── Helpers ─────────────────────────────────────────────────────────────────
 
 // computeSHA256 returns the hex-encoded SHA-256 digest of data.
@@ -162,6 +299,7 @@ func computeSHA256(data []byte) string {
 // classifyContent maps a file's relative path to a content type string
 // based on its extension and name patterns.
 func classifyContent(relPath string) string {
+	relPath = trimCompressed(relPath) // --compress: classify by the inner name
 	base := filepath.Base(relPath)
 	ext := filepath.Ext(relPath)
 
@@ -174,6 +312,9 @@ func classifyContent(relPath string) string {
 		if containsAny(base, ".highlights") {
 			return "highlights"
 		}
+		if containsAny(base, receiptSuffix) {
+			return "receipt"
+		}
 		return "metadata"
 	case ".txt":
 		if containsAny(base, ".transcript") {
@@ -182,10 +323,12 @@ func classifyContent(relPath string) string {
 		return "metadata"
 	case ".md":
 		return "markdown"
-	case ".mp4", ".webm":
+	case ".mp4", ".webm", ".mkv", ".mov", ".ts":
 		return "video"
 	case ".m4a":
 		return "audio"
+	case ".mhtml":
+		return "snapshot"
 	default:
 		return "other"
 	}
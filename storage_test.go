@@ -2,9 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLocalStorage_WriteFile(t *testing.T) {
@@ -31,6 +34,62 @@ func TestLocalStorage_WriteFile(t *testing.T) {
 	}
 }
 
+func TestWriteStreamed(t *testing.T) {
+	dir := t.TempDir()
+	mirror := newFakeMirror("a", "")
+	render := func(text string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			for range 3 {
+				if _, err := io.WriteString(w, text); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	for name, s := range map[string]Storage{
+		"local":      NewLocalStorage(filepath.Join(dir, "local")),
+		"multi":      NewMultiStorage(NewLocalStorage(filepath.Join(dir, "multi")), mirror),
+		"immutable":  NewImmutableStorage(NewLocalStorage(filepath.Join(dir, "immutable"))),
+		"compressed": NewCompressedStorage(NewLocalStorage(filepath.Join(dir, "compressed")), "gzip"),
+		"fallback":   struct{ Storage }{NewLocalStorage(filepath.Join(dir, "fallback"))},
+	} {
+		if err := writeStreamed(s, "2025-01-02/m1.md", render("ab")); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		abs := s.AbsPath("2025-01-02/m1.md")
+		if got, _ := os.ReadFile(abs); string(got) != "ababab" {
+			t.Errorf("%s: content = %q", name, got)
+		}
+		if info, err := os.Stat(abs); err != nil || info.Mode().Perm() != 0o600 {
+			t.Errorf("%s: mode = %v, %v", name, info.Mode().Perm(), err)
+		}
+		// Rewriting a shorter file truncates it.
+		_ = writeStreamed(s, "2025-01-02/m1.md", render("c"))
+		if got, _ := os.ReadFile(abs); string(got) != "ccc" {
+			t.Errorf("%s: rewritten content = %q", name, got)
+		}
+	}
+	if got := mirror.files["2025-01-02/m1.md"]; got != "ccc" {
+		t.Errorf("mirror copy = %q", got)
+	}
+
+	// A compressible file is compressed like any other write.
+	cs := NewCompressedStorage(NewLocalStorage(filepath.Join(dir, "compressed")), "gzip")
+	if err := writeStreamed(cs, "2025-01-02/m1.transcript.txt", render("ab")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readArtifact(cs.AbsPath("2025-01-02/m1.transcript.txt")); err != nil || string(got) != "ababab" {
+		t.Errorf("compressed transcript = %q, %v", got, err)
+	}
+
+	// A failing renderer fails the write.
+	boom := errors.New("boom")
+	if err := writeStreamed(NewLocalStorage(dir), "x.md", func(io.Writer) error { return boom }); !errors.Is(err, boom) {
+		t.Errorf("render error = %v", err)
+	}
+}
+
 func TestLocalStorage_WriteJSON(t *testing.T) {
 	dir := t.TempDir()
 	s := NewLocalStorage(dir)
@@ -190,6 +249,7 @@ func TestClassifyContent(t *testing.T) {
 		{"2025-01-15/abc.mp4", "video"},
 		{"2025-01-15/abc.webm", "video"},
 		{"2025-01-15/abc.m4a", "audio"},
+		{"2025-01-15/abc.mhtml", "snapshot"},
 		{"_export-manifest.json", "manifest"},
 		{"other.xyz", "other"},
 	}
@@ -214,3 +274,112 @@ func TestSyncState_SavePermissions(t *testing.T) {
 		t.Fatalf("perm = %o, want 0600", perm)
 	}
 }
+
+func TestSyncState_SaveRotatesBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	first := NewSyncState()
+	first.Files["a.json"] = &SyncFileEntry{SHA256: "v1"}
+	if err := saveSyncState(path, first); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(path + stateBackupSuffix) {
+		t.Fatal("first save should not create a backup")
+	}
+	first.Files["a.json"].SHA256 = "v2"
+	if err := saveSyncState(path, first); err != nil {
+		t.Fatal(err)
+	}
+
+	bak := loadSyncState(path + stateBackupSuffix)
+	if bak.Files["a.json"] == nil || bak.Files["a.json"].SHA256 != "v1" {
+		t.Fatalf("backup should hold the previous version, got %+v", bak.Files["a.json"])
+	}
+	info, _ := os.Stat(path + stateBackupSuffix)
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("backup perm = %o, want 0600", perm)
+	}
+}
+
+func TestSyncState_RecoversFromBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	state := NewSyncState()
+	state.Files["a.json"] = &SyncFileEntry{SHA256: "good"}
+	if err := saveSyncState(path, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSyncState(path, state); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(path, []byte("{torn wri"), 0o600)
+
+	loaded := loadSyncState(path)
+	if loaded.Files["a.json"] == nil || loaded.Files["a.json"].SHA256 != "good" {
+		t.Fatalf("expected recovery from backup, got %+v", loaded.Files)
+	}
+
+	// Saving over the corrupt primary must not clobber the good backup.
+	if err := saveSyncState(path, NewSyncState()); err != nil {
+		t.Fatal(err)
+	}
+	if bak := loadSyncState(path + stateBackupSuffix); bak.Files["a.json"] == nil {
+		t.Fatal("corrupt primary was rotated over the backup")
+	}
+}
+
+func TestSyncState_RecoversMissingPrimary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	state := NewSyncState()
+	state.Files["a.json"] = &SyncFileEntry{SHA256: "x"}
+	data, _ := json.Marshal(state)
+	_ = os.WriteFile(path+stateBackupSuffix, data, 0o600)
+
+	// A crash between the backup and final renames leaves only the .bak.
+	if loaded := loadSyncState(path); len(loaded.Files) != 1 {
+		t.Fatalf("files = %d, want 1 from backup", len(loaded.Files))
+	}
+}
+
+func TestCompactSyncFiles(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "2025-01-15"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "2025-01-15", "kept.json"), []byte("{}"), 0o600)
+
+	files := map[string]*SyncFileEntry{
+		"2025-01-15/kept.json": {},
+		"2025-01-15/gone.json": {},
+		"2024-12-01/gone.mp4":  {},
+	}
+	if n := compactSyncFiles(files, dir, nil); n != 2 {
+		t.Fatalf("dropped = %d, want 2", n)
+	}
+	if _, ok := files["2025-01-15/kept.json"]; !ok || len(files) != 1 {
+		t.Fatalf("files after compaction = %v", files)
+	}
+
+	// Entries of routed classes are looked up under their own root.
+	videoRoot := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(videoRoot, "2025-01-15"), 0o755)
+	_ = os.WriteFile(filepath.Join(videoRoot, "2025-01-15", "call.mp4"), []byte("v"), 0o600)
+	files = map[string]*SyncFileEntry{"2025-01-15/kept.json": {}, "2025-01-15/call.mp4": {}}
+	if n := compactSyncFiles(files, dir, OutputRoots{outputVideo: videoRoot}); n != 0 || len(files) != 2 {
+		t.Fatalf("routed compaction dropped %d: %v", n, files)
+	}
+}
+
+func TestCompactDue(t *testing.T) {
+	if !compactDue("") {
+		t.Error("never-compacted state should be due")
+	}
+	if compactDue(time.Now().UTC().Format(time.RFC3339)) {
+		t.Error("just-compacted state should not be due")
+	}
+	if !compactDue(time.Now().Add(-syncCompactInterval - time.Minute).UTC().Format(time.RFC3339)) {
+		t.Error("state older than the interval should be due")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ── Strict Mode ─────────────────────────────────────────────────────────────
+//
+// By default a run that exported what it could exits 0: failed meetings are
+// recorded in the manifest and retried by the next run. --strict is for CI
+// and scripts that must notice: the run exits with exitStrictFailed when any
+// meeting failed, left an HLS stream pending, or got a video whose length
+// doesn't match the meeting's (see duration.go). --max-errors N aborts the
+// run once N meetings have failed, rather than failing the rest one by one
+// when something is obviously broken (changed Grain markup, a full disk);
+// meetings not attempted are left for the next run. In watch mode --strict
+// does not apply, and the --max-errors count restarts each cycle; a cycle
+// it aborts is retried like any other failed cycle.
+
+// exitStrictFailed is the process exit code when --strict finds failures.
+const exitStrictFailed = 4
+
+var (
+	// errStrictFailed is returned by Run under --strict when meetings failed.
+	errStrictFailed = errors.New("run had failures (--strict)")
+
+	// errTooManyErrors is returned by Run when --max-errors aborted it.
+	errTooManyErrors = errors.New("aborted after too many failed meetings (--max-errors)")
+)
+
+// failedStatus reports whether an ExportResult status counts as an error
+// (the manifest's Errors total).
+func failedStatus(status string) bool {
+	switch status {
+	case "ok", "skipped", "hls_pending", statusAuthBlocked:
+		return false
+	}
+	return true
+}
+
+// errorBudget counts failed meetings across (possibly parallel) exports
+// and trips at --max-errors. A nil budget (no limit) never trips.
+type errorBudget struct {
+	mu     sync.Mutex
+	limit  int
+	failed int
+}
+
+// newErrorBudget returns a budget of limit failures, or nil for no limit.
+func newErrorBudget(limit int) *errorBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &errorBudget{limit: limit}
+}
+
+// Record notes the outcome of one export and reports whether the budget is
+// (now) used up.
+func (b *errorBudget) Record(r *ExportResult) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if failedStatus(r.Status) {
+		b.failed++
+	}
+	return b.failed >= b.limit
+}
+
+// Exhausted reports whether the run should stop.
+func (b *errorBudget) Exhausted() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failed >= b.limit
+}
+
+// strictErr returns errStrictFailed, with the counts, when --strict is set
+// and the finished run recorded errors, pending HLS streams, or duration
+// mismatches.
+func (e *Exporter) strictErr() error {
+	if !e.cfg.Strict || e.cfg.Watch {
+		return nil
+	}
+	m := e.manifest
+	if m.DurationMismatch > 0 {
+		return fmt.Errorf("%w: %d error(s), %d HLS pending, %d video(s) with the wrong length", errStrictFailed, m.Errors, m.HLSPending, m.DurationMismatch)
+	}
+	if m.Errors > 0 || m.HLSPending > 0 {
+		return fmt.Errorf("%w: %d error(s), %d HLS pending", errStrictFailed, m.Errors, m.HLSPending)
+	}
+	return nil
+}
+
+// exitCode maps a Run error to the process exit code.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errAuthBlocked):
+		return exitAuthBlocked
+	case errors.Is(err, errStrictFailed):
+		return exitStrictFailed
+	}
+	return 1
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorBudget(t *testing.T) {
+	none := newErrorBudget(0)
+	if none.Record(&ExportResult{Status: "error"}) || none.Exhausted() {
+		t.Error("no limit should never trip")
+	}
+
+	b := newErrorBudget(2)
+	for _, status := range []string{"ok", "skipped", "hls_pending", statusAuthBlocked, "error"} {
+		if b.Record(&ExportResult{Status: status}) {
+			t.Fatalf("tripped after %q", status)
+		}
+	}
+	if !b.Record(&ExportResult{Status: "error"}) || !b.Exhausted() {
+		t.Error("second error should trip a budget of 2")
+	}
+}
+
+// newExhaustedExporter returns an exporter whose --max-errors budget is
+// already used up.
+func newExhaustedExporter(t *testing.T, parallel int) *Exporter {
+	t.Helper()
+	cfg := &Config{OutputDir: t.TempDir(), SkipVideo: true, Parallel: parallel, MaxDelaySec: 0.01, MaxErrors: 1}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	t.Cleanup(e.Close)
+	e.failures.Record(&ExportResult{Status: "error"})
+	return e
+}
+
+func TestExportMaxErrorsStops(t *testing.T) {
+	meetings := []MeetingRef{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	for _, parallel := range []int{1, 2} {
+		e := newExhaustedExporter(t, parallel)
+		if parallel > 1 {
+			e.exportParallel(context.Background(), meetings)
+		} else {
+			e.exportSequential(context.Background(), meetings)
+		}
+		if len(e.manifest.Meetings) != 0 {
+			t.Errorf("parallel=%d: exported %d meetings after --max-errors tripped", parallel, len(e.manifest.Meetings))
+		}
+	}
+}
+
+func TestStrictErr(t *testing.T) {
+	e := &Exporter{cfg: &Config{Strict: true}, manifest: &ExportManifest{OK: 3}}
+	if err := e.strictErr(); err != nil {
+		t.Errorf("clean run: %v", err)
+	}
+	e.manifest.HLSPending = 1
+	if err := e.strictErr(); !errors.Is(err, errStrictFailed) {
+		t.Errorf("HLS pending: err = %v", err)
+	}
+	e.manifest.HLSPending, e.manifest.Errors = 0, 2
+	if err := e.strictErr(); err == nil || err.Error() != "run had failures (--strict): 2 error(s), 0 HLS pending" {
+		t.Errorf("errors: err = %v", err)
+	}
+	e.cfg.Watch = true
+	if err := e.strictErr(); err != nil {
+		t.Errorf("watch: %v", err)
+	}
+	e.cfg = &Config{}
+	if err := e.strictErr(); err != nil {
+		t.Errorf("lenient: %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for err, want := range map[error]int{
+		nil:                                  0,
+		errAuthBlocked:                       exitAuthBlocked,
+		fmt.Errorf("%w: 1", errStrictFailed): exitStrictFailed,
+		errTooManyErrors:                     1,
+		errors.New("discover: boom"):         1,
+	} {
+		if got := exitCode(err); got != want {
+			t.Errorf("exitCode(%v) = %d, want %d", err, got, want)
+		}
+	}
+}
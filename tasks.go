@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Tasks ───────────────────────────────────────────────────────────────────
+//
+// Action items become Obsidian Tasks checkboxes ("- [ ] task 📅 2025-03-07").
+// They come from the AI notes' action-item section (metadata action_items)
+// and from highlights marked as tasks ("Action item: ...", "TODO: ...",
+// "Follow up: ..."). Each formatted note lists its meeting's tasks under
+// "## Action Items", and with --output-format every run rebuilds tasks.md at
+// the archive root: all open tasks grouped by meeting, newest first, linked
+// to their notes. A due date is added when the task names one ("by Friday",
+// "on March 10", "2025-03-10"), resolved against the meeting date.
+//
+// tasks.md is regenerated each time; tasks checked off in it or in their
+// meeting note are matched by description and stay checked.
+
+// tasksFile is the archive-wide task rollup.
+const tasksFile = "tasks.md"
+
+// meetingTask is one action item.
+type meetingTask struct {
+	Text string
+	Due  string // YYYY-MM-DD, or ""
+}
+
+// taskMarker matches highlight titles/texts flagged as action items.
+var taskMarker = regexp.MustCompile(`(?i)^\s*(?:\[ \]|action(?:\s+item)?|todo|to-do|follow[\s-]?up|next\s+step)\s*[:\-–—]?\s+(.+)$`)
+
+// meetingTasks collects a meeting's action items: AI notes first, then
+// marked highlights, without duplicates.
+func meetingTasks(meta *Metadata, clips []HighlightClip) []meetingTask {
+	meetingDate, _ := time.Parse("2006-01-02", dateFromISO(meta.Date))
+	seen := map[string]bool{}
+	var tasks []meetingTask
+	add := func(text string) {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" || seen[strings.ToLower(text)] {
+			return
+		}
+		seen[strings.ToLower(text)] = true
+		tasks = append(tasks, meetingTask{Text: text, Due: taskDueDate(text, meetingDate)})
+	}
+	for _, item := range meta.ActionItems {
+		add(item)
+	}
+	for _, c := range clips {
+		for _, s := range []string{c.Title, c.Text} {
+			if m := taskMarker.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+				add(m[1])
+				break
+			}
+		}
+	}
+	return tasks
+}
+
+// String renders the task as an Obsidian Tasks checkbox.
+func (t meetingTask) String() string {
+	if t.Due == "" {
+		return "- [ ] " + t.Text
+	}
+	return "- [ ] " + t.Text + " 📅 " + t.Due
+}
+
+var (
+	dueISO      = regexp.MustCompile(`\b(\d{4}-\d{2}-\d{2})\b`)
+	dueRelative = regexp.MustCompile(`(?i)\b(?:by|on|due|before|until)\s+(?:the\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday|tomorrow|today|eod|eow|end\s+of\s+(?:the\s+)?(?:day|week)|next\s+week)\b`)
+	dueMonthDay = regexp.MustCompile(`(?i)\b(?:by|on|due|before|until)\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b`)
+)
+
+var dueMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// taskDueDate finds a due date in text. Relative dates need the meeting
+// date; without one only explicit YYYY-MM-DD dates are recognized.
+func taskDueDate(text string, meeting time.Time) string {
+	if m := dueISO.FindStringSubmatch(text); m != nil {
+		if _, err := time.Parse("2006-01-02", m[1]); err == nil {
+			return m[1]
+		}
+	}
+	if meeting.IsZero() {
+		return ""
+	}
+	if m := dueMonthDay.FindStringSubmatch(text); m != nil {
+		month := dueMonths[strings.ToLower(m[1][:3])]
+		day, _ := strconv.Atoi(m[2])
+		t := time.Date(meeting.Year(), month, day, 0, 0, 0, 0, meeting.Location())
+		if t.Day() == day { // reject Feb 30 and the like
+			if t.Before(meeting) {
+				t = t.AddDate(1, 0, 0)
+			}
+			return t.Format("2006-01-02")
+		}
+	}
+	m := dueRelative.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	word := strings.Join(strings.Fields(strings.ToLower(m[1])), " ")
+	var due time.Time
+	switch word {
+	case "today", "eod", "end of day", "end of the day":
+		due = meeting
+	case "tomorrow":
+		due = meeting.AddDate(0, 0, 1)
+	case "eow", "end of week", "end of the week":
+		due = nextWeekday(meeting, time.Friday, true)
+	case "next week":
+		due = nextWeekday(meeting, time.Monday, false)
+	default:
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.ToLower(d.String()) == word {
+				due = nextWeekday(meeting, d, false)
+			}
+		}
+	}
+	if due.IsZero() {
+		return ""
+	}
+	return due.Format("2006-01-02")
+}
+
+// nextWeekday returns the first day after from (or on it, when sameDay) that
+// falls on wd.
+func nextWeekday(from time.Time, wd time.Weekday, sameDay bool) time.Time {
+	days := (int(wd) - int(from.Weekday()) + 7) % 7
+	if days == 0 && !sameDay {
+		days = 7
+	}
+	return from.AddDate(0, 0, days)
+}
+
+// writeTaskSection appends a meeting's "## Action Items" section.
+func writeTaskSection(b noteWriter, meta *Metadata) {
+	tasks := meetingTasks(meta, normalizeHighlights(parseHighlights(meta.Highlights)))
+	if len(tasks) == 0 {
+		return
+	}
+	b.WriteString("\n## Action Items\n\n")
+	for _, t := range tasks {
+		b.WriteString(t.String())
+		b.WriteString("\n")
+	}
+}
+
+// ── Rollup ──────────────────────────────────────────────────────────────────
+
+// writeTasksRollup rebuilds tasks.md from the archive.
+func (e *Exporter) writeTasksRollup() {
+	if e.cfg.OutputFormat == "" {
+		return
+	}
+	entries, err := scanArchive(e.cfg.dataDir())
+	if err != nil {
+		slog.Warn("Task rollup skipped", "error", err)
+		return
+	}
+	// A task checked off in its meeting note or in the previous rollup
+	// stays checked.
+	done := doneTasks(e.storage.AbsPath(tasksFile))
+	notes := make(map[*ArchiveEntry]string, len(entries))
+	for _, a := range entries {
+		if p := e.noteRelPath(a.Meta, a.RelBase); e.storage.FileExists(p) {
+			notes[a] = p
+			for t := range doneTasks(e.storage.AbsPath(p)) {
+				done[t] = true
+			}
+		}
+	}
+	md, n := renderTasksRollup(entries, e.cfg.dataDir(), done, notes)
+	if err := e.storage.WriteFile(tasksFile, []byte(md)); err != nil {
+		slog.Warn("Task rollup write failed", "error", err)
+		return
+	}
+	slog.Debug("Task rollup written", "tasks", n, "path", tasksFile)
+}
+
+// renderTasksRollup groups every meeting's tasks under a heading linking to
+// its note (from notes, when it has one), newest meeting first. Tasks whose
+// description is in done are rendered checked.
+func renderTasksRollup(entries []*ArchiveEntry, outputDir string, done map[string]bool, notes map[*ArchiveEntry]string) (string, int) {
+	sorted := append([]*ArchiveEntry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date().After(sorted[j].Date()) })
+
+	var b strings.Builder
+	b.WriteString("# Tasks\n\n")
+	b.WriteString("_Action items from exported Grain meetings, rebuilt by graindl after every run. Tasks checked off here or in a meeting note stay checked._\n")
+	total := 0
+	for _, a := range sorted {
+		tasks := meetingTasks(a.Meta, a.Highlights(outputDir))
+		if len(tasks) == 0 {
+			continue
+		}
+		title := coalesce(a.Meta.Title, a.Meta.ID)
+		if note := notes[a]; note != "" {
+			title = "[" + escapeMarkdownLinkText(title) + "](" + markdownLinkPath(note) + ")"
+		}
+		fmt.Fprintf(&b, "\n## %s · %s\n\n", a.Date().Format("2006-01-02"), title)
+		for _, t := range tasks {
+			line := t.String()
+			if done[t.Text] {
+				line = "- [x]" + strings.TrimPrefix(line, "- [ ]")
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+			total++
+		}
+	}
+	if total == 0 {
+		b.WriteString("\nNo action items yet.\n")
+	}
+	return b.String(), total
+}
+
+// doneTasks reads the descriptions of checked tasks from an existing rollup.
+func doneTasks(path string) map[string]bool {
+	done := map[string]bool{}
+	f, err := os.Open(path)
+	if err != nil {
+		return done
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		rest, ok := strings.CutPrefix(line, "- [x] ")
+		if !ok {
+			rest, ok = strings.CutPrefix(line, "- [X] ")
+		}
+		if !ok {
+			continue
+		}
+		// Drop Tasks plugin fields (📅 due, ✅ done date, ...).
+		if i := strings.IndexAny(rest, "📅✅⏳🛫➕🔁⏫🔼🔽"); i >= 0 {
+			rest = rest[:i]
+		}
+		done[strings.TrimSpace(rest)] = true
+	}
+	return done
+}
+
+// escapeMarkdownLinkText escapes brackets in link text.
+func escapeMarkdownLinkText(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(s)
+}
+
+// markdownLinkPath turns a relative file path into a link target: forward
+// slashes, spaces and parentheses percent-encoded.
+func markdownLinkPath(rel string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(filepath.ToSlash(rel))
+}
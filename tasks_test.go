@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskDueDate(t *testing.T) {
+	wed := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC) // a Wednesday
+	tests := map[string]string{
+		"Send pricing by Friday":               "2025-03-07",
+		"Ship it on Wednesday":                 "2025-03-12",
+		"Call back tomorrow":                   "",
+		"Reply by tomorrow":                    "2025-03-06",
+		"Draft due EOW":                        "2025-03-07",
+		"Renew contract before March 3rd":      "2026-03-03",
+		"Kickoff on Apr 10":                    "2025-04-10",
+		"Deadline 2025-06-30 for the RFP":      "2025-06-30",
+		"Follow up with legal":                 "",
+		"Book venue by Feb 30":                 "",
+		"Prepare deck by end of the week":      "2025-03-07",
+		"Schedule workshop sometime next week": "",
+		"Schedule workshop by next week":       "2025-03-10",
+	}
+	for text, want := range tests {
+		if got := taskDueDate(text, wed); got != want {
+			t.Errorf("taskDueDate(%q) = %q, want %q", text, got, want)
+		}
+	}
+	if got := taskDueDate("Send by Friday", time.Time{}); got != "" {
+		t.Errorf("no meeting date: got %q, want none", got)
+	}
+}
+
+func TestMeetingTasks(t *testing.T) {
+	meta := &Metadata{Date: "2025-03-05T10:00:00Z", ActionItems: []string{"Dana: send pricing by Friday", "  "}}
+	clips := []HighlightClip{
+		{Title: "Action item: loop in security", Text: "we should loop in security"},
+		{Text: "TODO - dana: send   pricing by Friday"},
+		{Title: "Great quote", Text: "Follow up: share the case study"},
+		{Title: "Pricing objection", Text: "It's too expensive"},
+	}
+	got := meetingTasks(meta, clips)
+	want := []string{
+		"- [ ] Dana: send pricing by Friday 📅 2025-03-07",
+		"- [ ] loop in security",
+		"- [ ] share the case study",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("tasks = %v, want %d", got, len(want))
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Errorf("task %d = %q, want %q", i, got[i].String(), want[i])
+		}
+	}
+
+	md := renderFormattedMarkdown("obsidian", &Metadata{ID: "m1", Title: "T", ActionItems: []string{"Send notes"}}, "")
+	if !strings.Contains(md, "\n## Action Items\n\n- [ ] Send notes\n") {
+		t.Errorf("obsidian note missing action items:\n%s", md)
+	}
+}
+
+func TestTasksRollup(t *testing.T) {
+	dir := t.TempDir()
+	writeArchiveMeta(t, dir, "2025-03-04", &Metadata{ID: "old", Title: "Old [sync]", Date: "2025-03-04", ActionItems: []string{"Send notes", "Book room"}})
+	writeArchiveMeta(t, dir, "2025-03-05", &Metadata{ID: "new", Date: "2025-03-05"})
+	clips, _ := json.Marshal([]HighlightClip{{Title: "TODO: renew license on Mar 20"}})
+	os.WriteFile(filepath.Join(dir, "2025-03-05", "new.highlights.json"), clips, 0o600)
+	writeArchiveMeta(t, dir, "2025-03-06", &Metadata{ID: "none", Date: "2025-03-06"})
+
+	// "Send notes" was checked off in the meeting note, "renew license" in
+	// the previous rollup.
+	os.WriteFile(filepath.Join(dir, "2025-03-04", "old.md"), []byte("## Action Items\n\n- [x] Send notes\n- [ ] Book room\n"), 0o600)
+	os.WriteFile(filepath.Join(dir, tasksFile), []byte("- [x] renew license on Mar 20 📅 2025-03-20 ✅ 2025-03-19\n"), 0o600)
+
+	e, err := NewExporter(context.Background(), &Config{OutputDir: dir, OutputFormat: "obsidian"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	e.writeTasksRollup()
+
+	data, err := os.ReadFile(filepath.Join(dir, tasksFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := string(data)
+	for _, want := range []string{
+		"## 2025-03-05 · new\n\n- [x] renew license on Mar 20 📅 2025-03-20\n",
+		"## 2025-03-04 · [Old \\[sync\\]](2025-03-04/old.md)\n\n- [x] Send notes\n- [ ] Book room\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("rollup missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Index(md, "2025-03-05") > strings.Index(md, "2025-03-04") {
+		t.Error("meetings should be newest first")
+	}
+	if strings.Contains(md, "none") {
+		t.Error("meetings without tasks should be omitted")
+	}
+}
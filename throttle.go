@@ -3,17 +3,195 @@ package main
 import (
 	"context"
 	"crypto/rand"
+	"fmt"
 	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Throttle provides random-duration sleeps in [Min, Max) via crypto/rand.
-// Two instances exist by design: Exporter.throttle (between meetings) and
-// Scraper.throttle (between API calls). Both are constructed from the same
-// config values but operate independently.
+// The exporter holds one instance (Exporter.throttle) and waits on it
+// between meetings that load a Grain page (see grainPacer). There is no
+// HTTP API client in this tree; all Grain access goes through the browser.
+//
+// Requests made outside the browser (direct video downloads, HLS
+// playlists) are paced per host with WaitHost. Each host bucket has its own
+// delay range and its own clock, so slow video-host pacing never delays
+// grain.com page loads and vice versa.
 type Throttle struct {
 	Min time.Duration
 	Max time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostPace // bucket name → pacing state
+}
+
+// hostCDN is the bucket for every host without a bucket of its own.
+const hostCDN = "cdn"
+
+// hostGrain is the bucket for the Grain web app's hosts (grain.com, or
+// --grain-base-url), paced by --min-delay/--max-delay.
+const hostGrain = "grain.com"
+
+// defaultHostDelays returns the buckets every exporter starts with: the
+// public API host (see --grain-api-url) and cdn. --host-delay overrides them.
+func defaultHostDelays() []HostDelay {
+	return []HostDelay{
+		{Host: grainAPIHost(), Min: 500 * time.Millisecond, Max: 1500 * time.Millisecond},
+		{Host: hostCDN, Min: 500 * time.Millisecond, Max: 2 * time.Second},
+	}
+}
+
+// HostDelay is one --host-delay entry.
+type HostDelay struct {
+	Host string
+	Min  time.Duration
+	Max  time.Duration
+}
+
+// hostPace is one host bucket: its delay range and when its next request
+// may start.
+type hostPace struct {
+	min, max time.Duration
+	next     time.Time
+}
+
+// SetHost configures the delay range for host and its subdomains. The name
+// "cdn" sets the range for hosts that match no other bucket.
+func (t *Throttle) SetHost(host string, min, max time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.hosts == nil {
+		t.hosts = map[string]*hostPace{}
+	}
+	t.hosts[strings.ToLower(host)] = &hostPace{min: min, max: max}
+}
+
+// WaitHost spaces requests to host: it returns once a random delay from the
+// host bucket's range has passed since the bucket's previous request. The
+// first request to a bucket does not wait. Concurrent callers are queued
+// one delay apart.
+func (t *Throttle) WaitHost(ctx context.Context, host string) error {
+	t.mu.Lock()
+	p := t.bucket(host)
+	now := time.Now()
+	start := now
+	if p.next.After(now) {
+		start = p.next
+	}
+	p.next = start.Add(randDuration(p.min, p.max))
+	t.mu.Unlock()
+
+	d := start.Sub(now)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// bucket returns the pacing state for host: the longest configured name
+// that equals host or is a parent domain of it, else the cdn bucket, else
+// the Grain bucket's Min/Max. Callers hold t.mu.
+func (t *Throttle) bucket(host string) *hostPace {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if t.hosts == nil {
+		t.hosts = map[string]*hostPace{}
+	}
+	best := ""
+	for name := range t.hosts {
+		if (host == name || strings.HasSuffix(host, "."+name)) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		best = hostCDN
+		if isGrainDomain(host) {
+			best = hostGrain
+		}
+		if t.hosts[best] == nil {
+			t.hosts[best] = &hostPace{min: t.Min, max: t.Max}
+		}
+	}
+	return t.hosts[best]
+}
+
+// hostOf returns the host of rawURL, or "" if it does not parse.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// parseHostDelays parses a --host-delay list such as
+// "api.grain.com=0.5-1.5,cdn=0-1". Delays are seconds; a single number
+// means a fixed delay.
+func parseHostDelays(spec string) ([]HostDelay, error) {
+	var out []HostDelay
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, rng, ok := strings.Cut(entry, "=")
+		host = strings.TrimSpace(host)
+		if !ok || host == "" {
+			return nil, fmt.Errorf("invalid --host-delay entry %q (want host=min-max)", entry)
+		}
+		lo, hi, ranged := strings.Cut(rng, "-")
+		if !ranged {
+			hi = lo
+		}
+		minSec, err1 := strconv.ParseFloat(strings.TrimSpace(lo), 64)
+		maxSec, err2 := strconv.ParseFloat(strings.TrimSpace(hi), 64)
+		if err1 != nil || err2 != nil || minSec < 0 || maxSec < minSec {
+			return nil, fmt.Errorf("invalid --host-delay range %q for %s (want seconds, e.g. 0.5-2)", rng, host)
+		}
+		out = append(out, HostDelay{
+			Host: strings.ToLower(host),
+			Min:  time.Duration(minSec * float64(time.Second)),
+			Max:  time.Duration(maxSec * float64(time.Second)),
+		})
+	}
+	return out, nil
+}
+
+// grainPacer spaces the meetings of a sequential run that need Grain: the
+// Throttle delay runs before a meeting's first page load, and only once an
+// earlier meeting has loaded one. Meetings answered from the archive
+// (already exported, claimed elsewhere) never wait, so a re-run over a
+// complete archive finishes without sleeping. A nil pacer never waits.
+type grainPacer struct {
+	throttle *Throttle
+	used     bool // a meeting has accessed Grain
+}
+
+// wait sleeps before a meeting that is about to access Grain, unless none
+// has before it.
+func (p *grainPacer) wait(ctx context.Context) error {
+	if p == nil {
+		return ctx.Err()
+	}
+	if !p.used {
+		p.used = true
+		return ctx.Err()
+	}
+	return p.throttle.Wait(ctx)
 }
 
 // Wait sleeps for a random duration in [Min, Max). Returns immediately
@@ -35,13 +213,19 @@ func (t *Throttle) Wait(ctx context.Context) error {
 
 // duration calculates a random sleep time in [Min, Max).
 func (t *Throttle) duration() time.Duration {
-	if t.Min >= t.Max {
-		return t.Min
+	return randDuration(t.Min, t.Max)
+}
+
+// randDuration returns a random duration in [min, max), or min when the
+// range is empty.
+func randDuration(min, max time.Duration) time.Duration {
+	if min >= max {
+		return min
 	}
-	spread := t.Max - t.Min
+	spread := max - min
 	n, err := rand.Int(rand.Reader, big.NewInt(int64(spread)))
 	if err != nil {
-		return t.Min + spread/2
+		return min + spread/2
 	}
-	return t.Min + time.Duration(n.Int64())
+	return min + time.Duration(n.Int64())
 }
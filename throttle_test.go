@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -105,3 +107,150 @@ func TestThrottleAlreadyCancelled(t *testing.T) {
 		t.Errorf("already-cancelled should be instant, took %v", elapsed)
 	}
 }
+
+func TestThrottleHostBucketsAreIndependent(t *testing.T) {
+	th := &Throttle{Min: 0, Max: 0}
+	th.SetHost("cdn", 150*time.Millisecond, 150*time.Millisecond)
+	th.SetHost("api.grain.com", 0, 0)
+	ctx := context.Background()
+
+	// First request to a bucket never waits.
+	start := time.Now()
+	th.WaitHost(ctx, "video.cloudfront.net")
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("first cdn request waited %v", elapsed)
+	}
+
+	// A grain.com request right after is not held back by the cdn bucket.
+	start = time.Now()
+	th.WaitHost(ctx, "api.grain.com:443")
+	th.WaitHost(ctx, "api.grain.com")
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("api.grain.com waited %v behind cdn pacing", elapsed)
+	}
+
+	// A second cdn request (any CDN host) is spaced by the cdn delay.
+	start = time.Now()
+	th.WaitHost(ctx, "other-cdn.example.net")
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("second cdn request waited only %v, want ~150ms", elapsed)
+	}
+}
+
+func TestThrottleHostBucketMatching(t *testing.T) {
+	th := &Throttle{Min: time.Second, Max: 2 * time.Second}
+	th.SetHost("api.grain.com", 10*time.Millisecond, 20*time.Millisecond)
+	th.SetHost("cdn", 30*time.Millisecond, 40*time.Millisecond)
+	th.SetHost("media.example.com", 50*time.Millisecond, 60*time.Millisecond)
+
+	tests := []struct {
+		host     string
+		wantMin  time.Duration
+		wantName string
+	}{
+		{"api.grain.com", 10 * time.Millisecond, "api.grain.com"},
+		{"v2.api.grain.com", 10 * time.Millisecond, "api.grain.com"},
+		{"grain.com", time.Second, "grain.com"},
+		{"www.grain.com:443", time.Second, "grain.com"},
+		{"media.example.com", 50 * time.Millisecond, "media.example.com"},
+		{"x.media.example.com", 50 * time.Millisecond, "media.example.com"},
+		{"notmedia.example.com", 30 * time.Millisecond, "cdn"},
+		{"d1.cloudfront.net", 30 * time.Millisecond, "cdn"},
+	}
+	for _, tt := range tests {
+		if got := th.bucket(tt.host); got.min != tt.wantMin || got != th.hosts[tt.wantName] {
+			t.Errorf("bucket(%q): min %v, want bucket %q (min %v)", tt.host, got.min, tt.wantName, tt.wantMin)
+		}
+	}
+}
+
+func TestThrottleWaitHostCancelled(t *testing.T) {
+	th := &Throttle{}
+	th.SetHost("cdn", 5*time.Second, 5*time.Second)
+	th.WaitHost(context.Background(), "cdn.example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := th.WaitHost(ctx, "cdn.example.com"); err == nil {
+		t.Error("expected context error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("should return on cancel, took %v", elapsed)
+	}
+}
+
+func TestParseHostDelays(t *testing.T) {
+	got, err := parseHostDelays(" API.grain.com=0.5-1.5, cdn=2 ,")
+	if err != nil {
+		t.Fatalf("parseHostDelays: %v", err)
+	}
+	want := []HostDelay{
+		{Host: "api.grain.com", Min: 500 * time.Millisecond, Max: 1500 * time.Millisecond},
+		{Host: "cdn", Min: 2 * time.Second, Max: 2 * time.Second},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"cdn", "=1-2", "cdn=x", "cdn=2-1", "cdn=-1"} {
+		if _, err := parseHostDelays(bad); err == nil {
+			t.Errorf("parseHostDelays(%q): expected error", bad)
+		}
+	}
+}
+
+func TestGrainPacer(t *testing.T) {
+	ctx := context.Background()
+	var nilPacer *grainPacer
+	if err := nilPacer.wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &grainPacer{throttle: &Throttle{Min: 50 * time.Millisecond, Max: 50 * time.Millisecond}}
+	start := time.Now()
+	p.wait(ctx)
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("first Grain access waited %v", elapsed)
+	}
+	start = time.Now()
+	p.wait(ctx)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("second Grain access waited only %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := p.wait(cancelled); err == nil {
+		t.Error("wait ignored a cancelled context")
+	}
+}
+
+func TestExportSequentialSkipsDontWait(t *testing.T) {
+	cfg := &Config{OutputDir: t.TempDir(), SkipVideo: true, MinDelaySec: 5, MaxDelaySec: 5}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(e.Close)
+	meetings := []MeetingRef{{ID: "a", Date: "2025-01-15"}, {ID: "b", Date: "2025-01-15"}, {ID: "c", Date: "2025-01-16"}}
+	for _, m := range meetings {
+		dir := filepath.Join(cfg.OutputDir, m.Date)
+		os.MkdirAll(dir, 0o755)
+		os.WriteFile(filepath.Join(dir, m.ID+".json"), []byte(`{"id":"`+m.ID+`"}`), 0o600)
+	}
+
+	start := time.Now()
+	e.exportSequential(context.Background(), meetings)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("re-run over an exported archive took %v", elapsed)
+	}
+	if e.manifest.Skipped != 3 {
+		t.Errorf("skipped = %d, want 3", e.manifest.Skipped)
+	}
+}
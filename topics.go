@@ -0,0 +1,230 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ── Topic Extraction ────────────────────────────────────────────────────────
+//
+// --topics N picks each meeting's N most distinctive transcript keywords by
+// TF-IDF: words frequent in this meeting but rare across the archive rank
+// highest, so every-meeting vocabulary ("team", "update") drops out. The
+// archive's existing <date>/<id>.transcript.txt files seed the document
+// frequencies when the exporter starts, and each exported transcript joins
+// the corpus as it is processed. Topics go into metadata as `topics` and
+// into markdown frontmatter as tags, where Obsidian's graph view and Notion
+// filters can use them.
+//
+// Tokenization is deliberately simple: lowercase words of three or more
+// letters, minus English stop words, conversational filler, speaker labels,
+// and the meeting's participant names.
+
+// topicMinCount is how often a word must occur in a transcript to be a
+// topic; single mentions are mostly noise.
+const topicMinCount = 2
+
+// topicIndex holds document frequencies for the archive's transcripts. It is
+// safe for concurrent use.
+type topicIndex struct {
+	mu   sync.Mutex
+	df   map[string]int  // word → number of transcripts containing it
+	seen map[string]bool // transcripts already counted, by relBase
+}
+
+func newTopicIndex() *topicIndex {
+	return &topicIndex{df: map[string]int{}, seen: map[string]bool{}}
+}
+
+// loadTopicIndex seeds an index from every transcript under outputDir.
+func loadTopicIndex(outputDir string) *topicIndex {
+	idx := newTopicIndex()
+	dirs, _ := archiveDirs(outputDir)
+	var paths []string
+	for _, d := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(globEscape(filepath.Join(outputDir, d)), "*.transcript.txt*"))
+		paths = append(paths, matches...)
+	}
+	for _, p := range paths {
+		if !strings.HasSuffix(trimCompressed(p), ".transcript.txt") {
+			continue
+		}
+		data, err := readArtifact(p)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(outputDir, p)
+		if err != nil {
+			continue
+		}
+		idx.add(strings.TrimSuffix(trimCompressed(rel), ".transcript.txt"), topicTermCounts(string(data), nil))
+	}
+	slog.Debug("Topic index loaded", "transcripts", len(idx.seen), "terms", len(idx.df))
+	return idx
+}
+
+// add counts a transcript's terms once per document key.
+func (idx *topicIndex) add(key string, counts map[string]int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.addLocked(key, counts)
+}
+
+func (idx *topicIndex) addLocked(key string, counts map[string]int) {
+	if idx.seen[key] {
+		return
+	}
+	idx.seen[key] = true
+	for w := range counts {
+		idx.df[w]++
+	}
+}
+
+// Topics adds the transcript under key to the corpus and returns its top n
+// keywords, best first. exclude lists names (e.g. participants) whose words
+// are never topics.
+func (idx *topicIndex) Topics(key, transcript string, exclude []string, n int) []string {
+	counts := topicTermCounts(transcript, exclude)
+	if len(counts) == 0 || n <= 0 {
+		return nil
+	}
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+
+	idx.mu.Lock()
+	idx.addLocked(key, counts)
+	docs := float64(len(idx.seen))
+	type scored struct {
+		word  string
+		score float64
+	}
+	var ranked []scored
+	for w, c := range counts {
+		if c < topicMinCount {
+			continue
+		}
+		idf := math.Log((1+docs)/(1+float64(idx.df[w]))) + 1
+		ranked = append(ranked, scored{w, float64(c) / float64(total) * idf})
+	}
+	idx.mu.Unlock()
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].word < ranked[j].word
+	})
+	var out []string
+	for _, s := range ranked[:min(n, len(ranked))] {
+		out = append(out, s.word)
+	}
+	return out
+}
+
+// topicTermCounts tokenizes a transcript into candidate topic words.
+func topicTermCounts(transcript string, exclude []string) map[string]int {
+	skip := map[string]bool{}
+	for _, name := range exclude {
+		for _, w := range topicWords(name) {
+			skip[w] = true
+		}
+	}
+	counts := map[string]int{}
+	for _, line := range strings.Split(transcript, "\n") {
+		for _, w := range topicWords(stripSpeakerLabel(line)) {
+			if !skip[w] {
+				counts[w]++
+			}
+		}
+	}
+	return counts
+}
+
+// topicWords splits s into lowercase words, dropping stop words, short
+// words, numbers, and contractions.
+func topicWords(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	}) {
+		f = strings.TrimSuffix(strings.TrimSuffix(f, "'s"), "’s")
+		f = strings.Trim(f, "'’")
+		if len([]rune(f)) < 3 || strings.ContainsAny(f, "'’") || topicStopWords[f] {
+			continue
+		}
+		if strings.IndexFunc(f, unicode.IsLetter) < 0 {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// stripSpeakerLabel removes a leading "Speaker Name:" (and any timestamp
+// before it) from a transcript line.
+func stripSpeakerLabel(line string) string {
+	label, rest, ok := strings.Cut(line, ": ")
+	if !ok || len(label) > 40 || strings.ContainsAny(label, ".?!,") {
+		return line
+	}
+	return rest
+}
+
+// topicStopWords are common English words and conversational filler that
+// never make useful topics.
+var topicStopWords = func() map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(`
+		about above after again against all also although always among and another any anybody anyone anything
+		are around because been before being below between both but can cannot could did does doing done down
+		during each either else even ever every everybody everyone everything for from further had has have
+		having her here hers herself him himself his how however into its itself just least less let like many
+		may maybe might mine more most much must myself neither never nobody none nor not nothing now off once
+		one only onto other others our ours ourselves out over own perhaps quite rather same she should since
+		some somebody someone something sometimes somewhere still such than that the their theirs them
+		themselves then there these they thing things this those though through thus too under until upon
+		very was way we were what whatever when whenever where whether which while who whoever whole whom whose
+		why will with within without would yet you your yours yourself yourselves
+		able actually almost already alright anyway back basically bit come coming cool definitely didn
+		doesn don exactly fine first get gets getting give go goes going gonna good got gotta great guess
+		guys hey huh kind know last lot lots look looking made make makes making mean means mhm mm
+		need needs new next nice okay oh ok put really right said saw say saying says see seems seen so sort
+		stuff sure take talk talking tell thank thanks think thought time today told totally try trying two
+		uh uhm um use used using want wanted wants well went yeah yep yes yup wanna kinda sorta
+		`) {
+		m[w] = true
+	}
+	return m
+}()
+
+// extractTopics sets meta.Topics from the transcript when --topics is on.
+func (e *Exporter) extractTopics(meta *Metadata, scraped *MeetingPageData, relBase string) {
+	if e.topics == nil || scraped == nil || scraped.Transcript == "" {
+		return
+	}
+	meta.Topics = e.topics.Topics(relBase, scraped.Transcript, flattenStringSlice(meta.Participants), e.cfg.Topics)
+}
+
+// topicTags turns topics into frontmatter tags: spaces become hyphens and
+// tags already present are skipped.
+func topicTags(tags, topics []string) []string {
+	have := map[string]bool{}
+	for _, t := range tags {
+		have[strings.ToLower(t)] = true
+	}
+	for _, t := range topics {
+		t = strings.ReplaceAll(strings.TrimSpace(t), " ", "-")
+		if t != "" && !have[strings.ToLower(t)] {
+			have[strings.ToLower(t)] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
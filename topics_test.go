@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTopicWords(t *testing.T) {
+	got := topicWords("Yeah, so the Kubernetes migration's blocked — we're waiting on 2024 budget; it's OK.")
+	want := []string{"kubernetes", "migration", "blocked", "waiting", "budget"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("topicWords = %q, want %q", got, want)
+	}
+}
+
+func TestStripSpeakerLabel(t *testing.T) {
+	tests := map[string]string{
+		"Alice Smith: the rollout": "the rollout",
+		"00:12 Bob: hi":            "hi",
+		"Well, as I said: ship it": "Well, as I said: ship it",
+		"no label here":            "no label here",
+	}
+	for in, want := range tests {
+		if got := stripSpeakerLabel(in); got != want {
+			t.Errorf("stripSpeakerLabel(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTopicsPreferDistinctiveWords(t *testing.T) {
+	idx := newTopicIndex()
+	// Every archived meeting talks about the roadmap; only this one is
+	// about kubernetes and billing.
+	for i, tr := range []string{
+		"roadmap roadmap planning planning hiring",
+		"roadmap roadmap design design review",
+		"roadmap roadmap customers customers",
+	} {
+		idx.add(string(rune('a'+i)), topicTermCounts(tr, nil))
+	}
+
+	transcript := strings.Join([]string{
+		"Alice: The roadmap needs kubernetes work.",
+		"Bob: Kubernetes upgrade first, then billing.",
+		"Alice: Billing exports break on kubernetes nodes. Roadmap slips.",
+		"Carol: Billing again, and the roadmap.",
+	}, "\n")
+	got := idx.Topics("2025-01-02/m1", transcript, []string{"Alice Cooper", "Bob", "Carol"}, 2)
+	want := []string{"billing", "kubernetes"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Topics = %q, want %q", got, want)
+	}
+
+	for _, w := range got {
+		if w == "alice" || w == "bob" {
+			t.Errorf("speaker/participant name %q returned as topic", w)
+		}
+	}
+	if len(idx.seen) != 4 {
+		t.Errorf("corpus size = %d, want 4 (new transcript added)", len(idx.seen))
+	}
+	// Re-processing the same meeting must not inflate document frequency.
+	before := idx.df["billing"]
+	idx.Topics("2025-01-02/m1", transcript, nil, 2)
+	if idx.df["billing"] != before {
+		t.Errorf("df[billing] = %d after re-export, want %d", idx.df["billing"], before)
+	}
+}
+
+func TestTopicsSkipsSingleMentions(t *testing.T) {
+	if got := newTopicIndex().Topics("k", "kubernetes billing roadmap", nil, 5); len(got) != 0 {
+		t.Errorf("Topics = %q, want none for words mentioned once", got)
+	}
+}
+
+func TestLoadTopicIndex(t *testing.T) {
+	dir := t.TempDir()
+	for rel, body := range map[string]string{
+		"2025-01-01/a.transcript.txt": "roadmap roadmap hiring",
+		"2025-01-02/b.transcript.txt": "roadmap billing",
+		"2025-01-02/b.json":           `{"id":"b"}`,
+	} {
+		p := filepath.Join(dir, rel)
+		os.MkdirAll(filepath.Dir(p), 0o700)
+		if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	idx := loadTopicIndex(dir)
+	if len(idx.seen) != 2 || idx.df["roadmap"] != 2 || idx.df["billing"] != 1 {
+		t.Errorf("index: %d docs, df=%v", len(idx.seen), idx.df)
+	}
+	if !idx.seen[filepath.Join("2025-01-01", "a")] {
+		t.Errorf("seen keys = %v, want relBase keys", idx.seen)
+	}
+}
+
+func TestTopicsRenderedAsTags(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Sync", Tags: []string{"Billing"}, Topics: []string{"billing", "kubernetes", "cost model"}}
+	for _, format := range []string{"obsidian", "notion", "minutes"} {
+		md := renderFormattedMarkdown(format, meta, "")
+		if !strings.Contains(md, "  - kubernetes\n") || !strings.Contains(md, "  - cost-model\n") {
+			t.Errorf("%s: topics missing from tags:\n%s", format, md)
+		}
+		if strings.Count(strings.ToLower(md), "- billing\n") != 1 {
+			t.Errorf("%s: duplicate billing tag:\n%s", format, md)
+		}
+	}
+}
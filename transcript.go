@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ── Transcript Rendering ────────────────────────────────────────────────────
+//
+// Scraped transcripts are blank-line-separated segments of the form
+// "[HH:MM:SS ]Speaker: text". In formatted output they are rendered as one
+// block per speaker turn instead of a wall of text: Obsidian gets a quote
+// callout per turn, Notion a Time | Speaker | Text table. Timestamps link to
+// the meeting at that point in the recording (?t=<seconds>). Transcripts
+// without speakers or timestamps are written as-is.
+
+// transcriptTurn is a run of consecutive segments by one speaker.
+type transcriptTurn struct {
+	Start   float64 // seconds; -1 when unknown
+	Speaker string
+	Lines   []string
+}
+
+// parseTranscript splits transcript text into speaker turns. It returns nil
+// when no segment carries a speaker label or timestamp.
+func parseTranscript(text string) []transcriptTurn {
+	var turns []transcriptTurn
+	structured := false
+	for _, seg := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		seg = strings.TrimSpace(seg)
+		if seg == "" {
+			continue
+		}
+		start := -1.0
+		if ts := leadingTimestamp(seg); ts != "" {
+			if _, rest, ok := strings.Cut(seg, " "); ok && strings.TrimSpace(rest) != "" {
+				seg, start = strings.TrimSpace(rest), clockSeconds(ts)
+				structured = true
+			}
+		}
+		speaker := ""
+		if label, rest, ok := strings.Cut(seg, ": "); ok && stripSpeakerLabel(seg) == rest && label != "" {
+			speaker, seg = strings.TrimSpace(label), strings.TrimSpace(rest)
+			structured = true
+		}
+		if n := len(turns); n > 0 && turns[n-1].Speaker == speaker && speaker != "" {
+			turns[n-1].Lines = append(turns[n-1].Lines, seg)
+			continue
+		}
+		turns = append(turns, transcriptTurn{Start: start, Speaker: speaker, Lines: []string{seg}})
+	}
+	if !structured {
+		return nil
+	}
+	return turns
+}
+
+// clockSeconds converts "MM:SS" or "HH:MM:SS" to seconds.
+func clockSeconds(clock string) float64 {
+	secs := 0
+	for _, p := range strings.Split(clock, ":") {
+		n, _ := strconv.Atoi(p)
+		secs = secs*60 + n
+	}
+	return float64(secs)
+}
+
+// timestampLink renders "[HH:MM:SS]", linked to meetingURL at that offset
+// when the meeting has a URL.
+func timestampLink(meetingURL string, sec float64) string {
+	label := `\[` + formatTimestamp(sec) + `\]`
+	link := timestampURL(meetingURL, sec)
+	if link == "" {
+		return label
+	}
+	return "[" + label + "](" + link + ")"
+}
+
+// timestampURL returns meetingURL opened at sec (?t=<seconds>), or "" when
+// meetingURL is empty or invalid.
+func timestampURL(meetingURL string, sec float64) string {
+	u, err := url.Parse(meetingURL)
+	if meetingURL == "" || err != nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("t", strconv.Itoa(int(sec)))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// writeTranscriptSection appends the "## Transcript" section in the style
+// of format ("obsidian" or "notion").
+func writeTranscriptSection(b noteWriter, format string, meta *Metadata, text string) {
+	if text == "" {
+		return
+	}
+	b.WriteString("\n## Transcript\n\n")
+	turns := parseTranscript(text)
+	if turns == nil {
+		b.WriteString(text)
+		b.WriteString("\n")
+		return
+	}
+	meetingURL := coalesce(meta.Links.Grain, meta.Links.Share)
+	if format == "notion" {
+		writeTranscriptTable(b, turns, meetingURL)
+	} else {
+		writeTranscriptCallouts(b, turns, meetingURL)
+	}
+}
+
+// writeTranscriptCallouts renders one Obsidian quote callout per turn.
+func writeTranscriptCallouts(b noteWriter, turns []transcriptTurn, meetingURL string) {
+	for i, t := range turns {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		var title []string
+		if t.Speaker != "" {
+			title = append(title, "**"+t.Speaker+"**")
+		}
+		if t.Start >= 0 {
+			title = append(title, timestampLink(meetingURL, t.Start))
+		}
+		b.WriteString("> [!quote]")
+		if len(title) > 0 {
+			b.WriteString(" " + strings.Join(title, " · "))
+		}
+		b.WriteString("\n")
+		for j, line := range t.Lines {
+			if j > 0 {
+				b.WriteString(">\n")
+			}
+			for _, l := range strings.Split(line, "\n") {
+				b.WriteString("> " + l + "\n")
+			}
+		}
+	}
+}
+
+// writeTranscriptTable renders turns as a Time | Speaker | Text table.
+func writeTranscriptTable(b noteWriter, turns []transcriptTurn, meetingURL string) {
+	cell := strings.NewReplacer("|", `\|`, "\n", "<br>")
+	b.WriteString("| Time | Speaker | Text |\n| --- | --- | --- |\n")
+	for _, t := range turns {
+		ts := ""
+		if t.Start >= 0 {
+			ts = timestampLink(meetingURL, t.Start)
+		}
+		b.WriteString("| " + ts + " | " + cell.Replace(t.Speaker) + " | " +
+			cell.Replace(strings.Join(t.Lines, "\n\n")) + " |\n")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTranscript(t *testing.T) {
+	text := "00:00:05 Alice: Welcome everyone.\n\n00:00:09 Alice: Let's start.\n\n01:02 Bob: Thanks.\n\nNo label here."
+	turns := parseTranscript(text)
+	if len(turns) != 3 {
+		t.Fatalf("turns = %d, want 3: %+v", len(turns), turns)
+	}
+	if turns[0].Speaker != "Alice" || turns[0].Start != 5 || len(turns[0].Lines) != 2 {
+		t.Errorf("turn 0 = %+v", turns[0])
+	}
+	if turns[1].Speaker != "Bob" || turns[1].Start != 62 || turns[1].Lines[0] != "Thanks." {
+		t.Errorf("turn 1 = %+v", turns[1])
+	}
+	if turns[2].Speaker != "" || turns[2].Start != -1 {
+		t.Errorf("turn 2 = %+v", turns[2])
+	}
+}
+
+func TestParseTranscriptUnstructured(t *testing.T) {
+	for _, text := range []string{"Hello world transcript", "First point. Then: second.\n\nMore text"} {
+		if turns := parseTranscript(text); turns != nil {
+			t.Errorf("parseTranscript(%q) = %+v, want nil", text, turns)
+		}
+	}
+}
+
+func TestTimestampLink(t *testing.T) {
+	if got, want := timestampLink("https://grain.com/app/meetings/m1", 754), `[\[00:12:34\]](https://grain.com/app/meetings/m1?t=754)`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := timestampLink("https://grain.com/share/x?tab=notes", 3); !strings.Contains(got, "tab=notes") || !strings.Contains(got, "t=3") {
+		t.Errorf("existing query should be kept: %q", got)
+	}
+	if got := timestampLink("", 1); got != `\[00:00:01\]` {
+		t.Errorf("no URL: %q", got)
+	}
+}
+
+func TestRenderTranscriptObsidianCallouts(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Sync", Links: Links{Grain: "https://grain.com/app/meetings/m1"}}
+	md := renderFormattedMarkdown("obsidian", meta, "00:12:34 Alice: Ship it.\n\n00:12:40 Bob: Agreed.")
+
+	want := "> [!quote] **Alice** · [\\[00:12:34\\]](https://grain.com/app/meetings/m1?t=754)\n> Ship it.\n"
+	if !strings.Contains(md, want) {
+		t.Errorf("missing Alice callout:\n%s", md)
+	}
+	if !strings.Contains(md, "> [!quote] **Bob** · [\\[00:12:40\\]](https://grain.com/app/meetings/m1?t=760)\n> Agreed.\n") {
+		t.Errorf("missing Bob callout:\n%s", md)
+	}
+}
+
+func TestRenderTranscriptNotionTable(t *testing.T) {
+	meta := &Metadata{ID: "m1", Title: "Sync", Links: Links{Share: "https://grain.com/share/recording/m1/abc"}}
+	md := renderFormattedMarkdown("notion", meta, "00:01:00 Carol: a | b\n\nDave: done")
+
+	if !strings.Contains(md, "| Time | Speaker | Text |\n| --- | --- | --- |\n") {
+		t.Errorf("missing table header:\n%s", md)
+	}
+	if !strings.Contains(md, "| [\\[00:01:00\\]](https://grain.com/share/recording/m1/abc?t=60) | Carol | a \\| b |\n") {
+		t.Errorf("missing Carol row:\n%s", md)
+	}
+	if !strings.Contains(md, "|  | Dave | done |\n") {
+		t.Errorf("missing Dave row without timestamp:\n%s", md)
+	}
+}
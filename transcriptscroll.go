@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-rod/rod/lib/input"
+)
+
+// ── Transcript Expansion ────────────────────────────────────────────────────
+//
+// Grain lazy-loads long transcripts: the panel renders the segments near its
+// scroll position, fetches more as it is scrolled (sometimes only behind a
+// "Load more" button), and may drop segments that scrolled out of view. One
+// read of the DOM therefore captures the first few minutes of a multi-hour
+// meeting. expandTranscript walks the panel from the top instead. Each step
+// collects the rendered segments into a page-side accumulator (deduplicated,
+// in order), clicks a load-more control if one is showing, and pages down by
+// scrolling the panel's scroll container. When the container won't scroll
+// programmatically, or it sits at the bottom without growing, real PageDown
+// and End keystrokes are sent, which virtualized lists listening for input
+// events do honour. It stops once the segment count has held for
+// transcriptStableSteps steps at the bottom, or after transcriptExpandTimeout.
+
+const (
+	transcriptExpandTimeout = 3 * time.Minute
+	transcriptMaxSteps      = 1000
+	transcriptStableSteps   = 3 // steps at the bottom without new segments
+	transcriptStepDelay     = 400 * time.Millisecond
+)
+
+// transcriptSegmentSelectors match one rendered transcript segment.
+const transcriptSegmentSelectors = `'[data-testid="transcript-segment"], ' +
+			'.transcript-segment, ' +
+			'[class*="transcript"] [class*="segment"], ' +
+			'[class*="transcript"] [class*="block"], ' +
+			'[class*="Transcript"] [class*="Segment"]'`
+
+// transcriptSegmentsJS returns the rendered segments as [{key, line}], where
+// line is "[ts ]speaker: text" and key identifies the segment across
+// re-renders.
+const transcriptSegmentsJS = `() => {
+		const out = [];
+		document.querySelectorAll(` + transcriptSegmentSelectors + `).forEach(seg => {
+			const speaker = (seg.querySelector('[class*="speaker"], [class*="Speaker"], [data-testid="speaker-name"]') || {}).textContent || '';
+			const text = (seg.querySelector('[class*="text"], [class*="Text"], [class*="content"], p') || seg).textContent || '';
+			// Segment start time, e.g. "12:34" or "1:02:03".
+			const ts = ((seg.querySelector('[data-testid="timestamp"], [class*="timestamp"], [class*="Timestamp"], time') || {}).textContent || '').trim();
+			const clean = text.replace(speaker, '').replace(ts, '').trim();
+			if (!clean) return;
+			const line = speaker.trim() ? (speaker.trim() + ': ' + clean) : clean;
+			const full = /^\d{1,2}:\d{2}(:\d{2})?$/.test(ts) ? (ts + ' ' + line) : line;
+			const id = seg.getAttribute('data-segment-id') || seg.getAttribute('data-index') || seg.getAttribute('aria-posinset') || seg.id || '';
+			out.push({key: id ? id + '|' + full : full, line: full});
+		});
+		return out;
+	}`
+
+// transcriptStartJS resets the accumulator and scrolls the panel to the top.
+const transcriptStartJS = `() => {
+		window.__graindlTranscript = {seen: new Set(), lines: []};
+		const first = document.querySelector(` + transcriptSegmentSelectors + `);
+		for (let el = first && first.parentElement; el; el = el.parentElement) {
+			if (el.scrollHeight > el.clientHeight + 4 && /(auto|scroll)/.test(getComputedStyle(el).overflowY)) {
+				el.scrollTop = 0;
+				break;
+			}
+		}
+		return !!first;
+	}`
+
+// transcriptStepJS collects, clicks load-more, and scrolls one step.
+const transcriptStepJS = `() => {
+		const acc = window.__graindlTranscript || (window.__graindlTranscript = {seen: new Set(), lines: []});
+		const segs = (` + transcriptSegmentsJS + `)();
+		for (const s of segs) {
+			if (!acc.seen.has(s.key)) {
+				acc.seen.add(s.key);
+				acc.lines.push(s.line);
+			}
+		}
+
+		const last = segs.length ? Array.from(document.querySelectorAll(` + transcriptSegmentSelectors + `)).pop() : null;
+		let scroller = null;
+		for (let el = last && last.parentElement; el; el = el.parentElement) {
+			if (el.scrollHeight > el.clientHeight + 4 && /(auto|scroll)/.test(getComputedStyle(el).overflowY)) {
+				scroller = el;
+				break;
+			}
+		}
+		scroller = scroller || document.scrollingElement || document.documentElement;
+
+		let clicked = false;
+		const scope = (scroller === document.scrollingElement ? document : scroller);
+		for (const btn of scope.querySelectorAll('button, [role="button"], a')) {
+			const t = (btn.textContent || '').trim().toLowerCase();
+			if (t.length < 40 && /^(load|show|view|see) (more|all|full transcript|entire transcript)/.test(t) && btn.offsetParent !== null && !btn.disabled) {
+				btn.click();
+				clicked = true;
+				break;
+			}
+		}
+
+		const before = scroller.scrollTop;
+		scroller.scrollTop = before + Math.max(200, scroller.clientHeight * 0.8);
+		if (typeof scroller.focus === 'function') {
+			if (!scroller.hasAttribute('tabindex') && scroller !== document.scrollingElement) scroller.setAttribute('tabindex', '-1');
+			scroller.focus({preventScroll: true});
+		}
+		return {
+			count: acc.lines.length,
+			moved: scroller.scrollTop !== before,
+			bottom: scroller.scrollTop + scroller.clientHeight >= scroller.scrollHeight - 4,
+			clicked: clicked,
+		};
+	}`
+
+// transcriptStep is what one expansion step saw.
+type transcriptStep struct {
+	Count   int  `json:"count"`   // segments collected so far
+	Moved   bool `json:"moved"`   // the scroll position changed
+	Bottom  bool `json:"bottom"`  // the panel is scrolled to the end
+	Clicked bool `json:"clicked"` // a load-more control was clicked
+}
+
+// transcriptProgress decides when expansion is done.
+type transcriptProgress struct {
+	last   int
+	stable int
+}
+
+// observe records a step and reports whether expansion should stop, and
+// whether keystrokes should be sent to move the panel on.
+func (p *transcriptProgress) observe(s transcriptStep) (done, keys bool) {
+	grew := s.Count > p.last
+	p.last = max(p.last, s.Count)
+	if grew || s.Clicked || !s.Bottom {
+		p.stable = 0
+		return false, !s.Moved && !s.Bottom && !s.Clicked
+	}
+	p.stable++
+	return p.stable >= transcriptStableSteps, true
+}
+
+// expandTranscript scrolls the transcript panel to the end and returns
+// every segment line seen on the way, or nil when the page has no
+// transcript segments.
+func (b *Browser) expandTranscript(ctx context.Context) []string {
+	res, err := b.page.Timeout(10 * time.Second).Eval(transcriptStartJS)
+	if err != nil || !res.Value.Bool() {
+		return nil
+	}
+	start := time.Now()
+	var p transcriptProgress
+	steps := 0
+	for ; steps < transcriptMaxSteps && time.Since(start) < transcriptExpandTimeout && ctx.Err() == nil; steps++ {
+		res, err := b.page.Timeout(10 * time.Second).Eval(transcriptStepJS)
+		if err != nil {
+			slog.Debug("Transcript expansion step failed", "error", err)
+			break
+		}
+		var s transcriptStep
+		if err := res.Value.Unmarshal(&s); err != nil {
+			break
+		}
+		done, keys := p.observe(s)
+		if done {
+			break
+		}
+		if keys {
+			key := input.PageDown
+			if s.Bottom {
+				key = input.End
+			}
+			_ = b.page.KeyActions().Press(key).Do()
+		}
+		time.Sleep(transcriptStepDelay)
+	}
+
+	res, err = b.page.Timeout(10 * time.Second).Eval(`() => (window.__graindlTranscript || {lines: []}).lines`)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, v := range res.Value.Arr() {
+		lines = append(lines, v.Str())
+	}
+	if time.Since(start) >= transcriptExpandTimeout {
+		slog.Warn("Transcript expansion timed out; the transcript may be incomplete", "segments", len(lines))
+	}
+	slog.Debug("Transcript expanded", "segments", len(lines), "steps", steps, "elapsed", time.Since(start).Round(time.Second))
+	return lines
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestTranscriptProgress(t *testing.T) {
+	type want struct{ done, keys bool }
+	for _, tc := range []struct {
+		name  string
+		steps []transcriptStep
+		want  []want
+	}{
+		{
+			name: "short transcript settles at the bottom",
+			steps: []transcriptStep{
+				{Count: 12, Bottom: true},
+				{Count: 12, Bottom: true},
+				{Count: 12, Bottom: true},
+				{Count: 12, Bottom: true},
+			},
+			want: []want{{false, false}, {false, true}, {false, true}, {true, true}},
+		},
+		{
+			name: "lazy panel keeps growing while scrolled",
+			steps: []transcriptStep{
+				{Count: 20, Moved: true},
+				{Count: 40, Moved: true},
+				{Count: 40, Bottom: true, Clicked: true}, // "Load more"
+				{Count: 60, Moved: true},
+				{Count: 60, Bottom: true},
+			},
+			want: []want{{false, false}, {false, false}, {false, false}, {false, false}, {false, true}},
+		},
+		{
+			name: "a panel that ignores scrollTop gets keystrokes",
+			steps: []transcriptStep{
+				{Count: 20},
+				{Count: 20},
+			},
+			want: []want{{false, true}, {false, true}},
+		},
+		{
+			name: "a lower count is not growth",
+			steps: []transcriptStep{
+				{Count: 30, Moved: true},
+				{Count: 10, Bottom: true},
+				{Count: 30, Bottom: true},
+				{Count: 30, Bottom: true},
+			},
+			want: []want{{false, false}, {false, true}, {false, true}, {true, true}},
+		},
+	} {
+		var p transcriptProgress
+		for i, s := range tc.steps {
+			done, keys := p.observe(s)
+			if (want{done, keys}) != tc.want[i] {
+				t.Errorf("%s: step %d = done %v keys %v, want %+v", tc.name, i, done, keys, tc.want[i])
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -81,8 +82,12 @@ type tuiLogMsg struct{ line string }
 // tuiDoneMsg signals the export goroutine has finished.
 type tuiDoneMsg struct{ err error }
 
-// tuiTotalMsg communicates how many meetings will be exported.
-type tuiTotalMsg struct{ n int }
+// tuiTotalMsg communicates how many meetings will be exported. grow marks
+// a raised total for the same run.
+type tuiTotalMsg struct {
+	n    int
+	grow bool
+}
 
 // tuiStartMsg signals that a specific meeting has started exporting.
 type tuiStartMsg struct {
@@ -133,7 +138,7 @@ var defaultTUIKeys = tuiKeyMap{
 type tuiMeeting struct {
 	index  int
 	title  string
-	status string // "pending" | "active" | "ok" | "skipped" | "error" | "hls_pending"
+	status string // "pending" | "active" | "ok" | "skipped" | "error" | "hls_pending" | "auth-blocked"
 }
 
 // ── TUI Model ────────────────────────────────────────────────────────────────
@@ -267,14 +272,17 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tuiTotalMsg:
 		m.total = msg.n
 		// Pre-populate with pending placeholders so the list shows
-		// all slots immediately.
-		m.meetings = make([]tuiMeeting, msg.n)
-		for i := range m.meetings {
-			m.meetings[i] = tuiMeeting{
+		// all slots immediately. A streamed --search raises the total as
+		// matches arrive; then the slots already shown are kept.
+		if !msg.grow {
+			m.meetings = nil
+		}
+		for i := len(m.meetings); i < msg.n; i++ {
+			m.meetings = append(m.meetings, tuiMeeting{
 				index:  i,
 				title:  fmt.Sprintf("Meeting %d", i+1),
 				status: "pending",
-			}
+			})
 		}
 
 	case tuiStartMsg:
@@ -567,6 +575,9 @@ func (m *tuiModel) renderMeetingRow(mt tuiMeeting, width int) string {
 	case "error":
 		icon = "✗"
 		rowStyle = tuiErr
+	case statusAuthBlocked:
+		icon = "⊗"
+		rowStyle = tuiErr
 	case "hls_pending":
 		icon = "↓"
 		rowStyle = tuiHLS
@@ -753,9 +764,10 @@ func runTUI(ctx context.Context, cfg *Config) error {
 		logLevel = slog.LevelDebug
 	}
 	handler := NewTUIHandler(p, logLevel)
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(withLogFile(newRedactHandler(handler))))
 
 	// Run exporter in the background.
+	runErr := make(chan error, 1)
 	go func() {
 		exp, err := NewExporter(ctx, cfg)
 		if err != nil {
@@ -765,6 +777,7 @@ func runTUI(ctx context.Context, cfg *Config) error {
 		defer exp.Close()
 
 		exp.tuiSendTotal = func(n int) { p.Send(tuiTotalMsg{n: n}) }
+		exp.tuiGrowTotal = func(n int) { p.Send(tuiTotalMsg{n: n, grow: true}) }
 		exp.tuiSendStart = func(i int, title string) { p.Send(tuiStartMsg{index: i, title: title}) }
 		exp.tuiSendResult = func(i int, title string, status string) {
 			p.Send(tuiResultMsg{index: i, title: title, status: status})
@@ -776,9 +789,21 @@ func runTUI(ctx context.Context, cfg *Config) error {
 		} else {
 			err2 = exp.Run(ctx)
 		}
+		runErr <- err2
 		p.Send(tuiDoneMsg{err: err2})
 	}()
 
-	_, err := p.Run()
-	return err
+	if _, err := p.Run(); err != nil {
+		return err
+	}
+	// Surface an auth abort or a failed --strict run so main can exit
+	// with its distinct code.
+	select {
+	case err := <-runErr:
+		if errors.Is(err, errAuthBlocked) || errors.Is(err, errStrictFailed) || errors.Is(err, errTooManyErrors) {
+			return err
+		}
+	default:
+	}
+	return nil
 }
@@ -432,3 +432,28 @@ func TestTUIHandler_LevelPrefixes(t *testing.T) {
 	}
 }
 
+
+func TestTUIModel_TotalMsgGrowKeepsSlots(t *testing.T) {
+	m := newTUIModel()
+	m2, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m3, _ := m2.(tuiModel).Update(tuiTotalMsg{n: 1, grow: true})
+	m4, _ := m3.(tuiModel).Update(tuiStartMsg{index: 0, title: "First Match"})
+	m5, _ := m4.(tuiModel).Update(tuiTotalMsg{n: 2, grow: true})
+	got := m5.(tuiModel)
+
+	if got.total != 2 || len(got.meetings) != 2 {
+		t.Fatalf("total=%d meetings=%d, want 2/2", got.total, len(got.meetings))
+	}
+	if got.meetings[0].title != "First Match" || got.meetings[0].status != "active" {
+		t.Errorf("meeting[0] = %+v, want the active first match kept", got.meetings[0])
+	}
+	if got.meetings[1].status != "pending" {
+		t.Errorf("meeting[1] status=%q, want pending", got.meetings[1].status)
+	}
+
+	// A plain total starts a fresh list.
+	m6, _ := got.Update(tuiTotalMsg{n: 1})
+	if fresh := m6.(tuiModel); fresh.meetings[0].status != "pending" {
+		t.Errorf("non-grow total should reset slots, got %+v", fresh.meetings[0])
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	xterm "github.com/charmbracelet/x/term"
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+// ── Version Report ──────────────────────────────────────────────────────────
+//
+// `graindl version` reports the build together with what this machine can
+// do with it: where ffmpeg and the Chromium that Rod launches live and which
+// versions they are, the backends the environment (.env included) enables,
+// and platform quirks that change graindl's behaviour. --json prints the
+// same report for bug reports and for wrapper scripts that gate features on
+// it. The --version flag keeps printing the one-line build string.
+
+// versionProbeTimeout bounds each external `--version` call.
+const versionProbeTimeout = 5 * time.Second
+
+// VersionReport is the `graindl version` report.
+type VersionReport struct {
+	Version   string        `json:"version"`
+	Commit    string        `json:"commit"`
+	BuildTime string        `json:"build_time,omitempty"` // VCS commit time, when built from a checkout
+	Modified  bool          `json:"modified,omitempty"`   // built from a dirty checkout
+	GoVersion string        `json:"go_version"`
+	OS        string        `json:"os"`
+	Arch      string        `json:"arch"`
+	Rod       string        `json:"rod_version,omitempty"`
+	FFmpeg    ToolInfo      `json:"ffmpeg"`
+	Chromium  ToolInfo      `json:"chromium"`
+	Backends  []BackendInfo `json:"backends"`
+	Quirks    []string      `json:"quirks"`
+}
+
+// ToolInfo describes an external program graindl runs.
+type ToolInfo struct {
+	Found   bool   `json:"found"`
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"` // first line of its version output
+	Note    string `json:"note,omitempty"`
+}
+
+// BackendInfo is one storage or delivery backend.
+type BackendInfo struct {
+	Name       string `json:"name"`
+	Available  bool   `json:"available"`  // usable on this platform
+	Configured bool   `json:"configured"` // enabled by the environment or .env
+}
+
+func runVersion(args []string) int {
+	dotenv := loadDotEnv(".env")
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	rep := collectVersionReport(context.Background(), dotenv)
+	var err error
+	if *asJSON {
+		err = writeVersionJSON(os.Stdout, rep)
+	} else {
+		err = writeVersionText(os.Stdout, rep)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// collectVersionReport builds the report, probing ffmpeg and Chromium.
+func collectVersionReport(ctx context.Context, dotenv map[string]string) *VersionReport {
+	rep := &VersionReport{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if rep.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			rep.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if rep.Commit == "none" {
+					rep.Commit = s.Value
+				}
+			case "vcs.time":
+				rep.BuildTime = s.Value
+			case "vcs.modified":
+				rep.Modified = s.Value == "true"
+			}
+		}
+		for _, dep := range bi.Deps {
+			if dep.Path == "github.com/go-rod/rod" {
+				rep.Rod = dep.Version
+			}
+		}
+	}
+
+	rep.FFmpeg = probeFFmpeg(ctx)
+	rep.Chromium = probeChromium(ctx, envGet(dotenv, "GRAIN_BROWSER_BIN"), envGet(dotenv, "GRAIN_BROWSER_REMOTE"), envBool(dotenv, "GRAIN_NO_DOWNLOAD_BROWSER"))
+
+	macOS := runtime.GOOS == "darwin"
+	rep.Backends = []BackendInfo{
+		{Name: "local", Available: true, Configured: true},
+		{Name: "icloud", Available: macOS || envGet(dotenv, "GRAIN_ICLOUD_PATH") != "", Configured: envBool(dotenv, "GRAIN_ICLOUD")},
+		{Name: "webdav", Available: true, Configured: envGet(dotenv, "GRAIN_WEBDAV_URL") != ""},
+		{Name: "gdrive", Available: true, Configured: envBool(dotenv, "GRAIN_GDRIVE")},
+		{Name: "s3", Available: true, Configured: envGet(dotenv, "GRAIN_S3_BUCKET") != ""},
+		{Name: "apple-notes", Available: macOS, Configured: envBool(dotenv, "GRAIN_APPLE_NOTES")},
+	}
+	rep.Quirks = platformQuirks(rep)
+	return rep
+}
+
+// probeFFmpeg finds ffmpeg on PATH and reads its version.
+func probeFFmpeg(ctx context.Context) ToolInfo {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return ToolInfo{Note: "not on PATH"}
+	}
+	return ToolInfo{Found: true, Path: path, Version: toolVersion(ctx, path, "-version")}
+}
+
+// probeChromium reports the browser graindl uses: a remote browser (not
+// contacted), the --browser-bin binary, or Rod's own Chromium download,
+// fetched on the first browser run when missing (see browserdownload.go).
+func probeChromium(ctx context.Context, bin, remote string, noDownload bool) ToolInfo {
+	if remote != "" {
+		return ToolInfo{Found: true, Path: remote, Note: "remote browser (--browser-remote)"}
+	}
+	if bin != "" {
+		if err := validateBrowserBin(bin); err != nil {
+			return ToolInfo{Path: bin, Note: err.Error()}
+		}
+		return ToolInfo{Found: true, Path: bin, Version: toolVersion(ctx, bin, "--version")}
+	}
+	path := launcher.NewBrowser().BinPath()
+	if _, err := os.Stat(path); err != nil {
+		sys, hasSys := launcher.LookPath()
+		switch {
+		case noDownload && hasSys:
+			return ToolInfo{Found: true, Path: sys, Version: toolVersion(ctx, sys, "--version"), Note: "installed browser (--no-download-browser)"}
+		case noDownload:
+			return ToolInfo{Path: path, Note: "not downloaded, and no installed browser found (--no-download-browser)"}
+		}
+		info := ToolInfo{Path: path, Note: "not downloaded yet; fetched on the first browser run"}
+		if hasSys {
+			info.Note += " (" + sys + " is used if the download fails)"
+		}
+		return info
+	}
+	return ToolInfo{Found: true, Path: path, Version: toolVersion(ctx, path, "--version")}
+}
+
+// toolVersion runs path with args and returns the first line of its output.
+func toolVersion(ctx context.Context, path string, args ...string) string {
+	ctx, cancel := context.WithTimeout(ctx, versionProbeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimSpace(line)
+}
+
+// platformQuirks lists environment conditions that change what graindl
+// does or can do.
+func platformQuirks(rep *VersionReport) []string {
+	quirks := []string{}
+	if rep.OS != "darwin" {
+		quirks = append(quirks, "iCloud auto-detection and Apple Notes need macOS (--icloud needs --icloud-path)")
+	}
+	if !rep.FFmpeg.Found {
+		quirks = append(quirks, "no ffmpeg: --audio-only, HLS remux, encrypted HLS, non-MP4 video remux, and MP4 tagging are unavailable")
+	}
+	if root, mem := sessionTmpRoot(); !mem {
+		quirks = append(quirks, fmt.Sprintf("no /dev/shm: --encrypt-session unpacks to %s, which is not memory-backed", root))
+	}
+	if fileExists("/.dockerenv") || fileExists("/run/.containerenv") {
+		quirks = append(quirks, "running in a container: use --headless (no display)")
+	}
+	if !xterm.IsTerminal(os.Stderr.Fd()) {
+		quirks = append(quirks, "stderr is not a terminal: the TUI is off unless --tui is given")
+	}
+	return quirks
+}
+
+func writeVersionJSON(w io.Writer, rep *VersionReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
+
+func writeVersionText(w io.Writer, rep *VersionReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "graindl\t%s (%s)\n", rep.Version, rep.Commit)
+	if rep.BuildTime != "" {
+		modified := ""
+		if rep.Modified {
+			modified = ", modified"
+		}
+		fmt.Fprintf(tw, "Built\t%s%s\n", rep.BuildTime, modified)
+	}
+	fmt.Fprintf(tw, "Go\t%s %s/%s\n", rep.GoVersion, rep.OS, rep.Arch)
+	if rep.Rod != "" {
+		fmt.Fprintf(tw, "Rod\t%s\n", rep.Rod)
+	}
+	fmt.Fprintf(tw, "ffmpeg\t%s\n", toolSummary(rep.FFmpeg))
+	fmt.Fprintf(tw, "Chromium\t%s\n", toolSummary(rep.Chromium))
+	var backends []string
+	for _, b := range rep.Backends {
+		switch {
+		case !b.Available:
+			backends = append(backends, b.Name+" (unavailable)")
+		case b.Configured && b.Name != "local":
+			backends = append(backends, b.Name+" (configured)")
+		default:
+			backends = append(backends, b.Name)
+		}
+	}
+	fmt.Fprintf(tw, "Backends\t%s\n", strings.Join(backends, ", "))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(rep.Quirks) > 0 {
+		fmt.Fprintln(w, "\nPlatform notes:")
+		for _, q := range rep.Quirks {
+			fmt.Fprintf(w, "  - %s\n", q)
+		}
+	}
+	return nil
+}
+
+// toolSummary renders a ToolInfo on one line.
+func toolSummary(t ToolInfo) string {
+	if !t.Found {
+		return strings.TrimSpace("not found " + t.Note)
+	}
+	if t.Version == "" {
+		return t.Path
+	}
+	return fmt.Sprintf("%s (%s)", t.Path, t.Version)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCollectVersionReport(t *testing.T) {
+	t.Setenv("GRAIN_GDRIVE", "")
+	t.Setenv("GRAIN_WEBDAV_URL", "")
+	rep := collectVersionReport(t.Context(), map[string]string{
+		"GRAIN_GDRIVE":    "true",
+		"GRAIN_S3_BUCKET": "acme-share",
+	})
+	if rep.GoVersion == "" || rep.OS == "" || rep.Arch == "" {
+		t.Errorf("runtime fields missing: %+v", rep)
+	}
+	configured := map[string]bool{}
+	for _, b := range rep.Backends {
+		configured[b.Name] = b.Configured
+	}
+	want := map[string]bool{"local": true, "gdrive": true, "s3": true, "webdav": false}
+	for name, on := range want {
+		if got, ok := configured[name]; !ok || got != on {
+			t.Errorf("backend %s configured = %v (present %v), want %v", name, got, ok, on)
+		}
+	}
+	if rep.Chromium.Path == "" {
+		t.Error("Chromium path not reported")
+	}
+}
+
+func TestPlatformQuirks(t *testing.T) {
+	q := strings.Join(platformQuirks(&VersionReport{OS: "linux"}), "\n")
+	if !strings.Contains(q, "need macOS") || !strings.Contains(q, "no ffmpeg") {
+		t.Errorf("quirks = %q", q)
+	}
+	q = strings.Join(platformQuirks(&VersionReport{OS: "darwin", FFmpeg: ToolInfo{Found: true}}), "\n")
+	if strings.Contains(q, "need macOS") || strings.Contains(q, "no ffmpeg") {
+		t.Errorf("quirks = %q", q)
+	}
+}
+
+func TestWriteVersionReport(t *testing.T) {
+	rep := &VersionReport{
+		Version: "v1.2.3", Commit: "abc123", GoVersion: "go1.24.2", OS: "linux", Arch: "amd64",
+		FFmpeg:   ToolInfo{Found: true, Path: "/usr/bin/ffmpeg", Version: "ffmpeg version 6.1"},
+		Chromium: ToolInfo{Path: "/tmp/chrome", Note: "not downloaded yet"},
+		Backends: []BackendInfo{{Name: "local", Available: true, Configured: true}, {Name: "gdrive", Available: true, Configured: true}, {Name: "icloud"}},
+		Quirks:   []string{"running in a container"},
+	}
+
+	var text strings.Builder
+	if err := writeVersionText(&text, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"v1.2.3 (abc123)", "/usr/bin/ffmpeg (ffmpeg version 6.1)", "not found not downloaded yet", "local, gdrive (configured), icloud (unavailable)", "  - running in a container"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var js strings.Builder
+	if err := writeVersionJSON(&js, rep); err != nil {
+		t.Fatal(err)
+	}
+	var back VersionReport
+	if err := json.Unmarshal([]byte(js.String()), &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Version != "v1.2.3" || !back.FFmpeg.Found || len(back.Backends) != 3 {
+		t.Errorf("JSON round trip = %+v", back)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ── Direct Video Download ───────────────────────────────────────────────────
+//
+// When the meeting page exposes a direct (usually signed CDN) video URL, the
+// file is fetched with Go's http.Client instead of through the page's JS
+// fetch. The browser session's cookies are loaded into a cookie jar so URLs
+// that still require the Grain session work too. The body is streamed to
+// <output>.part and renamed into place when complete; an interrupted
+// transfer resumes from the partial file with a Range request. There is no
+// size limit — nothing is buffered in memory or in the browser's heap.
+
+const (
+	videoDLRetries          = 3
+	videoDLProgressInterval = 10 * time.Second
+)
+
+// videoDownloader streams direct video URLs to disk.
+type videoDownloader struct {
+	client   *http.Client
+	retries  int
+	backoff  time.Duration // base retry delay; doubled per attempt
+	interval time.Duration // how often to log progress
+	pace     *Throttle     // per-host request pacing; nil = none
+}
+
+// newVideoDownloader returns a downloader that sends the given browser
+// session cookies to matching hosts.
+func newVideoDownloader(cookies []*http.Cookie, pace *Throttle) *videoDownloader {
+	jar, _ := cookiejar.New(nil) // only errors with non-nil options
+	for _, c := range cookies {
+		host := strings.TrimPrefix(c.Domain, ".")
+		if host == "" {
+			continue
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: c.Path}, []*http.Cookie{c})
+	}
+	return &videoDownloader{
+		client: &http.Client{
+			Jar: jar,
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: 30 * time.Second,
+			},
+		},
+		retries:  videoDLRetries,
+		backoff:  time.Second,
+		interval: videoDLProgressInterval,
+		pace:     pace,
+	}
+}
+
+// Download fetches videoURL to outputPath, resuming a previous partial
+// download when one exists. Returns the number of bytes in the final file.
+func (d *videoDownloader) Download(ctx context.Context, videoURL, outputPath string) (int64, error) {
+	u, err := url.Parse(videoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return 0, fmt.Errorf("not an http(s) URL")
+	}
+
+	part := outputPath + ".part"
+	var lastErr error
+	for attempt := range d.retries {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(d.backoff << (attempt - 1)):
+			}
+			slog.Debug("Retrying video download", "attempt", attempt+1, "error", lastErr)
+		}
+		n, err := d.fetch(ctx, videoURL, part)
+		if err == nil {
+			if err := os.Rename(part, outputPath); err != nil {
+				return 0, err
+			}
+			return n, nil
+		}
+		lastErr = err
+		var perm *permanentDLError
+		if errors.As(err, &perm) || ctx.Err() != nil {
+			break
+		}
+	}
+	return 0, lastErr
+}
+
+// permanentDLError marks failures that retrying will not fix.
+type permanentDLError struct{ err error }
+
+func (e *permanentDLError) Error() string { return e.err.Error() }
+func (e *permanentDLError) Unwrap() error { return e.err }
+
+// fetch makes one request, appending to part when the server honours a
+// Range request for the bytes already on disk. Returns the total size of
+// part once the body has been fully read.
+func (d *videoDownloader) fetch(ctx context.Context, videoURL, part string) (int64, error) {
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	if d.pace != nil {
+		if err := d.pace.WaitHost(ctx, hostOf(videoURL)); err != nil {
+			return 0, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", videoURL, nil)
+	if err != nil {
+		return 0, &permanentDLError{err}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+		slog.Debug("Resuming video download", "offset", offset)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is already complete (or bogus); start over.
+		_ = os.Remove(part)
+		return 0, errors.New("range not satisfiable; restarting")
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		return 0, &permanentDLError{fmt.Errorf("HTTP %d", resp.StatusCode)}
+	}
+	if ct := resp.Header.Get("Content-Type"); strings.HasPrefix(ct, "text/") {
+		// A login or error page served with 200, not a video.
+		return 0, &permanentDLError{fmt.Errorf("unexpected content type %q", ct)}
+	}
+
+	f, err := os.OpenFile(part, flags, 0o600)
+	if err != nil {
+		return 0, &permanentDLError{err}
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	pw := &progressWriter{w: f, done: offset, total: total, interval: d.interval, last: time.Now()}
+	_, copyErr := io.Copy(pw, resp.Body)
+	if err := f.Close(); copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return 0, fmt.Errorf("download interrupted at %s: %w", formatBytes(pw.done), copyErr)
+	}
+	if total >= 0 && pw.done != total {
+		return 0, fmt.Errorf("short download: got %s of %s", formatBytes(pw.done), formatBytes(total))
+	}
+	return pw.done, nil
+}
+
+// progressWriter counts bytes written and logs progress periodically.
+type progressWriter struct {
+	w        io.Writer
+	done     int64
+	total    int64 // -1 when unknown
+	interval time.Duration
+	last     time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.done += int64(n)
+	if p.interval > 0 && time.Since(p.last) >= p.interval {
+		p.last = time.Now()
+		if p.total > 0 {
+			slog.Info("Downloading video", "progress", fmt.Sprintf("%s / %s (%d%%)",
+				formatBytes(p.done), formatBytes(p.total), p.done*100/p.total))
+		} else {
+			slog.Info("Downloading video", "progress", formatBytes(p.done))
+		}
+	}
+	return n, err
+}
+
+// fetchViaHTTP downloads a direct video URL with the browser session's
+// cookies. Returns false (after logging why) when the URL could not be
+// fetched this way.
+func (b *Browser) fetchViaHTTP(ctx context.Context, videoURL, outputPath string) bool {
+	cookies, err := b.exportCookies()
+	if err != nil {
+		slog.Debug("Could not export cookies for video download", "error", err)
+	}
+	d := newVideoDownloader(cookies, b.throttle)
+	d.client.Transport = withChaos(b.cfg.Chaos, chaosDownload, true, withAPIIdentity(b.cfg, d.client.Transport))
+	n, err := d.Download(ctx, videoURL, outputPath)
+	if err != nil {
+		slog.Debug("Direct video download failed", "error", err)
+		return false
+	}
+	slog.Debug("Video downloaded", "size", formatBytes(n))
+	return true
+}
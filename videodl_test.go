@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testVideoBody() []byte {
+	return bytes.Repeat([]byte("0123456789abcdef"), 4096) // 64 KiB
+}
+
+func TestVideoDownloaderSendsSessionCookies(t *testing.T) {
+	body := testVideoBody()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "abc" {
+			http.Error(w, "no session", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	host := strings.Split(strings.TrimPrefix(srv.URL, "http://"), ":")[0]
+	cookies := []*http.Cookie{
+		{Name: "session", Value: "abc", Domain: host, Path: "/"},
+		{Name: "other", Value: "x", Domain: ".example.com", Path: "/"},
+	}
+	out := filepath.Join(t.TempDir(), "v.mp4")
+	n, err := newVideoDownloader(cookies, nil).Download(context.Background(), srv.URL+"/v.mp4?sig=1", out)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if n != int64(len(body)) {
+		t.Errorf("size = %d, want %d", n, len(body))
+	}
+	got, _ := os.ReadFile(out)
+	if !bytes.Equal(got, body) {
+		t.Error("downloaded content mismatch")
+	}
+	if info, _ := os.Stat(out); info.Mode().Perm() != 0o600 {
+		t.Errorf("perm = %o, want 600", info.Mode().Perm())
+	}
+	if fileExists(out + ".part") {
+		t.Error(".part file left behind")
+	}
+}
+
+func TestVideoDownloaderResumesPartial(t *testing.T) {
+	body := testVideoBody()
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("Content-Type", "video/mp4")
+		http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "v.mp4")
+	if err := os.WriteFile(out+".part", body[:1000], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newVideoDownloader(nil, nil).Download(context.Background(), srv.URL, out); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=1000-" {
+		t.Errorf("Range headers = %q, want [bytes=1000-]", ranges)
+	}
+	got, _ := os.ReadFile(out)
+	if !bytes.Equal(got, body) {
+		t.Error("resumed content mismatch")
+	}
+}
+
+func TestVideoDownloaderRetriesInterruptedTransfer(t *testing.T) {
+	body := testVideoBody()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		if calls.Add(1) == 1 {
+			// Promise the full body, send half, then drop the connection.
+			w.Header().Set("Content-Length", "65536")
+			w.Write(body[:len(body)/2])
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "v.mp4", time.Time{}, bytes.NewReader(body))
+	}))
+	defer srv.Close()
+
+	d := newVideoDownloader(nil, nil)
+	d.backoff = time.Millisecond
+	out := filepath.Join(t.TempDir(), "v.mp4")
+	if _, err := d.Download(context.Background(), srv.URL, out); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	if !bytes.Equal(got, body) {
+		t.Error("content mismatch after retry")
+	}
+}
+
+func TestVideoDownloaderRejectsNonVideo(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/login" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>sign in</html>"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	d := newVideoDownloader(nil, nil)
+	d.backoff = time.Millisecond
+	dir := t.TempDir()
+	for _, path := range []string{"/login", "/missing"} {
+		calls.Store(0)
+		out := filepath.Join(dir, "v.mp4")
+		if _, err := d.Download(context.Background(), srv.URL+path, out); err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("%s: %d requests, want 1 (no retry)", path, calls.Load())
+		}
+		if fileExists(out) {
+			t.Errorf("%s: output written", path)
+		}
+	}
+
+	blob := (&url.URL{Scheme: "blob", Opaque: "https://grain.com/abc"}).String()
+	if _, err := d.Download(context.Background(), blob, filepath.Join(dir, "b.mp4")); err == nil {
+		t.Error("blob: URL should be rejected")
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ── Local Video Retention ───────────────────────────────────────────────────
+//
+// --gdrive-clean-local removes every local file as soon as its upload
+// succeeds. --gdrive-keep-videos is the gentler policy: metadata,
+// transcripts, highlights, and notes always stay, and videos and audio stay
+// for a while after they reach Drive so recent meetings can still be played
+// locally. At the end of every run (so after each watch cycle) a cleanup
+// pass walks the Drive sync state for video and audio files uploaded longer
+// ago than the retention period. One is removed only when its upload is
+// verified: the local copy still has the MD5 that was uploaded, and Drive
+// reports the same MD5 for the file. Anything else is kept and looked at
+// again next run. The manifest's video_retention reports the pass.
+
+// VideoRetentionReport summarizes one cleanup pass.
+type VideoRetentionReport struct {
+	Removed    int   `json:"removed"`
+	Reclaimed  int64 `json:"reclaimed_bytes"`
+	Kept       int   `json:"kept,omitempty"`       // uploaded, still within the retention period
+	Unverified int   `json:"unverified,omitempty"` // past it, but the upload could not be verified
+}
+
+// parseKeepVideos parses --gdrive-keep-videos: whole days or weeks ("30d",
+// "2w") or a Go duration ("36h").
+func parseKeepVideos(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	d, err := time.ParseDuration(s)
+	if m := sinceRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		d, err = time.Duration(n)*24*time.Hour, nil
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --gdrive-keep-videos %q (use e.g. 30d, 2w, or 36h)", s)
+	}
+	return d, nil
+}
+
+// isRetainedVideo reports whether relPath is subject to video retention.
+func isRetainedVideo(relPath string) bool {
+	switch classifyContent(relPath) {
+	case "video", "audio":
+		return true
+	}
+	return false
+}
+
+// remoteMD5 returns the MD5 Drive reports for fileID.
+func (d *DriveUploader) remoteMD5(ctx context.Context, fileID string) (string, error) {
+	apiURL := fmt.Sprintf("%s/files/%s?fields=%s", driveAPIBase, url.PathEscape(fileID), url.QueryEscape("md5Checksum,trashed"))
+	resp, err := d.driveRequest(ctx, "GET", apiURL, nil, "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("get file failed (%d): %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+	var f struct {
+		MD5Checksum string `json:"md5Checksum"`
+		Trashed     bool   `json:"trashed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&f); err != nil {
+		return "", err
+	}
+	if f.Trashed {
+		return "", fmt.Errorf("file %s is in the Drive trash", fileID)
+	}
+	return f.MD5Checksum, nil
+}
+
+// applyVideoRetention removes local videos whose verified upload is older
+// than --gdrive-keep-videos and records the pass in the manifest.
+func (e *Exporter) applyVideoRetention(ctx context.Context) {
+	if e.drive == nil || e.cfg.GDriveKeepVideos <= 0 {
+		return
+	}
+	d := e.drive
+	d.mu.Lock()
+	entries := make(map[string]SyncEntry)
+	for rel, entry := range d.state.Files {
+		if isRetainedVideo(rel) {
+			entries[rel] = *entry
+		}
+	}
+	d.mu.Unlock()
+
+	report := &VideoRetentionReport{}
+	cutoff := time.Now().Add(-e.cfg.GDriveKeepVideos)
+	var removed []string
+	for _, rel := range slices.Sorted(maps.Keys(entries)) {
+		if ctx.Err() != nil {
+			break
+		}
+		entry := entries[rel]
+		local := filepath.Join(e.cfg.OutputDir, rel)
+		info, err := os.Stat(local)
+		if err != nil {
+			continue // already gone
+		}
+		if uploaded, err := time.Parse(time.RFC3339, entry.UploadedAt); err != nil || uploaded.After(cutoff) {
+			report.Kept++
+			continue
+		}
+		if sum, err := md5File(local); err != nil || sum != entry.MD5Checksum {
+			slog.Debug("Keeping video changed since its upload", "path", rel)
+			report.Unverified++
+			continue
+		}
+		sum, err := d.remoteMD5(ctx, entry.DriveFileID)
+		if err == nil && sum != entry.MD5Checksum {
+			err = fmt.Errorf("Drive reports MD5 %s, uploaded %s", sum, entry.MD5Checksum)
+		}
+		if err != nil {
+			slog.Warn("Keeping video: Drive copy could not be verified", "path", rel, "error", err)
+			report.Unverified++
+			continue
+		}
+		if err := os.Remove(local); err != nil {
+			slog.Warn("Failed to remove local video", "path", rel, "error", err)
+			continue
+		}
+		slog.Debug("Removed local video past retention", "path", rel)
+		removed = append(removed, rel)
+		report.Removed++
+		report.Reclaimed += info.Size()
+	}
+	if len(removed) > 0 {
+		forgetChecksums(e.cfg.OutputDir, removed)
+	}
+
+	if report.Removed > 0 || report.Unverified > 0 {
+		slog.Info(fmt.Sprintf("Video retention: removed %d local video(s), reclaimed %s", report.Removed, formatBytes(report.Reclaimed)),
+			"kept", report.Kept, "unverified", report.Unverified)
+	}
+	e.manifest.VideoRetention = report
+}
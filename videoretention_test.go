@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseKeepVideos(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		if got, err := parseKeepVideos(in); err != nil || got != want {
+			t.Errorf("parseKeepVideos(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0d", "-1h", "soon"} {
+		if _, err := parseKeepVideos(in); err == nil {
+			t.Errorf("parseKeepVideos(%q): want error", in)
+		}
+	}
+}
+
+// fakeDriveMD5 answers file metadata requests with the MD5 in md5s.
+type fakeDriveMD5 map[string]string
+
+func (f fakeDriveMD5) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	sum, ok := f[strings.TrimPrefix(req.URL.Path, "/drive/v3/files/")]
+	if req.Method != "GET" || !ok {
+		rec.WriteHeader(http.StatusNotFound)
+		return rec.Result(), nil
+	}
+	fmt.Fprintf(rec, `{"md5Checksum":%q}`, sum)
+	return rec.Result(), nil
+}
+
+func TestApplyVideoRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-72 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	state := &DriveSyncState{Files: map[string]*SyncEntry{}}
+	remote := fakeDriveMD5{}
+	add := func(name, content, uploadedAt, driveMD5 string) {
+		writeArchiveFile(t, dir, "2025-01-15/"+name, content, 0)
+		sum, _ := md5File(filepath.Join(dir, "2025-01-15", name))
+		state.Files[filepath.Join("2025-01-15", name)] = &SyncEntry{DriveFileID: "id-" + name, MD5Checksum: sum, UploadedAt: uploadedAt}
+		remote["id-"+name] = coalesce(driveMD5, sum)
+	}
+	add("old.mp4", "old video", old, "")
+	add("old.m4a", "old audio", old, "")
+	add("old.transcript.txt", "transcript", old, "")
+	add("recent.mp4", "recent video", recent, "")
+	add("mismatch.mp4", "drive copy differs", old, "bad")
+	add("edited.mp4", "uploaded", old, "")
+	writeArchiveFile(t, dir, "2025-01-15/edited.mp4", "edited since", 0)
+
+	e := &Exporter{
+		cfg:      &Config{OutputDir: dir, GDriveKeepVideos: 24 * time.Hour},
+		manifest: &ExportManifest{},
+		drive: &DriveUploader{
+			client: &http.Client{Transport: remote},
+			token:  &oauthToken{AccessToken: "t", Expiry: time.Now().Add(time.Hour)},
+			state:  state,
+		},
+	}
+	e.applyVideoRetention(context.Background())
+
+	for name, want := range map[string]bool{
+		"old.mp4":            false,
+		"old.m4a":            false,
+		"old.transcript.txt": true,
+		"recent.mp4":         true,
+		"mismatch.mp4":       true,
+		"edited.mp4":         true,
+	} {
+		if got := fileExists(filepath.Join(dir, "2025-01-15", name)); got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+	rep := e.manifest.VideoRetention
+	if rep == nil || rep.Removed != 2 || rep.Reclaimed != int64(len("old video")+len("old audio")) || rep.Kept != 1 || rep.Unverified != 2 {
+		t.Errorf("report = %+v", rep)
+	}
+
+	// Without the flag nothing is touched or reported.
+	e = &Exporter{cfg: &Config{OutputDir: dir}, manifest: &ExportManifest{}, drive: e.drive}
+	e.applyVideoRetention(context.Background())
+	if e.manifest.VideoRetention != nil {
+		t.Error("report without --gdrive-keep-videos")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025-01-15", "recent.mp4")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ── Unavailable Videos ──────────────────────────────────────────────────────
+//
+// Some meetings never get a downloadable video: audio-only calls, or
+// recordings whose processing failed in Grain. Each export of such a meeting
+// (--overwrite, --min-quality, a re-run after deleting metadata) would walk
+// the whole download fallback chain again — button, <video> source, network
+// capture — which holds the browser for up to a minute. When the chain finds
+// no video, the meeting is recorded in videoStateFile as video_unavailable,
+// and exports skip the browser attempts until videoRetryAfter has passed. A
+// later download that finds a video clears the entry.
+
+// videoStateFile records meetings without a video. Hidden, like the other
+// state files, so mirrors and Drive sync leave it alone.
+const videoStateFile = ".graindl-video-state.json"
+
+// videoRetryAfter is how long a meeting without a video is left alone.
+const videoRetryAfter = 7 * 24 * time.Hour
+
+// videoUnavailable is the ExportResult.VideoStatus of a meeting whose video
+// could not be found.
+const videoUnavailable = "video_unavailable"
+
+// VideoState is the persisted list of meetings without a video.
+type VideoState struct {
+	Meetings map[string]*UnavailableVideo `json:"meetings"` // meeting ID →
+}
+
+// UnavailableVideo is one meeting whose download chain found no video.
+type UnavailableVideo struct {
+	Status    string    `json:"status"` // "video_unavailable"
+	FirstSeen time.Time `json:"first_seen"`
+	LastTried time.Time `json:"last_tried"`
+	Attempts  int       `json:"attempts"`
+	RetryAt   time.Time `json:"retry_at"`
+}
+
+// videoStateMu serializes read-modify-write of the video state between
+// --parallel workers.
+var videoStateMu sync.Mutex
+
+// loadVideoState reads the video state, falling back to its backup. A
+// missing or unreadable file yields an empty state (every video is tried).
+func loadVideoState(outputDir string) *VideoState {
+	st := &VideoState{}
+	path := filepath.Join(outputDir, videoStateFile)
+	err := readStateFile(path, func(data []byte) error {
+		st = &VideoState{}
+		return json.Unmarshal(data, st)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Video state unreadable, retrying all videos", "path", path, "error", err)
+	}
+	if st.Meetings == nil {
+		st.Meetings = map[string]*UnavailableVideo{}
+	}
+	return st
+}
+
+// videoCoolingDown reports whether id is a meeting without a video that is
+// not due for another attempt at now, and when it is due.
+func (e *Exporter) videoCoolingDown(id string, now time.Time) (time.Time, bool) {
+	videoStateMu.Lock()
+	defer videoStateMu.Unlock()
+	u := loadVideoState(e.cfg.OutputDir).Meetings[id]
+	if u == nil || !now.Before(u.RetryAt) {
+		return time.Time{}, false
+	}
+	return u.RetryAt, true
+}
+
+// skipUnavailableVideo marks r and reports true when id's video is still
+// cooling down, so the caller can skip the download attempts.
+func (e *Exporter) skipUnavailableVideo(id string, r *ExportResult) bool {
+	if e.cfg.Retry {
+		return false // graindl retry tries dead-lettered meetings in full
+	}
+	retryAt, ok := e.videoCoolingDown(id, time.Now())
+	if !ok {
+		return false
+	}
+	r.VideoStatus, r.VideoRetryAt = videoUnavailable, retryAt.UTC().Format(time.RFC3339)
+	slog.Info("No video last time, skipping download", "id", id, "retry_at", retryAt.Format(time.DateOnly))
+	return true
+}
+
+// recordVideoOutcome updates id's entry after a download attempt: found
+// clears it, otherwise the meeting is marked video_unavailable until a week
+// from now. Attempts cut short by shutdown are not recorded.
+func (e *Exporter) recordVideoOutcome(ctx context.Context, id string, found bool, r *ExportResult) {
+	if ctx.Err() != nil {
+		return
+	}
+	now := time.Now().UTC()
+	videoStateMu.Lock()
+	defer videoStateMu.Unlock()
+	st := loadVideoState(e.cfg.OutputDir)
+	u := st.Meetings[id]
+	switch {
+	case found && u == nil:
+		return
+	case found:
+		delete(st.Meetings, id)
+		slog.Info("Video available again", "id", id, "after_attempts", u.Attempts)
+	default:
+		if u == nil {
+			u = &UnavailableVideo{Status: videoUnavailable, FirstSeen: now}
+			st.Meetings[id] = u
+		}
+		u.LastTried, u.RetryAt = now, now.Add(videoRetryAfter)
+		u.Attempts++
+		r.VideoStatus, r.VideoRetryAt = videoUnavailable, u.RetryAt.Format(time.RFC3339)
+		slog.Warn("No video found; not retrying for a week", "id", id, "retry_at", u.RetryAt.Format(time.DateOnly))
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err == nil {
+		err = writeStateFile(filepath.Join(e.cfg.OutputDir, videoStateFile), data)
+	}
+	if err != nil {
+		slog.Warn("Video state write failed", "error", err)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecordVideoOutcome(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}, storage: NewLocalStorage(dir)}
+	ctx := context.Background()
+
+	r := &ExportResult{}
+	e.recordVideoOutcome(ctx, "m1", false, r)
+	if r.VideoStatus != videoUnavailable || r.VideoRetryAt == "" {
+		t.Errorf("result = %q, %q", r.VideoStatus, r.VideoRetryAt)
+	}
+	e.recordVideoOutcome(ctx, "m1", false, &ExportResult{})
+	u := loadVideoState(dir).Meetings["m1"]
+	if u == nil || u.Attempts != 2 || u.Status != videoUnavailable {
+		t.Fatalf("state = %+v", u)
+	}
+	if d := u.RetryAt.Sub(u.LastTried); d != videoRetryAfter {
+		t.Errorf("retry after %v, want %v", d, videoRetryAfter)
+	}
+
+	// Cooling down now; due again a week later.
+	if _, ok := e.videoCoolingDown("m1", time.Now()); !ok {
+		t.Error("m1 not cooling down right after a failure")
+	}
+	if _, ok := e.videoCoolingDown("m1", time.Now().Add(videoRetryAfter+time.Minute)); ok {
+		t.Error("m1 still cooling down after a week")
+	}
+	if _, ok := e.videoCoolingDown("m2", time.Now()); ok {
+		t.Error("unknown meeting cooling down")
+	}
+
+	// The cool-down skips the download before touching the browser.
+	r = &ExportResult{}
+	e.writeVideo(ctx, MeetingRef{ID: "m1"}, nil, "2025-01-15/m1.mp4", r)
+	if r.VideoStatus != videoUnavailable || r.VideoMethod != "" {
+		t.Errorf("writeVideo during cool-down: status %q, method %q", r.VideoStatus, r.VideoMethod)
+	}
+	r = &ExportResult{}
+	e.writeAudio(ctx, MeetingRef{ID: "m1"}, "2025-01-15/m1.m4a", r)
+	if r.VideoStatus != videoUnavailable || r.AudioMethod != "" {
+		t.Errorf("writeAudio during cool-down: status %q, method %q", r.VideoStatus, r.AudioMethod)
+	}
+
+	// A found video clears the entry; a cancelled attempt records nothing.
+	e.recordVideoOutcome(ctx, "m1", true, &ExportResult{})
+	if len(loadVideoState(dir).Meetings) != 0 {
+		t.Error("found video left an entry")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	e.recordVideoOutcome(cancelled, "m3", false, &ExportResult{})
+	if len(loadVideoState(dir).Meetings) != 0 {
+		t.Error("cancelled attempt recorded")
+	}
+}
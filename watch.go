@@ -2,31 +2,60 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
 )
 
 // RunWatch runs the exporter in a continuous loop, polling for new meetings
-// at the configured interval. The browser session is reused across cycles,
-// and meetings that were already exported (metadata file exists) are
-// automatically skipped.
+// at the configured interval, or at the times given by --schedule. The
+// browser session is reused across cycles, and meetings that were already
+// exported (metadata file exists) are automatically skipped.
 func (e *Exporter) RunWatch(ctx context.Context) error {
-	interval := e.cfg.WatchInterval
-
 	var totalOK, totalSkipped, totalErrors int
-	cycle := 0
+	var fatal error
+	cycle, failures := 0, 0
+
+	// After downtime that skipped cycles, catch up right away; otherwise
+	// wait for the first slot of a schedule or start right away.
+	next := time.Now()
+	state := loadWatchState(e.cfg.OutputDir)
+	last := state.LastCycleAt
+	e.health.update(func(st *HealthStatus) { st.LastExportAt = formatHealthTime(state.LastExportAt) })
+	missed := e.missedCycles(last, next)
+	catchUp := missed >= watchCatchUpMissed
+	if catchUp {
+		slog.Info(fmt.Sprintf("Watch was down since %s (%d missed cycles) — running a catch-up cycle",
+			last.Local().Format("Mon Jan 2 15:04 MST"), missed))
+	} else if e.cfg.WatchSchedule != nil {
+		next = e.nextWatchRun(next)
+		slog.Info(fmt.Sprintf("Next scheduled run: %s (in %s)", next.Format("Mon Jan 2 15:04 MST"), time.Until(next).Round(time.Second)))
+		e.touchHealthcheck(next)
+	}
 
-	for {
+	for waitUntil(ctx, next) {
 		cycle++
 		slog.Info(fmt.Sprintf("── watch cycle %d ─────────────────────────────────────", cycle))
 
-		// Fresh manifest per cycle.
+		// Fresh manifest, auth guard, and error budget per cycle.
 		e.manifest = &ExportManifest{ExportedAt: time.Now().UTC().Format(time.RFC3339)}
-		e.searchFilter = nil
+		e.auth = newAuthGuard(authFailureThreshold)
+		e.failures = newErrorBudget(e.cfg.MaxErrors)
 
+		endCatchUp := func() {}
+		if catchUp {
+			endCatchUp = e.beginCatchUp(missed)
+		}
+		started := time.Now()
+		e.health.update(func(st *HealthStatus) { st.CycleStartedAt = formatHealthTime(started) })
 		err := e.Run(ctx)
+		endCatchUp()
+		e.endHealthCycle(cycle, time.Now(), err)
 		totalOK += e.manifest.OK
 		totalSkipped += e.manifest.Skipped
 		totalErrors += e.manifest.Errors
@@ -36,29 +65,40 @@ func (e *Exporter) RunWatch(ctx context.Context) error {
 			break
 		}
 
+		// A revoked session won't fix itself; stop so the operator notices.
+		if errors.Is(err, errAuthBlocked) {
+			fatal = err
+			break
+		}
 		if err != nil {
-			slog.Error("Cycle failed (will retry)", "cycle", cycle, "error", err)
+			// A failed catch-up is retried next cycle.
+			failures++
+			slog.Error("Cycle failed (will retry)", "cycle", cycle, "error", err, "consecutive_failures", failures)
+		} else {
+			if failures >= watchBackoffAfter {
+				slog.Info("Cycle succeeded; back to the normal interval", "failed_cycles", failures)
+			}
+			failures = 0
+			catchUp = false
+			if !e.cfg.DryRun {
+				e.saveWatchState(time.Now())
+			}
 		}
 
-		// Touch healthcheck file so external monitors can detect liveness.
-		if e.cfg.HealthcheckFile != "" {
-			if err := os.WriteFile(e.cfg.HealthcheckFile, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600); err != nil {
-				slog.Warn("Healthcheck file write failed", "error", err)
-			}
+		next = e.nextWatchBackoff(time.Now(), failures)
+		e.health.update(func(st *HealthStatus) {
+			st.FailedCycles = failures
+			st.Backoff = failures >= watchBackoffAfter
+		})
+		if failures >= watchBackoffAfter {
+			slog.Warn(fmt.Sprintf("%d consecutive failed cycles — backing off, next attempt %s", failures, describeNextRun(next, true)))
 		}
 
-		slog.Info(fmt.Sprintf("── cycle %d done (exported=%d skipped=%d errors=%d) — next poll in %s ──",
-			cycle, e.manifest.OK, e.manifest.Skipped, e.manifest.Errors, interval))
+		// Touch healthcheck file so external monitors can detect liveness.
+		e.touchHealthcheck(next)
 
-		timer := time.NewTimer(interval)
-		select {
-		case <-ctx.Done():
-			timer.Stop()
-			// Shutdown requested while waiting.
-		case <-timer.C:
-			continue
-		}
-		break
+		slog.Info(fmt.Sprintf("── cycle %d done (exported=%d skipped=%d errors=%d) — next poll %s ──",
+			cycle, e.manifest.OK, e.manifest.Skipped, e.manifest.Errors, describeNextRun(next, e.cfg.WatchSchedule != nil)))
 	}
 
 	slog.Info("Watch mode stopped",
@@ -67,5 +107,330 @@ func (e *Exporter) RunWatch(ctx context.Context) error {
 		"total_skipped", totalSkipped,
 		"total_errors", totalErrors,
 	)
-	return nil
+	return fatal
+}
+
+// ── Catch-up ────────────────────────────────────────────────────────────────
+//
+// The time of the last successful cycle is kept in watchStateFile. When the
+// daemon restarts after missing watchCatchUpMissed or more cycles (it was
+// down, or the host slept), the first cycle runs immediately and digs
+// deeper than usual: lists are scrolled with catchUpScrollDepth and --max is
+// raised to cover every missed cycle. With --claim-ttl only the instance
+// holding the watchCatchUpClaim goes deep; the others run a normal cycle
+// and per-meeting claims keep them from exporting anything twice.
+
+// watchStateFile records the last successful cycle. Hidden, like the sync
+// state, so mirrors and Drive sync leave it alone.
+const watchStateFile = ".graindl-watch-state.json"
+
+const (
+	watchCatchUpMissed = 2                 // missed cycles that trigger a catch-up
+	watchMissedCap     = 1000              // stop counting missed cycles here
+	watchCatchUpClaim  = "_watch-catch-up" // claim id shared by all instances
+)
+
+// WatchState is the persisted watch-mode state.
+type WatchState struct {
+	LastCycleAt  time.Time `json:"last_cycle_at"`
+	LastExportAt time.Time `json:"last_export_at,omitempty"` // last cycle with a new export
+}
+
+// loadWatchState reads the watch state, falling back to its backup. A
+// missing or unreadable file yields the zero state (no catch-up).
+func loadWatchState(outputDir string) WatchState {
+	var st WatchState
+	path := filepath.Join(outputDir, watchStateFile)
+	err := readStateFile(path, func(data []byte) error {
+		st = WatchState{}
+		return json.Unmarshal(data, &st)
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Watch state unreadable, not catching up", "path", path, "error", err)
+	}
+	return st
+}
+
+// saveWatchState records a finished cycle. Instances sharing the archive
+// all write the file; the time never moves backwards.
+func (e *Exporter) saveWatchState(at time.Time) {
+	e.updateWatchState(func(st *WatchState) bool {
+		if at.Before(st.LastCycleAt) {
+			return false
+		}
+		st.LastCycleAt = at.UTC()
+		return true
+	})
+}
+
+// updateWatchState applies fn to the stored watch state and writes it back
+// if fn reports a change.
+func (e *Exporter) updateWatchState(fn func(*WatchState) bool) {
+	st := loadWatchState(e.cfg.OutputDir)
+	if !fn(&st) {
+		return
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err == nil {
+		err = writeStateFile(filepath.Join(e.cfg.OutputDir, watchStateFile), data)
+	}
+	if err != nil {
+		slog.Warn("Watch state write failed", "error", err)
+	}
+}
+
+// missedCycles counts the cycles that should have started after last and
+// by now, up to watchMissedCap. A zero last (first start) misses none.
+func (e *Exporter) missedCycles(last, now time.Time) int {
+	if last.IsZero() {
+		return 0
+	}
+	n := 0
+	for t := e.nextWatchRun(last); !t.IsZero() && !t.After(now) && n < watchMissedCap; t = e.nextWatchRun(t) {
+		n++
+	}
+	return n
+}
+
+// beginCatchUp switches the exporter to catch-up depth for one cycle and
+// returns the function that switches it back.
+func (e *Exporter) beginCatchUp(missed int) (end func()) {
+	var claim *Claim
+	if e.claims != nil {
+		c, err := e.claims.Acquire(watchCatchUpClaim)
+		switch {
+		case errors.Is(err, errClaimHeld):
+			slog.Info("Another instance is catching up; running a normal cycle")
+			return func() {}
+		case err != nil:
+			slog.Warn("Catch-up claim failed, catching up anyway", "error", err)
+		default:
+			claim = c
+		}
+	}
+
+	maxMeetings := e.cfg.MaxMeetings
+	if maxMeetings > 0 {
+		e.cfg.MaxMeetings = maxMeetings * (missed + 1)
+	}
+	e.setScrollDepth(catchUpScrollDepth)
+	slog.Debug("Catch-up cycle", "missed", missed, "max", e.cfg.MaxMeetings, "stable_rounds", catchUpScrollDepth.stableRounds)
+
+	return func() {
+		e.cfg.MaxMeetings = maxMeetings
+		e.setScrollDepth(defaultScrollDepth)
+		if claim != nil {
+			claim.Release()
+		}
+	}
+}
+
+// ── Failure Backoff ─────────────────────────────────────────────────────────
+//
+// After watchBackoffAfter consecutive failed cycles (network down, Grain
+// outage) the wait before the next cycle doubles with each further failure,
+// up to watchBackoffCap, instead of retrying every interval. With
+// --schedule the scheduled slots inside the backoff are skipped. The first
+// successful cycle returns to the normal interval. The healthcheck reports
+// backoff and consecutive_failures meanwhile.
+
+const (
+	watchBackoffAfter = 3             // consecutive failed cycles before backing off
+	watchBackoffCap   = 6 * time.Hour // longest wait between cycles while backing off
+)
+
+// nextWatchBackoff returns when the next cycle should start after now,
+// given the number of consecutive failed cycles.
+func (e *Exporter) nextWatchBackoff(now time.Time, failures int) time.Time {
+	next := e.nextWatchRun(now)
+	if failures < watchBackoffAfter || next.IsZero() {
+		return next
+	}
+	wait := next.Sub(now)
+	limit := max(watchBackoffCap, wait)
+	for range failures - watchBackoffAfter + 1 {
+		if wait *= 2; wait >= limit {
+			wait = limit
+			break
+		}
+	}
+	if e.cfg.WatchSchedule != nil {
+		// First scheduled slot at or after the backoff.
+		return e.cfg.WatchSchedule.Next(now.Add(wait - time.Second))
+	}
+	return now.Add(wait)
+}
+
+// nextWatchRun returns when the next cycle should start after now.
+func (e *Exporter) nextWatchRun(now time.Time) time.Time {
+	if e.cfg.WatchSchedule != nil {
+		return e.cfg.WatchSchedule.Next(now)
+	}
+	return now.Add(e.cfg.WatchInterval)
+}
+
+// touchHealthcheck writes the healthcheck file after a cycle, recording
+// the next scheduled run.
+func (e *Exporter) touchHealthcheck(next time.Time) {
+	var lines []string
+	e.health.update(func(st *HealthStatus) {
+		st.NextRun = formatHealthTime(next)
+		st.Progress = nil
+		if st.Backoff {
+			lines = append(lines, "backoff=true", fmt.Sprintf("consecutive_failures=%d", st.FailedCycles))
+		}
+	})
+	if !next.IsZero() {
+		lines = append([]string{"next_run=" + next.UTC().Format(time.RFC3339)}, lines...)
+	}
+	e.writeHealthcheck(lines...)
+}
+
+// writeHealthcheck writes the current time on the first line (unchanged
+// format for existing monitors), followed by key=value status lines. With
+// --healthcheck-format json the HealthStatus is written instead.
+func (e *Exporter) writeHealthcheck(lines ...string) {
+	if e.cfg.HealthcheckFile == "" {
+		return
+	}
+	if e.cfg.HealthcheckFormat == "json" {
+		if err := e.writeHealthJSON(); err != nil {
+			slog.Warn("Healthcheck file write failed", "error", err)
+		}
+		return
+	}
+	content := time.Now().UTC().Format(time.RFC3339) + "\n"
+	for _, l := range lines {
+		content += l + "\n"
+	}
+	if err := os.WriteFile(e.cfg.HealthcheckFile, []byte(content), 0o600); err != nil {
+		slog.Warn("Healthcheck file write failed", "error", err)
+	}
+}
+
+// ── Healthcheck status ──────────────────────────────────────────────────────
+//
+// With --healthcheck-format json the healthcheck file holds a HealthStatus
+// document instead of the text lines, so monitors can alert on what the
+// daemon did ("no new exports for 7 days") and not only on liveness. The
+// status is kept up to date in both formats; only the file differs.
+
+// HealthStatus is the JSON healthcheck document.
+type HealthStatus struct {
+	UpdatedAt      string          `json:"updated_at"`
+	Cycle          int             `json:"cycle"`
+	CycleStartedAt string          `json:"cycle_started_at,omitempty"`
+	CycleEndedAt   string          `json:"cycle_ended_at,omitempty"`
+	Exported       int             `json:"exported"` // new exports in the last cycle
+	Skipped        int             `json:"skipped"`
+	Errors         int             `json:"errors"`
+	Error          string          `json:"error,omitempty"`          // why the last cycle failed
+	LastExportAt   string          `json:"last_export_at,omitempty"` // last cycle with a new export, across restarts
+	NextRun        string          `json:"next_run,omitempty"`
+	Backoff        bool            `json:"backoff,omitempty"`              // waiting longer after repeated failed cycles
+	FailedCycles   int             `json:"consecutive_failures,omitempty"` // failed cycles in a row
+	Progress       *HealthProgress `json:"progress,omitempty"`             // while a run is in progress
+}
+
+// HealthProgress mirrors the progress= healthcheck lines.
+type HealthProgress struct {
+	Done       int     `json:"done"`
+	Total      int     `json:"total"`
+	AvgSeconds float64 `json:"avg_seconds,omitempty"`
+	ETA        string  `json:"eta,omitempty"`
+}
+
+// healthState guards the status; progress updates arrive from workers.
+type healthState struct {
+	mu sync.Mutex
+	st HealthStatus
+}
+
+// update applies fn to the status under the lock.
+func (h *healthState) update(fn func(*HealthStatus)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fn(&h.st)
+}
+
+// formatHealthTime formats t for the status; the zero time is omitted.
+func formatHealthTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// endHealthCycle records a finished cycle's counts in the status. A cycle
+// with new exports also moves last_export_at, which is persisted in the
+// watch state so a restart doesn't reset the monitor's clock.
+func (e *Exporter) endHealthCycle(cycle int, now time.Time, err error) {
+	m := e.manifest
+	e.health.update(func(st *HealthStatus) {
+		st.Cycle = cycle
+		st.CycleEndedAt = formatHealthTime(now)
+		st.Exported, st.Skipped, st.Errors = m.OK, m.Skipped, m.Errors
+		st.Error = ""
+		if err != nil {
+			st.Error = err.Error()
+		}
+		if m.OK > 0 {
+			st.LastExportAt = formatHealthTime(now)
+		}
+	})
+	if m.OK > 0 && !e.cfg.DryRun {
+		e.updateWatchState(func(st *WatchState) bool {
+			if now.Before(st.LastExportAt) {
+				return false
+			}
+			st.LastExportAt = now.UTC()
+			return true
+		})
+	}
+}
+
+// writeHealthJSON atomically replaces the healthcheck file with the status,
+// so a monitor never reads half a document.
+func (e *Exporter) writeHealthJSON() error {
+	e.health.mu.Lock()
+	defer e.health.mu.Unlock()
+	e.health.st.UpdatedAt = formatHealthTime(time.Now())
+	data, err := json.MarshalIndent(e.health.st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := e.cfg.HealthcheckFile + ".tmp"
+	if err := writeFile(tmp, append(data, '\n')); err != nil {
+		return err
+	}
+	return os.Rename(tmp, e.cfg.HealthcheckFile)
+}
+
+// describeNextRun formats the next-run time for the cycle summary line.
+func describeNextRun(next time.Time, scheduled bool) string {
+	wait := time.Until(next).Round(time.Second)
+	if scheduled {
+		return fmt.Sprintf("at %s (in %s)", next.Format("Mon Jan 2 15:04"), wait)
+	}
+	return "in " + wait.String()
+}
+
+// waitUntil blocks until t or ctx cancellation. It returns false if the
+// context was cancelled or t is zero (a schedule that never fires).
+func waitUntil(ctx context.Context, t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
 }
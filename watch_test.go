@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -185,3 +187,321 @@ func TestRunWatchManifestResetBetweenCycles(t *testing.T) {
 		t.Errorf("unexpected manifest state: ok=%d skipped=%d errors=%d", m.OK, m.Skipped, m.Errors)
 	}
 }
+
+// ── Schedules & healthcheck ─────────────────────────────────────────────────
+
+func TestRunWatchScheduleWaitsForFirstSlot(t *testing.T) {
+	dir := t.TempDir()
+	sched, err := parseCron("0 0 1 1 *") // next Jan 1: never reached in the test
+	if err != nil {
+		t.Fatal(err)
+	}
+	health := filepath.Join(dir, "health")
+	cfg := &Config{
+		MeetingID:       "test-meeting-1",
+		OutputDir:       dir,
+		SkipVideo:       true,
+		Watch:           true,
+		WatchInterval:   time.Minute,
+		WatchSchedule:   sched,
+		HealthcheckFile: health,
+		MinDelaySec:     0,
+		MaxDelaySec:     0.001,
+	}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer e.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := e.RunWatch(ctx); err != nil {
+		t.Fatalf("RunWatch: %v", err)
+	}
+
+	if fileExists(filepath.Join(dir, "_export-manifest.json")) {
+		t.Error("no cycle should run before the first scheduled time")
+	}
+	data, err := os.ReadFile(health)
+	if err != nil {
+		t.Fatalf("healthcheck file: %v", err)
+	}
+	want := "next_run=" + sched.Next(time.Now()).UTC().Format(time.RFC3339)
+	if !strings.Contains(string(data), want) {
+		t.Errorf("healthcheck = %q, want line %q", data, want)
+	}
+}
+
+func TestHealthcheckJSON(t *testing.T) {
+	dir := t.TempDir()
+	health := filepath.Join(dir, "health.json")
+	e := &Exporter{
+		cfg:      &Config{OutputDir: dir, HealthcheckFile: health, HealthcheckFormat: "json"},
+		manifest: &ExportManifest{OK: 2, Skipped: 5},
+	}
+	read := func() HealthStatus {
+		t.Helper()
+		data, err := os.ReadFile(health)
+		if err != nil {
+			t.Fatalf("healthcheck file: %v", err)
+		}
+		var st HealthStatus
+		if err := json.Unmarshal(data, &st); err != nil {
+			t.Fatalf("healthcheck is not JSON: %v\n%s", err, data)
+		}
+		return st
+	}
+
+	ended := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	next := ended.Add(time.Hour)
+	e.endHealthCycle(1, ended, nil)
+	e.touchHealthcheck(next)
+	st := read()
+	if st.Cycle != 1 || st.Exported != 2 || st.Skipped != 5 || st.Errors != 0 || st.Error != "" {
+		t.Errorf("cycle status = %+v", st)
+	}
+	if st.CycleEndedAt != "2026-03-02T09:00:00Z" || st.LastExportAt != st.CycleEndedAt || st.NextRun != "2026-03-02T10:00:00Z" {
+		t.Errorf("times = %+v", st)
+	}
+	if st.UpdatedAt == "" {
+		t.Error("updated_at missing")
+	}
+	if got := loadWatchState(dir).LastExportAt; !got.Equal(ended) {
+		t.Errorf("persisted last export = %v, want %v", got, ended)
+	}
+
+	// A failed cycle with nothing new keeps the last export time.
+	e.manifest = &ExportManifest{Errors: 1}
+	e.endHealthCycle(2, ended.Add(time.Hour), errors.New("listing failed"))
+	e.touchHealthcheck(next.Add(time.Hour))
+	st = read()
+	if st.Exported != 0 || st.Errors != 1 || st.Error != "listing failed" || st.LastExportAt != "2026-03-02T09:00:00Z" {
+		t.Errorf("failed cycle status = %+v", st)
+	}
+
+	// Progress appears during a run and is cleared when the cycle ends.
+	e.health.update(func(st *HealthStatus) { st.Progress = &HealthProgress{Done: 3, Total: 10} })
+	e.writeHealthcheck("progress=3/10")
+	if p := read().Progress; p == nil || p.Done != 3 || p.Total != 10 {
+		t.Errorf("progress = %+v", p)
+	}
+	e.touchHealthcheck(next)
+	if p := read().Progress; p != nil {
+		t.Errorf("progress after cycle = %+v, want none", p)
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	if !waitUntil(context.Background(), time.Now().Add(-time.Second)) {
+		t.Error("past time should return true immediately")
+	}
+	if waitUntil(context.Background(), time.Time{}) {
+		t.Error("zero time should return false")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if waitUntil(ctx, time.Now().Add(time.Hour)) {
+		t.Error("cancelled context should return false")
+	}
+}
+
+// ── Catch-up ────────────────────────────────────────────────────────────────
+
+func TestMissedCycles(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	e := &Exporter{cfg: &Config{WatchInterval: time.Hour}}
+	cases := []struct {
+		last time.Time
+		want int
+	}{
+		{time.Time{}, 0},
+		{now.Add(-30 * time.Minute), 0},
+		{now.Add(-90 * time.Minute), 1},
+		{now.Add(-72 * time.Hour), 72},
+		{now.AddDate(-1, 0, 0), watchMissedCap},
+	}
+	for _, c := range cases {
+		if got := e.missedCycles(c.last, now); got != c.want {
+			t.Errorf("missedCycles(%v) = %d, want %d", c.last, got, c.want)
+		}
+	}
+
+	sched, err := parseCron("0 9 * * *") // daily at 09:00
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.cfg.WatchSchedule = sched
+	if got := e.missedCycles(now.Add(-72*time.Hour), now); got != 3 {
+		t.Errorf("scheduled missedCycles = %d, want 3", got)
+	}
+}
+
+func TestWatchStateRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir}}
+	if st := loadWatchState(dir); !st.LastCycleAt.IsZero() {
+		t.Fatalf("missing state should be zero, got %v", st.LastCycleAt)
+	}
+
+	at := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	e.saveWatchState(at)
+	e.saveWatchState(at.Add(-time.Hour)) // another instance, older cycle
+	if got := loadWatchState(dir).LastCycleAt; !got.Equal(at) {
+		t.Errorf("LastCycleAt = %v, want %v", got, at)
+	}
+	info, err := os.Stat(filepath.Join(dir, watchStateFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("state perms = %04o, want 0600", info.Mode().Perm())
+	}
+
+	// A corrupt state file falls back to the backup written by the
+	// previous save.
+	e.saveWatchState(at.Add(time.Hour))
+	if err := os.WriteFile(filepath.Join(dir, watchStateFile), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadWatchState(dir).LastCycleAt; !got.Equal(at) {
+		t.Errorf("recovered LastCycleAt = %v, want backup %v", got, at)
+	}
+}
+
+func TestRunWatchCatchesUpAfterDowntime(t *testing.T) {
+	dir := t.TempDir()
+	sched, err := parseCron("0 0 1 1 *") // next Jan 1: never reached in the test
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		MeetingID:     "test-meeting-1",
+		OutputDir:     dir,
+		SkipVideo:     true,
+		Watch:         true,
+		WatchSchedule: sched,
+		MinDelaySec:   0,
+		MaxDelaySec:   0.001,
+	}
+	e, err := NewExporter(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewExporter: %v", err)
+	}
+	defer e.Close()
+	// Last cycle three years ago: the yearly schedule missed three runs.
+	e.saveWatchState(time.Now().AddDate(-3, 0, -1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	if err := e.RunWatch(ctx); err != nil {
+		t.Fatalf("RunWatch: %v", err)
+	}
+
+	if !fileExists(filepath.Join(dir, "_export-manifest.json")) {
+		t.Error("catch-up cycle should run without waiting for the schedule")
+	}
+	if last := loadWatchState(dir).LastCycleAt; time.Since(last) > time.Minute {
+		t.Errorf("LastCycleAt = %v, want the catch-up cycle", last)
+	}
+	if e.scrollDepth != defaultScrollDepth {
+		t.Errorf("scroll depth after catch-up = %+v, want default", e.scrollDepth)
+	}
+}
+
+func TestBeginCatchUp(t *testing.T) {
+	dir := t.TempDir()
+	e := &Exporter{cfg: &Config{OutputDir: dir, MaxMeetings: 10}}
+
+	end := e.beginCatchUp(3)
+	if e.cfg.MaxMeetings != 40 || e.scrollDepth != catchUpScrollDepth {
+		t.Errorf("during catch-up: max=%d depth=%+v", e.cfg.MaxMeetings, e.scrollDepth)
+	}
+	end()
+	if e.cfg.MaxMeetings != 10 || e.scrollDepth != defaultScrollDepth {
+		t.Errorf("after catch-up: max=%d depth=%+v", e.cfg.MaxMeetings, e.scrollDepth)
+	}
+
+	// With shared archives, only the claim holder goes deep.
+	e.claims = NewClaimStore(dir, time.Minute)
+	other, err := NewClaimStore(dir, time.Minute).Acquire(watchCatchUpClaim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end = e.beginCatchUp(3)
+	if e.cfg.MaxMeetings != 10 || e.scrollDepth == catchUpScrollDepth {
+		t.Error("catch-up claimed by another instance should leave the cycle normal")
+	}
+	end()
+	other.Release()
+
+	end = e.beginCatchUp(1)
+	if e.scrollDepth != catchUpScrollDepth {
+		t.Error("catch-up should run once the claim is free")
+	}
+	end()
+	if fileExists(filepath.Join(dir, claimDirName, watchCatchUpClaim+".claim")) {
+		t.Error("catch-up claim should be released")
+	}
+}
+
+func TestNextWatchBackoff(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	e := &Exporter{cfg: &Config{WatchInterval: 30 * time.Minute}}
+	for failures, want := range []time.Duration{
+		30 * time.Minute, 30 * time.Minute, 30 * time.Minute, // below the threshold
+		time.Hour, 2 * time.Hour, 4 * time.Hour, watchBackoffCap, watchBackoffCap,
+	} {
+		if got := e.nextWatchBackoff(now, failures).Sub(now); got != want {
+			t.Errorf("failures=%d: wait %v, want %v", failures, got, want)
+		}
+	}
+
+	// An interval past the cap is never shortened.
+	e.cfg.WatchInterval = 8 * time.Hour
+	if got := e.nextWatchBackoff(now, 5).Sub(now); got != 8*time.Hour {
+		t.Errorf("long interval backoff = %v", got)
+	}
+
+	// Schedules skip the slots inside the backoff.
+	sched, err := parseCron("*/15 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.cfg.WatchSchedule = sched
+	if got := e.nextWatchBackoff(now, 0); !got.Equal(now.Add(15 * time.Minute)) {
+		t.Errorf("scheduled next = %v", got)
+	}
+	if got := e.nextWatchBackoff(now, 4); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("scheduled backoff next = %v, want %v", got, now.Add(time.Hour))
+	}
+}
+
+func TestHealthcheckBackoff(t *testing.T) {
+	dir := t.TempDir()
+	health := filepath.Join(dir, "health")
+	e := &Exporter{cfg: &Config{OutputDir: dir, HealthcheckFile: health}}
+	next := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+
+	e.health.update(func(st *HealthStatus) { st.Backoff, st.FailedCycles = true, 4 })
+	e.touchHealthcheck(next)
+	data, _ := os.ReadFile(health)
+	if !strings.Contains(string(data), "next_run=2026-03-02T11:00:00Z\nbackoff=true\nconsecutive_failures=4\n") {
+		t.Errorf("healthcheck = %q", data)
+	}
+
+	e.cfg.HealthcheckFormat = "json"
+	e.touchHealthcheck(next)
+	var st HealthStatus
+	data, _ = os.ReadFile(health)
+	if err := json.Unmarshal(data, &st); err != nil || !st.Backoff || st.FailedCycles != 4 {
+		t.Errorf("json healthcheck = %s (%v)", data, err)
+	}
+
+	e.health.update(func(st *HealthStatus) { st.Backoff, st.FailedCycles = false, 0 })
+	e.cfg.HealthcheckFormat = ""
+	e.touchHealthcheck(next)
+	if data, _ := os.ReadFile(health); strings.Contains(string(data), "backoff") {
+		t.Errorf("healthcheck after recovery = %q", data)
+	}
+}
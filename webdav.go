@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ── WebDAV Mirror ───────────────────────────────────────────────────────────
+//
+// --webdav-url mirrors the archive to a WebDAV share (Nextcloud, ownCloud,
+// Synology, rclone serve webdav, ...) with plain PUT and MKCOL requests.
+// Credentials come from GRAIN_WEBDAV_USER / GRAIN_WEBDAV_PASSWORD (env or
+// .env only) and are sent with HTTP Basic auth, so the URL must be https
+// unless it points at the local machine.
+
+// WebDAVMirror implements Mirror for a WebDAV collection.
+type WebDAVMirror struct {
+	base   *url.URL
+	user   string
+	pass   string
+	client *http.Client
+
+	mu   sync.Mutex
+	dirs map[string]bool // collections known to exist
+}
+
+// NewWebDAVMirror validates rawURL and returns a mirror rooted at it.
+func NewWebDAVMirror(rawURL, user, pass string) (*WebDAVMirror, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid --webdav-url %q: must be an http(s) URL", rawURL)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("--webdav-url must not embed credentials; use GRAIN_WEBDAV_USER and GRAIN_WEBDAV_PASSWORD")
+	}
+	if u.Scheme == "http" && !isLoopbackHost(u.Hostname()) {
+		return nil, fmt.Errorf("--webdav-url must use https (plain http is only allowed for localhost)")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawQuery, u.Fragment = "", ""
+	return &WebDAVMirror{
+		base: u,
+		user: user,
+		pass: pass,
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			ResponseHeaderTimeout: 60 * time.Second,
+		}},
+		dirs: map[string]bool{"": true},
+	}, nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (w *WebDAVMirror) Name() string { return "webdav" }
+
+func (w *WebDAVMirror) Put(relPath string, data []byte) error {
+	if err := w.Mkdir(filepath.Dir(relPath)); err != nil {
+		return err
+	}
+	return w.put(relPath, bytes.NewReader(data), int64(len(data)))
+}
+
+func (w *WebDAVMirror) PutFile(relPath, absPath string) error {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := w.Mkdir(filepath.Dir(relPath)); err != nil {
+		return err
+	}
+	return w.put(relPath, f, info.Size())
+}
+
+// Mkdir creates relPath and any missing parents with MKCOL.
+func (w *WebDAVMirror) Mkdir(relPath string) error {
+	rel := filepath.ToSlash(filepath.Clean(relPath))
+	if rel == "." {
+		return nil
+	}
+	dir := ""
+	for _, part := range strings.Split(rel, "/") {
+		dir = path.Join(dir, part)
+		w.mu.Lock()
+		known := w.dirs[dir]
+		w.mu.Unlock()
+		if known {
+			continue
+		}
+		resp, err := w.do("MKCOL", w.url(dir)+"/", nil, 0)
+		if err != nil {
+			return fmt.Errorf("webdav mkcol %s: %w", dir, err)
+		}
+		resp.Body.Close()
+		// 405: the collection already exists.
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("webdav mkcol %s: %s", dir, resp.Status)
+		}
+		w.mu.Lock()
+		w.dirs[dir] = true
+		w.mu.Unlock()
+	}
+	return nil
+}
+
+func (w *WebDAVMirror) Close() error { return nil }
+
+func (w *WebDAVMirror) put(relPath string, body io.Reader, size int64) error {
+	resp, err := w.do(http.MethodPut, w.url(filepath.ToSlash(relPath)), body, size)
+	if err != nil {
+		return fmt.Errorf("webdav put: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return fmt.Errorf("webdav put %s: %s", relPath, resp.Status)
+}
+
+func (w *WebDAVMirror) do(method, target string, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if w.user != "" || w.pass != "" {
+		req.SetBasicAuth(w.user, w.pass)
+	}
+	return w.client.Do(req)
+}
+
+// url returns the absolute URL of a slash-separated path under the base.
+func (w *WebDAVMirror) url(rel string) string {
+	u := *w.base
+	u.Path = w.base.Path + "/" + strings.TrimPrefix(rel, "/")
+	u.RawPath = ""
+	return u.String()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ── Zstandard ───────────────────────────────────────────────────────────────
+//
+// --compress zstd uses github.com/klauspost/compress/zstd, so the frames
+// graindl writes are standard (with content checksums) and any zstd file,
+// including those from the zstd CLI, reads back. The encoder and the
+// whole-buffer decoder are shared: EncodeAll and DecodeAll are safe for
+// concurrent use.
+
+const zstdMaxDecodedSize = 1 << 30 // refuse to inflate past this
+
+var (
+	zstdEncoder = sync.OnceValue(func() *zstd.Encoder {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+		return enc
+	})
+	zstdDecoder = sync.OnceValue(func() *zstd.Decoder {
+		dec, _ := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(zstdMaxDecodedSize))
+		return dec
+	})
+)
+
+// zstdCompress returns src as a single Zstandard frame.
+func zstdCompress(src []byte) []byte {
+	return zstdEncoder().EncodeAll(src, nil)
+}
+
+// zstdDecompress decodes every frame in src.
+func zstdDecompress(src []byte) ([]byte, error) {
+	return zstdDecoder().DecodeAll(src, nil)
+}
+
+// newZstdReader returns a streaming decoder for r. Close releases it.
+func newZstdReader(r io.Reader) (io.ReadCloser, error) {
+	dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(zstdMaxDecodedSize))
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
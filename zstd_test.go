@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func zstdSamples() map[string][]byte {
+	rng := rand.New(rand.NewSource(1))
+	noise := make([]byte, 200<<10)
+	rng.Read(noise)
+	letters := make([]byte, 150<<10)
+	for i := range letters {
+		letters[i] = "etaoin shrdlu\n"[rng.Intn(14)]
+	}
+	return map[string][]byte{
+		"empty":      {},
+		"one byte":   {'x'},
+		"run":        bytes.Repeat([]byte{'a'}, 300<<10),
+		"transcript": []byte(longTranscript(3000)), // several blocks
+		"letters":    letters,                      // Huffman-coded literals
+		"short text": []byte("00:00:01 Ana: hello hello hello there"),
+		"noise":      noise, // incompressible: raw blocks
+		"unicode":    []byte(strings.Repeat("Résumé — naïve café ☕ ", 2000)),
+	}
+}
+
+func TestZstdRoundTrip(t *testing.T) {
+	for name, src := range zstdSamples() {
+		enc := zstdCompress(src)
+		dec, err := zstdDecompress(enc)
+		if err != nil {
+			t.Errorf("%s: decompress: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(dec, src) {
+			t.Errorf("%s: round trip changed %d bytes into %d", name, len(src), len(dec))
+		}
+	}
+	if src := []byte(longTranscript(3000)); len(zstdCompress(src))*3 > len(src) {
+		t.Errorf("transcript compressed poorly: %d → %d bytes", len(src), len(zstdCompress(src)))
+	}
+}
+
+func TestZstdDecompressErrors(t *testing.T) {
+	enc := zstdCompress([]byte(longTranscript(50)))
+	if _, err := zstdDecompress(enc[:len(enc)-10]); err == nil {
+		t.Error("truncated frame decoded")
+	}
+	bad := append([]byte(nil), enc...)
+	bad[len(bad)-1] ^= 0xFF // checksum
+	if _, err := zstdDecompress(bad); err == nil {
+		t.Errorf("bad checksum: err = %v", err)
+	}
+	if _, err := zstdDecompress([]byte("not zstd at all")); err == nil {
+		t.Error("garbage decoded")
+	}
+
+	// Skippable frames are ignored; concatenated frames are joined.
+	skip := []byte{0x50, 0x2A, 0x4D, 0x18, 3, 0, 0, 0, 1, 2, 3}
+	two := append(append(skip, zstdCompress([]byte("ab"))...), zstdCompress([]byte("cd"))...)
+	if got, err := zstdDecompress(two); err != nil || string(got) != "abcd" {
+		t.Errorf("multi-frame = %q, %v", got, err)
+	}
+}
+
+// TestZstdCLI checks that the reference zstd tool reads our frames and
+// that we read its output. Skipped when zstd isn't installed.
+func TestZstdCLI(t *testing.T) {
+	bin, err := exec.LookPath("zstd")
+	if err != nil {
+		t.Skip("zstd not installed")
+	}
+	dir := t.TempDir()
+	for name, src := range zstdSamples() {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-"))
+		if err := os.WriteFile(path+".zst", zstdCompress(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command(bin, "-qdc", path+".zst").Output()
+		if err != nil || !bytes.Equal(out, src) {
+			t.Errorf("%s: zstd -d failed or differs: %v", name, err)
+		}
+
+		if err := os.WriteFile(path, src, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		ref, err := exec.Command(bin, "-qc", "--no-check", path).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := zstdDecompress(ref); err != nil || !bytes.Equal(got, src) {
+			t.Errorf("%s: decoding zstd output: %v", name, err)
+		}
+	}
+}